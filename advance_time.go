@@ -0,0 +1,67 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+var errAdvanceTimeNotStubbable = errors.New("AdvanceTime requires Config.Test.Time be set to a time generator that can be stubbed, like riversharedtest.TimeStub")
+
+// AdvanceTime moves the client's stubbed clock forward by the given duration,
+// then synchronously runs a pass of each time-based maintenance service so
+// that work which would've come due in the elapsed time happens immediately:
+// scheduled jobs and retries are made available, periodic jobs are enqueued,
+// stuck jobs are rescued, and finalized jobs and stale queues are cleaned. Once
+// maintenance is finished, producers are woken to fetch any newly available
+// jobs.
+//
+// It's intended for tests of time-based behavior, which would otherwise have
+// to wait in real time for the relevant durations to elapse:
+//
+//	config := &river.Config{
+//		Test: river.TestConfig{
+//			Time: &riversharedtest.TimeStub{},
+//		},
+//		...
+//	}
+//
+//	...
+//
+//	// Work a retry that'd otherwise be scheduled well into the future.
+//	if err := client.AdvanceTime(ctx, 10*time.Minute); err != nil {
+//		// handle error
+//	}
+//
+// AdvanceTime returns an error unless Config.Test.Time is set to a time
+// generator that can be stubbed. Maintenance runs regardless of whether the
+// client has been elected leader, but periodic jobs are only enqueued once
+// the client's periodic job enqueuer has started, which happens when the
+// client is started and elected leader.
+func (c *Client[TTx]) AdvanceTime(ctx context.Context, d time.Duration) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	if _, ok := c.config.Test.Time.(baseservice.TimeGeneratorWithStub); !ok {
+		return errAdvanceTimeNotStubbable
+	}
+
+	c.baseService.Time.StubNow(c.baseService.Time.Now().Add(d))
+
+	// Insert-only clients don't have a queue maintainer.
+	if c.queueMaintainer != nil {
+		if err := c.queueMaintainer.RunOnce(ctx); err != nil {
+			return fmt.Errorf("error running maintenance: %w", err)
+		}
+	}
+
+	for _, producer := range c.producersByQueueName {
+		producer.TriggerJobFetch()
+	}
+
+	return nil
+}
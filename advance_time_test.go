@@ -0,0 +1,140 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/maintenance"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestClientAdvanceTime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testArgs struct {
+		testutil.JobArgsReflectKind[testArgs]
+	}
+
+	type testBundle struct {
+		subscribeChan <-chan *Event
+	}
+
+	setup := func(t *testing.T, config *Config) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+		config.Schema = schema
+
+		client := newTestClient(t, dbPool, config)
+		client.testSignals.Init(t)
+
+		return client, &testBundle{
+			subscribeChan: subscribe(t, client),
+		}
+	}
+
+	t.Run("ErrorsWithoutStubbableTime", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.Test.Time = nil
+
+		client, _ := setup(t, config)
+
+		require.ErrorIs(t, client.AdvanceTime(ctx, time.Hour), errAdvanceTimeNotStubbable)
+	})
+
+	t.Run("WorksScheduledJob", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[testArgs]) error {
+			return nil
+		}))
+
+		client, bundle := setup(t, config)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, testArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, insertRes.Job.State)
+
+		require.NoError(t, client.AdvanceTime(ctx, time.Hour+time.Second))
+
+		event := riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("WorksRetry", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[testArgs]) error {
+			if job.Attempt < 2 {
+				return errors.New("first attempt fails")
+			}
+			return nil
+		}))
+
+		client, bundle := setup(t, config)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, testArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
+
+		require.NoError(t, client.AdvanceTime(ctx, time.Hour))
+
+		event = riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, 2, event.Job.Attempt)
+	})
+
+	t.Run("EnqueuesPeriodicJobs", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		AddWorker(config.Workers, &periodicJobWorker{})
+		config.PeriodicJobs = []*PeriodicJob{
+			NewPeriodicJob(PeriodicInterval(15*time.Minute), func() (JobArgs, *InsertOpts) {
+				return periodicJobArgs{}, nil
+			}, nil),
+		}
+
+		client, bundle := setup(t, config)
+		startClient(ctx, t, client)
+		client.queueMaintainerLeader.TestSignals.ElectedLeader.WaitOrTimeout()
+
+		svc := maintenance.GetService[*maintenance.PeriodicJobEnqueuer](client.queueMaintainer)
+		svc.TestSignals.EnteredLoop.WaitOrTimeout()
+
+		// Two runs elapse, so two jobs are enqueued.
+		require.NoError(t, client.AdvanceTime(ctx, 31*time.Minute))
+
+		for range 2 {
+			event := riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+			require.Equal(t, EventKindJobCompleted, event.Kind)
+			require.Equal(t, (periodicJobArgs{}).Kind(), event.Job.Kind)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package river
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+
+	"github.com/riverqueue/river/internal/argscodec"
+)
+
+// ArgsCodec encodes and decodes job args, replacing the default JSON encoding
+// when configured with Config.ArgsCodec. It can be used to encode args with a
+// more efficient format like msgpack or protobuf.
+//
+// Args are stored in a JSON column, so a codec's output is wrapped in a JSON
+// string (base64 encoded) in the job's EncodedArgs. Features that inspect
+// args as JSON, like UniqueOpts.ByArgs, continue to work because they're
+// derived from a JSON encoding of args made separately at insert. Args
+// assertions in the rivertest package only support JSON encoded args.
+type ArgsCodec interface {
+	// Name is a unique name for the codec, like "msgpack". It's recorded in
+	// the metadata of jobs inserted with the codec so that any client can
+	// decode them with the right codec, so it shouldn't be changed once jobs
+	// have been inserted with it. The name "json" is reserved.
+	Name() string
+
+	// Marshal encodes the given job args.
+	Marshal(args any) ([]byte, error)
+
+	// Unmarshal decodes data previously encoded by Marshal into args, which is
+	// a pointer to a job args struct.
+	Unmarshal(data []byte, args any) error
+}
+
+// ArgsCompressor compresses and decompresses encoded job args. See
+// ArgsCompressionConfig.
+type ArgsCompressor interface {
+	// Name is a unique name for the compressor, like "zstd". It's recorded in
+	// the metadata of jobs compressed with it so that any client can
+	// decompress them with the right compressor, so it shouldn't be changed
+	// once jobs have been inserted with it. The name "gzip" is reserved for
+	// the built-in gzip compressor.
+	Name() string
+
+	// Compress compresses encoded job args.
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress decompresses data previously compressed by Compress.
+	Decompress(data []byte) ([]byte, error)
+}
+
+const argsCompressionMinBytesDefault = 32 * 1024
+
+// ArgsCompressionConfig configures compression of the args of inserted jobs
+// when their encoded size reaches a threshold, trading some CPU on insert and
+// work for smaller rows and less WAL for jobs with large args.
+//
+// The name of the compressor is recorded in the metadata of each compressed
+// job, and args are decompressed transparently before they're decoded for
+// work. Like args encoded with an ArgsCodec, compressed args are wrapped in a
+// JSON string (base64 encoded) in the job's EncodedArgs, and features that
+// inspect args as JSON, like UniqueOpts.ByArgs, continue to work because
+// they're derived from a JSON encoding of args made separately at insert.
+//
+// Jobs compressed with the built-in gzip compressor can be decompressed by any
+// client, even one without compression configured. Every client that may work
+// jobs compressed with a custom compressor must have it configured.
+type ArgsCompressionConfig struct {
+	// Compressor is the compressor used to compress args.
+	//
+	// Defaults to a built-in gzip compressor.
+	Compressor ArgsCompressor
+
+	// MinBytes is the minimum size of a job's encoded args for them to be
+	// compressed. Smaller args are stored uncompressed because compression
+	// wouldn't save much, and Postgres compresses large values on its own
+	// beyond a certain size anyway.
+	//
+	// Defaults to 32 KiB.
+	MinBytes int
+}
+
+// ArgsEncryptor encrypts and decrypts encoded job args when configured with
+// Config.ArgsEncryptor, providing encryption at rest for args that contain
+// sensitive data. Args are encrypted after they're encoded and compressed.
+//
+// Each job records the ID of the key its args were encrypted with, so an
+// encryptor may support multiple keys to allow for key rotation: new args are
+// encrypted with the key returned by KeyID, while args encrypted with older
+// keys can still be decrypted as long as Decrypt knows about them. While an
+// encryptor is configured, the client's leader periodically re-encrypts the
+// args of jobs encrypted with keys other than the current one so that older
+// keys can eventually be retired. AESGCMArgsEncryptor is a built-in
+// implementation using AES-GCM.
+//
+// Like args encoded with an ArgsCodec, encrypted args are wrapped in a JSON
+// string (base64 encoded) in the job's EncodedArgs, and features that inspect
+// args as JSON, like UniqueOpts.ByArgs, continue to work because they're
+// derived from a JSON encoding of args made separately at insert. Note that
+// this means that unique keys for jobs unique by args are derived from
+// unencrypted args. Args assertions in the rivertest package don't support
+// encrypted args.
+type ArgsEncryptor interface {
+	// KeyID returns the ID of the key that new args should be encrypted with.
+	// It's recorded in the metadata of jobs encrypted with the key, so it
+	// should uniquely identify the key and not be reused for another one.
+	KeyID() string
+
+	// Encrypt encrypts plaintext with the key identified by keyID.
+	Encrypt(keyID string, plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts ciphertext previously encrypted by Encrypt with the
+	// key identified by keyID.
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// argsDecoder returns a decoder for JSON and gzip as well as all configured
+// codecs, compressors, and encryption.
+func (c *Config) argsDecoder() *argscodec.Decoder {
+	codecs := make([]argscodec.Codec, 0, 1+len(c.ArgsCodecsDecodeOnly))
+	codecs = append(codecs, c.ArgsCodec)
+	for _, codec := range c.ArgsCodecsDecodeOnly {
+		codecs = append(codecs, codec)
+	}
+
+	var compressors []argscodec.Compressor
+	if c.ArgsCompression != nil && c.ArgsCompression.Compressor != nil {
+		compressors = append(compressors, c.ArgsCompression.Compressor)
+	}
+
+	var encryptor argscodec.Encryptor
+	if c.ArgsEncryptor != nil {
+		encryptor = c.ArgsEncryptor
+	}
+
+	return argscodec.NewDecoder(codecs, compressors, encryptor)
+}
+
+// argsEncoder returns an encoder for args encoded with the given codec, and
+// compressed and encrypted according to configuration.
+func (c *Config) argsEncoder(codec argscodec.Codec) *argscodec.Encoder {
+	encoder := &argscodec.Encoder{Codec: codec}
+	if c.ArgsEncryptor != nil {
+		encoder.Encryptor = c.ArgsEncryptor
+	}
+	if c.ArgsCompression != nil {
+		encoder.Compressor = argscodec.GzipCompressor{}
+		if c.ArgsCompression.Compressor != nil {
+			encoder.Compressor = c.ArgsCompression.Compressor
+		}
+		encoder.CompressMinBytes = cmp.Or(c.ArgsCompression.MinBytes, argsCompressionMinBytesDefault)
+	}
+	return encoder
+}
+
+// argsEncodingCustomized returns true if args may be encoded as something
+// other than plain JSON.
+func (c *Config) argsEncodingCustomized() bool {
+	return c.ArgsCodec != nil || c.ArgsCompression != nil || c.ArgsEncryptor != nil
+}
+
+func (c *Config) validateArgsCodecs() error {
+	names := make(map[string]struct{})
+
+	for _, codec := range append([]ArgsCodec{c.ArgsCodec}, c.ArgsCodecsDecodeOnly...) {
+		if codec == nil {
+			continue
+		}
+
+		name := codec.Name()
+		switch {
+		case name == "":
+			return errors.New("ArgsCodec name cannot be empty")
+		case name == argscodec.NameJSON:
+			return fmt.Errorf("ArgsCodec name %q is reserved", argscodec.NameJSON)
+		}
+
+		if _, ok := names[name]; ok {
+			return fmt.Errorf("ArgsCodec name %q is used by more than one codec", name)
+		}
+		names[name] = struct{}{}
+	}
+
+	return nil
+}
+
+func (c *Config) validateArgsCompression() error {
+	if c.ArgsCompression == nil {
+		return nil
+	}
+
+	if c.ArgsCompression.MinBytes < 0 {
+		return errors.New("ArgsCompression.MinBytes cannot be less than zero")
+	}
+
+	if compressor := c.ArgsCompression.Compressor; compressor != nil {
+		switch name := compressor.Name(); name {
+		case "":
+			return errors.New("ArgsCompressor name cannot be empty")
+		case argscodec.NameGzip:
+			return fmt.Errorf("ArgsCompressor name %q is reserved", argscodec.NameGzip)
+		}
+	}
+
+	return nil
+}
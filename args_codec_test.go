@@ -0,0 +1,426 @@
+package river
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/internal/argscodec"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// gobArgsCodec is an ArgsCodec for tests that encodes args with encoding/gob.
+type gobArgsCodec struct {
+	name string
+}
+
+func (c *gobArgsCodec) Name() string { return c.name }
+
+func (c *gobArgsCodec) Marshal(args any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(args); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gobArgsCodec) Unmarshal(data []byte, args any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(args)
+}
+
+// testArgsCompressor is an ArgsCompressor for tests that wraps gzip under a
+// custom name.
+type testArgsCompressor struct {
+	argscodec.GzipCompressor
+
+	name string
+}
+
+func (c *testArgsCompressor) Name() string { return c.name }
+
+// testArgsEncryptor is an insecure ArgsEncryptor for tests that XORs data with
+// a single byte key.
+type testArgsEncryptor struct {
+	keyID string
+	keys  map[string]byte
+}
+
+func (e *testArgsEncryptor) KeyID() string { return e.keyID }
+
+func (e *testArgsEncryptor) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return e.xor(keyID, plaintext)
+}
+
+func (e *testArgsEncryptor) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return e.xor(keyID, ciphertext)
+}
+
+func (e *testArgsEncryptor) xor(keyID string, data []byte) ([]byte, error) {
+	key, ok := e.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ key
+	}
+	return out, nil
+}
+
+type argsCodecArgs struct {
+	Name string `json:"name" unique:"true"`
+}
+
+func (argsCodecArgs) Kind() string { return "args_codec" }
+
+func Test_Client_ArgsCodec(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config     *Config
+		workedArgs chan argsCodecArgs
+	}
+
+	setupConfig := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		bundle := &testBundle{
+			config:     newTestConfig(t, ""),
+			workedArgs: make(chan argsCodecArgs, 10),
+		}
+		bundle.config.ArgsCodec = &gobArgsCodec{name: "gob"}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[argsCodecArgs]) error {
+			bundle.workedArgs <- job.Args
+			return nil
+		}))
+
+		return bundle
+	}
+
+	t.Run("InsertsAndWorks", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "gob", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCodec).String())
+		require.Equal(t, gjson.String, gjson.ParseBytes(insertRes.Job.EncodedArgs).Type)
+
+		require.Equal(t, argsCodecArgs{Name: "name"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("DecodeOnly", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+
+		// Only the client working jobs is started, and it decodes the codec
+		// without encoding with it.
+		bundle.config.ArgsCodec = nil
+		bundle.config.ArgsCodecsDecodeOnly = []ArgsCodec{&gobArgsCodec{name: "gob"}}
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertConfig := newTestConfig(t, client.config.Schema)
+		insertConfig.ArgsCodec = &gobArgsCodec{name: "gob"}
+		insertClient, err := NewClient(riverpgxv5.New(riversharedtest.DBPool(ctx, t)), insertConfig)
+		require.NoError(t, err)
+
+		_, err = insertClient.Insert(ctx, argsCodecArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, argsCodecArgs{Name: "name"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+
+		// Jobs inserted by the client itself are still JSON.
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "json"}, nil)
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCodec).Exists())
+		require.JSONEq(t, `{"name":"json"}`, string(insertRes.Job.EncodedArgs))
+
+		require.Equal(t, argsCodecArgs{Name: "json"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("UniqueByArgs", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			bundle = setupConfig(t)
+		)
+
+		bundle.config.Schema = schema
+		client := newTestClient(t, dbPool, bundle.config)
+
+		insertOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "name"}, insertOpts)
+		require.NoError(t, err)
+		require.False(t, insertRes.UniqueSkippedAsDuplicate)
+
+		insertRes, err = client.Insert(ctx, argsCodecArgs{Name: "name"}, insertOpts)
+		require.NoError(t, err)
+		require.True(t, insertRes.UniqueSkippedAsDuplicate)
+
+		insertRes, err = client.Insert(ctx, argsCodecArgs{Name: "other"}, insertOpts)
+		require.NoError(t, err)
+		require.False(t, insertRes.UniqueSkippedAsDuplicate)
+	})
+
+	t.Run("JobRetryWithNewArgs", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			bundle = setupConfig(t)
+		)
+
+		bundle.config.Schema = schema
+		client := newTestClient(t, dbPool, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+
+		job, err := client.JobRetryWithNewArgs(ctx, insertRes.Job.ID, argsCodecArgs{Name: "new_name"})
+		require.NoError(t, err)
+
+		var args argsCodecArgs
+		require.NoError(t, client.argsDecoder.Decode(job, &args))
+		require.Equal(t, argsCodecArgs{Name: "new_name"}, args)
+	})
+}
+
+func Test_Client_ArgsCompression(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config     *Config
+		workedArgs chan argsCodecArgs
+	}
+
+	setupConfig := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		bundle := &testBundle{
+			config:     newTestConfig(t, ""),
+			workedArgs: make(chan argsCodecArgs, 10),
+		}
+		bundle.config.ArgsCompression = &ArgsCompressionConfig{MinBytes: 100}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[argsCodecArgs]) error {
+			bundle.workedArgs <- job.Args
+			return nil
+		}))
+
+		return bundle
+	}
+
+	largeName := strings.Repeat("a", 100)
+
+	t.Run("InsertsAndWorks", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: largeName}, nil)
+		require.NoError(t, err)
+		require.Equal(t, argscodec.NameGzip, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCompression).String())
+		require.Equal(t, gjson.String, gjson.ParseBytes(insertRes.Job.EncodedArgs).Type)
+
+		require.Equal(t, argsCodecArgs{Name: largeName}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("BelowMinBytes", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCompression).Exists())
+		require.JSONEq(t, `{"name":"name"}`, string(insertRes.Job.EncodedArgs))
+
+		require.Equal(t, argsCodecArgs{Name: "name"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("CustomCompressorWithCodec", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+		bundle.config.ArgsCodec = &gobArgsCodec{name: "gob"}
+		bundle.config.ArgsCompression.Compressor = &testArgsCompressor{name: "custom"}
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: largeName}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "gob", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCodec).String())
+		require.Equal(t, "custom", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCompression).String())
+
+		require.Equal(t, argsCodecArgs{Name: largeName}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("UniqueByArgs", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			bundle = setupConfig(t)
+		)
+
+		bundle.config.Schema = schema
+		client := newTestClient(t, dbPool, bundle.config)
+
+		insertOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: largeName}, insertOpts)
+		require.NoError(t, err)
+		require.False(t, insertRes.UniqueSkippedAsDuplicate)
+
+		insertRes, err = client.Insert(ctx, argsCodecArgs{Name: largeName}, insertOpts)
+		require.NoError(t, err)
+		require.True(t, insertRes.UniqueSkippedAsDuplicate)
+	})
+
+	t.Run("JobRetryWithNewArgs", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			bundle = setupConfig(t)
+		)
+
+		bundle.config.Schema = schema
+		client := newTestClient(t, dbPool, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: largeName}, nil)
+		require.NoError(t, err)
+		require.True(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsCompression).Exists())
+
+		// New args are small enough that they're no longer compressed.
+		job, err := client.JobRetryWithNewArgs(ctx, insertRes.Job.ID, argsCodecArgs{Name: "new_name"})
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(job.Metadata, rivertype.MetadataKeyArgsCompression).Exists())
+
+		var args argsCodecArgs
+		require.NoError(t, client.argsDecoder.Decode(job, &args))
+		require.Equal(t, argsCodecArgs{Name: "new_name"}, args)
+	})
+}
+
+func Test_Client_ArgsEncryptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config     *Config
+		workedArgs chan argsCodecArgs
+	}
+
+	setupConfig := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		bundle := &testBundle{
+			config:     newTestConfig(t, ""),
+			workedArgs: make(chan argsCodecArgs, 10),
+		}
+		bundle.config.ArgsEncryptor = &testArgsEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[argsCodecArgs]) error {
+			bundle.workedArgs <- job.Args
+			return nil
+		}))
+
+		return bundle
+	}
+
+	t.Run("InsertsAndWorks", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "secret"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "key1", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsEncryptionKeyID).String())
+		require.Equal(t, gjson.String, gjson.ParseBytes(insertRes.Job.EncodedArgs).Type)
+		require.NotContains(t, string(insertRes.Job.EncodedArgs), "secret")
+
+		require.Equal(t, argsCodecArgs{Name: "secret"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("DecryptsRotatedKey", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setupConfig(t)
+
+		// Only the client working jobs is started, and it's rotated to a new
+		// key, but can still decrypt args encrypted with the old one.
+		bundle.config.ArgsEncryptor = &testArgsEncryptor{keyID: "key2", keys: map[string]byte{"key1": 0x2a, "key2": 0x3b}}
+		client := runNewTestClient(ctx, t, bundle.config)
+
+		insertConfig := newTestConfig(t, client.config.Schema)
+		insertConfig.ArgsEncryptor = &testArgsEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}
+		insertClient, err := NewClient(riverpgxv5.New(riversharedtest.DBPool(ctx, t)), insertConfig)
+		require.NoError(t, err)
+
+		_, err = insertClient.Insert(ctx, argsCodecArgs{Name: "secret"}, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, argsCodecArgs{Name: "secret"}, riversharedtest.WaitOrTimeout(t, bundle.workedArgs))
+	})
+
+	t.Run("JobRetryWithNewArgs", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			bundle = setupConfig(t)
+		)
+
+		bundle.config.Schema = schema
+		client := newTestClient(t, dbPool, bundle.config)
+
+		insertRes, err := client.Insert(ctx, argsCodecArgs{Name: "secret"}, nil)
+		require.NoError(t, err)
+
+		// New args are encrypted with the current key.
+		client.config.ArgsEncryptor = &testArgsEncryptor{keyID: "key2", keys: map[string]byte{"key1": 0x2a, "key2": 0x3b}}
+		client.argsDecoder = client.config.argsDecoder()
+
+		job, err := client.JobRetryWithNewArgs(ctx, insertRes.Job.ID, argsCodecArgs{Name: "new_secret"})
+		require.NoError(t, err)
+		require.Equal(t, "key2", gjson.GetBytes(job.Metadata, rivertype.MetadataKeyArgsEncryptionKeyID).String())
+
+		var args argsCodecArgs
+		require.NoError(t, client.argsDecoder.Decode(job, &args))
+		require.Equal(t, argsCodecArgs{Name: "new_secret"}, args)
+	})
+}
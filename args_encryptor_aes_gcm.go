@@ -0,0 +1,111 @@
+package river
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// AESGCMArgsEncryptor is a built-in ArgsEncryptor that encrypts args with
+// AES-GCM. It supports multiple keys so that keys can be rotated: new args are
+// encrypted with the current key, while args encrypted with any other known key
+// can still be decrypted.
+//
+// To rotate keys, add a new key and make it current, keeping the previous key
+// until jobs encrypted with it have been re-encrypted. While an encryptor is
+// configured, the client's leader periodically re-encrypts the args of jobs
+// encrypted with keys other than the current one, after which the previous key
+// can be removed.
+type AESGCMArgsEncryptor struct {
+	aeads        map[string]cipher.AEAD
+	currentKeyID string
+}
+
+// NewAESGCMArgsEncryptor returns a new AESGCMArgsEncryptor for the given keys,
+// which are keyed by ID. New args are encrypted with the key identified by
+// currentKeyID. Keys must be 16, 24, or 32 bytes long to select AES-128,
+// AES-192, or AES-256 respectively.
+func NewAESGCMArgsEncryptor(currentKeyID string, keys map[string][]byte) (*AESGCMArgsEncryptor, error) {
+	if currentKeyID == "" {
+		return nil, errors.New("current key ID cannot be empty")
+	}
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("current key ID %q not found in keys", currentKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for keyID, key := range keys {
+		if keyID == "" {
+			return nil, errors.New("key ID cannot be empty")
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cipher for key %q: %w", keyID, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("error creating GCM for key %q: %w", keyID, err)
+		}
+
+		aeads[keyID] = aead
+	}
+
+	return &AESGCMArgsEncryptor{
+		aeads:        aeads,
+		currentKeyID: currentKeyID,
+	}, nil
+}
+
+func (e *AESGCMArgsEncryptor) KeyID() string { return e.currentKeyID }
+
+// Encrypt encrypts plaintext with the key identified by keyID. The returned
+// ciphertext is prefixed with a random nonce. The key ID is used as additional
+// authenticated data so that ciphertext can't be decrypted as if it'd been
+// encrypted with another key.
+func (e *AESGCMArgsEncryptor) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	aead, err := e.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Decrypt decrypts ciphertext previously encrypted by Encrypt with the key
+// identified by keyID.
+func (e *AESGCMArgsEncryptor) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	aead, err := e.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is shorter than nonce")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting with key %q: %w", keyID, err)
+	}
+
+	return plaintext, nil
+}
+
+func (e *AESGCMArgsEncryptor) aead(keyID string) (cipher.AEAD, error) {
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown args encryption key %q", keyID)
+	}
+	return aead, nil
+}
@@ -0,0 +1,118 @@
+package river
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESGCMArgsEncryptor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key1 = bytes.Repeat([]byte{1}, 32)
+		key2 = bytes.Repeat([]byte{2}, 16)
+	)
+
+	t.Run("EncryptsAndDecrypts", func(t *testing.T) {
+		t.Parallel()
+
+		encryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1})
+		require.NoError(t, err)
+		require.Equal(t, "key1", encryptor.KeyID())
+
+		ciphertext, err := encryptor.Encrypt("key1", []byte(`{"name":"secret"}`))
+		require.NoError(t, err)
+		require.NotContains(t, string(ciphertext), "secret")
+
+		plaintext, err := encryptor.Decrypt("key1", ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"secret"}`, string(plaintext))
+	})
+
+	t.Run("RandomNonce", func(t *testing.T) {
+		t.Parallel()
+
+		encryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1})
+		require.NoError(t, err)
+
+		ciphertext1, err := encryptor.Encrypt("key1", []byte("data"))
+		require.NoError(t, err)
+		ciphertext2, err := encryptor.Encrypt("key1", []byte("data"))
+		require.NoError(t, err)
+		require.NotEqual(t, ciphertext1, ciphertext2)
+	})
+
+	t.Run("DecryptsWithOlderKey", func(t *testing.T) {
+		t.Parallel()
+
+		oldEncryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1})
+		require.NoError(t, err)
+
+		ciphertext, err := oldEncryptor.Encrypt("key1", []byte("data"))
+		require.NoError(t, err)
+
+		encryptor, err := NewAESGCMArgsEncryptor("key2", map[string][]byte{"key1": key1, "key2": key2})
+		require.NoError(t, err)
+		require.Equal(t, "key2", encryptor.KeyID())
+
+		plaintext, err := encryptor.Decrypt("key1", ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(plaintext))
+	})
+
+	t.Run("WrongKeyIDError", func(t *testing.T) {
+		t.Parallel()
+
+		// Same key material under two IDs. Because the key ID is authenticated,
+		// ciphertext still can't be decrypted under the wrong one.
+		encryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1, "key1_copy": key1})
+		require.NoError(t, err)
+
+		ciphertext, err := encryptor.Encrypt("key1", []byte("data"))
+		require.NoError(t, err)
+
+		_, err = encryptor.Decrypt("key1_copy", ciphertext)
+		require.ErrorContains(t, err, `error decrypting with key "key1_copy"`)
+	})
+
+	t.Run("UnknownKeyError", func(t *testing.T) {
+		t.Parallel()
+
+		encryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1})
+		require.NoError(t, err)
+
+		_, err = encryptor.Encrypt("key2", []byte("data"))
+		require.EqualError(t, err, `unknown args encryption key "key2"`)
+
+		_, err = encryptor.Decrypt("key2", []byte("data"))
+		require.EqualError(t, err, `unknown args encryption key "key2"`)
+	})
+
+	t.Run("ShortCiphertextError", func(t *testing.T) {
+		t.Parallel()
+
+		encryptor, err := NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1})
+		require.NoError(t, err)
+
+		_, err = encryptor.Decrypt("key1", []byte("short"))
+		require.EqualError(t, err, "ciphertext is shorter than nonce")
+	})
+
+	t.Run("ConstructorErrors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewAESGCMArgsEncryptor("", map[string][]byte{"key1": key1})
+		require.EqualError(t, err, "current key ID cannot be empty")
+
+		_, err = NewAESGCMArgsEncryptor("key1", map[string][]byte{"key2": key2})
+		require.EqualError(t, err, `current key ID "key1" not found in keys`)
+
+		_, err = NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": key1, "": key2})
+		require.EqualError(t, err, "key ID cannot be empty")
+
+		_, err = NewAESGCMArgsEncryptor("key1", map[string][]byte{"key1": []byte("too short")})
+		require.ErrorContains(t, err, `error creating cipher for key "key1"`)
+	})
+}
@@ -0,0 +1,58 @@
+package river
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// setArgsVersion records the current version of the given args in job
+// metadata if they implement JobArgsWithVersion.
+func setArgsVersion(metadata []byte, args JobArgs) ([]byte, error) {
+	argsWithVersion, ok := args.(JobArgsWithVersion)
+	if !ok {
+		return metadata, nil
+	}
+
+	metadata, err := sjson.SetBytes(metadata, rivertype.MetadataKeyArgsVersion, argsWithVersion.ArgsVersion())
+	if err != nil {
+		return nil, fmt.Errorf("error setting args version in metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// argsUpgradeFunc returns a function that upgrades the encoded args of the
+// given job to the current version of args if they implement
+// JobArgsWithVersion and the job was inserted with an older version, or nil
+// if no upgrade is needed.
+func argsUpgradeFunc(jobRow *rivertype.JobRow, args JobArgs) func(data []byte) ([]byte, error) {
+	argsWithVersion, ok := args.(JobArgsWithVersion)
+	if !ok {
+		return nil
+	}
+
+	var (
+		currentVersion = argsWithVersion.ArgsVersion()
+		version        = int(gjson.GetBytes(jobRow.Metadata, rivertype.MetadataKeyArgsVersion).Int())
+	)
+	switch {
+	case version == currentVersion:
+		return nil
+	case version > currentVersion:
+		return func(data []byte) ([]byte, error) {
+			return nil, fmt.Errorf("job of kind %q has args version %d, newer than current version %d", jobRow.Kind, version, currentVersion)
+		}
+	}
+
+	return func(data []byte) ([]byte, error) {
+		upgraded, err := argsWithVersion.UpgradeArgs(version, data)
+		if err != nil {
+			return nil, fmt.Errorf("error upgrading args of job of kind %q from version %d to %d: %w", jobRow.Kind, version, currentVersion, err)
+		}
+		return upgraded, nil
+	}
+}
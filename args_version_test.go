@@ -0,0 +1,153 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// versionedArgsV1 is the original shape of versionedArgs, before it was
+// versioned.
+type versionedArgsV1 struct {
+	To string `json:"to"`
+}
+
+func (versionedArgsV1) Kind() string { return "versioned" }
+
+type versionedArgs struct {
+	To []string `json:"to"`
+}
+
+func (versionedArgs) Kind() string { return "versioned" }
+
+func (versionedArgs) ArgsVersion() int { return 2 }
+
+func (versionedArgs) UpgradeArgs(version int, encodedArgs []byte) ([]byte, error) {
+	if version < 2 {
+		to := gjson.GetBytes(encodedArgs, "to")
+		if !to.Exists() {
+			return nil, errors.New("missing to")
+		}
+		return sjson.SetBytes(encodedArgs, "to", []string{to.String()})
+	}
+	return encodedArgs, nil
+}
+
+func TestSetArgsVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RecordsVersion", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := setArgsVersion([]byte(`{"foo":"bar"}`), versionedArgs{})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar","river:args_version":2}`, string(metadata))
+	})
+
+	t.Run("UnversionedArgs", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := setArgsVersion([]byte(`{}`), versionedArgsV1{})
+		require.NoError(t, err)
+		require.JSONEq(t, `{}`, string(metadata))
+	})
+}
+
+func TestArgsUpgradeFunc(t *testing.T) {
+	t.Parallel()
+
+	jobRowWithVersion := func(version int) *rivertype.JobRow {
+		metadata := []byte(`{}`)
+		if version > 0 {
+			metadata, _ = sjson.SetBytes(metadata, rivertype.MetadataKeyArgsVersion, version)
+		}
+		return &rivertype.JobRow{Kind: "versioned", Metadata: metadata}
+	}
+
+	t.Run("UpgradesUnversionedJob", func(t *testing.T) {
+		t.Parallel()
+
+		upgrade := argsUpgradeFunc(jobRowWithVersion(0), versionedArgs{})
+		require.NotNil(t, upgrade)
+
+		upgraded, err := upgrade([]byte(`{"to":"a@example.com"}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"to":["a@example.com"]}`, string(upgraded))
+	})
+
+	t.Run("CurrentVersion", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, argsUpgradeFunc(jobRowWithVersion(2), versionedArgs{}))
+	})
+
+	t.Run("UnversionedArgs", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, argsUpgradeFunc(jobRowWithVersion(0), versionedArgsV1{}))
+	})
+
+	t.Run("NewerVersionError", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := argsUpgradeFunc(jobRowWithVersion(3), versionedArgs{})([]byte(`{}`))
+		require.EqualError(t, err, `job of kind "versioned" has args version 3, newer than current version 2`)
+	})
+
+	t.Run("UpgradeError", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := argsUpgradeFunc(jobRowWithVersion(1), versionedArgs{})([]byte(`{}`))
+		require.EqualError(t, err, `error upgrading args of job of kind "versioned" from version 1 to 2: missing to`)
+	})
+}
+
+func Test_Client_ArgsVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		dbPool     = riversharedtest.DBPool(ctx, t)
+		driver     = riverpgxv5.New(dbPool)
+		schema     = riverdbtest.TestSchema(ctx, t, driver, nil)
+		config     = newTestConfig(t, schema)
+		workedChan = make(chan versionedArgs, 2)
+	)
+
+	AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[versionedArgs]) error {
+		workedChan <- job.Args
+		return nil
+	}))
+
+	client := newTestClient(t, dbPool, config)
+
+	// Insert a job with the args' original shape, then one with the current.
+	insertRes, err := client.Insert(ctx, versionedArgsV1{To: "a@example.com"}, nil)
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsVersion).Exists())
+
+	insertRes, err = client.Insert(ctx, versionedArgs{To: []string{"b@example.com"}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyArgsVersion).Int())
+
+	startClient(ctx, t, client)
+
+	worked := []versionedArgs{
+		riversharedtest.WaitOrTimeout(t, workedChan),
+		riversharedtest.WaitOrTimeout(t, workedChan),
+	}
+	require.ElementsMatch(t, []versionedArgs{
+		{To: []string{"a@example.com"}},
+		{To: []string{"b@example.com"}},
+	}, worked)
+}
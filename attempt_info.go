@@ -0,0 +1,47 @@
+package river
+
+import (
+	"os"
+	"runtime/debug"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const riverModulePath = "github.com/riverqueue/river"
+
+// newAttemptInfo returns the attempt info fields that are the same for every
+// attempt worked by a client with the given ID, recorded with
+// Config.RecordAttemptInfo.
+func newAttemptInfo(clientID string) *rivertype.AttemptInfo {
+	hostname, _ := os.Hostname()
+
+	return &rivertype.AttemptInfo{
+		ClientID:      clientID,
+		ClientVersion: riverVersion(),
+		Hostname:      hostname,
+		PID:           os.Getpid(),
+	}
+}
+
+// riverVersion returns the version of River that the program was built with
+// according to its build info, or an empty string if it couldn't be
+// determined, like when River is the main module or is replaced with a local
+// copy.
+func riverVersion() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, dep := range buildInfo.Deps {
+		if dep.Path != riverModulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+
+	return ""
+}
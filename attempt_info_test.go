@@ -0,0 +1,71 @@
+package river
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestNewAttemptInfo(t *testing.T) {
+	t.Parallel()
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	attemptInfo := newAttemptInfo("client")
+	require.Equal(t, "client", attemptInfo.ClientID)
+	require.Equal(t, hostname, attemptInfo.Hostname)
+	require.Equal(t, os.Getpid(), attemptInfo.PID)
+	require.Empty(t, attemptInfo.ClientVersion) // River is the main module in its own tests
+}
+
+func Test_Client_RecordAttemptInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.RecordAttemptInfo = true
+
+		client := runNewTestClient(ctx, t, config)
+
+		subscribeChan := subscribe(t, client)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+
+		attemptInfos := event.Job.AttemptInfos()
+		require.Len(t, attemptInfos, 1)
+		require.Equal(t, 1, attemptInfos[0].Attempt)
+		require.Equal(t, client.ID(), attemptInfos[0].ClientID)
+		require.Equal(t, os.Getpid(), attemptInfos[0].PID)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+
+		client := runNewTestClient(ctx, t, config)
+
+		subscribeChan := subscribe(t, client)
+
+		_, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Nil(t, event.Job.AttemptInfos())
+	})
+}
@@ -0,0 +1,123 @@
+package river
+
+import (
+	"github.com/riverqueue/river/internal/dblist"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// JobChangeQueueParams specifies the parameters for a JobChangeQueue query. It
+// must be initialized with NewJobChangeQueueParams. Params can be built by
+// chaining methods on the JobChangeQueueParams object:
+//
+//	params := NewJobChangeQueueParams().Kinds("email_send").Queues("default")
+type JobChangeQueueParams struct {
+	ids        []int64
+	kinds      []string
+	priorities []int16
+	queues     []string
+	schema     string
+	states     []rivertype.JobState
+	unsafeAll  bool
+}
+
+// NewJobChangeQueueParams creates a new JobChangeQueueParams to move jobs to a
+// different queue.
+func NewJobChangeQueueParams() *JobChangeQueueParams {
+	return &JobChangeQueueParams{}
+}
+
+func (p *JobChangeQueueParams) copy() *JobChangeQueueParams {
+	return &JobChangeQueueParams{
+		ids:        append([]int64(nil), p.ids...),
+		kinds:      append([]string(nil), p.kinds...),
+		priorities: append([]int16(nil), p.priorities...),
+		queues:     append([]string(nil), p.queues...),
+		schema:     p.schema,
+		states:     append([]rivertype.JobState(nil), p.states...),
+		unsafeAll:  p.unsafeAll,
+	}
+}
+
+func (p *JobChangeQueueParams) filtersEmpty() bool {
+	return len(p.ids) < 1 &&
+		len(p.kinds) < 1 &&
+		len(p.priorities) < 1 &&
+		len(p.queues) < 1 &&
+		len(p.states) < 1
+}
+
+func (p *JobChangeQueueParams) toDBParams(limit int32) *dblist.JobListParams {
+	return &dblist.JobListParams{
+		IDs:        p.ids,
+		Kinds:      p.kinds,
+		LimitCount: limit,
+		OrderBy:    []dblist.JobListOrderBy{{Expr: "id", Order: dblist.SortOrderAsc}},
+		Priorities: p.priorities,
+		Queues:     p.queues,
+		Schema:     p.schema,
+		States:     p.states,
+	}
+}
+
+// IDs returns an updated filter set that will only move jobs with the given
+// IDs.
+func (p *JobChangeQueueParams) IDs(ids ...int64) *JobChangeQueueParams {
+	paramsCopy := p.copy()
+	paramsCopy.ids = make([]int64, len(ids))
+	copy(paramsCopy.ids, ids)
+	return paramsCopy
+}
+
+// Kinds returns an updated filter set that will only move jobs of the given
+// kinds.
+func (p *JobChangeQueueParams) Kinds(kinds ...string) *JobChangeQueueParams {
+	paramsCopy := p.copy()
+	paramsCopy.kinds = make([]string, len(kinds))
+	copy(paramsCopy.kinds, kinds)
+	return paramsCopy
+}
+
+// Priorities returns an updated filter set that will only move jobs with the
+// given priorities.
+func (p *JobChangeQueueParams) Priorities(priorities ...int16) *JobChangeQueueParams {
+	paramsCopy := p.copy()
+	paramsCopy.priorities = make([]int16, len(priorities))
+	copy(paramsCopy.priorities, priorities)
+	return paramsCopy
+}
+
+// Queues returns an updated filter set that will only move jobs from the given
+// queues.
+func (p *JobChangeQueueParams) Queues(queues ...string) *JobChangeQueueParams {
+	paramsCopy := p.copy()
+	paramsCopy.queues = make([]string, len(queues))
+	copy(paramsCopy.queues, queues)
+	return paramsCopy
+}
+
+// States returns an updated filter set that will only move jobs in the given
+// states.
+func (p *JobChangeQueueParams) States(states ...rivertype.JobState) *JobChangeQueueParams {
+	paramsCopy := p.copy()
+	paramsCopy.states = make([]rivertype.JobState, len(states))
+	copy(paramsCopy.states, states)
+	return paramsCopy
+}
+
+// UnsafeAll is a special directive that allows every non-running job to be
+// moved without any filters. Normally, filters like Queues or Kinds are
+// required to scope down the change so that the caller doesn't accidentally
+// move all jobs into a single queue. Invoking UnsafeAll removes this safety
+// guard.
+//
+// It only makes sense to call this function if no filters have yet been applied
+// on the parameters object. If some have already, calling it will panic.
+func (p *JobChangeQueueParams) UnsafeAll() *JobChangeQueueParams {
+	if !p.filtersEmpty() {
+		panic("UnsafeAll no longer meaningful with non-default filters applied")
+	}
+
+	paramsCopy := p.copy()
+	paramsCopy.unsafeAll = true
+	return paramsCopy
+}
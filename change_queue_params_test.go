@@ -0,0 +1,31 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestJobChangeQueueParams_filtersEmpty(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, NewJobChangeQueueParams().filtersEmpty())
+
+	require.False(t, NewJobChangeQueueParams().IDs(123).filtersEmpty())
+	require.False(t, NewJobChangeQueueParams().Kinds("kind").filtersEmpty())
+	require.False(t, NewJobChangeQueueParams().Priorities(1).filtersEmpty())
+	require.False(t, NewJobChangeQueueParams().Queues("queues").filtersEmpty())
+	require.False(t, NewJobChangeQueueParams().States(rivertype.JobStateAvailable).filtersEmpty())
+}
+
+func TestJobChangeQueueParams_UnsafeAll(t *testing.T) {
+	t.Parallel()
+
+	NewJobChangeQueueParams().UnsafeAll()
+
+	require.PanicsWithValue(t, "UnsafeAll no longer meaningful with non-default filters applied", func() {
+		NewJobChangeQueueParams().IDs(123).UnsafeAll()
+	})
+}
@@ -0,0 +1,106 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Checkpoint immediately persists a checkpoint for a job that's being worked,
+// like a cursor marking how far through a large batch of work it's gotten. If
+// the attempt fails or the client working it crashes, the job's next attempt
+// can read the checkpoint back with Job.Checkpoint (or rivertype.MetadataAs)
+// and continue from where the last attempt left off instead of starting over:
+//
+//	func (w *BackfillWorker) Work(ctx context.Context, job *river.Job[BackfillArgs]) error {
+//		var lastID int64
+//		if checkpoint := job.Checkpoint(); checkpoint != nil {
+//			if err := json.Unmarshal(checkpoint, &lastID); err != nil {
+//				return err
+//			}
+//		}
+//
+//		for {
+//			ids, err := backfillBatch(ctx, lastID)
+//			...
+//			lastID = ids[len(ids)-1]
+//			if err := river.Checkpoint(ctx, job, lastID); err != nil {
+//				return err
+//			}
+//		}
+//	}
+//
+// The checkpoint may be any JSON-encodable value. It's stored in the job's
+// metadata under rivertype.MetadataKeyCheckpoint, replacing any checkpoint
+// persisted previously, and is kept after the job finishes. Unlike
+// ResumableSetCursor, which is stored only when an attempt ends in an error,
+// each call writes to the database, so checkpoints survive a crash, but
+// should be taken at a reasonable interval rather than for every item
+// processed.
+//
+// This function must be called within a Worker's Work function with the job
+// being worked. It returns an error if called anywhere else.
+func Checkpoint[TArgs JobArgs](ctx context.Context, job *Job[TArgs], checkpoint any) error {
+	checkpointer, ok := ctx.Value(rivercommon.ContextKeyClient{}).(jobCheckpointer)
+	if !ok {
+		return errClientNotInContext
+	}
+
+	if job.State != rivertype.JobStateRunning {
+		return errors.New("job must be running")
+	}
+
+	checkpointBytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	updatedJob, err := checkpointer.jobCheckpoint(ctx, job.ID, checkpointBytes)
+	if err != nil {
+		return err
+	}
+
+	// Also include the checkpoint in updates made when the job is completed so
+	// that they're consistent with what was persisted here.
+	if metadataUpdates, ok := jobexecutor.MetadataUpdatesFromWorkContext(ctx); ok {
+		metadataUpdates[rivertype.MetadataKeyCheckpoint] = json.RawMessage(checkpointBytes)
+	}
+
+	job.Metadata = updatedJob.Metadata
+	return nil
+}
+
+// jobCheckpointer is implemented by Client so that Checkpoint can be used
+// without knowing the client's transaction type.
+type jobCheckpointer interface {
+	jobCheckpoint(ctx context.Context, id int64, checkpoint json.RawMessage) (*rivertype.JobRow, error)
+}
+
+func (c *Client[TTx]) jobCheckpoint(ctx context.Context, id int64, checkpoint json.RawMessage) (*rivertype.JobRow, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	metadataUpdates, err := json.Marshal(map[string]json.RawMessage{rivertype.MetadataKeyCheckpoint: checkpoint})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := c.driver.GetExecutor().JobUpdate(ctx, &riverdriver.JobUpdateParams{
+		ID:              id,
+		Metadata:        metadataUpdates,
+		MetadataDoMerge: true,
+		Schema:          c.config.Schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error persisting checkpoint: %w", err)
+	}
+
+	return job, nil
+}
@@ -0,0 +1,103 @@
+package river
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		client *Client[pgx.Tx]
+		schema string
+	}
+
+	setup := func(t *testing.T) (context.Context, *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+		)
+
+		return withClient(ctx, client), &testBundle{client: client, schema: schema}
+	}
+
+	insertJob := func(t *testing.T, bundle *testBundle, state rivertype.JobState) *Job[noOpArgs] {
+		t.Helper()
+
+		jobRow := testfactory.Job(ctx, t, bundle.client.driver.GetExecutor(), &testfactory.JobOpts{
+			Metadata: []byte(`{"foo":"bar"}`),
+			Schema:   bundle.schema,
+			State:    ptrutil.Ptr(state),
+		})
+		return &Job[noOpArgs]{JobRow: jobRow}
+	}
+
+	t.Run("PersistsCheckpoint", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx, bundle := setup(t)
+
+		job := insertJob(t, bundle, rivertype.JobStateRunning)
+		require.Nil(t, job.Checkpoint())
+
+		require.NoError(t, Checkpoint(workCtx, job, map[string]int{"last_id": 123}))
+		require.JSONEq(t, `{"last_id":123}`, string(job.Checkpoint()))
+
+		updatedJob, err := bundle.client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"last_id":123}`, string(updatedJob.Checkpoint()))
+
+		// Other metadata is left intact.
+		require.JSONEq(t, `{"foo":"bar","river:checkpoint":{"last_id":123}}`, string(updatedJob.Metadata))
+	})
+
+	t.Run("ReplacesPreviousCheckpoint", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx, bundle := setup(t)
+
+		job := insertJob(t, bundle, rivertype.JobStateRunning)
+
+		require.NoError(t, Checkpoint(workCtx, job, map[string]int{"last_id": 123}))
+		require.NoError(t, Checkpoint(workCtx, job, map[string]int{"last_id": 456}))
+
+		updatedJob, err := bundle.client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"last_id":456}`, string(updatedJob.Checkpoint()))
+	})
+
+	t.Run("JobNotRunningError", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx, bundle := setup(t)
+
+		job := insertJob(t, bundle, rivertype.JobStateAvailable)
+
+		require.EqualError(t, Checkpoint(workCtx, job, 123), "job must be running")
+	})
+
+	t.Run("NoClientInContextError", func(t *testing.T) {
+		t.Parallel()
+
+		job := &Job[noOpArgs]{JobRow: &rivertype.JobRow{State: rivertype.JobStateRunning}}
+
+		require.ErrorIs(t, Checkpoint(ctx, job, 123), errClientNotInContext)
+	})
+}
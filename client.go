@@ -9,10 +9,13 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/dblist"
 	"github.com/riverqueue/river/internal/dbunique"
 	"github.com/riverqueue/river/internal/hooklookup"
@@ -27,12 +30,15 @@ import (
 	"github.com/riverqueue/river/internal/rivermiddleware"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riverpilot"
 	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
 	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/uniquestates"
 	"github.com/riverqueue/river/rivershared/util/dbutil"
 	"github.com/riverqueue/river/rivershared/util/maputil"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
 	"github.com/riverqueue/river/rivershared/util/sliceutil"
 	"github.com/riverqueue/river/rivershared/util/testutil"
 	"github.com/riverqueue/river/rivershared/util/valutil"
@@ -46,6 +52,8 @@ const (
 	FetchPollIntervalDefault = 1 * time.Second
 	FetchPollIntervalMin     = 1 * time.Millisecond
 
+	InsertManyCopyFromThresholdDefault = 10_000
+
 	JobTimeoutDefault  = 1 * time.Minute
 	MaxAttemptsDefault = rivercommon.MaxAttemptsDefault
 	PriorityDefault    = rivercommon.PriorityDefault
@@ -78,6 +86,18 @@ type TestConfig struct {
 	// when creating jobs.
 	DisableUniqueEnforcement bool
 
+	// JobIDFunc, if set, is invoked to generate an ID for every job inserted
+	// by the client instead of deferring to the database's ID sequence. Used
+	// in conjunction with Time and a fixed Config.ID (which is what's appended
+	// to a job's `attempted_by`), it makes inserted rows and event payloads
+	// fully deterministic so they can be compared against snapshots.
+	//
+	// Generated IDs must be unique, and are best drawn from a range that
+	// won't collide with IDs that the database's sequence might hand out.
+	// They're not used by InsertManyFast on drivers that insert with `COPY
+	// FROM`, which can't assign an explicit ID.
+	JobIDFunc func() int64
+
 	// Time is a time generator to make time stubbable in tests.
 	Time rivertype.TimeGenerator
 }
@@ -90,6 +110,23 @@ type TestConfig struct {
 // it so River can check that inserted job kinds have a worker that can run
 // them.
 type Config struct {
+	// AdaptiveFetchBatchSize causes each queue's producer to size its fetches
+	// according to recent throughput and the backlog of job completions
+	// waiting to be written to the database, rather than always fetching as
+	// many jobs as it has free worker slots. Fetches grow quickly while they
+	// come back full so that a backlog of jobs is worked through with fewer
+	// round trips, and shrink to roughly the number of jobs the producer
+	// finishes in a fetch poll interval when they come back short, so that a
+	// lightly loaded producer doesn't lock more jobs than it needs. While the
+	// completion backlog is more than half full, fetches are shrunk so that
+	// the client doesn't pile on more work while it's falling behind.
+	//
+	// Batch sizes never exceed a queue's MaxWorkers. Statistics on the sizes
+	// chosen are available in HealthStatus.FetchBatchSizes.
+	//
+	// Defaults to false.
+	AdaptiveFetchBatchSize bool
+
 	// AdvisoryLockPrefix is a configurable 32-bit prefix that River will use
 	// when generating any key to acquire a Postgres advisory lock. All advisory
 	// locks share the same 64-bit number space, so this allows a calling
@@ -108,6 +145,46 @@ type Config struct {
 	// are omitted from a customized ByState configuration.
 	AdvisoryLockPrefix int32
 
+	// ArgsCodec encodes the args of inserted jobs in place of JSON, like a
+	// msgpack or protobuf codec for args that are large or binary and
+	// expensive to roundtrip through JSON. The codec's name is recorded in the
+	// metadata of each inserted job so that it's decoded with the same codec
+	// when worked, and jobs inserted without a codec continue to be decoded as
+	// JSON.
+	//
+	// Every client that may work jobs inserted with the codec must be able to
+	// decode them. When rolling out a codec to an existing cluster, configure it
+	// in ArgsCodecsDecodeOnly on all clients first, and only then in ArgsCodec.
+	//
+	// Defaults to nil, which encodes args as JSON.
+	ArgsCodec ArgsCodec
+
+	// ArgsCodecsDecodeOnly are codecs used to decode the args of jobs inserted
+	// with them, but never to encode args. See ArgsCodec.
+	ArgsCodecsDecodeOnly []ArgsCodec
+
+	// ArgsCompression enables compression of the args of inserted jobs whose
+	// encoded size reaches a threshold. See ArgsCompressionConfig.
+	//
+	// Defaults to nil, which never compresses args. Args compressed with the
+	// built-in gzip compressor are decompressed regardless.
+	ArgsCompression *ArgsCompressionConfig
+
+	// ArgsEncryptor encrypts the args of inserted jobs before they're stored,
+	// and decrypts them before they're decoded to be worked, for args that
+	// contain sensitive data like PII. The ID of the key that args were
+	// encrypted with is recorded in the metadata of each inserted job so that
+	// it can be decrypted with the same key, and jobs inserted without
+	// encryption continue to be decoded as normal.
+	//
+	// Every client that may work jobs with encrypted args must have an
+	// encryptor configured that can decrypt them. Jobs encrypted with a key
+	// other than the current one are periodically re-encrypted with it by a
+	// maintenance service. See ArgsEncryptor and AESGCMArgsEncryptor.
+	//
+	// Defaults to nil, which doesn't encrypt args.
+	ArgsEncryptor ArgsEncryptor
+
 	// CancelledJobRetentionPeriod is the amount of time to keep cancelled jobs
 	// around before they're removed permanently.
 	//
@@ -124,6 +201,58 @@ type Config struct {
 	// Defaults to 24 hours.
 	CompletedJobRetentionPeriod time.Duration
 
+	// CompleterBackpressure enables backpressure that pauses fetching of new
+	// jobs while the backlog of job completions waiting to be written to the
+	// database is large, rather than continuing to pile on more work while
+	// the database is falling behind. Events are emitted when backpressure
+	// engages and releases, and its state is available in
+	// HealthStatus.CompleterBackpressure. See CompleterBackpressureConfig.
+	//
+	// Defaults to nil, which disables backpressure.
+	CompleterBackpressure *CompleterBackpressureConfig
+
+	// ControlHandlers are handlers for custom control messages sent with
+	// ClientNotifyBundle.Control, keyed by message name. Messages are received
+	// by started clients with a notifier (i.e. not in PollOnly mode), including
+	// read-only clients. Messages with a name that has no handler are ignored.
+	ControlHandlers map[string]ControlHandlerFunc
+
+	// DatabaseCircuitBreaker configures a circuit breaker that puts the client
+	// into a degraded mode during a database outage instead of having every
+	// subsystem fail operations and log errors independently. The circuit
+	// opens after sustained failures from pool exhaustion or connection
+	// failures, as reported by EventKindDatabaseDegraded events. While it's
+	// open:
+	//
+	//   - Producers stop fetching jobs. Jobs already running continue.
+	//   - Completions are held in the completer's backlog, up to its maximum
+	//     size, after which jobs finishing work wait to be completed.
+	//   - Maintenance work like job cleaning and rescuing is skipped.
+	//
+	// The database is probed periodically, and the circuit closes once it's
+	// reachable, after which held completions are flushed and fetching
+	// resumes. If the client stops while the circuit is open, held
+	// completions aren't sent, and their jobs are left running to be rescued
+	// by the rescuer. EventKindDatabaseCircuitOpened and
+	// EventKindDatabaseCircuitClosed events are sent on each transition, and
+	// HealthStatus.DatabaseCircuitOpen reports the circuit's current state.
+	//
+	// Only has an effect on clients that work jobs. Setting it implies
+	// DatabaseDegradationEvents. Defaults to nil, in which case there's no
+	// circuit breaker.
+	DatabaseCircuitBreaker *DatabaseCircuitBreaker
+
+	// DatabaseDegradationEvents enables tracking of the database operations of
+	// the client's subsystems so that EventKindDatabaseDegraded and
+	// EventKindDatabaseRecovered events are sent when one of them starts
+	// failing because of pool exhaustion or connection failures, and when it
+	// recovers.
+	//
+	// Only has an effect on clients that work jobs. Implied by
+	// DatabaseCircuitBreaker and ShedMaintenanceOnDatabaseDegradation.
+	// Defaults to false.
+	DatabaseDegradationEvents bool
+
 	// DiscardedJobRetentionPeriod is the amount of time to keep discarded jobs
 	// around before they're removed permanently.
 	//
@@ -132,11 +261,84 @@ type Config struct {
 	// Defaults to 7 days.
 	DiscardedJobRetentionPeriod time.Duration
 
+	// DriverOperationTimeouts are optional timeouts for classes of database
+	// operations, applied as context deadlines on each operation in the class.
+	// They allow a slow class of operation, like a list query with an
+	// expensive filter, to be cut short without holding a connection for
+	// minutes, while other classes like fetches stay snappy:
+	//
+	//	DriverOperationTimeouts: map[river.DriverOperation]time.Duration{
+	//		river.DriverOperationFetch: 2 * time.Second,
+	//		river.DriverOperationList:  10 * time.Second,
+	//	},
+	//
+	// A timeout only shortens an operation's deadline, so an operation whose
+	// context already has an earlier deadline keeps it. Timeouts apply to
+	// operations run by the client and through its API, including those in a
+	// transaction passed to a Tx method. Classes without a timeout, or with a
+	// timeout of zero, have no deadline beyond the one of their context.
+	//
+	// Defaults to no timeouts.
+	DriverOperationTimeouts map[DriverOperation]time.Duration
+
+	// DriverRetryPolicy configures automatic retries of database operations
+	// that fail with a transient error, like a serialization failure, a
+	// deadlock, or a connection that was reset before a statement was sent.
+	// Such errors wrap riverdriver.ErrTransient.
+	//
+	//	DriverRetryPolicy: &river.DriverRetryPolicy{MaxAttempts: 5},
+	//
+	// Only operations outside of a transaction are retried, because a failed
+	// statement aborts the transaction it's in. Counts of retries are reported
+	// in HealthStatus.DriverRetries.
+	//
+	// Defaults to nil, in which case operations aren't retried.
+	DriverRetryPolicy *DriverRetryPolicy
+
+	// DriverStatementTimeouts are optional Postgres `statement_timeout` and
+	// `lock_timeout` settings for classes of database operations. Unlike
+	// DriverOperationTimeouts, they're enforced by Postgres itself, so they
+	// bound a runaway statement even if the client loses track of it, and a
+	// lock timeout keeps an operation from queueing behind a long-held lock:
+	//
+	//	DriverStatementTimeouts: map[river.DriverOperation]river.DriverStatementTimeouts{
+	//		river.DriverOperationMaintenance: {LockTimeout: time.Second, StatementTimeout: 30 * time.Second},
+	//	},
+	//
+	// Settings are applied only for the duration of each operation. Operations
+	// not already in a transaction are run in one so that settings are scoped
+	// to them, which costs extra round trips to the database. Timeouts are
+	// rounded down to the millisecond. An operation cancelled by one of these
+	// timeouts returns an error wrapping riverdriver.ErrStatementTimeout, and
+	// maintenance services respond to it by reducing their batch size.
+	//
+	// Only supported by Postgres drivers. Defaults to no timeouts.
+	DriverStatementTimeouts map[DriverOperation]DriverStatementTimeouts
+
 	// ErrorHandler can be configured to be invoked in case of an error or panic
 	// occurring in a job. This is often useful for logging and exception
 	// tracking, but can also be used to customize retry behavior.
 	ErrorHandler ErrorHandler
 
+	// ErrorSizeLimits configures maximum sizes for the error messages and
+	// panic traces recorded to a job's errors when an attempt fails. Values
+	// that are too large are truncated, keeping their head and tail. See
+	// ErrorSizeLimits.
+	//
+	// Defaults to nil, which applies no limits.
+	ErrorSizeLimits *ErrorSizeLimits
+
+	// ExternalIDFunc generates an external ID for each inserted job that
+	// doesn't have one set with InsertOpts.ExternalID. External IDs are unique
+	// across all jobs and can be exposed in external APIs in place of
+	// sequential job IDs. Jobs can be looked up by them with
+	// Client.JobGetByExternalID. ExternalIDUUIDv7 is a built-in generator
+	// that produces globally unique, time ordered IDs.
+	//
+	// Defaults to nil, in which case jobs only have external IDs when one is
+	// set explicitly on insert.
+	ExternalIDFunc func() string
+
 	// FetchCooldown is the minimum amount of time to wait between fetches of new
 	// jobs. Jobs will only be fetched *at most* this often, but if no new jobs
 	// are coming in via LISTEN/NOTIFY then fetches may be delayed as long as
@@ -178,6 +380,49 @@ type Config struct {
 	// If in doubt, leave this property empty.
 	ID string
 
+	// InsertAsync configures how jobs inserted with Client.InsertAsync are
+	// batched. See InsertAsyncConfig.
+	//
+	// Defaults to batches of up to 1,000 jobs inserted at least every 100
+	// milliseconds.
+	InsertAsync *InsertAsyncConfig
+
+	// InsertManyCopyFromThreshold is the number of jobs at or above which
+	// InsertMany and InsertManyTx send jobs to the database using COPY FROM
+	// instead of a multi-row insert. COPY FROM is considerably faster and uses
+	// less memory for very large batches, but has a small fixed overhead that
+	// makes it slower for small ones. Unique jobs and the returned results
+	// are handled the same either way.
+	//
+	// Only used with drivers that support COPY FROM (currently riverpgxv5).
+	// Set to -1 to always use a multi-row insert.
+	//
+	// Defaults to 10,000.
+	InsertManyCopyFromThreshold int
+
+	// InsertOptsByKind maps job kinds to default insert options for jobs of
+	// that kind. It's useful for centrally routing kinds to queues or setting
+	// their priority without having to implement JobArgsWithInsertOpts on
+	// every args type:
+	//
+	//	InsertOptsByKind: map[string]river.InsertOpts{
+	//		"email":  {Queue: "email", Priority: 2},
+	//		"report": {MaxAttempts: 5, Queue: "reports"},
+	//	},
+	//
+	// Options are applied only where neither the options given at insertion
+	// time nor those from the job args (from JobArgsWithInsertOpts or a
+	// `river` struct tag) specify a value, and take precedence over client
+	// defaults like MaxAttempts. Only MaxAttempts, Priority, Queue,
+	// RequiredLabels, Tags, and UniqueOpts may be set.
+	InsertOptsByKind map[string]InsertOpts
+
+	// InsertParamsMiddleware are middleware that inspect and optionally
+	// mutate the args and insert options of each job before it's inserted,
+	// invoked in order. An error from any middleware aborts the insert. See
+	// InsertParamsMiddleware.
+	InsertParamsMiddleware []InsertParamsMiddleware
+
 	// JobCleanerTimeout is the timeout of the individual queries within the job
 	// cleaner.
 	//
@@ -221,6 +466,64 @@ type Config struct {
 	// Jobs may have their own specific hooks by implementing JobArgsWithHooks.
 	Hooks []rivertype.Hook
 
+	// Labels are arbitrary key/value pairs describing this client, like
+	// `gpu=true` or `region=eu`. Jobs inserted with InsertOpts.RequiredLabels
+	// are only fetched by clients whose labels include all of them with
+	// matching values, which allows jobs to be routed to a subset of a
+	// heterogeneous fleet of clients more strictly than queues do. Jobs
+	// without required labels are fetched by any client, regardless of its
+	// labels.
+	//
+	// Defaults to no labels.
+	Labels map[string]string
+
+	// LeaderElectionInterval is the interval on which clients that aren't the
+	// leader attempt to become leader. A small amount of random jitter is
+	// added to each attempt so that clients don't all contest leadership at
+	// once. Clients are also notified when a leader resigns (except in
+	// PollOnly mode), so this mainly governs how quickly a new leader is
+	// elected after one disappears without resigning.
+	//
+	// Longer intervals reduce load on the `river_leader` table in large
+	// clusters at the cost of slower failover.
+	//
+	// Defaults to 5 seconds.
+	LeaderElectionInterval time.Duration
+
+	// LeaderElectionPriority is this client's preference for becoming leader
+	// relative to other clients. A client whose priority is higher than the
+	// current leader's asks it to resign and takes over, and clients without
+	// a priority hold back slightly when bidding after a resignation so that
+	// prioritized clients are likely to win. This is useful to designate a
+	// node with more headroom to run maintenance services while it's healthy,
+	// with others only taking over if it fails.
+	//
+	// Clients with equal priorities have an equal chance of being elected.
+	// Preemption relies on a notifier, so it's not available in PollOnly mode,
+	// although prioritized clients still get a head start after resignations
+	// in that case.
+	//
+	// Defaults to 0, for no preference.
+	LeaderElectionPriority int
+
+	// LeaderReelectionInterval is the interval on which the leader reelects
+	// itself to extend its term. It must be shorter than LeaderTTL, and should
+	// be short enough to leave room for a few failed reelection attempts
+	// within a term.
+	//
+	// Defaults to 5 seconds.
+	LeaderReelectionInterval time.Duration
+
+	// LeaderTTL is the length of a leadership term, after which a leader that
+	// hasn't reelected itself (say because it crashed) is considered gone and
+	// other clients may become leader. Together with LeaderElectionInterval,
+	// it bounds how long a cluster goes without a leader running maintenance
+	// services after a leader disappears. Must be longer than
+	// LeaderReelectionInterval.
+	//
+	// Defaults to LeaderReelectionInterval plus 10 seconds.
+	LeaderTTL time.Duration
+
 	// Logger is the structured logger to use for logging purposes. If none is
 	// specified, logs will be emitted to STDOUT with messages at warn level
 	// or higher.
@@ -273,6 +576,36 @@ type Config struct {
 	// pooling mode.
 	PollOnly bool
 
+	// PayloadSizeLimits configures maximum sizes for job args and metadata,
+	// enforced on insert, and what happens to payloads that exceed them. See
+	// PayloadSizeLimits.
+	//
+	// Defaults to nil, which applies no limits. Payload sizes are tracked in
+	// HealthStatus.InsertPayloadSizes regardless.
+	PayloadSizeLimits *PayloadSizeLimits
+
+	// PoisonPill enables detection of poison pill jobs, which crash the
+	// process working them every time they're worked. A job that's crashed
+	// too many times in a row is discarded instead of being retried, and an
+	// EventKindJobPoisonPill event is emitted. See PoisonPillConfig.
+	//
+	// Defaults to nil, which disables detection.
+	PoisonPill *PoisonPillConfig
+
+	// PublishJobKinds causes the client to publish the kinds of its registered
+	// workers to the job kind registry when it starts, along with a schema and
+	// version for job args implementing JobArgsWithSchema. Registrations can
+	// be queried with Client.JobKindRegistry, so producers can check that a
+	// kind is workable before inserting it and operators can see which deployed
+	// versions accept which kinds.
+	//
+	// A registration is keyed by kind and version, and is updated each time a
+	// client publishing it starts. Registrations aren't removed when a client
+	// stops or a worker is removed.
+	//
+	// Can't be combined with ReadOnly.
+	PublishJobKinds bool
+
 	// Queues is a list of queue names for this client to operate on along with
 	// configuration for the queue like the maximum number of workers to run for
 	// each queue.
@@ -281,6 +614,48 @@ type Config struct {
 	// than working them. If it's specified, then Workers must also be given.
 	Queues map[string]QueueConfig
 
+	// RateAnomalyMonitor enables a monitor that emits EventKindRateAnomaly
+	// events when the rate at which jobs of a kind are inserted or fail rises
+	// sharply above its recent baseline, giving early warning of runaway
+	// producers or newly broken workers. Only runs on clients that work jobs,
+	// and only counts the inserts and failures of the client it runs on.
+	//
+	// Defaults to nil, which disables the monitor.
+	RateAnomalyMonitor *RateAnomalyMonitorConfig
+
+	// ReadOnly starts the client in read-only "observer" mode, in which it's
+	// guaranteed never to mutate rows. It's intended for dashboards and support
+	// tooling pointed at a production database, possibly with a role that only
+	// has read access.
+	//
+	// A read-only client can be started, but it doesn't fetch or work jobs,
+	// doesn't participate in leader election, and runs no maintenance services.
+	// Once started, it listens for control notifications so that subscriptions
+	// receive EventKindQueuePaused, EventKindQueueResumed, and
+	// EventKindQueueMetadataChanged events (for queue events triggered by
+	// pausing or resuming all queues at once, the event's queue name is "*").
+	// No events are received in poll only mode. Read APIs like JobGet,
+	// JobList, QueueGet, and QueueList work as normal, but APIs that would
+	// mutate rows like Insert, JobCancel, JobDelete, or QueuePause return
+	// ErrClientReadOnly.
+	//
+	// ReadOnly can't be combined with Queues or PeriodicJobs, and requires a
+	// driver with a database pool.
+	ReadOnly bool
+
+	// RecordAttemptInfo causes the client to record information about the
+	// environment in which each attempt of a job was worked in the job's
+	// metadata, including the client's ID and River version, the hostname and
+	// PID of its process, and how long the job waited to be worked. Useful for
+	// tracing a problem with a job back to the process and deployment that
+	// worked it without having to cross-reference deploy logs. Recorded
+	// attempts are available from JobRow.AttemptInfos.
+	//
+	// Only the most recent 25 attempts are kept. An attempt's information is
+	// recorded when it finishes, so it's not recorded for attempts whose
+	// process crashed before they could.
+	RecordAttemptInfo bool
+
 	// ReindexerSchedule is the schedule for running the reindexer. If nil, the
 	// reindexer will run at midnight UTC every day.
 	ReindexerSchedule PeriodicSchedule
@@ -297,6 +672,36 @@ type Config struct {
 	// Defaults to 1 minute.
 	ReindexerTimeout time.Duration
 
+	// ReleaseJobsOnStop causes jobs that are interrupted by the client stopping
+	// to be handed off to other clients instead of being retried with backoff.
+	//
+	// When a client hard stops (StopAndCancel, SoftStopTimeout elapsing, or
+	// cancellation of the context passed to Start), the contexts of running
+	// jobs are cancelled. Normally, a job that returns an error as a result is
+	// recorded as a failed attempt and scheduled for retry according to the
+	// retry policy. With ReleaseJobsOnStop, such a job is instead made
+	// immediately available with its attempt given back and no error recorded,
+	// so that a replacement client can pick it up right away. This smooths
+	// rolling deploys where medium-length jobs would otherwise be pushed into
+	// retry backoff every time an instance is replaced.
+	//
+	// Jobs that return successfully despite cancellation are completed as
+	// usual, and jobs that return a JobCancel error are still cancelled. Workers
+	// should be prepared for a released job to be worked again from the
+	// beginning. Because released jobs don't consume attempts, a job that can
+	// never finish inside the shutdown window can be released indefinitely.
+	ReleaseJobsOnStop bool
+
+	// ResourceGuard enables a guard that keeps the client within a budget of
+	// memory and goroutines. When usage exceeds it, the client stops fetching
+	// new jobs, and optionally interrupts running jobs, until usage subsides.
+	// Useful for clients working jobs that use a lot of memory, which could
+	// otherwise get the process killed for running out of it, losing the work
+	// of every job it was running. See ResourceGuardConfig.
+	//
+	// Defaults to nil, which disables the guard.
+	ResourceGuard *ResourceGuardConfig
+
 	// RescueStuckJobsAfter is the amount of time a job can be running before it
 	// is considered stuck. A stuck job which has not yet reached its max attempts
 	// will be scheduled for a retry, while one which has exhausted its attempts
@@ -310,6 +715,12 @@ type Config struct {
 	// will result in jobs being stuck for longer than necessary before they are
 	// retried.
 	//
+	// Jobs in queues with QueueConfig.VisibilityTimeout set hold a lease that's
+	// renewed for as long as they're running, and aren't rescued while that
+	// lease is unexpired, no matter how long they've been running. Long running
+	// jobs can be put in such a queue to avoid having to pick a horizon that
+	// exceeds their longest possible run.
+	//
 	// RescueStuckJobsAfter must be greater than JobTimeout. Otherwise, jobs
 	// would become eligible for rescue while they're still running.
 	//
@@ -317,6 +728,15 @@ type Config struct {
 	// is greater than 1 hour, JobTimeout + 1 hour.
 	RescueStuckJobsAfter time.Duration
 
+	// RetryBudget caps the total number of job retries per period across every
+	// client using the same database and schema, deferring retries beyond it
+	// until budget becomes available. It protects the database and downstream
+	// services from retry storms when a shared dependency fails and many jobs
+	// error at once. See RetryBudget.
+	//
+	// Defaults to nil, which applies no budget.
+	RetryBudget *RetryBudget
+
 	// RetryPolicy is a configurable retry policy for the client.
 	//
 	// Defaults to DefaultRetryPolicy.
@@ -329,6 +749,17 @@ type Config struct {
 	// setting of Postgres `search_path`.
 	Schema string
 
+	// ShedMaintenanceOnDatabaseDegradation causes maintenance work like job
+	// cleaning and rescuing to be skipped while any of the client's subsystems
+	// is degraded by database pool exhaustion or connection failures, as
+	// reported by EventKindDatabaseDegraded events. This leaves connections
+	// for fetching and completing jobs, which are more urgent. Maintenance
+	// resumes normally once all subsystems have recovered.
+	//
+	// Only has an effect on clients that work jobs. Setting it implies
+	// DatabaseDegradationEvents. Defaults to false.
+	ShedMaintenanceOnDatabaseDegradation bool
+
 	// SoftStopTimeout is the maximum amount of time that the client will wait
 	// for running jobs to finish during a stop before their contexts are
 	// cancelled. After the timeout elapses, the client escalates to a hard stop
@@ -375,6 +806,28 @@ type Config struct {
 	// Defaults to false.
 	SkipUnknownJobCheck bool
 
+	// SynchronousMode is a development and test mode in which inserted jobs
+	// aren't persisted, but are instead worked immediately in-process by the
+	// inserting goroutine before the insert function returns. Jobs are worked
+	// through the same hooks, middleware, and error handler as they would be
+	// by a started client, so it's useful for local development against a
+	// shared database where inserted jobs would otherwise be noisy and slow
+	// to get worked. The client doesn't need to be started.
+	//
+	// Each job is worked once, regardless of when it was scheduled or how
+	// many attempts it has. The returned insert result contains the job row
+	// as it would've been updated after being worked, so a failed job is
+	// returned with its error and in a retryable or discarded state. Jobs
+	// have synthetic IDs that are only unique within the client, and because
+	// they're never written to the database, functions that operate on them
+	// there like JobCompleteTx or JobGet won't find them. Insert functions
+	// taking a transaction ignore it.
+	//
+	// Requires Workers. Should not be used in production.
+	//
+	// Defaults to false.
+	SynchronousMode bool
+
 	// Test holds configuration specific to test environments.
 	Test TestConfig
 
@@ -389,6 +842,62 @@ type Config struct {
 	// client in a test case slower.
 	TestOnly bool
 
+	// UnknownJobKindPolicy determines what the client does when it fetches a
+	// job whose kind has no worker registered in Workers. By default, an
+	// UnknownJobKindError is recorded and the job is retried like any other
+	// failure, which consumes its attempts. Fleets where not every client has
+	// every worker registered should consider UnknownJobKindPolicyRelease
+	// instead. See UnknownJobKindPolicy's constants for options.
+	//
+	// The number of jobs with unknown kinds that the client has fetched is
+	// available in HealthStatus.UnknownJobKindsFetched.
+	//
+	// Defaults to UnknownJobKindPolicyRetry.
+	UnknownJobKindPolicy UnknownJobKindPolicy
+
+	// UnknownJobKindWorkFunc is a catch-all function that works jobs whose
+	// kind has no registered worker. It receives the job's raw row because its
+	// args type isn't known. Required when UnknownJobKindPolicy is
+	// UnknownJobKindPolicyCatchAll, and may not be set otherwise.
+	UnknownJobKindWorkFunc func(ctx context.Context, job *rivertype.JobRow) error
+
+	// WorkContext is an optional function invoked once as the client starts
+	// working jobs that derives the context from which every worked job's
+	// context is derived. It's useful for giving workers access to
+	// application services like loggers, connection pools, or feature flags
+	// through context values without resorting to a global registry or a
+	// middleware that does nothing but add them:
+	//
+	//	WorkContext: func(ctx context.Context) context.Context {
+	//		return context.WithValue(ctx, featureFlagsKey{}, featureFlags)
+	//	},
+	//
+	// The returned context must be derived from the given one, which is
+	// cancelled when the client stops and carries the client for functions
+	// like ClientFromContext and JobCompleteTx.
+	//
+	// Defaults to nil, in which case jobs' contexts carry only values added by
+	// River and middleware.
+	WorkContext func(ctx context.Context) context.Context
+
+	// WorkStealing lets a queue with a backlog borrow worker slots from the
+	// client's other queues while they're idle, so that capacity isn't left
+	// stranded when load is uneven across queues. Each queue's MaxWorkers
+	// contributes to a pool of slots shared by all of the client's queues,
+	// and a queue can work more than its MaxWorkers jobs at once by taking
+	// slots that other queues aren't using. QueueConfig.MinWorkers bounds
+	// borrowing by reserving a number of a queue's slots that are never lent
+	// out, guaranteeing that the queue can work at least that many jobs.
+	//
+	// Borrowed slots are given back as the jobs using them finish rather than
+	// by interrupting them, so a queue whose load picks up while its slots
+	// are on loan may have to wait for them beyond its MinWorkers. The total
+	// number of jobs worked by the client never exceeds the sum of its
+	// queues' MaxWorkers.
+	//
+	// Defaults to false.
+	WorkStealing bool
+
 	// Workers is a bundle of registered job workers.
 	//
 	// This field may be omitted for a program that's only enqueueing jobs
@@ -404,6 +913,17 @@ type Config struct {
 	// instances of rivertype.WorkerMiddleware).
 	WorkerMiddleware []rivertype.WorkerMiddleware
 
+	// WorkerQueuesEnforcedOnFetch causes the client to check each job it
+	// fetches against the queues its worker declares with WorkerWithQueues (or
+	// WorkFuncOpts.Queues). A job found in a queue its worker isn't bound to is
+	// errored without being worked so that the routing problem is surfaced in
+	// the job's errors, and can be retried once the job's been moved to an
+	// appropriate queue with Client.JobChangeQueue.
+	//
+	// Worker queues are always checked on insert and when the client starts
+	// regardless of this setting.
+	WorkerQueuesEnforcedOnFetch bool
+
 	// queuePollInterval is the amount of time between periodic checks for queue
 	// setting changes. This is only used in poll-only mode (when no notifier is
 	// provided).
@@ -460,52 +980,152 @@ func (c *Config) WithDefaults() *Config {
 		retryPolicy = &DefaultClientRetryPolicy{}
 	}
 
+	// Leader TTL defaults relative to the reelection interval so that a
+	// customized reelection interval always leaves the same padding.
+	leaderReelectionInterval := cmp.Or(c.LeaderReelectionInterval, leadership.ElectIntervalDefault)
+
 	return &Config{
-		AdvisoryLockPrefix:          c.AdvisoryLockPrefix,
-		CancelledJobRetentionPeriod: cmp.Or(c.CancelledJobRetentionPeriod, riversharedmaintenance.CancelledJobRetentionPeriodDefault),
-		CompletedJobRetentionPeriod: cmp.Or(c.CompletedJobRetentionPeriod, riversharedmaintenance.CompletedJobRetentionPeriodDefault),
-		DiscardedJobRetentionPeriod: cmp.Or(c.DiscardedJobRetentionPeriod, riversharedmaintenance.DiscardedJobRetentionPeriodDefault),
-		ErrorHandler:                c.ErrorHandler,
-		FetchCooldown:               cmp.Or(c.FetchCooldown, FetchCooldownDefault),
-		FetchPollInterval:           cmp.Or(c.FetchPollInterval, FetchPollIntervalDefault),
-		ID:                          valutil.ValOrDefaultFunc(c.ID, func() string { return defaultClientID(time.Now().UTC()) }),
-		Hooks:                       c.Hooks,
-		JobInsertMiddleware:         c.JobInsertMiddleware,
-		JobTimeout:                  cmp.Or(c.JobTimeout, JobTimeoutDefault),
-		Logger:                      logger,
-		MaxAttempts:                 cmp.Or(c.MaxAttempts, MaxAttemptsDefault),
-		Middleware:                  c.Middleware,
-		PeriodicJobs:                c.PeriodicJobs,
-		PollOnly:                    c.PollOnly,
-		Queues:                      c.Queues,
-		ReindexerIndexNames:         reindexerIndexNames,
-		ReindexerSchedule:           c.ReindexerSchedule,
-		ReindexerTimeout:            cmp.Or(c.ReindexerTimeout, maintenance.ReindexerTimeoutDefault),
-		RescueStuckJobsAfter:        cmp.Or(c.RescueStuckJobsAfter, rescueAfter),
-		RetryPolicy:                 retryPolicy,
-		Schema:                      c.Schema,
-		SoftStopTimeout:             c.SoftStopTimeout,
-		SkipJobKindValidation:       c.SkipJobKindValidation,
-		SkipUnknownJobCheck:         c.SkipUnknownJobCheck,
-		Test:                        c.Test,
-		TestOnly:                    c.TestOnly,
-		WorkerMiddleware:            c.WorkerMiddleware,
-		Workers:                     c.Workers,
-		queuePollInterval:           c.queuePollInterval,
-		schedulerInterval:           cmp.Or(c.schedulerInterval, maintenance.JobSchedulerIntervalDefault),
+		AdaptiveFetchBatchSize:               c.AdaptiveFetchBatchSize,
+		AdvisoryLockPrefix:                   c.AdvisoryLockPrefix,
+		ArgsCodec:                            c.ArgsCodec,
+		ArgsCodecsDecodeOnly:                 c.ArgsCodecsDecodeOnly,
+		ArgsCompression:                      c.ArgsCompression,
+		ArgsEncryptor:                        c.ArgsEncryptor,
+		CancelledJobRetentionPeriod:          cmp.Or(c.CancelledJobRetentionPeriod, riversharedmaintenance.CancelledJobRetentionPeriodDefault),
+		CompletedJobRetentionPeriod:          cmp.Or(c.CompletedJobRetentionPeriod, riversharedmaintenance.CompletedJobRetentionPeriodDefault),
+		CompleterBackpressure:                c.CompleterBackpressure,
+		ControlHandlers:                      c.ControlHandlers,
+		DatabaseCircuitBreaker:               c.DatabaseCircuitBreaker,
+		DiscardedJobRetentionPeriod:          cmp.Or(c.DiscardedJobRetentionPeriod, riversharedmaintenance.DiscardedJobRetentionPeriodDefault),
+		DriverOperationTimeouts:              c.DriverOperationTimeouts,
+		DriverRetryPolicy:                    c.DriverRetryPolicy,
+		DriverStatementTimeouts:              c.DriverStatementTimeouts,
+		ErrorHandler:                         c.ErrorHandler,
+		ErrorSizeLimits:                      c.ErrorSizeLimits,
+		ExternalIDFunc:                       c.ExternalIDFunc,
+		FetchCooldown:                        cmp.Or(c.FetchCooldown, FetchCooldownDefault),
+		FetchPollInterval:                    cmp.Or(c.FetchPollInterval, FetchPollIntervalDefault),
+		ID:                                   valutil.ValOrDefaultFunc(c.ID, func() string { return defaultClientID(time.Now().UTC()) }),
+		InsertAsync:                          c.InsertAsync,
+		InsertManyCopyFromThreshold:          cmp.Or(c.InsertManyCopyFromThreshold, InsertManyCopyFromThresholdDefault),
+		InsertOptsByKind:                     c.InsertOptsByKind,
+		InsertParamsMiddleware:               c.InsertParamsMiddleware,
+		Hooks:                                c.Hooks,
+		JobInsertMiddleware:                  c.JobInsertMiddleware,
+		JobTimeout:                           cmp.Or(c.JobTimeout, JobTimeoutDefault),
+		Labels:                               c.Labels,
+		LeaderElectionInterval:               cmp.Or(c.LeaderElectionInterval, leadership.ElectIntervalDefault),
+		LeaderElectionPriority:               c.LeaderElectionPriority,
+		LeaderReelectionInterval:             leaderReelectionInterval,
+		LeaderTTL:                            cmp.Or(c.LeaderTTL, leaderReelectionInterval+leadership.ElectIntervalTTLPaddingDefault),
+		Logger:                               logger,
+		MaxAttempts:                          cmp.Or(c.MaxAttempts, MaxAttemptsDefault),
+		Middleware:                           c.Middleware,
+		PeriodicJobs:                         c.PeriodicJobs,
+		PayloadSizeLimits:                    c.PayloadSizeLimits,
+		PoisonPill:                           c.PoisonPill,
+		PollOnly:                             c.PollOnly,
+		PublishJobKinds:                      c.PublishJobKinds,
+		Queues:                               c.Queues,
+		RateAnomalyMonitor:                   c.RateAnomalyMonitor,
+		ReadOnly:                             c.ReadOnly,
+		RecordAttemptInfo:                    c.RecordAttemptInfo,
+		ReindexerIndexNames:                  reindexerIndexNames,
+		ReindexerSchedule:                    c.ReindexerSchedule,
+		ReindexerTimeout:                     cmp.Or(c.ReindexerTimeout, maintenance.ReindexerTimeoutDefault),
+		ReleaseJobsOnStop:                    c.ReleaseJobsOnStop,
+		ResourceGuard:                        c.ResourceGuard,
+		RescueStuckJobsAfter:                 cmp.Or(c.RescueStuckJobsAfter, rescueAfter),
+		RetryBudget:                          c.RetryBudget,
+		RetryPolicy:                          retryPolicy,
+		Schema:                               c.Schema,
+		ShedMaintenanceOnDatabaseDegradation: c.ShedMaintenanceOnDatabaseDegradation,
+		SoftStopTimeout:                      c.SoftStopTimeout,
+		SkipJobKindValidation:                c.SkipJobKindValidation,
+		SkipUnknownJobCheck:                  c.SkipUnknownJobCheck,
+		SynchronousMode:                      c.SynchronousMode,
+		Test:                                 c.Test,
+		TestOnly:                             c.TestOnly,
+		UnknownJobKindPolicy:                 cmp.Or(c.UnknownJobKindPolicy, UnknownJobKindPolicyRetry),
+		UnknownJobKindWorkFunc:               c.UnknownJobKindWorkFunc,
+		WorkContext:                          c.WorkContext,
+		WorkStealing:                         c.WorkStealing,
+		WorkerMiddleware:                     c.WorkerMiddleware,
+		WorkerQueuesEnforcedOnFetch:          c.WorkerQueuesEnforcedOnFetch,
+		Workers:                              c.Workers,
+		queuePollInterval:                    c.queuePollInterval,
+		schedulerInterval:                    cmp.Or(c.schedulerInterval, maintenance.JobSchedulerIntervalDefault),
 	}
 }
 
 func (c *Config) validate() error {
+	if err := c.validateArgsCodecs(); err != nil {
+		return err
+	}
+	if err := c.validateArgsCompression(); err != nil {
+		return err
+	}
 	if c.CancelledJobRetentionPeriod < -1 {
 		return errors.New("CancelledJobRetentionPeriod time cannot be less than zero, except for -1 (infinite)")
 	}
 	if c.CompletedJobRetentionPeriod < -1 {
 		return errors.New("CompletedJobRetentionPeriod cannot be less than zero, except for -1 (infinite)")
 	}
+	if c.CompleterBackpressure != nil {
+		if err := c.CompleterBackpressure.validate(); err != nil {
+			return err
+		}
+	}
+	for name, handler := range c.ControlHandlers {
+		if name == "" {
+			return errors.New("ControlHandlers cannot contain an empty name")
+		}
+		if len(name) > controlMessageNameMaxLength {
+			return fmt.Errorf("ControlHandlers name %q cannot be longer than %d characters", name, controlMessageNameMaxLength)
+		}
+		if handler == nil {
+			return fmt.Errorf("ControlHandlers handler for %q cannot be nil", name)
+		}
+	}
+	if c.DatabaseCircuitBreaker != nil {
+		if c.DatabaseCircuitBreaker.FailureThreshold < 0 {
+			return errors.New("DatabaseCircuitBreaker.FailureThreshold cannot be less than zero")
+		}
+		if c.DatabaseCircuitBreaker.ProbeInterval < 0 {
+			return errors.New("DatabaseCircuitBreaker.ProbeInterval cannot be less than zero")
+		}
+	}
 	if c.DiscardedJobRetentionPeriod < -1 {
 		return errors.New("DiscardedJobRetentionPeriod cannot be less than zero, except for -1 (infinite)")
 	}
+	for operation, timeout := range c.DriverOperationTimeouts {
+		if !driverOperationValid(operation) {
+			return fmt.Errorf("DriverOperationTimeouts contains unknown operation %q", operation)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("DriverOperationTimeouts timeout for %q cannot be less than zero", operation)
+		}
+	}
+	if c.DriverRetryPolicy != nil {
+		if c.DriverRetryPolicy.MaxAttempts < 0 {
+			return errors.New("DriverRetryPolicy.MaxAttempts cannot be less than zero")
+		}
+		for _, operation := range c.DriverRetryPolicy.Operations {
+			if !driverOperationValid(operation) {
+				return fmt.Errorf("DriverRetryPolicy.Operations contains unknown operation %q", operation)
+			}
+		}
+	}
+	for operation, timeouts := range c.DriverStatementTimeouts {
+		if !driverOperationValid(operation) {
+			return fmt.Errorf("DriverStatementTimeouts contains unknown operation %q", operation)
+		}
+		for name, timeout := range map[string]time.Duration{"LockTimeout": timeouts.LockTimeout, "StatementTimeout": timeouts.StatementTimeout} {
+			if timeout != 0 && timeout < time.Millisecond {
+				return fmt.Errorf("DriverStatementTimeouts %s for %q must be zero or at least 1ms", name, operation)
+			}
+		}
+	}
 	if c.FetchCooldown < FetchCooldownMin {
 		return fmt.Errorf("FetchCooldown must be at least %s", FetchCooldownMin)
 	}
@@ -518,18 +1138,73 @@ func (c *Config) validate() error {
 	if len(c.ID) > 100 {
 		return errors.New("ID cannot be longer than 100 characters")
 	}
+	if c.InsertAsync != nil {
+		if err := c.InsertAsync.validate(); err != nil {
+			return err
+		}
+	}
+	if c.InsertManyCopyFromThreshold < -1 {
+		return errors.New("InsertManyCopyFromThreshold cannot be negative, except for -1 (never)")
+	}
+	for kind, insertOpts := range c.InsertOptsByKind {
+		if insertOpts.ExternalID != "" || insertOpts.Metadata != nil || insertOpts.PartitionKey != "" || insertOpts.Pending || insertOpts.ScheduleIn != 0 || !insertOpts.ScheduledAt.IsZero() {
+			return fmt.Errorf("InsertOptsByKind for %q may only set MaxAttempts, Priority, Queue, RequiredLabels, Tags, and UniqueOpts", kind)
+		}
+	}
 	if c.JobTimeout < -1 {
 		return errors.New("JobTimeout cannot be negative, except for -1 (infinite)")
 	}
+	if _, ok := c.Labels[""]; ok {
+		return errors.New("Labels cannot contain an empty key")
+	}
+	if c.LeaderElectionInterval < 0 {
+		return errors.New("LeaderElectionInterval cannot be less than zero")
+	}
+	if c.LeaderElectionPriority < 0 {
+		return errors.New("LeaderElectionPriority cannot be less than zero")
+	}
+	if c.LeaderReelectionInterval < 0 {
+		return errors.New("LeaderReelectionInterval cannot be less than zero")
+	}
+	if c.LeaderTTL < 0 {
+		return errors.New("LeaderTTL cannot be less than zero")
+	}
+	if c.LeaderTTL <= c.LeaderReelectionInterval {
+		return fmt.Errorf("LeaderTTL must be longer than LeaderReelectionInterval (%s)", c.LeaderReelectionInterval)
+	}
 	if c.MaxAttempts < 0 {
 		return errors.New("MaxAttempts cannot be less than zero")
 	}
 	if len(c.Middleware) > 0 && (len(c.JobInsertMiddleware) > 0 || len(c.WorkerMiddleware) > 0) {
 		return errors.New("only one of the pair JobInsertMiddleware/WorkerMiddleware or Middleware may be provided (Middleware is recommended, and may contain both job insert and worker middleware)")
 	}
+	if c.ReadOnly && len(c.Queues) > 0 {
+		return errors.New("Queues cannot be set on a ReadOnly client")
+	}
+	if c.ReadOnly && len(c.PeriodicJobs) > 0 {
+		return errors.New("PeriodicJobs cannot be set on a ReadOnly client")
+	}
+	if c.ReadOnly && c.PublishJobKinds {
+		return errors.New("PublishJobKinds cannot be set on a ReadOnly client")
+	}
+	if c.PoisonPill != nil {
+		if err := c.PoisonPill.validate(); err != nil {
+			return err
+		}
+	}
+	if c.RateAnomalyMonitor != nil {
+		if err := c.RateAnomalyMonitor.validate(); err != nil {
+			return err
+		}
+	}
 	if c.ReindexerTimeout < -1 {
 		return errors.New("ReindexerTimeout cannot be negative, except for -1 (infinite)")
 	}
+	if c.ResourceGuard != nil {
+		if err := c.ResourceGuard.validate(); err != nil {
+			return err
+		}
+	}
 	if c.RescueStuckJobsAfter < 0 {
 		return errors.New("RescueStuckJobsAfter cannot be less than zero")
 	}
@@ -554,6 +1229,36 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if !c.UnknownJobKindPolicy.validate() {
+		return fmt.Errorf("UnknownJobKindPolicy %q is not valid", c.UnknownJobKindPolicy)
+	}
+	if c.UnknownJobKindPolicy == UnknownJobKindPolicyCatchAll && c.UnknownJobKindWorkFunc == nil {
+		return errors.New("UnknownJobKindWorkFunc must be set if UnknownJobKindPolicy is UnknownJobKindPolicyCatchAll")
+	}
+	if c.UnknownJobKindPolicy != UnknownJobKindPolicyCatchAll && c.UnknownJobKindWorkFunc != nil {
+		return errors.New("UnknownJobKindWorkFunc may only be set if UnknownJobKindPolicy is UnknownJobKindPolicyCatchAll")
+	}
+
+	if c.SynchronousMode && c.Workers == nil {
+		return errors.New("Workers must be set if SynchronousMode is enabled")
+	}
+
+	if c.ErrorSizeLimits != nil {
+		if err := c.ErrorSizeLimits.validate(); err != nil {
+			return err
+		}
+	}
+	if c.PayloadSizeLimits != nil {
+		if err := c.PayloadSizeLimits.validate(); err != nil {
+			return err
+		}
+	}
+	if c.RetryBudget != nil {
+		if err := c.RetryBudget.validate(); err != nil {
+			return err
+		}
+	}
+
 	if c.Workers == nil && c.Queues != nil {
 		return errors.New("Workers must be set if Queues is set")
 	}
@@ -572,6 +1277,12 @@ func (c *Config) validate() error {
 				}
 			}
 		}
+
+		for kind := range c.Workers.middlewareByKind {
+			if workerInfo, ok := c.Workers.workersMap[kind]; !ok || workerInfo.jobArgs.Kind() != kind {
+				return fmt.Errorf("worker middleware added for kind %q, which has no registered worker", kind)
+			}
+		}
 	}
 
 	return nil
@@ -586,6 +1297,35 @@ func (c *Config) willExecuteJobs() bool {
 
 // QueueConfig contains queue-specific configuration.
 type QueueConfig struct {
+	// FairnessKey enables fair scheduling in the queue by interleaving
+	// fetched jobs across the values of this key in their metadata, so that
+	// a single noisy tenant with a large backlog can't monopolize the queue.
+	// For example, with jobs inserted with metadata like
+	// `{"tenant_id":"acme"}`:
+	//
+	//	FairnessKey: "tenant_id"
+	//
+	// Each tenant's jobs are ranked by priority and then scheduled time, and
+	// jobs of the same priority are fetched in rounds, taking a job from
+	// each tenant in turn (or several, see FairnessWeights). Jobs without
+	// the key are treated as belonging to one tenant of their own. Priority
+	// still takes precedence over fairness.
+	//
+	// Fair fetches rank every available job in the queue, so they're more
+	// expensive than normal fetches in queues with very large backlogs.
+	//
+	// Defaults to empty, which disables fair scheduling.
+	FairnessKey string
+
+	// FairnessWeights are relative weights for values of FairnessKey. A
+	// tenant with a weight of 2 has twice as many of its jobs fetched in each
+	// round as a tenant with the default weight of 1:
+	//
+	//	FairnessWeights: map[string]int{"acme": 2}
+	//
+	// Weights must be a minimum of 1, and require that FairnessKey be set.
+	FairnessWeights map[string]int
+
 	// FetchCooldown is the minimum amount of time to wait between fetches of new
 	// jobs. Jobs will only be fetched *at most* this often, but if no new jobs
 	// are coming in via LISTEN/NOTIFY then fetches may be delayed as long as
@@ -605,6 +1345,33 @@ type QueueConfig struct {
 	// Config.
 	FetchPollInterval time.Duration
 
+	// GlobalMaxWorkers limits the number of jobs running in the queue at once
+	// across all clients, as opposed to MaxWorkers, which only limits those
+	// of a single client. It's useful for protecting a downstream resource
+	// when the number of clients varies, like when worker processes are
+	// autoscaled.
+	//
+	// Running jobs are counted in the database each time jobs are fetched,
+	// and fetches in the queue are serialized across clients with an
+	// advisory lock so that they can't together exceed the limit. Clients
+	// aren't notified when jobs finish on other clients, so jobs held back by
+	// the limit are fetched on a subsequent fetch poll (see
+	// FetchPollInterval).
+	//
+	// Every client working the queue should be configured with the same
+	// limit. Defaults to zero, which means no limit.
+	GlobalMaxWorkers int
+
+	// GlobalMaxWorkersByKind limits the number of jobs of particular kinds
+	// running in the queue at once across all clients. It's the same as
+	// GlobalMaxWorkers, but per kind, and like MaxWorkersByKind, no more jobs
+	// of a kind are fetched at once than it has room for:
+	//
+	//	GlobalMaxWorkersByKind: map[string]int{"send_email": 5}
+	//
+	// Limits must be a minimum of 1.
+	GlobalMaxWorkersByKind map[string]int
+
 	// MaxWorkers is the maximum number of workers to run for the queue, or put
 	// otherwise, the maximum parallelism to run.
 	//
@@ -616,6 +1383,55 @@ type QueueConfig struct {
 	//
 	// Requires a minimum of 1, and a maximum of 10,000.
 	MaxWorkers int
+
+	// MaxWorkersByKind limits the number of jobs of particular kinds that
+	// are worked at once in this queue, independent of MaxWorkers. For
+	// example, a queue with 100 workers can be configured so that no more than
+	// 5 of them work "send_email" jobs at any given time:
+	//
+	//	MaxWorkersByKind: map[string]int{"send_email": 5}
+	//
+	// Like MaxWorkers, limits are per client, so the total number of jobs of a
+	// kind worked across all clients working the queue may be higher. Kinds
+	// at their limit aren't fetched until one of their jobs finishes, and no
+	// more jobs of a kind are fetched at once than it has room for.
+	//
+	// Limits must be a minimum of 1. Kinds without a limit are bounded only
+	// by MaxWorkers.
+	MaxWorkersByKind map[string]int
+
+	// MinWorkers is the number of the queue's worker slots that are never
+	// lent to other queues with Config.WorkStealing, guaranteeing that the
+	// queue can always work at least this many jobs at once. Setting it equal
+	// to MaxWorkers opts the queue out of lending entirely, although it may
+	// still borrow from other queues. Has no effect without
+	// Config.WorkStealing.
+	//
+	// Must be between zero and MaxWorkers. Defaults to zero, in which case
+	// all of the queue's idle slots may be lent out.
+	MinWorkers int
+
+	// VisibilityTimeout enables a lease-based execution model for the queue,
+	// similar to the visibility timeout of a message queue like SQS. When set,
+	// the client holds a lease of this duration on each job it's working and
+	// renews it periodically (at roughly a third of VisibilityTimeout) for as
+	// long as the job runs. Jobs whose leases lapse, most likely because the
+	// client working them crashed or lost its database connection, are made
+	// available to be worked again (or discarded if out of attempts) by any
+	// client working the queue, typically within VisibilityTimeout of the
+	// lapse rather than waiting out Config.RescueStuckJobsAfter. Conversely,
+	// a job holding an unexpired lease isn't rescued even if it's been running
+	// longer than Config.RescueStuckJobsAfter, so leases are also a good fit
+	// for jobs that legitimately run for hours.
+	//
+	// Because a client that's alive but can't reach the database also can't
+	// renew its leases, jobs may occasionally be worked twice concurrently.
+	// As always, jobs should be idempotent. A job is first leased on the
+	// client's next renewal after it's fetched, so a client that crashes
+	// before then leaves the job to the normal job rescuer.
+	//
+	// Defaults to zero, which disables leases.
+	VisibilityTimeout time.Duration
 }
 
 func (c QueueConfig) validate(queueName string, clientFetchCooldown time.Duration, clientFetchPollInterval time.Duration) error {
@@ -632,9 +1448,37 @@ func (c QueueConfig) validate(queueName string, clientFetchCooldown time.Duratio
 		return errors.New("FetchPollInterval cannot be less than FetchCooldown")
 	}
 
+	if c.FairnessKey == "" && len(c.FairnessWeights) > 0 {
+		return errors.New("FairnessWeights requires FairnessKey")
+	}
+	for value, weight := range c.FairnessWeights {
+		if weight < 1 {
+			return fmt.Errorf("invalid fairness weight for %q in queue %q: %d", value, queueName, weight)
+		}
+	}
+
 	if c.MaxWorkers < 1 || c.MaxWorkers > QueueNumWorkersMax {
 		return fmt.Errorf("invalid number of workers for queue %q: %d", queueName, c.MaxWorkers)
 	}
+	for kind, maxWorkers := range c.MaxWorkersByKind {
+		if maxWorkers < 1 {
+			return fmt.Errorf("invalid number of workers for kind %q in queue %q: %d", kind, queueName, maxWorkers)
+		}
+	}
+	if c.MinWorkers < 0 || c.MinWorkers > c.MaxWorkers {
+		return fmt.Errorf("invalid minimum number of workers for queue %q: %d (must be between 0 and MaxWorkers)", queueName, c.MinWorkers)
+	}
+	if c.GlobalMaxWorkers < 0 {
+		return errors.New("GlobalMaxWorkers cannot be less than zero")
+	}
+	for kind, maxWorkers := range c.GlobalMaxWorkersByKind {
+		if maxWorkers < 1 {
+			return fmt.Errorf("invalid number of global workers for kind %q in queue %q: %d", kind, queueName, maxWorkers)
+		}
+	}
+	if c.VisibilityTimeout < 0 {
+		return errors.New("VisibilityTimeout cannot be less than zero")
+	}
 	if err := validateQueueName(queueName); err != nil {
 		return err
 	}
@@ -651,16 +1495,26 @@ type Client[TTx any] struct {
 	baseService   baseservice.BaseService
 	baseStartStop startstop.BaseStartStop
 
+	argsDecoder            *argscodec.Decoder
+	attemptInfo            *rivertype.AttemptInfo // only set with Config.RecordAttemptInfo
 	clientNotifyBundle     *ClientNotifyBundle[TTx]
 	completer              jobcompleter.JobCompleter
+	completerBackpressure  *completerBackpressure // only set with Config.CompleterBackpressure on clients that work jobs
 	config                 *Config
+	controlBus             *controlBus                 // may be nil in poll-only mode
+	databaseDegradation    *databaseDegradationTracker // only set on clients that work jobs and track degradation
 	driver                 riverdriver.Driver[TTx]
+	driverRetryStats       *driverRetryStats       // only set with Config.DriverRetryPolicy
+	driverUnwrapped        riverdriver.Driver[TTx] // driver as given to NewClient, before wrapping for operation policies
 	elector                *leadership.Elector
 	hookLookupByJob        *hooklookup.JobHookLookup
 	hookLookupGlobal       hooklookup.HookLookupInterface
+	insertAsyncBatcher     *insertAsyncBatcher
 	insertNotifyLimiter    *notifylimiter.Limiter
 	middlewareLookupGlobal middlewarelookup.MiddlewareLookupInterface
 	notifier               *notifier.Notifier // may be nil in poll-only mode
+	observer               *observer          // only set on read-only clients
+	payloadSizeStats       payloadSizeStats
 	periodicJobs           *PeriodicJobBundle
 	pilot                  riverpilot.Pilot
 	producersByQueueName   map[string]*producer
@@ -668,11 +1522,25 @@ type Client[TTx any] struct {
 	queueMaintainer        *maintenance.QueueMaintainer
 	queueMaintainerLeader  *maintenance.QueueMaintainerLeader
 	queues                 *QueueBundle
+	rateAnomalyMonitor     *rateAnomalyMonitor // only set with Config.RateAnomalyMonitor on clients that work jobs
+	resourceGuard          *resourceGuard      // only set with Config.ResourceGuard on clients that work jobs
 	services               []startstop.Service
 	stopped                <-chan struct{}
 	subscriptionManager    *subscriptionManager
 	testSignals            clientTestSignals
 
+	// synchronousJobID generates IDs for jobs worked with
+	// Config.SynchronousMode, which are never inserted.
+	synchronousJobID atomic.Int64
+
+	// unknownJobKindsFetched counts jobs fetched by producers whose kind had no
+	// registered worker. Shared with each producer.
+	unknownJobKindsFetched atomic.Int64
+
+	// workerSlots pools worker slots across queues. Only set with
+	// Config.WorkStealing on clients that work jobs.
+	workerSlots *workerSlotPool
+
 	// workCancel cancels the context used for all work goroutines. Normal Stop
 	// does not cancel that context.
 	workCancel context.CancelCauseFunc
@@ -719,7 +1587,12 @@ var (
 	// return this error.
 	ErrNotFound = rivertype.ErrNotFound
 
+	// ErrClientReadOnly is returned by APIs that would mutate rows when
+	// invoked on a client configured with Config.ReadOnly.
+	ErrClientReadOnly = errors.New("client is read-only")
+
 	errMissingConfig                 = errors.New("missing config")
+	errMissingDatabasePoolReadOnly   = errors.New("must have a non-nil database pool to start a ReadOnly client")
 	errMissingDatabasePoolWithQueues = errors.New("must have a non-nil database pool to execute jobs (either use a driver with database pool or don't configure Queues)")
 	errMissingDriver                 = errors.New("missing database driver (try wrapping a Pgx pool with river/riverdriver/riverpgxv5.New)")
 )
@@ -780,13 +1653,48 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		}
 	}
 
+	// Optional interfaces like driverPlugin are checked on the original driver
+	// because they're hidden by wrapping.
+	unwrappedDriver := driver
+	if len(config.DriverStatementTimeouts) > 0 && driver.DatabaseName() != riverdriver.DatabaseNamePostgres {
+		return nil, errors.New("DriverStatementTimeouts is only supported by Postgres drivers")
+	}
+	var (
+		retryPolicy *DriverRetryPolicy
+		retryStats  *driverRetryStats
+	)
+	if config.DriverRetryPolicy != nil {
+		retryPolicy = config.DriverRetryPolicy.withDefaults()
+		retryStats = &driverRetryStats{}
+	}
+	// Degradation is only tracked for clients that work jobs because the
+	// subsystems it watches don't run otherwise.
+	var degradationTracker *databaseDegradationTracker
+	if config.willExecuteJobs() && (config.DatabaseDegradationEvents || config.DatabaseCircuitBreaker != nil || config.ShedMaintenanceOnDatabaseDegradation) {
+		degradationTracker = newDatabaseDegradationTracker(archetype, driver.PoolStats)
+	}
+	if len(config.DriverOperationTimeouts) > 0 || retryPolicy != nil || len(config.DriverStatementTimeouts) > 0 || degradationTracker != nil {
+		driver = newOperationDriver(driver, &operationDriverConfig{
+			DegradationTracker: degradationTracker,
+			OperationTimeouts:  config.DriverOperationTimeouts,
+			RetryPolicy:        retryPolicy,
+			RetryStats:         retryStats,
+			ShedMaintenance:    config.ShedMaintenanceOnDatabaseDegradation,
+			StatementTimeouts:  config.DriverStatementTimeouts,
+		})
+	}
+
 	client := &Client[TTx]{
+		argsDecoder: config.argsDecoder(),
 		clientNotifyBundle: &ClientNotifyBundle[TTx]{
 			config: config,
 			driver: driver,
 		},
 		config:               config,
 		driver:               driver,
+		driverUnwrapped:      unwrappedDriver,
+		databaseDegradation:  degradationTracker,
+		driverRetryStats:     retryStats,
 		hookLookupByJob:      hooklookup.NewJobHookLookup(),
 		hookLookupGlobal:     hooklookup.NewHookLookup(config.Hooks),
 		producersByQueueName: make(map[string]*producer),
@@ -794,6 +1702,10 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		workCancel:           func(cause error) {}, // replaced on start, but here in case StopAndCancel is called before start up
 	}
 
+	if config.RecordAttemptInfo {
+		client.attemptInfo = newAttemptInfo(config.ID)
+	}
+
 	client.queues = &QueueBundle{
 		clientFetchCooldown:     config.FetchCooldown,
 		clientFetchPollInterval: config.FetchPollInterval,
@@ -804,6 +1716,7 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 
 	baseservice.Init(archetype, &client.baseService)
 	client.baseService.Name = "Client" // Have to correct the name because base service isn't embedded like it usually is
+	client.insertAsyncBatcher = newInsertAsyncBatcher(archetype, config.InsertAsync, client.InsertManyFast)
 	client.insertNotifyLimiter = notifylimiter.NewLimiter(archetype, config.FetchCooldown)
 
 	// Validation ensures that config.JobInsertMiddleware/WorkerMiddleware or
@@ -841,7 +1754,7 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		client.middlewareLookupGlobal = middlewarelookup.NewMiddlewareLookup(middleware)
 	}
 
-	pluginDriver, _ := driver.(driverPlugin[TTx])
+	pluginDriver, _ := unwrappedDriver.(driverPlugin[TTx])
 	if pluginDriver != nil {
 		pluginDriver.PluginInit(archetype)
 		client.pilot = pluginDriver.PluginPilot()
@@ -885,10 +1798,48 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 			return nil, errMissingDatabasePoolWithQueues
 		}
 
-		client.completer = jobcompleter.NewBatchCompleter(archetype, config.Schema, driver.GetExecutor(), client.pilot, nil)
+		completer := jobcompleter.NewBatchCompleter(archetype, config.Schema, driver.GetExecutor(), client.pilot, nil)
+		client.completer = completer
 		client.subscriptionManager = newSubscriptionManager(archetype, nil)
 		client.services = append(client.services, client.completer, client.subscriptionManager)
 
+		if config.CompleterBackpressure != nil {
+			client.completerBackpressure = newCompleterBackpressure(archetype, config.CompleterBackpressure, func() int {
+				backlog, _ := completer.Backlog()
+				return backlog
+			})
+			client.completerBackpressure.eventCallback = client.subscriptionManager.distributeEvent
+		}
+
+		if config.RateAnomalyMonitor != nil {
+			client.rateAnomalyMonitor = newRateAnomalyMonitor(archetype, config.RateAnomalyMonitor)
+			client.rateAnomalyMonitor.eventCallback = client.subscriptionManager.distributeEvent
+			client.subscriptionManager.rateAnomalyMonitor = client.rateAnomalyMonitor
+			client.services = append(client.services, client.rateAnomalyMonitor)
+		}
+		if config.ResourceGuard != nil {
+			client.resourceGuard = newResourceGuard(archetype, config.ResourceGuard)
+			client.resourceGuard.eventCallback = client.subscriptionManager.distributeEvent
+			client.resourceGuard.releaseJobFunc = client.releaseNewestJob
+			client.services = append(client.services, client.resourceGuard)
+		}
+		if config.WorkStealing {
+			client.workerSlots = newWorkerSlotPool()
+		}
+		if client.databaseDegradation != nil {
+			client.databaseDegradation.eventCallback = client.subscriptionManager.distributeEvent
+		}
+
+		if config.DatabaseCircuitBreaker != nil {
+			// Probes go through the unwrapped driver so they're not
+			// themselves subject to the circuit breaker's policies.
+			breaker := newDatabaseCircuitBreaker(archetype, config.DatabaseCircuitBreaker, unwrappedDriver.GetExecutor())
+			breaker.eventCallback = client.subscriptionManager.distributeEvent
+			client.databaseDegradation.breaker = breaker
+			completer.SetHoldFunc(client.databaseDegradation.IsCircuitOpen)
+			client.services = append(client.services, breaker)
+		}
+
 		if driver.SupportsListener() {
 			// In poll only mode, we don't try to initialize a notifier that
 			// uses listen/notify. Instead, each service polls for changes it's
@@ -902,8 +1853,12 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		}
 
 		client.elector = leadership.NewElector(archetype, driver.GetExecutor(), client.notifier, &leadership.Config{
-			ClientID: config.ID,
-			Schema:   config.Schema,
+			ClientID:        config.ID,
+			ElectInterval:   config.LeaderElectionInterval,
+			Priority:        config.LeaderElectionPriority,
+			ReelectInterval: config.LeaderReelectionInterval,
+			Schema:          config.Schema,
+			TTL:             config.LeaderTTL,
 		})
 		client.services = append(client.services, client.elector)
 
@@ -926,6 +1881,14 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 
 		maintenanceServices := []startstop.Service{}
 
+		if config.ArgsEncryptor != nil {
+			argsEncryptionKeyRotator := maintenance.NewArgsEncryptionKeyRotator(archetype, &maintenance.ArgsEncryptionKeyRotatorConfig{
+				Encryptor: config.ArgsEncryptor,
+				Schema:    config.Schema,
+			}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, argsEncryptionKeyRotator)
+		}
+
 		{
 			jobCleaner := maintenance.NewJobCleaner(archetype, &maintenance.JobCleanerConfig{
 				CancelledJobRetentionPeriod: config.CancelledJobRetentionPeriod,
@@ -941,9 +1904,12 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 
 		{
 			jobRescuer := maintenance.NewRescuer(archetype, &maintenance.JobRescuerConfig{
-				ClientRetryPolicy: config.RetryPolicy,
-				RescueAfter:       config.RescueStuckJobsAfter,
-				Schema:            config.Schema,
+				ArgsDecoder:          client.argsDecoder,
+				ClientRetryPolicy:    config.RetryPolicy,
+				PoisonPillDetected:   client.handlePoisonPill,
+				PoisonPillMaxCrashes: config.PoisonPill.maxCrashes(),
+				RescueAfter:          config.RescueStuckJobsAfter,
+				Schema:               config.Schema,
 				WorkUnitFactoryFunc: func(kind string) workunit.WorkUnitFactory {
 					if workerInfo, ok := config.Workers.workersMap[kind]; ok {
 						return workerInfo.workUnitFactory
@@ -1022,6 +1988,7 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		// Not added to the main services list because the queue maintainer is
 		// started conditionally based on whether the client is the leader.
 		client.queueMaintainer = maintenance.NewQueueMaintainer(archetype, maintenanceServices)
+		client.queueMaintainer.SetRunRecorder(client.maintenanceRunRecord)
 
 		if config.TestOnly {
 			client.queueMaintainer.StaggerStartupDisable(true)
@@ -1037,6 +2004,44 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		client.testSignals.queueMaintainerLeader = &client.queueMaintainerLeader.TestSignals
 	}
 
+	// Jobs worked synchronously on insert emit events the same as those
+	// worked by producers, even if the client has no queues of its own.
+	if config.SynchronousMode && client.subscriptionManager == nil {
+		client.subscriptionManager = newSubscriptionManager(archetype, nil)
+	}
+
+	// A read-only client has no producers, completer, elector, or maintenance
+	// services, none of which can operate without mutating rows. It only
+	// listens for notifications to distribute to subscriptions.
+	if config.ReadOnly {
+		if !driver.PoolIsSet() {
+			return nil, errMissingDatabasePoolReadOnly
+		}
+
+		client.subscriptionManager = newSubscriptionManager(archetype, nil)
+
+		if driver.SupportsListener() && !config.PollOnly {
+			client.notifier = notifier.New(archetype, driver.GetListener(&riverdriver.GetListenenerParams{Schema: config.Schema}))
+			client.services = append(client.services, client.notifier)
+		}
+
+		client.observer = newObserver(archetype, client.notifier, client.subscriptionManager.distributeEvent)
+		client.services = append(client.services, client.observer, client.subscriptionManager)
+	}
+
+	if client.notifier != nil {
+		host, _ := os.Hostname()
+
+		client.controlBus = newControlBus(archetype, driver.GetExecutor(), client.notifier, &controlBusConfig{
+			ClientID:  config.ID,
+			Handlers:  config.ControlHandlers,
+			Host:      host,
+			JobStatus: client.activeJobStatus,
+			Schema:    config.Schema,
+		})
+		client.services = append(client.services, client.controlBus)
+	}
+
 	return client, nil
 }
 
@@ -1075,12 +2080,17 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 	// Startup code. Wrapped in a closure so it doesn't have to remember to
 	// close the stopped channel if returning with an error.
 	if err := func() error {
-		if !c.config.willExecuteJobs() {
+		if !c.config.willExecuteJobs() && !c.config.ReadOnly {
 			return errors.New("client Queues and Workers must be configured for a client to start working")
 		}
-		if c.config.Workers != nil && len(c.config.Workers.workersMap) < 1 {
+		if !c.config.ReadOnly && c.config.Workers != nil && len(c.config.Workers.workersMap) < 1 {
 			return errors.New("at least one Worker must be added to the Workers bundle")
 		}
+		if !c.config.ReadOnly && c.config.Workers != nil {
+			if err := c.validateWorkerQueues(); err != nil {
+				return err
+			}
+		}
 
 		// Before doing anything else, make an initial connection to the database to
 		// verify that it appears healthy. Many of the subcomponents below start up
@@ -1093,18 +2103,41 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 			return fmt.Errorf("error making initial connection to database: %w", err)
 		}
 
+		if c.config.PublishJobKinds && c.config.Workers != nil {
+			if err := c.jobKindRegistryPublish(fetchCtx); err != nil {
+				return fmt.Errorf("error publishing job kinds: %w", err)
+			}
+		}
+
 		// Each time we start, we need a fresh completer subscribe channel to
 		// send job completion events on, because the completer will close it
 		// each time it shuts down.
 		completerSubscribeCh := make(chan []jobcompleter.CompleterJobUpdated, 10)
-		c.completer.ResetSubscribeChan(completerSubscribeCh)
+		if c.config.ReadOnly {
+			c.observer.ResetSubscribeChan(completerSubscribeCh)
+		} else {
+			c.completer.ResetSubscribeChan(completerSubscribeCh)
+		}
 		c.subscriptionManager.ResetSubscribeChan(completerSubscribeCh)
 
+		// Give workers a chance to initialize shared resources before any
+		// jobs are fetched. Read-only clients don't work jobs.
+		workersSetUp := !c.config.ReadOnly && c.config.Workers != nil
+		if workersSetUp {
+			if err := c.config.Workers.setup(fetchCtx); err != nil {
+				return err
+			}
+		}
+
 		// In case of error, stop any services that might have started. This
 		// is safe because even services that were never started will still
 		// tolerate being stopped.
 		stopServicesOnError := func() {
 			startstop.StopAllParallel(c.services...)
+
+			if workersSetUp {
+				c.config.Workers.teardown(context.WithoutCancel(ctx))
+			}
 		}
 
 		// The completer is part of the services list below, but although it can
@@ -1113,28 +2146,28 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 		// context is cancelled.  This ensures that even when fetch is cancelled on
 		// shutdown, the completer is still given a separate opportunity to start
 		// stopping only after the producers have finished up and returned.
-		if err := c.completer.Start(context.WithoutCancel(ctx)); err != nil {
-			stopServicesOnError()
-			return err
+		if c.completer != nil {
+			if err := c.completer.Start(context.WithoutCancel(ctx)); err != nil {
+				stopServicesOnError()
+				return err
+			}
 		}
 
 		// We use separate contexts for fetching and working to allow for a
-		// graceful stop. When SoftStopTimeout is configured, the work context
-		// is detached from the start context so that cancelling the start
-		// context initiates a soft stop (with timeout escalation) rather than
-		// an immediate hard stop. When SoftStopTimeout is not configured, the
-		// work context inherits from the start context to preserve the
-		// existing behavior where cancelling the start context is equivalent
-		// to StopAndCancel.
-		workParentCtx := ctx
-		if c.config.SoftStopTimeout > 0 {
-			workParentCtx = context.WithoutCancel(ctx)
-		}
-		workCtx, workCancel := context.WithCancelCause(workParentCtx)
+		// graceful stop. When SoftStopTimeout is configured, cancelling the
+		// start context initiates a soft stop (with timeout escalation) rather
+		// than an immediate hard stop. When it's not, cancelling the start
+		// context is equivalent to StopAndCancel, so the work context is
+		// cancelled right away, with ErrStop as its cause so that interrupted
+		// jobs are handled the same way (e.g. released with ReleaseJobsOnStop).
+		workCtx, workCancel := newWorkContext(ctx, c.config.SoftStopTimeout <= 0)
 
 		// Client available to executors and to various service hooks.
 		fetchCtx := withClient(fetchCtx, c)
 		workCtx = withClient(workCtx, c)
+		if c.config.WorkContext != nil {
+			workCtx = c.config.WorkContext(workCtx)
+		}
 
 		if err := startstop.StartAll(fetchCtx, c.services...); err != nil {
 			workCancel(err)
@@ -1213,21 +2246,35 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 
 		c.workCancel(rivercommon.ErrStop)
 
+		// Insert any jobs still buffered by InsertAsync. The batcher is only
+		// started on first use, but can tolerate a stop without having been
+		// started.
+		c.insertAsyncBatcher.Stop()
+
 		// Stop all mainline services where stop order isn't important.
-		startstop.StopAllParallel(append(
-			// This list of services contains the completer, which should always
-			// stop after the producers so that any remaining work that was enqueued
-			// will have a chance to have its state completed as it finishes.
-			//
-			// TODO: there's a risk here that the completer is stuck on a job that
-			// won't complete. We probably need a timeout or way to move on in those
-			// cases.
-			c.services,
+		//
+		// This list of services contains the completer, which should always
+		// stop after the producers so that any remaining work that was enqueued
+		// will have a chance to have its state completed as it finishes.
+		//
+		// TODO: there's a risk here that the completer is stuck on a job that
+		// won't complete. We probably need a timeout or way to move on in those
+		// cases.
+		servicesToStop := c.services
+
+		// Will only be started if this client was leader, but can tolerate a
+		// stop without having been started. Read-only clients don't have one.
+		if c.queueMaintainer != nil {
+			servicesToStop = append(slices.Clip(servicesToStop), c.queueMaintainer)
+		}
 
-			// Will only be started if this client was leader, but can tolerate a
-			// stop without having been started.
-			c.queueMaintainer,
-		)...)
+		startstop.StopAllParallel(servicesToStop...)
+
+		// Workers are torn down last so that resources they share are
+		// available until all running jobs have finished.
+		if !c.config.ReadOnly && c.config.Workers != nil {
+			c.config.Workers.teardown(context.WithoutCancel(ctx))
+		}
 	}()
 
 	return nil
@@ -1356,6 +2403,264 @@ func (c *Client[TTx]) SubscribeConfig(config *SubscribeConfig) (<-chan *Event, f
 	return c.subscriptionManager.SubscribeConfig(config)
 }
 
+const (
+	// The default window behind the cursor that TailEvents re-scans on each
+	// poll to pick up jobs whose finalization committed late.
+	tailEventsOverlapWindowDefault = 30 * time.Second
+
+	// The default interval at which TailEvents polls for newly finalized jobs.
+	tailEventsPollIntervalDefault = 1 * time.Second
+)
+
+// TailEventsFilter configures a tail started with Client.TailEvents.
+type TailEventsFilter struct {
+	// After is a cursor from which to resume tailing, usually one that was
+	// received by a previous invocation of TailEventsFunc and persisted so that
+	// tailing can pick up where it left off across restarts.
+	//
+	// If unset, tailing starts from the oldest finalized job still retained
+	// in the database.
+	After *JobListCursor
+
+	// JobKinds optionally restricts events to jobs of the given kinds.
+	JobKinds []string
+
+	// Kinds are the kinds of events to tail. Tailing is driven by the jobs
+	// table, so only events that correspond to a final job state are
+	// supported: EventKindJobCancelled, EventKindJobCompleted, and
+	// EventKindJobFailed. The latter is only emitted for jobs that were
+	// discarded because a failure that will be retried isn't durably recorded
+	// in a way that can be backfilled.
+	//
+	// Defaults to all supported kinds.
+	Kinds []EventKind
+
+	// OverlapWindow is how far behind the cursor each poll re-scans for jobs.
+	// A job's finalized_at is assigned before the transaction finalizing it
+	// commits, so a job may become visible only after the cursor has already
+	// moved past its finalized_at. Jobs in the window are deduplicated by ID
+	// so that they're not delivered twice while tailing, and the window
+	// should be larger than the longest expected delay between a job being
+	// finalized and its transaction committing.
+	//
+	// Defaults to 30 seconds.
+	OverlapWindow time.Duration
+
+	// PollInterval is the interval at which the jobs table is polled for newly
+	// finalized jobs once the tail has caught up.
+	//
+	// Defaults to 1 second.
+	PollInterval time.Duration
+
+	// Queues optionally restricts events to jobs in the given queues.
+	Queues []string
+}
+
+// TailEventsFunc is a function invoked by Client.TailEvents for each event
+// being tailed. Along with the event, it receives a cursor that may be
+// persisted and later passed to TailEventsFilter.After to resume tailing
+// immediately after the event.
+//
+// Returning an error stops tailing, and the error is returned from
+// TailEvents.
+type TailEventsFunc func(ctx context.Context, event *Event, cursor *JobListCursor) error
+
+// TailEvents follows job lifecycle events as they occur, invoking fn for each
+// one in the order that jobs were finalized (except that a job whose
+// finalization committed late is delivered once it becomes visible). It
+// blocks until ctx is cancelled (in which case it returns nil) or fn returns
+// an error. A nil filter tails all supported events.
+//
+// Unlike Subscribe, events aren't sourced from jobs worked by this client, but
+// rather from the jobs table, so TailEvents sees events from all clients in
+// the database and doesn't require that the client be started or even that it
+// be configured to work jobs. Tailing survives database errors by logging them
+// and retrying on the next poll, and by persisting the cursor given to fn,
+// callers can resume tailing across restarts (as long as jobs are still
+// retained by the job cleaner). This makes it suitable for uses like log
+// shipping and auditing that need a continuous feed.
+//
+// Events are delivered at least once. If fn returns an error or the process
+// exits before a cursor is persisted, the same event will be delivered again
+// on resumption. Each poll re-scans TailEventsFilter.OverlapWindow behind the
+// cursor to catch jobs whose finalization committed late, so on resumption
+// events from within that window before the persisted cursor are delivered
+// again too. Jobs whose finalization commits later than OverlapWindow after
+// their finalized_at may be missed.
+//
+//	err := client.TailEvents(ctx, &river.TailEventsFilter{
+//		After: lastCursor,
+//		Kinds: []river.EventKind{river.EventKindJobCompleted},
+//	}, func(ctx context.Context, event *river.Event, cursor *river.JobListCursor) error {
+//		// ship event, then persist cursor
+//		return nil
+//	})
+func (c *Client[TTx]) TailEvents(ctx context.Context, filter *TailEventsFilter, fn TailEventsFunc) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+	if filter == nil {
+		filter = &TailEventsFilter{}
+	}
+	if filter.OverlapWindow < 0 {
+		return errors.New("OverlapWindow cannot be less than zero")
+	}
+	if filter.PollInterval < 0 {
+		return errors.New("PollInterval cannot be less than zero")
+	}
+
+	states, err := tailEventsStates(filter.Kinds)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cursor        = filter.After
+		overlapWindow = cmp.Or(filter.OverlapWindow, tailEventsOverlapWindowDefault)
+		pollInterval  = cmp.Or(filter.PollInterval, tailEventsPollIntervalDefault)
+
+		// IDs of jobs delivered within the overlap window, mapped to their
+		// finalized_at so they can be pruned as the window moves forward.
+		delivered = make(map[int64]time.Time)
+	)
+
+	const pageSize = 100
+
+	baseParams := NewJobListParams().
+		OrderBy(JobListOrderByFinalizedAt, SortOrderAsc).
+		States(states...).
+		First(pageSize)
+	if len(filter.JobKinds) > 0 {
+		baseParams = baseParams.Kinds(filter.JobKinds...)
+	}
+	if len(filter.Queues) > 0 {
+		baseParams = baseParams.Queues(filter.Queues...)
+	}
+
+	// The job a resumed cursor points to was delivered before the cursor was
+	// persisted, so don't deliver it again when re-scanning behind it.
+	if cursor != nil {
+		resolvedCursor := tailEventsResolveCursor(cursor, baseParams)
+		delivered[resolvedCursor.id] = resolvedCursor.time
+	}
+
+	for {
+		// Each poll starts from a cursor rewound by the overlap window and
+		// pages forward from there, skipping jobs that were already delivered.
+		var scanCursor *JobListCursor
+		if cursor != nil {
+			windowStart := tailEventsResolveCursor(cursor, baseParams).time.Add(-overlapWindow)
+			for id, finalizedAt := range delivered {
+				if finalizedAt.Before(windowStart) {
+					delete(delivered, id)
+				}
+			}
+
+			scanCursor = &JobListCursor{
+				sortField: JobListOrderByFinalizedAt,
+				sortOrder: ptrutil.Ptr(SortOrderAsc),
+				time:      windowStart,
+			}
+		}
+
+		for {
+			params := baseParams
+			if scanCursor != nil {
+				params = params.After(scanCursor)
+			}
+
+			listRes, err := c.JobList(ctx, params)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil //nolint:nilerr
+				}
+
+				c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Error listing jobs while tailing events; will retry",
+					slog.String("err", err.Error()),
+				)
+				break
+			}
+
+			for _, job := range listRes.Jobs {
+				jobCursor := jobListCursorFromJobAndParams(job, params)
+				scanCursor = jobCursor
+
+				if _, ok := delivered[job.ID]; ok {
+					continue
+				}
+
+				event := &Event{Job: job, Kind: tailEventKindForState(job.State)}
+				if err := fn(ctx, event, jobCursor); err != nil {
+					return err
+				}
+
+				delivered[job.ID] = *job.FinalizedAt
+			}
+
+			if scanCursor != nil {
+				cursor = scanCursor
+			}
+
+			// A full page means there are probably more jobs waiting, so go
+			// back around immediately instead of waiting for the next poll.
+			if len(listRes.Jobs) < pageSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// tailEventsResolveCursor resolves a cursor used by TailEvents, which may be
+// one from JobListCursorFromJob that doesn't yet carry an ID and position.
+func tailEventsResolveCursor(cursor *JobListCursor, params *JobListParams) *JobListCursor {
+	if cursor.job != nil {
+		return jobListCursorFromJobAndParams(cursor.job, params)
+	}
+	return cursor
+}
+
+// tailEventsStates maps event kinds requested for TailEvents to the final job
+// states that should be queried to produce them.
+func tailEventsStates(kinds []EventKind) ([]rivertype.JobState, error) {
+	if len(kinds) < 1 {
+		kinds = []EventKind{EventKindJobCancelled, EventKindJobCompleted, EventKindJobFailed}
+	}
+
+	states := make([]rivertype.JobState, 0, len(kinds))
+	for _, kind := range kinds {
+		switch kind { //nolint:exhaustive
+		case EventKindJobCancelled:
+			states = append(states, rivertype.JobStateCancelled)
+		case EventKindJobCompleted:
+			states = append(states, rivertype.JobStateCompleted)
+		case EventKindJobFailed:
+			states = append(states, rivertype.JobStateDiscarded)
+		default:
+			return nil, fmt.Errorf("event kind %q is not supported for tailing", kind)
+		}
+	}
+
+	return sliceutil.Uniq(states), nil
+}
+
+// tailEventKindForState returns the event kind that's produced by TailEvents
+// for a job in the given final state.
+func tailEventKindForState(state rivertype.JobState) EventKind {
+	switch state { //nolint:exhaustive
+	case rivertype.JobStateCancelled:
+		return EventKindJobCancelled
+	case rivertype.JobStateDiscarded:
+		return EventKindJobFailed
+	}
+	return EventKindJobCompleted
+}
+
 // Dump aggregate stats from job completions to logs periodically.  These
 // numbers don't mean much in themselves, but can give a rough idea of the
 // proportions of each compared to each other, and may help flag outlying values
@@ -1390,7 +2695,7 @@ func (c *Client[TTx]) logStatsLoop(ctx context.Context, shouldStart bool, starte
 //
 // API is not stable. DO NOT USE.
 func (c *Client[TTx]) Driver() riverdriver.Driver[TTx] {
-	return c.driver
+	return c.driverUnwrapped
 }
 
 // JobCancel cancels the job with the given ID. If possible, the job is
@@ -1430,18 +2735,7 @@ func (c *Client[TTx]) Driver() riverdriver.Driver[TTx] {
 // Returns the up-to-date JobRow for the specified jobID if it exists. Returns
 // ErrNotFound if the job doesn't exist.
 func (c *Client[TTx]) JobCancel(ctx context.Context, jobID int64) (*rivertype.JobRow, error) {
-	job, err := c.jobCancel(ctx, c.driver.GetExecutor(), jobID)
-	if err != nil {
-		return nil, err
-	}
-
-	c.notifyProducerWithoutListenerQueueControlEvent(job.Queue, &controlEventPayload{
-		Action: controlActionCancel,
-		JobID:  job.ID,
-		Queue:  job.Queue,
-	})
-
-	return job, nil
+	return c.JobCancelWithReason(ctx, jobID, "")
 }
 
 // JobCancelTx cancels the job with the given ID within the specified
@@ -1486,59 +2780,710 @@ func (c *Client[TTx]) JobCancel(ctx context.Context, jobID int64) (*rivertype.Jo
 // Returns the up-to-date JobRow for the specified jobID if it exists. Returns
 // ErrNotFound if the job doesn't exist.
 func (c *Client[TTx]) JobCancelTx(ctx context.Context, tx TTx, jobID int64) (*rivertype.JobRow, error) {
-	return c.jobCancel(ctx, c.driver.UnwrapExecutor(tx), jobID)
+	return c.JobCancelWithReasonTx(ctx, tx, jobID, "")
 }
 
-func (c *Client[TTx]) jobCancel(ctx context.Context, exec riverdriver.Executor, jobID int64) (*rivertype.JobRow, error) {
+// JobCancelWithReason cancels the job with the given ID in the same way as
+// JobCancel, but records a human-readable reason for the cancellation.
+//
+// The reason is stored in the job's metadata under `cancel_reason`. If the
+// job is running, the reason is also delivered to the client working it, and
+// the job's context is cancelled with a cause of JobCancelledRemotelyError
+// carrying the reason, which workers can extract with:
+//
+//	var cancelledErr *river.JobCancelledRemotelyError
+//	if errors.As(context.Cause(ctx), &cancelledErr) {
+//		...
+//	}
+//
+// The cause still matches ErrJobCancelledRemotely with errors.Is. If the job
+// returns an error after cancellation, the reason is included in the error
+// recorded on the job.
+//
+// Returns the up-to-date JobRow for the specified jobID if it exists. Returns
+// ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobCancelWithReason(ctx context.Context, jobID int64, reason string) (*rivertype.JobRow, error) {
+	job, err := c.jobCancel(ctx, c.driver.GetExecutor(), jobID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	c.notifyProducerWithoutListenerQueueControlEvent(job.Queue, &rivernotify.ControlPayload{
+		Action: rivernotify.ControlActionCancel,
+		JobID:  job.ID,
+		Queue:  job.Queue,
+		Reason: reason,
+	})
+
+	return job, nil
+}
+
+// JobCancelWithReasonTx cancels the job with the given ID within the specified
+// transaction in the same way as JobCancelTx, but records a human-readable
+// reason for the cancellation. See JobCancelWithReason for how the reason is
+// surfaced.
+//
+// Returns the up-to-date JobRow for the specified jobID if it exists. Returns
+// ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobCancelWithReasonTx(ctx context.Context, tx TTx, jobID int64, reason string) (*rivertype.JobRow, error) {
+	return c.jobCancel(ctx, c.driver.UnwrapExecutor(tx), jobID, reason)
+}
+
+func (c *Client[TTx]) jobCancel(ctx context.Context, exec riverdriver.Executor, jobID int64, reason string) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
 	return c.pilot.JobCancel(ctx, exec, &riverdriver.JobCancelParams{
 		ID:                jobID,
 		CancelAttemptedAt: c.baseService.Time.Now(),
 		ControlTopic:      string(notifier.NotificationTopicControl),
 		Now:               c.baseService.Time.NowOrNil(),
+		Reason:            reason,
 		Schema:            c.config.Schema,
 	})
 }
 
-// JobDelete deletes the job with the given ID from the database, returning the
-// deleted row if it was deleted. Jobs in the running state are not deleted,
-// instead returning rivertype.ErrJobRunning.
-func (c *Client[TTx]) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
-	return c.driver.GetExecutor().JobDelete(ctx, &riverdriver.JobDeleteParams{
-		ID:     id,
-		Schema: c.config.Schema,
-	})
-}
+// JobChangeQueueResult is the result of a job change queue operation.
+type JobChangeQueueResult struct {
+	// NumJobs is the total number of jobs that were moved to the target queue.
+	NumJobs int
+}
+
+// The number of jobs moved to a new queue per batch in JobChangeQueue.
+const jobChangeQueueBatchSize = 1_000
+
+// JobChangeQueue moves jobs matching the conditions defined by
+// JobChangeQueueParams to targetQueue. It's useful for rebalancing work after
+// jobs have been inserted to the wrong queue, like when a routing mistake sent
+// many jobs somewhere they'll never be worked.
+//
+// Jobs are moved in batches of 1,000, each in its own transaction, so that
+// moving a large number of jobs doesn't hold locks on all of them at once.
+// Running jobs and jobs locked by other transactions are skipped, as are jobs
+// already in targetQueue. Because of batching, a move that returns an error
+// may have moved some jobs already, but it's safe to be called again.
+//
+//	params := river.NewJobChangeQueueParams().Kinds("email_send").Queues("default")
+//	changeRes, err := client.JobChangeQueue(ctx, params, "email")
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobChangeQueue(ctx context.Context, params *JobChangeQueueParams, targetQueue string) (*JobChangeQueueResult, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.jobChangeQueue(ctx, c.driver.GetExecutor(), params, targetQueue)
+}
+
+// JobChangeQueueTx moves jobs matching the conditions defined by
+// JobChangeQueueParams to targetQueue within the given transaction. See
+// JobChangeQueue for details.
+//
+// Unlike JobChangeQueue, all batches are moved in the given transaction, so
+// locks on moved jobs are held until it commits.
+func (c *Client[TTx]) JobChangeQueueTx(ctx context.Context, tx TTx, params *JobChangeQueueParams, targetQueue string) (*JobChangeQueueResult, error) {
+	return c.jobChangeQueue(ctx, c.driver.UnwrapExecutor(tx), params, targetQueue)
+}
+
+func (c *Client[TTx]) jobChangeQueue(ctx context.Context, exec riverdriver.Executor, params *JobChangeQueueParams, targetQueue string) (*JobChangeQueueResult, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	if params == nil {
+		params = NewJobChangeQueueParams()
+	}
+	params = params.copy()
+	params.schema = c.config.Schema
+
+	if params.filtersEmpty() && !params.unsafeAll {
+		return nil, errors.New("change queue with no filters not allowed to prevent accidental move of all jobs; either specify a predicate (e.g. JobChangeQueueParams.IDs, JobChangeQueueParams.Queues, ...) or call JobChangeQueueParams.UnsafeAll")
+	}
+
+	if err := validateQueueName(targetQueue); err != nil {
+		return nil, err
+	}
+
+	listParams, err := dblist.JobMakeDriverParams(ctx, params.toDBParams(jobChangeQueueBatchSize), c.driver.SQLFragmentColumnIn)
+	if err != nil {
+		return nil, err
+	}
+
+	var numJobs int
+	for {
+		jobs, err := exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+			Max:           listParams.Max,
+			NamedArgs:     listParams.NamedArgs,
+			OrderByClause: listParams.OrderByClause,
+			Queue:         targetQueue,
+			Schema:        listParams.Schema,
+			WhereClause:   listParams.WhereClause,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		numJobs += len(jobs)
+
+		// Wake up producers for the target queue so that moved jobs are worked
+		// right away instead of waiting for the next poll.
+		if slices.ContainsFunc(jobs, func(job *rivertype.JobRow) bool { return job.State == rivertype.JobStateAvailable }) &&
+			c.driver.SupportsListenNotify() {
+			payload, err := rivernotify.EncodeInsert(&rivernotify.InsertPayload{Queue: targetQueue})
+			if err != nil {
+				return nil, err
+			}
+
+			if err := exec.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+				Payload: []string{payload},
+				Schema:  c.config.Schema,
+				Topic:   string(notifier.NotificationTopicInsert),
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(jobs) < jobChangeQueueBatchSize {
+			break
+		}
+	}
+
+	return &JobChangeQueueResult{NumJobs: numJobs}, nil
+}
+
+// JobClone inserts a new job that's a copy of the job with the given ID,
+// carrying over its kind, args, queue, priority, max attempts, tags, and
+// metadata. The clone is made available to be worked immediately, regardless
+// of the state of the original job, which is left untouched. This is useful
+// for operations like rerunning a job that's already completed.
+//
+// Metadata keys used internally by River (like recorded output) aren't copied,
+// and clones aren't subject to the original job's unique options because they
+// would otherwise conflict with the job they were cloned from.
+//
+// Returns ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobClone(ctx context.Context, id int64) (*rivertype.JobInsertResult, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	res, err := dbutil.WithTxV(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) (*rivertype.JobInsertResult, error) {
+		return c.jobClone(ctx, execTx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.notifyProducerWithoutListenerJobFetch(ctx, []*rivertype.JobInsertResult{res})
+
+	return res, nil
+}
+
+// JobCloneTx inserts a new job that's a copy of the job with the given ID,
+// carrying over its kind, args, queue, priority, max attempts, tags, and
+// metadata. This variant lets a caller clone a job atomically alongside other
+// database changes. A cloned job isn't visible to be worked until the
+// transaction commits, and if the transaction rolls back, so too is the
+// cloned job.
+//
+// See JobClone for more details.
+func (c *Client[TTx]) JobCloneTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobInsertResult, error) {
+	return c.jobClone(ctx, c.driver.UnwrapExecutor(tx), id)
+}
+
+func (c *Client[TTx]) jobClone(ctx context.Context, execTx riverdriver.ExecutorTx, id int64) (*rivertype.JobInsertResult, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	job, err := execTx.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := jobCloneMetadata(job.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.insertMany(ctx, execTx, []*rivertype.JobInsertParams{
+		{
+			Args:        c.jobArgsFromRow(job),
+			CreatedAt:   c.baseService.Time.NowOrNil(),
+			EncodedArgs: job.EncodedArgs,
+			Kind:        job.Kind,
+			MaxAttempts: job.MaxAttempts,
+			Metadata:    metadata,
+			Priority:    job.Priority,
+			Queue:       job.Queue,
+			ScheduledAt: c.baseService.Time.NowOrNil(),
+			State:       rivertype.JobStateAvailable,
+			Tags:        job.Tags,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res[0], nil
+}
+
+// jobArgsFromRow is a JobArgs implementation built from an existing job row.
+// It's used to reinsert a job in cases like cloning where the job's original
+// args struct isn't available, and carries over the hooks of the registered
+// args for the same kind (if there is one) so the job's insert hooks still run.
+type jobArgsFromRow struct {
+	encodedArgs []byte
+	hooks       []rivertype.Hook
+	kind        string
+}
+
+func (a *jobArgsFromRow) Hooks() []rivertype.Hook      { return a.hooks }
+func (a *jobArgsFromRow) Kind() string                 { return a.kind }
+func (a *jobArgsFromRow) MarshalJSON() ([]byte, error) { return a.encodedArgs, nil }
+
+func (c *Client[TTx]) jobArgsFromRow(job *rivertype.JobRow) *jobArgsFromRow {
+	args := &jobArgsFromRow{encodedArgs: job.EncodedArgs, kind: job.Kind}
+
+	if c.config.Workers != nil {
+		if workerInfo, ok := c.config.Workers.workersMap[job.Kind]; ok {
+			if argsWithHooks, ok := workerInfo.jobArgs.(JobArgsWithHooks); ok {
+				args.hooks = argsWithHooks.Hooks()
+			}
+		}
+	}
+
+	return args
+}
+
+// Returns a copy of a job's metadata suitable for a cloned job, which removes
+// keys that River uses internally to track the progress of a particular job.
+func jobCloneMetadata(metadata []byte) ([]byte, error) {
+	if len(metadata) < 1 {
+		return []byte("{}"), nil
+	}
+
+	var metadataMap map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+		return nil, fmt.Errorf("error unmarshaling job metadata: %w", err)
+	}
+
+	for key := range metadataMap {
+		if key == "cancel_attempted_at" || key == rivertype.MetadataKeyOutput || strings.HasPrefix(key, "river:") {
+			delete(metadataMap, key)
+		}
+	}
+
+	return json.Marshal(metadataMap)
+}
+
+// JobDelete deletes the job with the given ID from the database, returning the
+// deleted row if it was deleted. Jobs in the running state are not deleted,
+// instead returning rivertype.ErrJobRunning.
+func (c *Client[TTx]) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	return c.driver.GetExecutor().JobDelete(ctx, &riverdriver.JobDeleteParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+}
+
+// JobDeleteTx deletes the job with the given ID from the database, returning the
+// deleted row if it was deleted. Jobs in the running state are not deleted,
+// instead returning rivertype.ErrJobRunning. This variant lets a caller retry a
+// job atomically alongside other database changes. A deleted job isn't deleted
+// until the transaction commits, and if the transaction rolls back, so too is
+// the deleted job.
+func (c *Client[TTx]) JobDeleteTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	return c.driver.UnwrapExecutor(tx).JobDelete(ctx, &riverdriver.JobDeleteParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+}
+
+// JobGet fetches a single job by its ID. Returns the up-to-date JobRow for the
+// specified jobID if it exists. Returns ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	return c.driver.GetExecutor().JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+}
+
+// JobGetByExternalID fetches a single job by its external ID. Returns the
+// up-to-date JobRow for the job with the external ID if it exists. Returns
+// ErrNotFound if the job doesn't exist. See InsertOpts.ExternalID.
+func (c *Client[TTx]) JobGetByExternalID(ctx context.Context, externalID string) (*rivertype.JobRow, error) {
+	return c.driver.GetExecutor().JobGetByExternalID(ctx, &riverdriver.JobGetByExternalIDParams{
+		ExternalID: externalID,
+		Schema:     c.config.Schema,
+	})
+}
+
+// JobGetByExternalIDTx fetches a single job by its external ID, within a
+// transaction. Returns the up-to-date JobRow for the job with the external ID
+// if it exists. Returns ErrNotFound if the job doesn't exist. See
+// InsertOpts.ExternalID.
+func (c *Client[TTx]) JobGetByExternalIDTx(ctx context.Context, tx TTx, externalID string) (*rivertype.JobRow, error) {
+	return c.driver.UnwrapExecutor(tx).JobGetByExternalID(ctx, &riverdriver.JobGetByExternalIDParams{
+		ExternalID: externalID,
+		Schema:     c.config.Schema,
+	})
+}
+
+// JobGetTx fetches a single job by its ID, within a transaction. Returns the
+// up-to-date JobRow for the specified jobID if it exists. Returns ErrNotFound
+// if the job doesn't exist.
+func (c *Client[TTx]) JobGetTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobRow, error) {
+	return c.driver.UnwrapExecutor(tx).JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+}
+
+// JobInspectAttempt contains information on a single attempt of a job as
+// returned by Client.JobInspect.
+type JobInspectAttempt struct {
+	// Attempt is the attempt number, starting at 1.
+	Attempt int
+
+	// Error is the error that occurred during the attempt. It's nil if the
+	// attempt didn't produce an error, like if it succeeded, is still running,
+	// or was snoozed or cancelled.
+	Error *rivertype.AttemptError
+}
+
+// JobInspectResult is the result of Client.JobInspect. It contains a job row
+// along with information derived from it and related jobs.
+type JobInspectResult struct {
+	// Attempts contains information on each of the job's attempts in order.
+	Attempts []*JobInspectAttempt
+
+	// Job is the up-to-date job row.
+	Job *rivertype.JobRow
+
+	// WorkflowJobs are other jobs belonging to the same workflow as the
+	// inspected job, identified by a shared `workflow_id` metadata value, and
+	// ordered by ID. Empty if the job isn't part of a workflow. At most 1,000
+	// jobs are returned.
+	WorkflowJobs []*rivertype.JobRow
+}
+
+// JobInspect fetches a job by its ID along with additional information useful
+// for troubleshooting it, like a breakdown of its attempts and other jobs
+// belonging to the same workflow. It's a higher level alternative to JobGet
+// that's meant for operational tooling. Returns ErrNotFound if the job doesn't
+// exist.
+func (c *Client[TTx]) JobInspect(ctx context.Context, id int64) (*JobInspectResult, error) {
+	return c.jobInspect(ctx, c.driver.GetExecutor(), id)
+}
+
+// JobInspectTx fetches a job by its ID along with additional information
+// useful for troubleshooting it, within a transaction. Returns ErrNotFound if
+// the job doesn't exist.
+//
+// See JobInspect for more details.
+func (c *Client[TTx]) JobInspectTx(ctx context.Context, tx TTx, id int64) (*JobInspectResult, error) {
+	return c.jobInspect(ctx, c.driver.UnwrapExecutor(tx), id)
+}
+
+func (c *Client[TTx]) jobInspect(ctx context.Context, exec riverdriver.Executor, id int64) (*JobInspectResult, error) {
+	job, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]*JobInspectAttempt, job.Attempt)
+	for i := range attempts {
+		attempts[i] = &JobInspectAttempt{Attempt: i + 1}
+	}
+	for i := range job.Errors {
+		attemptErr := &job.Errors[i]
+		if attemptErr.Attempt >= 1 && attemptErr.Attempt <= len(attempts) {
+			attempts[attemptErr.Attempt-1].Error = attemptErr
+		}
+	}
+
+	res := &JobInspectResult{
+		Attempts: attempts,
+		Job:      job,
+	}
+
+	var metadata struct {
+		WorkflowID string `json:"workflow_id"`
+	}
+	if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("error unmarshaling job metadata: %w", err)
+	}
+
+	if metadata.WorkflowID != "" {
+		listRes, err := c.jobList(ctx, exec, NewJobListParams().
+			First(1_000).
+			Where("metadata ->> 'workflow_id' = @workflow_id", NamedArgs{"workflow_id": metadata.WorkflowID}),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res.WorkflowJobs = slices.DeleteFunc(listRes.Jobs, func(workflowJob *rivertype.JobRow) bool { return workflowJob.ID == job.ID })
+	}
+
+	return res, nil
+}
+
+// JobKindStorage is the storage used by jobs of a single kind as returned by
+// Client.JobKindStorageUsage.
+type JobKindStorage struct {
+	// ArgsBytes is the total size of the args of jobs of the kind.
+	ArgsBytes int64
+
+	// Count is the number of jobs of the kind.
+	Count int64
+
+	// ErrorsBytes is the total size of the errors of jobs of the kind.
+	ErrorsBytes int64
+
+	// Kind is the job kind.
+	Kind string
+
+	// MetadataBytes is the total size of the metadata of jobs of the kind.
+	MetadataBytes int64
+
+	// TotalBytes is the sum of ArgsBytes, ErrorsBytes, and MetadataBytes.
+	TotalBytes int64
+}
+
+// JobKindStorageUsageResult is the result of Client.JobKindStorageUsage.
+type JobKindStorageUsageResult struct {
+	// Kinds contains the storage used by each job kind, ordered by TotalBytes
+	// descending so that the kinds using the most storage come first.
+	Kinds []*JobKindStorage
+}
+
+// JobKindStorageUsage reports the storage used by each job kind, aggregating
+// the sizes of job args, errors, and metadata. It's useful for identifying
+// which kinds dominate the jobs table so that their retention can be tuned.
+//
+// Sizes are those of stored values, which in Postgres may be compressed, and
+// don't include per-row overhead, other columns, or indexes. Computing them
+// requires a full scan of the jobs table, so this may be slow on large tables
+// and shouldn't be called frequently.
+func (c *Client[TTx]) JobKindStorageUsage(ctx context.Context) (*JobKindStorageUsageResult, error) {
+	return c.jobKindStorageUsage(ctx, c.driver.GetExecutor())
+}
+
+// JobKindStorageUsageTx reports the storage used by each job kind within a
+// transaction.
+//
+// See JobKindStorageUsage for more details.
+func (c *Client[TTx]) JobKindStorageUsageTx(ctx context.Context, tx TTx) (*JobKindStorageUsageResult, error) {
+	return c.jobKindStorageUsage(ctx, c.driver.UnwrapExecutor(tx))
+}
+
+func (c *Client[TTx]) jobKindStorageUsage(ctx context.Context, exec riverdriver.Executor) (*JobKindStorageUsageResult, error) {
+	usages, err := exec.JobKindStorageUsage(ctx, &riverdriver.JobKindStorageUsageParams{
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := sliceutil.Map(usages, func(usage *riverdriver.JobKindStorageUsageResult) *JobKindStorage {
+		return &JobKindStorage{
+			ArgsBytes:     usage.ArgsBytes,
+			Count:         usage.Count,
+			ErrorsBytes:   usage.ErrorsBytes,
+			Kind:          usage.Kind,
+			MetadataBytes: usage.MetadataBytes,
+			TotalBytes:    usage.ArgsBytes + usage.ErrorsBytes + usage.MetadataBytes,
+		}
+	})
+	slices.SortStableFunc(kinds, func(a, b *JobKindStorage) int {
+		return cmp.Compare(b.TotalBytes, a.TotalBytes)
+	})
+
+	return &JobKindStorageUsageResult{Kinds: kinds}, nil
+}
+
+// JobMoveResult is the result of a job move operation.
+type JobMoveResult struct {
+	// Jobs are the moved jobs as they were inserted into the target schema, in
+	// the same order as the IDs that were requested to be moved. Moved jobs
+	// are assigned new IDs in the target schema.
+	Jobs []*rivertype.JobRow
+}
+
+// The maximum number of jobs that can be moved in a single call to JobMove.
+const jobMoveMax = 10_000
+
+// JobMove moves the jobs with the given IDs to another schema, copying them
+// into the target schema and removing them from the client's schema in a single
+// transaction. Moved jobs keep their state, attempts, errors, metadata, and
+// unique keys, but are assigned new IDs in the target schema. It's intended
+// for re-sharding tenants between schemas without downtime.
+//
+// Running jobs can't be moved. If any of the given jobs is running, doesn't
+// exist, or is locked by another transaction, no jobs are moved and an error
+// is returned. Moving a job with a unique key fails if a conflicting job
+// already exists in the target schema.
+//
+// A maximum of 10,000 jobs can be moved at once.
+//
+//	moveRes, err := client.JobMove(ctx, []int64{job1.ID, job2.ID}, "tenant_shard_2")
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobMove(ctx context.Context, ids []int64, targetSchema string) (*JobMoveResult, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	return dbutil.WithTxV(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) (*JobMoveResult, error) {
+		return c.jobMove(ctx, execTx, ids, targetSchema)
+	})
+}
+
+// JobMoveTx moves the jobs with the given IDs to another schema within the
+// given transaction. See JobMove for details.
+//
+// Both the client's schema and the target schema must be accessible to the
+// transaction.
+func (c *Client[TTx]) JobMoveTx(ctx context.Context, tx TTx, ids []int64, targetSchema string) (*JobMoveResult, error) {
+	return c.jobMove(ctx, c.driver.UnwrapExecutor(tx), ids, targetSchema)
+}
+
+func (c *Client[TTx]) jobMove(ctx context.Context, execTx riverdriver.ExecutorTx, ids []int64, targetSchema string) (*JobMoveResult, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	if len(ids) > jobMoveMax {
+		return nil, fmt.Errorf("at most %d jobs can be moved at once", jobMoveMax)
+	}
+	if targetSchema == "" || !postgresSchemaNameRE.MatchString(targetSchema) {
+		return nil, errors.New("target schema name can only contain letters, numbers, and underscores, and must start with a letter or underscore")
+	}
+	if targetSchema == c.config.Schema {
+		return nil, errors.New("target schema must be different from the client's schema")
+	}
+
+	if len(ids) < 1 {
+		return &JobMoveResult{Jobs: []*rivertype.JobRow{}}, nil
+	}
+
+	listParams, err := dblist.JobMakeDriverParams(ctx, (&JobDeleteManyParams{ids: ids, limit: int32(len(ids)), schema: c.config.Schema}).toDBParams(), c.driver.SQLFragmentColumnIn) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleting first locks the jobs being moved, and skips any that are
+	// running or already locked so they can't be moved out from underneath
+	// another transaction.
+	deletedJobs, err := execTx.JobDeleteMany(ctx, (*riverdriver.JobDeleteManyParams)(listParams))
+	if err != nil {
+		return nil, err
+	}
+
+	deletedJobsByID := make(map[int64]*rivertype.JobRow, len(deletedJobs))
+	for _, job := range deletedJobs {
+		deletedJobsByID[job.ID] = job
+	}
+
+	var notMovedIDs []int64
+	for _, id := range ids {
+		if _, ok := deletedJobsByID[id]; !ok {
+			notMovedIDs = append(notMovedIDs, id)
+		}
+	}
+	if len(notMovedIDs) > 0 {
+		return nil, fmt.Errorf("jobs can't be moved because they don't exist, are running, or are locked: %v", notMovedIDs)
+	}
+
+	var (
+		movedJobs      = make([]*rivertype.JobRow, 0, len(ids))
+		queuesToNotify = make(map[string]struct{})
+	)
+	for _, id := range ids {
+		job := deletedJobsByID[id]
+
+		errorsBytes := make([][]byte, len(job.Errors))
+		for i, attemptErr := range job.Errors {
+			if errorsBytes[i], err = json.Marshal(attemptErr); err != nil {
+				return nil, fmt.Errorf("error marshaling job errors: %w", err)
+			}
+		}
+
+		movedJob, err := execTx.JobInsertFull(ctx, &riverdriver.JobInsertFullParams{
+			Attempt:      job.Attempt,
+			AttemptedAt:  job.AttemptedAt,
+			AttemptedBy:  job.AttemptedBy,
+			CreatedAt:    &job.CreatedAt,
+			EncodedArgs:  job.EncodedArgs,
+			Errors:       errorsBytes,
+			FinalizedAt:  job.FinalizedAt,
+			Kind:         job.Kind,
+			MaxAttempts:  job.MaxAttempts,
+			Metadata:     job.Metadata,
+			Priority:     job.Priority,
+			Queue:        job.Queue,
+			ScheduledAt:  &job.ScheduledAt,
+			Schema:       targetSchema,
+			State:        job.State,
+			Tags:         job.Tags,
+			UniqueKey:    job.UniqueKey,
+			UniqueStates: uniquestates.UniqueStatesToBitmask(job.UniqueStates),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error inserting job %d into target schema: %w", id, err)
+		}
+
+		movedJobs = append(movedJobs, movedJob)
+
+		if movedJob.State == rivertype.JobStateAvailable {
+			queuesToNotify[movedJob.Queue] = struct{}{}
+		}
+	}
+
+	// Wake up clients working the target schema so they pick up moved jobs
+	// right away instead of waiting for their next poll.
+	if len(queuesToNotify) > 0 && c.driver.SupportsListenNotify() {
+		queues := maputil.Keys(queuesToNotify)
+		slices.Sort(queues)
 
-// JobDeleteTx deletes the job with the given ID from the database, returning the
-// deleted row if it was deleted. Jobs in the running state are not deleted,
-// instead returning rivertype.ErrJobRunning. This variant lets a caller retry a
-// job atomically alongside other database changes. A deleted job isn't deleted
-// until the transaction commits, and if the transaction rolls back, so too is
-// the deleted job.
-func (c *Client[TTx]) JobDeleteTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobRow, error) {
-	return c.driver.UnwrapExecutor(tx).JobDelete(ctx, &riverdriver.JobDeleteParams{
-		ID:     id,
-		Schema: c.config.Schema,
-	})
-}
+		payloads := make([]string, 0, len(queues))
+		for _, queue := range queues {
+			payload, err := rivernotify.EncodeInsert(&rivernotify.InsertPayload{Queue: queue})
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, payload)
+		}
 
-// JobGet fetches a single job by its ID. Returns the up-to-date JobRow for the
-// specified jobID if it exists. Returns ErrNotFound if the job doesn't exist.
-func (c *Client[TTx]) JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error) {
-	return c.driver.GetExecutor().JobGetByID(ctx, &riverdriver.JobGetByIDParams{
-		ID:     id,
-		Schema: c.config.Schema,
-	})
-}
+		if err := execTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+			Payload: payloads,
+			Schema:  targetSchema,
+			Topic:   string(notifier.NotificationTopicInsert),
+		}); err != nil {
+			return nil, err
+		}
+	}
 
-// JobGetTx fetches a single job by its ID, within a transaction. Returns the
-// up-to-date JobRow for the specified jobID if it exists. Returns ErrNotFound
-// if the job doesn't exist.
-func (c *Client[TTx]) JobGetTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobRow, error) {
-	return c.driver.UnwrapExecutor(tx).JobGetByID(ctx, &riverdriver.JobGetByIDParams{
-		ID:     id,
-		Schema: c.config.Schema,
-	})
+	return &JobMoveResult{Jobs: movedJobs}, nil
 }
 
 // JobRetry updates the job with the given ID to make it immediately available
@@ -1571,6 +3516,10 @@ func (c *Client[TTx]) JobRetryTx(ctx context.Context, tx TTx, id int64) (*rivert
 }
 
 func (c *Client[TTx]) jobRetry(ctx context.Context, exec riverdriver.Executor, id int64) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
 	return c.pilot.JobRetry(ctx, exec, &riverdriver.JobRetryParams{
 		ID:     id,
 		Now:    c.baseService.Time.NowOrNil(),
@@ -1578,6 +3527,94 @@ func (c *Client[TTx]) jobRetry(ctx context.Context, exec riverdriver.Executor, i
 	})
 }
 
+var errJobRetryWithNewArgsRunning = errors.New("running jobs cannot have their args changed")
+
+// JobRetryWithNewArgs replaces the args of the job with the given ID and makes
+// it immediately available to be retried. This is useful for rerunning a job
+// that failed because it was inserted with incorrect args. The new args must
+// be of the same kind as the job.
+//
+// Jobs in the running state are not touched and an error is returned. Like
+// JobRetry, scheduled_at is set to the current time only if it's not already
+// in the past, and MaxAttempts is incremented by one if the job has already
+// exhausted its max attempts.
+//
+// The job's unique key isn't recalculated, so a job that's unique by args will
+// still be considered unique based on its original args.
+//
+// Returns ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobRetryWithNewArgs(ctx context.Context, id int64, args JobArgs) (*rivertype.JobRow, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	return dbutil.WithTxV(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) (*rivertype.JobRow, error) {
+		return c.jobRetryWithNewArgs(ctx, execTx, id, args)
+	})
+}
+
+// JobRetryWithNewArgsTx replaces the args of the job with the given ID and
+// makes it immediately available to be retried, within the specified
+// transaction. This variant lets a caller retry a job atomically alongside
+// other database changes.
+//
+// See JobRetryWithNewArgs for more details.
+func (c *Client[TTx]) JobRetryWithNewArgsTx(ctx context.Context, tx TTx, id int64, args JobArgs) (*rivertype.JobRow, error) {
+	return c.jobRetryWithNewArgs(ctx, c.driver.UnwrapExecutor(tx), id, args)
+}
+
+func (c *Client[TTx]) jobRetryWithNewArgs(ctx context.Context, execTx riverdriver.ExecutorTx, id int64, args JobArgs) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	// Lock the job's row with an update that doesn't change anything before
+	// checking its state so that a producer can't fetch it between the check
+	// and the args update below. The update returns the job's most recently
+	// committed version, so a fetch that raced ahead of the lock is visible as
+	// a running state.
+	job, err := execTx.JobUpdateFull(ctx, &riverdriver.JobUpdateFullParams{
+		ID:     id,
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Kind() != job.Kind {
+		return nil, fmt.Errorf("args kind %q doesn't match job kind %q", args.Kind(), job.Kind)
+	}
+	if job.State == rivertype.JobStateRunning {
+		return nil, errJobRetryWithNewArgsRunning
+	}
+
+	// New args are encoded with the same codec as the job's existing args,
+	// but may be compressed or encrypted differently than the old ones were,
+	// so how they were encoded is updated in metadata to match.
+	codec, err := c.argsDecoder.CodecForJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedArgs, metadata, err := c.config.argsEncoder(codec).Encode(args, job.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling args: %w", err)
+	}
+
+	if _, err := execTx.JobUpdateFull(ctx, &riverdriver.JobUpdateFullParams{
+		ID:               id,
+		ArgsDoUpdate:     true,
+		Args:             encodedArgs,
+		MetadataDoUpdate: true,
+		Metadata:         metadata,
+		Schema:           c.config.Schema,
+	}); err != nil {
+		return nil, err
+	}
+
+	return c.jobRetry(ctx, execTx, id)
+}
+
 // JobUpdateParams contains parameters for Client.JobUpdate and Client.JobUpdateTx.
 type JobUpdateParams struct {
 	// Output is a new output value for a job.
@@ -1608,6 +3645,10 @@ func (c *Client[TTx]) JobUpdateTx(ctx context.Context, tx TTx, id int64, params
 }
 
 func (c *Client[TTx]) jobUpdate(ctx context.Context, exec riverdriver.Executor, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
 	if params == nil {
 		params = &JobUpdateParams{}
 	}
@@ -1676,37 +3717,54 @@ func (c *Client[TTx]) ID() string {
 }
 
 func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, config *Config, args JobArgs, insertOpts *InsertOpts) (*rivertype.JobInsertParams, error) {
-	encodedArgs, err := json.Marshal(args)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling args to JSON: %w", err)
-	}
-
 	if insertOpts == nil {
 		insertOpts = &InsertOpts{}
 	}
 
+	if argsWithValidate, ok := args.(JobArgsWithValidate); ok {
+		if err := argsWithValidate.Validate(); err != nil {
+			return nil, &JobArgsValidationError{Kind: args.Kind(), Err: err}
+		}
+	}
+
 	var jobInsertOpts InsertOpts
 	if argsWithOpts, ok := args.(JobArgsWithInsertOpts); ok {
 		jobInsertOpts = argsWithOpts.InsertOpts()
 	}
 
+	// Defaults from a `river` struct tag on the args type take precedence
+	// only over global defaults.
+	var tagInsertOpts InsertOpts
+	if opts, err := insertOptsFromStructTag(args); err != nil {
+		return nil, err
+	} else if opts != nil {
+		tagInsertOpts = *opts
+	}
+
+	// Defaults configured for the kind on the client apply where neither
+	// call site nor args specify a value.
+	kindInsertOpts := config.InsertOptsByKind[args.Kind()]
+
 	// If the time is stubbed (in a test), use that for `created_at`. Otherwise,
 	// leave an empty value which will either use the database's `now()` or be defaulted
 	// by drivers as necessary.
 	createdAt := archetype.Time.NowOrNil()
 
-	maxAttempts := cmp.Or(insertOpts.MaxAttempts, jobInsertOpts.MaxAttempts, config.MaxAttempts)
-	priority := cmp.Or(insertOpts.Priority, jobInsertOpts.Priority, rivercommon.PriorityDefault)
-	queue := cmp.Or(insertOpts.Queue, jobInsertOpts.Queue, rivercommon.QueueDefault)
+	maxAttempts := cmp.Or(insertOpts.MaxAttempts, jobInsertOpts.MaxAttempts, tagInsertOpts.MaxAttempts, kindInsertOpts.MaxAttempts, config.MaxAttempts)
+	priority := cmp.Or(insertOpts.Priority, jobInsertOpts.Priority, tagInsertOpts.Priority, kindInsertOpts.Priority, rivercommon.PriorityDefault)
+	queue := cmp.Or(insertOpts.Queue, jobInsertOpts.Queue, tagInsertOpts.Queue, kindInsertOpts.Queue, rivercommon.QueueDefault)
 
 	if err := validateQueueName(queue); err != nil {
 		return nil, err
 	}
 
 	tags := insertOpts.Tags
-	if insertOpts.Tags == nil {
+	if tags == nil {
 		tags = jobInsertOpts.Tags
 	}
+	if tags == nil {
+		tags = kindInsertOpts.Tags
+	}
 	if tags == nil {
 		tags = []string{}
 	} else {
@@ -1724,20 +3782,67 @@ func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, conf
 		return nil, errors.New("priority must be between 1 and 4")
 	}
 
+	for _, opts := range []*InsertOpts{insertOpts, &jobInsertOpts} {
+		if opts.ScheduleIn < 0 {
+			return nil, errors.New("ScheduleIn cannot be less than zero")
+		}
+		if opts.ScheduleIn != 0 && !opts.ScheduledAt.IsZero() {
+			return nil, errors.New("ScheduleIn and ScheduledAt cannot both be set")
+		}
+	}
+
 	var uniqueOpts UniqueOpts
 	if !config.Test.DisableUniqueEnforcement {
 		uniqueOpts = insertOpts.UniqueOpts
 		if uniqueOpts.isEmpty() {
 			uniqueOpts = jobInsertOpts.UniqueOpts
 		}
+		if uniqueOpts.isEmpty() {
+			uniqueOpts = kindInsertOpts.UniqueOpts
+		}
 	}
 	if err := uniqueOpts.validate(); err != nil {
 		return nil, err
 	}
 
-	metadata := insertOpts.Metadata
-	if len(metadata) == 0 {
-		metadata = []byte("{}")
+	// Encoding records how args were encoded in metadata, and defaults
+	// metadata to an empty object.
+	encodedArgs, metadata, err := config.argsEncoder(config.ArgsCodec).Encode(args, insertOpts.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling args: %w", err)
+	}
+
+	if metadata, err = setArgsVersion(metadata, args); err != nil {
+		return nil, err
+	}
+
+	externalID := insertOpts.ExternalID
+	if externalID == "" && config.ExternalIDFunc != nil {
+		externalID = config.ExternalIDFunc()
+	}
+	if externalID != "" {
+		if metadata, err = setExternalID(metadata, externalID); err != nil {
+			return nil, err
+		}
+	}
+
+	if partitionKey := cmp.Or(insertOpts.PartitionKey, jobInsertOpts.PartitionKey); partitionKey != "" {
+		if metadata, err = setPartitionKey(metadata, partitionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	requiredLabels := insertOpts.RequiredLabels
+	if requiredLabels == nil {
+		requiredLabels = jobInsertOpts.RequiredLabels
+	}
+	if requiredLabels == nil {
+		requiredLabels = kindInsertOpts.RequiredLabels
+	}
+	if len(requiredLabels) > 0 {
+		if metadata, err = setRequiredLabels(metadata, requiredLabels); err != nil {
+			return nil, err
+		}
 	}
 
 	insertParams := &rivertype.JobInsertParams{
@@ -1753,21 +3858,50 @@ func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, conf
 		Tags:        tags,
 	}
 	if !uniqueOpts.isEmpty() {
+		// Unique keys by args are derived from args as JSON, so they're the same
+		// regardless of the codec args were encoded with or whether they were
+		// compressed or encrypted.
+		uniqueKeyParams := insertParams
+		if config.argsEncodingCustomized() && uniqueOpts.ByArgs {
+			jsonArgs, err := json.Marshal(args)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling args to JSON: %w", err)
+			}
+
+			uniqueKeyParams = ptrutil.Ptr(*insertParams)
+			uniqueKeyParams.EncodedArgs = jsonArgs
+		}
+
 		internalUniqueOpts := (*dbunique.UniqueOpts)(&uniqueOpts)
-		insertParams.UniqueKey, err = dbunique.UniqueKey(archetype.Time, internalUniqueOpts, insertParams)
+		insertParams.UniqueKey, err = dbunique.UniqueKey(archetype.Time, internalUniqueOpts, uniqueKeyParams)
 		if err != nil {
 			return nil, err
 		}
 		insertParams.UniqueStates = internalUniqueOpts.StateBitmask()
 	}
 
+	// ScheduleIn is computed relative to the database's clock, unless time is
+	// stubbed, in which case it's relative to the stubbed time.
+	scheduleIn := func(scheduleIn time.Duration) {
+		if createdAt != nil {
+			insertParams.ScheduledAt = ptrutil.Ptr(createdAt.Add(scheduleIn))
+		} else {
+			insertParams.ScheduledIn = scheduleIn
+		}
+		insertParams.State = rivertype.JobStateScheduled
+	}
+
 	switch {
 	case !insertOpts.ScheduledAt.IsZero():
 		insertParams.ScheduledAt = &insertOpts.ScheduledAt
 		insertParams.State = rivertype.JobStateScheduled
+	case insertOpts.ScheduleIn > 0:
+		scheduleIn(insertOpts.ScheduleIn)
 	case !jobInsertOpts.ScheduledAt.IsZero():
 		insertParams.ScheduledAt = &jobInsertOpts.ScheduledAt
 		insertParams.State = rivertype.JobStateScheduled
+	case jobInsertOpts.ScheduleIn > 0:
+		scheduleIn(jobInsertOpts.ScheduleIn)
 	default:
 		// Use a stubbed time if there was one, but otherwise prefer the value
 		// generated by the database. createdAt is nil unless time is stubbed.
@@ -1778,6 +3912,10 @@ func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, conf
 		insertParams.State = rivertype.JobStatePending
 	}
 
+	if config.Test.JobIDFunc != nil {
+		insertParams.ID = ptrutil.Ptr(config.Test.JobIDFunc())
+	}
+
 	return insertParams, nil
 }
 
@@ -1793,6 +3931,8 @@ var errNoDriverDBPool = errors.New("driver must have non-nil database pool to us
 //	if err != nil {
 //		// handle error
 //	}
+//
+// See InsertHandle for a variant that returns a handle to the inserted job.
 func (c *Client[TTx]) Insert(ctx context.Context, args JobArgs, opts *InsertOpts) (*rivertype.JobInsertResult, error) {
 	if !c.driver.PoolIsSet() {
 		return nil, errNoDriverDBPool
@@ -1827,6 +3967,8 @@ func (c *Client[TTx]) Insert(ctx context.Context, args JobArgs, opts *InsertOpts
 // by the time it starts. Because of snapshot visibility guarantees across
 // transactions, the job will not be worked until the transaction has committed,
 // and if the transaction rolls back, so too is the inserted job.
+//
+// See InsertHandleTx for a variant that returns a handle to the inserted job.
 func (c *Client[TTx]) InsertTx(ctx context.Context, tx TTx, args JobArgs, opts *InsertOpts) (*rivertype.JobInsertResult, error) {
 	res, err := c.validateParamsAndInsertMany(ctx, c.driver.UnwrapExecutor(tx), []InsertManyParams{{Args: args, InsertOpts: opts}})
 	if err != nil {
@@ -1873,33 +4015,225 @@ func (c *Client[TTx]) InsertMany(ctx context.Context, params []InsertManyParams)
 
 	c.notifyProducerWithoutListenerJobFetch(ctx, res)
 
-	return res, nil
-}
+	return res, nil
+}
+
+// InsertManyTx inserts many jobs at once. Each job is inserted as an
+// InsertManyParams tuple, which takes job args along with an optional set of
+// insert options, which override insert options provided by an
+// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
+// The provided context is used for the underlying Postgres inserts and can be
+// used to cancel the operation or apply a timeout.
+//
+//	count, err := client.InsertManyTx(ctx, tx, []river.InsertManyParams{
+//		{Args: BatchInsertArgs{}},
+//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+//
+// This variant lets a caller insert jobs atomically alongside other database
+// changes. An inserted job isn't visible to be worked until the transaction
+// commits, and if the transaction rolls back, so too is the inserted job.
+func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
+	res, err := c.validateParamsAndInsertMany(ctx, c.driver.UnwrapExecutor(tx), params)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// The number of jobs inserted per transaction by InsertManyScheduled.
+const insertManyScheduledBatchSize = 1_000
+
+// InsertManyScheduled inserts many jobs so that they all become eligible to be
+// worked at the same moment. Jobs are first inserted in the `pending` state in
+// batches, each in its own transaction, so that no job can be worked while the
+// rest of a large batch is still being inserted. Once every job is inserted,
+// the whole batch is moved out of `pending` with a single database operation,
+// becoming `available` if scheduledAt has already passed, or `scheduled` to be
+// made available by the scheduler at scheduledAt otherwise. This is useful for
+// coordinated sends, like a campaign that should start at once rather than
+// trickle out as its jobs are inserted.
+//
+//	results, err := client.InsertManyScheduled(ctx, []river.InsertManyParams{
+//		{Args: CampaignSendArgs{UserID: 1}},
+//		{Args: CampaignSendArgs{UserID: 2}},
+//	}, campaignStartsAt)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Any ScheduledAt or Pending set in insert options is ignored in favor of
+// scheduledAt. Jobs skipped as unique duplicates are returned as is, and
+// aren't activated. If inserting a batch fails, jobs from batches already
+// inserted are deleted before the error is returned so that they aren't left
+// in `pending` indefinitely.
+func (c *Client[TTx]) InsertManyScheduled(ctx context.Context, params []InsertManyParams, scheduledAt time.Time) ([]*rivertype.JobInsertResult, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	insertParams, err := c.insertManyScheduledParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*rivertype.JobInsertResult, 0, len(insertParams))
+	for batch := range slices.Chunk(insertParams, insertManyScheduledBatchSize) {
+		batchResults, err := dbutil.WithTxV(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) ([]*rivertype.JobInsertResult, error) {
+			return c.insertMany(ctx, execTx, batch)
+		})
+		if err != nil {
+			c.insertManyScheduledCleanup(ctx, results)
+			return nil, err
+		}
+
+		results = append(results, batchResults...)
+	}
+
+	if err := dbutil.WithTx(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		return c.insertManyScheduledActivate(ctx, execTx, results, scheduledAt)
+	}); err != nil {
+		return nil, err
+	}
+
+	c.notifyProducerWithoutListenerJobFetch(ctx, results)
+
+	return results, nil
+}
+
+// InsertManyScheduledTx inserts many jobs so that they all become eligible to
+// be worked at the same moment. It works like InsertManyScheduled, except that
+// all jobs are inserted and activated on the given transaction, so none of
+// them are visible until it commits, and if the transaction rolls back, so too
+// are the inserted jobs.
+//
+//	results, err := client.InsertManyScheduledTx(ctx, tx, []river.InsertManyParams{
+//		{Args: CampaignSendArgs{UserID: 1}},
+//		{Args: CampaignSendArgs{UserID: 2}},
+//	}, campaignStartsAt)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) InsertManyScheduledTx(ctx context.Context, tx TTx, params []InsertManyParams, scheduledAt time.Time) ([]*rivertype.JobInsertResult, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	insertParams, err := c.insertManyScheduledParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	execTx := c.driver.UnwrapExecutor(tx)
+
+	results, err := c.insertMany(ctx, execTx, insertParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.insertManyScheduledActivate(ctx, execTx, results, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Validates params for InsertManyScheduled and generates insert params that
+// put every job in the `pending` state.
+func (c *Client[TTx]) insertManyScheduledParams(ctx context.Context, params []InsertManyParams) ([]*rivertype.JobInsertParams, error) {
+	insertParams, err := c.insertManyParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, insertParamsItem := range insertParams {
+		insertParamsItem.ScheduledAt = nil
+		insertParamsItem.State = rivertype.JobStatePending
+	}
+
+	return insertParams, nil
+}
+
+// Moves jobs inserted by InsertManyScheduled out of `pending` in a single
+// operation, updating results in place with the activated jobs.
+func (c *Client[TTx]) insertManyScheduledActivate(ctx context.Context, execTx riverdriver.ExecutorTx, results []*rivertype.JobInsertResult, scheduledAt time.Time) error {
+	// Jobs were already worked instead of being inserted.
+	if c.config.SynchronousMode {
+		return nil
+	}
+
+	var (
+		ids         = make([]int64, 0, len(results))
+		resultsByID = make(map[int64]*rivertype.JobInsertResult, len(results))
+	)
+	for _, result := range results {
+		if !result.UniqueSkippedAsDuplicate {
+			ids = append(ids, result.Job.ID)
+			resultsByID[result.Job.ID] = result
+		}
+	}
+
+	if len(ids) < 1 {
+		return nil
+	}
+
+	jobs, err := execTx.JobActivateMany(ctx, &riverdriver.JobActivateManyParams{
+		ID:          ids,
+		Now:         c.baseService.Time.NowOrNil(),
+		ScheduledAt: scheduledAt,
+		Schema:      c.config.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("error activating jobs: %w", err)
+	}
+
+	queues := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		resultsByID[job.ID].Job = job
+
+		if job.State == rivertype.JobStateAvailable {
+			queues = append(queues, job.Queue)
+		}
+	}
+
+	return c.maybeNotifyInsertForQueues(ctx, execTx, queues)
+}
+
+// Deletes jobs left in `pending` by an InsertManyScheduled that failed partway
+// through. Failure to clean up is logged rather than returned so that the
+// error that caused the insert to fail is the one that's surfaced.
+func (c *Client[TTx]) insertManyScheduledCleanup(ctx context.Context, results []*rivertype.JobInsertResult) {
+	if c.config.SynchronousMode {
+		return
+	}
+
+	ids := make([]int64, 0, len(results))
+	for _, result := range results {
+		if !result.UniqueSkippedAsDuplicate {
+			ids = append(ids, result.Job.ID)
+		}
+	}
 
-// InsertManyTx inserts many jobs at once. Each job is inserted as an
-// InsertManyParams tuple, which takes job args along with an optional set of
-// insert options, which override insert options provided by an
-// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
-// The provided context is used for the underlying Postgres inserts and can be
-// used to cancel the operation or apply a timeout.
-//
-//	count, err := client.InsertManyTx(ctx, tx, []river.InsertManyParams{
-//		{Args: BatchInsertArgs{}},
-//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
-//	})
-//	if err != nil {
-//		// handle error
-//	}
-//
-// This variant lets a caller insert jobs atomically alongside other database
-// changes. An inserted job isn't visible to be worked until the transaction
-// commits, and if the transaction rolls back, so too is the inserted job.
-func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
-	res, err := c.validateParamsAndInsertMany(ctx, c.driver.UnwrapExecutor(tx), params)
-	if err != nil {
-		return nil, err
+	for batch := range slices.Chunk(ids, insertManyScheduledBatchSize) {
+		if _, err := c.jobDeleteMany(ctx, c.driver.GetExecutor(), &JobDeleteManyParams{
+			ids:    batch,
+			limit:  int32(len(batch)), //nolint:gosec
+			states: []rivertype.JobState{rivertype.JobStatePending},
+		}); err != nil {
+			c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Error deleting pending jobs after failed scheduled insert",
+				slog.Int("num_jobs", len(batch)),
+				slog.String("err", err.Error()),
+			)
+			return
+		}
 	}
-	return res, nil
 }
 
 // validateParamsAndInsertMany is a helper method that wraps the insertMany
@@ -1907,7 +4241,11 @@ func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertM
 // insertMany method. This allows insertMany to be reused by the
 // PeriodicJobEnqueuer which cannot reference top-level river package types.
 func (c *Client[TTx]) validateParamsAndInsertMany(ctx context.Context, execTx riverdriver.ExecutorTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
-	insertParams, err := c.insertManyParams(params)
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	insertParams, err := c.insertManyParams(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -1919,10 +4257,23 @@ func (c *Client[TTx]) validateParamsAndInsertMany(ctx context.Context, execTx ri
 // by the PeriodicJobEnqueuer.
 func (c *Client[TTx]) insertMany(ctx context.Context, execTx riverdriver.ExecutorTx, insertParams []*rivertype.JobInsertParams) ([]*rivertype.JobInsertResult, error) {
 	return c.insertManyShared(ctx, execTx, insertParams, func(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
-		results, err := c.pilot.JobInsertMany(ctx, execTx, &riverdriver.JobInsertFastManyParams{
+		params := &riverdriver.JobInsertFastManyParams{
 			Jobs:   insertParams,
 			Schema: c.config.Schema,
-		})
+		}
+
+		var (
+			results []*riverdriver.JobInsertFastResult
+			err     error
+		)
+		if c.insertManyUseCopyFrom(len(insertParams)) {
+			results, err = execTx.JobInsertFastManyCopyFrom(ctx, params)
+			if errors.Is(err, riverdriver.ErrNotImplemented) {
+				results, err = c.pilot.JobInsertMany(ctx, execTx, params)
+			}
+		} else {
+			results, err = c.pilot.JobInsertMany(ctx, execTx, params)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -1935,6 +4286,75 @@ func (c *Client[TTx]) insertMany(ctx context.Context, execTx riverdriver.Executo
 	})
 }
 
+// Merges metadata contributed by any HookInsertMetadata hooks into the
+// metadata of the given insert params. Keys already present in metadata take
+// precedence over those from hooks.
+func (c *Client[TTx]) insertMetadataFromHooks(ctx context.Context, params *rivertype.JobInsertParams) error {
+	hooks := append(
+		c.hookLookupGlobal.ByHookKind(hooklookup.HookKindInsertMetadata),
+		c.hookLookupByJob.ByJobArgs(params.Args).ByHookKind(hooklookup.HookKindInsertMetadata)...,
+	)
+	if len(hooks) < 1 {
+		return nil
+	}
+
+	var metadata map[string]json.RawMessage
+	if len(params.Metadata) > 0 {
+		if err := json.Unmarshal(params.Metadata, &metadata); err != nil {
+			return fmt.Errorf("error unmarshaling metadata: %w", err)
+		}
+	}
+	if metadata == nil {
+		metadata = make(map[string]json.RawMessage)
+	}
+
+	var modified bool
+	for _, hook := range hooks {
+		hookMetadata, err := hook.(rivertype.HookInsertMetadata).InsertMetadata(ctx, params) //nolint:forcetypeassert
+		if err != nil {
+			return err
+		}
+
+		for key, val := range hookMetadata {
+			if _, ok := metadata[key]; ok {
+				continue
+			}
+
+			valJSON, err := json.Marshal(val)
+			if err != nil {
+				return fmt.Errorf("error marshaling metadata key %q: %w", key, err)
+			}
+
+			metadata[key] = valJSON
+			modified = true
+		}
+	}
+
+	if !modified {
+		return nil
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("error marshaling metadata: %w", err)
+	}
+	params.Metadata = metadataJSON
+
+	return nil
+}
+
+// Whether a batch of the given size should be inserted with COPY FROM. Pilots
+// other than the standard one may customize how jobs are inserted, so COPY
+// FROM is only used with the standard pilot.
+func (c *Client[TTx]) insertManyUseCopyFrom(numJobs int) bool {
+	if c.config.InsertManyCopyFromThreshold < 0 || numJobs < c.config.InsertManyCopyFromThreshold {
+		return false
+	}
+
+	_, isStandardPilot := c.pilot.(*riverpilot.StandardPilot)
+	return isStandardPilot
+}
+
 // The shared code path for all Insert and InsertMany methods. It takes a
 // function that executes the actual insert operation and allows for different
 // implementations of the insert query to be passed in, each mapping their
@@ -1947,6 +4367,10 @@ func (c *Client[TTx]) insertManyShared(
 ) ([]*rivertype.JobInsertResult, error) {
 	doInner := func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
 		for _, params := range insertParams {
+			if err := c.insertMetadataFromHooks(ctx, params); err != nil {
+				return nil, err
+			}
+
 			for _, hook := range append(
 				c.hookLookupGlobal.ByHookKind(hooklookup.HookKindInsertBegin),
 				c.hookLookupByJob.ByJobArgs(params.Args).ByHookKind(hooklookup.HookKindInsertBegin)...,
@@ -1955,17 +4379,33 @@ func (c *Client[TTx]) insertManyShared(
 					return nil, err
 				}
 			}
+
+			c.payloadSizeStats.record(params)
+
+			if c.config.PayloadSizeLimits != nil {
+				if err := c.config.PayloadSizeLimits.enforce(ctx, params, &c.payloadSizeStats); err != nil {
+					return nil, err
+				}
+			}
 		}
 
 		finalInsertParams := sliceutil.Map(insertParams, func(params *rivertype.JobInsertParams) *riverdriver.JobInsertFastParams {
 			return (*riverdriver.JobInsertFastParams)(params)
 		})
 
+		if c.config.SynchronousMode {
+			return c.workSynchronously(ctx, finalInsertParams)
+		}
+
 		insertResults, err := execute(ctx, finalInsertParams)
 		if err != nil {
 			return insertResults, err
 		}
 
+		if c.rateAnomalyMonitor != nil {
+			c.rateAnomalyMonitor.RecordInserts(insertParams)
+		}
+
 		queues := make([]string, 0, 10)
 		for _, params := range insertParams {
 			if params.State == rivertype.JobStateAvailable {
@@ -1998,18 +4438,14 @@ func (c *Client[TTx]) insertManyShared(
 
 // Validates input parameters for a batch insert operation and generates a set
 // of batch insert parameters.
-func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*rivertype.JobInsertParams, error) {
+func (c *Client[TTx]) insertManyParams(ctx context.Context, params []InsertManyParams) ([]*rivertype.JobInsertParams, error) {
 	if len(params) < 1 {
 		return nil, errors.New("no jobs to insert")
 	}
 
 	insertParams := make([]*rivertype.JobInsertParams, len(params))
 	for i, param := range params {
-		if err := c.validateJobArgs(param.Args); err != nil {
-			return nil, err
-		}
-
-		insertParamsItem, err := insertParamsFromConfigArgsAndOptions(&c.baseService.Archetype, c.config, param.Args, param.InsertOpts)
+		insertParamsItem, err := c.insertManyParamsItem(ctx, param)
 		if err != nil {
 			return nil, err
 		}
@@ -2020,6 +4456,30 @@ func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*rivertype.
 	return insertParams, nil
 }
 
+// Validates a single job's input parameters for a batch insert operation and
+// generates its insert parameters.
+func (c *Client[TTx]) insertManyParamsItem(ctx context.Context, param InsertManyParams) (*rivertype.JobInsertParams, error) {
+	param, err := c.config.runInsertParamsMiddleware(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateJobArgs(param.Args); err != nil {
+		return nil, err
+	}
+
+	insertParams, err := insertParamsFromConfigArgsAndOptions(&c.baseService.Archetype, c.config, param.Args, param.InsertOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateJobQueue(insertParams); err != nil {
+		return nil, err
+	}
+
+	return insertParams, nil
+}
+
 // Notifies an internal producer of new jobs being queued for work.  Only
 // invoked if the client's driver doesn't support a listener. If a listener is
 // supported, job notifications go out via listen/notify instead.
@@ -2131,7 +4591,11 @@ func (c *Client[TTx]) InsertManyFastTx(ctx context.Context, tx TTx, params []Ins
 }
 
 func (c *Client[TTx]) insertManyFast(ctx context.Context, execTx riverdriver.ExecutorTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
-	insertParams, err := c.insertManyParams(params)
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
+	insertParams, err := c.insertManyParams(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -2164,7 +4628,11 @@ func (c *Client[TTx]) maybeNotifyInsertForQueues(ctx context.Context, tx riverdr
 
 	for _, queue := range queuesDeduped {
 		if c.insertNotifyLimiter.ShouldTrigger(queue) {
-			payloads = append(payloads, fmt.Sprintf("{\"queue\": %q}", queue))
+			payload, err := rivernotify.EncodeInsert(&rivernotify.InsertPayload{Queue: queue})
+			if err != nil {
+				return err
+			}
+			payloads = append(payloads, payload)
 			queuesTriggered = append(queuesTriggered, queue)
 		}
 	}
@@ -2194,7 +4662,7 @@ func (c *Client[TTx]) maybeNotifyInsertForQueues(ctx context.Context, tx riverdr
 }
 
 // emit a notification about a queue being paused or resumed.
-func (c *Client[TTx]) notifyQueuePauseOrResume(ctx context.Context, tx riverdriver.ExecutorTx, action controlAction, queue string, opts *QueuePauseOpts) (*controlEventPayload, error) {
+func (c *Client[TTx]) notifyQueuePauseOrResume(ctx context.Context, tx riverdriver.ExecutorTx, action rivernotify.ControlAction, queue string, opts *QueuePauseOpts) (*rivernotify.ControlPayload, error) {
 	c.baseService.Logger.DebugContext(ctx,
 		c.baseService.Name+": Notifying about queue state change",
 		slog.String("action", string(action)),
@@ -2202,16 +4670,16 @@ func (c *Client[TTx]) notifyQueuePauseOrResume(ctx context.Context, tx riverdriv
 		slog.String("opts", fmt.Sprintf("%+v", opts)),
 	)
 
-	controlEvent := &controlEventPayload{Action: action, Queue: queue}
+	controlEvent := &rivernotify.ControlPayload{Action: action, Queue: queue}
 
-	payload, err := json.Marshal(controlEvent)
+	payload, err := rivernotify.EncodeControl(controlEvent)
 	if err != nil {
 		return nil, err
 	}
 
 	if c.driver.SupportsListenNotify() {
 		err = tx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
-			Payload: []string{string(payload)},
+			Payload: []string{payload},
 			Schema:  c.config.Schema,
 			Topic:   string(notifier.NotificationTopicControl),
 		})
@@ -2244,6 +4712,52 @@ func (c *Client[TTx]) validateJobArgs(args JobArgs) error {
 	return nil
 }
 
+// Validates that a job's queue is one its worker is bound to in case the worker
+// declares queues with WorkerWithQueues. This validation is skipped if the
+// client is configured as an insert-only (with no workers) or the job's kind
+// isn't registered.
+func (c *Client[TTx]) validateJobQueue(insertParams *rivertype.JobInsertParams) error {
+	if c.config.Workers == nil {
+		return nil
+	}
+
+	workerInfo, ok := c.config.Workers.workersMap[insertParams.Kind]
+	if !ok || workerInfo.queueAllowed(insertParams.Queue) {
+		return nil
+	}
+
+	return fmt.Errorf("job of kind %q can't be inserted to queue %q because its worker is bound to queues %v",
+		insertParams.Kind, insertParams.Queue, workerInfo.queues)
+}
+
+// Validates that every worker that declares queues with WorkerWithQueues has
+// at least one of them configured on the client. A worker that doesn't would
+// never have its jobs worked by this client, which is almost certainly a
+// configuration mistake.
+func (c *Client[TTx]) validateWorkerQueues() error {
+	c.producersMu.RLock()
+	defer c.producersMu.RUnlock()
+
+	kinds := maputil.Keys(c.config.Workers.workersMap)
+	slices.Sort(kinds)
+
+	for _, kind := range kinds {
+		workerInfo := c.config.Workers.workersMap[kind]
+		if len(workerInfo.queues) < 1 {
+			continue
+		}
+
+		if !slices.ContainsFunc(workerInfo.queues, func(queue string) bool {
+			_, ok := c.producersByQueueName[queue]
+			return ok
+		}) {
+			return fmt.Errorf("worker for kind %q is bound to queues %v, but none of them are configured on the client", kind, workerInfo.queues)
+		}
+	}
+
+	return nil
+}
+
 func (c *Client[TTx]) producerAdd(queueName string, queueConfig QueueConfig) (*producer, error) {
 	c.producersMu.Lock()
 	defer c.producersMu.Unlock()
@@ -2252,28 +4766,67 @@ func (c *Client[TTx]) producerAdd(queueName string, queueConfig QueueConfig) (*p
 		return nil, &QueueAlreadyAddedError{Name: queueName}
 	}
 
+	var retryBudgetLimiter *Limiter
+	if c.config.RetryBudget != nil {
+		retryBudgetLimiter = c.Limiter(retryBudgetLimiterName, c.config.RetryBudget.Limit, c.config.RetryBudget.periodOrDefault())
+	}
+
+	var completerBacklog func() (int, int)
+	if batchCompleter, ok := c.completer.(*jobcompleter.BatchCompleter); ok {
+		completerBacklog = batchCompleter.Backlog
+	}
+
 	producer := newProducer(&c.baseService.Archetype, c.driver.GetExecutor(), c.pilot, &producerConfig{
+		AdaptiveFetchBatchSize:       c.config.AdaptiveFetchBatchSize,
+		AdvisoryLockPrefix:           c.config.AdvisoryLockPrefix,
+		ArgsDecoder:                  c.argsDecoder,
+		AttemptInfo:                  c.attemptInfo,
+		CircuitOpen:                  c.databaseDegradation.IsCircuitOpen,
 		ClientID:                     c.config.ID,
 		Completer:                    c.completer,
+		CompleterBacklog:             completerBacklog,
+		CompleterBackpressureEngaged: c.completerBackpressure.IsEngaged,
 		ErrorHandler:                 c.config.ErrorHandler,
+		ErrorSizeLimits:              c.config.ErrorSizeLimits,
+		FairnessKey:                  queueConfig.FairnessKey,
+		FairnessWeights:              queueConfig.FairnessWeights,
 		FetchCooldown:                cmp.Or(queueConfig.FetchCooldown, c.config.FetchCooldown),
 		FetchPollInterval:            cmp.Or(queueConfig.FetchPollInterval, c.config.FetchPollInterval),
+		GlobalMaxWorkers:             queueConfig.GlobalMaxWorkers,
+		GlobalMaxWorkersByKind:       queueConfig.GlobalMaxWorkersByKind,
 		HookLookupByJob:              c.hookLookupByJob,
 		HookLookupGlobal:             c.hookLookupGlobal,
 		JobTimeout:                   c.config.JobTimeout,
+		Labels:                       c.config.Labels,
 		MaxWorkers:                   queueConfig.MaxWorkers,
+		MaxWorkersByKind:             queueConfig.MaxWorkersByKind,
 		MiddlewareLookupGlobal:       c.middlewareLookupGlobal,
 		Notifier:                     c.notifier,
+		PoisonPillDetected:           c.handlePoisonPill,
+		PoisonPillMaxCrashes:         c.config.PoisonPill.maxCrashes(),
 		Queue:                        queueName,
-		QueueEventCallback:           c.subscriptionManager.distributeQueueEvent,
+		QueueEventCallback:           c.subscriptionManager.distributeEvent,
 		QueuePollInterval:            c.config.queuePollInterval,
+		ReleaseJobsOnStop:            c.config.ReleaseJobsOnStop,
+		ResourceBudgetExceeded:       c.resourceGuard.IsExceeded,
+		RetryBudgetLimiter:           retryBudgetLimiter,
 		RetryPolicy:                  c.config.RetryPolicy,
 		SchedulerInterval:            c.config.schedulerInterval,
 		Schema:                       c.config.Schema,
 		StaleProducerRetentionPeriod: 5 * time.Minute,
+		UnknownJobKindPolicy:         c.config.UnknownJobKindPolicy,
+		UnknownJobKindWorkFunc:       c.config.UnknownJobKindWorkFunc,
+		UnknownJobKindsFetched:       &c.unknownJobKindsFetched,
+		VisibilityTimeout:            queueConfig.VisibilityTimeout,
+		WorkerQueuesEnforced:         c.config.WorkerQueuesEnforcedOnFetch,
+		WorkerSlots:                  c.workerSlots,
 		Workers:                      c.config.Workers,
 	})
 	c.producersByQueueName[queueName] = producer
+
+	if c.workerSlots != nil {
+		c.workerSlots.register(queueName, queueConfig.MaxWorkers, queueConfig.MinWorkers)
+	}
 	return producer, nil
 }
 
@@ -2299,6 +4852,10 @@ func (c *Client[TTx]) producerRemove(ctx context.Context, queueName string) erro
 
 	delete(c.producersByQueueName, queueName)
 
+	if c.workerSlots != nil {
+		c.workerSlots.unregister(queueName)
+	}
+
 	return nil
 }
 
@@ -2353,6 +4910,10 @@ func (c *Client[TTx]) JobDeleteManyTx(ctx context.Context, tx TTx, params *JobDe
 }
 
 func (c *Client[TTx]) jobDeleteMany(ctx context.Context, exec riverdriver.Executor, params *JobDeleteManyParams) (*JobDeleteManyResult, error) {
+	if c.config.ReadOnly {
+		return nil, ErrClientReadOnly
+	}
+
 	if params == nil {
 		params = NewJobDeleteManyParams()
 	}
@@ -2383,6 +4944,23 @@ type JobListResult struct {
 
 	// LastCursor is a cursor that can be used to list the next page of jobs.
 	LastCursor *JobListCursor
+
+	// Total is the total number of jobs matching the list's filters,
+	// regardless of pagination. It's only set if requested with
+	// JobListParams.CountTotal.
+	Total *JobListTotal
+}
+
+// JobListTotal is the total number of jobs matching a list operation's
+// filters. See JobListParams.CountTotal.
+type JobListTotal struct {
+	// Count is the number of matching jobs.
+	Count int
+
+	// Exact is true if Count is an exact count. It's false if more jobs
+	// matched than the maximum to count exactly, in which case Count is an
+	// estimate.
+	Exact bool
 }
 
 var errJobListParamsMetadataNotSupportedSQLite = errors.New("JobListParams.Metadata is not supported on SQLite")
@@ -2401,6 +4979,23 @@ func (c *Client[TTx]) JobList(ctx context.Context, params *JobListParams) (*JobL
 		return nil, errNoDriverDBPool
 	}
 
+	return c.jobList(ctx, c.driver.GetExecutor(), params)
+}
+
+// JobListTx returns a paginated list of jobs matching the provided filters. The
+// provided context is used for the underlying Postgres query and can be used to
+// cancel the operation or apply a timeout.
+//
+//	params := river.NewJobListParams().First(10).States(river.JobStateCompleted)
+//	jobRows, err := client.JobListTx(ctx, tx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListParams) (*JobListResult, error) {
+	return c.jobList(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobList(ctx context.Context, exec riverdriver.Executor, params *JobListParams) (*JobListResult, error) {
 	if params == nil {
 		params = NewJobListParams()
 	}
@@ -2420,7 +5015,7 @@ func (c *Client[TTx]) JobList(ctx context.Context, params *JobListParams) (*JobL
 		return nil, err
 	}
 
-	jobs, err := c.driver.GetExecutor().JobList(ctx, listParams)
+	jobs, err := exec.JobList(ctx, listParams)
 	if err != nil {
 		return nil, err
 	}
@@ -2429,29 +5024,24 @@ func (c *Client[TTx]) JobList(ctx context.Context, params *JobListParams) (*JobL
 	if len(jobs) > 0 {
 		res.LastCursor = jobListCursorFromJobAndParams(jobs[len(jobs)-1], params)
 	}
-	return res, nil
-}
 
-// JobListTx returns a paginated list of jobs matching the provided filters. The
-// provided context is used for the underlying Postgres query and can be used to
-// cancel the operation or apply a timeout.
-//
-//	params := river.NewJobListParams().First(10).States(river.JobStateCompleted)
-//	jobRows, err := client.JobListTx(ctx, tx, params)
-//	if err != nil {
-//		// handle error
-//	}
-func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListParams) (*JobListResult, error) {
-	if params == nil {
-		params = NewJobListParams()
+	if params.countTotalMax > 0 {
+		if res.Total, err = c.jobListTotal(ctx, exec, params); err != nil {
+			return nil, err
+		}
 	}
-	params.schema = c.config.Schema
 
-	if c.driver.DatabaseName() == riverdriver.DatabaseNameSQLite && params.metadataCalled {
-		return nil, errJobListParamsMetadataNotSupportedSQLite
-	}
+	return res, nil
+}
 
-	dbParams, err := params.toDBParams()
+// Counts jobs matching the filters of params for JobListParams.CountTotal.
+func (c *Client[TTx]) jobListTotal(ctx context.Context, exec riverdriver.Executor, params *JobListParams) (*JobListTotal, error) {
+	// The total is independent of the current page, so count without the
+	// cursor's predicate.
+	countParams := params.copy()
+	countParams.after = nil
+
+	dbParams, err := countParams.toDBParams()
 	if err != nil {
 		return nil, err
 	}
@@ -2461,16 +5051,30 @@ func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListPara
 		return nil, err
 	}
 
-	jobs, err := c.driver.UnwrapExecutor(tx).JobList(ctx, listParams)
+	countMatchingParams := &riverdriver.JobCountMatchingParams{
+		Max:         params.countTotalMax,
+		NamedArgs:   listParams.NamedArgs,
+		Schema:      listParams.Schema,
+		WhereClause: listParams.WhereClause,
+	}
+
+	count, err := exec.JobCountMatching(ctx, countMatchingParams)
 	if err != nil {
 		return nil, err
 	}
 
-	res := &JobListResult{Jobs: jobs}
-	if len(jobs) > 0 {
-		res.LastCursor = jobListCursorFromJobAndParams(jobs[len(jobs)-1], params)
+	if count < int(params.countTotalMax) {
+		return &JobListTotal{Count: count, Exact: true}, nil
 	}
-	return res, nil
+
+	// Too many matches to count exactly, so fall back to an estimate. The
+	// estimate is known to be low if it's less than what was counted.
+	estimate, err := exec.JobCountMatchingEstimate(ctx, countMatchingParams)
+	if err != nil && !errors.Is(err, riverdriver.ErrNotImplemented) {
+		return nil, err
+	}
+
+	return &JobListTotal{Count: max(count, estimate), Exact: false}, nil
 }
 
 // Notify retrieves a notification bundle for the client (in the sense of
@@ -2509,15 +5113,19 @@ func (c *ClientNotifyBundle[TTx]) RequestResignTx(ctx context.Context, tx TTx) e
 // notifyExecTx is a shared helper between Notify and NotifyTx that sends a
 // notification.
 func (c *ClientNotifyBundle[TTx]) requestResignTx(ctx context.Context, execTx riverdriver.ExecutorTx) error {
-	payloadStr, err := json.Marshal(&leadership.DBNotification{
-		Action: leadership.DBNotificationKindRequestResign,
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	payload, err := rivernotify.EncodeLeadership(&rivernotify.LeadershipPayload{
+		Action: rivernotify.LeadershipActionRequestResign,
 	})
 	if err != nil {
 		return err
 	}
 
 	return execTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
-		Payload: []string{string(payloadStr)},
+		Payload: []string{payload},
 		Schema:  c.config.Schema,
 		Topic:   string(notifier.NotificationTopicLeadership),
 	})
@@ -2552,27 +5160,41 @@ func (c *Client[TTx]) Pilot() riverpilot.Pilot {
 func (c *Client[TTx]) Queues() *QueueBundle { return c.queues }
 
 // QueueGet returns the queue with the given name. If the queue has not recently
-// been active or does not exist, returns ErrNotFound.
+// been active or does not exist, returns ErrNotFound. The returned queue
+// includes live counts of its available and running jobs, and of the clients
+// with jobs running (idle clients aren't counted).
 //
 // The provided context is used for the underlying Postgres query and can be
 // used to cancel the operation or apply a timeout.
 func (c *Client[TTx]) QueueGet(ctx context.Context, name string) (*rivertype.Queue, error) {
-	return c.driver.GetExecutor().QueueGet(ctx, &riverdriver.QueueGetParams{
-		Name:   name,
-		Schema: c.config.Schema,
-	})
+	return c.queueGet(ctx, c.driver.GetExecutor(), name)
 }
 
 // QueueGetTx returns the queue with the given name. If the queue has not recently
-// been active or does not exist, returns ErrNotFound.
+// been active or does not exist, returns ErrNotFound. The returned queue
+// includes live counts of its available and running jobs, and of the clients
+// with jobs running (idle clients aren't counted).
 //
 // The provided context is used for the underlying Postgres query and can be
 // used to cancel the operation or apply a timeout.
 func (c *Client[TTx]) QueueGetTx(ctx context.Context, tx TTx, name string) (*rivertype.Queue, error) {
-	return c.driver.UnwrapExecutor(tx).QueueGet(ctx, &riverdriver.QueueGetParams{
+	return c.queueGet(ctx, c.driver.UnwrapExecutor(tx), name)
+}
+
+func (c *Client[TTx]) queueGet(ctx context.Context, exec riverdriver.Executor, name string) (*rivertype.Queue, error) {
+	queue, err := exec.QueueGet(ctx, &riverdriver.QueueGetParams{
 		Name:   name,
 		Schema: c.config.Schema,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.queuesPopulateCounts(ctx, exec, []*rivertype.Queue{queue}); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
 }
 
 // QueueListResult is the result of a job list operation. It contains a list of
@@ -2598,15 +5220,7 @@ func (c *Client[TTx]) QueueList(ctx context.Context, params *QueueListParams) (*
 		params = NewQueueListParams()
 	}
 
-	queues, err := c.driver.GetExecutor().QueueList(ctx, &riverdriver.QueueListParams{
-		Max:    int(params.paginationCount),
-		Schema: c.config.Schema,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &QueueListResult{Queues: queues}, nil
+	return c.queueList(ctx, c.driver.GetExecutor(), params)
 }
 
 // QueueListTx returns a list of all queues that are currently active or were
@@ -2625,7 +5239,11 @@ func (c *Client[TTx]) QueueListTx(ctx context.Context, tx TTx, params *QueueList
 		params = NewQueueListParams()
 	}
 
-	queues, err := c.driver.UnwrapExecutor(tx).QueueList(ctx, &riverdriver.QueueListParams{
+	return c.queueList(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) queueList(ctx context.Context, exec riverdriver.Executor, params *QueueListParams) (*QueueListResult, error) {
+	queues, err := exec.QueueList(ctx, &riverdriver.QueueListParams{
 		Max:    int(params.paginationCount),
 		Schema: c.config.Schema,
 	})
@@ -2633,9 +5251,42 @@ func (c *Client[TTx]) QueueListTx(ctx context.Context, tx TTx, params *QueueList
 		return nil, err
 	}
 
+	if err := c.queuesPopulateCounts(ctx, exec, queues); err != nil {
+		return nil, err
+	}
+
 	return &QueueListResult{Queues: queues}, nil
 }
 
+// Populates live job and client counts on the given queues.
+func (c *Client[TTx]) queuesPopulateCounts(ctx context.Context, exec riverdriver.Executor, queues []*rivertype.Queue) error {
+	if len(queues) < 1 {
+		return nil
+	}
+
+	counts, err := exec.JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+		QueueNames: sliceutil.Map(queues, func(q *rivertype.Queue) string { return q.Name }),
+		Schema:     c.config.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("error counting queue jobs: %w", err)
+	}
+
+	countsByQueue := sliceutil.KeyBy(counts, func(count *riverdriver.JobCountByQueueAndStateResult) (string, *riverdriver.JobCountByQueueAndStateResult) {
+		return count.Queue, count
+	})
+
+	for _, queue := range queues {
+		if count, ok := countsByQueue[queue.Name]; ok {
+			queue.CountAvailable = int(count.CountAvailable)
+			queue.CountClientsWithRunningJobs = int(count.CountClientsWithRunningJobs)
+			queue.CountRunning = int(count.CountRunning)
+		}
+	}
+
+	return nil
+}
+
 // QueuePause pauses the queue with the given name. When a queue is paused,
 // clients will not fetch any more jobs for that particular queue. To pause all
 // queues at once, use the special queue name "*".
@@ -2648,6 +5299,10 @@ func (c *Client[TTx]) QueueListTx(ctx context.Context, tx TTx, params *QueueList
 // used to cancel the operation or apply a timeout. The opts are reserved for
 // future functionality.
 func (c *Client[TTx]) QueuePause(ctx context.Context, name string, opts *QueuePauseOpts) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
 	tx, err := c.driver.GetExecutor().Begin(ctx)
 	if err != nil {
 		return err
@@ -2662,7 +5317,7 @@ func (c *Client[TTx]) QueuePause(ctx context.Context, name string, opts *QueuePa
 		return err
 	}
 
-	controlEvent, err := c.notifyQueuePauseOrResume(ctx, tx, controlActionPause, name, opts)
+	controlEvent, err := c.notifyQueuePauseOrResume(ctx, tx, rivernotify.ControlActionPause, name, opts)
 	if err != nil {
 		return err
 	}
@@ -2688,6 +5343,10 @@ func (c *Client[TTx]) QueuePause(ctx context.Context, name string, opts *QueuePa
 // used to cancel the operation or apply a timeout. The opts are reserved for
 // future functionality.
 func (c *Client[TTx]) QueuePauseTx(ctx context.Context, tx TTx, name string, opts *QueuePauseOpts) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
 	executorTx := c.driver.UnwrapExecutor(tx)
 
 	if err := executorTx.QueuePause(ctx, &riverdriver.QueuePauseParams{
@@ -2698,7 +5357,7 @@ func (c *Client[TTx]) QueuePauseTx(ctx context.Context, tx TTx, name string, opt
 		return err
 	}
 
-	if _, err := c.notifyQueuePauseOrResume(ctx, executorTx, controlActionPause, name, opts); err != nil {
+	if _, err := c.notifyQueuePauseOrResume(ctx, executorTx, rivernotify.ControlActionPause, name, opts); err != nil {
 		return err
 	}
 
@@ -2718,6 +5377,10 @@ func (c *Client[TTx]) QueuePauseTx(ctx context.Context, tx TTx, name string, opt
 // used to cancel the operation or apply a timeout. The opts are reserved for
 // future functionality.
 func (c *Client[TTx]) QueueResume(ctx context.Context, name string, opts *QueuePauseOpts) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
 	tx, err := c.driver.GetExecutor().Begin(ctx)
 	if err != nil {
 		return err
@@ -2732,7 +5395,7 @@ func (c *Client[TTx]) QueueResume(ctx context.Context, name string, opts *QueueP
 		return err
 	}
 
-	controlEvent, err := c.notifyQueuePauseOrResume(ctx, tx, controlActionResume, name, opts)
+	controlEvent, err := c.notifyQueuePauseOrResume(ctx, tx, rivernotify.ControlActionResume, name, opts)
 	if err != nil {
 		return err
 	}
@@ -2759,6 +5422,10 @@ func (c *Client[TTx]) QueueResume(ctx context.Context, name string, opts *QueueP
 // used to cancel the operation or apply a timeout. The opts are reserved for
 // future functionality.
 func (c *Client[TTx]) QueueResumeTx(ctx context.Context, tx TTx, name string, opts *QueuePauseOpts) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
 	executorTx := c.driver.UnwrapExecutor(tx)
 
 	if err := executorTx.QueueResume(ctx, &riverdriver.QueueResumeParams{
@@ -2769,7 +5436,7 @@ func (c *Client[TTx]) QueueResumeTx(ctx context.Context, tx TTx, name string, op
 		return err
 	}
 
-	if _, err := c.notifyQueuePauseOrResume(ctx, executorTx, controlActionResume, name, opts); err != nil {
+	if _, err := c.notifyQueuePauseOrResume(ctx, executorTx, rivernotify.ControlActionResume, name, opts); err != nil {
 		return err
 	}
 
@@ -2784,7 +5451,10 @@ type QueueUpdateParams struct {
 }
 
 // QueueUpdate updates a queue's settings in the database. These settings
-// override the settings in the client (if applied).
+// override the settings in the client (if applied). When metadata is changed,
+// other clients are notified immediately through a control notification (or
+// on their next poll of the queue in poll only mode), and subscriptions on
+// clients working the queue receive an EventKindQueueMetadataChanged event.
 func (c *Client[TTx]) QueueUpdate(ctx context.Context, name string, params *QueueUpdateParams) (*rivertype.Queue, error) {
 	tx, err := c.driver.GetExecutor().Begin(ctx)
 	if err != nil {
@@ -2823,7 +5493,7 @@ func (c *Client[TTx]) QueueUpdateTx(ctx context.Context, tx TTx, name string, pa
 // Should only ever be invoked *outside* a transaction. If invoked within a
 // transaction, the producer wouldn't yet be able to access the state that
 // triggered the notification because it's not committed yet.
-func (c *Client[TTx]) notifyProducerWithoutListenerQueueControlEvent(queue string, controlEvent *controlEventPayload) {
+func (c *Client[TTx]) notifyProducerWithoutListenerQueueControlEvent(queue string, controlEvent *rivernotify.ControlPayload) {
 	if c.driver.SupportsListener() {
 		return
 	}
@@ -2840,7 +5510,11 @@ func (c *Client[TTx]) notifyProducerWithoutListenerQueueControlEvent(queue strin
 	}
 }
 
-func (c *Client[TTx]) queueUpdate(ctx context.Context, executorTx riverdriver.ExecutorTx, name string, params *QueueUpdateParams) (*rivertype.Queue, *controlEventPayload, error) {
+func (c *Client[TTx]) queueUpdate(ctx context.Context, executorTx riverdriver.ExecutorTx, name string, params *QueueUpdateParams) (*rivertype.Queue, *rivernotify.ControlPayload, error) {
+	if c.config.ReadOnly {
+		return nil, nil, ErrClientReadOnly
+	}
+
 	updateMetadata := len(params.Metadata) > 0
 
 	queue, err := executorTx.QueueUpdate(ctx, &riverdriver.QueueUpdateParams{
@@ -2857,20 +5531,20 @@ func (c *Client[TTx]) queueUpdate(ctx context.Context, executorTx riverdriver.Ex
 		return queue, nil, err
 	}
 
-	controlEvent := &controlEventPayload{
-		Action:   controlActionMetadataChanged,
+	controlEvent := &rivernotify.ControlPayload{
+		Action:   rivernotify.ControlActionMetadataChanged,
 		Metadata: params.Metadata,
 		Queue:    queue.Name,
 	}
 
-	payload, err := json.Marshal(controlEvent)
+	payload, err := rivernotify.EncodeControl(controlEvent)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if c.driver.SupportsListenNotify() {
 		if err := executorTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
-			Payload: []string{string(payload)},
+			Payload: []string{payload},
 			Schema:  c.config.Schema,
 			Topic:   string(notifier.NotificationTopicControl),
 		}); err != nil {
@@ -2987,3 +5661,29 @@ func defaultClientIDWithHost(startedAt time.Time, host string) string {
 
 	return host + "_" + strings.Replace(startedAt.Format(rfc3339Compact), ".", "_", 1)
 }
+
+// Returns a context for working jobs that's detached from cancellation of the
+// context passed to Start, so that it's only cancelled by the client. If
+// cancelWithStart is true, it's also cancelled as soon as the start context is
+// done, but with ErrStop as its cause like a client stop, and keeps the start
+// context's deadline.
+func newWorkContext(ctx context.Context, cancelWithStart bool) (context.Context, context.CancelCauseFunc) {
+	workCtx := context.WithoutCancel(ctx)
+	if !cancelWithStart {
+		return context.WithCancelCause(workCtx)
+	}
+
+	deadlineCancel := func() {}
+	if deadline, ok := ctx.Deadline(); ok {
+		workCtx, deadlineCancel = context.WithDeadlineCause(workCtx, deadline, rivercommon.ErrStop)
+	}
+
+	workCtx, workCancel := context.WithCancelCause(workCtx)
+	stopCancelOnStartDone := context.AfterFunc(ctx, func() { workCancel(rivercommon.ErrStop) })
+
+	return workCtx, func(cause error) {
+		workCancel(cause)
+		stopCancelOnStartDone()
+		deadlineCancel()
+	}
+}
@@ -21,10 +21,12 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
 	"github.com/riverqueue/river/internal/dbunique"
 	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/leadership"
 	"github.com/riverqueue/river/internal/maintenance"
 	"github.com/riverqueue/river/internal/middlewarelookup"
 	"github.com/riverqueue/river/internal/notifier"
@@ -34,6 +36,7 @@ import (
 	"github.com/riverqueue/river/riverdbtest"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
 	"github.com/riverqueue/river/rivershared/riversharedtest"
@@ -952,6 +955,75 @@ func Test_Client_Common(t *testing.T) {
 		require.Equal(t, 0, event.Job.Attempt)
 	})
 
+	t.Run("JobSnoozeLimitExceededDiscards", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		AddWorker(client.config.Workers, &snoozeLimitWorker{snoozeLimit: &SnoozeLimit{Action: SnoozeLimitActionDiscard, MaxSnoozes: 2}})
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, &snoozeLimitArgs{}, &InsertOpts{Metadata: []byte(`{"snoozes": 2}`)})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, rivertype.JobStateDiscarded, event.Job.State)
+
+		updatedJob, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, updatedJob.State)
+		require.Len(t, updatedJob.Errors, 1)
+		require.Equal(t, "job exceeded its snooze limit of 2", updatedJob.Errors[0].Error)
+	})
+
+	t.Run("JobSnoozeLimitExceededErrors", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		AddWorker(client.config.Workers, &snoozeLimitWorker{snoozeLimit: &SnoozeLimit{MaxSnoozes: 2}})
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, &snoozeLimitArgs{}, &InsertOpts{Metadata: []byte(`{"snoozes": 2}`)})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
+
+		updatedJob, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, updatedJob.State)
+		require.Len(t, updatedJob.Errors, 1)
+		require.Equal(t, "job exceeded its snooze limit of 2", updatedJob.Errors[0].Error)
+	})
+
+	t.Run("JobSnoozeUnderLimitSnoozes", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		AddWorker(client.config.Workers, &snoozeLimitWorker{snoozeLimit: &SnoozeLimit{Action: SnoozeLimitActionDiscard, MaxSnoozes: 2}})
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, &snoozeLimitArgs{}, &InsertOpts{Metadata: []byte(`{"snoozes": 1}`)})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, EventKindJobSnoozed, event.Kind)
+		require.Equal(t, rivertype.JobStateScheduled, event.Job.State)
+	})
+
 	// This helper is used to test cancelling a job both _in_ a transaction and
 	// _outside of_ a transaction. The exact same test logic applies to each case,
 	// the only difference is a different cancelFunc provided by the specific
@@ -1043,6 +1115,51 @@ func Test_Client_Common(t *testing.T) {
 		})
 	})
 
+	t.Run("CancelRunningJobWithReason", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		var (
+			jobCauseChan   = make(chan error, 1)
+			jobStartedChan = make(chan int64)
+		)
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			jobCauseChan <- context.Cause(ctx)
+			return ctx.Err()
+		}))
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		insertRes, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, jobStartedChan)
+
+		updatedJob, err := client.JobCancelWithReason(ctx, insertRes.Job.ID, "no longer needed")
+		require.NoError(t, err)
+		require.Equal(t, "no longer needed", gjson.GetBytes(updatedJob.Metadata, "cancel_reason").String())
+
+		cause := riversharedtest.WaitOrTimeout(t, jobCauseChan)
+		require.ErrorIs(t, cause, ErrJobCancelledRemotely)
+		var cancelledErr *JobCancelledRemotelyError
+		require.ErrorAs(t, cause, &cancelledErr)
+		require.Equal(t, "no longer needed", cancelledErr.Reason)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCancelled, event.Kind)
+		require.Len(t, event.Job.Errors, 1)
+		require.Equal(t, "JobCancelError: job cancelled remotely: no longer needed", event.Job.Errors[0].Error)
+	})
+
 	t.Run("CancelRunningJobPollOnly", func(t *testing.T) {
 		t.Parallel()
 
@@ -1162,7 +1279,7 @@ func Test_Client_Common(t *testing.T) {
 
 		controlEvent := client.producersByQueueName[QueueDefault].testSignals.QueueControlEventTriggered.WaitOrTimeout()
 		require.NotNil(t, controlEvent)
-		require.Equal(t, controlActionCancel, controlEvent.Action)
+		require.Equal(t, rivernotify.ControlActionCancel, controlEvent.Action)
 	})
 
 	t.Run("CancelProducerControlEventNotSent", func(t *testing.T) {
@@ -1243,6 +1360,52 @@ func Test_Client_Common(t *testing.T) {
 		require.True(t, insertBeginHookCalled)
 	})
 
+	t.Run("WithGlobalInsertMetadataHook", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		bundle.config.Hooks = []rivertype.Hook{
+			HookInsertMetadataFunc(func(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error) {
+				return map[string]any{"tenant_id": 123, "trace_id": "hook_trace"}, nil
+			}),
+			HookInsertBeginFunc(func(ctx context.Context, params *rivertype.JobInsertParams) error {
+				require.Equal(t, "hook_trace", gjson.GetBytes(params.Metadata, "trace_id").String())
+				return nil
+			}),
+		}
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"tenant_id":123,"trace_id":"hook_trace"}`, string(insertRes.Job.Metadata))
+
+		// Metadata given at insertion time takes precedence.
+		insertRes, err = client.Insert(ctx, noOpArgs{}, &InsertOpts{Metadata: []byte(`{"trace_id":"insert_trace"}`)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"tenant_id":123,"trace_id":"insert_trace"}`, string(insertRes.Job.Metadata))
+	})
+
+	t.Run("WithGlobalInsertMetadataHookError", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		bundle.config.Hooks = []rivertype.Hook{
+			HookInsertMetadataFunc(func(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error) {
+				return nil, errors.New("hook error")
+			}),
+		}
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		_, err = client.Insert(ctx, noOpArgs{}, nil)
+		require.EqualError(t, err, "hook error")
+	})
+
 	t.Run("HookArchetypeInitialized", func(t *testing.T) {
 		t.Parallel()
 
@@ -1611,6 +1774,100 @@ func Test_Client_Common(t *testing.T) {
 		require.True(t, middlewareCalled)
 	})
 
+	t.Run("WithWorkContext", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		type privateKey string
+
+		bundle.config.WorkContext = func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, privateKey("service"), "from_work_context")
+		}
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			require.Equal(t, "from_work_context", ctx.Value(privateKey("service")))
+
+			// The client is still available from the derived context.
+			_, err := ClientFromContextSafely[pgx.Tx](ctx)
+			return err
+		}))
+
+		driver := riverpgxv5.New(bundle.dbPool)
+		client, err := NewClient(driver, bundle.config)
+		require.NoError(t, err)
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		result, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, result.Job.ID, event.Job.ID)
+	})
+
+	t.Run("WithSynchronousMode", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+		bundle.config.SynchronousMode = true
+
+		var middlewareCalled bool
+		bundle.config.Middleware = []rivertype.Middleware{&overridableJobMiddleware{
+			workFunc: func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				middlewareCalled = true
+				return doInner(ctx)
+			},
+		}}
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+
+			Fail bool `json:"fail"`
+		}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			if job.Args.Fail {
+				return errors.New("job failed")
+			}
+			return RecordOutput(ctx, "worked")
+		}))
+
+		driver := riverpgxv5.New(bundle.dbPool)
+		client, err := NewClient(driver, bundle.config)
+		require.NoError(t, err)
+
+		subscribeChan := subscribe(t, client)
+
+		// The client isn't started, and jobs are worked before Insert returns.
+		result, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, result.Job.State)
+		require.JSONEq(t, `"worked"`, string(result.Job.Output()))
+		require.True(t, middlewareCalled)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, result.Job.ID, event.Job.ID)
+
+		result, err = client.Insert(ctx, JobArgs{Fail: true}, nil)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, result.Job.State)
+		require.Len(t, result.Job.Errors, 1)
+		require.Equal(t, "job failed", result.Job.Errors[0].Error)
+
+		// Nothing was inserted.
+		listRes, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Empty(t, listRes.Jobs)
+	})
+
 	t.Run("WithWorkerMiddlewareOnWorker", func(t *testing.T) {
 		t.Parallel()
 
@@ -1661,6 +1918,65 @@ func Test_Client_Common(t *testing.T) {
 		require.True(t, middlewareCalled)
 	})
 
+	t.Run("WithWorkerMiddlewareByKind", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		type OtherJobArgs struct {
+			testutil.JobArgsReflectKind[OtherJobArgs]
+		}
+
+		var (
+			calledMu sync.Mutex
+			called   = make(map[string][]string) // job kind -> middleware names
+		)
+		recordingMiddleware := func(name string) rivertype.WorkerMiddleware {
+			return WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				calledMu.Lock()
+				called[job.Kind] = append(called[job.Kind], name)
+				calledMu.Unlock()
+				return doInner(ctx)
+			})
+		}
+
+		bundle.config.Middleware = []rivertype.Middleware{recordingMiddleware("global")}
+
+		AddWorker(bundle.config.Workers, &workerWithMiddleware[JobArgs]{
+			workFunc: func(ctx context.Context, job *Job[JobArgs]) error { return nil },
+			middlewareFunc: func(job *rivertype.JobRow) []rivertype.WorkerMiddleware {
+				return []rivertype.WorkerMiddleware{recordingMiddleware("worker")}
+			},
+		})
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[OtherJobArgs]) error { return nil }))
+		AddWorkerMiddleware(bundle.config.Workers, []string{(JobArgs{}).Kind()}, recordingMiddleware("kind1"), recordingMiddleware("kind2"))
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		_, err = client.InsertMany(ctx, []InsertManyParams{{Args: JobArgs{}}, {Args: OtherJobArgs{}}})
+		require.NoError(t, err)
+
+		for _, event := range riversharedtest.WaitOrTimeoutN(t, subscribeChan, 2) {
+			require.Equal(t, EventKindJobCompleted, event.Kind)
+		}
+
+		calledMu.Lock()
+		defer calledMu.Unlock()
+
+		require.Equal(t, map[string][]string{
+			(JobArgs{}).Kind():      {"kind1", "kind2", "worker", "global"},
+			(OtherJobArgs{}).Kind(): {"global"},
+		}, called)
+	})
+
 	t.Run("MiddlewareModifiesEncodedArgs", func(t *testing.T) {
 		t.Parallel()
 
@@ -1978,13 +2294,13 @@ func Test_Client_Common(t *testing.T) {
 
 		controlEvent := client.producersByQueueName[QueueDefault].testSignals.QueueControlEventTriggered.WaitOrTimeout()
 		require.NotNil(t, controlEvent)
-		require.Equal(t, controlActionPause, controlEvent.Action)
+		require.Equal(t, rivernotify.ControlActionPause, controlEvent.Action)
 
 		require.NoError(t, client.QueueResume(ctx, QueueDefault, nil))
 
 		controlEvent = client.producersByQueueName[QueueDefault].testSignals.QueueControlEventTriggered.WaitOrTimeout()
 		require.NotNil(t, controlEvent)
-		require.Equal(t, controlActionResume, controlEvent.Action)
+		require.Equal(t, rivernotify.ControlActionResume, controlEvent.Action)
 	})
 
 	t.Run("QueuePauseAndResumeProducerControlEventNotSent", func(t *testing.T) {
@@ -2654,32 +2970,156 @@ func Test_Client_SoftStopTimeout(t *testing.T) {
 	})
 }
 
-type callbackWithCustomTimeoutArgs struct {
-	TimeoutValue time.Duration `json:"timeout"`
-}
+func Test_Client_ReleaseJobsOnStop(t *testing.T) {
+	t.Parallel()
 
-func (callbackWithCustomTimeoutArgs) Kind() string { return "callbackWithCustomTimeout" }
+	ctx := context.Background()
 
-type callbackWorkerWithCustomTimeout struct {
-	WorkerDefaults[callbackWithCustomTimeoutArgs]
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
 
-	fn func(context.Context, *Job[callbackWithCustomTimeoutArgs]) error
-}
+	setupConfig := func(t *testing.T, releaseJobsOnStop bool) (*Config, chan struct{}) {
+		t.Helper()
 
-func (w *callbackWorkerWithCustomTimeout) Work(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
-	return w.fn(ctx, job)
-}
+		config := newTestConfig(t, "")
+		config.ReleaseJobsOnStop = releaseJobsOnStop
 
-func (w *callbackWorkerWithCustomTimeout) Timeout(job *Job[callbackWithCustomTimeoutArgs]) time.Duration {
-	return job.Args.TimeoutValue
-}
+		jobStartedChan := make(chan struct{})
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			close(jobStartedChan)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
 
-func Test_Client_JobContextInheritsFromProvidedContext(t *testing.T) {
-	t.Parallel()
+		return config, jobStartedChan
+	}
 
-	deadline := time.Now().Add(2 * time.Minute)
+	setup := func(t *testing.T, releaseJobsOnStop bool) (*Client[pgx.Tx], chan struct{}) {
+		t.Helper()
 
-	jobCtxCh := make(chan context.Context)
+		config, jobStartedChan := setupConfig(t, releaseJobsOnStop)
+
+		return runNewTestClient(ctx, t, config), jobStartedChan
+	}
+
+	t.Run("ReleasesInterruptedJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, jobStartedChan := setup(t, true)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, jobStartedChan)
+
+		require.NoError(t, client.StopAndCancel(ctx))
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+		require.Zero(t, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+
+	t.Run("ReleasesJobsInterruptedByStartContextCancellation", func(t *testing.T) {
+		t.Parallel()
+
+		config, jobStartedChan := setupConfig(t, true)
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+		)
+		config.Schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+
+		client, err := NewClient(driver, config)
+		require.NoError(t, err)
+
+		startCtx, startCancel := context.WithCancel(ctx)
+		t.Cleanup(startCancel)
+
+		require.NoError(t, client.Start(startCtx))
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, jobStartedChan)
+
+		// Without SoftStopTimeout, cancelling the start context is a hard stop
+		// equivalent to StopAndCancel.
+		startCancel()
+		riversharedtest.WaitOrTimeout(t, client.Stopped())
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+		require.Zero(t, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+
+	t.Run("RetriesInterruptedJobsByDefault", func(t *testing.T) {
+		t.Parallel()
+
+		client, jobStartedChan := setup(t, false)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, jobStartedChan)
+
+		require.NoError(t, client.StopAndCancel(ctx))
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Equal(t, 1, job.Attempt)
+		require.Len(t, job.Errors, 1)
+	})
+}
+
+type snoozeLimitArgs struct{}
+
+func (snoozeLimitArgs) Kind() string { return "snooze_limit" }
+
+type snoozeLimitWorker struct {
+	WorkerDefaults[snoozeLimitArgs]
+
+	snoozeLimit *SnoozeLimit
+}
+
+func (w *snoozeLimitWorker) SnoozeLimit() *SnoozeLimit { return w.snoozeLimit }
+
+func (w *snoozeLimitWorker) Work(ctx context.Context, job *Job[snoozeLimitArgs]) error {
+	return JobSnooze(15 * time.Minute)
+}
+
+type callbackWithCustomTimeoutArgs struct {
+	TimeoutValue time.Duration `json:"timeout"`
+}
+
+func (callbackWithCustomTimeoutArgs) Kind() string { return "callbackWithCustomTimeout" }
+
+type callbackWorkerWithCustomTimeout struct {
+	WorkerDefaults[callbackWithCustomTimeoutArgs]
+
+	fn func(context.Context, *Job[callbackWithCustomTimeoutArgs]) error
+}
+
+func (w *callbackWorkerWithCustomTimeout) Work(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
+	return w.fn(ctx, job)
+}
+
+func (w *callbackWorkerWithCustomTimeout) Timeout(job *Job[callbackWithCustomTimeoutArgs]) time.Duration {
+	return job.Args.TimeoutValue
+}
+
+func Test_Client_JobContextInheritsFromProvidedContext(t *testing.T) {
+	t.Parallel()
+
+	deadline := time.Now().Add(2 * time.Minute)
+
+	jobCtxCh := make(chan context.Context)
 	doneCh := make(chan struct{})
 	close(doneCh)
 
@@ -2756,6 +3196,260 @@ func Test_Client_ClientFromContext(t *testing.T) {
 	require.Equal(t, client, clientResult)
 }
 
+func Test_Client_JobChangeQueue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("MovesMatchingJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			job1       = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("wrong_queue"), Schema: bundle.schema})
+			job2       = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("wrong_queue"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+			runningJob = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("wrong_queue"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			otherJob   = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("other_queue"), Schema: bundle.schema})
+		)
+
+		changeRes, err := client.JobChangeQueue(ctx, NewJobChangeQueueParams().Queues("wrong_queue"), "right_queue")
+		require.NoError(t, err)
+		require.Equal(t, 2, changeRes.NumJobs)
+
+		for _, job := range []*rivertype.JobRow{job1, job2} {
+			updatedJob, err := client.JobGet(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, "right_queue", updatedJob.Queue)
+			require.Equal(t, job.State, updatedJob.State)
+		}
+
+		// Running jobs are never moved.
+		updatedRunningJob, err := client.JobGet(ctx, runningJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, "wrong_queue", updatedRunningJob.Queue)
+
+		// Non-matching job is left alone.
+		updatedOtherJob, err := client.JobGet(ctx, otherJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, "other_queue", updatedOtherJob.Queue)
+	})
+
+	t.Run("MovesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		numJobs := jobChangeQueueBatchSize + 10
+
+		_, err := bundle.exec.JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+			Jobs: sliceutil.Map(make([]struct{}, numJobs), func(struct{}) *riverdriver.JobInsertFastParams {
+				return &riverdriver.JobInsertFastParams{
+					EncodedArgs: []byte(`{}`),
+					Kind:        "misrouted_kind",
+					MaxAttempts: rivercommon.MaxAttemptsDefault,
+					Metadata:    []byte(`{}`),
+					Priority:    rivercommon.PriorityDefault,
+					Queue:       QueueDefault,
+					State:       rivertype.JobStateAvailable,
+				}
+			}),
+			Schema: bundle.schema,
+		})
+		require.NoError(t, err)
+
+		changeRes, err := client.JobChangeQueue(ctx, NewJobChangeQueueParams().Kinds("misrouted_kind"), "right_queue")
+		require.NoError(t, err)
+		require.Equal(t, numJobs, changeRes.NumJobs)
+
+		countRes, err := bundle.exec.JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+			QueueNames: []string{QueueDefault, "right_queue"},
+			Schema:     bundle.schema,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.JobCountByQueueAndStateResult{
+			{CountAvailable: 0, Queue: QueueDefault},
+			{CountAvailable: int64(numJobs), Queue: "right_queue"},
+		}, countRes)
+	})
+
+	t.Run("NoFiltersError", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobChangeQueue(ctx, NewJobChangeQueueParams(), "right_queue")
+		require.EqualError(t, err, "change queue with no filters not allowed to prevent accidental move of all jobs; either specify a predicate (e.g. JobChangeQueueParams.IDs, JobChangeQueueParams.Queues, ...) or call JobChangeQueueParams.UnsafeAll")
+	})
+
+	t.Run("UnsafeAll", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		changeRes, err := client.JobChangeQueue(ctx, NewJobChangeQueueParams().UnsafeAll(), "right_queue")
+		require.NoError(t, err)
+		require.Equal(t, 1, changeRes.NumJobs)
+	})
+
+	t.Run("InvalidTargetQueue", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobChangeQueue(ctx, NewJobChangeQueueParams().IDs(123), "")
+		require.EqualError(t, err, "queue name cannot be empty")
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		changeRes, err := client.JobChangeQueueTx(ctx, tx, NewJobChangeQueueParams().IDs(job.ID), "right_queue")
+		require.NoError(t, err)
+		require.Equal(t, 1, changeRes.NumJobs)
+
+		updatedJob, err := client.JobGetTx(ctx, tx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, "right_queue", updatedJob.Queue)
+
+		// Not visible outside the transaction.
+		updatedJob, err = client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, QueueDefault, updatedJob.Queue)
+	})
+}
+
+func Test_Client_JobClone(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("ClonesJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			EncodedArgs: []byte(`{"name":"original"}`),
+			FinalizedAt: ptrutil.Ptr(time.Now()),
+			Kind:        ptrutil.Ptr("noOp"),
+			MaxAttempts: ptrutil.Ptr(7),
+			Metadata:    []byte(`{"cancel_attempted_at":"2026-01-01T00:00:00Z","foo":"bar","output":{"a":1},"river:internal":true}`),
+			Priority:    ptrutil.Ptr(3),
+			Queue:       ptrutil.Ptr("custom_queue"),
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateCompleted),
+			Tags:        []string{"tag1"},
+			UniqueKey:   []byte("unique_key"),
+		})
+
+		insertRes, err := client.JobClone(ctx, job.ID)
+		require.NoError(t, err)
+
+		clonedJob := insertRes.Job
+		require.NotEqual(t, job.ID, clonedJob.ID)
+		require.JSONEq(t, `{"name":"original"}`, string(clonedJob.EncodedArgs))
+		require.Equal(t, "noOp", clonedJob.Kind)
+		require.Equal(t, 7, clonedJob.MaxAttempts)
+		require.JSONEq(t, `{"foo":"bar"}`, string(clonedJob.Metadata))
+		require.Equal(t, 3, clonedJob.Priority)
+		require.Equal(t, "custom_queue", clonedJob.Queue)
+		require.Equal(t, rivertype.JobStateAvailable, clonedJob.State)
+		require.Equal(t, []string{"tag1"}, clonedJob.Tags)
+		require.Nil(t, clonedJob.UniqueKey)
+
+		originalJob, err := client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, originalJob.State)
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		var insertRes *rivertype.JobInsertResult
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			insertRes, err = client.JobCloneTx(ctx, tx, job.ID)
+			return err
+		})
+		require.NoError(t, err)
+		require.NotEqual(t, job.ID, insertRes.Job.ID)
+		require.Equal(t, job.Kind, insertRes.Job.Kind)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.JobClone(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, insertRes)
+	})
+}
+
 func Test_Client_JobDelete(t *testing.T) {
 	t.Parallel()
 
@@ -3299,11 +3993,39 @@ func Test_Client_Insert(t *testing.T) {
 		require.Equal(t, []string{}, jobRow.Tags)
 	})
 
-	t.Run("ProducerFetchLimiterCalled", func(t *testing.T) {
+	t.Run("DeterministicWithTestJobIDFuncAndTime", func(t *testing.T) {
 		t.Parallel()
 
 		var (
-			dbPool = riversharedtest.DBPoolClone(ctx, t)
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			nextID = int64(1_000_000_000)
+			now    = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		)
+
+		config.Test.JobIDFunc = func() int64 { nextID++; return nextID }
+
+		client := newTestClient(t, dbPool, config)
+		client.baseService.Time.StubNow(now)
+
+		insertRes1, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1_000_000_001), insertRes1.Job.ID)
+		require.Equal(t, now, insertRes1.Job.CreatedAt)
+		require.Equal(t, now, insertRes1.Job.ScheduledAt)
+
+		insertRes2, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1_000_000_002), insertRes2.Job.ID)
+	})
+
+	t.Run("ProducerFetchLimiterCalled", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPoolClone(ctx, t)
 			driver = NewDriverWithoutListenNotify(dbPool)
 			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
 			config = newTestConfig(t, schema)
@@ -3372,6 +4094,19 @@ func Test_Client_Insert(t *testing.T) {
 		require.WithinDuration(t, time.Now(), insertRes.Job.ScheduledAt, 2*time.Second)
 	})
 
+	t.Run("WithInsertOptsScheduleIn", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{
+			ScheduleIn: time.Hour,
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, insertRes.Job.State)
+		require.WithinDuration(t, time.Now().Add(time.Hour), insertRes.Job.ScheduledAt, 2*time.Second)
+	})
+
 	t.Run("OnlyTriggersInsertNotificationForAvailableJobs", func(t *testing.T) {
 		t.Parallel()
 
@@ -4160,6 +4895,55 @@ func Test_Client_InsertMany(t *testing.T) {
 		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind())
 	})
 
+	t.Run("CopyFromAboveThreshold", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+		config.InsertManyCopyFromThreshold = 2
+
+		client := newTestClient(t, dbPool, config)
+
+		uniqueOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		results, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{Name: "Foo"}, InsertOpts: &InsertOpts{Metadata: []byte(`{"a": "b"}`), Queue: "foo", Priority: 2}},
+			{Args: noOpArgs{Name: "Unique"}, InsertOpts: uniqueOpts},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		require.False(t, results[0].UniqueSkippedAsDuplicate)
+		require.JSONEq(t, `{"name": "Foo"}`, string(results[0].Job.EncodedArgs))
+		require.JSONEq(t, `{"a": "b"}`, string(results[0].Job.Metadata))
+		require.Equal(t, 2, results[0].Job.Priority)
+		require.Equal(t, "foo", results[0].Job.Queue)
+		require.False(t, results[1].UniqueSkippedAsDuplicate)
+		require.JSONEq(t, `{"name": "Unique"}`, string(results[1].Job.EncodedArgs))
+
+		// Inserting the unique job again is skipped as a duplicate.
+		results2, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{Name: "Bar"}},
+			{Args: noOpArgs{Name: "Unique"}, InsertOpts: uniqueOpts},
+		})
+		require.NoError(t, err)
+		require.Len(t, results2, 2)
+		require.False(t, results2[0].UniqueSkippedAsDuplicate)
+		require.True(t, results2[1].UniqueSkippedAsDuplicate)
+		require.Equal(t, results[1].Job.ID, results2[1].Job.ID)
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(noOpArgs{}).Kind()},
+			Schema: client.config.Schema,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs, 3)
+	})
+
 	t.Run("TriggersImmediateWork", func(t *testing.T) {
 		t.Parallel()
 
@@ -4355,6 +5139,26 @@ func Test_Client_InsertMany(t *testing.T) {
 		require.Len(t, results, 1)
 	})
 
+	t.Run("ErrorsOnInvalidArgs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		client.config.SkipUnknownJobCheck = true
+
+		_, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: validatedArgs{Email: "a@example.com"}},
+			{Args: validatedArgs{}},
+		})
+		var validationErr *JobArgsValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, "validated", validationErr.Kind)
+
+		jobs, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Empty(t, jobs.Jobs)
+	})
+
 	t.Run("ErrorsOnInsertOptsWithV1UniqueOpts", func(t *testing.T) {
 		t.Parallel()
 
@@ -4692,6 +5496,200 @@ func Test_Client_InsertManyTx(t *testing.T) {
 	})
 }
 
+func Test_Client_InsertManyScheduled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *Client[pgx.Tx] {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		return newTestClient(t, dbPool, config)
+	}
+
+	t.Run("ActivatesBatchAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		scheduledAt := time.Now().UTC().Add(-1 * time.Minute).Truncate(time.Microsecond)
+
+		results, err := client.InsertManyScheduled(ctx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)}},
+		}, scheduledAt)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		for _, result := range results {
+			require.Equal(t, rivertype.JobStateAvailable, result.Job.State)
+			require.True(t, scheduledAt.Equal(result.Job.ScheduledAt))
+
+			job, err := client.JobGet(ctx, result.Job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, job.State)
+		}
+	})
+
+	t.Run("ActivatesBatchScheduled", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		scheduledAt := time.Now().UTC().Add(time.Hour).Truncate(time.Microsecond)
+
+		results, err := client.InsertManyScheduled(ctx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Pending: true}},
+		}, scheduledAt)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		for _, result := range results {
+			require.Equal(t, rivertype.JobStateScheduled, result.Job.State)
+			require.True(t, scheduledAt.Equal(result.Job.ScheduledAt))
+		}
+	})
+
+	t.Run("MultipleBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		params := make([]InsertManyParams, insertManyScheduledBatchSize+1)
+		for i := range params {
+			params[i] = InsertManyParams{Args: noOpArgs{}}
+		}
+
+		results, err := client.InsertManyScheduled(ctx, params, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, results, insertManyScheduledBatchSize+1)
+
+		for _, result := range results {
+			require.Equal(t, rivertype.JobStateScheduled, result.Job.State)
+		}
+	})
+
+	t.Run("UniqueSkippedAsDuplicateNotActivated", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Pending: true, UniqueOpts: UniqueOpts{ByArgs: true}})
+		require.NoError(t, err)
+
+		results, err := client.InsertManyScheduled(ctx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}},
+		}, time.Now())
+		require.NoError(t, err)
+		require.True(t, results[0].UniqueSkippedAsDuplicate)
+		require.Equal(t, insertRes.Job.ID, results[0].Job.ID)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, job.State)
+	})
+
+	t.Run("DeletesInsertedJobsOnError", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		var numInserts int
+		config.Middleware = []rivertype.Middleware{
+			&overridableJobMiddleware{
+				insertManyFunc: func(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(ctx context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+					numInserts++
+					if numInserts > 1 {
+						return nil, errors.New("second batch failed")
+					}
+					return doInner(ctx)
+				},
+			},
+		}
+
+		client := newTestClient(t, dbPool, config)
+
+		params := make([]InsertManyParams, insertManyScheduledBatchSize+1)
+		for i := range params {
+			params[i] = InsertManyParams{Args: noOpArgs{}}
+		}
+
+		_, err := client.InsertManyScheduled(ctx, params, time.Now())
+		require.EqualError(t, err, "second batch failed")
+
+		listRes, err := client.JobList(ctx, NewJobListParams().States(rivertype.JobStatePending))
+		require.NoError(t, err)
+		require.Empty(t, listRes.Jobs)
+	})
+}
+
+func Test_Client_InsertManyScheduledTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		tx pgx.Tx
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		tx, err := dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		return client, &testBundle{
+			tx: tx,
+		}
+	}
+
+	t.Run("ActivatesBatch", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		scheduledAt := time.Now().UTC().Add(time.Hour).Truncate(time.Microsecond)
+
+		results, err := client.InsertManyScheduledTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: noOpArgs{}},
+		}, scheduledAt)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		for _, result := range results {
+			require.Equal(t, rivertype.JobStateScheduled, result.Job.State)
+			require.True(t, scheduledAt.Equal(result.Job.ScheduledAt))
+
+			job, err := client.JobGetTx(ctx, bundle.tx, result.Job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateScheduled, job.State)
+		}
+	})
+}
+
 func Test_Client_JobGet(t *testing.T) {
 	t.Parallel()
 
@@ -4740,7 +5738,7 @@ func Test_Client_JobGet(t *testing.T) {
 	})
 }
 
-func Test_Client_JobList(t *testing.T) {
+func Test_Client_JobInspect(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
@@ -4767,68 +5765,243 @@ func Test_Client_JobList(t *testing.T) {
 		}
 	}
 
-	t.Run("FiltersByID", func(t *testing.T) {
+	t.Run("IncludesAttempts", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
-
-		listRes, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID))
-		require.NoError(t, err)
-		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Attempt: ptrutil.Ptr(3),
+			Errors: [][]byte{
+				[]byte(`{"at":"2026-01-01T00:00:00Z","attempt":1,"error":"error 1"}`),
+				[]byte(`{"at":"2026-01-01T00:01:00Z","attempt":2,"error":"error 2"}`),
+			},
+			Schema: bundle.schema,
+			State:  ptrutil.Ptr(rivertype.JobStateRunning),
+		})
 
-		listRes, err = client.JobList(ctx, NewJobListParams().IDs(job2.ID, job3.ID))
+		inspectRes, err := client.JobInspect(ctx, job.ID)
 		require.NoError(t, err)
-		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, job.ID, inspectRes.Job.ID)
+		require.Len(t, inspectRes.Attempts, 3)
+		require.Equal(t, 1, inspectRes.Attempts[0].Attempt)
+		require.Equal(t, "error 1", inspectRes.Attempts[0].Error.Error)
+		require.Equal(t, 2, inspectRes.Attempts[1].Attempt)
+		require.Equal(t, "error 2", inspectRes.Attempts[1].Error.Error)
+		require.Equal(t, 3, inspectRes.Attempts[2].Attempt)
+		require.Nil(t, inspectRes.Attempts[2].Error)
+		require.Empty(t, inspectRes.WorkflowJobs)
 	})
 
-	t.Run("FiltersByIDAndPriorityAndKind", func(t *testing.T) {
+	t.Run("IncludesWorkflowJobs", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("special_kind"), Priority: ptrutil.Ptr(1)})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("special_kind"), Priority: ptrutil.Ptr(2)})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("other_kind"), Priority: ptrutil.Ptr(1)})
+		var (
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"workflow_id":"wf1"}`), Schema: bundle.schema})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"workflow_id":"wf1"}`), Schema: bundle.schema})
+			job3 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"workflow_id":"wf1"}`), Schema: bundle.schema})
+			_    = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"workflow_id":"wf2"}`), Schema: bundle.schema})
+		)
 
-		listRes, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID, job2.ID, job3.ID).Priorities(1, 2).Kinds("special_kind"))
+		inspectRes, err := client.JobInspect(ctx, job2.ID)
 		require.NoError(t, err)
-		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, []int64{job1.ID, job3.ID}, sliceutil.Map(inspectRes.WorkflowJobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
-	t.Run("FiltersByPriority", func(t *testing.T) {
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
-
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(1)})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(2)})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(3)})
-
-		listRes, err := client.JobList(ctx, NewJobListParams().Priorities(1))
-		require.NoError(t, err)
-		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		client, _ := setup(t)
 
-		listRes, err = client.JobList(ctx, NewJobListParams().Priorities(2, 3))
-		require.NoError(t, err)
-		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		inspectRes, err := client.JobInspect(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, inspectRes)
 	})
+}
 
-	t.Run("FiltersByKind", func(t *testing.T) { //nolint:dupl
-		t.Parallel()
+func Test_Client_JobKindStorageUsage(t *testing.T) {
+	t.Parallel()
 
-		client, bundle := setup(t)
+	ctx := context.Background()
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1"), Schema: bundle.schema})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1"), Schema: bundle.schema})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_2"), Schema: bundle.schema})
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
 
-		listRes, err := client.JobList(ctx, NewJobListParams().Kinds("test_kind_1"))
-		require.NoError(t, err)
-		// jobs ordered by ScheduledAt ASC by default
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("OrdersByTotalBytes", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("small"), Schema: bundle.schema})
+		for range 2 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				EncodedArgs: []byte(`{"data":"` + strings.Repeat("x", 100) + `"}`),
+				Errors:      [][]byte{[]byte(`{"at":"2026-01-01T00:00:00Z","attempt":1,"error":"error"}`)},
+				Kind:        ptrutil.Ptr("large"),
+				Schema:      bundle.schema,
+			})
+		}
+
+		res, err := client.JobKindStorageUsage(ctx)
+		require.NoError(t, err)
+		require.Len(t, res.Kinds, 2)
+
+		large, small := res.Kinds[0], res.Kinds[1]
+		require.Equal(t, "large", large.Kind)
+		require.Equal(t, int64(2), large.Count)
+		require.Positive(t, large.ErrorsBytes)
+		require.Equal(t, large.ArgsBytes+large.ErrorsBytes+large.MetadataBytes, large.TotalBytes)
+
+		require.Equal(t, "small", small.Kind)
+		require.Equal(t, int64(1), small.Count)
+		require.Zero(t, small.ErrorsBytes)
+		require.Greater(t, large.ArgsBytes, small.ArgsBytes)
+		require.Greater(t, large.TotalBytes, small.TotalBytes)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		res, err := client.JobKindStorageUsage(ctx)
+		require.NoError(t, err)
+		require.Empty(t, res.Kinds)
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		_ = testfactory.Job(ctx, t, client.driver.UnwrapExecutor(tx), &testfactory.JobOpts{Schema: bundle.schema})
+
+		res, err := client.JobKindStorageUsageTx(ctx, tx)
+		require.NoError(t, err)
+		require.Len(t, res.Kinds, 1)
+
+		res, err = client.JobKindStorageUsage(ctx)
+		require.NoError(t, err)
+		require.Empty(t, res.Kinds)
+	})
+}
+
+func Test_Client_JobList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("FiltersByID", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().IDs(job2.ID, job3.ID))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByIDAndPriorityAndKind", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("special_kind"), Priority: ptrutil.Ptr(1)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("special_kind"), Priority: ptrutil.Ptr(2)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Kind: ptrutil.Ptr("other_kind"), Priority: ptrutil.Ptr(1)})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID, job2.ID, job3.ID).Priorities(1, 2).Kinds("special_kind"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByPriority", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(1)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(2)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, Priority: ptrutil.Ptr(3)})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().Priorities(1))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().Priorities(2, 3))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByKind", func(t *testing.T) { //nolint:dupl
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1"), Schema: bundle.schema})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1"), Schema: bundle.schema})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_2"), Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().Kinds("test_kind_1"))
+		require.NoError(t, err)
+		// jobs ordered by ScheduledAt ASC by default
 		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
 		listRes, err = client.JobList(ctx, NewJobListParams().Kinds("test_kind_2"))
@@ -4968,6 +6141,24 @@ func Test_Client_JobList(t *testing.T) {
 		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
+	t.Run("OrderByFinalizedAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateDiscarded), FinalizedAt: ptrutil.Ptr(now.Add(-10 * time.Second))})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByFinalizedAt, SortOrderAsc))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		_, err = client.JobList(ctx, NewJobListParams().States(rivertype.JobStateAvailable, rivertype.JobStateCompleted).OrderBy(JobListOrderByFinalizedAt, SortOrderAsc))
+		require.EqualError(t, err, "cannot order by finalized_at with non-finalized state filters [available]")
+	})
+
 	t.Run("WithNilParamsFiltersToAllStatesByDefault", func(t *testing.T) {
 		t.Parallel()
 
@@ -5075,6 +6266,184 @@ func Test_Client_JobList(t *testing.T) {
 		require.Equal(t, job6.ID, listRes.LastCursor.id)
 	})
 
+	t.Run("PaginatesWithAfter_JobListOrderByPriority", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(2), Schema: bundle.schema})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(1), Schema: bundle.schema})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(2), Schema: bundle.schema})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3), Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc).First(2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, JobListOrderByPriority, listRes.LastCursor.sortField)
+
+		// Continues past the job with tied priority.
+		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc).After(listRes.LastCursor))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID, job4.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		// Descending
+		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderDesc).After(JobListCursorFromJob(job3)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("PaginatesWithCursorCarryingSort", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, ScheduledAt: ptrutil.Ptr(now.Add(-3 * time.Second))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, ScheduledAt: ptrutil.Ptr(now.Add(-2 * time.Second))})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Second))})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByScheduledAt, SortOrderDesc).First(1))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		// Round trip the cursor as a UI would through a URL.
+		cursorText, err := listRes.LastCursor.MarshalText()
+		require.NoError(t, err)
+
+		var cursor JobListCursor
+		require.NoError(t, cursor.UnmarshalText(cursorText))
+
+		// A job arriving mid-browse that sorts before the cursor doesn't shift
+		// the next page.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, ScheduledAt: &now})
+
+		// The cursor's sort is used without it having to be specified again.
+		listRes, err = client.JobList(ctx, NewJobListParams().After(&cursor))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("CursorWithDifferentSortErrors", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc))
+		require.NoError(t, err)
+
+		_, err = client.JobList(ctx, NewJobListParams().After(listRes.LastCursor).OrderBy(JobListOrderByScheduledAt, SortOrderAsc))
+		require.EqualError(t, err, "cursor is for a list with a different sort; call OrderBy before After, or omit it to use the cursor's sort")
+	})
+
+	// Lists all jobs matching params a page of one job at a time, round
+	// tripping each page's cursor through text as a UI would, and returns
+	// their IDs.
+	listJobIDsByPage := func(t *testing.T, client *Client[pgx.Tx], params *JobListParams) []int64 {
+		t.Helper()
+
+		var (
+			cursor *JobListCursor
+			jobIDs []int64
+		)
+		for {
+			pageParams := params.First(1)
+			if cursor != nil {
+				pageParams = pageParams.After(cursor)
+			}
+
+			listRes, err := client.JobList(ctx, pageParams)
+			require.NoError(t, err)
+			if len(listRes.Jobs) < 1 {
+				return jobIDs
+			}
+
+			jobIDs = append(jobIDs, listRes.Jobs[0].ID)
+
+			cursorText, err := listRes.LastCursor.MarshalText()
+			require.NoError(t, err)
+
+			cursor = &JobListCursor{}
+			require.NoError(t, cursor.UnmarshalText(cursorText))
+		}
+	}
+
+	t.Run("MultiColumnSort", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-3 * time.Second)), Priority: ptrutil.Ptr(2), Schema: bundle.schema})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-2 * time.Second)), Priority: ptrutil.Ptr(1), Schema: bundle.schema})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Second)), Priority: ptrutil.Ptr(1), Schema: bundle.schema})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Second)), Priority: ptrutil.Ptr(2), Schema: bundle.schema})
+
+		params := NewJobListParams().
+			OrderBy(JobListOrderByPriority, SortOrderAsc).
+			ThenOrderBy(JobListOrderByCreatedAt, SortOrderDesc)
+
+		listRes, err := client.JobList(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID, job2.ID, job4.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		require.Equal(t, []int64{job3.ID, job2.ID, job4.ID, job1.ID}, listJobIDsByPage(t, client, params))
+
+		// Secondary sort in the same direction as the primary.
+		params = NewJobListParams().
+			OrderBy(JobListOrderByPriority, SortOrderDesc).
+			ThenOrderBy(JobListOrderByCreatedAt, SortOrderDesc)
+		require.Equal(t, []int64{job4.ID, job1.ID, job3.ID, job2.ID}, listJobIDsByPage(t, client, params))
+	})
+
+	t.Run("MultiColumnSortWithNulls", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Second)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Second)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		baseParams := NewJobListParams().States(rivertype.JobStateAvailable, rivertype.JobStateCompleted)
+
+		// Jobs without a finalized_at sort last ascending ...
+		params := baseParams.
+			OrderBy(JobListOrderByQueue, SortOrderAsc).
+			ThenOrderBy(JobListOrderByFinalizedAt, SortOrderAsc)
+		require.Equal(t, []int64{job2.ID, job4.ID, job1.ID, job3.ID}, listJobIDsByPage(t, client, params))
+
+		// ... and first descending.
+		params = baseParams.
+			OrderBy(JobListOrderByQueue, SortOrderAsc).
+			ThenOrderBy(JobListOrderByFinalizedAt, SortOrderDesc)
+		require.Equal(t, []int64{job1.ID, job3.ID, job4.ID, job2.ID}, listJobIDsByPage(t, client, params))
+
+		params = baseParams.
+			OrderBy(JobListOrderByState, SortOrderAsc).
+			ThenOrderBy(JobListOrderByFinalizedAt, SortOrderDesc)
+		require.Equal(t, []int64{job1.ID, job3.ID, job4.ID, job2.ID}, listJobIDsByPage(t, client, params))
+	})
+
+	t.Run("ThenOrderByInvalid", func(t *testing.T) {
+		t.Parallel()
+
+		params := NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc)
+
+		require.PanicsWithValue(t, "cannot use id as a secondary sort field", func() { params.ThenOrderBy(JobListOrderByID, SortOrderAsc) })
+		require.PanicsWithValue(t, "cannot use time as a secondary sort field", func() { params.ThenOrderBy(JobListOrderByTime, SortOrderAsc) })
+		require.PanicsWithValue(t, "list is already sorted by priority", func() { params.ThenOrderBy(JobListOrderByPriority, SortOrderDesc) })
+		require.PanicsWithValue(t, "list is already sorted by kind", func() {
+			params.ThenOrderBy(JobListOrderByKind, SortOrderAsc).ThenOrderBy(JobListOrderByKind, SortOrderDesc)
+		})
+		require.PanicsWithValue(t, "cannot add a secondary sort to a list sorted by id, which is unique", func() {
+			NewJobListParams().OrderBy(JobListOrderByID, SortOrderAsc).ThenOrderBy(JobListOrderByKind, SortOrderAsc)
+		})
+	})
+
 	t.Run("MetadataOnly", func(t *testing.T) {
 		t.Parallel()
 
@@ -5150,41 +6519,254 @@ func Test_Client_JobList(t *testing.T) {
 		require.Equal(t, []int64{job1.ID, job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
-	t.Run("ArbitraryWhereMultipleClauses", func(t *testing.T) {
+	t.Run("ArbitraryWhereMultipleClauses", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			job = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				MaxAttempts: ptrutil.Ptr(27),
+				Queue:       ptrutil.Ptr("custom_queue"),
+				Schema:      bundle.schema,
+				State:       ptrutil.Ptr(rivertype.JobStateDiscarded),
+			})
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+		)
+
+		listRes, err := client.JobList(ctx, NewJobListParams().
+			Where("kind = @kind", NamedArgs{"kind": job.Kind}).
+			Where("max_attempts = @max_attempts", NamedArgs{"max_attempts": job.MaxAttempts}).
+			Where("queue = @queue", NamedArgs{"queue": job.Queue}),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("CountTotal", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Schema: bundle.schema})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Schema: bundle.schema})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Schema: bundle.schema})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().Kinds("kind1").First(1).CountTotal(100))
+		require.NoError(t, err)
+		require.Len(t, listRes.Jobs, 1)
+		require.Equal(t, &JobListTotal{Count: 3, Exact: true}, listRes.Total)
+
+		// The total is unaffected by pagination.
+		listRes, err = client.JobList(ctx, NewJobListParams().Kinds("kind1").First(1).CountTotal(100).After(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Len(t, listRes.Jobs, 1)
+		require.Equal(t, &JobListTotal{Count: 3, Exact: true}, listRes.Total)
+	})
+
+	t.Run("CountTotalBeyondExactMax", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		for range 3 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+		}
+
+		listRes, err := client.JobList(ctx, NewJobListParams().CountTotal(2))
+		require.NoError(t, err)
+		require.Len(t, listRes.Jobs, 3)
+		require.False(t, listRes.Total.Exact)
+
+		// Estimates can't be relied on to be exact, but they're never less
+		// than what was counted.
+		require.GreaterOrEqual(t, listRes.Total.Count, 2)
+	})
+
+	t.Run("CountTotalNotRequested", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Nil(t, listRes.Total)
+	})
+
+	t.Run("WithCancelledContext", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel() // cancel immediately
+
+		listRes, err := client.JobList(ctx, NewJobListParams().States(rivertype.JobStateRunning))
+		require.ErrorIs(t, context.Canceled, err)
+		require.Nil(t, listRes)
+	})
+}
+
+func Test_Client_JobMove(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool       *pgxpool.Pool
+		exec         riverdriver.Executor
+		schema       string
+		targetSchema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool       = riversharedtest.DBPool(ctx, t)
+			driver       = riverpgxv5.New(dbPool)
+			schema       = riverdbtest.TestSchema(ctx, t, driver, nil)
+			targetSchema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config       = newTestConfig(t, schema)
+			client       = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			dbPool:       dbPool,
+			exec:         client.driver.GetExecutor(),
+			schema:       schema,
+			targetSchema: targetSchema,
+		}
+	}
+
+	t.Run("MovesJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				Errors:       [][]byte{[]byte(`{"at":"2025-01-01T00:00:00Z","attempt":1,"error":"oops","trace":""}`)},
+				Metadata:     []byte(`{"tenant":"acme"}`),
+				Schema:       bundle.schema,
+				State:        ptrutil.Ptr(rivertype.JobStateRetryable),
+				UniqueKey:    []byte("unique_key"),
+				UniqueStates: 0xFF,
+			})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+			job3 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+		)
+
+		moveRes, err := client.JobMove(ctx, []int64{job2.ID, job1.ID}, bundle.targetSchema)
+		require.NoError(t, err)
+		require.Len(t, moveRes.Jobs, 2)
+
+		movedJob1 := moveRes.Jobs[1]
+		require.Equal(t, job1.Attempt, movedJob1.Attempt)
+		require.Equal(t, job1.Errors, movedJob1.Errors)
+		require.Equal(t, job1.Kind, movedJob1.Kind)
+		require.JSONEq(t, `{"tenant":"acme"}`, string(movedJob1.Metadata))
+		require.Equal(t, rivertype.JobStateRetryable, movedJob1.State)
+		require.Equal(t, []byte("unique_key"), movedJob1.UniqueKey)
+		require.Equal(t, job1.UniqueStates, movedJob1.UniqueStates)
+		require.Equal(t, job2.Kind, moveRes.Jobs[0].Kind)
+
+		for _, movedJob := range moveRes.Jobs {
+			_, err = bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: movedJob.ID, Schema: bundle.targetSchema})
+			require.NoError(t, err)
+		}
+
+		_, err = client.JobGet(ctx, job1.ID)
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+		_, err = client.JobGet(ctx, job2.ID)
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		// job3 wasn't moved
+		_, err = client.JobGet(ctx, job3.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("NoIDs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		moveRes, err := client.JobMove(ctx, []int64{}, bundle.targetSchema)
+		require.NoError(t, err)
+		require.Empty(t, moveRes.Jobs)
+	})
+
+	t.Run("RunningJobNotMoved", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		)
+
+		_, err := client.JobMove(ctx, []int64{job1.ID, job2.ID}, bundle.targetSchema)
+		require.EqualError(t, err, fmt.Sprintf("jobs can't be moved because they don't exist, are running, or are locked: [%d]", job2.ID))
+
+		// Neither job was moved.
+		_, err = client.JobGet(ctx, job1.ID)
+		require.NoError(t, err)
+		_, err = client.JobGet(ctx, job2.ID)
+		require.NoError(t, err)
+
+		numJobs, err := bundle.exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+			Schema: bundle.targetSchema,
+			State:  rivertype.JobStateAvailable,
+		})
+		require.NoError(t, err)
+		require.Zero(t, numJobs)
+	})
+
+	t.Run("InvalidTargetSchema", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobMove(ctx, []int64{1}, "invalid-schema")
+		require.EqualError(t, err, "target schema name can only contain letters, numbers, and underscores, and must start with a letter or underscore")
+	})
+
+	t.Run("SameSchema", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_, err := client.JobMove(ctx, []int64{1}, bundle.schema)
+		require.EqualError(t, err, "target schema must be different from the client's schema")
+	})
+
+	t.Run("Tx", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		var (
-			job = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
-				MaxAttempts: ptrutil.Ptr(27),
-				Queue:       ptrutil.Ptr("custom_queue"),
-				Schema:      bundle.schema,
-				State:       ptrutil.Ptr(rivertype.JobStateDiscarded),
-			})
-			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
-		)
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
 
-		listRes, err := client.JobList(ctx, NewJobListParams().
-			Where("kind = @kind", NamedArgs{"kind": job.Kind}).
-			Where("max_attempts = @max_attempts", NamedArgs{"max_attempts": job.MaxAttempts}).
-			Where("queue = @queue", NamedArgs{"queue": job.Queue}),
-		)
+		tx, err := bundle.dbPool.Begin(ctx)
 		require.NoError(t, err)
-		require.Equal(t, []int64{job.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
-	})
+		t.Cleanup(func() { tx.Rollback(ctx) })
 
-	t.Run("WithCancelledContext", func(t *testing.T) {
-		t.Parallel()
+		moveRes, err := client.JobMoveTx(ctx, tx, []int64{job.ID}, bundle.targetSchema)
+		require.NoError(t, err)
+		require.Len(t, moveRes.Jobs, 1)
 
-		client, _ := setup(t)
+		// Not visible outside the transaction until it's committed.
+		_, err = client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
 
-		ctx, cancel := context.WithCancel(ctx)
-		cancel() // cancel immediately
+		require.NoError(t, tx.Commit(ctx))
 
-		listRes, err := client.JobList(ctx, NewJobListParams().States(rivertype.JobStateRunning))
-		require.ErrorIs(t, context.Canceled, err)
-		require.Nil(t, listRes)
+		_, err = client.JobGet(ctx, job.ID)
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
 	})
 }
 
@@ -5263,6 +6845,149 @@ func Test_Client_JobRetry(t *testing.T) {
 	})
 }
 
+func Test_Client_JobRetryWithNewArgs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+
+		Name string `json:"name"`
+	}
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("ReplacesArgsAndMakesAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			EncodedArgs: []byte(`{"name":"wrong"}`),
+			FinalizedAt: ptrutil.Ptr(time.Now()),
+			Kind:        ptrutil.Ptr((JobArgs{}).Kind()),
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateDiscarded),
+		})
+
+		updatedJob, err := client.JobRetryWithNewArgs(ctx, job.ID, JobArgs{Name: "right"})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"right"}`, string(updatedJob.EncodedArgs))
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+		require.Nil(t, updatedJob.FinalizedAt)
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			EncodedArgs: []byte(`{"name":"wrong"}`),
+			Kind:        ptrutil.Ptr((JobArgs{}).Kind()),
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateRetryable),
+		})
+
+		var updatedJob *rivertype.JobRow
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			updatedJob, err = client.JobRetryWithNewArgsTx(ctx, tx, job.ID, JobArgs{Name: "right"})
+			return err
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"right"}`, string(updatedJob.EncodedArgs))
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+	})
+
+	t.Run("ErrorsOnKindMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("other_kind"), Schema: bundle.schema})
+
+		_, err := client.JobRetryWithNewArgs(ctx, job.ID, JobArgs{Name: "right"})
+		require.EqualError(t, err, `args kind "JobArgs" doesn't match job kind "other_kind"`)
+	})
+
+	t.Run("ErrorsOnRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Kind:   ptrutil.Ptr((JobArgs{}).Kind()),
+			Schema: bundle.schema,
+			State:  ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		_, err := client.JobRetryWithNewArgs(ctx, job.ID, JobArgs{Name: "right"})
+		require.ErrorIs(t, err, errJobRetryWithNewArgsRunning)
+	})
+
+	t.Run("LocksJobAgainstConcurrentFetch", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Kind:   ptrutil.Ptr((JobArgs{}).Kind()),
+			Schema: bundle.schema,
+			State:  ptrutil.Ptr(rivertype.JobStateRetryable),
+		})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, tx.Rollback(ctx)) })
+
+		_, err = client.JobRetryWithNewArgsTx(ctx, tx, job.ID, JobArgs{Name: "right"})
+		require.NoError(t, err)
+
+		// Producers lock jobs with SKIP LOCKED when fetching, so while the
+		// transaction is open the job can't be fetched out from under it.
+		var numLockable int
+		require.NoError(t, bundle.dbPool.QueryRow(ctx,
+			"SELECT count(*) FROM (SELECT id FROM "+bundle.schema+".river_job WHERE id = $1 FOR UPDATE SKIP LOCKED) AS lockable",
+			job.ID,
+		).Scan(&numLockable))
+		require.Zero(t, numLockable)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		job, err := client.JobRetryWithNewArgs(ctx, 0, JobArgs{})
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, job)
+	})
+}
+
 func Test_Client_JobUpdate(t *testing.T) {
 	t.Parallel()
 
@@ -6348,6 +8073,35 @@ func Test_Client_QueueGet(t *testing.T) {
 		require.Nil(t, queueRes.PausedAt)
 	})
 
+	t.Run("IncludesLiveCounts", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			exec  = client.driver.GetExecutor()
+			queue = testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{Schema: bundle.schema})
+		)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: &queue.Name, Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client1"}, Queue: &queue.Name, Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client1"}, Queue: &queue.Name, Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client2"}, Queue: &queue.Name, Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		queueRes, err := client.QueueGet(ctx, queue.Name)
+		require.NoError(t, err)
+		require.Equal(t, 1, queueRes.CountAvailable)
+		require.Equal(t, 2, queueRes.CountClientsWithRunningJobs)
+		require.Equal(t, 3, queueRes.CountRunning)
+
+		listRes, err := client.QueueList(ctx, NewQueueListParams())
+		require.NoError(t, err)
+		require.Len(t, listRes.Queues, 1)
+		require.Equal(t, 1, listRes.Queues[0].CountAvailable)
+		require.Equal(t, 2, listRes.Queues[0].CountClientsWithRunningJobs)
+		require.Equal(t, 3, listRes.Queues[0].CountRunning)
+	})
+
 	t.Run("ReturnsErrNotFoundIfQueueDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
@@ -6648,6 +8402,28 @@ func Test_Client_QueueUpdate(t *testing.T) {
 		}
 	})
 
+	t.Run("SendsQueueMetadataChangedEvent", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		subscribeChan, cancel := client.Subscribe(EventKindQueueMetadataChanged)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		_, err := client.QueueUpdate(ctx, QueueDefault, &QueueUpdateParams{
+			Metadata: []byte(`{"foo":"bar"}`),
+		})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindQueueMetadataChanged, event.Kind)
+		require.Equal(t, QueueDefault, event.Queue.Name)
+		require.JSONEq(t, `{"foo":"bar"}`, string(event.Queue.Metadata))
+	})
+
 	t.Run("ProducerControlEventSent", func(t *testing.T) {
 		t.Parallel()
 
@@ -6671,7 +8447,7 @@ func Test_Client_QueueUpdate(t *testing.T) {
 
 		controlEvent := client.producersByQueueName[QueueDefault].testSignals.QueueControlEventTriggered.WaitOrTimeout()
 		require.NotNil(t, controlEvent)
-		require.Equal(t, controlActionMetadataChanged, controlEvent.Action)
+		require.Equal(t, rivernotify.ControlActionMetadataChanged, controlEvent.Action)
 	})
 
 	t.Run("ProducerControlEventNotSent", func(t *testing.T) {
@@ -6714,42 +8490,199 @@ func Test_Client_QueueUpdateTx(t *testing.T) {
 			client = newTestClient(t, dbPool, config)
 		)
 
-		tx, err := dbPool.Begin(ctx)
+		tx, err := dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		return client, &testBundle{
+			executorTx: client.driver.UnwrapExecutor(tx),
+			schema:     schema,
+			tx:         tx,
+		}
+	}
+
+	t.Run("UpdatesQueueMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		queue := testfactory.Queue(ctx, t, bundle.executorTx, &testfactory.QueueOpts{Schema: bundle.schema})
+		require.Equal(t, []byte(`{}`), queue.Metadata)
+
+		queue, err := client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Metadata: []byte(`{"foo":"bar"}`),
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar"}`, string(queue.Metadata))
+
+		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Metadata: nil,
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar"}`, string(queue.Metadata))
+
+		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Metadata: []byte(`{}`),
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{}`, string(queue.Metadata))
+	})
+}
+
+func Test_Client_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		job    *rivertype.JobRow
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		config.Queues = nil
+		config.ReadOnly = true
+		config.Workers = nil
+
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			exec:   driver.GetExecutor(),
+			job:    testfactory.Job(ctx, t, driver.GetExecutor(), &testfactory.JobOpts{Schema: schema}),
+			schema: schema,
+		}
+	}
+
+	t.Run("MutatingAPIsReturnError", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.InsertMany(ctx, []InsertManyParams{{Args: noOpArgs{}}})
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.InsertManyFast(ctx, []InsertManyParams{{Args: noOpArgs{}}})
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobCancel(ctx, bundle.job.ID)
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobChangeQueue(ctx, NewJobChangeQueueParams().IDs(bundle.job.ID), "other_queue")
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobClone(ctx, bundle.job.ID)
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobDelete(ctx, bundle.job.ID)
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobDeleteMany(ctx, NewJobDeleteManyParams().IDs(bundle.job.ID))
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobRetry(ctx, bundle.job.ID)
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		_, err = client.JobUpdate(ctx, bundle.job.ID, &JobUpdateParams{})
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		require.ErrorIs(t, client.KindPause(ctx, (noOpArgs{}).Kind(), nil), ErrClientReadOnly)
+		require.ErrorIs(t, client.KindResume(ctx, (noOpArgs{}).Kind(), nil), ErrClientReadOnly)
+
+		require.ErrorIs(t, client.QueuePause(ctx, QueueDefault, nil), ErrClientReadOnly)
+		require.ErrorIs(t, client.QueueResume(ctx, QueueDefault, nil), ErrClientReadOnly)
+
+		_, err = client.QueueUpdate(ctx, QueueDefault, &QueueUpdateParams{})
+		require.ErrorIs(t, err, ErrClientReadOnly)
+
+		require.ErrorIs(t, client.Notify().RequestResign(ctx), ErrClientReadOnly)
+
+		// The job was left untouched.
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: bundle.job.ID, Schema: bundle.schema})
+		require.NoError(t, err)
+		require.Equal(t, bundle.job.State, job.State)
+
+		numJobs, err := bundle.exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{Schema: bundle.schema, State: rivertype.JobStateAvailable})
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("ReadAPIsWork", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job, err := client.JobGet(ctx, bundle.job.ID)
 		require.NoError(t, err)
-		t.Cleanup(func() { tx.Rollback(ctx) })
+		require.Equal(t, bundle.job.ID, job.ID)
 
-		return client, &testBundle{
-			executorTx: client.driver.UnwrapExecutor(tx),
-			schema:     schema,
-			tx:         tx,
-		}
-	}
+		listRes, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Len(t, listRes.Jobs, 1)
 
-	t.Run("UpdatesQueueMetadata", func(t *testing.T) {
+		_, err = client.QueueList(ctx, NewQueueListParams())
+		require.NoError(t, err)
+	})
+
+	t.Run("StartsAndReceivesQueueEvents", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		queue := testfactory.Queue(ctx, t, bundle.executorTx, &testfactory.QueueOpts{Schema: bundle.schema})
-		require.Equal(t, []byte(`{}`), queue.Metadata)
+		subscribeChan, cancel := client.Subscribe(EventKindQueueMetadataChanged, EventKindQueuePaused, EventKindQueueResumed)
+		t.Cleanup(cancel)
 
-		queue, err := client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
-			Metadata: []byte(`{"foo":"bar"}`),
-		})
-		require.NoError(t, err)
-		require.JSONEq(t, `{"foo":"bar"}`, string(queue.Metadata))
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
 
-		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
-			Metadata: nil,
-		})
-		require.NoError(t, err)
-		require.JSONEq(t, `{"foo":"bar"}`, string(queue.Metadata))
+		// A working client pauses and resumes a queue, which a read-only
+		// client sees through control notifications.
+		workingClient := newTestClient(t, riversharedtest.DBPool(ctx, t), newTestConfig(t, bundle.schema))
 
-		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
-			Metadata: []byte(`{}`),
-		})
+		testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr(QueueDefault), Schema: bundle.schema})
+
+		require.NoError(t, workingClient.QueuePause(ctx, QueueDefault, nil))
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, &Event{Kind: EventKindQueuePaused, Queue: &rivertype.Queue{Name: QueueDefault}}, event)
+
+		require.NoError(t, workingClient.QueueResume(ctx, QueueDefault, nil))
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, &Event{Kind: EventKindQueueResumed, Queue: &rivertype.Queue{Name: QueueDefault}}, event)
+
+		_, err := workingClient.QueueUpdate(ctx, QueueDefault, &QueueUpdateParams{Metadata: []byte(`{"foo":"bar"}`)})
 		require.NoError(t, err)
-		require.JSONEq(t, `{}`, string(queue.Metadata))
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindQueueMetadataChanged, event.Kind)
+		require.Equal(t, QueueDefault, event.Queue.Name)
+		require.JSONEq(t, `{"foo":"bar"}`, string(event.Queue.Metadata))
+
+		require.NoError(t, client.Stop(ctx))
+
+		// Subscription channels are closed on stop.
+		_, ok := <-subscribeChan
+		require.False(t, ok)
+	})
+
+	t.Run("MissingDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewClient(riverpgxv5.New(nil), &Config{ReadOnly: true})
+		require.ErrorIs(t, err, errMissingDatabasePoolReadOnly)
 	})
 }
 
@@ -7235,126 +9168,355 @@ func Test_Client_SubscribeConfig(t *testing.T) {
 			require.Equal(t, rivertype.JobStateRetryable, eventFailed1.Job.State)
 		}
 
-		{
-			eventFailed2 := eventsByName["failed2"]
-			require.Equal(t, EventKindJobFailed, eventFailed2.Kind)
-			require.Equal(t, jobFailed2.ID, eventFailed2.Job.ID)
-			require.Equal(t, rivertype.JobStateRetryable, eventFailed2.Job.State)
-		}
+		{
+			eventFailed2 := eventsByName["failed2"]
+			require.Equal(t, EventKindJobFailed, eventFailed2.Kind)
+			require.Equal(t, jobFailed2.ID, eventFailed2.Job.ID)
+			require.Equal(t, rivertype.JobStateRetryable, eventFailed2.Job.State)
+		}
+	})
+
+	t.Run("EventsDropWithNoListeners", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			return nil
+		}))
+
+		// A first channel that we'll use to make sure all the expected jobs are
+		// finished.
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		// Artificially lowered subscribe channel size so we don't have to try
+		// and process thousands of jobs.
+		const (
+			subscribeChanSize = 100
+			numJobsToInsert   = subscribeChanSize + 1
+		)
+
+		// Another channel with no listeners. Despite no listeners, it shouldn't
+		// block or gum up the client's progress in any way.
+		subscribeChan2, cancel := client.SubscribeConfig(&SubscribeConfig{
+			ChanSize: subscribeChanSize,
+			Kinds:    []EventKind{EventKindJobCompleted},
+		})
+		t.Cleanup(cancel)
+
+		var (
+			insertParams = make([]*riverdriver.JobInsertFastParams, numJobsToInsert)
+			kind         = (&JobArgs{}).Kind()
+		)
+		for i := range numJobsToInsert {
+			insertParams[i] = &riverdriver.JobInsertFastParams{
+				Args:        &JobArgs{},
+				EncodedArgs: []byte(`{}`),
+				Kind:        kind,
+				MaxAttempts: rivercommon.MaxAttemptsDefault,
+				Priority:    rivercommon.PriorityDefault,
+				Queue:       rivercommon.QueueDefault,
+				State:       rivertype.JobStateAvailable,
+			}
+		}
+
+		_, err := client.driver.GetExecutor().JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+			Jobs:   insertParams,
+			Schema: schema,
+		})
+		require.NoError(t, err)
+
+		// Need to start waiting on events before running the client or the
+		// channel could overflow before we start listening.
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			_ = riversharedtest.WaitOrTimeoutN(t, subscribeChan, numJobsToInsert)
+		})
+
+		startClient(ctx, t, client)
+
+		wg.Wait()
+
+		// Filled to maximum.
+		require.Len(t, subscribeChan2, subscribeChanSize)
+	})
+
+	t.Run("PanicOnChanSizeLessThanZero", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		client := newTestClient(t, dbPool, config)
+
+		require.PanicsWithValue(t, "SubscribeConfig.ChanSize must be greater or equal to 1", func() {
+			_, _ = client.SubscribeConfig(&SubscribeConfig{
+				ChanSize: -1,
+			})
+		})
+	})
+
+	t.Run("PanicOnUnknownKind", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		client := newTestClient(t, dbPool, config)
+
+		require.PanicsWithError(t, "unknown event kind: does_not_exist", func() {
+			_, _ = client.SubscribeConfig(&SubscribeConfig{
+				Kinds: []EventKind{EventKind("does_not_exist")},
+			})
+		})
+	})
+}
+
+func Test_Client_TailEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+			client = newTestClient(t, dbPool, config)
+		)
+
+		return client, &testBundle{
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	type tailedEvent struct {
+		cursor *JobListCursor
+		event  *Event
+	}
+
+	// Starts a tail in a goroutine, sending events back on the returned
+	// channel. The tail is stopped on test cleanup.
+	startTail := func(t *testing.T, client *Client[pgx.Tx], filter *TailEventsFilter) <-chan tailedEvent {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(ctx)
+
+		var (
+			errCh   = make(chan error, 1)
+			eventCh = make(chan tailedEvent, 100)
+		)
+
+		go func() {
+			errCh <- client.TailEvents(ctx, filter, func(ctx context.Context, event *Event, cursor *JobListCursor) error {
+				eventCh <- tailedEvent{cursor: cursor, event: event}
+				return nil
+			})
+		}()
+
+		t.Cleanup(func() {
+			cancel()
+			require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+		})
+
+		return eventCh
+	}
+
+	allKinds := []EventKind{EventKindJobCancelled, EventKindJobCompleted, EventKindJobFailed}
+
+	t.Run("DeliversFinalizedJobsInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		var (
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-3 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			job3 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			_    = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		)
+
+		tailed := riversharedtest.WaitOrTimeoutN(t, startTail(t, client, &TailEventsFilter{Kinds: allKinds}), 3)
+		require.Equal(t, []int64{job1.ID, job3.ID, job2.ID}, sliceutil.Map(tailed, func(e tailedEvent) int64 { return e.event.Job.ID }))
+		require.Equal(t, []EventKind{EventKindJobCompleted, EventKindJobCancelled, EventKindJobFailed}, sliceutil.Map(tailed, func(e tailedEvent) EventKind { return e.event.Kind }))
+	})
+
+	t.Run("FiltersByEventKind", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			_    = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		)
+
+		tailed := riversharedtest.WaitOrTimeout(t, startTail(t, client, &TailEventsFilter{Kinds: []EventKind{EventKindJobCompleted}}))
+		require.Equal(t, job2.ID, tailed.event.Job.ID)
+	})
+
+	t.Run("FiltersByQueueAndJobKind", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			_    = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("other_queue"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			_    = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue1"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			job3 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		)
+
+		tailed := riversharedtest.WaitOrTimeout(t, startTail(t, client, &TailEventsFilter{JobKinds: []string{"kind1"}, Kinds: allKinds, Queues: []string{"queue1"}}))
+		require.Equal(t, job3.ID, tailed.event.Job.ID)
+	})
+
+	t.Run("FuncErrorStopsTail", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		funcErr := errors.New("func error")
+
+		err := client.TailEvents(ctx, &TailEventsFilter{Kinds: allKinds}, func(ctx context.Context, event *Event, cursor *JobListCursor) error {
+			return funcErr
+		})
+		require.ErrorIs(t, err, funcErr)
 	})
 
-	t.Run("EventsDropWithNoListeners", func(t *testing.T) {
+	t.Run("NilFilterTailsAllKinds", func(t *testing.T) {
 		t.Parallel()
 
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
 		var (
-			dbPool = riversharedtest.DBPool(ctx, t)
-			driver = riverpgxv5.New(dbPool)
-			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
-			config = newTestConfig(t, schema)
-			client = newTestClient(t, dbPool, config)
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-3 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			job3 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
 		)
 
-		type JobArgs struct {
-			testutil.JobArgsReflectKind[JobArgs]
-		}
+		tailed := riversharedtest.WaitOrTimeoutN(t, startTail(t, client, nil), 3)
+		require.Equal(t, []int64{job1.ID, job2.ID, job3.ID}, sliceutil.Map(tailed, func(e tailedEvent) int64 { return e.event.Job.ID }))
+	})
 
-		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			return nil
-		}))
+	t.Run("PicksUpLateCommittedJobsInOverlapWindow", func(t *testing.T) {
+		t.Parallel()
 
-		// A first channel that we'll use to make sure all the expected jobs are
-		// finished.
-		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
-		t.Cleanup(cancel)
+		client, bundle := setup(t)
 
-		// Artificially lowered subscribe channel size so we don't have to try
-		// and process thousands of jobs.
-		const (
-			subscribeChanSize = 100
-			numJobsToInsert   = subscribeChanSize + 1
-		)
+		now := time.Now().UTC()
 
-		// Another channel with no listeners. Despite no listeners, it shouldn't
-		// block or gum up the client's progress in any way.
-		subscribeChan2, cancel := client.SubscribeConfig(&SubscribeConfig{
-			ChanSize: subscribeChanSize,
-			Kinds:    []EventKind{EventKindJobCompleted},
-		})
-		t.Cleanup(cancel)
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
 
-		var (
-			insertParams = make([]*riverdriver.JobInsertFastParams, numJobsToInsert)
-			kind         = (&JobArgs{}).Kind()
-		)
-		for i := range numJobsToInsert {
-			insertParams[i] = &riverdriver.JobInsertFastParams{
-				Args:        &JobArgs{},
-				EncodedArgs: []byte(`{}`),
-				Kind:        kind,
-				MaxAttempts: rivercommon.MaxAttemptsDefault,
-				Priority:    rivercommon.PriorityDefault,
-				Queue:       rivercommon.QueueDefault,
-				State:       rivertype.JobStateAvailable,
-			}
+		eventCh := startTail(t, client, &TailEventsFilter{PollInterval: 50 * time.Millisecond})
+
+		tailed := riversharedtest.WaitOrTimeout(t, eventCh)
+		require.Equal(t, job1.ID, tailed.event.Job.ID)
+
+		// Simulates a job finalized before job1 whose transaction only
+		// committed after the tail had already moved past it.
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-5 * time.Second)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		tailed = riversharedtest.WaitOrTimeout(t, eventCh)
+		require.Equal(t, job2.ID, tailed.event.Job.ID)
+
+		// Jobs in the overlap window aren't delivered again on later polls.
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(time.Second)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		tailed = riversharedtest.WaitOrTimeout(t, eventCh)
+		require.Equal(t, job3.ID, tailed.event.Job.ID)
+
+		select {
+		case tailed := <-eventCh:
+			require.FailNow(t, "Unexpected duplicate event", "job ID: %d", tailed.event.Job.ID)
+		case <-time.After(200 * time.Millisecond):
 		}
+	})
 
-		_, err := client.driver.GetExecutor().JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
-			Jobs:   insertParams,
-			Schema: schema,
-		})
-		require.NoError(t, err)
+	t.Run("PicksUpNewlyFinalizedJobs", func(t *testing.T) {
+		t.Parallel()
 
-		// Need to start waiting on events before running the client or the
-		// channel could overflow before we start listening.
-		var wg sync.WaitGroup
-		wg.Go(func() {
-			_ = riversharedtest.WaitOrTimeoutN(t, subscribeChan, numJobsToInsert)
-		})
+		client, bundle := setup(t)
 
-		startClient(ctx, t, client)
+		eventCh := startTail(t, client, &TailEventsFilter{Kinds: allKinds, PollInterval: 50 * time.Millisecond})
 
-		wg.Wait()
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
 
-		// Filled to maximum.
-		require.Len(t, subscribeChan2, subscribeChanSize)
+		tailed := riversharedtest.WaitOrTimeout(t, eventCh)
+		require.Equal(t, job.ID, tailed.event.Job.ID)
 	})
 
-	t.Run("PanicOnChanSizeLessThanZero", func(t *testing.T) {
+	t.Run("ResumesFromCursor", func(t *testing.T) {
 		t.Parallel()
 
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
 		var (
-			dbPool = riversharedtest.DBPool(ctx, t)
-			driver = riverpgxv5.New(dbPool)
-			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
-			config = newTestConfig(t, schema)
+			job1 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			job2 = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
 		)
 
-		client := newTestClient(t, dbPool, config)
+		var cursor *JobListCursor
+		{
+			tailed := riversharedtest.WaitOrTimeout(t, startTail(t, client, &TailEventsFilter{Kinds: allKinds}))
+			require.Equal(t, job1.ID, tailed.event.Job.ID)
 
-		require.PanicsWithValue(t, "SubscribeConfig.ChanSize must be greater or equal to 1", func() {
-			_, _ = client.SubscribeConfig(&SubscribeConfig{
-				ChanSize: -1,
-			})
-		})
+			// Round trip the cursor through text as it'd be persisted.
+			cursorText, err := tailed.cursor.MarshalText()
+			require.NoError(t, err)
+
+			cursor = &JobListCursor{}
+			require.NoError(t, cursor.UnmarshalText(cursorText))
+		}
+
+		tailed := riversharedtest.WaitOrTimeout(t, startTail(t, client, &TailEventsFilter{After: cursor, Kinds: allKinds}))
+		require.Equal(t, job2.ID, tailed.event.Job.ID)
 	})
 
-	t.Run("PanicOnUnknownKind", func(t *testing.T) {
+	t.Run("ValidatesKinds", func(t *testing.T) {
 		t.Parallel()
 
-		var (
-			dbPool = riversharedtest.DBPool(ctx, t)
-			driver = riverpgxv5.New(dbPool)
-			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
-			config = newTestConfig(t, schema)
-		)
+		client, _ := setup(t)
 
-		client := newTestClient(t, dbPool, config)
+		noOpFunc := func(ctx context.Context, event *Event, cursor *JobListCursor) error { return nil }
 
-		require.PanicsWithError(t, "unknown event kind: does_not_exist", func() {
-			_, _ = client.SubscribeConfig(&SubscribeConfig{
-				Kinds: []EventKind{EventKind("does_not_exist")},
-			})
-		})
+		err := client.TailEvents(ctx, &TailEventsFilter{Kinds: []EventKind{EventKindJobSnoozed}}, noOpFunc)
+		require.EqualError(t, err, `event kind "job_snoozed" is not supported for tailing`)
 	})
 }
 
@@ -7819,58 +9981,243 @@ func Test_Client_JobCompletion(t *testing.T) {
 		// updated job inside the txn:
 		require.WithinDuration(t, *updatedJob.FinalizedAt, *event.Job.FinalizedAt, time.Microsecond)
 
-		reloadedJob, err := client.JobGet(ctx, insertRes.Job.ID)
-		require.NoError(t, err)
+		reloadedJob, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+
+		require.Equal(t, rivertype.JobStateCompleted, reloadedJob.State)
+		require.Equal(t, updatedJob.FinalizedAt, reloadedJob.FinalizedAt)
+	})
+
+	t.Run("UnknownJobKindCatchAllWorksJob", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.SkipUnknownJobCheck = true
+		config.UnknownJobKindPolicy = UnknownJobKindPolicyCatchAll
+
+		workedChan := make(chan *rivertype.JobRow, 1)
+		config.UnknownJobKindWorkFunc = func(ctx context.Context, job *rivertype.JobRow) error {
+			workedChan <- job
+			return nil
+		}
+
+		client, bundle := setup(t, config)
+
+		insertRes, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
+		require.NoError(t, err)
+
+		workedJob := riversharedtest.WaitOrTimeout(t, workedChan)
+		require.Equal(t, insertRes.Job.ID, workedJob.ID)
+		require.Equal(t, (&unregisteredJobArgs{}).Kind(), workedJob.Kind)
+
+		event := riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, event.Job.State)
+
+		require.Equal(t, int64(1), client.Liveness(ctx).UnknownJobKindsFetched)
+	})
+
+	t.Run("UnknownJobKindQuarantineDiscardsJob", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.SkipUnknownJobCheck = true
+		config.UnknownJobKindPolicy = UnknownJobKindPolicyQuarantine
+
+		client, bundle := setup(t, config)
+
+		insertRes, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateDiscarded, event.Job.State)
+		require.Len(t, event.Job.Errors, 1)
+	})
+}
+
+type unregisteredJobArgs struct{}
+
+func (unregisteredJobArgs) Kind() string { return "RandomWorkerNameThatIsNeverRegistered" }
+
+func Test_Client_UnknownJobKindErrorsTheJob(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	t.Cleanup(cancel)
+
+	doneCh := make(chan struct{})
+	close(doneCh) // don't need to block any jobs from completing
+
+	config := newTestConfig(t, "")
+	client := runNewTestClient(ctx, t, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindJobFailed)
+	t.Cleanup(cancel)
+
+	insertParams, err := insertParamsFromConfigArgsAndOptions(&client.baseService.Archetype, config, unregisteredJobArgs{}, nil)
+	require.NoError(t, err)
+	insertedResults, err := client.driver.GetExecutor().JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+		Jobs:   []*riverdriver.JobInsertFastParams{(*riverdriver.JobInsertFastParams)(insertParams)},
+		Schema: client.config.Schema,
+	})
+	require.NoError(t, err)
+
+	insertedResult := insertedResults[0]
+
+	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, insertedResult.Job.ID, event.Job.ID)
+	require.Equal(t, "RandomWorkerNameThatIsNeverRegistered", insertedResult.Job.Kind)
+	require.Len(t, event.Job.Errors, 1)
+	require.Equal(t, (&UnknownJobKindError{Kind: "RandomWorkerNameThatIsNeverRegistered"}).Error(), event.Job.Errors[0].Error)
+	require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
+	// Ensure that ScheduledAt was updated with next run time:
+	require.True(t, event.Job.ScheduledAt.After(insertedResult.Job.ScheduledAt))
+	// It's the 1st attempt that failed. Attempt won't be incremented again until
+	// the job gets fetched a 2nd time.
+	require.Equal(t, 1, event.Job.Attempt)
+
+	require.NoError(t, client.Stop(ctx))
+}
+
+func Test_Client_WorkerQueues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config *Config
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		AddWorker(config.Workers, &withQueuesWorker{})
+		config.Queues = map[string]QueueConfig{
+			QueueDefault:  {MaxWorkers: 50},
+			"with_queues": {MaxWorkers: 50},
+		}
+
+		return &testBundle{
+			config: config,
+			dbPool: dbPool,
+			exec:   driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("InsertToBoundQueue", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		insertRes, err := client.Insert(ctx, withQueuesArgs{}, &InsertOpts{Queue: "with_queues"})
+		require.NoError(t, err)
+		require.Equal(t, "with_queues", insertRes.Job.Queue)
+	})
+
+	t.Run("InsertToUnboundQueueError", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		_, err := client.Insert(ctx, withQueuesArgs{}, nil)
+		require.EqualError(t, err, `job of kind "with_queues" can't be inserted to queue "default" because its worker is bound to queues [with_queues]`)
+
+		_, err = client.InsertMany(ctx, []InsertManyParams{{Args: withQueuesArgs{}, InsertOpts: &InsertOpts{Queue: "other"}}})
+		require.EqualError(t, err, `job of kind "with_queues" can't be inserted to queue "other" because its worker is bound to queues [with_queues]`)
+	})
+
+	t.Run("StartErrorsWithNoBoundQueueConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		bundle.config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 50}}
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		err := client.Start(ctx)
+		require.EqualError(t, err, `worker for kind "with_queues" is bound to queues [with_queues], but none of them are configured on the client`)
+	})
+
+	t.Run("FetchNotEnforcedByDefault", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobFailed)
+		t.Cleanup(cancel)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr((withQueuesArgs{}).Kind()), Schema: bundle.schema})
 
-		require.Equal(t, rivertype.JobStateCompleted, reloadedJob.State)
-		require.Equal(t, updatedJob.FinalizedAt, reloadedJob.FinalizedAt)
+		startClient(ctx, t, client)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, event.Job.State)
 	})
-}
 
-type unregisteredJobArgs struct{}
+	t.Run("FetchEnforcedErrorsJob", func(t *testing.T) {
+		t.Parallel()
 
-func (unregisteredJobArgs) Kind() string { return "RandomWorkerNameThatIsNeverRegistered" }
+		bundle := setup(t)
+		bundle.config.WorkerQueuesEnforcedOnFetch = true
+		client := newTestClient(t, bundle.dbPool, bundle.config)
 
-func Test_Client_UnknownJobKindErrorsTheJob(t *testing.T) {
-	t.Parallel()
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobFailed)
+		t.Cleanup(cancel)
 
-	ctx := context.Background()
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr((withQueuesArgs{}).Kind()), Schema: bundle.schema})
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	t.Cleanup(cancel)
+		startClient(ctx, t, client)
 
-	doneCh := make(chan struct{})
-	close(doneCh) // don't need to block any jobs from completing
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
+		require.Len(t, event.Job.Errors, 1)
+		require.Equal(t, `job of kind "with_queues" is in queue "default", but its worker is bound to queues [with_queues]`, event.Job.Errors[0].Error)
+	})
+}
 
-	config := newTestConfig(t, "")
-	client := runNewTestClient(ctx, t, config)
+func Test_Client_WorkerLifecycle(t *testing.T) {
+	t.Parallel()
 
-	subscribeChan, cancel := client.Subscribe(EventKindJobFailed)
-	t.Cleanup(cancel)
+	ctx := context.Background()
 
-	insertParams, err := insertParamsFromConfigArgsAndOptions(&client.baseService.Archetype, config, unregisteredJobArgs{}, nil)
-	require.NoError(t, err)
-	insertedResults, err := client.driver.GetExecutor().JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
-		Jobs:   []*riverdriver.JobInsertFastParams{(*riverdriver.JobInsertFastParams)(insertParams)},
-		Schema: client.config.Schema,
-	})
-	require.NoError(t, err)
+	var (
+		dbPool = riversharedtest.DBPool(ctx, t)
+		driver = riverpgxv5.New(dbPool)
+		schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		config = newTestConfig(t, schema)
+	)
 
-	insertedResult := insertedResults[0]
+	var calls []string
+	AddWorkerArgs(config.Workers, lifecycleArgs{kind: "lifecycle"}, Worker[lifecycleArgs](&lifecycleWorker{calls: &calls, kind: "lifecycle"}))
 
-	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
-	require.Equal(t, insertedResult.Job.ID, event.Job.ID)
-	require.Equal(t, "RandomWorkerNameThatIsNeverRegistered", insertedResult.Job.Kind)
-	require.Len(t, event.Job.Errors, 1)
-	require.Equal(t, (&UnknownJobKindError{Kind: "RandomWorkerNameThatIsNeverRegistered"}).Error(), event.Job.Errors[0].Error)
-	require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
-	// Ensure that ScheduledAt was updated with next run time:
-	require.True(t, event.Job.ScheduledAt.After(insertedResult.Job.ScheduledAt))
-	// It's the 1st attempt that failed. Attempt won't be incremented again until
-	// the job gets fetched a 2nd time.
-	require.Equal(t, 1, event.Job.Attempt)
+	client := newTestClient(t, dbPool, config)
 
-	require.NoError(t, client.Stop(ctx))
+	// Setup and teardown are invoked once per start and stop, including when
+	// the client is restarted.
+	for range 2 {
+		startClient(ctx, t, client)
+		require.NoError(t, client.Stop(ctx))
+	}
+
+	require.Equal(t, []string{"setup_lifecycle", "teardown_lifecycle", "setup_lifecycle", "teardown_lifecycle"}, calls)
 }
 
 func Test_Client_Start_Error(t *testing.T) {
@@ -7911,6 +10258,29 @@ func Test_Client_Start_Error(t *testing.T) {
 		require.EqualError(t, err, "at least one Worker must be added to the Workers bundle")
 	})
 
+	t.Run("WorkerSetupError", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		var calls []string
+		AddWorkerArgs(config.Workers, lifecycleArgs{kind: "lifecycle"}, Worker[lifecycleArgs](&lifecycleWorker{
+			calls:    &calls,
+			kind:     "lifecycle",
+			setupErr: errors.New("setup error"),
+		}))
+
+		client := newTestClient(t, dbPool, config)
+		err := client.Start(ctx)
+		require.EqualError(t, err, `error setting up worker for kind "lifecycle": setup error`)
+		require.Equal(t, []string{"setup_lifecycle"}, calls)
+	})
+
 	t.Run("DatabaseError", func(t *testing.T) {
 		t.Parallel()
 
@@ -8096,6 +10466,10 @@ func Test_NewClient_Defaults(t *testing.T) {
 	require.Equal(t, FetchPollIntervalDefault, client.config.FetchPollInterval)
 	require.Equal(t, JobTimeoutDefault, client.config.JobTimeout)
 	require.Nil(t, client.config.Hooks)
+	require.Equal(t, leadership.ElectIntervalDefault, client.config.LeaderElectionInterval)
+	require.Zero(t, client.config.LeaderElectionPriority)
+	require.Equal(t, leadership.ElectIntervalDefault, client.config.LeaderReelectionInterval)
+	require.Equal(t, leadership.ElectIntervalDefault+leadership.ElectIntervalTTLPaddingDefault, client.config.LeaderTTL)
 	require.NotZero(t, client.baseService.Logger)
 	require.Equal(t, MaxAttemptsDefault, client.config.MaxAttempts)
 	require.Equal(t, maintenance.ReindexerTimeoutDefault, client.config.ReindexerTimeout)
@@ -8147,6 +10521,10 @@ func Test_NewClient_Overrides(t *testing.T) {
 		Hooks:                       []rivertype.Hook{&noOpHook{}},
 		JobInsertMiddleware:         []rivertype.JobInsertMiddleware{&noOpInsertMiddleware{}},
 		JobTimeout:                  125 * time.Millisecond,
+		LeaderElectionInterval:      30 * time.Second,
+		LeaderElectionPriority:      10,
+		LeaderReelectionInterval:    20 * time.Second,
+		LeaderTTL:                   time.Minute,
 		Logger:                      logger,
 		MaxAttempts:                 5,
 		Queues:                      map[string]QueueConfig{QueueDefault: {MaxWorkers: 1}},
@@ -8186,6 +10564,10 @@ func Test_NewClient_Overrides(t *testing.T) {
 	require.Len(t, client.config.JobInsertMiddleware, 1)
 	require.Equal(t, 125*time.Millisecond, client.config.JobTimeout)
 	require.Equal(t, []rivertype.Hook{&noOpHook{}}, client.config.Hooks)
+	require.Equal(t, 30*time.Second, client.config.LeaderElectionInterval)
+	require.Equal(t, 10, client.config.LeaderElectionPriority)
+	require.Equal(t, 20*time.Second, client.config.LeaderReelectionInterval)
+	require.Equal(t, time.Minute, client.config.LeaderTTL)
 	require.Equal(t, logger, client.baseService.Logger)
 	require.Equal(t, 5, client.config.MaxAttempts)
 	require.Equal(t, 125*time.Millisecond, client.config.ReindexerTimeout)
@@ -8260,11 +10642,115 @@ func Test_NewClient_Validations(t *testing.T) {
 		wantErr        error
 		validateResult func(*testing.T, *Client[pgx.Tx])
 	}{
+		{
+			name: "ArgsCodec name cannot be empty",
+			configFunc: func(config *Config) {
+				config.ArgsCodec = &gobArgsCodec{name: ""}
+			},
+			wantErr: errors.New("ArgsCodec name cannot be empty"),
+		},
+		{
+			name: "ArgsCodec name cannot be json",
+			configFunc: func(config *Config) {
+				config.ArgsCodec = &gobArgsCodec{name: "json"}
+			},
+			wantErr: errors.New(`ArgsCodec name "json" is reserved`),
+		},
+		{
+			name: "ArgsCodec names must be unique",
+			configFunc: func(config *Config) {
+				config.ArgsCodec = &gobArgsCodec{name: "gob"}
+				config.ArgsCodecsDecodeOnly = []ArgsCodec{&gobArgsCodec{name: "gob"}}
+			},
+			wantErr: errors.New(`ArgsCodec name "gob" is used by more than one codec`),
+		},
+		{
+			name:       "ArgsCompression MinBytes cannot be less than zero",
+			configFunc: func(config *Config) { config.ArgsCompression = &ArgsCompressionConfig{MinBytes: -1} },
+			wantErr:    errors.New("ArgsCompression.MinBytes cannot be less than zero"),
+		},
+		{
+			name: "ArgsCompressor name cannot be gzip",
+			configFunc: func(config *Config) {
+				config.ArgsCompression = &ArgsCompressionConfig{Compressor: &testArgsCompressor{name: "gzip"}}
+			},
+			wantErr: errors.New(`ArgsCompressor name "gzip" is reserved`),
+		},
 		{
 			name:       "CompletedJobRetentionPeriod cannot be less than zero",
 			configFunc: func(config *Config) { config.CompletedJobRetentionPeriod = -1 * time.Second },
 			wantErr:    errors.New("CompletedJobRetentionPeriod cannot be less than zero"),
 		},
+		{
+			name: "ControlHandlers cannot contain an empty name",
+			configFunc: func(config *Config) {
+				config.ControlHandlers = map[string]ControlHandlerFunc{"": func(ctx context.Context, msg *ControlMessage) error { return nil }}
+			},
+			wantErr: errors.New("ControlHandlers cannot contain an empty name"),
+		},
+		{
+			name: "ControlHandlers cannot contain a nil handler",
+			configFunc: func(config *Config) {
+				config.ControlHandlers = map[string]ControlHandlerFunc{"flush_cache": nil}
+			},
+			wantErr: errors.New(`ControlHandlers handler for "flush_cache" cannot be nil`),
+		},
+		{
+			name: "DatabaseCircuitBreaker.FailureThreshold cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.DatabaseCircuitBreaker = &DatabaseCircuitBreaker{FailureThreshold: -1}
+			},
+			wantErr: errors.New("DatabaseCircuitBreaker.FailureThreshold cannot be less than zero"),
+		},
+		{
+			name: "DatabaseCircuitBreaker.ProbeInterval cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.DatabaseCircuitBreaker = &DatabaseCircuitBreaker{ProbeInterval: -1}
+			},
+			wantErr: errors.New("DatabaseCircuitBreaker.ProbeInterval cannot be less than zero"),
+		},
+		{
+			name: "DriverOperationTimeouts cannot contain an unknown operation",
+			configFunc: func(config *Config) {
+				config.DriverOperationTimeouts = map[DriverOperation]time.Duration{"delete": time.Second}
+			},
+			wantErr: errors.New(`DriverOperationTimeouts contains unknown operation "delete"`),
+		},
+		{
+			name: "DriverOperationTimeouts cannot contain a negative timeout",
+			configFunc: func(config *Config) {
+				config.DriverOperationTimeouts = map[DriverOperation]time.Duration{DriverOperationFetch: -1}
+			},
+			wantErr: errors.New(`DriverOperationTimeouts timeout for "fetch" cannot be less than zero`),
+		},
+		{
+			name: "DriverRetryPolicy.MaxAttempts cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.DriverRetryPolicy = &DriverRetryPolicy{MaxAttempts: -1}
+			},
+			wantErr: errors.New("DriverRetryPolicy.MaxAttempts cannot be less than zero"),
+		},
+		{
+			name: "DriverRetryPolicy.Operations cannot contain unknown operations",
+			configFunc: func(config *Config) {
+				config.DriverRetryPolicy = &DriverRetryPolicy{Operations: []DriverOperation{"delete"}}
+			},
+			wantErr: errors.New(`DriverRetryPolicy.Operations contains unknown operation "delete"`),
+		},
+		{
+			name: "DriverStatementTimeouts cannot contain an unknown operation",
+			configFunc: func(config *Config) {
+				config.DriverStatementTimeouts = map[DriverOperation]DriverStatementTimeouts{"delete": {StatementTimeout: time.Second}}
+			},
+			wantErr: errors.New(`DriverStatementTimeouts contains unknown operation "delete"`),
+		},
+		{
+			name: "DriverStatementTimeouts cannot contain a timeout less than 1ms",
+			configFunc: func(config *Config) {
+				config.DriverStatementTimeouts = map[DriverOperation]DriverStatementTimeouts{DriverOperationList: {LockTimeout: time.Microsecond}}
+			},
+			wantErr: errors.New(`DriverStatementTimeouts LockTimeout for "list" must be zero or at least 1ms`),
+		},
 		{
 			name:       "FetchCooldown cannot be less than FetchCooldownMin",
 			configFunc: func(config *Config) { config.FetchCooldown = time.Millisecond - 1 },
@@ -8317,6 +10803,54 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: errors.New("ID cannot be longer than 100 characters"),
 		},
+		{
+			name: "InsertAsync is validated",
+			configFunc: func(config *Config) {
+				config.InsertAsync = &InsertAsyncConfig{BatchSize: -1}
+			},
+			wantErr: errors.New("InsertAsync.BatchSize cannot be less than zero"),
+		},
+		{
+			name: "InsertManyCopyFromThreshold can be -1 (never)",
+			configFunc: func(config *Config) {
+				config.InsertManyCopyFromThreshold = -1
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, -1, client.config.InsertManyCopyFromThreshold)
+			},
+		},
+		{
+			name: "InsertManyCopyFromThreshold cannot be less than -1",
+			configFunc: func(config *Config) {
+				config.InsertManyCopyFromThreshold = -2
+			},
+			wantErr: errors.New("InsertManyCopyFromThreshold cannot be negative, except for -1 (never)"),
+		},
+		{
+			name: "InsertManyCopyFromThreshold of zero applies InsertManyCopyFromThresholdDefault",
+			configFunc: func(config *Config) {
+				config.InsertManyCopyFromThreshold = 0
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, InsertManyCopyFromThresholdDefault, client.config.InsertManyCopyFromThreshold)
+			},
+		},
+		{
+			name: "InsertOptsByKind can set queue",
+			configFunc: func(config *Config) {
+				config.InsertOptsByKind = map[string]InsertOpts{"noOp": {Queue: "other"}}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, map[string]InsertOpts{"noOp": {Queue: "other"}}, client.config.InsertOptsByKind)
+			},
+		},
+		{
+			name: "InsertOptsByKind cannot set ScheduledAt",
+			configFunc: func(config *Config) {
+				config.InsertOptsByKind = map[string]InsertOpts{"noOp": {ScheduledAt: time.Now()}}
+			},
+			wantErr: errors.New(`InsertOptsByKind for "noOp" may only set MaxAttempts, Priority, Queue, RequiredLabels, Tags, and UniqueOpts`),
+		},
 		{
 			name: "JobTimeout can be -1 (infinite)",
 			configFunc: func(config *Config) {
@@ -8349,6 +10883,76 @@ func Test_NewClient_Validations(t *testing.T) {
 				config.JobTimeout = 7 * 24 * time.Hour
 			},
 		},
+		{
+			name: "Labels cannot contain an empty key",
+			configFunc: func(config *Config) {
+				config.Labels = map[string]string{"": "true"}
+			},
+			wantErr: errors.New("Labels cannot contain an empty key"),
+		},
+		{
+			name: "Labels are copied",
+			configFunc: func(config *Config) {
+				config.Labels = map[string]string{"gpu": "true"}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, map[string]string{"gpu": "true"}, client.config.Labels)
+			},
+		},
+		{
+			name: "LeaderElectionInterval cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.LeaderElectionInterval = -1
+			},
+			wantErr: errors.New("LeaderElectionInterval cannot be less than zero"),
+		},
+		{
+			name: "LeaderElectionInterval can be sub-second",
+			configFunc: func(config *Config) {
+				config.LeaderElectionInterval = 200 * time.Millisecond
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, 200*time.Millisecond, client.config.LeaderElectionInterval)
+			},
+		},
+		{
+			name: "LeaderElectionPriority cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.LeaderElectionPriority = -1
+			},
+			wantErr: errors.New("LeaderElectionPriority cannot be less than zero"),
+		},
+		{
+			name: "LeaderReelectionInterval cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.LeaderReelectionInterval = -1
+			},
+			wantErr: errors.New("LeaderReelectionInterval cannot be less than zero"),
+		},
+		{
+			name: "LeaderTTL cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.LeaderTTL = -1
+			},
+			wantErr: errors.New("LeaderTTL cannot be less than zero"),
+		},
+		{
+			name: "LeaderTTL must be longer than LeaderReelectionInterval",
+			configFunc: func(config *Config) {
+				config.LeaderReelectionInterval = time.Minute
+				config.LeaderTTL = time.Minute
+			},
+			wantErr: errors.New("LeaderTTL must be longer than LeaderReelectionInterval (1m0s)"),
+		},
+		{
+			name: "LeaderTTL defaults relative to LeaderReelectionInterval",
+			configFunc: func(config *Config) {
+				config.LeaderReelectionInterval = time.Minute
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, time.Minute+leadership.ElectIntervalTTLPaddingDefault, client.config.LeaderTTL)
+			},
+		},
 		{
 			name: "MaxAttempts cannot be less than zero",
 			configFunc: func(config *Config) {
@@ -8415,6 +11019,88 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: errors.New("only one of the pair JobInsertMiddleware/WorkerMiddleware or Middleware may be provided (Middleware is recommended, and may contain both job insert and worker middleware)"),
 		},
+		{
+			name: "RateAnomalyMonitor is validated",
+			configFunc: func(config *Config) {
+				config.RateAnomalyMonitor = &RateAnomalyMonitorConfig{Threshold: 0.5}
+			},
+			wantErr: errors.New("RateAnomalyMonitor.Threshold must be greater than 1"),
+		},
+		{
+			name: "RateAnomalyMonitor is enabled",
+			configFunc: func(config *Config) {
+				config.RateAnomalyMonitor = &RateAnomalyMonitorConfig{}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.NotNil(t, client.rateAnomalyMonitor)
+				require.Equal(t, client.rateAnomalyMonitor, client.subscriptionManager.rateAnomalyMonitor)
+			},
+		},
+		{
+			name: "CompleterBackpressure is validated",
+			configFunc: func(config *Config) {
+				config.CompleterBackpressure = &CompleterBackpressureConfig{Threshold: -1}
+			},
+			wantErr: errors.New("CompleterBackpressure.Threshold cannot be less than zero"),
+		},
+		{
+			name: "CompleterBackpressure is enabled",
+			configFunc: func(config *Config) {
+				config.CompleterBackpressure = &CompleterBackpressureConfig{}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.NotNil(t, client.completerBackpressure)
+				require.NotNil(t, client.producersByQueueName[QueueDefault].config.CompleterBackpressureEngaged)
+			},
+		},
+		{
+			name: "ResourceGuard is validated",
+			configFunc: func(config *Config) {
+				config.ResourceGuard = &ResourceGuardConfig{}
+			},
+			wantErr: errors.New("ResourceGuard must set at least one of MaxGoroutines or MaxMemoryBytes"),
+		},
+		{
+			name: "ResourceGuard is enabled",
+			configFunc: func(config *Config) {
+				config.ResourceGuard = &ResourceGuardConfig{MaxGoroutines: 10_000}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.NotNil(t, client.resourceGuard)
+			},
+		},
+		{
+			name:       "ReadOnly cannot be set with Queues",
+			configFunc: func(config *Config) { config.ReadOnly = true },
+			wantErr:    errors.New("Queues cannot be set on a ReadOnly client"),
+		},
+		{
+			name: "ReadOnly cannot be set with PeriodicJobs",
+			configFunc: func(config *Config) {
+				config.PeriodicJobs = []*PeriodicJob{
+					NewPeriodicJob(PeriodicInterval(time.Minute), func() (JobArgs, *InsertOpts) { return noOpArgs{}, nil }, nil),
+				}
+				config.Queues = nil
+				config.ReadOnly = true
+			},
+			wantErr: errors.New("PeriodicJobs cannot be set on a ReadOnly client"),
+		},
+		{
+			name: "ReadOnly cannot be set with PublishJobKinds",
+			configFunc: func(config *Config) {
+				config.PublishJobKinds = true
+				config.Queues = nil
+				config.ReadOnly = true
+			},
+			wantErr: errors.New("PublishJobKinds cannot be set on a ReadOnly client"),
+		},
+		{
+			name: "ReadOnly can be set without Queues",
+			configFunc: func(config *Config) {
+				config.Queues = nil
+				config.ReadOnly = true
+			},
+		},
 		{
 			name: "ReindexerTimeout can be -1 (infinite)",
 			configFunc: func(config *Config) {
@@ -8503,6 +11189,30 @@ func Test_NewClient_Validations(t *testing.T) {
 			name:       "Queues can be empty",
 			configFunc: func(config *Config) { config.Queues = make(map[string]QueueConfig) },
 		},
+		{
+			name: "Queues FairnessWeights require FairnessKey",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {FairnessWeights: map[string]int{"acme": 2}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("FairnessWeights requires FairnessKey"),
+		},
+		{
+			name: "Queues FairnessWeights must be at least 1",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {FairnessKey: "tenant_id", FairnessWeights: map[string]int{"acme": 0}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid fairness weight for \"acme\" in queue \"default\": 0"),
+		},
+		{
+			name: "Queues FairnessKey and FairnessWeights are passed to producer",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {FairnessKey: "tenant_id", FairnessWeights: map[string]int{"acme": 2}, MaxWorkers: 1}}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, "tenant_id", client.producersByQueueName[QueueDefault].config.FairnessKey)
+				require.Equal(t, map[string]int{"acme": 2}, client.producersByQueueName[QueueDefault].config.FairnessWeights)
+			},
+		},
 		{
 			name: "Queues FetchCooldown can be overridden",
 			configFunc: func(config *Config) {
@@ -8543,6 +11253,87 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("invalid number of workers for queue \"default\": %d", QueueNumWorkersMax+1),
 		},
+		{
+			name: "Queues MaxWorkersByKind must be at least 1",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, MaxWorkersByKind: map[string]int{"send_email": 0}}}
+			},
+			wantErr: errors.New("invalid number of workers for kind \"send_email\" in queue \"default\": 0"),
+		},
+		{
+			name: "Queues MaxWorkersByKind is passed to producer",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 100, MaxWorkersByKind: map[string]int{"send_email": 5}}}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, map[string]int{"send_email": 5}, client.producersByQueueName[QueueDefault].config.MaxWorkersByKind)
+			},
+		},
+		{
+			name: "Queues MinWorkers can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, MinWorkers: -1}}
+			},
+			wantErr: errors.New("invalid minimum number of workers for queue \"default\": -1 (must be between 0 and MaxWorkers)"),
+		},
+		{
+			name: "Queues MinWorkers can't be more than MaxWorkers",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, MinWorkers: 2}}
+			},
+			wantErr: errors.New("invalid minimum number of workers for queue \"default\": 2 (must be between 0 and MaxWorkers)"),
+		},
+		{
+			name: "WorkStealing registers queues with pool",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 10, MinWorkers: 2}}
+				config.WorkStealing = true
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, client.workerSlots, client.producersByQueueName[QueueDefault].config.WorkerSlots)
+				require.Equal(t, &workerSlotPoolQueue{maxWorkers: 10, minWorkers: 2}, client.workerSlots.queues[QueueDefault])
+			},
+		},
+		{
+			name: "Queues GlobalMaxWorkers can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {GlobalMaxWorkers: -1, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("GlobalMaxWorkers cannot be less than zero"),
+		},
+		{
+			name: "Queues GlobalMaxWorkersByKind must be at least 1",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {GlobalMaxWorkersByKind: map[string]int{"send_email": 0}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid number of global workers for kind \"send_email\" in queue \"default\": 0"),
+		},
+		{
+			name: "Queues GlobalMaxWorkers and GlobalMaxWorkersByKind are passed to producer",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {GlobalMaxWorkers: 10, GlobalMaxWorkersByKind: map[string]int{"send_email": 5}, MaxWorkers: 100}}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, 10, client.producersByQueueName[QueueDefault].config.GlobalMaxWorkers)
+				require.Equal(t, map[string]int{"send_email": 5}, client.producersByQueueName[QueueDefault].config.GlobalMaxWorkersByKind)
+			},
+		},
+		{
+			name: "Queues VisibilityTimeout can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, VisibilityTimeout: -1}}
+			},
+			wantErr: errors.New("VisibilityTimeout cannot be less than zero"),
+		},
+		{
+			name: "Queues VisibilityTimeout is passed to producer",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, VisibilityTimeout: 30 * time.Second}}
+			},
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, 30*time.Second, client.producersByQueueName[QueueDefault].config.VisibilityTimeout)
+			},
+		},
 		{
 			name: "Queues queue names can't be empty",
 			configFunc: func(config *Config) {
@@ -8576,6 +11367,38 @@ func Test_NewClient_Validations(t *testing.T) {
 				config.Queues = map[string]QueueConfig{"some-awesome-3rd-queue-namezzz": {MaxWorkers: 1}}
 			},
 		},
+		{
+			name: "SynchronousMode requires Workers",
+			configFunc: func(config *Config) {
+				config.Queues = nil
+				config.SynchronousMode = true
+				config.Workers = nil
+			},
+			wantErr: errors.New("Workers must be set if SynchronousMode is enabled"),
+		},
+		{
+			name:       "UnknownJobKindPolicy must be valid",
+			configFunc: func(config *Config) { config.UnknownJobKindPolicy = "invalid" },
+			wantErr:    errors.New(`UnknownJobKindPolicy "invalid" is not valid`),
+		},
+		{
+			name:       "UnknownJobKindPolicy catch all requires UnknownJobKindWorkFunc",
+			configFunc: func(config *Config) { config.UnknownJobKindPolicy = UnknownJobKindPolicyCatchAll },
+			wantErr:    errors.New("UnknownJobKindWorkFunc must be set if UnknownJobKindPolicy is UnknownJobKindPolicyCatchAll"),
+		},
+		{
+			name: "UnknownJobKindWorkFunc requires UnknownJobKindPolicy catch all",
+			configFunc: func(config *Config) {
+				config.UnknownJobKindWorkFunc = func(ctx context.Context, job *rivertype.JobRow) error { return nil }
+			},
+			wantErr: errors.New("UnknownJobKindWorkFunc may only be set if UnknownJobKindPolicy is UnknownJobKindPolicyCatchAll"),
+		},
+		{
+			name: "UnknownJobKindPolicy defaults to retry",
+			validateResult: func(t *testing.T, client *Client[pgx.Tx]) { //nolint:thelper
+				require.Equal(t, UnknownJobKindPolicyRetry, client.config.UnknownJobKindPolicy)
+			},
+		},
 		{
 			name: "Workers can be nil",
 			configFunc: func(config *Config) {
@@ -8596,6 +11419,15 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: errors.New("Workers must be set if Queues is set"),
 		},
+		{
+			name: "Workers middleware must be for a registered kind",
+			configFunc: func(config *Config) {
+				AddWorkerMiddleware(config.Workers, []string{"not_registered"}, WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+					return doInner(ctx)
+				}))
+			},
+			wantErr: errors.New(`worker middleware added for kind "not_registered", which has no registered worker`),
+		},
 		{
 			name: "Job kinds must be valid",
 			configFunc: func(config *Config) {
@@ -8771,6 +11603,99 @@ func TestClient_JobTimeout(t *testing.T) {
 	}
 }
 
+type timeoutFromArgsTestArgs struct {
+	TimeoutValue time.Duration `json:"timeout_value"`
+}
+
+func (timeoutFromArgsTestArgs) Kind() string { return "timeoutFromArgsTest" }
+
+func (a timeoutFromArgsTestArgs) Timeout() time.Duration { return a.TimeoutValue }
+
+type timeoutFromArgsTestWorker struct {
+	WorkerDefaults[timeoutFromArgsTestArgs]
+
+	doneCh        chan testWorkerDeadline
+	workerTimeout time.Duration
+}
+
+func (w *timeoutFromArgsTestWorker) Timeout(job *Job[timeoutFromArgsTestArgs]) time.Duration {
+	return w.workerTimeout
+}
+
+func (w *timeoutFromArgsTestWorker) Work(ctx context.Context, job *Job[timeoutFromArgsTestArgs]) error {
+	deadline, ok := ctx.Deadline()
+	w.doneCh <- testWorkerDeadline{deadline: deadline, ok: ok}
+	return nil
+}
+
+func TestClient_JobTimeoutFromArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		argsTimeout      time.Duration
+		clientJobTimeout time.Duration
+		workerTimeout    time.Duration
+		wantDuration     time.Duration
+	}{
+		{
+			name:             "ArgsTimeoutTakesPrecedenceOverClient",
+			argsTimeout:      2 * time.Hour,
+			clientJobTimeout: time.Hour,
+			wantDuration:     2 * time.Hour,
+		},
+		{
+			name:             "WorkerTimeoutTakesPrecedenceOverArgs",
+			argsTimeout:      2 * time.Hour,
+			clientJobTimeout: time.Hour,
+			workerTimeout:    3 * time.Hour,
+			wantDuration:     3 * time.Hour,
+		},
+		{
+			name:             "ClientJobTimeoutIsUsedIfArgsTimeoutIsZero",
+			argsTimeout:      0,
+			clientJobTimeout: time.Hour,
+			wantDuration:     time.Hour,
+		},
+		{
+			name:             "NoJobTimeoutIfArgsIsNegativeOne",
+			argsTimeout:      -1,
+			clientJobTimeout: time.Hour,
+			wantDuration:     0, // infinite
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			testWorker := &timeoutFromArgsTestWorker{doneCh: make(chan testWorkerDeadline), workerTimeout: tt.workerTimeout}
+
+			workers := NewWorkers()
+			AddWorker(workers, testWorker)
+
+			config := newTestConfig(t, "")
+			config.JobTimeout = tt.clientJobTimeout
+			config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1}}
+			config.Workers = workers
+
+			client := runNewTestClient(ctx, t, config)
+			_, err := client.Insert(ctx, timeoutFromArgsTestArgs{TimeoutValue: tt.argsTimeout}, nil)
+			require.NoError(t, err)
+
+			result := riversharedtest.WaitOrTimeout(t, testWorker.doneCh)
+			if tt.wantDuration == 0 {
+				require.False(t, result.ok, "expected no deadline")
+				return
+			}
+			require.True(t, result.ok, "expected a deadline, but none was set")
+			require.WithinDuration(t, time.Now().Add(tt.wantDuration), result.deadline, 2*time.Second)
+		})
+	}
+}
+
 type JobArgsStaticKind struct {
 	kind string
 }
@@ -8819,6 +11744,83 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		require.Equal(t, overrideConfig.MaxAttempts, insertParams.MaxAttempts)
 	})
 
+	t.Run("InsertOptsByKind", func(t *testing.T) {
+		t.Parallel()
+
+		kindConfig := &Config{
+			InsertOptsByKind: map[string]InsertOpts{
+				(noOpArgs{}).Kind(): {
+					MaxAttempts: 7,
+					Priority:    3,
+					Queue:       "kind_queue",
+					Tags:        []string{"kind_tag"},
+					UniqueOpts:  UniqueOpts{ByQueue: true},
+				},
+				(&customInsertOptsJobArgs{}).Kind(): {
+					Queue: "kind_queue",
+				},
+			},
+			MaxAttempts: 34,
+		}
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, kindConfig, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 7, insertParams.MaxAttempts)
+		require.Equal(t, 3, insertParams.Priority)
+		require.Equal(t, "kind_queue", insertParams.Queue)
+		require.Equal(t, []string{"kind_tag"}, insertParams.Tags)
+		require.NotEmpty(t, insertParams.UniqueKey)
+
+		// Options from the call site take precedence.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, kindConfig, noOpArgs{}, &InsertOpts{
+			MaxAttempts: 8,
+			Priority:    2,
+			Queue:       "insert_queue",
+			Tags:        []string{"insert_tag"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 8, insertParams.MaxAttempts)
+		require.Equal(t, 2, insertParams.Priority)
+		require.Equal(t, "insert_queue", insertParams.Queue)
+		require.Equal(t, []string{"insert_tag"}, insertParams.Tags)
+
+		// Options from job args take precedence.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, kindConfig, &customInsertOptsJobArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "other", insertParams.Queue)
+
+		// Other kinds are unaffected.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, kindConfig, timeoutTestArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 34, insertParams.MaxAttempts)
+		require.Equal(t, QueueDefault, insertParams.Queue)
+		require.Equal(t, []string{}, insertParams.Tags)
+	})
+
+	t.Run("ConfigTestJobIDFunc", func(t *testing.T) {
+		t.Parallel()
+
+		var nextID int64
+		overrideConfig := &Config{
+			Test: TestConfig{
+				JobIDFunc: func() int64 { nextID++; return nextID },
+			},
+		}
+
+		insertParams1, err := insertParamsFromConfigArgsAndOptions(archetype, overrideConfig, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), *insertParams1.ID)
+
+		insertParams2, err := insertParamsFromConfigArgsAndOptions(archetype, overrideConfig, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), *insertParams2.ID)
+
+		// No ID is set without a generator, leaving it to the database.
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Nil(t, insertParams.ID)
+	})
+
 	t.Run("InsertOptsOverrides", func(t *testing.T) {
 		t.Parallel()
 
@@ -8866,6 +11868,64 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		require.Nil(t, insertParams.ScheduledAt)
 	})
 
+	t.Run("ScheduleIn", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{ScheduleIn: time.Hour})
+		require.NoError(t, err)
+		require.Nil(t, insertParams.ScheduledAt)
+		require.Equal(t, time.Hour, insertParams.ScheduledIn)
+		require.Equal(t, rivertype.JobStateScheduled, insertParams.State)
+	})
+
+	t.Run("ScheduleInWithStubbedTime", func(t *testing.T) {
+		t.Parallel()
+
+		archetype := riversharedtest.BaseServiceArchetype(t)
+		now := archetype.Time.StubNow(time.Now().UTC())
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{ScheduleIn: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, now.Add(time.Hour), *insertParams.ScheduledAt)
+		require.Zero(t, insertParams.ScheduledIn)
+		require.Equal(t, rivertype.JobStateScheduled, insertParams.State)
+	})
+
+	t.Run("ScheduleInValidated", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{ScheduleIn: -time.Hour})
+		require.EqualError(t, err, "ScheduleIn cannot be less than zero")
+
+		_, err = insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{ScheduleIn: time.Hour, ScheduledAt: time.Now()})
+		require.EqualError(t, err, "ScheduleIn and ScheduledAt cannot both be set")
+	})
+
+	t.Run("StructTagInsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, structTagInsertOptsArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, insertParams.MaxAttempts)
+		require.Equal(t, 2, insertParams.Priority)
+		require.Equal(t, "email", insertParams.Queue)
+
+		// Options passed at insertion time take precedence.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, config, structTagInsertOptsArgs{}, &InsertOpts{Queue: "other"})
+		require.NoError(t, err)
+		require.Equal(t, 2, insertParams.Priority)
+		require.Equal(t, "other", insertParams.Queue)
+
+		// As do options from an InsertOpts method on the args.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, config, structTagAndMethodInsertOptsArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, insertParams.Priority)
+		require.Equal(t, "other", insertParams.Queue)
+
+		_, err = insertParamsFromConfigArgsAndOptions(archetype, config, structTagInvalidInsertOptsArgs{}, nil)
+		require.ErrorContains(t, err, "priority must be an integer between 1 and 4")
+	})
+
 	t.Run("TagFormatValidated", func(t *testing.T) {
 		t.Parallel()
 
@@ -9033,6 +12093,36 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		require.EqualError(t, err, "UniqueOpts.ByPeriod should not be less than 1 second")
 		require.Nil(t, insertParams)
 	})
+
+	t.Run("ArgsAreValidated", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, validatedArgs{Email: "a@example.com"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "validated", insertParams.Kind)
+
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, config, validatedArgs{}, nil)
+		require.EqualError(t, err, `invalid args for job of kind "validated": email is required`)
+		require.Nil(t, insertParams)
+
+		var validationErr *JobArgsValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, "validated", validationErr.Kind)
+		require.EqualError(t, validationErr.Err, "email is required")
+	})
+}
+
+type validatedArgs struct {
+	Email string `json:"email"`
+}
+
+func (validatedArgs) Kind() string { return "validated" }
+
+func (a validatedArgs) Validate() error {
+	if a.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
 }
 
 func TestID(t *testing.T) {
@@ -9268,6 +12358,46 @@ func TestUniqueOpts(t *testing.T) {
 		require.Equal(t, insertRes0.Job.ID, insertRes1.Job.ID)
 	})
 
+	t.Run("ReturnsExistingJobOnSkip", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		uniqueOpts := UniqueOpts{
+			ByPeriod: 24 * time.Hour,
+		}
+
+		insertRes0, err := client.Insert(ctx, noOpArgs{Name: "original"}, &InsertOpts{
+			Metadata:   []byte(`{"original":true}`),
+			Priority:   2,
+			Tags:       []string{"original"},
+			UniqueOpts: uniqueOpts,
+		})
+		require.NoError(t, err)
+		require.False(t, insertRes0.UniqueSkippedAsDuplicate)
+
+		// Args aren't part of the unique key, so this insert conflicts with
+		// the original despite its different properties.
+		insertRes1, err := client.Insert(ctx, noOpArgs{Name: "duplicate"}, &InsertOpts{
+			Metadata:   []byte(`{"duplicate":true}`),
+			Priority:   3,
+			Tags:       []string{"duplicate"},
+			UniqueOpts: uniqueOpts,
+		})
+		require.NoError(t, err)
+		require.True(t, insertRes1.UniqueSkippedAsDuplicate)
+
+		// The full existing job comes back rather than the params of the
+		// skipped insert.
+		require.Equal(t, insertRes0.Job.ID, insertRes1.Job.ID)
+		require.JSONEq(t, `{"name":"original"}`, string(insertRes1.Job.EncodedArgs))
+		require.Equal(t, insertRes0.Job.CreatedAt, insertRes1.Job.CreatedAt)
+		require.True(t, gjson.GetBytes(insertRes1.Job.Metadata, "original").Bool())
+		require.False(t, gjson.GetBytes(insertRes1.Job.Metadata, "duplicate").Exists())
+		require.Equal(t, 2, insertRes1.Job.Priority)
+		require.Equal(t, []string{"original"}, insertRes1.Job.Tags)
+	})
+
 	t.Run("UniqueByCustomStates", func(t *testing.T) {
 		t.Parallel()
 
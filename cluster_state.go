@@ -0,0 +1,149 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// ClusterState is a snapshot of the state of all clients sharing a database
+// and schema as returned by Client.ClusterState.
+type ClusterState struct {
+	// Clients are the clients currently working jobs, ordered by ID. Clients
+	// aren't registered anywhere in the database, so those that are idle
+	// aren't included.
+	Clients []*ClusterStateClient
+
+	// Leader is the currently elected leader, which runs maintenance services.
+	// Nil if no leader is elected, which should only be the case briefly
+	// after a leader stops or if no clients are running.
+	Leader *ClusterStateLeader
+
+	// Maintenance contains the maintenance services that have completed a run,
+	// ordered by name. Maintenance services only run on the leader and their
+	// run times are only known to it, so this is only populated when
+	// ClusterState is called on the client that's currently leader.
+	Maintenance []*ClusterStateMaintenanceService
+
+	// Queues are all known queues, including whether they're paused and live
+	// counts of their available and running jobs.
+	Queues []*rivertype.Queue
+}
+
+// ClusterStateClient is a client working jobs as part of a ClusterState.
+type ClusterStateClient struct {
+	// CountRunning is the number of jobs the client is currently working.
+	CountRunning int
+
+	// ID is the client's ID as configured with Config.ID.
+	ID string
+
+	// LastAttemptedAt is the most recent time the client started working one
+	// of its running jobs. A client that hasn't started a job in a long time
+	// despite having running jobs may be stuck or gone.
+	LastAttemptedAt time.Time
+}
+
+// ClusterStateLeader is the elected leader as part of a ClusterState.
+type ClusterStateLeader struct {
+	// ElectedAt is when the leader was elected.
+	ElectedAt time.Time
+
+	// ExpiresAt is when the leader's term expires unless it's reelected.
+	// Leaders reelect themselves regularly, so a time in the past suggests
+	// the leader has gone away.
+	ExpiresAt time.Time
+
+	// ID is the ID of the leader client.
+	ID string
+}
+
+// ClusterStateMaintenanceService is a maintenance service as part of a
+// ClusterState.
+type ClusterStateMaintenanceService struct {
+	// LastRunAt is when the service last completed a run.
+	LastRunAt time.Time
+
+	// Name is the name of the service, like "JobCleaner".
+	Name string
+}
+
+// The maximum number of queues returned in a ClusterState.
+const clusterStateQueuesMax = 10_000
+
+// ClusterState returns a snapshot of the state of all clients sharing the
+// client's database and schema, including the elected leader, the clients
+// working jobs, and queues with their pause states and job counts. It's meant
+// to back a one page operational overview.
+//
+// The snapshot is assembled with a handful of queries that aren't run in a
+// single transaction, so it may be slightly inconsistent on a busy cluster.
+func (c *Client[TTx]) ClusterState(ctx context.Context) (*ClusterState, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	exec := c.driver.GetExecutor()
+
+	state := &ClusterState{}
+
+	leader, err := exec.LeaderGetElectedLeader(ctx, &riverdriver.LeaderGetElectedLeaderParams{
+		Schema: c.config.Schema,
+	})
+	if err != nil && !errors.Is(err, rivertype.ErrNotFound) {
+		return nil, fmt.Errorf("error getting leader: %w", err)
+	}
+	if leader != nil {
+		state.Leader = &ClusterStateLeader{
+			ElectedAt: leader.ElectedAt,
+			ExpiresAt: leader.ExpiresAt,
+			ID:        leader.LeaderID,
+		}
+	}
+
+	clientCounts, err := exec.JobCountRunningByClient(ctx, &riverdriver.JobCountRunningByClientParams{
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error counting running jobs by client: %w", err)
+	}
+	state.Clients = make([]*ClusterStateClient, len(clientCounts))
+	for i, clientCount := range clientCounts {
+		state.Clients[i] = &ClusterStateClient{
+			CountRunning:    int(clientCount.CountRunning),
+			ID:              clientCount.ClientID,
+			LastAttemptedAt: clientCount.LastAttemptedAt,
+		}
+	}
+
+	state.Queues, err = exec.QueueList(ctx, &riverdriver.QueueListParams{
+		Max:    clusterStateQueuesMax,
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing queues: %w", err)
+	}
+	if err := c.queuesPopulateCounts(ctx, exec, state.Queues); err != nil {
+		return nil, err
+	}
+
+	if c.queueMaintainer != nil && c.elector.IsLeader() {
+		for name, lastRunAt := range c.queueMaintainer.LastRunTimes() {
+			state.Maintenance = append(state.Maintenance, &ClusterStateMaintenanceService{
+				LastRunAt: lastRunAt,
+				Name:      name,
+			})
+		}
+		slices.SortFunc(state.Maintenance, func(a, b *ClusterStateMaintenanceService) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+
+	return state, nil
+}
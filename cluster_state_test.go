@@ -0,0 +1,144 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_Client_ClusterState(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+		)
+
+		return client, &testBundle{
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		state, err := client.ClusterState(ctx)
+		require.NoError(t, err)
+		require.Empty(t, state.Clients)
+		require.Nil(t, state.Leader)
+		require.Empty(t, state.Maintenance)
+		require.Empty(t, state.Queues)
+	})
+
+	t.Run("Populated", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		_, err := bundle.exec.LeaderInsert(ctx, &riverdriver.LeaderInsertParams{
+			LeaderID: "leader_client",
+			Now:      &now,
+			Schema:   bundle.schema,
+			TTL:      10 * time.Second,
+		})
+		require.NoError(t, err)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{AttemptedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)), AttemptedBy: []string{"client1"}, Queue: ptrutil.Ptr("queue1"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{AttemptedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)), AttemptedBy: []string{"client1"}, Queue: ptrutil.Ptr("queue1"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{AttemptedAt: ptrutil.Ptr(now.Add(-3 * time.Minute)), AttemptedBy: []string{"client1", "client2"}, Queue: ptrutil.Ptr("queue2"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue1"), Schema: bundle.schema})
+		testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue2"), PausedAt: &now, Schema: bundle.schema})
+
+		state, err := client.ClusterState(ctx)
+		require.NoError(t, err)
+
+		require.NotNil(t, state.Leader)
+		require.Equal(t, "leader_client", state.Leader.ID)
+		require.WithinDuration(t, now, state.Leader.ElectedAt, time.Millisecond)
+		require.WithinDuration(t, now.Add(10*time.Second), state.Leader.ExpiresAt, time.Millisecond)
+
+		require.Len(t, state.Clients, 2)
+		require.Equal(t, "client1", state.Clients[0].ID)
+		require.Equal(t, 2, state.Clients[0].CountRunning)
+		require.WithinDuration(t, now.Add(-1*time.Minute), state.Clients[0].LastAttemptedAt, time.Millisecond)
+		require.Equal(t, "client2", state.Clients[1].ID)
+		require.Equal(t, 1, state.Clients[1].CountRunning)
+		require.WithinDuration(t, now.Add(-3*time.Minute), state.Clients[1].LastAttemptedAt, time.Millisecond)
+
+		require.Len(t, state.Queues, 2)
+		require.Equal(t, "queue1", state.Queues[0].Name)
+		require.Nil(t, state.Queues[0].PausedAt)
+		require.Equal(t, "queue2", state.Queues[1].Name)
+		require.NotNil(t, state.Queues[1].PausedAt)
+
+		// Not the leader, so no maintenance services are reported.
+		require.Empty(t, state.Maintenance)
+	})
+
+	t.Run("MaintenanceOnLeader", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		client.testSignals.Init(t)
+
+		startClient(ctx, t, client)
+
+		client.queueMaintainerLeader.TestSignals.ElectedLeader.WaitOrTimeout()
+
+		require.Eventually(t, func() bool {
+			state, err := client.ClusterState(ctx)
+			require.NoError(t, err)
+			return len(state.Maintenance) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		state, err := client.ClusterState(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, state.Leader)
+		require.Equal(t, client.ID(), state.Leader.ID)
+
+		for _, service := range state.Maintenance {
+			require.NotEmpty(t, service.Name)
+			require.False(t, service.LastRunAt.IsZero())
+		}
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{})
+		require.NoError(t, err)
+
+		_, err = client.ClusterState(ctx)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/riverqueue/river/cmd/river/riverbench"
+	"github.com/riverqueue/river/cmd/river/riversoak"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/riverdriver/riversqlite"
@@ -20,6 +21,7 @@ import (
 type DriverProcurer interface {
 	GetBenchmarker(config *riverbench.Config) BenchmarkerInterface
 	GetMigrator(config *rivermigrate.Config) (MigratorInterface, error)
+	GetSoaker(config *riversoak.Config) SoakerInterface
 	QueryRow(ctx context.Context, sql string, args ...any) riverdriver.Row
 }
 
@@ -45,6 +47,12 @@ type MigratorInterface interface {
 	Validate(ctx context.Context, opts *rivermigrate.ValidateOpts) (*rivermigrate.ValidateResult, error)
 }
 
+// SoakerInterface is an interface to a Soaker. Like BenchmarkerInterface, it
+// strips a soaker of its generic parameter.
+type SoakerInterface interface {
+	Run(ctx context.Context, duration time.Duration) error
+}
+
 type pgxV5DriverProcurer struct {
 	dbPool *pgxpool.Pool
 }
@@ -57,6 +65,10 @@ func (p *pgxV5DriverProcurer) GetMigrator(config *rivermigrate.Config) (Migrator
 	return rivermigrate.New(riverpgxv5.New(p.dbPool), config)
 }
 
+func (p *pgxV5DriverProcurer) GetSoaker(config *riversoak.Config) SoakerInterface {
+	return riversoak.NewSoaker(riverpgxv5.New(p.dbPool), config)
+}
+
 func (p *pgxV5DriverProcurer) QueryRow(ctx context.Context, sql string, args ...any) riverdriver.Row {
 	return riverpgxv5.New(p.dbPool).GetExecutor().QueryRow(ctx, sql, args...)
 }
@@ -73,6 +85,10 @@ func (p *sqliteDriverProcurer) GetMigrator(config *rivermigrate.Config) (Migrato
 	return rivermigrate.New(riversqlite.New(p.dbPool), config)
 }
 
+func (p *sqliteDriverProcurer) GetSoaker(config *riversoak.Config) SoakerInterface {
+	return riversoak.NewSoaker(riversqlite.New(p.dbPool), config)
+}
+
 func (p *sqliteDriverProcurer) QueryRow(ctx context.Context, sql string, args ...any) riverdriver.Row {
 	return riversqlite.New(p.dbPool).GetExecutor().QueryRow(ctx, sql, args...)
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/riverqueue/river/cmd/river/riverbench"
+	"github.com/riverqueue/river/cmd/river/riversoak"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivermigrate"
 	"github.com/riverqueue/river/rivershared/sqlctemplate"
@@ -329,6 +330,39 @@ dump prospective migrations that would be applied to stdout.
 		rootCmd.AddCommand(cmd)
 	}
 
+	// soak
+	{
+		var opts soakOpts
+
+		cmd := &cobra.Command{
+			Use:   "soak",
+			Short: "Run River soak test",
+			Long: strings.TrimSpace(`
+Run a River soak test which continuously inserts and works jobs while checking
+invariants that should hold however long a client runs: no job is worked twice
+concurrently, no job is stuck running for longer than --stuck-threshold, no job
+is unexpectedly discarded or cancelled, and once load stops and remaining jobs
+are drained, inserted and completed job counts reconcile with the database.
+
+It's intended to be run for an extended period before rolling out changes to a
+driver or to the job completer. By default, it runs until interrupted by SIGINT
+(Ctrl^C), or can take a maximum run duration with --duration. Exits with a
+non-zero status in case any invariant was violated.
+
+The database in --database-url will have its jobs table truncated, so make sure
+to use a development database only.
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &soak{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		addSchemaFlag(cmd, &opts.Schema)
+		cmd.Flags().DurationVar(&opts.Duration, "duration", 0, "duration after which to stop generating load, accepting Go-style durations like 1h, 12h")
+		cmd.Flags().DurationVar(&opts.StuckThreshold, "stuck-threshold", riversoak.StuckThresholdDefault, "duration after which a job still running is considered stuck")
+		rootCmd.AddCommand(cmd)
+	}
+
 	// validate
 	{
 		var opts validateOpts
@@ -684,6 +718,36 @@ func (c *migrateUp) Run(ctx context.Context, opts *migrateOpts) (bool, error) {
 	return true, nil
 }
 
+type soakOpts struct {
+	DatabaseURL    string
+	Duration       time.Duration
+	Schema         string
+	StuckThreshold time.Duration
+}
+
+func (o *soakOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	return nil
+}
+
+type soak struct {
+	CommandBase
+}
+
+func (c *soak) Run(ctx context.Context, opts *soakOpts) (bool, error) {
+	if err := c.DriverProcurer.GetSoaker(&riversoak.Config{
+		Logger:         c.Logger,
+		Schema:         c.Schema,
+		StuckThreshold: opts.StuckThreshold,
+	}).Run(ctx, opts.Duration); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 type validateOpts struct {
 	DatabaseURL string
 	Line        string
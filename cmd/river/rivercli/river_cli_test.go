@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/riverqueue/river/cmd/river/riverbench"
+	"github.com/riverqueue/river/cmd/river/riversoak"
 	"github.com/riverqueue/river/riverdbtest"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
@@ -27,6 +28,7 @@ import (
 type DriverProcurerStub struct {
 	getBenchmarkerStub func(config *riverbench.Config) BenchmarkerInterface
 	getMigratorStub    func(config *rivermigrate.Config) (MigratorInterface, error)
+	getSoakerStub      func(config *riversoak.Config) SoakerInterface
 	initPgxV5Stub      func(pool *pgxpool.Pool)
 	queryRowStub       func(ctx context.Context, sql string, args ...any) riverdriver.Row
 }
@@ -47,6 +49,14 @@ func (p *DriverProcurerStub) GetMigrator(config *rivermigrate.Config) (MigratorI
 	return p.getMigratorStub(config)
 }
 
+func (p *DriverProcurerStub) GetSoaker(config *riversoak.Config) SoakerInterface {
+	if p.getSoakerStub == nil {
+		panic("GetSoaker is not stubbed")
+	}
+
+	return p.getSoakerStub(config)
+}
+
 func (p *DriverProcurerStub) InitPgxV5(pool *pgxpool.Pool) {
 	if p.initPgxV5Stub == nil {
 		panic("InitPgxV5 is not stubbed")
@@ -0,0 +1,437 @@
+package riversoak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// ErrInvariantViolated is returned by Run in case any of the soak test's
+// invariants were violated during the run. The returned error wraps it, and
+// contains a description of each violation.
+var ErrInvariantViolated = errors.New("soak test invariant violated")
+
+// Soaker runs a long-running soak test which generates continuous load against
+// a River client while checking a set of invariants that should hold no matter
+// how long the client runs:
+//
+//   - No job is worked by more than one worker at the same time.
+//   - No job is stuck in a running state for longer than a stuck threshold.
+//   - No job is unexpectedly discarded or cancelled.
+//   - Once load stops and remaining jobs are drained, the number of jobs
+//     inserted reconciles with the number of jobs completed, both as observed
+//     by the client's event subscription and as counted in the database.
+//
+// It's intended to be run for an extended period against candidate driver or
+// completer changes before they're rolled out. The jobs it inserts fail
+// randomly on their first attempt so that retries are exercised alongside the
+// happy path.
+type Soaker[TTx any] struct {
+	driver         riverdriver.Driver[TTx] // database pool wrapped in River driver
+	logger         *slog.Logger            // logger, also injected to client
+	name           string                  // name of the service for logging purposes
+	schema         string                  // custom schema where River tables are located
+	stuckThreshold time.Duration           // duration after which a running job is considered stuck
+
+	violations   []string
+	violationsMu sync.Mutex
+}
+
+type Config struct {
+	Logger *slog.Logger
+	Schema string
+
+	// StuckThreshold is the duration after which a job that's still running
+	// is considered stuck and reported as an invariant violation.
+	//
+	// Defaults to 1 minute.
+	StuckThreshold time.Duration
+}
+
+func NewSoaker[TTx any](driver riverdriver.Driver[TTx], config *Config) *Soaker[TTx] {
+	stuckThreshold := config.StuckThreshold
+	if stuckThreshold == 0 {
+		stuckThreshold = StuckThresholdDefault
+	}
+
+	return &Soaker[TTx]{
+		driver:         driver,
+		logger:         config.Logger,
+		name:           "Soaker",
+		schema:         config.Schema,
+		stuckThreshold: stuckThreshold,
+	}
+}
+
+const (
+	// DrainTimeoutDefault is how long a soak test waits for remaining jobs to
+	// be worked after it stops inserting new ones.
+	DrainTimeoutDefault = 1 * time.Minute
+
+	// StuckThresholdDefault is the default value of Config.StuckThreshold.
+	StuckThresholdDefault = 1 * time.Minute
+
+	insertBatchSize = 500
+	maxJobsLeft     = 10_000 // don't let the backlog grow past this so that drain stays fast
+)
+
+// Run starts the soak test. Load is generated until receiving SIGINT/SIGTERM,
+// or when reaching maximum configured run duration, after which remaining jobs
+// are drained and final counts reconciled. Returns an error wrapping
+// ErrInvariantViolated if any invariant was violated.
+func (s *Soaker[TTx]) Run(ctx context.Context, duration time.Duration) error {
+	var (
+		numJobsCompleted atomic.Int64
+		numJobsInserted  atomic.Int64
+		shutdown         = make(chan struct{})
+		shutdownOnce     sync.Once
+	)
+
+	closeShutdown := func() {
+		shutdownOnce.Do(func() {
+			s.logger.DebugContext(ctx, "Closing shutdown channel")
+			close(shutdown)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// As with the benchmarker, install signals so that a SIGINT stops load
+	// gracefully and still produces a final reconciliation. A second signal
+	// cancels context, forcing a hard shut down.
+	go func() {
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+
+		select {
+		case <-ctx.Done():
+		case <-signalChan:
+			closeShutdown()
+
+			select {
+			case <-ctx.Done():
+			case <-signalChan:
+				fmt.Printf("second signal received; canceling context\n")
+				cancel()
+			}
+		}
+	}()
+
+	if err := s.resetJobsTable(ctx); err != nil {
+		return err
+	}
+
+	worker := &SoakWorker{
+		running:         make(map[int64]struct{}),
+		violationFunc:   func(msg string, args ...any) { s.violation(ctx, msg, args...) },
+		workDurationMax: 10 * time.Millisecond,
+	}
+
+	workers := river.NewWorkers()
+	river.AddWorker(workers, worker)
+
+	client, err := river.NewClient(s.driver, &river.Config{
+		FetchCooldown:     10 * time.Millisecond,
+		FetchPollInterval: 100 * time.Millisecond,
+		Logger:            s.logger,
+		Queues: map[string]river.QueueConfig{
+			river.QueueDefault: {MaxWorkers: 100},
+		},
+		RetryPolicy: &soakRetryPolicy{},
+		Schema:      s.schema,
+		Workers:     workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	subscribeChan, subscribeCancel := client.SubscribeConfig(&river.SubscribeConfig{
+		// Dropped events would throw off reconciliation, so pick a channel size
+		// comfortably larger than the maximum backlog.
+		ChanSize: 2 * maxJobsLeft,
+
+		Kinds: []river.EventKind{
+			river.EventKindJobCancelled,
+			river.EventKindJobCompleted,
+			river.EventKindJobFailed,
+		},
+	})
+	defer subscribeCancel()
+
+	subscribeDone := make(chan struct{})
+	go func() {
+		defer close(subscribeDone)
+
+		for event := range subscribeChan {
+			switch {
+			case event.Kind == river.EventKindJobCancelled:
+				s.violation(ctx, "job unexpectedly cancelled", "job_id", event.Job.ID)
+
+			case event.Kind == river.EventKindJobCompleted:
+				numJobsCompleted.Add(1)
+
+			case event.Kind == river.EventKindJobFailed && event.Job.State == rivertype.JobStateDiscarded:
+				s.violation(ctx, "job unexpectedly discarded", "job_id", event.Job.ID)
+
+			case event.Kind == river.EventKindJobFailed:
+				// Expected failure that'll be retried.
+
+			default:
+				s.logger.ErrorContext(ctx, s.name+": Unhandled subscription event kind", "kind", event.Kind)
+			}
+		}
+	}()
+
+	if err := client.Start(ctx); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, s.name+": Client started; generating load", "duration", duration, "stuck_threshold", s.stuckThreshold)
+
+	const iterationPeriod = 5 * time.Second
+
+	var (
+		insertTicker = time.NewTicker(100 * time.Millisecond)
+		start        = time.Now()
+		ticker       = time.NewTicker(iterationPeriod)
+	)
+	defer insertTicker.Stop()
+	defer ticker.Stop()
+
+loadLoop:
+	for {
+		if duration != 0 && time.Since(start) >= duration {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-shutdown:
+			break loadLoop
+
+		case <-insertTicker.C:
+			if numJobsInserted.Load()-numJobsCompleted.Load() >= maxJobsLeft {
+				continue
+			}
+
+			if err := s.insertBatch(ctx, client, &numJobsInserted); err != nil {
+				s.logger.ErrorContext(ctx, s.name+": Error inserting jobs", "err", err)
+			}
+
+		case <-ticker.C:
+			s.checkStuckJobs(ctx)
+
+			fmt.Printf("soak: jobs completed [ %10d ], inserted [ %10d ], violations [ %4d ], running %s\n",
+				numJobsCompleted.Load(), numJobsInserted.Load(), s.numViolations(), time.Since(start).Round(time.Second))
+		}
+	}
+
+	s.logger.InfoContext(ctx, s.name+": Load stopped; draining remaining jobs", "num_jobs_left", numJobsInserted.Load()-numJobsCompleted.Load())
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, DrainTimeoutDefault)
+	defer drainCancel()
+
+drainLoop:
+	for numJobsCompleted.Load() < numJobsInserted.Load() {
+		select {
+		case <-drainCtx.Done():
+			s.violation(ctx, "timed out draining jobs",
+				"num_jobs_completed", numJobsCompleted.Load(), "num_jobs_inserted", numJobsInserted.Load())
+			break drainLoop
+
+		case <-ticker.C:
+			s.checkStuckJobs(ctx)
+
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	if err := client.Stop(ctx); err != nil {
+		return fmt.Errorf("error stopping client: %w", err)
+	}
+
+	subscribeCancel()
+	<-subscribeDone
+
+	if err := s.reconcileCounts(ctx, numJobsInserted.Load(), numJobsCompleted.Load()); err != nil {
+		return err
+	}
+
+	fmt.Printf("soak: total jobs completed [ %10d ], total jobs inserted [ %10d ], violations [ %4d ], ran %s\n",
+		numJobsCompleted.Load(), numJobsInserted.Load(), s.numViolations(), time.Since(start).Round(time.Second))
+
+	s.violationsMu.Lock()
+	defer s.violationsMu.Unlock()
+
+	if len(s.violations) > 0 {
+		return fmt.Errorf("%w: %d violation(s):\n%s", ErrInvariantViolated, len(s.violations), strings.Join(s.violations, "\n"))
+	}
+
+	return nil
+}
+
+// Checks for any jobs that have been running for longer than the stuck
+// threshold.
+func (s *Soaker[TTx]) checkStuckJobs(ctx context.Context) {
+	stuckJobs, err := s.driver.GetExecutor().JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
+		Max:          100,
+		Now:          time.Now(),
+		Schema:       s.schema,
+		StuckHorizon: time.Now().Add(-s.stuckThreshold),
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			s.logger.ErrorContext(ctx, s.name+": Error checking for stuck jobs", "err", err)
+		}
+		return
+	}
+
+	for _, job := range stuckJobs {
+		s.violation(ctx, "job stuck running", "job_id", job.ID, "attempted_at", job.AttemptedAt)
+	}
+}
+
+func (s *Soaker[TTx]) insertBatch(ctx context.Context, client *river.Client[TTx], numJobsInserted *atomic.Int64) error {
+	insertParamsBatch := make([]river.InsertManyParams, insertBatchSize)
+	for i := range insertParamsBatch {
+		insertParamsBatch[i].Args = SoakArgs{
+			FailFirstAttempt: rand.IntN(10) == 0, //nolint:gosec
+		}
+	}
+
+	if _, err := client.InsertMany(ctx, insertParamsBatch); err != nil {
+		return err
+	}
+
+	numJobsInserted.Add(int64(len(insertParamsBatch)))
+	return nil
+}
+
+func (s *Soaker[TTx]) numViolations() int {
+	s.violationsMu.Lock()
+	defer s.violationsMu.Unlock()
+
+	return len(s.violations)
+}
+
+// Reconciles the number of jobs inserted and completed as observed by the soak
+// test with job counts in the database, recording a violation for any
+// mismatch.
+func (s *Soaker[TTx]) reconcileCounts(ctx context.Context, numJobsInserted, numJobsCompleted int64) error {
+	if numJobsCompleted != numJobsInserted {
+		s.violation(ctx, "completed job count doesn't match inserted count",
+			"num_jobs_completed", numJobsCompleted, "num_jobs_inserted", numJobsInserted)
+	}
+
+	for _, state := range rivertype.JobStates() {
+		numJobs, err := s.driver.GetExecutor().JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+			Schema: s.schema,
+			State:  state,
+		})
+		if err != nil {
+			return fmt.Errorf("error counting %s jobs: %w", state, err)
+		}
+
+		var expected int64
+		if state == rivertype.JobStateCompleted {
+			expected = numJobsInserted
+		}
+
+		if int64(numJobs) != expected {
+			s.violation(ctx, "database job count doesn't reconcile",
+				"state", state, "num_jobs", numJobs, "num_jobs_expected", expected)
+		}
+	}
+
+	return nil
+}
+
+// Truncates the jobs table so that final counts can be reconciled against jobs
+// inserted by this run only.
+func (s *Soaker[TTx]) resetJobsTable(ctx context.Context) error {
+	s.logger.InfoContext(ctx, s.name+": Truncating jobs table")
+
+	return s.driver.GetExecutor().TableTruncate(ctx, &riverdriver.TableTruncateParams{
+		Schema: s.schema,
+		Table:  s.driver.GetMigrationTruncateTables(riverdriver.MigrationLineMain, 0),
+	})
+}
+
+// Records an invariant violation and logs it immediately so that it's visible
+// while the soak test is still running.
+func (s *Soaker[TTx]) violation(ctx context.Context, msg string, args ...any) {
+	s.logger.ErrorContext(ctx, s.name+": Invariant violated: "+msg, args...)
+
+	s.violationsMu.Lock()
+	defer s.violationsMu.Unlock()
+
+	s.violations = append(s.violations, fmt.Sprintln(append([]any{msg}, args...)...))
+}
+
+type SoakArgs struct {
+	FailFirstAttempt bool `json:"fail_first_attempt"`
+}
+
+func (SoakArgs) Kind() string { return "soak" }
+
+// SoakWorker is a job worker that tracks which jobs are currently being worked
+// so that it can detect any job being worked twice concurrently.
+type SoakWorker struct {
+	river.WorkerDefaults[SoakArgs]
+
+	running         map[int64]struct{}
+	runningMu       sync.Mutex
+	violationFunc   func(msg string, args ...any)
+	workDurationMax time.Duration
+}
+
+func (w *SoakWorker) Work(ctx context.Context, job *river.Job[SoakArgs]) error {
+	w.runningMu.Lock()
+	if _, ok := w.running[job.ID]; ok {
+		w.violationFunc("job worked twice concurrently", "job_id", job.ID, "attempt", job.Attempt)
+	}
+	w.running[job.ID] = struct{}{}
+	w.runningMu.Unlock()
+
+	defer func() {
+		w.runningMu.Lock()
+		delete(w.running, job.ID)
+		w.runningMu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(rand.N(w.workDurationMax)): //nolint:gosec
+	}
+
+	if job.Args.FailFirstAttempt && job.Attempt == 1 {
+		return errors.New("failing first attempt as requested")
+	}
+
+	return nil
+}
+
+// soakRetryPolicy retries jobs almost immediately so that retried jobs don't
+// hold up draining at the end of a soak test.
+type soakRetryPolicy struct{}
+
+func (*soakRetryPolicy) NextRetry(job *rivertype.JobRow) time.Time {
+	return time.Now().Add(100 * time.Millisecond)
+}
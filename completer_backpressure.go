@@ -0,0 +1,177 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+// completerBackpressureThresholdDefault is half of the completer's maximum
+// backlog, after which jobs finishing work start to wait to be completed.
+const completerBackpressureThresholdDefault = 10_000
+
+// CompleterBackpressure contains the size of the backlog of job completions
+// waiting to be written to the database along with the thresholds it was
+// compared against. It's sent with EventKindCompleterBackpressureEngaged and
+// EventKindCompleterBackpressureReleased events.
+type CompleterBackpressure struct {
+	// Backlog is the number of completions waiting to be written.
+	Backlog int
+
+	// ResumeThreshold is the configured
+	// CompleterBackpressureConfig.ResumeThreshold.
+	ResumeThreshold int
+
+	// Threshold is the configured CompleterBackpressureConfig.Threshold.
+	Threshold int
+}
+
+// CompleterBackpressureConfig configures backpressure that pauses fetching
+// while the backlog of job completions waiting to be written to the database
+// is large. See Config.CompleterBackpressure.
+//
+// Completions are written in batches, and when the database is slow to accept
+// them, they accumulate in a backlog. Producers that keep fetching jobs while
+// it grows only make it grow faster, and once it reaches its maximum, jobs
+// that have finished working wait to be completed while holding their worker
+// slots. Backpressure engages when the backlog exceeds Threshold, after which
+// the client stops fetching new jobs and an
+// EventKindCompleterBackpressureEngaged event is emitted. Once the backlog
+// drains to ResumeThreshold, fetching resumes and an
+// EventKindCompleterBackpressureReleased event is emitted.
+type CompleterBackpressureConfig struct {
+	// ResumeThreshold is the size the backlog must drain to after
+	// backpressure engages for fetching to resume. It keeps the client from
+	// flapping between pausing and resuming when the backlog hovers around
+	// Threshold. Must be less than Threshold.
+	//
+	// Defaults to half of Threshold.
+	ResumeThreshold int
+
+	// Threshold is the number of completions in the backlog above which the
+	// client stops fetching new jobs.
+	//
+	// Defaults to 10,000, which is half of the backlog's maximum size.
+	Threshold int
+}
+
+func (c *CompleterBackpressureConfig) validate() error {
+	if c.ResumeThreshold < 0 {
+		return errors.New("CompleterBackpressure.ResumeThreshold cannot be less than zero")
+	}
+	if c.Threshold < 0 {
+		return errors.New("CompleterBackpressure.Threshold cannot be less than zero")
+	}
+	if config := c.withDefaults(); config.ResumeThreshold >= config.Threshold {
+		return errors.New("CompleterBackpressure.ResumeThreshold must be less than Threshold")
+	}
+	return nil
+}
+
+func (c *CompleterBackpressureConfig) withDefaults() *CompleterBackpressureConfig {
+	threshold := cmp.Or(c.Threshold, completerBackpressureThresholdDefault)
+
+	return &CompleterBackpressureConfig{
+		ResumeThreshold: cmp.Or(c.ResumeThreshold, threshold/2),
+		Threshold:       threshold,
+	}
+}
+
+// completerBackpressure tracks whether the completer's backlog is large
+// enough that producers should stop fetching. Rather than running its own
+// loop, the backlog is checked each time a producer is about to fetch, and
+// because producers keep trying to fetch on every fetch poll interval while
+// it's engaged, it's released promptly once the backlog drains.
+type completerBackpressure struct {
+	baseservice.BaseService
+
+	// backlogFunc returns the completer's backlog size.
+	backlogFunc func() int
+
+	config *CompleterBackpressureConfig
+
+	// eventCallback receives backpressure events. Set after construction so
+	// that it can be pointed at the subscription manager.
+	eventCallback func(event *Event)
+
+	mu         sync.Mutex
+	engaged    bool
+	numEngaged int64
+}
+
+func newCompleterBackpressure(archetype *baseservice.Archetype, config *CompleterBackpressureConfig, backlogFunc func() int) *completerBackpressure {
+	return baseservice.Init(archetype, &completerBackpressure{
+		backlogFunc: backlogFunc,
+		config:      config.withDefaults(),
+	})
+}
+
+// IsEngaged checks the completer's backlog, returning true if producers should
+// stop fetching. Safe to call on a nil backpressure for clients that don't
+// have it configured.
+func (b *completerBackpressure) IsEngaged() bool {
+	if b == nil {
+		return false
+	}
+
+	backlog := b.backlogFunc()
+
+	var event *Event
+	engaged := func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		switch {
+		case !b.engaged && backlog > b.config.Threshold:
+			b.engaged = true
+			b.numEngaged++
+			event = &Event{Kind: EventKindCompleterBackpressureEngaged, CompleterBackpressure: b.toCompleterBackpressure(backlog)}
+
+			b.Logger.WarnContext(context.Background(), b.Name+": Completer backlog over threshold; pausing job fetching",
+				slog.Int("backlog", backlog),
+				slog.Int("threshold", b.config.Threshold),
+			)
+
+		case b.engaged && backlog <= b.config.ResumeThreshold:
+			b.engaged = false
+			event = &Event{Kind: EventKindCompleterBackpressureReleased, CompleterBackpressure: b.toCompleterBackpressure(backlog)}
+
+			b.Logger.InfoContext(context.Background(), b.Name+": Completer backlog drained; resuming job fetching",
+				slog.Int("backlog", backlog),
+				slog.Int("resume_threshold", b.config.ResumeThreshold),
+			)
+		}
+
+		return b.engaged
+	}()
+
+	if event != nil && b.eventCallback != nil {
+		b.eventCallback(event)
+	}
+
+	return engaged
+}
+
+// toHealthStatus returns the backpressure's current state for a HealthStatus.
+func (b *completerBackpressure) toHealthStatus() *HealthStatusCompleterBackpressure {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &HealthStatusCompleterBackpressure{
+		Backlog:    b.backlogFunc(),
+		Engaged:    b.engaged,
+		NumEngaged: b.numEngaged,
+	}
+}
+
+func (b *completerBackpressure) toCompleterBackpressure(backlog int) *CompleterBackpressure {
+	return &CompleterBackpressure{
+		Backlog:         backlog,
+		ResumeThreshold: b.config.ResumeThreshold,
+		Threshold:       b.config.Threshold,
+	}
+}
@@ -0,0 +1,175 @@
+package river
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestCompleterBackpressureConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&CompleterBackpressureConfig{}).validate())
+	require.NoError(t, (&CompleterBackpressureConfig{ResumeThreshold: 10, Threshold: 100}).validate())
+	require.NoError(t, (&CompleterBackpressureConfig{Threshold: 1}).validate())
+
+	require.EqualError(t, (&CompleterBackpressureConfig{ResumeThreshold: -1}).validate(), "CompleterBackpressure.ResumeThreshold cannot be less than zero")
+	require.EqualError(t, (&CompleterBackpressureConfig{Threshold: -1}).validate(), "CompleterBackpressure.Threshold cannot be less than zero")
+	require.EqualError(t, (&CompleterBackpressureConfig{ResumeThreshold: 100, Threshold: 100}).validate(), "CompleterBackpressure.ResumeThreshold must be less than Threshold")
+	require.EqualError(t, (&CompleterBackpressureConfig{ResumeThreshold: completerBackpressureThresholdDefault}).validate(), "CompleterBackpressure.ResumeThreshold must be less than Threshold")
+}
+
+func TestCompleterBackpressureConfig_withDefaults(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, &CompleterBackpressureConfig{
+		ResumeThreshold: completerBackpressureThresholdDefault / 2,
+		Threshold:       completerBackpressureThresholdDefault,
+	}, (&CompleterBackpressureConfig{}).withDefaults())
+
+	require.Equal(t, &CompleterBackpressureConfig{
+		ResumeThreshold: 50,
+		Threshold:       100,
+	}, (&CompleterBackpressureConfig{Threshold: 100}).withDefaults())
+
+	require.Equal(t, &CompleterBackpressureConfig{
+		ResumeThreshold: 10,
+		Threshold:       100,
+	}, (&CompleterBackpressureConfig{ResumeThreshold: 10, Threshold: 100}).withDefaults())
+}
+
+func TestCompleterBackpressure(t *testing.T) {
+	t.Parallel()
+
+	type testBundle struct {
+		backlog int
+		events  []*Event
+	}
+
+	setup := func(t *testing.T) (*completerBackpressure, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{}
+
+		backpressure := newCompleterBackpressure(riversharedtest.BaseServiceArchetype(t), &CompleterBackpressureConfig{ResumeThreshold: 50, Threshold: 100}, func() int { return bundle.backlog })
+		backpressure.eventCallback = func(event *Event) { bundle.events = append(bundle.events, event) }
+
+		return backpressure, bundle
+	}
+
+	t.Run("EngagesAndReleases", func(t *testing.T) {
+		t.Parallel()
+
+		backpressure, bundle := setup(t)
+
+		bundle.backlog = 100
+		require.False(t, backpressure.IsEngaged())
+		require.Empty(t, bundle.events)
+
+		bundle.backlog = 101
+		require.True(t, backpressure.IsEngaged())
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, EventKindCompleterBackpressureEngaged, bundle.events[0].Kind)
+		require.Equal(t, &CompleterBackpressure{Backlog: 101, ResumeThreshold: 50, Threshold: 100}, bundle.events[0].CompleterBackpressure)
+
+		// Not sent again while still engaged, and stays engaged until the
+		// backlog drains to the resume threshold.
+		require.True(t, backpressure.IsEngaged())
+		bundle.backlog = 51
+		require.True(t, backpressure.IsEngaged())
+		require.Len(t, bundle.events, 1)
+
+		bundle.backlog = 50
+		require.False(t, backpressure.IsEngaged())
+		require.Len(t, bundle.events, 2)
+		require.Equal(t, EventKindCompleterBackpressureReleased, bundle.events[1].Kind)
+		require.Equal(t, &CompleterBackpressure{Backlog: 50, ResumeThreshold: 50, Threshold: 100}, bundle.events[1].CompleterBackpressure)
+	})
+
+	t.Run("HealthStatus", func(t *testing.T) {
+		t.Parallel()
+
+		backpressure, bundle := setup(t)
+
+		bundle.backlog = 10
+		require.Equal(t, &HealthStatusCompleterBackpressure{Backlog: 10}, backpressure.toHealthStatus())
+
+		bundle.backlog = 101
+		backpressure.IsEngaged()
+		bundle.backlog = 0
+		backpressure.IsEngaged()
+		bundle.backlog = 101
+		backpressure.IsEngaged()
+
+		require.Equal(t, &HealthStatusCompleterBackpressure{Backlog: 101, Engaged: true, NumEngaged: 2}, backpressure.toHealthStatus())
+	})
+
+	t.Run("NilNotEngaged", func(t *testing.T) {
+		t.Parallel()
+
+		require.False(t, (*completerBackpressure)(nil).IsEngaged())
+	})
+}
+
+func Test_Client_CompleterBackpressure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	t.Run("StopsFetchingWhileEngaged", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.CompleterBackpressure = &CompleterBackpressureConfig{Threshold: 100}
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		client := newTestClient(t, riversharedtest.DBPool(ctx, t), config)
+
+		var backlog atomic.Int64
+		backlog.Store(101)
+		client.completerBackpressure.backlogFunc = func() int { return int(backlog.Load()) }
+
+		subscribeChan, cancel := client.Subscribe(EventKindCompleterBackpressureEngaged, EventKindCompleterBackpressureReleased, EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindCompleterBackpressureEngaged, event.Kind)
+		require.Equal(t, 101, event.CompleterBackpressure.Backlog)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		// Give the producer a few fetch polls in which it could've worked the
+		// job.
+		time.Sleep(3 * client.config.FetchPollInterval)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		status := client.Liveness(ctx)
+		require.Equal(t, &HealthStatusCompleterBackpressure{Backlog: 101, Engaged: true, NumEngaged: 1}, status.CompleterBackpressure)
+
+		backlog.Store(0)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindCompleterBackpressureReleased, event.Kind)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+}
@@ -0,0 +1,363 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// The maximum length of a control message name.
+const controlMessageNameMaxLength = 128
+
+// ControlHandlerFunc handles a custom control message sent with
+// ClientNotifyBundle.Control. Handlers are registered by message name in
+// Config.ControlHandlers.
+//
+// Each message is handled in its own goroutine so that a slow handler doesn't
+// hold up others. The context is cancelled when the client stops, and the
+// client waits for running handlers to return before its stop completes. An
+// error returned from a handler is logged.
+type ControlHandlerFunc func(ctx context.Context, msg *ControlMessage) error
+
+// ControlMessage is a custom control message received by a
+// ControlHandlerFunc.
+type ControlMessage struct {
+	// ClientID is the ID of the client the message was targeted to, or empty
+	// if it was broadcast to all clients.
+	ClientID string
+
+	// Name is the name of the message, which determines its handler.
+	Name string
+
+	// Payload is the JSON-encoded payload sent with the message. Empty if no
+	// payload was sent.
+	Payload json.RawMessage
+}
+
+// ControlParams are parameters for ClientNotifyBundle.Control.
+type ControlParams struct {
+	// ClientID optionally targets the message to the client with this ID.
+	// When empty, the message is broadcast to every client.
+	ClientID string
+
+	// Name is the name of the message, used to select a handler from
+	// Config.ControlHandlers on receiving clients. Required.
+	Name string
+
+	// Payload is an optional value that's encoded to JSON and sent with the
+	// message. Postgres limits notifications to 8000 bytes, so payloads should
+	// be kept small.
+	Payload any
+}
+
+// Control sends a custom control message to clients sharing the client's
+// database and schema, which may be used to issue fleet-wide commands like
+// flushing a cache or reloading configuration over infrastructure River
+// already maintains:
+//
+//	err := client.Notify().Control(ctx, &river.ControlParams{
+//		Name:    "flush_cache",
+//		Payload: map[string]string{"cache": "users"},
+//	})
+//
+// The message is broadcast to all clients unless targeted to one with
+// ControlParams.ClientID, and is handled by receiving clients that have a
+// handler for its name in Config.ControlHandlers. Clients without a handler
+// ignore it. Messages are delivered with Postgres listen/notify, so they're
+// only received by clients that are started and not in PollOnly mode, and
+// delivery isn't guaranteed.
+func (c *ClientNotifyBundle[TTx]) Control(ctx context.Context, params *ControlParams) error {
+	return dbutil.WithTx(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		return c.controlTx(ctx, execTx, params)
+	})
+}
+
+// ControlTx sends a custom control message in the same way as Control, but
+// within a transaction, which means that the message isn't sent until the
+// transaction commits.
+func (c *ClientNotifyBundle[TTx]) ControlTx(ctx context.Context, tx TTx, params *ControlParams) error {
+	return c.controlTx(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *ClientNotifyBundle[TTx]) controlTx(ctx context.Context, execTx riverdriver.ExecutorTx, params *ControlParams) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	if params.Name == "" {
+		return errors.New("control message name is required")
+	}
+	if len(params.Name) > controlMessageNameMaxLength {
+		return fmt.Errorf("control message name cannot be longer than %d characters", controlMessageNameMaxLength)
+	}
+
+	var payloadBytes json.RawMessage
+	if params.Payload != nil {
+		var err error
+		if payloadBytes, err = json.Marshal(params.Payload); err != nil {
+			return fmt.Errorf("error marshaling control message payload: %w", err)
+		}
+	}
+
+	payload, err := rivernotify.EncodeControl(&rivernotify.ControlPayload{
+		Action:   rivernotify.ControlActionCustom,
+		ClientID: params.ClientID,
+		Name:     params.Name,
+		Payload:  payloadBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return execTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: []string{payload},
+		Schema:  c.config.Schema,
+		Topic:   string(notifier.NotificationTopicControl),
+	})
+}
+
+// controlBus listens for control messages, dispatching custom ones to handlers
+// configured in Config.ControlHandlers, and both answering and sending requests
+// for the live status of running jobs.
+type controlBus struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	config    *controlBusConfig
+	exec      riverdriver.Executor
+	listening atomic.Bool
+	notifier  *notifier.Notifier
+	wg        sync.WaitGroup
+
+	jobStatusRequests   map[string]chan *rivernotify.ControlPayload
+	jobStatusRequestsMu sync.Mutex
+	jobStatusRequestSeq atomic.Int64
+}
+
+type controlBusConfig struct {
+	// ClientID is the ID of the client, used to recognize messages targeted to
+	// it.
+	ClientID string
+
+	// Handlers are custom control message handlers keyed by message name.
+	Handlers map[string]ControlHandlerFunc
+
+	// Host is the hostname reported in job status responses.
+	Host string
+
+	// JobStatus returns the live status of a job being worked by the client
+	// in the given queue, or nil if it's not being worked.
+	JobStatus func(queue string, jobID int64) *rivernotify.JobStatus
+
+	// Schema is the schema in which job status responses are sent.
+	Schema string
+}
+
+func newControlBus(archetype *baseservice.Archetype, exec riverdriver.Executor, notifier *notifier.Notifier, config *controlBusConfig) *controlBus {
+	return baseservice.Init(archetype, &controlBus{
+		config:            config,
+		exec:              exec,
+		jobStatusRequests: make(map[string]chan *rivernotify.ControlPayload),
+		notifier:          notifier,
+	})
+}
+
+func (b *controlBus) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := b.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	controlSub, err := b.notifier.Listen(ctx, notifier.NotificationTopicControl, b.handleControlNotification(ctx))
+	if err != nil {
+		stopped()
+		return err
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		b.Logger.DebugContext(ctx, b.Name+": Run loop started")
+		defer b.Logger.DebugContext(ctx, b.Name+": Run loop stopped")
+
+		b.listening.Store(true)
+
+		<-ctx.Done()
+
+		// Stop receiving messages before waiting on running handlers so that
+		// no new ones are started in the meantime.
+		b.listening.Store(false)
+		controlSub.Unlisten(ctx)
+		b.wg.Wait()
+	}()
+
+	return nil
+}
+
+func (b *controlBus) handleControlNotification(ctx context.Context) func(notifier.NotificationTopic, string) {
+	return func(topic notifier.NotificationTopic, payload string) {
+		decoded, err := rivernotify.DecodeControl(payload)
+		if err != nil {
+			b.Logger.ErrorContext(ctx, b.Name+": Failed to decode control notification payload", slog.String("err", err.Error()))
+			return
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if decoded.ClientID != "" && decoded.ClientID != b.config.ClientID {
+			return
+		}
+
+		switch decoded.Action {
+		case rivernotify.ControlActionCustom:
+			b.handleCustom(ctx, decoded)
+		case rivernotify.ControlActionJobStatusRequest:
+			b.handleJobStatusRequest(ctx, decoded)
+		case rivernotify.ControlActionJobStatusResponse:
+			b.handleJobStatusResponse(decoded)
+		}
+	}
+}
+
+func (b *controlBus) handleCustom(ctx context.Context, decoded *rivernotify.ControlPayload) {
+	handler, ok := b.config.Handlers[decoded.Name]
+	if !ok {
+		b.Logger.DebugContext(ctx, b.Name+": No handler for control message", slog.String("name", decoded.Name))
+		return
+	}
+
+	msg := &ControlMessage{
+		ClientID: decoded.ClientID,
+		Name:     decoded.Name,
+		Payload:  decoded.Payload,
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() {
+			if recovery := recover(); recovery != nil {
+				b.Logger.ErrorContext(ctx, b.Name+": Control handler panicked", slog.String("name", msg.Name), slog.Any("panic", recovery))
+			}
+		}()
+
+		if err := handler(ctx, msg); err != nil {
+			b.Logger.ErrorContext(ctx, b.Name+": Control handler returned error", slog.String("name", msg.Name), slog.String("err", err.Error()))
+		}
+	}()
+}
+
+func (b *controlBus) handleJobStatusRequest(ctx context.Context, decoded *rivernotify.ControlPayload) {
+	// Only answer requests targeted to this client specifically. A broadcast
+	// request would get a response from every client in the cluster.
+	if decoded.ClientID == "" || decoded.ReplyTo == "" {
+		return
+	}
+
+	response := &rivernotify.ControlPayload{
+		Action:    rivernotify.ControlActionJobStatusResponse,
+		ClientID:  decoded.ReplyTo,
+		JobID:     decoded.JobID,
+		Queue:     decoded.Queue,
+		RequestID: decoded.RequestID,
+	}
+	if b.config.JobStatus != nil {
+		if response.JobStatus = b.config.JobStatus(decoded.Queue, decoded.JobID); response.JobStatus != nil {
+			response.JobStatus.Host = b.config.Host
+		}
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		if err := b.notify(ctx, response); err != nil {
+			b.Logger.ErrorContext(ctx, b.Name+": Failed to send job status response", slog.Int64("job_id", decoded.JobID), slog.String("err", err.Error()))
+		}
+	}()
+}
+
+func (b *controlBus) handleJobStatusResponse(decoded *rivernotify.ControlPayload) {
+	b.jobStatusRequestsMu.Lock()
+	responseChan, ok := b.jobStatusRequests[decoded.RequestID]
+	b.jobStatusRequestsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	// Buffered with room for exactly one response. Drop duplicates.
+	select {
+	case responseChan <- decoded:
+	default:
+	}
+}
+
+func (b *controlBus) notify(ctx context.Context, payload *rivernotify.ControlPayload) error {
+	encoded, err := rivernotify.EncodeControl(payload)
+	if err != nil {
+		return err
+	}
+
+	return b.exec.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: []string{encoded},
+		Schema:  b.config.Schema,
+		Topic:   string(notifier.NotificationTopicControl),
+	})
+}
+
+// Asks the client clientID for the live status of job, which it's expected to
+// be working, and waits for its response until ctx is done. Returns nil if the
+// responding client isn't working the job.
+func (b *controlBus) requestJobStatus(ctx context.Context, clientID string, job *rivertype.JobRow) (*rivernotify.JobStatus, error) {
+	if !b.listening.Load() {
+		return nil, errors.New("client must be started to request live job status")
+	}
+
+	var (
+		requestID    = b.config.ClientID + "_" + strconv.FormatInt(b.jobStatusRequestSeq.Add(1), 10)
+		responseChan = make(chan *rivernotify.ControlPayload, 1)
+	)
+
+	b.jobStatusRequestsMu.Lock()
+	b.jobStatusRequests[requestID] = responseChan
+	b.jobStatusRequestsMu.Unlock()
+
+	defer func() {
+		b.jobStatusRequestsMu.Lock()
+		delete(b.jobStatusRequests, requestID)
+		b.jobStatusRequestsMu.Unlock()
+	}()
+
+	if err := b.notify(ctx, &rivernotify.ControlPayload{
+		Action:    rivernotify.ControlActionJobStatusRequest,
+		ClientID:  clientID,
+		JobID:     job.ID,
+		Queue:     job.Queue,
+		ReplyTo:   b.config.ClientID,
+		RequestID: requestID,
+	}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no live status response from client %q: %w", clientID, context.Cause(ctx))
+	case response := <-responseChan:
+		return response.JobStatus, nil
+	}
+}
@@ -0,0 +1,218 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func Test_ClientNotifyBundle_Control(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		schema string
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+
+		return &testBundle{
+			dbPool: dbPool,
+			schema: schema,
+		}
+	}
+
+	// Starts a client with a handler for the "test_message" control message
+	// that sends received messages to the returned channel.
+	startReceivingClient := func(t *testing.T, bundle *testBundle, clientID string) (*Client[pgx.Tx], chan *ControlMessage) {
+		t.Helper()
+
+		msgChan := make(chan *ControlMessage, 10)
+
+		config := newTestConfig(t, bundle.schema)
+		config.ControlHandlers = map[string]ControlHandlerFunc{
+			"test_message": func(ctx context.Context, msg *ControlMessage) error {
+				msgChan <- msg
+				return nil
+			},
+		}
+		config.ID = clientID
+
+		client := newTestClient(t, bundle.dbPool, config)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		return client, msgChan
+	}
+
+	requireNoMessage := func(t *testing.T, msgChan chan *ControlMessage) {
+		t.Helper()
+
+		select {
+		case msg := <-msgChan:
+			require.FailNow(t, "unexpected control message", "%+v", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	t.Run("Broadcast", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		client1, msgChan1 := startReceivingClient(t, bundle, "client1")
+		_, msgChan2 := startReceivingClient(t, bundle, "client2")
+
+		require.NoError(t, client1.Notify().Control(ctx, &ControlParams{
+			Name:    "test_message",
+			Payload: map[string]string{"cache": "users"},
+		}))
+
+		for _, msgChan := range []chan *ControlMessage{msgChan1, msgChan2} {
+			msg := riversharedtest.WaitOrTimeout(t, msgChan)
+			require.Empty(t, msg.ClientID)
+			require.Equal(t, "test_message", msg.Name)
+			require.JSONEq(t, `{"cache":"users"}`, string(msg.Payload))
+		}
+	})
+
+	t.Run("Targeted", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		client1, msgChan1 := startReceivingClient(t, bundle, "client1")
+		_, msgChan2 := startReceivingClient(t, bundle, "client2")
+
+		require.NoError(t, client1.Notify().Control(ctx, &ControlParams{
+			ClientID: "client2",
+			Name:     "test_message",
+		}))
+
+		msg := riversharedtest.WaitOrTimeout(t, msgChan2)
+		require.Equal(t, "client2", msg.ClientID)
+		require.Equal(t, "test_message", msg.Name)
+		require.Empty(t, msg.Payload)
+
+		requireNoMessage(t, msgChan1)
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		client, msgChan := startReceivingClient(t, bundle, "client1")
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		require.NoError(t, client.Notify().ControlTx(ctx, tx, &ControlParams{Name: "test_message"}))
+
+		// Not sent until the transaction commits.
+		requireNoMessage(t, msgChan)
+
+		require.NoError(t, tx.Commit(ctx))
+
+		msg := riversharedtest.WaitOrTimeout(t, msgChan)
+		require.Equal(t, "test_message", msg.Name)
+	})
+
+	t.Run("UnhandledNameIgnored", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		client, msgChan := startReceivingClient(t, bundle, "client1")
+
+		require.NoError(t, client.Notify().Control(ctx, &ControlParams{Name: "other_message"}))
+		require.NoError(t, client.Notify().Control(ctx, &ControlParams{Name: "test_message"}))
+
+		msg := riversharedtest.WaitOrTimeout(t, msgChan)
+		require.Equal(t, "test_message", msg.Name)
+	})
+
+	t.Run("HandlerErrorAndPanicDoNotStopBus", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		msgChan := make(chan *ControlMessage, 10)
+
+		config := newTestConfig(t, bundle.schema)
+		config.ControlHandlers = map[string]ControlHandlerFunc{
+			"error_message": func(ctx context.Context, msg *ControlMessage) error {
+				return errors.New("handler error")
+			},
+			"panic_message": func(ctx context.Context, msg *ControlMessage) error {
+				panic("handler panic")
+			},
+			"test_message": func(ctx context.Context, msg *ControlMessage) error {
+				msgChan <- msg
+				return nil
+			},
+		}
+
+		client := newTestClient(t, bundle.dbPool, config)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		require.NoError(t, client.Notify().Control(ctx, &ControlParams{Name: "error_message"}))
+		require.NoError(t, client.Notify().Control(ctx, &ControlParams{Name: "panic_message"}))
+		require.NoError(t, client.Notify().Control(ctx, &ControlParams{Name: "test_message"}))
+
+		msg := riversharedtest.WaitOrTimeout(t, msgChan)
+		require.Equal(t, "test_message", msg.Name)
+	})
+
+	t.Run("InvalidParams", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		client := newTestClient(t, bundle.dbPool, newTestConfig(t, bundle.schema))
+
+		require.EqualError(t, client.Notify().Control(ctx, &ControlParams{}), "control message name is required")
+		require.EqualError(t, client.Notify().Control(ctx, &ControlParams{Name: strings.Repeat("x", 129)}), "control message name cannot be longer than 128 characters")
+
+		err := client.Notify().Control(ctx, &ControlParams{Name: "test_message", Payload: make(chan int)})
+		var unsupportedTypeErr *json.UnsupportedTypeError
+		require.ErrorAs(t, err, &unsupportedTypeErr)
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		config := newTestConfig(t, bundle.schema)
+		config.Queues = nil
+		config.ReadOnly = true
+		config.Workers = nil
+
+		client := newTestClient(t, bundle.dbPool, config)
+
+		require.ErrorIs(t, client.Notify().Control(ctx, &ControlParams{Name: "test_message"}), ErrClientReadOnly)
+	})
+}
@@ -0,0 +1,180 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+)
+
+// DatabaseCircuitBreaker configures a circuit breaker that puts a client into a
+// degraded mode during a database outage. See Config.DatabaseCircuitBreaker.
+type DatabaseCircuitBreaker struct {
+	// FailureThreshold is the number of consecutive database operations that
+	// must fail because of pool exhaustion or connection failures before the
+	// circuit opens. Failures are counted across the fetch, completer, and
+	// notifier subsystems, and any successful operation resets the count.
+	//
+	// Defaults to 5.
+	FailureThreshold int
+
+	// ProbeInterval is how often the database is probed while the circuit is
+	// open. The circuit closes after the first successful probe.
+	//
+	// Defaults to 5 seconds.
+	ProbeInterval time.Duration
+}
+
+const (
+	databaseCircuitBreakerFailureThresholdDefault = 5
+	databaseCircuitBreakerProbeIntervalDefault    = 5 * time.Second
+)
+
+func (b *DatabaseCircuitBreaker) withDefaults() *DatabaseCircuitBreaker {
+	return &DatabaseCircuitBreaker{
+		FailureThreshold: cmp.Or(b.FailureThreshold, databaseCircuitBreakerFailureThresholdDefault),
+		ProbeInterval:    cmp.Or(b.ProbeInterval, databaseCircuitBreakerProbeIntervalDefault),
+	}
+}
+
+// Test-only properties.
+type databaseCircuitBreakerTestSignals struct {
+	Closed testsignal.TestSignal[struct{}] // circuit closed after a successful probe
+	Opened testsignal.TestSignal[struct{}] // circuit opened after reaching the failure threshold
+}
+
+func (ts *databaseCircuitBreakerTestSignals) Init(tb testutil.TestingTB) {
+	ts.Closed.Init(tb)
+	ts.Opened.Init(tb)
+}
+
+// databaseCircuitBreaker opens after sustained database failures reported by a
+// databaseDegradationTracker. While it's open, producers stop fetching jobs,
+// the completer holds completions in its backlog, and maintenance is skipped,
+// so that an outage doesn't produce a storm of failing operations. It probes
+// the database periodically and closes once the database is reachable again.
+type databaseCircuitBreaker struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	config        *DatabaseCircuitBreaker
+	eventCallback func(event *Event)
+	exec          riverdriver.Executor
+	testSignals   databaseCircuitBreakerTestSignals
+
+	mu                     sync.RWMutex
+	isOpen                 bool
+	numConsecutiveFailures int
+}
+
+func newDatabaseCircuitBreaker(archetype *baseservice.Archetype, config *DatabaseCircuitBreaker, exec riverdriver.Executor) *databaseCircuitBreaker {
+	return baseservice.Init(archetype, &databaseCircuitBreaker{
+		config: config.withDefaults(),
+		exec:   exec,
+	})
+}
+
+func (b *databaseCircuitBreaker) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := b.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	go func() {
+		started()
+		defer stopped()
+
+		b.Logger.DebugContext(ctx, b.Name+": Run loop started")
+		defer b.Logger.DebugContext(ctx, b.Name+": Run loop stopped")
+
+		ticker := time.NewTicker(b.config.ProbeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if b.IsOpen() {
+				b.probe(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// IsOpen returns true if the circuit is open, meaning that the database is
+// considered to be unavailable.
+func (b *databaseCircuitBreaker) IsOpen() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.isOpen
+}
+
+func (b *databaseCircuitBreaker) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, b.config.ProbeInterval)
+	defer cancel()
+
+	if err := b.exec.Ping(ctx); err != nil {
+		b.Logger.DebugContext(ctx, b.Name+": Database probe failed; circuit remains open", slog.String("err", err.Error()))
+		return
+	}
+
+	b.mu.Lock()
+	b.isOpen = false
+	b.numConsecutiveFailures = 0
+	b.mu.Unlock()
+
+	b.Logger.InfoContext(ctx, b.Name+": Database probe succeeded; circuit closed")
+	b.testSignals.Closed.Signal(struct{}{})
+
+	if b.eventCallback != nil {
+		b.eventCallback(&Event{Kind: EventKindDatabaseCircuitClosed})
+	}
+}
+
+func (b *databaseCircuitBreaker) recordFailure(ctx context.Context, degradation *DatabaseDegradation) {
+	b.mu.Lock()
+	if b.isOpen {
+		b.mu.Unlock()
+		return
+	}
+	b.numConsecutiveFailures++
+	if b.numConsecutiveFailures < b.config.FailureThreshold {
+		b.mu.Unlock()
+		return
+	}
+	b.isOpen = true
+	b.numConsecutiveFailures = 0
+	b.mu.Unlock()
+
+	b.Logger.WarnContext(ctx, b.Name+": Database failures reached threshold; circuit opened",
+		slog.String("cause", string(degradation.Cause)),
+		slog.Int("failure_threshold", b.config.FailureThreshold),
+		slog.String("probe_interval", b.config.ProbeInterval.String()),
+		slog.String("subsystem", string(degradation.Subsystem)),
+	)
+	b.testSignals.Opened.Signal(struct{}{})
+
+	if b.eventCallback != nil {
+		b.eventCallback(&Event{Kind: EventKindDatabaseCircuitOpened, DatabaseDegradation: degradation})
+	}
+}
+
+func (b *databaseCircuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.numConsecutiveFailures = 0
+}
@@ -0,0 +1,193 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+// Executor whose Ping fails while failing is true. Other operations panic on
+// the nil embedded executor.
+type probeExecutor struct {
+	riverdriver.Executor
+
+	failing atomic.Bool
+}
+
+func (e *probeExecutor) Ping(ctx context.Context) error {
+	if e.failing.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestDatabaseCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	type testBundle struct {
+		breaker *databaseCircuitBreaker
+		eventCh chan *Event
+		exec    *probeExecutor
+	}
+
+	setup := func(t *testing.T, config *DatabaseCircuitBreaker) (*databaseDegradationTracker, *testBundle) {
+		t.Helper()
+
+		var (
+			archetype = riversharedtest.BaseServiceArchetype(t)
+			eventCh   = make(chan *Event, 10)
+			exec      = &probeExecutor{}
+			breaker   = newDatabaseCircuitBreaker(archetype, config, exec)
+			tracker   = newDatabaseDegradationTracker(archetype, func() *riverdriver.PoolStats { return nil })
+		)
+		exec.failing.Store(true)
+		breaker.eventCallback = func(event *Event) { eventCh <- event }
+		breaker.testSignals.Init(t)
+		tracker.breaker = breaker
+
+		return tracker, &testBundle{
+			breaker: breaker,
+			eventCh: eventCh,
+			exec:    exec,
+		}
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t, &DatabaseCircuitBreaker{})
+		require.Equal(t, databaseCircuitBreakerFailureThresholdDefault, bundle.breaker.config.FailureThreshold)
+		require.Equal(t, databaseCircuitBreakerProbeIntervalDefault, bundle.breaker.config.ProbeInterval)
+	})
+
+	t.Run("OpensAtFailureThreshold", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t, &DatabaseCircuitBreaker{FailureThreshold: 3})
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		tracker.Record(ctx, DatabaseSubsystemCompleter, connErr)
+		require.False(t, tracker.IsCircuitOpen())
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		require.True(t, tracker.IsCircuitOpen())
+		bundle.breaker.testSignals.Opened.WaitOrTimeout()
+
+		event := riversharedtest.WaitOrTimeout(t, bundle.eventCh)
+		require.Equal(t, EventKindDatabaseCircuitOpened, event.Kind)
+		require.Equal(t, DatabaseSubsystemFetch, event.DatabaseDegradation.Subsystem)
+
+		// Further failures don't produce more events.
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		require.Empty(t, bundle.eventCh)
+	})
+
+	t.Run("SuccessResetsFailureCount", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, _ := setup(t, &DatabaseCircuitBreaker{FailureThreshold: 2})
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		tracker.Record(ctx, DatabaseSubsystemCompleter, nil)
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		require.False(t, tracker.IsCircuitOpen())
+	})
+
+	t.Run("OtherErrorsNotCounted", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, _ := setup(t, &DatabaseCircuitBreaker{FailureThreshold: 1})
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, errors.New("syntax error"))
+		require.False(t, tracker.IsCircuitOpen())
+	})
+
+	t.Run("ClosesAfterSuccessfulProbe", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t, &DatabaseCircuitBreaker{FailureThreshold: 1, ProbeInterval: 10 * time.Millisecond})
+
+		require.NoError(t, bundle.breaker.Start(ctx))
+		t.Cleanup(bundle.breaker.Stop)
+
+		tracker.Record(ctx, DatabaseSubsystemNotifier, connErr)
+		require.True(t, tracker.IsCircuitOpen())
+		require.Equal(t, EventKindDatabaseCircuitOpened, riversharedtest.WaitOrTimeout(t, bundle.eventCh).Kind)
+
+		// Failed probes leave the circuit open.
+		time.Sleep(50 * time.Millisecond)
+		require.True(t, tracker.IsCircuitOpen())
+
+		bundle.exec.failing.Store(false)
+		bundle.breaker.testSignals.Closed.WaitOrTimeout()
+		require.False(t, tracker.IsCircuitOpen())
+		require.Equal(t, EventKindDatabaseCircuitClosed, riversharedtest.WaitOrTimeout(t, bundle.eventCh).Kind)
+	})
+
+	t.Run("MaintenanceShedWhileOpen", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, _ := setup(t, &DatabaseCircuitBreaker{FailureThreshold: 1})
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+
+		// Shedding while degraded is disabled, but the open circuit sheds
+		// maintenance regardless. The underlying executor doesn't implement
+		// JobDeleteBefore, so this would panic if it weren't skipped.
+		executor := &operationExecutor{Executor: &erroringExecutor{}, config: &operationDriverConfig{DegradationTracker: tracker}}
+		numDeleted, err := executor.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{})
+		require.NoError(t, err)
+		require.Zero(t, numDeleted)
+	})
+}
+
+func Test_Client_DatabaseCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbPool := riversharedtest.DBPool(ctx, t)
+	config := newTestConfig(t, riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil))
+	config.DatabaseCircuitBreaker = &DatabaseCircuitBreaker{FailureThreshold: 1, ProbeInterval: 500 * time.Millisecond}
+
+	client := newTestClient(t, dbPool, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindDatabaseCircuitClosed, EventKindDatabaseCircuitOpened, EventKindJobCompleted)
+	t.Cleanup(cancel)
+
+	startClient(ctx, t, client)
+
+	// Simulate an outage that's already over by the time the breaker probes.
+	client.databaseDegradation.Record(ctx, DatabaseSubsystemFetch, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+
+	require.True(t, client.Liveness(ctx).DatabaseCircuitOpen)
+
+	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, EventKindDatabaseCircuitOpened, event.Kind)
+
+	event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, EventKindDatabaseCircuitClosed, event.Kind)
+	require.False(t, client.Liveness(ctx).DatabaseCircuitOpen)
+
+	// Jobs are worked normally once the circuit is closed.
+	insertRes, err := client.Insert(ctx, &noOpArgs{}, nil)
+	require.NoError(t, err)
+
+	event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, EventKindJobCompleted, event.Kind)
+	require.Equal(t, insertRes.Job.ID, event.Job.ID)
+}
@@ -0,0 +1,288 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+// DatabaseSubsystem is a part of a client that depends on the database, used
+// to identify which one was starved in a DatabaseDegradation.
+type DatabaseSubsystem string
+
+const (
+	// DatabaseSubsystemCompleter is the subsystem that sets the final states of
+	// worked jobs.
+	DatabaseSubsystemCompleter DatabaseSubsystem = "completer"
+
+	// DatabaseSubsystemFetch is the subsystem that locks and fetches jobs to be
+	// worked.
+	DatabaseSubsystemFetch DatabaseSubsystem = "fetch"
+
+	// DatabaseSubsystemNotifier is the subsystem that holds a connection to
+	// listen for notifications, like new jobs being inserted.
+	DatabaseSubsystemNotifier DatabaseSubsystem = "notifier"
+)
+
+// DatabaseDegradationCause is the reason that a subsystem's database operation
+// failed in a DatabaseDegradation.
+type DatabaseDegradationCause string
+
+const (
+	// DatabaseDegradationCauseConnectionFailed indicates that a connection to
+	// the database couldn't be established or was lost.
+	DatabaseDegradationCauseConnectionFailed DatabaseDegradationCause = "connection_failed"
+
+	// DatabaseDegradationCausePoolExhausted indicates that every connection in
+	// the database pool was checked out so that operations had to wait for
+	// one. It's reported when an operation times out while the pool is
+	// exhausted, which usually means that it was waiting for a connection
+	// rather than on the database itself, or when waits for a connection
+	// during an operation averaged longer than one second, even if the
+	// operation eventually succeeded.
+	DatabaseDegradationCausePoolExhausted DatabaseDegradationCause = "pool_exhausted"
+)
+
+// The average time that connection acquisitions must wait during an operation
+// for its subsystem to be considered degraded by pool exhaustion.
+const databaseDegradationPoolWaitThreshold = 1 * time.Second
+
+// DatabaseDegradation contains information about a subsystem whose database
+// operations are failing because of pool exhaustion or connection failures.
+// It's sent with EventKindDatabaseDegraded and EventKindDatabaseRecovered
+// events.
+type DatabaseDegradation struct {
+	// Cause is the reason that the subsystem's operation failed.
+	Cause DatabaseDegradationCause
+
+	// Err is the error returned by the failed operation, or nil if the
+	// operation succeeded, but waited too long for a connection from an
+	// exhausted pool.
+	Err error
+
+	// PoolNumAcquired is the number of connections checked out of the
+	// database pool when the operation failed.
+	PoolNumAcquired int
+
+	// PoolNumMax is the maximum number of connections that the database pool
+	// may hold, or zero if it's unbounded or its driver doesn't report it.
+	PoolNumMax int
+
+	// Subsystem is the part of the client whose operation failed.
+	Subsystem DatabaseSubsystem
+}
+
+// Classifies an error from a database operation, returning a cause and true if
+// it indicates pool exhaustion or a connection failure.
+//
+// Pools don't distinguish a timeout waiting for a connection from a timeout of
+// the statement itself, so a deadline that's exceeded while the pool is
+// exhausted is assumed to be the former.
+func databaseDegradationCauseOf(err error, poolStats *riverdriver.PoolStats) (DatabaseDegradationCause, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && poolStats != nil && poolStats.Exhausted():
+		return DatabaseDegradationCausePoolExhausted, true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return DatabaseDegradationCauseConnectionFailed, true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return DatabaseDegradationCauseConnectionFailed, true
+	}
+
+	return "", false
+}
+
+// Returns true if connection acquisitions that waited on an exhausted pool
+// between two pool stats snapshots waited on average at least threshold.
+func databaseDegradationPoolWaitExceeded(poolStatsBefore, poolStatsAfter *riverdriver.PoolStats, threshold time.Duration) bool {
+	if poolStatsBefore == nil || poolStatsAfter == nil {
+		return false
+	}
+
+	numWaits := poolStatsAfter.NumWaits - poolStatsBefore.NumWaits
+	if numWaits < 1 {
+		return false
+	}
+
+	return (poolStatsAfter.WaitDuration-poolStatsBefore.WaitDuration)/time.Duration(numWaits) >= threshold
+}
+
+// databaseDegradationTracker tracks which subsystems are degraded by recording
+// the outcome of their database operations, emitting EventKindDatabaseDegraded
+// when one starts failing and EventKindDatabaseRecovered when it succeeds
+// again.
+type databaseDegradationTracker struct {
+	baseservice.BaseService
+
+	// breaker is fed every failure and success so it can open on sustained
+	// failures. Nil unless Config.DatabaseCircuitBreaker is set.
+	breaker *databaseCircuitBreaker
+
+	// eventCallback receives events on degradation and recovery. Set after
+	// construction because the subscription manager is created after the
+	// driver that's wrapped to feed the tracker.
+	eventCallback func(event *Event)
+	poolStatsFunc func() *riverdriver.PoolStats
+
+	mu       sync.RWMutex
+	degraded map[DatabaseSubsystem]*DatabaseDegradation
+}
+
+func newDatabaseDegradationTracker(archetype *baseservice.Archetype, poolStatsFunc func() *riverdriver.PoolStats) *databaseDegradationTracker {
+	return baseservice.Init(archetype, &databaseDegradationTracker{
+		poolStatsFunc: poolStatsFunc,
+		degraded:      make(map[DatabaseSubsystem]*DatabaseDegradation),
+	})
+}
+
+// IsCircuitOpen returns true if a circuit breaker is configured and open. Safe
+// to call on a nil tracker for clients that don't track degradation.
+func (t *databaseDegradationTracker) IsCircuitOpen() bool {
+	return t != nil && t.breaker != nil && t.breaker.IsOpen()
+}
+
+// IsDegraded returns true if any subsystem is degraded.
+func (t *databaseDegradationTracker) IsDegraded() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.degraded) > 0
+}
+
+// Record records the outcome of a subsystem's database operation. Errors that
+// don't indicate pool exhaustion or a connection failure are ignored.
+func (t *databaseDegradationTracker) Record(ctx context.Context, subsystem DatabaseSubsystem, err error) {
+	t.RecordSince(ctx, subsystem, err, nil)
+}
+
+// RecordSince is like Record, but also takes pool stats from before the
+// operation started. Pools block operations waiting for a connection until
+// their context is done, so without a deadline an exhausted pool only makes
+// operations slow rather than failing them. If acquisitions waited too long
+// for a connection while the operation ran, the subsystem is considered
+// degraded by pool exhaustion even if the operation succeeded.
+func (t *databaseDegradationTracker) RecordSince(ctx context.Context, subsystem DatabaseSubsystem, err error, poolStatsBefore *riverdriver.PoolStats) {
+	poolStats := t.poolStatsFunc()
+
+	cause, ok := databaseDegradationCauseOf(err, poolStats)
+	if !ok && databaseDegradationPoolWaitExceeded(poolStatsBefore, poolStats, databaseDegradationPoolWaitThreshold) {
+		cause, ok = DatabaseDegradationCausePoolExhausted, true
+	}
+	if !ok {
+		if err == nil {
+			if t.breaker != nil {
+				t.breaker.recordSuccess()
+			}
+			t.recordSuccess(ctx, subsystem)
+		}
+		return
+	}
+
+	degradation := &DatabaseDegradation{
+		Cause:     cause,
+		Err:       err,
+		Subsystem: subsystem,
+	}
+	if poolStats != nil {
+		degradation.PoolNumAcquired = poolStats.NumAcquired
+		degradation.PoolNumMax = poolStats.NumMax
+	}
+
+	t.mu.Lock()
+	_, alreadyDegraded := t.degraded[subsystem]
+	t.degraded[subsystem] = degradation
+	t.mu.Unlock()
+
+	// Slow operations still reach the database, so only failures count
+	// towards opening the circuit.
+	if t.breaker != nil && err != nil {
+		t.breaker.recordFailure(ctx, degradation)
+	}
+
+	// Only transitions are reported so that a subsystem failing over and over
+	// doesn't flood subscribers.
+	if alreadyDegraded {
+		return
+	}
+
+	var errMessage string
+	if err != nil {
+		errMessage = err.Error()
+	}
+
+	t.Logger.WarnContext(ctx, t.Name+": Database subsystem degraded",
+		slog.String("cause", string(cause)),
+		slog.String("err", errMessage),
+		slog.Int("pool_num_acquired", degradation.PoolNumAcquired),
+		slog.Int("pool_num_max", degradation.PoolNumMax),
+		slog.String("subsystem", string(subsystem)),
+	)
+
+	if t.eventCallback != nil {
+		t.eventCallback(&Event{Kind: EventKindDatabaseDegraded, DatabaseDegradation: degradation})
+	}
+}
+
+func (t *databaseDegradationTracker) recordSuccess(ctx context.Context, subsystem DatabaseSubsystem) {
+	// Fast path for the common case of nothing being degraded.
+	t.mu.RLock()
+	_, degraded := t.degraded[subsystem]
+	t.mu.RUnlock()
+	if !degraded {
+		return
+	}
+
+	t.mu.Lock()
+	degradation, degraded := t.degraded[subsystem]
+	delete(t.degraded, subsystem)
+	t.mu.Unlock()
+	if !degraded {
+		return
+	}
+
+	t.Logger.InfoContext(ctx, t.Name+": Database subsystem recovered",
+		slog.String("cause", string(degradation.Cause)),
+		slog.String("subsystem", string(subsystem)),
+	)
+
+	if t.eventCallback != nil {
+		t.eventCallback(&Event{Kind: EventKindDatabaseRecovered, DatabaseDegradation: degradation})
+	}
+}
+
+// degradationListener wraps a listener to record the outcome of its connection
+// attempts and waits with a databaseDegradationTracker.
+type degradationListener struct {
+	riverdriver.Listener
+
+	tracker *databaseDegradationTracker
+}
+
+func (l *degradationListener) Connect(ctx context.Context) error {
+	err := l.Listener.Connect(ctx)
+	if !errors.Is(err, context.Canceled) {
+		l.tracker.Record(ctx, DatabaseSubsystemNotifier, err)
+	}
+	return err
+}
+
+func (l *degradationListener) WaitForNotification(ctx context.Context) (*riverdriver.Notification, error) {
+	notification, err := l.Listener.WaitForNotification(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		l.tracker.Record(ctx, DatabaseSubsystemNotifier, err)
+	}
+	return notification, err
+}
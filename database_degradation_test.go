@@ -0,0 +1,385 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+// Listener whose Connect and WaitForNotification return connectErr and
+// waitErr. Other operations panic on the nil embedded listener.
+type erroringListener struct {
+	riverdriver.Listener
+
+	connectErr error
+	waitErr    error
+}
+
+func (l *erroringListener) Connect(ctx context.Context) error { return l.connectErr }
+
+func (l *erroringListener) WaitForNotification(ctx context.Context) (*riverdriver.Notification, error) {
+	return nil, l.waitErr
+}
+
+func TestDatabaseDegradationCauseOf(t *testing.T) {
+	t.Parallel()
+
+	var (
+		connRefusedErr = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		exhausted      = &riverdriver.PoolStats{NumAcquired: 10, NumMax: 10}
+		notExhausted   = &riverdriver.PoolStats{NumAcquired: 9, NumMax: 10}
+	)
+
+	for _, tt := range []struct {
+		name      string
+		err       error
+		poolStats *riverdriver.PoolStats
+		wantCause DatabaseDegradationCause
+		wantOK    bool
+	}{
+		{name: "DeadlineExceededPoolExhausted", err: fmt.Errorf("error fetching: %w", context.DeadlineExceeded), poolStats: exhausted, wantCause: DatabaseDegradationCausePoolExhausted, wantOK: true},
+		{name: "DeadlineExceededPoolNotExhausted", err: context.DeadlineExceeded, poolStats: notExhausted},
+		{name: "DeadlineExceededPoolUnbounded", err: context.DeadlineExceeded, poolStats: &riverdriver.PoolStats{NumAcquired: 10}},
+		{name: "DeadlineExceededNoPoolStats", err: context.DeadlineExceeded},
+		{name: "NetOpError", err: fmt.Errorf("failed to connect: %w", connRefusedErr), poolStats: notExhausted, wantCause: DatabaseDegradationCauseConnectionFailed, wantOK: true},
+		{name: "UnexpectedEOF", err: io.ErrUnexpectedEOF, wantCause: DatabaseDegradationCauseConnectionFailed, wantOK: true},
+		{name: "OtherError", err: errors.New("syntax error"), poolStats: exhausted},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cause, ok := databaseDegradationCauseOf(tt.err, tt.poolStats)
+			require.Equal(t, tt.wantCause, cause)
+			require.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestDatabaseDegradationPoolWaitExceeded(t *testing.T) {
+	t.Parallel()
+
+	before := &riverdriver.PoolStats{NumWaits: 10, WaitDuration: 5 * time.Second}
+
+	for _, tt := range []struct {
+		name   string
+		after  *riverdriver.PoolStats
+		before *riverdriver.PoolStats
+		want   bool
+	}{
+		{name: "AverageWaitOverThreshold", before: before, after: &riverdriver.PoolStats{NumWaits: 12, WaitDuration: 9 * time.Second}, want: true},
+		{name: "AverageWaitUnderThreshold", before: before, after: &riverdriver.PoolStats{NumWaits: 12, WaitDuration: 6 * time.Second}},
+		{name: "NoNewWaits", before: before, after: before},
+		{name: "NoStatsBefore", after: before},
+		{name: "NoStatsAfter", before: before},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, databaseDegradationPoolWaitExceeded(tt.before, tt.after, time.Second))
+		})
+	}
+}
+
+func TestDatabaseDegradationTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	type testBundle struct {
+		events    []*Event
+		poolStats *riverdriver.PoolStats
+	}
+
+	setup := func(t *testing.T) (*databaseDegradationTracker, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{
+			poolStats: &riverdriver.PoolStats{NumAcquired: 2, NumMax: 10},
+		}
+
+		tracker := newDatabaseDegradationTracker(riversharedtest.BaseServiceArchetype(t), func() *riverdriver.PoolStats { return bundle.poolStats })
+		tracker.eventCallback = func(event *Event) { bundle.events = append(bundle.events, event) }
+
+		return tracker, bundle
+	}
+
+	t.Run("DegradedAndRecovered", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		require.True(t, tracker.IsDegraded())
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, EventKindDatabaseDegraded, bundle.events[0].Kind)
+		require.Equal(t, &DatabaseDegradation{
+			Cause:           DatabaseDegradationCauseConnectionFailed,
+			Err:             connErr,
+			PoolNumAcquired: 2,
+			PoolNumMax:      10,
+			Subsystem:       DatabaseSubsystemFetch,
+		}, bundle.events[0].DatabaseDegradation)
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, nil)
+		require.False(t, tracker.IsDegraded())
+		require.Len(t, bundle.events, 2)
+		require.Equal(t, EventKindDatabaseRecovered, bundle.events[1].Kind)
+		require.Equal(t, DatabaseSubsystemFetch, bundle.events[1].DatabaseDegradation.Subsystem)
+	})
+
+	t.Run("OnlyTransitionsReported", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		tracker.Record(ctx, DatabaseSubsystemCompleter, nil)
+		require.Empty(t, bundle.events)
+
+		tracker.Record(ctx, DatabaseSubsystemCompleter, connErr)
+		tracker.Record(ctx, DatabaseSubsystemCompleter, connErr)
+		require.Len(t, bundle.events, 1)
+
+		tracker.Record(ctx, DatabaseSubsystemCompleter, nil)
+		tracker.Record(ctx, DatabaseSubsystemCompleter, nil)
+		require.Len(t, bundle.events, 2)
+	})
+
+	t.Run("PoolExhausted", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+		bundle.poolStats = &riverdriver.PoolStats{NumAcquired: 10, NumMax: 10}
+
+		tracker.Record(ctx, DatabaseSubsystemNotifier, context.DeadlineExceeded)
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, DatabaseDegradationCausePoolExhausted, bundle.events[0].DatabaseDegradation.Cause)
+		require.Equal(t, DatabaseSubsystemNotifier, bundle.events[0].DatabaseDegradation.Subsystem)
+	})
+
+	t.Run("PoolWaitExceeded", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		poolStatsBefore := &riverdriver.PoolStats{NumAcquired: 10, NumMax: 10}
+		bundle.poolStats = &riverdriver.PoolStats{NumAcquired: 10, NumMax: 10, NumWaits: 1, WaitDuration: 2 * time.Second}
+
+		tracker.RecordSince(ctx, DatabaseSubsystemFetch, nil, poolStatsBefore)
+		require.True(t, tracker.IsDegraded())
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, &DatabaseDegradation{
+			Cause:           DatabaseDegradationCausePoolExhausted,
+			PoolNumAcquired: 10,
+			PoolNumMax:      10,
+			Subsystem:       DatabaseSubsystemFetch,
+		}, bundle.events[0].DatabaseDegradation)
+
+		// An operation that didn't wait on the pool recovers the subsystem.
+		tracker.RecordSince(ctx, DatabaseSubsystemFetch, nil, bundle.poolStats)
+		require.False(t, tracker.IsDegraded())
+		require.Len(t, bundle.events, 2)
+		require.Equal(t, EventKindDatabaseRecovered, bundle.events[1].Kind)
+	})
+
+	t.Run("NilTracker", func(t *testing.T) {
+		t.Parallel()
+
+		var tracker *databaseDegradationTracker
+		require.False(t, tracker.IsCircuitOpen())
+		require.False(t, tracker.IsDegraded())
+	})
+
+	t.Run("SubsystemsTrackedIndependently", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		tracker.Record(ctx, DatabaseSubsystemCompleter, connErr)
+		tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+		require.Len(t, bundle.events, 2)
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, nil)
+		require.True(t, tracker.IsDegraded())
+
+		tracker.Record(ctx, DatabaseSubsystemCompleter, nil)
+		require.False(t, tracker.IsDegraded())
+	})
+
+	t.Run("OtherErrorsIgnored", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		tracker.Record(ctx, DatabaseSubsystemFetch, errors.New("syntax error"))
+		require.False(t, tracker.IsDegraded())
+		require.Empty(t, bundle.events)
+	})
+
+	t.Run("ListenerRecordsNotifier", func(t *testing.T) {
+		t.Parallel()
+
+		tracker, bundle := setup(t)
+
+		listener := &degradationListener{Listener: &erroringListener{connectErr: connErr}, tracker: tracker}
+		require.ErrorIs(t, listener.Connect(ctx), connErr)
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, DatabaseSubsystemNotifier, bundle.events[0].DatabaseDegradation.Subsystem)
+
+		listener.Listener = &erroringListener{}
+		require.NoError(t, listener.Connect(ctx))
+		require.Len(t, bundle.events, 2)
+		require.Equal(t, EventKindDatabaseRecovered, bundle.events[1].Kind)
+
+		listener.Listener = &erroringListener{waitErr: connErr}
+		_, err := listener.WaitForNotification(ctx)
+		require.ErrorIs(t, err, connErr)
+		require.Len(t, bundle.events, 3)
+	})
+}
+
+func TestOperationExecutor_Degradation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	connErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	type testBundle struct {
+		exec    *erroringExecutor
+		tracker *databaseDegradationTracker
+	}
+
+	setup := func(t *testing.T, errs ...error) (*operationExecutor, *testBundle) {
+		t.Helper()
+
+		var (
+			exec    = &erroringExecutor{errs: errs}
+			tracker = newDatabaseDegradationTracker(riversharedtest.BaseServiceArchetype(t), func() *riverdriver.PoolStats { return nil })
+		)
+
+		return &operationExecutor{Executor: exec, config: &operationDriverConfig{DegradationTracker: tracker, ShedMaintenance: true}}, &testBundle{
+			exec:    exec,
+			tracker: tracker,
+		}
+	}
+
+	t.Run("FetchRecorded", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, connErr)
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.ErrorIs(t, err, connErr)
+		require.True(t, bundle.tracker.IsDegraded())
+
+		_, err = executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.False(t, bundle.tracker.IsDegraded())
+	})
+
+	t.Run("ListNotRecorded", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, connErr)
+
+		_, err := executor.JobList(ctx, &riverdriver.JobListParams{})
+		require.ErrorIs(t, err, connErr)
+		require.False(t, bundle.tracker.IsDegraded())
+	})
+
+	t.Run("MaintenanceShedWhileDegraded", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		bundle.tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+
+		// The underlying executor doesn't implement JobDeleteBefore, so this
+		// would panic if the operation weren't skipped.
+		numDeleted, err := executor.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{})
+		require.NoError(t, err)
+		require.Zero(t, numDeleted)
+	})
+
+	t.Run("MaintenanceNotShedInTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.inTx = true
+		bundle.tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+
+		require.Panics(t, func() {
+			_, _ = executor.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{})
+		})
+	})
+
+	t.Run("MaintenanceNotShedWhenDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.config.ShedMaintenance = false
+		bundle.tracker.Record(ctx, DatabaseSubsystemFetch, connErr)
+
+		require.Panics(t, func() {
+			_, _ = executor.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{})
+		})
+	})
+}
+
+func Test_Client_DatabaseDegradation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbPool := riversharedtest.DBPool(ctx, t)
+	config := newTestConfig(t, riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil))
+	config.DatabaseDegradationEvents = true
+
+	client := newTestClient(t, dbPool, config)
+
+	// The driver is wrapped to track degradation, but the client still
+	// exposes the one it was given.
+	require.IsType(t, &riverpgxv5.Driver{}, client.Driver())
+
+	subscribeChan, cancel := client.Subscribe(EventKindDatabaseDegraded, EventKindDatabaseRecovered)
+	t.Cleanup(cancel)
+
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	client.databaseDegradation.Record(ctx, DatabaseSubsystemCompleter, connErr)
+
+	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, EventKindDatabaseDegraded, event.Kind)
+	require.Equal(t, DatabaseSubsystemCompleter, event.DatabaseDegradation.Subsystem)
+	require.Equal(t, DatabaseDegradationCauseConnectionFailed, event.DatabaseDegradation.Cause)
+
+	client.databaseDegradation.Record(ctx, DatabaseSubsystemCompleter, nil)
+
+	event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, EventKindDatabaseRecovered, event.Kind)
+}
+
+func Test_Client_DatabaseDegradationNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbPool := riversharedtest.DBPool(ctx, t)
+	config := newTestConfig(t, riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil))
+
+	client := newTestClient(t, dbPool, config)
+	require.Nil(t, client.databaseDegradation)
+	require.IsType(t, &riverpgxv5.Driver{}, client.driver)
+}
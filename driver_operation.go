@@ -0,0 +1,463 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivershared/util/serviceutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// DriverOperation is a class of database operations that the client performs,
+// used to configure timeouts and retries like Config.DriverOperationTimeouts.
+type DriverOperation string
+
+const (
+	// DriverOperationComplete is the class of operations that set the final
+	// state of worked jobs, like marking them completed or scheduling them for
+	// retry.
+	DriverOperationComplete DriverOperation = "complete"
+
+	// DriverOperationFetch is the class of operations that lock and fetch jobs
+	// to be worked.
+	DriverOperationFetch DriverOperation = "fetch"
+
+	// DriverOperationList is the class of operations that list and count jobs
+	// and queues, like Client.JobList and Client.QueueList.
+	DriverOperationList DriverOperation = "list"
+
+	// DriverOperationMaintenance is the class of operations run by maintenance
+	// services on the leader, like deleting old jobs, rescuing stuck jobs, and
+	// scheduling jobs that are due. Reindexing isn't included because it's
+	// governed by Config.ReindexerTimeout.
+	DriverOperationMaintenance DriverOperation = "maintenance"
+)
+
+// driverOperationValid returns true if operation is a known DriverOperation.
+func driverOperationValid(operation DriverOperation) bool {
+	switch operation {
+	case DriverOperationComplete, DriverOperationFetch, DriverOperationList, DriverOperationMaintenance:
+		return true
+	}
+	return false
+}
+
+// DriverStatementTimeouts are Postgres timeouts set on the statements of a
+// class of operations. See Config.DriverStatementTimeouts.
+type DriverStatementTimeouts struct {
+	// LockTimeout is the maximum time that a statement waits to acquire a
+	// lock, set as Postgres' `lock_timeout`. Zero leaves the connection's
+	// setting unchanged.
+	LockTimeout time.Duration
+
+	// StatementTimeout is the maximum time that a statement may run, set as
+	// Postgres' `statement_timeout`. Zero leaves the connection's setting
+	// unchanged.
+	StatementTimeout time.Duration
+}
+
+// Formats a timeout for use as a Postgres setting, or returns an empty string
+// for no timeout, which leaves the setting unchanged.
+func postgresTimeoutSetting(timeout time.Duration) string {
+	if timeout <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(timeout.Milliseconds(), 10) + "ms"
+}
+
+// DriverRetryPolicy configures automatic retries of database operations that
+// fail with a transient error. See Config.DriverRetryPolicy.
+type DriverRetryPolicy struct {
+	// Backoff returns the time to wait before the given retry of an operation,
+	// where retry is 1 for the first retry. The wait is cut short if the
+	// operation's context is cancelled.
+	//
+	// Defaults to an exponential backoff starting at 50 ms, doubling for each
+	// retry up to 1 second, with 10% jitter.
+	Backoff func(retry int) time.Duration
+
+	// MaxAttempts is the maximum number of times an operation is attempted,
+	// including its first attempt.
+	//
+	// Defaults to 3.
+	MaxAttempts int
+
+	// Operations are the classes of operations that are retried.
+	//
+	// Defaults to DriverOperationComplete and DriverOperationFetch.
+	Operations []DriverOperation
+}
+
+const (
+	driverRetryBackoffMax         = 1 * time.Second
+	driverRetryBackoffMin         = 50 * time.Millisecond
+	driverRetryMaxAttemptsDefault = 3
+)
+
+func (p *DriverRetryPolicy) withDefaults() *DriverRetryPolicy {
+	policy := &DriverRetryPolicy{
+		Backoff:     p.Backoff,
+		MaxAttempts: cmp.Or(p.MaxAttempts, driverRetryMaxAttemptsDefault),
+		Operations:  p.Operations,
+	}
+	if policy.Backoff == nil {
+		policy.Backoff = driverRetryBackoffDefault
+	}
+	if len(policy.Operations) < 1 {
+		policy.Operations = []DriverOperation{DriverOperationComplete, DriverOperationFetch}
+	}
+	return policy
+}
+
+func driverRetryBackoffDefault(retry int) time.Duration {
+	backoff := min(driverRetryBackoffMin<<(min(retry, 16)-1), driverRetryBackoffMax)
+	return backoff + randutil.DurationBetween(0, backoff/10)
+}
+
+// driverRetryStats counts retries of database operations made according to
+// Config.DriverRetryPolicy.
+type driverRetryStats struct {
+	numExhausted           atomic.Int64
+	numRetries             atomic.Int64
+	numSucceededAfterRetry atomic.Int64
+}
+
+func (s *driverRetryStats) toHealthStatus() *HealthStatusDriverRetries {
+	return &HealthStatusDriverRetries{
+		NumExhausted:           s.numExhausted.Load(),
+		NumRetries:             s.numRetries.Load(),
+		NumSucceededAfterRetry: s.numSucceededAfterRetry.Load(),
+	}
+}
+
+type operationDriverConfig struct {
+	DegradationTracker *databaseDegradationTracker // nil to disable tracking
+	OperationTimeouts  map[DriverOperation]time.Duration
+	RetryPolicy        *DriverRetryPolicy // nil to disable retries
+	RetryStats         *driverRetryStats
+	ShedMaintenance    bool // skip maintenance operations while degraded
+	StatementTimeouts  map[DriverOperation]DriverStatementTimeouts
+}
+
+// Subsystems whose degradation is tracked through operations of a class.
+var operationDegradationSubsystems = map[DriverOperation]DatabaseSubsystem{ //nolint:gochecknoglobals
+	DriverOperationComplete: DatabaseSubsystemCompleter,
+	DriverOperationFetch:    DatabaseSubsystemFetch,
+}
+
+// operationDriver wraps a driver so that its executors apply configured
+// policies to classes of operations: timeouts, both as context deadlines and as
+// Postgres statement and lock timeouts, retries of transient errors, and
+// tracking of degradation from pool exhaustion or connection failures.
+// Operations not in a class with a policy are passed through unchanged.
+type operationDriver[TTx any] struct {
+	riverdriver.Driver[TTx]
+
+	config *operationDriverConfig
+}
+
+func newOperationDriver[TTx any](driver riverdriver.Driver[TTx], config *operationDriverConfig) *operationDriver[TTx] {
+	return &operationDriver[TTx]{
+		Driver: driver,
+		config: config,
+	}
+}
+
+func (d *operationDriver[TTx]) GetExecutor() riverdriver.Executor {
+	return &operationExecutor{Executor: d.Driver.GetExecutor(), config: d.config}
+}
+
+func (d *operationDriver[TTx]) GetListener(params *riverdriver.GetListenenerParams) riverdriver.Listener {
+	listener := d.Driver.GetListener(params)
+	if d.config.DegradationTracker == nil {
+		return listener
+	}
+	return &degradationListener{Listener: listener, tracker: d.config.DegradationTracker}
+}
+
+func (d *operationDriver[TTx]) UnwrapExecutor(tx TTx) riverdriver.ExecutorTx {
+	return newOperationExecutorTx(d.Driver.UnwrapExecutor(tx), d.config)
+}
+
+func (d *operationDriver[TTx]) UnwrapTx(execTx riverdriver.ExecutorTx) TTx {
+	if operationExecTx, ok := execTx.(*operationExecutorTx); ok {
+		execTx = operationExecTx.tx
+	}
+	return d.Driver.UnwrapTx(execTx)
+}
+
+type operationExecutor struct {
+	riverdriver.Executor
+
+	config *operationDriverConfig
+	inTx   bool
+}
+
+// Runs an operation of the given class, applying its timeouts, retrying it if
+// it fails with a transient error, and recording its outcome for degradation
+// tracking. A context timeout applies to the operation as a whole, including
+// any retries.
+//
+// Retries are only made outside of a transaction because a failed statement
+// aborts the transaction it's in.
+func runOperation[T any](ctx context.Context, e *operationExecutor, operation DriverOperation, operationFunc func(ctx context.Context, exec riverdriver.Executor) (T, error)) (T, error) {
+	tracker := e.config.DegradationTracker
+	if tracker != nil {
+		// Maintenance is the least urgent work, so it's skipped while the
+		// database is degraded to leave connections for fetching and
+		// completing jobs, and while the circuit breaker is open. Skipped
+		// operations look like there was no work to do, so services try
+		// again on their next run. Operations in a transaction are part of a
+		// larger unit of work like a leader election, so they're never
+		// skipped.
+		if operation == DriverOperationMaintenance && !e.inTx && (e.config.ShedMaintenance && tracker.IsDegraded() || tracker.IsCircuitOpen()) {
+			var defaultRes T
+			return defaultRes, nil
+		}
+
+		if subsystem, ok := operationDegradationSubsystems[operation]; ok {
+			poolStatsBefore := tracker.poolStatsFunc()
+			res, err := runOperationWithRetries(ctx, e, operation, operationFunc)
+			if !errors.Is(err, context.Canceled) {
+				tracker.RecordSince(ctx, subsystem, err, poolStatsBefore)
+			}
+			return res, err
+		}
+	}
+
+	return runOperationWithRetries(ctx, e, operation, operationFunc)
+}
+
+func runOperationWithRetries[T any](ctx context.Context, e *operationExecutor, operation DriverOperation, operationFunc func(ctx context.Context, exec riverdriver.Executor) (T, error)) (T, error) {
+	if timeout := e.config.OperationTimeouts[operation]; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	retryPolicy := e.config.RetryPolicy
+	if e.inTx || retryPolicy == nil || !slices.Contains(retryPolicy.Operations, operation) {
+		return runOperationAttempt(ctx, e, operation, operationFunc)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := runOperationAttempt(ctx, e, operation, operationFunc)
+		if err == nil {
+			if attempt > 1 {
+				e.config.RetryStats.numSucceededAfterRetry.Add(1)
+			}
+			return res, nil
+		}
+
+		if !errors.Is(err, riverdriver.ErrTransient) {
+			return res, err
+		}
+
+		if attempt >= retryPolicy.MaxAttempts {
+			e.config.RetryStats.numExhausted.Add(1)
+			return res, err
+		}
+
+		serviceutil.CancellableSleep(ctx, retryPolicy.Backoff(attempt))
+		if ctx.Err() != nil {
+			return res, err
+		}
+
+		e.config.RetryStats.numRetries.Add(1)
+	}
+}
+
+// Runs a single attempt of an operation, applying statement timeouts. Statement
+// timeouts are set with `SET LOCAL` semantics so they only last as long as the
+// transaction, which means that outside of a transaction the operation is
+// wrapped in one. Within a transaction, the previous settings are restored
+// after the operation so they don't leak into the rest of it.
+func runOperationAttempt[T any](ctx context.Context, e *operationExecutor, operation DriverOperation, operationFunc func(ctx context.Context, exec riverdriver.Executor) (T, error)) (T, error) {
+	statementTimeouts := e.config.StatementTimeouts[operation]
+	var (
+		lockTimeout      = postgresTimeoutSetting(statementTimeouts.LockTimeout)
+		statementTimeout = postgresTimeoutSetting(statementTimeouts.StatementTimeout)
+	)
+	if lockTimeout == "" && statementTimeout == "" {
+		return operationFunc(ctx, e.Executor)
+	}
+
+	timeouts := &riverdriver.PGTransactionTimeouts{
+		LockTimeout:      lockTimeout,
+		StatementTimeout: statementTimeout,
+	}
+
+	if !e.inTx {
+		return dbutil.WithTxV(ctx, e.Executor, func(ctx context.Context, execTx riverdriver.ExecutorTx) (T, error) {
+			if err := execTx.PGTransactionTimeoutsSet(ctx, timeouts); err != nil && !errors.Is(err, riverdriver.ErrNotImplemented) {
+				var defaultRes T
+				return defaultRes, err
+			}
+			return operationFunc(ctx, execTx)
+		})
+	}
+
+	var defaultRes T
+
+	prevTimeouts, err := e.Executor.PGTransactionTimeoutsGet(ctx)
+	if err != nil {
+		// Drivers for databases without these timeouts, like SQLite, run the
+		// operation as is.
+		if errors.Is(err, riverdriver.ErrNotImplemented) {
+			return operationFunc(ctx, e.Executor)
+		}
+		return defaultRes, err
+	}
+
+	if err := e.Executor.PGTransactionTimeoutsSet(ctx, timeouts); err != nil {
+		return defaultRes, err
+	}
+
+	res, err := operationFunc(ctx, e.Executor)
+	if err != nil {
+		// The transaction is aborted, so settings can't be restored, but
+		// they're discarded along with it when it's rolled back.
+		return defaultRes, err
+	}
+
+	if err := e.Executor.PGTransactionTimeoutsSet(ctx, prevTimeouts); err != nil {
+		return defaultRes, err
+	}
+
+	return res, nil
+}
+
+func (e *operationExecutor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
+	tx, err := e.Executor.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newOperationExecutorTx(tx, e.config), nil
+}
+
+func (e *operationExecutor) JobCountMatching(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) (int, error) {
+		return exec.JobCountMatching(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobCountMatchingEstimate(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) (int, error) {
+		return exec.JobCountMatchingEstimate(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobDeleteBeforeParams) (int, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) (int, error) {
+		return exec.JobDeleteBefore(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	return runOperation(ctx, e, DriverOperationFetch, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.JobRow, error) {
+		return exec.JobGetAvailable(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobGetLeaseExpired(ctx context.Context, params *riverdriver.JobGetLeaseExpiredParams) ([]*rivertype.JobRow, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.JobRow, error) {
+		return exec.JobGetLeaseExpired(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.JobRow, error) {
+		return exec.JobGetStuck(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobKindList(ctx context.Context, params *riverdriver.JobKindListParams) ([]string, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) ([]string, error) {
+		return exec.JobKindList(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.JobRow, error) {
+		return exec.JobList(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobRescueMany(ctx context.Context, params *riverdriver.JobRescueManyParams) (*struct{}, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) (*struct{}, error) {
+		return exec.JobRescueMany(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobSchedule(ctx context.Context, params *riverdriver.JobScheduleParams) ([]*riverdriver.JobScheduleResult, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) ([]*riverdriver.JobScheduleResult, error) {
+		return exec.JobSchedule(ctx, params)
+	})
+}
+
+func (e *operationExecutor) JobSetStateIfRunningMany(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
+	return runOperation(ctx, e, DriverOperationComplete, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.JobRow, error) {
+		return exec.JobSetStateIfRunningMany(ctx, params)
+	})
+}
+
+func (e *operationExecutor) LeaderDeleteExpired(ctx context.Context, params *riverdriver.LeaderDeleteExpiredParams) (int, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) (int, error) {
+		return exec.LeaderDeleteExpired(ctx, params)
+	})
+}
+
+func (e *operationExecutor) NotificationDeleteBefore(ctx context.Context, params *riverdriver.NotificationDeleteBeforeParams) (int, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) (int, error) {
+		return exec.NotificationDeleteBefore(ctx, params)
+	})
+}
+
+func (e *operationExecutor) QueueDeleteExpired(ctx context.Context, params *riverdriver.QueueDeleteExpiredParams) ([]string, error) {
+	return runOperation(ctx, e, DriverOperationMaintenance, func(ctx context.Context, exec riverdriver.Executor) ([]string, error) {
+		return exec.QueueDeleteExpired(ctx, params)
+	})
+}
+
+func (e *operationExecutor) QueueList(ctx context.Context, params *riverdriver.QueueListParams) ([]*rivertype.Queue, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) ([]*rivertype.Queue, error) {
+		return exec.QueueList(ctx, params)
+	})
+}
+
+func (e *operationExecutor) QueueNameList(ctx context.Context, params *riverdriver.QueueNameListParams) ([]string, error) {
+	return runOperation(ctx, e, DriverOperationList, func(ctx context.Context, exec riverdriver.Executor) ([]string, error) {
+		return exec.QueueNameList(ctx, params)
+	})
+}
+
+// operationExecutorTx is a transaction of an operationExecutor. It
+// applies the same timeouts to operations run within the transaction.
+type operationExecutorTx struct {
+	*operationExecutor
+
+	tx riverdriver.ExecutorTx
+}
+
+func newOperationExecutorTx(tx riverdriver.ExecutorTx, config *operationDriverConfig) *operationExecutorTx {
+	return &operationExecutorTx{
+		operationExecutor: &operationExecutor{Executor: tx, config: config, inTx: true},
+		tx:                tx,
+	}
+}
+
+func (t *operationExecutorTx) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t *operationExecutorTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
@@ -0,0 +1,557 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Executor that captures the deadline of the context passed to the operations
+// it implements. Other operations panic on the nil embedded executor.
+type deadlineCapturingExecutor struct {
+	riverdriver.Executor
+
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (e *deadlineCapturingExecutor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	e.deadline, e.hasDeadline = ctx.Deadline()
+	return nil, nil
+}
+
+func (e *deadlineCapturingExecutor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
+	e.deadline, e.hasDeadline = ctx.Deadline()
+	return nil, nil
+}
+
+// Executor whose operations fail with the errors in errs, one per call, before
+// succeeding. Other operations panic on the nil embedded executor.
+type erroringExecutor struct {
+	riverdriver.Executor
+
+	errs     []error
+	numCalls int
+}
+
+func (e *erroringExecutor) nextErr() error {
+	e.numCalls++
+	if len(e.errs) < 1 {
+		return nil
+	}
+	err := e.errs[0]
+	e.errs = e.errs[1:]
+	return err
+}
+
+func (e *erroringExecutor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	return nil, e.nextErr()
+}
+
+func (e *erroringExecutor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
+	return nil, e.nextErr()
+}
+
+// Executor for a database without transaction timeouts like SQLite, whose
+// list operation returns a single job. Other operations panic on the nil
+// embedded executor.
+type noTransactionTimeoutsExecutor struct {
+	riverdriver.Executor
+}
+
+func (e *noTransactionTimeoutsExecutor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
+	return []*rivertype.JobRow{{ID: 123}}, nil
+}
+
+func (e *noTransactionTimeoutsExecutor) PGTransactionTimeoutsGet(ctx context.Context) (*riverdriver.PGTransactionTimeouts, error) {
+	return nil, riverdriver.ErrNotImplemented
+}
+
+func (e *noTransactionTimeoutsExecutor) PGTransactionTimeoutsSet(ctx context.Context, timeouts *riverdriver.PGTransactionTimeouts) error {
+	return riverdriver.ErrNotImplemented
+}
+
+// Driver that reports itself as SQLite so that Postgres-only configuration is
+// rejected without bringing `riversqlite` into the top level package.
+type driverSQLiteName struct {
+	riverpgxv5.Driver
+}
+
+func (d *driverSQLiteName) DatabaseName() string { return riverdriver.DatabaseNameSQLite }
+
+func TestOperationExecutor_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec *deadlineCapturingExecutor
+	}
+
+	setup := func(t *testing.T, timeouts map[DriverOperation]time.Duration) (*operationExecutor, *testBundle) {
+		t.Helper()
+
+		exec := &deadlineCapturingExecutor{}
+
+		return &operationExecutor{Executor: exec, config: &operationDriverConfig{OperationTimeouts: timeouts}}, &testBundle{
+			exec: exec,
+		}
+	}
+
+	t.Run("AppliesTimeoutForOperationClass", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationFetch: time.Second,
+			DriverOperationList:  time.Hour,
+		})
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.True(t, bundle.exec.hasDeadline)
+		require.WithinDuration(t, time.Now().Add(time.Second), bundle.exec.deadline, 500*time.Millisecond)
+
+		_, err = executor.JobList(ctx, &riverdriver.JobListParams{})
+		require.NoError(t, err)
+		require.True(t, bundle.exec.hasDeadline)
+		require.WithinDuration(t, time.Now().Add(time.Hour), bundle.exec.deadline, 500*time.Millisecond)
+	})
+
+	t.Run("NoTimeoutForUnconfiguredClass", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationList: time.Second,
+		})
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.False(t, bundle.exec.hasDeadline)
+	})
+
+	t.Run("ZeroTimeoutIsNoTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationFetch: 0,
+		})
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.False(t, bundle.exec.hasDeadline)
+	})
+
+	t.Run("EarlierContextDeadlineKept", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationFetch: time.Hour,
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.True(t, bundle.exec.hasDeadline)
+		require.WithinDuration(t, time.Now().Add(time.Second), bundle.exec.deadline, 500*time.Millisecond)
+	})
+}
+
+func TestOperationExecutor_Retries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	errTransient := fmt.Errorf("serialization failure: %w", riverdriver.ErrTransient)
+
+	type testBundle struct {
+		exec  *erroringExecutor
+		stats *driverRetryStats
+	}
+
+	setup := func(t *testing.T, policy *DriverRetryPolicy, errs ...error) (*operationExecutor, *testBundle) {
+		t.Helper()
+
+		var (
+			exec  = &erroringExecutor{errs: errs}
+			stats = &driverRetryStats{}
+		)
+
+		policy = policy.withDefaults()
+		policy.Backoff = func(retry int) time.Duration { return 0 }
+
+		return &operationExecutor{Executor: exec, config: &operationDriverConfig{RetryPolicy: policy, RetryStats: stats}}, &testBundle{
+			exec:  exec,
+			stats: stats,
+		}
+	}
+
+	t.Run("SucceedsAfterRetry", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{}, errTransient, errTransient)
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.NoError(t, err)
+		require.Equal(t, 3, bundle.exec.numCalls)
+		require.Equal(t, &HealthStatusDriverRetries{NumRetries: 2, NumSucceededAfterRetry: 1}, bundle.stats.toHealthStatus())
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{MaxAttempts: 2}, errTransient, errTransient, errTransient)
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.ErrorIs(t, err, riverdriver.ErrTransient)
+		require.Equal(t, 2, bundle.exec.numCalls)
+		require.Equal(t, &HealthStatusDriverRetries{NumExhausted: 1, NumRetries: 1}, bundle.stats.toHealthStatus())
+	})
+
+	t.Run("NonTransientErrorNotRetried", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{}, errors.New("syntax error"))
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.EqualError(t, err, "syntax error")
+		require.Equal(t, 1, bundle.exec.numCalls)
+		require.Equal(t, &HealthStatusDriverRetries{}, bundle.stats.toHealthStatus())
+	})
+
+	t.Run("UnconfiguredOperationNotRetried", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{Operations: []DriverOperation{DriverOperationFetch}}, errTransient)
+
+		_, err := executor.JobList(ctx, &riverdriver.JobListParams{})
+		require.ErrorIs(t, err, riverdriver.ErrTransient)
+		require.Equal(t, 1, bundle.exec.numCalls)
+	})
+
+	t.Run("NotRetriedInTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{}, errTransient)
+		executor.inTx = true
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.ErrorIs(t, err, riverdriver.ErrTransient)
+		require.Equal(t, 1, bundle.exec.numCalls)
+	})
+
+	t.Run("StopsOnContextCancelled", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t, &DriverRetryPolicy{MaxAttempts: 10}, errTransient, errTransient)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := executor.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{})
+		require.ErrorIs(t, err, riverdriver.ErrTransient)
+		require.Equal(t, 1, bundle.exec.numCalls)
+	})
+}
+
+func TestOperationExecutor_StatementTimeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("SkippedWhenNotImplemented", func(t *testing.T) {
+		t.Parallel()
+
+		executor := &operationExecutor{
+			Executor: &noTransactionTimeoutsExecutor{},
+			config: &operationDriverConfig{StatementTimeouts: map[DriverOperation]DriverStatementTimeouts{
+				DriverOperationList: {LockTimeout: time.Second, StatementTimeout: time.Second},
+			}},
+			inTx: true,
+		}
+
+		jobs, err := executor.JobList(ctx, &riverdriver.JobListParams{})
+		require.NoError(t, err)
+		require.Equal(t, []*rivertype.JobRow{{ID: 123}}, jobs)
+	})
+}
+
+func TestDriverRetryBackoffDefault(t *testing.T) {
+	t.Parallel()
+
+	for retry, expected := range map[int]time.Duration{
+		1:   50 * time.Millisecond,
+		2:   100 * time.Millisecond,
+		5:   800 * time.Millisecond,
+		6:   time.Second,
+		100: time.Second,
+	} {
+		backoff := driverRetryBackoffDefault(retry)
+		require.GreaterOrEqual(t, backoff, expected)
+		require.LessOrEqual(t, backoff, expected+expected/10)
+	}
+}
+
+func Test_Client_DriverRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbPool := riversharedtest.DBPool(ctx, t)
+	config := newTestConfig(t, riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil))
+	config.DriverRetryPolicy = &DriverRetryPolicy{}
+
+	client := newTestClient(t, dbPool, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+	t.Cleanup(cancel)
+
+	startClient(ctx, t, client)
+
+	insertRes, err := client.Insert(ctx, &noOpArgs{}, nil)
+	require.NoError(t, err)
+
+	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Equal(t, insertRes.Job.ID, event.Job.ID)
+
+	require.Equal(t, &HealthStatusDriverRetries{}, client.Liveness(ctx).DriverRetries)
+}
+
+func Test_Client_DriverOperationTimeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T, timeouts map[DriverOperation]time.Duration) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+		config.DriverOperationTimeouts = timeouts
+
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			exec:   driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("ListTimesOut", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationList: 50 * time.Millisecond,
+		})
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		_, err := client.JobList(ctx, NewJobListParams().Where("pg_sleep(1) IS NOT NULL"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// Operations of other classes aren't affected.
+		_, err = client.JobGet(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ListWithinTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationList: 10 * time.Second,
+		})
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		listRes, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Len(t, listRes.Jobs, 1)
+		require.Equal(t, job.ID, listRes.Jobs[0].ID)
+	})
+
+	t.Run("AppliedInTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationList: 50 * time.Millisecond,
+		})
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		tx, err := client.driver.GetExecutor().Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+		_, err = client.JobListTx(ctx, client.driver.UnwrapTx(tx), NewJobListParams().Where("pg_sleep(1) IS NOT NULL"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("WorksJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t, map[DriverOperation]time.Duration{
+			DriverOperationComplete:    5 * time.Second,
+			DriverOperationFetch:       5 * time.Second,
+			DriverOperationMaintenance: 5 * time.Second,
+		})
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+}
+
+func Test_Client_DriverStatementTimeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T, timeouts map[DriverOperation]DriverStatementTimeouts) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+		config.DriverStatementTimeouts = timeouts
+
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("StatementTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, map[DriverOperation]DriverStatementTimeouts{
+			DriverOperationList: {StatementTimeout: 50 * time.Millisecond},
+		})
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		_, err := client.JobList(ctx, NewJobListParams().Where("pg_sleep(1) IS NOT NULL"))
+		require.ErrorIs(t, err, riverdriver.ErrStatementTimeout)
+
+		// Operations of other classes aren't affected.
+		_, err = client.QueueGet(ctx, QueueDefault)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("LockTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, map[DriverOperation]DriverStatementTimeouts{
+			DriverOperationList: {LockTimeout: 50 * time.Millisecond},
+		})
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema})
+
+		// Hold a lock that conflicts with the lock taken by the list below.
+		lockTx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = lockTx.Rollback(ctx) })
+
+		_, err = lockTx.Exec(ctx, "LOCK TABLE "+bundle.schema+".river_job IN ACCESS EXCLUSIVE MODE")
+		require.NoError(t, err)
+
+		_, err = client.JobList(ctx, NewJobListParams())
+		require.ErrorIs(t, err, riverdriver.ErrStatementTimeout)
+	})
+
+	t.Run("SettingsRestoredInTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t, map[DriverOperation]DriverStatementTimeouts{
+			DriverOperationList: {LockTimeout: 2 * time.Second, StatementTimeout: 3 * time.Second},
+		})
+
+		tx, err := client.driver.GetExecutor().Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+		require.NoError(t, tx.Exec(ctx, "SET LOCAL statement_timeout = '5s'"))
+
+		_, err = client.JobListTx(ctx, client.driver.UnwrapTx(tx), NewJobListParams())
+		require.NoError(t, err)
+
+		var lockTimeout, statementTimeout string
+		require.NoError(t, tx.QueryRow(ctx, "SELECT current_setting('lock_timeout'), current_setting('statement_timeout')").Scan(&lockTimeout, &statementTimeout))
+		require.Equal(t, "0", lockTimeout)
+		require.Equal(t, "5s", statementTimeout)
+	})
+
+	t.Run("WorksJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t, map[DriverOperation]DriverStatementTimeouts{
+			DriverOperationComplete:    {LockTimeout: time.Second, StatementTimeout: 5 * time.Second},
+			DriverOperationFetch:       {LockTimeout: time.Second, StatementTimeout: 5 * time.Second},
+			DriverOperationMaintenance: {LockTimeout: time.Second, StatementTimeout: 5 * time.Second},
+		})
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("NotSupportedBySQLite", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.DriverStatementTimeouts = map[DriverOperation]DriverStatementTimeouts{
+			DriverOperationList: {StatementTimeout: time.Second},
+		}
+
+		_, err := NewClient(&driverSQLiteName{Driver: *riverpgxv5.New(nil)}, config)
+		require.EqualError(t, err, "DriverStatementTimeouts is only supported by Postgres drivers")
+	})
+}
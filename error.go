@@ -15,6 +15,11 @@ var ErrJobCancelledRemotely = rivertype.ErrJobCancelledRemotely
 // be used for test assertions.
 type JobCancelError = rivertype.JobCancelError
 
+// JobCancelledRemotelyError is the cause of a job context's cancellation when
+// the job was cancelled remotely along with a reason using
+// Client.JobCancelWithReason. See rivertype.JobCancelledRemotelyError.
+type JobCancelledRemotelyError = rivertype.JobCancelledRemotelyError
+
 // JobCancel wraps err and can be returned from a Worker's Work method to cancel
 // the job at the end of execution. Regardless of whether or not the job has any
 // remaining attempts, this will ensure the job does not execute again.
@@ -22,6 +27,23 @@ func JobCancel(err error) error {
 	return rivertype.JobCancel(err)
 }
 
+// JobArgsValidationError is returned from Insert and InsertMany when job args
+// implementing JobArgsWithValidate fail validation. Err is the error returned
+// by the args' Validate method.
+type JobArgsValidationError struct {
+	// Kind is the kind of the job whose args failed validation.
+	Kind string
+
+	// Err is the error returned by Validate.
+	Err error
+}
+
+func (e *JobArgsValidationError) Error() string {
+	return fmt.Sprintf("invalid args for job of kind %q: %s", e.Kind, e.Err)
+}
+
+func (e *JobArgsValidationError) Unwrap() error { return e.Err }
+
 // JobSnoozeError is the error type returned by JobSnooze. It should not be
 // initialized directly, but is returned from the [JobSnooze] function and can
 // be used for test assertions.
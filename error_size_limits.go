@@ -0,0 +1,64 @@
+package river
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errorSizeLimitMinBytes is the smallest limit that may be configured in
+// ErrorSizeLimits. Smaller limits wouldn't leave room for much beyond the
+// truncation marker.
+const errorSizeLimitMinBytes = 256
+
+// ErrorSizeLimits configures maximum sizes for the error messages and panic
+// traces recorded to a job's errors when an attempt fails. Without limits, a
+// worker that returns a huge error (like one wrapping an entire HTTP response
+// body) stores it again on every attempt, bloating the jobs table and slowing
+// down queries that list jobs.
+//
+// Values exceeding their limit are truncated from the middle, keeping their
+// head and tail (which usually carry the most useful context) around a marker
+// noting how many bytes were removed.
+type ErrorSizeLimits struct {
+	// ErrorMaxBytes is the maximum size in bytes of an attempt's error message.
+	// Must be zero or at least 256.
+	//
+	// Defaults to zero, which applies no limit.
+	ErrorMaxBytes int
+
+	// TraceMaxBytes is the maximum size in bytes of the stack trace recorded
+	// when a job panics. Must be zero or at least 256.
+	//
+	// Defaults to zero, which applies no limit.
+	TraceMaxBytes int
+}
+
+func (l *ErrorSizeLimits) validate() error {
+	if l.ErrorMaxBytes < 0 {
+		return errors.New("ErrorSizeLimits.ErrorMaxBytes cannot be less than zero")
+	}
+	if l.ErrorMaxBytes > 0 && l.ErrorMaxBytes < errorSizeLimitMinBytes {
+		return fmt.Errorf("ErrorSizeLimits.ErrorMaxBytes must be zero or at least %d", errorSizeLimitMinBytes)
+	}
+	if l.TraceMaxBytes < 0 {
+		return errors.New("ErrorSizeLimits.TraceMaxBytes cannot be less than zero")
+	}
+	if l.TraceMaxBytes > 0 && l.TraceMaxBytes < errorSizeLimitMinBytes {
+		return fmt.Errorf("ErrorSizeLimits.TraceMaxBytes must be zero or at least %d", errorSizeLimitMinBytes)
+	}
+	return nil
+}
+
+func (l *ErrorSizeLimits) errorMaxBytes() int {
+	if l == nil {
+		return 0
+	}
+	return l.ErrorMaxBytes
+}
+
+func (l *ErrorSizeLimits) traceMaxBytes() int {
+	if l == nil {
+		return 0
+	}
+	return l.TraceMaxBytes
+}
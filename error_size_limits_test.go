@@ -0,0 +1,54 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestErrorSizeLimits_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&ErrorSizeLimits{}).validate())
+	require.NoError(t, (&ErrorSizeLimits{ErrorMaxBytes: 256, TraceMaxBytes: 1_000}).validate())
+
+	require.EqualError(t, (&ErrorSizeLimits{ErrorMaxBytes: -1}).validate(), "ErrorSizeLimits.ErrorMaxBytes cannot be less than zero")
+	require.EqualError(t, (&ErrorSizeLimits{ErrorMaxBytes: 255}).validate(), "ErrorSizeLimits.ErrorMaxBytes must be zero or at least 256")
+	require.EqualError(t, (&ErrorSizeLimits{TraceMaxBytes: -1}).validate(), "ErrorSizeLimits.TraceMaxBytes cannot be less than zero")
+	require.EqualError(t, (&ErrorSizeLimits{TraceMaxBytes: 255}).validate(), "ErrorSizeLimits.TraceMaxBytes must be zero or at least 256")
+}
+
+type errorSizeLimitsArgs struct{}
+
+func (errorSizeLimitsArgs) Kind() string { return "error_size_limits" }
+
+func Test_Client_ErrorSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	config := newTestConfig(t, "")
+	config.ErrorSizeLimits = &ErrorSizeLimits{ErrorMaxBytes: 256}
+	AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[errorSizeLimitsArgs]) error {
+		return errors.New("head" + strings.Repeat("x", 10_000) + "tail")
+	}))
+
+	client := runNewTestClient(ctx, t, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindJobFailed)
+	t.Cleanup(cancel)
+
+	_, err := client.Insert(ctx, errorSizeLimitsArgs{}, nil)
+	require.NoError(t, err)
+
+	event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+	require.Len(t, event.Job.Errors, 1)
+	require.LessOrEqual(t, len(event.Job.Errors[0].Error), 256)
+	require.True(t, strings.HasPrefix(event.Job.Errors[0].Error, "headxxx"))
+	require.True(t, strings.HasSuffix(event.Job.Errors[0].Error, "xxxtail"))
+}
@@ -11,6 +11,45 @@ import (
 type EventKind string
 
 const (
+	// EventKindCompleterBackpressureEngaged occurs when the backlog of job
+	// completions waiting to be written to the database exceeds the threshold
+	// configured with Config.CompleterBackpressure, at which point the client
+	// stops fetching jobs. Event.CompleterBackpressure contains the backlog's
+	// size. Only sent once until the backlog drains.
+	EventKindCompleterBackpressureEngaged EventKind = "completer_backpressure_engaged"
+
+	// EventKindCompleterBackpressureReleased occurs when the completer's
+	// backlog drains after an EventKindCompleterBackpressureEngaged event and
+	// the client resumes fetching jobs. Event.CompleterBackpressure contains
+	// the backlog's size.
+	EventKindCompleterBackpressureReleased EventKind = "completer_backpressure_released"
+
+	// EventKindDatabaseCircuitClosed occurs when a circuit breaker configured
+	// with Config.DatabaseCircuitBreaker closes because the database is
+	// reachable again, after which the client resumes normal operation.
+	EventKindDatabaseCircuitClosed EventKind = "database_circuit_closed"
+
+	// EventKindDatabaseCircuitOpened occurs when a circuit breaker configured
+	// with Config.DatabaseCircuitBreaker opens after sustained database
+	// failures. Event.DatabaseDegradation contains the failure that tripped
+	// it. While open, the client stops fetching jobs, holds completions, and
+	// skips maintenance.
+	EventKindDatabaseCircuitOpened EventKind = "database_circuit_opened"
+
+	// EventKindDatabaseDegraded occurs when one of the client's subsystems
+	// starts failing database operations because the database pool is
+	// exhausted or because a connection to the database couldn't be
+	// established or was lost. Event.DatabaseDegradation contains the
+	// subsystem that was starved and the cause. Only sent once per subsystem
+	// until it recovers.
+	EventKindDatabaseDegraded EventKind = "database_degraded"
+
+	// EventKindDatabaseRecovered occurs when a subsystem for which an
+	// EventKindDatabaseDegraded event was sent successfully completes a
+	// database operation again. Event.DatabaseDegradation contains the
+	// degradation that it recovered from.
+	EventKindDatabaseRecovered EventKind = "database_recovered"
+
 	// EventKindJobCancelled occurs when a job is cancelled.
 	EventKindJobCancelled EventKind = "job_cancelled"
 
@@ -23,26 +62,70 @@ const (
 	// differentiate each type of occurrence.
 	EventKindJobFailed EventKind = "job_failed"
 
+	// EventKindJobPoisonPill occurs when a job is quarantined as a poison pill
+	// after crashing repeatedly, as detected with Config.PoisonPill. Event.Job
+	// contains the job as it was before being discarded. Sent in addition to
+	// the EventKindJobFailed event for the job's last attempt if the client
+	// that detected it was working it.
+	EventKindJobPoisonPill EventKind = "job_poison_pill"
+
 	// EventKindJobSnoozed occurs when a job is snoozed.
 	EventKindJobSnoozed EventKind = "job_snoozed"
 
+	// EventKindQueueMetadataChanged occurs when a queue's metadata is changed
+	// with Client.QueueUpdate. Event.Queue contains the queue's name and its
+	// new metadata, so receivers can react to per-queue configuration stored
+	// in metadata immediately instead of polling for it. Sent once by each
+	// client working the queue, and by read-only clients.
+	EventKindQueueMetadataChanged EventKind = "queue_metadata_changed"
+
 	// EventKindQueuePaused occurs when a queue is paused.
 	EventKindQueuePaused EventKind = "queue_paused"
 
 	// EventKindQueueResumed occurs when a queue is resumed.
 	EventKindQueueResumed EventKind = "queue_resumed"
+
+	// EventKindRateAnomaly occurs when the rate at which jobs of a kind are
+	// inserted or fail rises sharply above its recent baseline, as detected by
+	// a monitor configured with Config.RateAnomalyMonitor. Event.RateAnomaly
+	// contains the kind, the rate, and its baseline. Only sent once when a
+	// rate becomes anomalous, and not again until it's returned to normal.
+	EventKindRateAnomaly EventKind = "rate_anomaly"
+
+	// EventKindResourceBudgetExceeded occurs when the client's memory or
+	// goroutines exceed the budget configured with Config.ResourceGuard, at
+	// which point it stops fetching jobs. Event.ResourceBudget contains the
+	// usage that exceeded it. Only sent once until usage recovers.
+	EventKindResourceBudgetExceeded EventKind = "resource_budget_exceeded"
+
+	// EventKindResourceBudgetRecovered occurs when the client's resource usage
+	// falls back under budget after an EventKindResourceBudgetExceeded event
+	// and it resumes fetching jobs. Event.ResourceBudget contains the usage
+	// that it recovered to.
+	EventKindResourceBudgetRecovered EventKind = "resource_budget_recovered"
 )
 
 // All known event kinds, used to validate incoming kinds. This is purposely not
 // exported because end users should have no way of subscribing to all known
 // kinds for forward compatibility reasons.
 var allKinds = map[EventKind]struct{}{ //nolint:gochecknoglobals
-	EventKindJobCancelled: {},
-	EventKindJobCompleted: {},
-	EventKindJobFailed:    {},
-	EventKindJobSnoozed:   {},
-	EventKindQueuePaused:  {},
-	EventKindQueueResumed: {},
+	EventKindCompleterBackpressureEngaged:  {},
+	EventKindCompleterBackpressureReleased: {},
+	EventKindDatabaseCircuitClosed:         {},
+	EventKindDatabaseCircuitOpened:         {},
+	EventKindDatabaseDegraded:              {},
+	EventKindDatabaseRecovered:             {},
+	EventKindJobCancelled:                  {},
+	EventKindJobCompleted:                  {},
+	EventKindJobFailed:                     {},
+	EventKindJobPoisonPill:                 {},
+	EventKindJobSnoozed:                    {},
+	EventKindQueueMetadataChanged:          {},
+	EventKindQueuePaused:                   {},
+	EventKindQueueResumed:                  {},
+	EventKindRateAnomaly:                   {},
+	EventKindResourceBudgetExceeded:        {},
+	EventKindResourceBudgetRecovered:       {},
 }
 
 // Event wraps an event that occurred within a River client, like a job being
@@ -53,6 +136,17 @@ type Event struct {
 	// requested when creating a subscription with Subscribe.
 	Kind EventKind
 
+	// CompleterBackpressure contains the size of the completer's backlog and
+	// the thresholds it was compared against. Only set for
+	// EventKindCompleterBackpressureEngaged and
+	// EventKindCompleterBackpressureReleased.
+	CompleterBackpressure *CompleterBackpressure
+
+	// DatabaseDegradation contains information about a subsystem whose
+	// database operations are failing. Only set for EventKindDatabaseDegraded,
+	// EventKindDatabaseRecovered, and EventKindDatabaseCircuitOpened.
+	DatabaseDegradation *DatabaseDegradation
+
 	// Job contains job-related information.
 	Job *rivertype.JobRow
 
@@ -61,6 +155,15 @@ type Event struct {
 
 	// Queue contains queue-related information.
 	Queue *rivertype.Queue
+
+	// RateAnomaly contains information about a kind whose insert or failure
+	// rate deviated from its baseline. Only set for EventKindRateAnomaly.
+	RateAnomaly *RateAnomaly
+
+	// ResourceBudget contains the client's resource usage and budget. Only
+	// set for EventKindResourceBudgetExceeded and
+	// EventKindResourceBudgetRecovered.
+	ResourceBudget *ResourceBudget
 }
 
 // JobStatistics contains information about a single execution of a job.
@@ -0,0 +1,66 @@
+package river
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const externalIDMaxLength = 255
+
+// ExternalIDUUIDv7 generates a new UUIDv7 in its canonical string form. It's
+// suitable for use as Config.ExternalIDFunc to give every inserted job a
+// globally unique external ID that's roughly ordered by creation time.
+func ExternalIDUUIDv7() string {
+	return uuidV7(time.Now())
+}
+
+// uuidV7 generates a UUIDv7 as specified by RFC 9562 whose timestamp is
+// derived from the given time.
+func uuidV7(now time.Time) string {
+	var uuid [16]byte
+
+	// Random bits fill everything after the 48-bit timestamp. crypto/rand's
+	// Read never returns an error.
+	_, _ = rand.Read(uuid[6:])
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(now.UnixMilli())) //nolint:gosec
+	copy(uuid[0:6], timestamp[2:])
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x70 // version 7
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // variant 10
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf[:])
+}
+
+// setExternalID records the given external ID in job metadata.
+func setExternalID(metadata []byte, externalID string) ([]byte, error) {
+	if len(externalID) > externalIDMaxLength {
+		return nil, fmt.Errorf("external ID should be a maximum of %d characters long", externalIDMaxLength)
+	}
+
+	metadata, err := sjson.SetBytes(metadata, rivertype.MetadataKeyExternalID, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("error setting external ID in metadata: %w", err)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,165 @@
+package river
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestExternalIDUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	uuidV7RE := regexp.MustCompile(`\A[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\z`)
+
+	t.Run("Format", func(t *testing.T) {
+		t.Parallel()
+
+		id := ExternalIDUUIDv7()
+		require.Regexp(t, uuidV7RE, id)
+		require.NotEqual(t, id, ExternalIDUUIDv7())
+	})
+
+	t.Run("EncodesTimestamp", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.UnixMilli(0x0123456789ab)
+
+		id := uuidV7(now)
+		require.Regexp(t, uuidV7RE, id)
+		require.True(t, strings.HasPrefix(id, "01234567-89ab-7"), "expected timestamp prefix in %s", id)
+	})
+
+	t.Run("OrderedByTime", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		require.Less(t, uuidV7(now), uuidV7(now.Add(time.Millisecond)))
+	})
+}
+
+func Test_Client_ExternalID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		return newTestClient(t, dbPool, config), &testBundle{dbPool: dbPool}
+	}
+
+	t.Run("InsertOptsExternalID", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ExternalID: "ext_123", Metadata: []byte(`{"foo":"bar"}`)})
+		require.NoError(t, err)
+		require.Equal(t, "ext_123", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyExternalID).String())
+		require.Equal(t, "bar", gjson.GetBytes(insertRes.Job.Metadata, "foo").String())
+
+		job, err := client.JobGetByExternalID(ctx, "ext_123")
+		require.NoError(t, err)
+		require.Equal(t, insertRes.Job.ID, job.ID)
+	})
+
+	t.Run("ExternalIDFunc", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		client.config.ExternalIDFunc = ExternalIDUUIDv7
+
+		insertRes1, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+		insertRes2, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		externalID1 := gjson.GetBytes(insertRes1.Job.Metadata, rivertype.MetadataKeyExternalID).String()
+		externalID2 := gjson.GetBytes(insertRes2.Job.Metadata, rivertype.MetadataKeyExternalID).String()
+		require.NotEmpty(t, externalID1)
+		require.NotEqual(t, externalID1, externalID2)
+
+		job, err := client.JobGetByExternalID(ctx, externalID2)
+		require.NoError(t, err)
+		require.Equal(t, insertRes2.Job.ID, job.ID)
+
+		// An explicit external ID takes precedence.
+		insertRes3, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ExternalID: "ext_123"})
+		require.NoError(t, err)
+		require.Equal(t, "ext_123", gjson.GetBytes(insertRes3.Job.Metadata, rivertype.MetadataKeyExternalID).String())
+	})
+
+	t.Run("NoExternalID", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyExternalID).Exists())
+	})
+
+	t.Run("DuplicateExternalIDError", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ExternalID: "ext_123"})
+		require.NoError(t, err)
+
+		_, err = client.Insert(ctx, noOpArgs{}, &InsertOpts{ExternalID: "ext_123"})
+		require.Error(t, err)
+	})
+
+	t.Run("ExternalIDTooLongError", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ExternalID: strings.Repeat("x", 256)})
+		require.EqualError(t, err, "external ID should be a maximum of 255 characters long")
+	})
+
+	t.Run("JobGetByExternalIDTx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		insertRes, err := client.InsertTx(ctx, tx, noOpArgs{}, &InsertOpts{ExternalID: "ext_123"})
+		require.NoError(t, err)
+
+		job, err := client.JobGetByExternalIDTx(ctx, tx, "ext_123")
+		require.NoError(t, err)
+		require.Equal(t, insertRes.Job.ID, job.ID)
+
+		_, err = client.JobGetByExternalID(ctx, "ext_123")
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+	})
+}
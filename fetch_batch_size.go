@@ -0,0 +1,108 @@
+package river
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// fetchBatchSizeMinDivisor sets the smallest batch an adaptive producer
+	// fetches to this fraction of its MaxWorkers.
+	fetchBatchSizeMinDivisor = 10
+
+	// fetchBatchThroughputWeight is the weight given to the most recent
+	// measurement of throughput in its moving average.
+	fetchBatchThroughputWeight = 0.3
+)
+
+// fetchBatchSizer chooses the number of jobs a producer requests in each fetch
+// for Config.AdaptiveFetchBatchSize.
+//
+// Batches grow quickly while fetches come back full, which suggests that a
+// backlog of jobs is waiting in the database, so that it's worked through
+// with fewer round trips. When a fetch comes back short, the batch size
+// shrinks to roughly the number of jobs the producer finishes in one fetch
+// poll interval, so a lightly loaded producer doesn't lock more rows than it
+// needs. Batches are also shrunk while the completer's backlog is more than
+// half full, because fetching more work while completions are falling behind
+// only piles more on.
+type fetchBatchSizer struct {
+	maxSize      int
+	minSize      int
+	pollInterval time.Duration
+
+	mu             sync.Mutex
+	lastFetchAt    time.Time
+	lastNumJobsRan uint64
+	size           int
+	stats          HealthStatusFetchBatchSize
+	throughput     float64 // jobs finished per second, as a moving average
+}
+
+func newFetchBatchSizer(maxSize int, pollInterval time.Duration) *fetchBatchSizer {
+	return &fetchBatchSizer{
+		maxSize:      maxSize,
+		minSize:      max(1, maxSize/fetchBatchSizeMinDivisor),
+		pollInterval: pollInterval,
+		size:         maxSize,
+	}
+}
+
+// next returns the number of jobs to request in the next fetch given the
+// fraction of the completer's maximum backlog that's currently in use.
+func (s *fetchBatchSizer) next(completerBacklogFraction float64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if completerBacklogFraction <= 0.5 {
+		return s.size
+	}
+
+	// Scale down linearly from the full size at half the maximum backlog to a
+	// single job at the maximum.
+	return max(1, int(float64(s.size)*2*(1-min(completerBacklogFraction, 1))))
+}
+
+// recordFetch records the result of a fetch in which fetched jobs were
+// returned out of the requested number, adjusting the size of the next batch.
+// numJobsRan is the total number of jobs the producer has finished, from
+// which its throughput is measured.
+func (s *fetchBatchSizer) recordFetch(now time.Time, requested, fetched int, numJobsRan uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.lastFetchAt.IsZero() {
+		if elapsed := now.Sub(s.lastFetchAt).Seconds(); elapsed > 0 {
+			rate := float64(numJobsRan-s.lastNumJobsRan) / elapsed
+			s.throughput = fetchBatchThroughputWeight*rate + (1-fetchBatchThroughputWeight)*s.throughput
+		}
+	}
+	s.lastFetchAt = now
+	s.lastNumJobsRan = numJobsRan
+
+	// Jobs the producer can be expected to finish before the next poll.
+	throughputSize := int(math.Ceil(s.throughput * s.pollInterval.Seconds()))
+
+	if fetched >= requested {
+		s.size = min(s.maxSize, max(s.size*2, throughputSize))
+	} else {
+		s.size = min(s.maxSize, max(s.minSize, throughputSize))
+	}
+
+	if s.stats.NumFetches == 0 || requested < s.stats.Min {
+		s.stats.Min = requested
+	}
+	s.stats.Last = requested
+	s.stats.Max = max(s.stats.Max, requested)
+	s.stats.NumFetches++
+}
+
+// snapshot returns statistics on the batch sizes requested so far.
+func (s *fetchBatchSizer) snapshot() *HealthStatusFetchBatchSize {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	return &stats
+}
@@ -0,0 +1,124 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+)
+
+func TestFetchBatchSizer(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	t.Run("StartsAtMaxSize", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+		require.Equal(t, 100, sizer.next(0))
+		require.Equal(t, 10, sizer.minSize)
+	})
+
+	t.Run("ShrinksToMinSizeWhenIdle", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+
+		sizer.recordFetch(start, 100, 0, 0)
+		require.Equal(t, 10, sizer.next(0))
+	})
+
+	t.Run("ShrinksToThroughputOnShortFetch", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+
+		sizer.recordFetch(start, 100, 0, 0)
+
+		// 100 jobs finished in a second, weighted into a moving average of 30
+		// per second, which is about how many finish in a poll interval.
+		sizer.recordFetch(start.Add(time.Second), 10, 5, 100)
+		require.InDelta(t, 30, sizer.throughput, 0.001)
+		require.Equal(t, 30, sizer.next(0))
+	})
+
+	t.Run("GrowsOnFullFetch", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+
+		sizer.recordFetch(start, 100, 0, 0)
+		require.Equal(t, 10, sizer.next(0))
+
+		sizer.recordFetch(start, 10, 10, 0)
+		require.Equal(t, 20, sizer.next(0))
+
+		sizer.recordFetch(start, 20, 20, 0)
+		sizer.recordFetch(start, 40, 40, 0)
+		sizer.recordFetch(start, 80, 80, 0)
+		require.Equal(t, 100, sizer.next(0))
+	})
+
+	t.Run("ShrinksWithCompleterBacklog", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+
+		require.Equal(t, 100, sizer.next(0.5))
+		require.Equal(t, 50, sizer.next(0.75))
+		require.Equal(t, 1, sizer.next(1))
+		require.Equal(t, 1, sizer.next(2))
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		sizer := newFetchBatchSizer(100, time.Second)
+		require.Equal(t, &HealthStatusFetchBatchSize{}, sizer.snapshot())
+
+		sizer.recordFetch(start, 100, 0, 0)
+		sizer.recordFetch(start, 10, 10, 0)
+		sizer.recordFetch(start, 20, 5, 0)
+
+		require.Equal(t, &HealthStatusFetchBatchSize{
+			Last:       20,
+			Max:        100,
+			Min:        10,
+			NumFetches: 3,
+		}, sizer.snapshot())
+	})
+}
+
+func Test_Client_AdaptiveFetchBatchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	config := newTestConfig(t, "")
+	config.AdaptiveFetchBatchSize = true
+	AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+	client := runNewTestClient(ctx, t, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+	t.Cleanup(cancel)
+
+	_, err := client.Insert(ctx, JobArgs{}, nil)
+	require.NoError(t, err)
+
+	riversharedtest.WaitOrTimeout(t, subscribeChan)
+
+	status := client.Liveness(ctx)
+	require.Contains(t, status.FetchBatchSizes, QueueDefault)
+	require.Positive(t, status.FetchBatchSizes[QueueDefault].NumFetches)
+	require.Equal(t, config.Queues[QueueDefault].MaxWorkers, status.FetchBatchSizes[QueueDefault].Max)
+}
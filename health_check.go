@@ -0,0 +1,364 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/maputil"
+)
+
+// HealthStatus is a structured health status for a client as returned by
+// Client.Liveness or Client.Readiness. It's designed to be serialized to JSON
+// so that it can be returned from a health check endpoint, which is what
+// Client.LivenessHandler and Client.ReadinessHandler do.
+type HealthStatus struct {
+	// CompleterBackpressure contains the state of backpressure applied to
+	// fetching because of a backlog of job completions. Only populated when
+	// Config.CompleterBackpressure is set.
+	CompleterBackpressure *HealthStatusCompleterBackpressure `json:"completer_backpressure,omitempty"`
+
+	// Database contains information about the client's database connectivity.
+	// It's only populated by readiness checks, which interact with the
+	// database. Liveness checks only look at process-local state so that an
+	// outage of a shared database doesn't cause every process to be restarted.
+	Database *HealthStatusDatabase `json:"database,omitempty"`
+
+	// DatabaseCircuitOpen is whether the circuit breaker configured with
+	// Config.DatabaseCircuitBreaker is open because of a database outage.
+	// Readiness checks fail while it's open, but liveness checks don't, since
+	// restarting the process won't bring the database back.
+	DatabaseCircuitOpen bool `json:"database_circuit_open"`
+
+	// DriverRetries counts retries of database operations that failed with a
+	// transient error. Only populated when Config.DriverRetryPolicy is set.
+	DriverRetries *HealthStatusDriverRetries `json:"driver_retries,omitempty"`
+
+	// FetchBatchSizes contains statistics on the number of jobs requested in
+	// each fetch by each queue's producer, keyed by queue name. Only populated
+	// when Config.AdaptiveFetchBatchSize is set.
+	FetchBatchSizes map[string]*HealthStatusFetchBatchSize `json:"fetch_batch_sizes,omitempty"`
+
+	// Healthy is true if the check succeeded. When false, Problems contains a
+	// human-readable explanation of each reason why.
+	Healthy bool `json:"healthy"`
+
+	// InsertPayloadSizes is the distribution of the sizes of job payloads
+	// inserted by the client since it was created, along with the number of
+	// payloads that exceeded limits configured in Config.PayloadSizeLimits.
+	InsertPayloadSizes *HealthStatusPayloadSizes `json:"insert_payload_sizes,omitempty"`
+
+	// IsLeader is whether the client is currently the elected leader. This is
+	// informational only and doesn't affect Healthy, because only one client
+	// in a cluster is ever leader.
+	IsLeader bool `json:"is_leader"`
+
+	// NotifierConnected is whether the client's notifier has an established
+	// listen connection. Always false for clients in poll only mode or which
+	// aren't configured to work jobs.
+	NotifierConnected bool `json:"notifier_connected"`
+
+	// PollOnly is whether the client is running without a notifier, either
+	// because it was configured with PollOnly or its driver doesn't support a
+	// listener.
+	PollOnly bool `json:"poll_only"`
+
+	// Problems is a list of human-readable reasons that the check failed.
+	// Empty if Healthy is true.
+	Problems []string `json:"problems,omitempty"`
+
+	// ProducersRunning is the number of queue producers that are currently
+	// running.
+	ProducersRunning int `json:"producers_running"`
+
+	// ProducersTotal is the total number of queue producers configured on the
+	// client.
+	ProducersTotal int `json:"producers_total"`
+
+	// Started is whether the client has been started and hasn't yet stopped.
+	Started bool `json:"started"`
+
+	// UnknownJobKindsFetched is the number of jobs the client has fetched since
+	// it was created whose kind had no registered worker. A growing count
+	// suggests that jobs are being inserted to queues worked by clients that
+	// don't have their workers registered. What happens to these jobs is
+	// determined by Config.UnknownJobKindPolicy.
+	UnknownJobKindsFetched int64 `json:"unknown_job_kinds_fetched"`
+}
+
+// HealthStatusCompleterBackpressure contains the state of
+// Config.CompleterBackpressure as part of a HealthStatus.
+type HealthStatusCompleterBackpressure struct {
+	// Backlog is the number of job completions waiting to be written to the
+	// database.
+	Backlog int `json:"backlog"`
+
+	// Engaged is whether backpressure is currently engaged, in which case the
+	// client isn't fetching new jobs.
+	Engaged bool `json:"engaged"`
+
+	// NumEngaged is the number of times backpressure has engaged since the
+	// client was created.
+	NumEngaged int64 `json:"num_engaged"`
+}
+
+// HealthStatusDatabase contains information about a client's database
+// connectivity as part of a HealthStatus.
+type HealthStatusDatabase struct {
+	// Error is the error that occurred while checking the database, if any.
+	Error string `json:"error,omitempty"`
+
+	// Reachable is whether the database could be successfully queried.
+	Reachable bool `json:"reachable"`
+
+	// SchemaVersion is the most recently applied version of River's main
+	// migration line in the client's schema. Zero if no migrations have been
+	// applied or the database isn't reachable.
+	SchemaVersion int `json:"schema_version"`
+}
+
+// HealthStatusDriverRetries contains counts of retried database operations as
+// part of a HealthStatus.
+type HealthStatusDriverRetries struct {
+	// NumExhausted is the number of operations that failed with a transient
+	// error on every attempt allowed by the retry policy.
+	NumExhausted int64 `json:"num_exhausted"`
+
+	// NumRetries is the total number of retries made.
+	NumRetries int64 `json:"num_retries"`
+
+	// NumSucceededAfterRetry is the number of operations that succeeded after
+	// being retried at least once.
+	NumSucceededAfterRetry int64 `json:"num_succeeded_after_retry"`
+}
+
+// HealthStatusFetchBatchSize contains statistics on the batch sizes chosen by
+// a producer with Config.AdaptiveFetchBatchSize as part of a HealthStatus.
+type HealthStatusFetchBatchSize struct {
+	// Last is the number of jobs requested in the most recent fetch.
+	Last int `json:"last"`
+
+	// Max is the largest number of jobs requested in a fetch.
+	Max int `json:"max"`
+
+	// Min is the smallest number of jobs requested in a fetch.
+	Min int `json:"min"`
+
+	// NumFetches is the number of fetches made.
+	NumFetches int64 `json:"num_fetches"`
+}
+
+// HealthStatusPayloadSizes contains the distribution of inserted job payload
+// sizes as part of a HealthStatus.
+type HealthStatusPayloadSizes struct {
+	// Args counts inserted jobs by the size of their encoded args.
+	Args []HealthStatusPayloadSizeBucket `json:"args"`
+
+	// Metadata counts inserted jobs by the size of their metadata.
+	Metadata []HealthStatusPayloadSizeBucket `json:"metadata"`
+
+	// NumOffloaded is the number of payloads offloaded because they exceeded
+	// their maximum size.
+	NumOffloaded int64 `json:"num_offloaded"`
+
+	// NumRejected is the number of payloads rejected because they exceeded
+	// their maximum size.
+	NumRejected int64 `json:"num_rejected"`
+
+	// NumTruncated is the number of payloads truncated because they exceeded
+	// their maximum size.
+	NumTruncated int64 `json:"num_truncated"`
+}
+
+// HealthStatusPayloadSizeBucket is a count of inserted payloads whose size
+// fell within a bucket.
+type HealthStatusPayloadSizeBucket struct {
+	// Count is the number of payloads in the bucket.
+	Count int64 `json:"count"`
+
+	// UpToBytes is the inclusive upper bound of payload sizes in the bucket,
+	// with the lower bound being the previous bucket's upper bound. Zero for
+	// the last bucket, which is unbounded.
+	UpToBytes int `json:"up_to_bytes"`
+}
+
+// Liveness checks whether the client is alive, returning a structured health
+// status. A client configured to work jobs is alive if it's been started and
+// all its producers are running. Liveness doesn't interact with the database,
+// making it suitable for a Kubernetes liveness probe where a failure results
+// in the process being restarted.
+//
+// Clients that aren't configured to work jobs (i.e. insert-only clients) are
+// always considered alive.
+func (c *Client[TTx]) Liveness(_ context.Context) *HealthStatus {
+	status := &HealthStatus{}
+	c.healthCheckLocal(status)
+	status.Healthy = len(status.Problems) < 1
+	return status
+}
+
+// LivenessHandler returns an http.Handler that runs Liveness and responds
+// with the resulting HealthStatus serialized as JSON. The response code is 200
+// if the client is alive and 503 otherwise.
+func (c *Client[TTx]) LivenessHandler() http.Handler {
+	return healthHandler(c.Liveness)
+}
+
+// Readiness checks whether the client is ready to work jobs, returning a
+// structured health status. In addition to the checks performed by Liveness,
+// readiness verifies that the database is reachable and River's migrations
+// have been applied, and that the notifier is connected for clients that
+// aren't running in poll only mode. It's suitable for a Kubernetes readiness
+// probe.
+func (c *Client[TTx]) Readiness(ctx context.Context) *HealthStatus {
+	status := &HealthStatus{}
+	c.healthCheckLocal(status)
+
+	status.Database = &HealthStatusDatabase{}
+	if err := c.healthCheckDatabase(ctx, status.Database); err != nil {
+		status.Database.Error = err.Error()
+		status.Problems = append(status.Problems, "database check failed: "+err.Error())
+	}
+
+	if status.DatabaseCircuitOpen {
+		status.Problems = append(status.Problems, "database circuit breaker is open")
+	}
+
+	if c.config.willExecuteJobs() && !status.PollOnly && !status.NotifierConnected {
+		status.Problems = append(status.Problems, "notifier isn't connected")
+	}
+
+	status.Healthy = len(status.Problems) < 1
+	return status
+}
+
+// ReadinessHandler returns an http.Handler that runs Readiness and responds
+// with the resulting HealthStatus serialized as JSON. The response code is 200
+// if the client is ready and 503 otherwise.
+func (c *Client[TTx]) ReadinessHandler() http.Handler {
+	return healthHandler(c.Readiness)
+}
+
+func (c *Client[TTx]) healthCheckDatabase(ctx context.Context, status *HealthStatusDatabase) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+
+	exec := c.driver.GetExecutor()
+
+	if err := exec.Ping(ctx); err != nil {
+		return err
+	}
+	status.Reachable = true
+
+	migrations, err := exec.MigrationGetByLine(ctx, &riverdriver.MigrationGetByLineParams{
+		Line:   riverdriver.MigrationLineMain,
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting migrations: %w", err)
+	}
+	if len(migrations) < 1 {
+		return fmt.Errorf("no migrations applied for line %q", riverdriver.MigrationLineMain)
+	}
+	status.SchemaVersion = migrations[len(migrations)-1].Version
+
+	return nil
+}
+
+// Populates the parts of a health status that can be determined without a
+// database round trip.
+func (c *Client[TTx]) healthCheckLocal(status *HealthStatus) {
+	// Populated for all clients because insert-only clients are often where
+	// oversized payloads originate.
+	status.InsertPayloadSizes = c.payloadSizeStats.toHealthStatus()
+
+	if c.driverRetryStats != nil {
+		status.DriverRetries = c.driverRetryStats.toHealthStatus()
+	}
+
+	if !c.config.willExecuteJobs() {
+		return
+	}
+
+	if c.completerBackpressure != nil {
+		status.CompleterBackpressure = c.completerBackpressure.toHealthStatus()
+	}
+
+	status.DatabaseCircuitOpen = c.databaseDegradation.IsCircuitOpen()
+	status.PollOnly = c.notifier == nil
+	if c.notifier != nil {
+		status.NotifierConnected = c.notifier.IsConnected()
+	}
+
+	status.IsLeader = c.elector.IsLeader()
+	status.UnknownJobKindsFetched = c.unknownJobKindsFetched.Load()
+
+	status.Started = serviceIsRunning(&c.baseStartStop)
+	if !status.Started {
+		status.Problems = append(status.Problems, "client isn't started")
+	}
+
+	c.producersMu.RLock()
+	defer c.producersMu.RUnlock()
+
+	status.ProducersTotal = len(c.producersByQueueName)
+
+	// Iterate in a stable order so that problems are reported consistently.
+	queues := maputil.Keys(c.producersByQueueName)
+	slices.Sort(queues)
+
+	for _, queue := range queues {
+		if sizer := c.producersByQueueName[queue].fetchBatchSizer; sizer != nil {
+			if status.FetchBatchSizes == nil {
+				status.FetchBatchSizes = make(map[string]*HealthStatusFetchBatchSize, len(queues))
+			}
+			status.FetchBatchSizes[queue] = sizer.snapshot()
+		}
+
+		if serviceIsRunning(c.producersByQueueName[queue]) {
+			status.ProducersRunning++
+		} else if status.Started {
+			status.Problems = append(status.Problems, fmt.Sprintf("producer for queue %q isn't running", queue))
+		}
+	}
+}
+
+func healthHandler(checkFunc func(ctx context.Context) *HealthStatus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := checkFunc(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if status.Healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// Returns true if the given service has finished starting and hasn't yet
+// stopped.
+func serviceIsRunning(svc interface {
+	Started() <-chan struct{}
+	Stopped() <-chan struct{}
+},
+) bool {
+	select {
+	case <-svc.Stopped():
+		return false
+	default:
+	}
+
+	select {
+	case <-svc.Started():
+		return true
+	default:
+		return false
+	}
+}
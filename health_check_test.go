@@ -0,0 +1,169 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestClientHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config *Config
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		return &testBundle{
+			config: config,
+			dbPool: dbPool,
+		}
+	}
+
+	// Poll only mode is used in most tests so that readiness doesn't depend on
+	// the notifier having finished connecting asynchronously.
+	setupPollOnly := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		bundle := setup(t)
+		bundle.config.PollOnly = true
+
+		return newTestClient(t, bundle.dbPool, bundle.config), bundle
+	}
+
+	t.Run("LivenessStarted", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setupPollOnly(t)
+
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		status := client.Liveness(ctx)
+		require.True(t, status.Healthy)
+		require.Nil(t, status.Database)
+		require.Empty(t, status.Problems)
+		require.True(t, status.PollOnly)
+		require.Equal(t, 1, status.ProducersRunning)
+		require.Equal(t, 1, status.ProducersTotal)
+		require.True(t, status.Started)
+	})
+
+	t.Run("LivenessNotStarted", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setupPollOnly(t)
+
+		status := client.Liveness(ctx)
+		require.False(t, status.Healthy)
+		require.Equal(t, []string{"client isn't started"}, status.Problems)
+		require.Equal(t, 0, status.ProducersRunning)
+		require.Equal(t, 1, status.ProducersTotal)
+		require.False(t, status.Started)
+	})
+
+	t.Run("LivenessInsertOnlyClient", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		bundle.config.Queues = nil
+		bundle.config.Workers = nil
+
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		status := client.Liveness(ctx)
+		require.True(t, status.Healthy)
+		require.Empty(t, status.Problems)
+	})
+
+	t.Run("ReadinessStarted", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setupPollOnly(t)
+
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		status := client.Readiness(ctx)
+		require.True(t, status.Healthy)
+		require.Empty(t, status.Problems)
+		require.NotNil(t, status.Database)
+		require.Empty(t, status.Database.Error)
+		require.True(t, status.Database.Reachable)
+		require.Positive(t, status.Database.SchemaVersion)
+	})
+
+	t.Run("ReadinessDatabaseError", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+		bundle.config.Queues = nil
+		bundle.config.Schema = "health_check_schema_does_not_exist"
+		bundle.config.Workers = nil
+
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		status := client.Readiness(ctx)
+		require.False(t, status.Healthy)
+		require.Len(t, status.Problems, 1)
+		require.NotEmpty(t, status.Database.Error)
+		require.True(t, status.Database.Reachable)
+		require.Zero(t, status.Database.SchemaVersion)
+	})
+
+	t.Run("LivenessHandlerUnhealthy", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setupPollOnly(t)
+
+		recorder := httptest.NewRecorder()
+		client.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var status HealthStatus
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		require.False(t, status.Healthy)
+		require.Equal(t, []string{"client isn't started"}, status.Problems)
+	})
+
+	t.Run("ReadinessHandlerHealthy", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setupPollOnly(t)
+
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		recorder := httptest.NewRecorder()
+		client.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var status HealthStatus
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		require.True(t, status.Healthy)
+		require.True(t, status.Database.Reachable)
+	})
+}
@@ -23,6 +23,16 @@ func (f HookInsertBeginFunc) InsertBegin(ctx context.Context, params *rivertype.
 
 func (f HookInsertBeginFunc) IsHook() bool { return true }
 
+// HookInsertMetadataFunc is a convenience helper for implementing
+// rivertype.HookInsertMetadata using a simple function instead of a struct.
+type HookInsertMetadataFunc func(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error)
+
+func (f HookInsertMetadataFunc) InsertMetadata(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error) {
+	return f(ctx, params)
+}
+
+func (f HookInsertMetadataFunc) IsHook() bool { return true }
+
 // HookPeriodicJobsStartFunc is a convenience helper for implementing
 // rivertype.HookPeriodicJobsStart using a simple function instead of a struct.
 type HookPeriodicJobsStartFunc func(ctx context.Context, params *rivertype.HookPeriodicJobsStartParams) error
@@ -11,6 +11,9 @@ var (
 	_ rivertype.Hook            = HookInsertBeginFunc(func(ctx context.Context, params *rivertype.JobInsertParams) error { return nil })
 	_ rivertype.HookInsertBegin = HookInsertBeginFunc(func(ctx context.Context, params *rivertype.JobInsertParams) error { return nil })
 
+	_ rivertype.Hook               = HookInsertMetadataFunc(func(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error) { return nil, nil })
+	_ rivertype.HookInsertMetadata = HookInsertMetadataFunc(func(ctx context.Context, params *rivertype.JobInsertParams) (map[string]any, error) { return nil, nil })
+
 	_ rivertype.Hook                  = HookPeriodicJobsStartFunc(func(ctx context.Context, params *rivertype.HookPeriodicJobsStartParams) error { return nil })
 	_ rivertype.HookPeriodicJobsStart = HookPeriodicJobsStartFunc(func(ctx context.Context, params *rivertype.HookPeriodicJobsStartParams) error { return nil })
 
@@ -0,0 +1,291 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+)
+
+const (
+	insertAsyncBatchSizeDefault     = 1_000
+	insertAsyncFlushIntervalDefault = 100 * time.Millisecond
+
+	// Maximum amount of time that buffered jobs are given to be inserted when
+	// the client is stopping.
+	insertAsyncStopFlushTimeout = 10 * time.Second
+)
+
+// InsertAsyncConfig configures the batching of jobs inserted with
+// Client.InsertAsync.
+type InsertAsyncConfig struct {
+	// BatchSize is the maximum number of jobs inserted in a single batch. Once
+	// this many jobs are buffered, they're inserted immediately instead of
+	// waiting for FlushInterval to elapse.
+	//
+	// Defaults to 1,000.
+	BatchSize int
+
+	// FlushErrorFunc is invoked with the jobs of a batch that failed to insert
+	// along with the error that caused the failure. It can be used to log the
+	// failure or retry the jobs elsewhere. Failures are also logged by the
+	// client.
+	FlushErrorFunc func(ctx context.Context, params []InsertManyParams, err error)
+
+	// FlushInterval is the maximum amount of time that a job is buffered
+	// before being inserted.
+	//
+	// Defaults to 100 milliseconds.
+	FlushInterval time.Duration
+
+	// MaxBuffered is the maximum number of jobs that may be buffered waiting
+	// to be inserted, including those of batches currently being inserted.
+	// Once reached, InsertAsync blocks until there's room or its context is
+	// done, limiting memory use when jobs are buffered faster than they can be
+	// inserted. Must be at least BatchSize.
+	//
+	// Defaults to ten times BatchSize.
+	MaxBuffered int
+}
+
+func (c *InsertAsyncConfig) validate() error {
+	if c.BatchSize < 0 {
+		return errors.New("InsertAsync.BatchSize cannot be less than zero")
+	}
+	if c.FlushInterval < 0 {
+		return errors.New("InsertAsync.FlushInterval cannot be less than zero")
+	}
+	if c.MaxBuffered < 0 {
+		return errors.New("InsertAsync.MaxBuffered cannot be less than zero")
+	}
+	if c.MaxBuffered > 0 && c.MaxBuffered < cmp.Or(c.BatchSize, insertAsyncBatchSizeDefault) {
+		return errors.New("InsertAsync.MaxBuffered must be at least InsertAsync.BatchSize")
+	}
+	return nil
+}
+
+func (c *InsertAsyncConfig) withDefaults() *InsertAsyncConfig {
+	if c == nil {
+		c = &InsertAsyncConfig{}
+	}
+
+	batchSize := cmp.Or(c.BatchSize, insertAsyncBatchSizeDefault)
+
+	return &InsertAsyncConfig{
+		BatchSize:      batchSize,
+		FlushErrorFunc: c.FlushErrorFunc,
+		FlushInterval:  cmp.Or(c.FlushInterval, insertAsyncFlushIntervalDefault),
+		MaxBuffered:    cmp.Or(c.MaxBuffered, 10*batchSize),
+	}
+}
+
+// insertAsyncBatcher buffers jobs given to Client.InsertAsync and inserts them
+// in batches. Buffered jobs are inserted once a full batch has accumulated or
+// FlushInterval elapses, whichever comes first.
+//
+// It's started lazily on the first InsertAsync rather than with the client
+// because clients used only for inserts are never started.
+type insertAsyncBatcher struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	config    *InsertAsyncConfig
+	flushFunc func(ctx context.Context, params []InsertManyParams) (int, error)
+
+	buffer   []InsertManyParams
+	bufferMu sync.Mutex
+
+	// Serializes flushes so that a call to Flush waits on any batch that's
+	// already being inserted from the run loop.
+	flushMu sync.Mutex
+
+	// Signals the run loop that a full batch has accumulated.
+	flushNow chan struct{}
+
+	// Semaphore with one slot per job allowed to be buffered, applying back
+	// pressure once MaxBuffered is reached. Slots are released once a job's
+	// batch has been inserted.
+	slots chan struct{}
+}
+
+func newInsertAsyncBatcher(archetype *baseservice.Archetype, config *InsertAsyncConfig, flushFunc func(ctx context.Context, params []InsertManyParams) (int, error)) *insertAsyncBatcher {
+	config = config.withDefaults()
+
+	return baseservice.Init(archetype, &insertAsyncBatcher{
+		config:    config,
+		flushFunc: flushFunc,
+		flushNow:  make(chan struct{}, 1),
+		slots:     make(chan struct{}, config.MaxBuffered),
+	})
+}
+
+// Add buffers a job to be inserted, blocking if MaxBuffered jobs are already
+// buffered until there's room or the context is done.
+func (b *insertAsyncBatcher) Add(ctx context.Context, params InsertManyParams) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case b.slots <- struct{}{}:
+	}
+
+	b.bufferMu.Lock()
+	b.buffer = append(b.buffer, params)
+	batchFull := len(b.buffer) >= b.config.BatchSize
+	b.bufferMu.Unlock()
+
+	if batchFull {
+		select {
+		case b.flushNow <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush synchronously inserts all buffered jobs, waiting on any batch already
+// being inserted. Returns the errors of any batches that failed.
+func (b *insertAsyncBatcher) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	var errs []error
+	for {
+		b.bufferMu.Lock()
+		batch := b.buffer[:min(len(b.buffer), b.config.BatchSize)]
+		b.buffer = b.buffer[len(batch):]
+		b.bufferMu.Unlock()
+
+		if len(batch) < 1 {
+			return errors.Join(errs...)
+		}
+
+		if err := b.flushBatch(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+func (b *insertAsyncBatcher) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := b.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		// Inserts aren't cancelled when the batcher is stopped so that a batch
+		// in progress is given a chance to finish.
+		flushCtx := context.WithoutCancel(ctx)
+
+		ticker := time.NewTicker(b.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				// Insert anything still buffered before stopping. Errors have
+				// already been logged and passed to FlushErrorFunc.
+				flushCtx, cancel := context.WithTimeout(flushCtx, insertAsyncStopFlushTimeout)
+				defer cancel()
+				_ = b.Flush(flushCtx)
+				return
+
+			case <-b.flushNow:
+			case <-ticker.C:
+			}
+
+			_ = b.Flush(flushCtx)
+		}
+	}()
+
+	return nil
+}
+
+func (b *insertAsyncBatcher) flushBatch(ctx context.Context, batch []InsertManyParams) error {
+	_, err := b.flushFunc(ctx, batch)
+
+	for range batch {
+		<-b.slots
+	}
+
+	if err != nil {
+		b.Logger.ErrorContext(ctx, b.Name+": Error inserting batch of jobs",
+			slog.String("err", err.Error()),
+			slog.Int("num_jobs", len(batch)),
+		)
+
+		if b.config.FlushErrorFunc != nil {
+			b.config.FlushErrorFunc(ctx, batch, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// InsertAsync buffers a job to be inserted in the background along with other
+// buffered jobs in a single batch, avoiding a database round trip per job for
+// high throughput producers. Job opts can be used to override any defaults
+// that may have been provided by an implementation of
+// JobArgsWithInsertOpts.InsertOpts, as well as any global defaults. Batching is
+// configured with Config.InsertAsync.
+//
+//	if err := client.InsertAsync(ctx, MyArgs{}, nil); err != nil {
+//		// handle error
+//	}
+//
+// Args are validated immediately, but because the job is inserted later, a
+// nil error doesn't guarantee that it'll be inserted successfully. Batches
+// that fail to insert are logged and passed to InsertAsyncConfig.FlushErrorFunc.
+// Batches are inserted like InsertManyFast, so unique jobs that conflict with
+// an existing job are skipped as usual, but inserted jobs aren't returned.
+//
+// The provided context is only used while waiting for room in the buffer if
+// InsertAsyncConfig.MaxBuffered jobs are already buffered.
+//
+// Buffered jobs are inserted when a started client is stopped. Clients that
+// are only used for inserts and never started should call InsertAsyncFlush
+// before exiting so that buffered jobs aren't lost.
+func (c *Client[TTx]) InsertAsync(ctx context.Context, args JobArgs, opts *InsertOpts) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	params := InsertManyParams{Args: args, InsertOpts: opts}
+
+	// Validate immediately so that invalid args are returned as an error to
+	// the caller instead of failing the entire batch they end up in.
+	if _, err := c.insertManyParams(ctx, []InsertManyParams{params}); err != nil {
+		return err
+	}
+
+	if err := c.insertAsyncBatcher.Start(context.Background()); err != nil {
+		return err
+	}
+
+	return c.insertAsyncBatcher.Add(ctx, params)
+}
+
+// InsertAsyncFlush synchronously inserts all jobs buffered by InsertAsync,
+// including waiting on any batch that's already being inserted. It returns the
+// errors of any batches that failed to insert.
+//
+//	if err := client.InsertAsyncFlush(ctx); err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) InsertAsyncFlush(ctx context.Context) error {
+	return c.insertAsyncBatcher.Flush(ctx)
+}
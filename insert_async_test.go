@@ -0,0 +1,261 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+)
+
+func TestInsertAsyncConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&InsertAsyncConfig{}).validate())
+	require.NoError(t, (&InsertAsyncConfig{BatchSize: 10, FlushInterval: time.Second, MaxBuffered: 10}).validate())
+
+	require.EqualError(t, (&InsertAsyncConfig{BatchSize: -1}).validate(), "InsertAsync.BatchSize cannot be less than zero")
+	require.EqualError(t, (&InsertAsyncConfig{FlushInterval: -1}).validate(), "InsertAsync.FlushInterval cannot be less than zero")
+	require.EqualError(t, (&InsertAsyncConfig{MaxBuffered: -1}).validate(), "InsertAsync.MaxBuffered cannot be less than zero")
+	require.EqualError(t, (&InsertAsyncConfig{BatchSize: 10, MaxBuffered: 9}).validate(), "InsertAsync.MaxBuffered must be at least InsertAsync.BatchSize")
+	require.EqualError(t, (&InsertAsyncConfig{MaxBuffered: 999}).validate(), "InsertAsync.MaxBuffered must be at least InsertAsync.BatchSize")
+}
+
+func TestInsertAsyncBatcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		batches   chan []InsertManyParams
+		flushErr  error
+		flushErrs chan error
+	}
+
+	setup := func(t *testing.T, config *InsertAsyncConfig) (*insertAsyncBatcher, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{
+			batches:   make(chan []InsertManyParams, 100),
+			flushErrs: make(chan error, 100),
+		}
+
+		config.FlushErrorFunc = func(ctx context.Context, params []InsertManyParams, err error) {
+			bundle.flushErrs <- err
+		}
+
+		batcher := newInsertAsyncBatcher(riversharedtest.BaseServiceArchetype(t), config, func(ctx context.Context, params []InsertManyParams) (int, error) {
+			bundle.batches <- params
+			return len(params), bundle.flushErr
+		})
+
+		return batcher, bundle
+	}
+
+	startBatcher := func(t *testing.T, batcher *insertAsyncBatcher) {
+		t.Helper()
+
+		require.NoError(t, batcher.Start(ctx))
+		t.Cleanup(batcher.Stop)
+	}
+
+	t.Run("FlushesFullBatch", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, bundle := setup(t, &InsertAsyncConfig{BatchSize: 3, FlushInterval: time.Hour})
+		startBatcher(t, batcher)
+
+		for range 3 {
+			require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+		}
+
+		require.Len(t, riversharedtest.WaitOrTimeout(t, bundle.batches), 3)
+	})
+
+	t.Run("FlushesOnInterval", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, bundle := setup(t, &InsertAsyncConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+		startBatcher(t, batcher)
+
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+
+		require.Len(t, riversharedtest.WaitOrTimeout(t, bundle.batches), 1)
+	})
+
+	t.Run("FlushSplitsIntoBatches", func(t *testing.T) {
+		t.Parallel()
+
+		// Not started so that only the explicit flush inserts.
+		batcher, bundle := setup(t, &InsertAsyncConfig{BatchSize: 2})
+
+		for range 5 {
+			require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+		}
+
+		require.NoError(t, batcher.Flush(ctx))
+
+		require.Len(t, <-bundle.batches, 2)
+		require.Len(t, <-bundle.batches, 2)
+		require.Len(t, <-bundle.batches, 1)
+		require.Empty(t, bundle.batches)
+	})
+
+	t.Run("FlushesOnStop", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, bundle := setup(t, &InsertAsyncConfig{BatchSize: 100, FlushInterval: time.Hour})
+		require.NoError(t, batcher.Start(ctx))
+
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+
+		batcher.Stop()
+
+		require.Len(t, riversharedtest.WaitOrTimeout(t, bundle.batches), 1)
+	})
+
+	t.Run("FlushError", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, bundle := setup(t, &InsertAsyncConfig{})
+		bundle.flushErr = errors.New("flush error")
+
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+
+		require.ErrorIs(t, batcher.Flush(ctx), bundle.flushErr)
+		require.ErrorIs(t, riversharedtest.WaitOrTimeout(t, bundle.flushErrs), bundle.flushErr)
+
+		// Slots are released even though the batch failed.
+		require.Empty(t, batcher.slots)
+	})
+
+	t.Run("BlocksAtMaxBuffered", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, _ := setup(t, &InsertAsyncConfig{BatchSize: 2, MaxBuffered: 2})
+
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+
+		addCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, batcher.Add(addCtx, InsertManyParams{Args: noOpArgs{}}), context.DeadlineExceeded)
+
+		// Room is made once buffered jobs are inserted.
+		require.NoError(t, batcher.Flush(ctx))
+		require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+	})
+
+	t.Run("ConcurrentAdds", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, bundle := setup(t, &InsertAsyncConfig{BatchSize: 10, FlushInterval: time.Millisecond, MaxBuffered: 20})
+		startBatcher(t, batcher)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 20 {
+					require.NoError(t, batcher.Add(ctx, InsertManyParams{Args: noOpArgs{}}))
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.NoError(t, batcher.Flush(ctx))
+
+		var numInserted int
+		for len(bundle.batches) > 0 {
+			numInserted += len(<-bundle.batches)
+		}
+		require.Equal(t, 100, numInserted)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		batcher, _ := setup(t, &InsertAsyncConfig{})
+
+		startstoptest.Stress(ctx, t, batcher)
+	})
+}
+
+func Test_Client_InsertAsync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("InsertsOnFlush", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.InsertAsync = &InsertAsyncConfig{FlushInterval: time.Hour}
+
+		client := runNewTestClient(ctx, t, config)
+
+		for range 3 {
+			require.NoError(t, client.InsertAsync(ctx, noOpArgs{}, nil))
+		}
+		require.NoError(t, client.InsertAsync(ctx, noOpArgs{}, &InsertOpts{Queue: "other"}))
+
+		require.NoError(t, client.InsertAsyncFlush(ctx))
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(noOpArgs{}).Kind()},
+			Schema: client.config.Schema,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs, 4)
+	})
+
+	t.Run("InsertsOnStop", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.InsertAsync = &InsertAsyncConfig{FlushInterval: time.Hour}
+
+		client := runNewTestClient(ctx, t, config)
+
+		require.NoError(t, client.InsertAsync(ctx, noOpArgs{}, nil))
+
+		// Producers are stopped before buffered jobs are inserted, so the job
+		// isn't worked.
+		require.NoError(t, client.Stop(ctx))
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(noOpArgs{}).Kind()},
+			Schema: client.config.Schema,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+	})
+
+	t.Run("InvalidArgsReturnedImmediately", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+
+		client := runNewTestClient(ctx, t, config)
+
+		require.ErrorContains(t, client.InsertAsync(ctx, noOpArgs{}, &InsertOpts{Queue: "invalid*queue"}), "queue name is invalid")
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, client.InsertAsync(ctx, noOpArgs{}, nil), errNoDriverDBPool)
+	})
+}
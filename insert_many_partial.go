@@ -0,0 +1,139 @@
+package river
+
+import (
+	"context"
+	"errors"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// InsertManyPartialResult is the result of inserting a single job with
+// Client.InsertManyPartial or Client.InsertManyPartialTx.
+type InsertManyPartialResult struct {
+	// Err is the error that prevented the job from being inserted, like one
+	// from failed validation of its args or insert options. It's nil if the
+	// job was inserted or skipped as a unique duplicate.
+	Err error
+
+	// Job is the inserted job, or if the job was skipped as a unique
+	// duplicate, the existing job that it duplicates. Nil if Err is set.
+	Job *rivertype.JobRow
+
+	// UniqueSkippedAsDuplicate indicates that the job wasn't inserted because
+	// it was a duplicate of an existing unique job.
+	UniqueSkippedAsDuplicate bool
+}
+
+// InsertManyPartial inserts many jobs at once like InsertMany, but instead of
+// failing the whole batch when any job fails validation, it inserts the jobs
+// that are valid and returns a result for every job indicating whether it was
+// inserted, skipped as a unique duplicate, or failed with an error. Results
+// are in the same order as params. It's useful for bulk importers that'd
+// rather report bad rows than reject a whole import because of them.
+//
+//	results, err := client.InsertManyPartial(ctx, []river.InsertManyParams{
+//		{Args: ImportRowArgs{Row: 1}},
+//		{Args: ImportRowArgs{Row: 2}},
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+//	for i, result := range results {
+//		if result.Err != nil {
+//			// handle invalid row i
+//		}
+//	}
+//
+// Only errors that occur while jobs are validated and prepared for insertion
+// are reported per job. Valid jobs are inserted with a single database
+// operation, so an error from the database fails all of them, and is returned
+// as the second return value. Use InsertMany instead to insert either all jobs
+// or none of them.
+func (c *Client[TTx]) InsertManyPartial(ctx context.Context, params []InsertManyParams) ([]*InsertManyPartialResult, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	var (
+		insertResults []*rivertype.JobInsertResult
+		results       []*InsertManyPartialResult
+	)
+	if err := dbutil.WithTx(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		var err error
+		results, insertResults, err = c.insertManyPartial(ctx, execTx, params)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(insertResults) > 0 {
+		c.notifyProducerWithoutListenerJobFetch(ctx, insertResults)
+	}
+
+	return results, nil
+}
+
+// InsertManyPartialTx inserts many jobs at once like InsertManyTx, but instead
+// of failing the whole batch when any job fails validation, it inserts the
+// jobs that are valid and returns a result for every job indicating whether it
+// was inserted, skipped as a unique duplicate, or failed with an error. See
+// InsertManyPartial for details.
+//
+// This variant lets a caller insert jobs atomically alongside other database
+// changes. An inserted job isn't visible to be worked until the transaction
+// commits, and if the transaction rolls back, so too are the inserted jobs.
+func (c *Client[TTx]) InsertManyPartialTx(ctx context.Context, tx TTx, params []InsertManyParams) ([]*InsertManyPartialResult, error) {
+	results, _, err := c.insertManyPartial(ctx, c.driver.UnwrapExecutor(tx), params)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Shared code path for InsertManyPartial and InsertManyPartialTx. In addition
+// to per-job results, returns the results of jobs that were inserted.
+func (c *Client[TTx]) insertManyPartial(ctx context.Context, execTx riverdriver.ExecutorTx, params []InsertManyParams) ([]*InsertManyPartialResult, []*rivertype.JobInsertResult, error) {
+	if c.config.ReadOnly {
+		return nil, nil, ErrClientReadOnly
+	}
+
+	if len(params) < 1 {
+		return nil, nil, errors.New("no jobs to insert")
+	}
+
+	var (
+		insertIndexes = make([]int, 0, len(params))
+		insertParams  = make([]*rivertype.JobInsertParams, 0, len(params))
+		results       = make([]*InsertManyPartialResult, len(params))
+	)
+	for i, param := range params {
+		insertParamsItem, err := c.insertManyParamsItem(ctx, param)
+		if err != nil {
+			results[i] = &InsertManyPartialResult{Err: err}
+			continue
+		}
+
+		insertIndexes = append(insertIndexes, i)
+		insertParams = append(insertParams, insertParamsItem)
+	}
+
+	if len(insertParams) < 1 {
+		return results, nil, nil
+	}
+
+	insertResults, err := c.insertMany(ctx, execTx, insertParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, insertResult := range insertResults {
+		results[insertIndexes[i]] = &InsertManyPartialResult{
+			Job:                      insertResult.Job,
+			UniqueSkippedAsDuplicate: insertResult.UniqueSkippedAsDuplicate,
+		}
+	}
+
+	return results, insertResults, nil
+}
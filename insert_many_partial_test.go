@@ -0,0 +1,133 @@
+package river
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_Client_InsertManyPartial(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		return newTestClient(t, dbPool, config), &testBundle{dbPool: dbPool}
+	}
+
+	t.Run("InsertsValidJobsAndReportsErrors", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		results, err := client.InsertManyPartial(ctx, []InsertManyParams{
+			{Args: noOpArgs{Name: "first"}},
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Priority: 5}},
+			{Args: unregisteredJobArgs{}},
+			{Args: noOpArgs{Name: "last"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+
+		require.NoError(t, results[0].Err)
+		require.JSONEq(t, `{"name":"first"}`, string(results[0].Job.EncodedArgs))
+		require.False(t, results[0].UniqueSkippedAsDuplicate)
+
+		require.EqualError(t, results[1].Err, "priority must be between 1 and 4")
+		require.Nil(t, results[1].Job)
+
+		var unknownJobKindErr *UnknownJobKindError
+		require.ErrorAs(t, results[2].Err, &unknownJobKindErr)
+		require.Nil(t, results[2].Job)
+
+		require.NoError(t, results[3].Err)
+		require.JSONEq(t, `{"name":"last"}`, string(results[3].Job.EncodedArgs))
+
+		jobs, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Len(t, jobs.Jobs, 2)
+	})
+
+	t.Run("UniqueSkippedAsDuplicate", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		results, err := client.InsertManyPartial(ctx, []InsertManyParams{
+			{Args: noOpArgs{Name: "unique"}, InsertOpts: insertOpts},
+			{Args: noOpArgs{Name: "unique"}, InsertOpts: insertOpts},
+		})
+		require.NoError(t, err)
+		require.False(t, results[0].UniqueSkippedAsDuplicate)
+		require.True(t, results[1].UniqueSkippedAsDuplicate)
+		require.Equal(t, results[0].Job.ID, results[1].Job.ID)
+	})
+
+	t.Run("AllInvalid", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		results, err := client.InsertManyPartial(ctx, []InsertManyParams{
+			{Args: unregisteredJobArgs{}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Error(t, results[0].Err)
+	})
+
+	t.Run("ErrorsWithZeroJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.InsertManyPartial(ctx, []InsertManyParams{})
+		require.EqualError(t, err, "no jobs to insert")
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		results, err := client.InsertManyPartialTx(ctx, tx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: unregisteredJobArgs{}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, rivertype.JobStateAvailable, results[0].Job.State)
+		require.Error(t, results[1].Err)
+
+		jobs, err := client.JobListTx(ctx, tx, NewJobListParams())
+		require.NoError(t, err)
+		require.Len(t, jobs.Jobs, 1)
+	})
+}
@@ -23,6 +23,17 @@ var tagRE = regexp.MustCompile(`\A[\w][\w\-]+[\w]\z`)
 // insertion time. These will override any default InsertOpts settings provided
 // by JobArgsWithInsertOpts, as well as any global defaults.
 type InsertOpts struct {
+	// ExternalID is a unique identifier for the job that's suitable for
+	// exposing outside of the system in place of its sequential ID, like a
+	// UUID. It's stored in the job's metadata and is unique across all jobs,
+	// so inserting a job with an external ID that's already in use returns an
+	// error. Jobs can be looked up by external ID with
+	// Client.JobGetByExternalID.
+	//
+	// Defaults to an ID generated by Config.ExternalIDFunc if it's set, or no
+	// external ID otherwise.
+	ExternalID string
+
 	// MaxAttempts is the maximum number of total attempts (including both the
 	// original run and all retries) before a job is abandoned and set as
 	// discarded.
@@ -33,6 +44,26 @@ type InsertOpts struct {
 	// field by River.
 	Metadata []byte
 
+	// PartitionKey places the job in a partition with all other jobs sharing
+	// the same key. Jobs in a partition are worked strictly one at a time and
+	// in the order they were inserted: a job isn't fetched while another job
+	// in its partition is running, or while an earlier job in its partition
+	// hasn't yet reached a final state (completed, cancelled, or discarded).
+	// A failing job that's waiting to be retried holds up every job behind it
+	// until it completes or is discarded.
+	//
+	// Ordering is enforced across all queues and job kinds, so jobs sharing a
+	// key should generally be inserted into the same queue. It's stored in the
+	// job's metadata.
+	//
+	// Use of this option generally makes sense when it's derived from job args
+	// (e.g. a customer ID) in JobArgsWithInsertOpts, but it may be set when
+	// inserting as well, which takes precedence.
+	//
+	// Defaults to no partition key, which places no ordering constraints on
+	// the job.
+	PartitionKey string
+
 	// Pending indicates that the job should be inserted in the `pending` state.
 	// Pending jobs are not immediately available to be worked and are never
 	// deleted, but they can be used to indicate work which should be performed in
@@ -55,6 +86,20 @@ type InsertOpts struct {
 	// `JobArgsWithInsertOpts`, or QueueDefault if not.
 	Queue string
 
+	// RequiredLabels are labels that a client must have been configured with
+	// in Config.Labels, with matching values, to work the job. Clients lacking
+	// any of them won't fetch it, so it's worked only by clients that are
+	// suitable for it, like those with a GPU or in a particular region:
+	//
+	//	RequiredLabels: map[string]string{"gpu": "true"},
+	//
+	// It's stored in the job's metadata. If no running client has the
+	// required labels, the job remains available until one starts.
+	//
+	// Defaults to no required labels, which lets the job be worked by any
+	// client.
+	RequiredLabels map[string]string
+
 	// ScheduledAt is a time in future at which to schedule the job (i.e. in
 	// cases where it shouldn't be run immediately). The job is guaranteed not
 	// to run before this time, but may run slightly after depending on the
@@ -65,6 +110,14 @@ type InsertOpts struct {
 	// JobArgsWithInsertOpts, however, it will work in both cases.
 	ScheduledAt time.Time
 
+	// ScheduleIn schedules the job to run this long after it's inserted, as
+	// measured by the database's clock rather than the client's. Unlike
+	// ScheduledAt, which is computed with the local clock, it's unaffected by
+	// skew between the clocks of clients and the database.
+	//
+	// Can't be combined with ScheduledAt.
+	ScheduleIn time.Duration
+
 	// Tags are an arbitrary list of keywords to add to the job. They have no
 	// functional behavior and are meant entirely as a user-specified construct
 	// to help group and categorize jobs.
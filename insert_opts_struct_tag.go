@@ -0,0 +1,116 @@
+package river
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// insertOptsStructTagCache caches insert options parsed from struct tags for
+// each JobArgs type so that tags are only parsed once per type. Values are of
+// type *insertOptsStructTagResult.
+var insertOptsStructTagCache sync.Map //nolint:gochecknoglobals
+
+type insertOptsStructTagResult struct {
+	err        error
+	insertOpts *InsertOpts
+}
+
+// insertOptsFromStructTag extracts default insert options from a `river`
+// struct tag on a blank field of a job args struct:
+//
+//	type EmailArgs struct {
+//		_ struct{} `river:"queue=email,priority=2,max_attempts=5"`
+//
+//		To string `json:"to"`
+//	}
+//
+// Supported keys are `max_attempts`, `priority`, and `queue`. Returns nil if
+// the args type has no such tag.
+func insertOptsFromStructTag(args JobArgs) (*InsertOpts, error) {
+	typ := reflect.TypeOf(args)
+	if typ == nil {
+		return nil, nil
+	}
+
+	if cached, ok := insertOptsStructTagCache.Load(typ); ok {
+		res := cached.(*insertOptsStructTagResult) //nolint:forcetypeassert
+		return res.insertOpts, res.err
+	}
+
+	insertOpts, err := insertOptsFromStructTagUncached(typ)
+	if err != nil {
+		err = fmt.Errorf("error parsing `river` struct tag on %s: %w", typ, err)
+	}
+
+	insertOptsStructTagCache.Store(typ, &insertOptsStructTagResult{err: err, insertOpts: insertOpts})
+
+	return insertOpts, err
+}
+
+func insertOptsFromStructTagUncached(typ reflect.Type) (*InsertOpts, error) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var insertOpts *InsertOpts
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+
+		if field.Name != "_" {
+			continue
+		}
+
+		riverTag, ok := field.Tag.Lookup("river")
+		if !ok {
+			continue
+		}
+
+		if insertOpts != nil {
+			return nil, errors.New("only one blank field may carry a `river` struct tag")
+		}
+		insertOpts = &InsertOpts{}
+
+		for part := range strings.SplitSeq(riverTag, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("expected key=value, got %q", part)
+			}
+
+			switch key {
+			case "max_attempts":
+				maxAttempts, err := strconv.Atoi(value)
+				if err != nil || maxAttempts < 1 {
+					return nil, fmt.Errorf("max_attempts must be an integer greater than zero, got %q", value)
+				}
+				insertOpts.MaxAttempts = maxAttempts
+
+			case "priority":
+				priority, err := strconv.Atoi(value)
+				if err != nil || priority < 1 || priority > 4 {
+					return nil, fmt.Errorf("priority must be an integer between 1 and 4, got %q", value)
+				}
+				insertOpts.Priority = priority
+
+			case "queue":
+				if err := validateQueueName(value); err != nil {
+					return nil, err
+				}
+				insertOpts.Queue = value
+
+			default:
+				return nil, fmt.Errorf("unknown key %q", key)
+			}
+		}
+	}
+
+	return insertOpts, nil
+}
@@ -0,0 +1,91 @@
+package river
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type structTagInsertOptsArgs struct {
+	_ struct{} `river:"queue=email,priority=2,max_attempts=5"`
+
+	To string `json:"to"`
+}
+
+func (structTagInsertOptsArgs) Kind() string { return "struct_tag_insert_opts" }
+
+type structTagAndMethodInsertOptsArgs struct {
+	_ struct{} `river:"queue=email,priority=2,max_attempts=5"`
+}
+
+func (structTagAndMethodInsertOptsArgs) Kind() string { return "struct_tag_and_method_insert_opts" }
+
+func (structTagAndMethodInsertOptsArgs) InsertOpts() InsertOpts {
+	return InsertOpts{Queue: "other"}
+}
+
+type structTagInvalidInsertOptsArgs struct {
+	_ struct{} `river:"priority=5"`
+}
+
+func (structTagInvalidInsertOptsArgs) Kind() string { return "struct_tag_invalid_insert_opts" }
+
+func TestInsertOptsFromStructTag(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AllKeys", func(t *testing.T) {
+		t.Parallel()
+
+		insertOpts, err := insertOptsFromStructTag(structTagInsertOptsArgs{})
+		require.NoError(t, err)
+		require.Equal(t, &InsertOpts{MaxAttempts: 5, Priority: 2, Queue: "email"}, insertOpts)
+
+		// Pointers are handled the same way.
+		insertOpts, err = insertOptsFromStructTag(&structTagInsertOptsArgs{})
+		require.NoError(t, err)
+		require.Equal(t, &InsertOpts{MaxAttempts: 5, Priority: 2, Queue: "email"}, insertOpts)
+	})
+
+	t.Run("NoTag", func(t *testing.T) {
+		t.Parallel()
+
+		insertOpts, err := insertOptsFromStructTag(noOpArgs{})
+		require.NoError(t, err)
+		require.Nil(t, insertOpts)
+	})
+
+	t.Run("InvalidValues", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tt := range []struct {
+			tag     string
+			wantErr string
+		}{
+			{tag: "max_attempts=0", wantErr: `max_attempts must be an integer greater than zero, got "0"`},
+			{tag: "max_attempts=abc", wantErr: `max_attempts must be an integer greater than zero, got "abc"`},
+			{tag: "priority=5", wantErr: `priority must be an integer between 1 and 4, got "5"`},
+			{tag: "queue=", wantErr: "queue name cannot be empty"},
+			{tag: "queue=Invalid Queue", wantErr: `queue name is invalid, expected letters and numbers separated by underscores or hyphens: "Invalid Queue"`},
+			{tag: "queue", wantErr: `expected key=value, got "queue"`},
+			{tag: "unknown=1", wantErr: `unknown key "unknown"`},
+		} {
+			typ := reflect.StructOf([]reflect.StructField{
+				{Name: "_", PkgPath: "github.com/riverqueue/river", Type: reflect.TypeFor[struct{}](), Tag: reflect.StructTag(`river:"` + tt.tag + `"`)},
+			})
+
+			_, err := insertOptsFromStructTagUncached(typ)
+			require.EqualError(t, err, tt.wantErr, "tag: %s", tt.tag)
+		}
+	})
+
+	t.Run("ErrorCached", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := insertOptsFromStructTag(structTagInvalidInsertOptsArgs{})
+		require.EqualError(t, err, "error parsing `river` struct tag on river.structTagInvalidInsertOptsArgs: priority must be an integer between 1 and 4, got \"5\"")
+
+		_, err = insertOptsFromStructTag(structTagInvalidInsertOptsArgs{})
+		require.Error(t, err)
+	})
+}
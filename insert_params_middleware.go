@@ -0,0 +1,58 @@
+package river
+
+import (
+	"context"
+	"slices"
+)
+
+// InsertParamsMiddleware inspects and optionally mutates the args and insert
+// options of jobs before they're inserted when configured with
+// Config.InsertParamsMiddleware. It can be used to enforce organization-wide
+// conventions like required tags or metadata, or to reject malformed args, in
+// one place.
+//
+// Unlike rivertype.JobInsertMiddleware, which operates on fully resolved
+// insert params just before they're persisted, InsertParamsMiddleware runs
+// before insert options are merged with defaults from the job args and
+// client, so options it sets are subject to the usual precedence rules and
+// validation.
+type InsertParamsMiddleware interface {
+	// InsertParams is invoked with the params of each job being inserted, in
+	// the order that middleware was configured. InsertOpts is always non-nil
+	// and is a copy of the caller's options, so it may be modified freely.
+	// Returning an error aborts the insert, including the insert of any other
+	// jobs in the same batch, and skips any remaining middleware.
+	InsertParams(ctx context.Context, params *InsertManyParams) error
+}
+
+// InsertParamsMiddlewareFunc is a function that implements
+// InsertParamsMiddleware.
+type InsertParamsMiddlewareFunc func(ctx context.Context, params *InsertManyParams) error
+
+func (f InsertParamsMiddlewareFunc) InsertParams(ctx context.Context, params *InsertManyParams) error {
+	return f(ctx, params)
+}
+
+// runInsertParamsMiddleware runs configured insert params middleware on a copy
+// of params, returning the possibly modified copy.
+func (c *Config) runInsertParamsMiddleware(ctx context.Context, params InsertManyParams) (InsertManyParams, error) {
+	if len(c.InsertParamsMiddleware) < 1 {
+		return params, nil
+	}
+
+	insertOpts := InsertOpts{}
+	if params.InsertOpts != nil {
+		insertOpts = *params.InsertOpts
+		insertOpts.Metadata = slices.Clone(insertOpts.Metadata)
+		insertOpts.Tags = slices.Clone(insertOpts.Tags)
+	}
+	params.InsertOpts = &insertOpts
+
+	for _, middleware := range c.InsertParamsMiddleware {
+		if err := middleware.InsertParams(ctx, &params); err != nil {
+			return InsertManyParams{}, err
+		}
+	}
+
+	return params, nil
+}
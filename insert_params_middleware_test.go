@@ -0,0 +1,152 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestConfigRunInsertParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("NoMiddleware", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{}
+
+		params, err := config.runInsertParamsMiddleware(ctx, InsertManyParams{Args: noOpArgs{}})
+		require.NoError(t, err)
+		require.Nil(t, params.InsertOpts)
+	})
+
+	t.Run("RunsInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			InsertParamsMiddleware: []InsertParamsMiddleware{
+				InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+					params.InsertOpts.Tags = append(params.InsertOpts.Tags, "first")
+					return nil
+				}),
+				InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+					params.InsertOpts.Tags = append(params.InsertOpts.Tags, "second")
+					return nil
+				}),
+			},
+		}
+
+		params, err := config.runInsertParamsMiddleware(ctx, InsertManyParams{Args: noOpArgs{}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"first", "second"}, params.InsertOpts.Tags)
+	})
+
+	t.Run("DoesNotModifyCallerOpts", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			InsertParamsMiddleware: []InsertParamsMiddleware{
+				InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+					params.InsertOpts.Queue = "other"
+					params.InsertOpts.Tags[0] = "changed"
+					return nil
+				}),
+			},
+		}
+
+		insertOpts := &InsertOpts{Tags: []string{"original"}}
+
+		params, err := config.runInsertParamsMiddleware(ctx, InsertManyParams{Args: noOpArgs{}, InsertOpts: insertOpts})
+		require.NoError(t, err)
+		require.Equal(t, "other", params.InsertOpts.Queue)
+		require.Equal(t, []string{"changed"}, params.InsertOpts.Tags)
+
+		require.Equal(t, &InsertOpts{Tags: []string{"original"}}, insertOpts)
+	})
+
+	t.Run("ErrorShortCircuits", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			InsertParamsMiddleware: []InsertParamsMiddleware{
+				InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+					return errors.New("rejected")
+				}),
+				InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+					require.FailNow(t, "second middleware should not have been invoked")
+					return nil
+				}),
+			},
+		}
+
+		_, err := config.runInsertParamsMiddleware(ctx, InsertManyParams{Args: noOpArgs{}})
+		require.EqualError(t, err, "rejected")
+	})
+}
+
+func Test_Client_InsertParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	setup := func(t *testing.T, middleware ...InsertParamsMiddleware) *Client[pgx.Tx] {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+		config.InsertParamsMiddleware = middleware
+
+		return newTestClient(t, dbPool, config)
+	}
+
+	requireTagMiddleware := InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+		params.InsertOpts.Tags = append(params.InsertOpts.Tags, "team_a")
+		return nil
+	})
+
+	t.Run("MutatesInsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t, requireTagMiddleware)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Tags: []string{"custom"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"custom", "team_a"}, insertRes.Job.Tags)
+
+		results, err := client.InsertMany(ctx, []InsertManyParams{{Args: noOpArgs{}}, {Args: noOpArgs{}}})
+		require.NoError(t, err)
+		for _, res := range results {
+			require.Equal(t, []string{"team_a"}, res.Job.Tags)
+		}
+	})
+
+	t.Run("ErrorAbortsBatch", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t, InsertParamsMiddlewareFunc(func(ctx context.Context, params *InsertManyParams) error {
+			if params.Args.(noOpArgs).Name == "invalid" { //nolint:forcetypeassert
+				return errors.New("invalid args")
+			}
+			return nil
+		}))
+
+		_, err := client.InsertMany(ctx, []InsertManyParams{{Args: noOpArgs{}}, {Args: noOpArgs{Name: "invalid"}}})
+		require.EqualError(t, err, "invalid args")
+
+		jobs, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Empty(t, jobs.Jobs)
+	})
+}
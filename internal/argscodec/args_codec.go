@@ -0,0 +1,346 @@
+// Package argscodec encodes and decodes job args with a pluggable codec,
+// optional compression, and optional encryption, recording the names of the
+// codec and compressor and the ID of the encryption key in job metadata so
+// that a job can be decoded by any client that knows about them.
+package argscodec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	// NameGzip is the name of the built-in gzip compressor.
+	NameGzip = "gzip"
+
+	// NameJSON is the name of the default JSON encoding. It's never recorded
+	// in job metadata, so jobs without a codec name are assumed to be JSON.
+	NameJSON = "json"
+)
+
+// Codec encodes and decodes job args. Has the same shape as river.ArgsCodec,
+// which is what's used to implement it.
+type Codec interface {
+	Name() string
+	Marshal(args any) ([]byte, error)
+	Unmarshal(data []byte, args any) error
+}
+
+// Compressor compresses and decompresses encoded job args. Has the same shape
+// as river.ArgsCompressor, which is what's used to implement it.
+type Compressor interface {
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Encryptor encrypts and decrypts encoded job args. Has the same shape as
+// river.ArgsEncryptor, which is what's used to implement it.
+type Encryptor interface {
+	KeyID() string
+	Encrypt(keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// GzipCompressor is a Compressor using gzip. It's always known to decoders.
+type GzipCompressor struct{}
+
+func (GzipCompressor) Name() string { return NameGzip }
+
+func (GzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// Encoder encodes job args.
+type Encoder struct {
+	// Codec encodes args. Nil encodes args as JSON.
+	Codec Codec
+
+	// Compressor compresses encoded args at least CompressMinBytes long. Nil
+	// disables compression.
+	Compressor Compressor
+
+	// CompressMinBytes is the minimum size of encoded args for them to be
+	// compressed.
+	CompressMinBytes int
+
+	// Encryptor encrypts encoded args after they're compressed. Nil disables
+	// encryption.
+	Encryptor Encryptor
+}
+
+// Encode encodes args, returning them along with the given job metadata
+// updated to record how they were encoded. Metadata keys for steps that
+// weren't applied are removed so that metadata can be reused from a job whose
+// args were previously encoded differently.
+//
+// Args are stored in a JSON column, so args that aren't plain JSON because
+// they were encoded with a codec other than JSON, compressed, or encrypted are
+// wrapped in a JSON string (base64 encoded).
+func (e *Encoder) Encode(args any, metadata []byte) ([]byte, []byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if e.Codec == nil {
+		data, err = json.Marshal(args)
+	} else {
+		data, err = e.Codec.Marshal(args)
+		if err != nil {
+			err = fmt.Errorf("error encoding args with codec %q: %w", e.Codec.Name(), err)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var codecName string
+	if e.Codec != nil {
+		codecName = e.Codec.Name()
+	}
+
+	var compressorName string
+	if e.Compressor != nil && len(data) >= e.CompressMinBytes {
+		if data, err = e.Compressor.Compress(data); err != nil {
+			return nil, nil, fmt.Errorf("error compressing args with compressor %q: %w", e.Compressor.Name(), err)
+		}
+		compressorName = e.Compressor.Name()
+	}
+
+	var keyID string
+	if e.Encryptor != nil {
+		keyID = e.Encryptor.KeyID()
+		if keyID == "" {
+			return nil, nil, errors.New("ArgsEncryptor key ID cannot be empty")
+		}
+
+		if data, err = e.Encryptor.Encrypt(keyID, data); err != nil {
+			return nil, nil, fmt.Errorf("error encrypting args with key %q: %w", keyID, err)
+		}
+	}
+
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+	for _, metadataVal := range []struct {
+		key string
+		val string
+	}{
+		{rivertype.MetadataKeyArgsCodec, codecName},
+		{rivertype.MetadataKeyArgsCompression, compressorName},
+		{rivertype.MetadataKeyArgsEncryptionKeyID, keyID},
+	} {
+		if metadataVal.val == "" {
+			if gjson.GetBytes(metadata, metadataVal.key).Exists() {
+				metadata, err = sjson.DeleteBytes(metadata, metadataVal.key)
+			}
+		} else {
+			metadata, err = sjson.SetBytes(metadata, metadataVal.key, metadataVal.val)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error setting %q in metadata: %w", metadataVal.key, err)
+		}
+	}
+
+	if codecName == "" && compressorName == "" && keyID == "" {
+		return data, metadata, nil
+	}
+
+	data, err = json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, metadata, nil
+}
+
+// Reencrypt decrypts encoded args encrypted with the key fromKeyID and
+// encrypts them again with the key toKeyID, leaving how they were otherwise
+// encoded unchanged.
+func Reencrypt(encryptor Encryptor, encodedArgs []byte, fromKeyID, toKeyID string) ([]byte, error) {
+	var data []byte
+	if err := json.Unmarshal(encodedArgs, &data); err != nil {
+		return nil, fmt.Errorf("error unwrapping encoded args: %w", err)
+	}
+
+	data, err := encryptor.Decrypt(fromKeyID, data)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting args with key %q: %w", fromKeyID, err)
+	}
+
+	if data, err = encryptor.Encrypt(toKeyID, data); err != nil {
+		return nil, fmt.Errorf("error encrypting args with key %q: %w", toKeyID, err)
+	}
+
+	return json.Marshal(data)
+}
+
+// Decoder decodes job args according to the codec, compressor, and encryption
+// key recorded in their job's metadata. A nil decoder is valid and decodes
+// JSON, compressed or not with gzip.
+type Decoder struct {
+	codecs      []Codec
+	compressors []Compressor
+	encryptor   Encryptor
+}
+
+// NewDecoder returns a decoder able to decode JSON and gzip as well as the
+// given codecs and compressors, and to decrypt args with the given encryptor.
+// Nil codecs, compressors, and encryptor are ignored.
+func NewDecoder(codecs []Codec, compressors []Compressor, encryptor Encryptor) *Decoder {
+	decoder := &Decoder{encryptor: encryptor}
+	for _, codec := range codecs {
+		if codec != nil {
+			decoder.codecs = append(decoder.codecs, codec)
+		}
+	}
+	for _, compressor := range compressors {
+		if compressor != nil {
+			decoder.compressors = append(decoder.compressors, compressor)
+		}
+	}
+	return decoder
+}
+
+// Codec returns the codec with the given name, nil for JSON, or an error if
+// the decoder doesn't know about a codec with the name.
+func (d *Decoder) Codec(name string) (Codec, error) {
+	if name == "" || name == NameJSON {
+		return nil, nil //nolint:nilnil
+	}
+
+	if d != nil {
+		for _, codec := range d.codecs {
+			if codec.Name() == name {
+				return codec, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("args encoded with unknown codec %q; configure it with Config.ArgsCodec or Config.ArgsCodecsDecodeOnly", name)
+}
+
+// CodecForJob returns the codec that the given job's args were encoded with,
+// nil for JSON, or an error if the decoder doesn't know about it.
+func (d *Decoder) CodecForJob(jobRow *rivertype.JobRow) (Codec, error) {
+	return d.Codec(gjson.GetBytes(jobRow.Metadata, rivertype.MetadataKeyArgsCodec).String())
+}
+
+// Compressor returns the compressor with the given name, or an error if the
+// decoder doesn't know about a compressor with the name.
+func (d *Decoder) Compressor(name string) (Compressor, error) {
+	if d != nil {
+		for _, compressor := range d.compressors {
+			if compressor.Name() == name {
+				return compressor, nil
+			}
+		}
+	}
+
+	if name == NameGzip {
+		return GzipCompressor{}, nil
+	}
+
+	return nil, fmt.Errorf("args compressed with unknown compressor %q; configure it with Config.ArgsCompression", name)
+}
+
+// Decode decodes the given job's args into v.
+func (d *Decoder) Decode(jobRow *rivertype.JobRow, v any) error {
+	return d.DecodeWithUpgrade(jobRow, v, nil)
+}
+
+// DecodeWithUpgrade decodes the given job's args into v like Decode, but if
+// upgrade is non-nil, it's invoked with args after they're decrypted and
+// decompressed, but before they're unmarshaled, so it can convert them from
+// an older shape. Data it receives and returns is in the format of the
+// codec args were encoded with.
+func (d *Decoder) DecodeWithUpgrade(jobRow *rivertype.JobRow, v any, upgrade func(data []byte) ([]byte, error)) error {
+	codec, err := d.CodecForJob(jobRow)
+	if err != nil {
+		return err
+	}
+
+	var (
+		compressorName = gjson.GetBytes(jobRow.Metadata, rivertype.MetadataKeyArgsCompression).String()
+		keyID          = gjson.GetBytes(jobRow.Metadata, rivertype.MetadataKeyArgsEncryptionKeyID).String()
+	)
+
+	if codec == nil && compressorName == "" && keyID == "" {
+		data := jobRow.EncodedArgs
+		if upgrade != nil {
+			if data, err = upgrade(data); err != nil {
+				return err
+			}
+		}
+		return json.Unmarshal(data, v)
+	}
+
+	var data []byte
+	if err := json.Unmarshal(jobRow.EncodedArgs, &data); err != nil {
+		return fmt.Errorf("error unwrapping encoded args: %w", err)
+	}
+
+	if keyID != "" {
+		if d == nil || d.encryptor == nil {
+			return fmt.Errorf("args encrypted with key %q, but no encryptor is configured; configure one with Config.ArgsEncryptor", keyID)
+		}
+
+		if data, err = d.encryptor.Decrypt(keyID, data); err != nil {
+			return fmt.Errorf("error decrypting args with key %q: %w", keyID, err)
+		}
+	}
+
+	if compressorName != "" {
+		compressor, err := d.Compressor(compressorName)
+		if err != nil {
+			return err
+		}
+
+		if data, err = compressor.Decompress(data); err != nil {
+			return fmt.Errorf("error decompressing args with compressor %q: %w", compressorName, err)
+		}
+	}
+
+	if upgrade != nil {
+		if data, err = upgrade(data); err != nil {
+			return err
+		}
+	}
+
+	if codec == nil {
+		return json.Unmarshal(data, v)
+	}
+
+	if err := codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding args with codec %q: %w", codec.Name(), err)
+	}
+
+	return nil
+}
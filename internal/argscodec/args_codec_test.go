@@ -0,0 +1,285 @@
+package argscodec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(args any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(args); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, args any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(args)
+}
+
+type testArgs struct {
+	Name string `json:"name"`
+}
+
+// xorEncryptor is an insecure Encryptor for tests that XORs data with a
+// single byte key.
+type xorEncryptor struct {
+	keyID string
+	keys  map[string]byte
+}
+
+func (e *xorEncryptor) KeyID() string { return e.keyID }
+
+func (e *xorEncryptor) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return e.xor(keyID, plaintext)
+}
+
+func (e *xorEncryptor) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return e.xor(keyID, ciphertext)
+}
+
+func (e *xorEncryptor) xor(keyID string, data []byte) ([]byte, error) {
+	key, ok := e.keys[keyID]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ key
+	}
+	return out, nil
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	args := testArgs{Name: "name"}
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{}).Encode(args, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"name"}`, string(encodedArgs))
+		require.JSONEq(t, `{}`, string(metadata))
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder(nil, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("JSONWithNilDecoder", func(t *testing.T) {
+		t.Parallel()
+
+		var decoder *Decoder
+
+		var decodedArgs testArgs
+		require.NoError(t, decoder.Decode(&rivertype.JobRow{EncodedArgs: []byte(`{"name":"name"}`)}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("Codec", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Codec: gobCodec{}}).Encode(args, []byte(`{"foo":"bar"}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar","river:args_codec":"gob"}`, string(metadata))
+
+		// Still valid JSON so that it can be stored in a JSON column.
+		require.True(t, json.Valid(encodedArgs))
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder([]Codec{gobCodec{}}, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("UnknownCodec", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Codec: gobCodec{}}).Encode(args, nil)
+		require.NoError(t, err)
+
+		var decodedArgs testArgs
+		require.EqualError(t, NewDecoder(nil, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs),
+			`args encoded with unknown codec "gob"; configure it with Config.ArgsCodec or Config.ArgsCodecsDecodeOnly`)
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Compressor: GzipCompressor{}}).Encode(args, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"river:args_compression":"gzip"}`, string(metadata))
+		require.True(t, json.Valid(encodedArgs))
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder(nil, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("CompressedWithCodec", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Codec: gobCodec{}, Compressor: GzipCompressor{}}).Encode(args, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"river:args_codec":"gob","river:args_compression":"gzip"}`, string(metadata))
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder([]Codec{gobCodec{}}, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("Upgrade", func(t *testing.T) {
+		t.Parallel()
+
+		upgrade := func(data []byte) ([]byte, error) {
+			require.JSONEq(t, `{"name":"old"}`, string(data))
+			return []byte(`{"name":"upgraded"}`), nil
+		}
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder(nil, nil, nil).DecodeWithUpgrade(&rivertype.JobRow{EncodedArgs: []byte(`{"name":"old"}`), Metadata: []byte(`{}`)}, &decodedArgs, upgrade))
+		require.Equal(t, testArgs{Name: "upgraded"}, decodedArgs)
+
+		// Upgrade receives args after they're decompressed.
+		encodedArgs, metadata, err := (&Encoder{Compressor: GzipCompressor{}}).Encode(testArgs{Name: "old"}, nil)
+		require.NoError(t, err)
+
+		decodedArgs = testArgs{}
+		require.NoError(t, NewDecoder(nil, nil, nil).DecodeWithUpgrade(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs, upgrade))
+		require.Equal(t, testArgs{Name: "upgraded"}, decodedArgs)
+	})
+
+	t.Run("UpgradeError", func(t *testing.T) {
+		t.Parallel()
+
+		var decodedArgs testArgs
+		err := NewDecoder(nil, nil, nil).DecodeWithUpgrade(&rivertype.JobRow{EncodedArgs: []byte(`{"name":"old"}`), Metadata: []byte(`{}`)}, &decodedArgs, func(data []byte) ([]byte, error) {
+			return nil, errors.New("upgrade error")
+		})
+		require.EqualError(t, err, "upgrade error")
+	})
+
+	t.Run("BelowCompressMinBytes", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Compressor: GzipCompressor{}, CompressMinBytes: 100}).Encode(args, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"name"}`, string(encodedArgs))
+		require.JSONEq(t, `{}`, string(metadata))
+	})
+
+	t.Run("UnknownCompressor", func(t *testing.T) {
+		t.Parallel()
+
+		var decodedArgs testArgs
+		require.EqualError(t, NewDecoder(nil, nil, nil).Decode(&rivertype.JobRow{
+			EncodedArgs: []byte(`"AAAA"`),
+			Metadata:    []byte(`{"river:args_compression":"zstd"}`),
+		}, &decodedArgs), `args compressed with unknown compressor "zstd"; configure it with Config.ArgsCompression`)
+	})
+
+	t.Run("Encrypted", func(t *testing.T) {
+		t.Parallel()
+
+		encryptor := &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}
+
+		encodedArgs, metadata, err := (&Encoder{Compressor: GzipCompressor{}, Encryptor: encryptor}).Encode(args, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"river:args_compression":"gzip","river:args_encryption_key_id":"key1"}`, string(metadata))
+
+		var decodedArgs testArgs
+		require.NoError(t, NewDecoder(nil, nil, encryptor).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+
+		// Still decryptable after the encryptor has rotated to a new key.
+		encryptor = &xorEncryptor{keyID: "key2", keys: map[string]byte{"key1": 0x2a, "key2": 0x3b}}
+		require.NoError(t, NewDecoder(nil, nil, encryptor).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs))
+		require.Equal(t, args, decodedArgs)
+	})
+
+	t.Run("EncryptedWithoutEncryptor", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{Encryptor: &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}}).Encode(args, nil)
+		require.NoError(t, err)
+
+		var decodedArgs testArgs
+		require.EqualError(t, NewDecoder(nil, nil, nil).Decode(&rivertype.JobRow{EncodedArgs: encodedArgs, Metadata: metadata}, &decodedArgs),
+			`args encrypted with key "key1", but no encryptor is configured; configure one with Config.ArgsEncryptor`)
+	})
+
+	t.Run("EncryptorEmptyKeyID", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := (&Encoder{Encryptor: &xorEncryptor{}}).Encode(args, nil)
+		require.EqualError(t, err, "ArgsEncryptor key ID cannot be empty")
+	})
+
+	t.Run("MetadataKeysRemovedWhenNotApplied", func(t *testing.T) {
+		t.Parallel()
+
+		encodedArgs, metadata, err := (&Encoder{}).Encode(args, []byte(`{"foo":"bar","river:args_compression":"gzip","river:args_encryption_key_id":"key1"}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"name"}`, string(encodedArgs))
+		require.JSONEq(t, `{"foo":"bar"}`, string(metadata))
+	})
+}
+
+func TestReencrypt(t *testing.T) {
+	t.Parallel()
+
+	args := testArgs{Name: "name"}
+
+	encodedArgs, metadata, err := (&Encoder{Encryptor: &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}}).Encode(args, nil)
+	require.NoError(t, err)
+
+	encryptor := &xorEncryptor{keyID: "key2", keys: map[string]byte{"key1": 0x2a, "key2": 0x3b}}
+
+	reencryptedArgs, err := Reencrypt(encryptor, encodedArgs, "key1", "key2")
+	require.NoError(t, err)
+	require.NotEqual(t, encodedArgs, reencryptedArgs)
+
+	// Old key no longer works.
+	var decodedArgs testArgs
+	require.Error(t, NewDecoder(nil, nil, encryptor).Decode(&rivertype.JobRow{EncodedArgs: reencryptedArgs, Metadata: metadata}, &decodedArgs))
+
+	require.NoError(t, NewDecoder(nil, nil, encryptor).Decode(&rivertype.JobRow{
+		EncodedArgs: reencryptedArgs,
+		Metadata:    []byte(`{"river:args_encryption_key_id":"key2"}`),
+	}, &decodedArgs))
+	require.Equal(t, args, decodedArgs)
+}
+
+func TestDecoderCodec(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder([]Codec{gobCodec{}}, nil, nil)
+
+	codec, err := decoder.Codec("")
+	require.NoError(t, err)
+	require.Nil(t, codec)
+
+	codec, err = decoder.Codec(NameJSON)
+	require.NoError(t, err)
+	require.Nil(t, codec)
+
+	codec, err = decoder.Codec("gob")
+	require.NoError(t, err)
+	require.Equal(t, gobCodec{}, codec)
+
+	_, err = decoder.Codec("msgpack")
+	require.Error(t, err)
+}
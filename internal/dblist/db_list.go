@@ -20,7 +20,14 @@ const (
 )
 
 type JobListOrderBy struct {
-	Expr  string
+	Expr string
+
+	// Nullable indicates that Expr may be null. Nulls are sorted as if they're
+	// greater than any other value (last in ascending order and first in
+	// descending order), which is Postgres' default, but made explicit so that
+	// other databases sort them the same way.
+	Nullable bool
+
 	Order SortOrder
 }
 
@@ -58,8 +65,9 @@ func JobMakeDriverParams(ctx context.Context, params *JobListParams, sqlFragment
 	orderBy := make([]JobListOrderBy, len(params.OrderBy))
 	for i, o := range params.OrderBy {
 		orderBy[i] = JobListOrderBy{
-			Expr:  o.Expr,
-			Order: o.Order,
+			Expr:     o.Expr,
+			Nullable: o.Nullable,
+			Order:    o.Order,
 		}
 	}
 
@@ -176,6 +184,13 @@ func JobMakeDriverParams(ctx context.Context, params *JobListParams, sqlFragment
 		case SortOrderUnspecified:
 			return nil, errors.New("should not have gotten SortOrderUnspecified by this point before executing list (bug?)")
 		}
+		if orderBy.Nullable {
+			if orderBy.Order == SortOrderDesc {
+				orderByBuilder.WriteString(" NULLS FIRST")
+			} else {
+				orderByBuilder.WriteString(" NULLS LAST")
+			}
+		}
 		if i < len(params.OrderBy)-1 {
 			orderByBuilder.WriteString(", ")
 		}
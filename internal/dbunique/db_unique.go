@@ -114,7 +114,11 @@ func buildUniqueKeyString(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpt
 	}
 
 	if uniqueOpts.ByPeriod != time.Duration(0) {
-		lowerPeriodBound := ptrutil.ValOrDefaultFunc(params.ScheduledAt, timeGen.Now).Truncate(uniqueOpts.ByPeriod)
+		// A job scheduled relative to database time has its period estimated
+		// with the local clock, which is as close as can be done before insert.
+		lowerPeriodBound := ptrutil.ValOrDefaultFunc(params.ScheduledAt, func() time.Time {
+			return timeGen.Now().Add(params.ScheduledIn)
+		}).Truncate(uniqueOpts.ByPeriod)
 		sb.WriteString("&period=" + lowerPeriodBound.Format(time.RFC3339))
 	}
 
@@ -261,6 +261,7 @@ type BatchCompleter struct {
 	disableSleep         bool // disable sleep in testing
 	maxBacklog           int  // configurable for testing purposes; max backlog allowed before no more completions accepted
 	exec                 riverdriver.Executor
+	holdFunc             func() bool // may be nil
 	pilot                riverpilot.Pilot
 	schema               string
 	setStateParams       map[int64]*batchCompleterSetState
@@ -293,6 +294,25 @@ func (c *BatchCompleter) ResetSubscribeChan(subscribeCh SubscribeChan) {
 	c.subscribeCh = subscribeCh
 }
 
+// Backlog returns the number of completions waiting to be sent to the
+// database, along with the maximum backlog beyond which new completions wait.
+func (c *BatchCompleter) Backlog() (size, maxSize int) {
+	c.setStateParamsMu.RLock()
+	defer c.setStateParamsMu.RUnlock()
+
+	return len(c.setStateParams), c.maxBacklog
+}
+
+// SetHoldFunc sets a function that's checked before each batch is completed.
+// While it returns true, completions accumulate in the backlog instead of being
+// sent to the database, and once the backlog reaches its maximum size, new
+// completions wait. If it returns true when the completer stops, the final
+// batch isn't sent either, and the jobs in it are left running in the database
+// to be rescued. Must be called before Start.
+func (c *BatchCompleter) SetHoldFunc(holdFunc func() bool) {
+	c.holdFunc = holdFunc
+}
+
 func (c *BatchCompleter) Start(ctx context.Context) error {
 	stopCtx, shouldStart, started, stopped := c.StartInit(ctx)
 	if !shouldStart {
@@ -323,6 +343,16 @@ func (c *BatchCompleter) Start(ctx context.Context) error {
 		for numTicks := 0; ; numTicks++ {
 			select {
 			case <-stopCtx.Done():
+				// A hold means that the database is known to be unavailable,
+				// so don't send it a final batch that'd only fail after a
+				// round of retries, delaying stop.
+				if c.holdFunc != nil && c.holdFunc() {
+					if numHeld := backlogSize(); numHeld > 0 {
+						c.Logger.WarnContext(ctx, c.Name+": Stopping with held completions; jobs will be rescued", "num_jobs", numHeld)
+					}
+					return
+				}
+
 				// Try to insert last batch before leaving. Note we use the
 				// original context so operations aren't immediately cancelled.
 				if err := c.handleBatch(ctx); err != nil {
@@ -333,6 +363,12 @@ func (c *BatchCompleter) Start(ctx context.Context) error {
 			case <-ticker.C:
 			}
 
+			// While held, completions stay in the backlog to be completed once
+			// the hold is released.
+			if c.holdFunc != nil && c.holdFunc() {
+				continue
+			}
+
 			// The ticker fires quite often to make sure that given a huge glut
 			// of jobs, we don't accidentally build up too much of a backlog by
 			// waiting too long. However, don't start a complete operation until
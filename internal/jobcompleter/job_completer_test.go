@@ -546,6 +546,94 @@ func TestBatchCompleter_JobStatsSnapshotsPerUpdate(t *testing.T) {
 	require.NotSame(t, firstUpdates[0].JobStats, secondUpdates[0].JobStats)
 }
 
+func TestBatchCompleter_HoldFunc(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	execMock := &partialExecutorMock{}
+	execMock.JobSetStateIfRunningManyFunc = func(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
+		rows := make([]*rivertype.JobRow, len(params.ID))
+		for i := range params.ID {
+			rows[i] = &rivertype.JobRow{ID: params.ID[i], State: params.State[i]}
+		}
+		return rows, nil
+	}
+
+	var held atomic.Bool
+	held.Store(true)
+
+	subscribeCh := make(chan []CompleterJobUpdated, 10)
+	completer := NewBatchCompleter(riversharedtest.BaseServiceArchetype(t), "", execMock, &riverpilot.StandardPilot{}, subscribeCh)
+	completer.SetHoldFunc(held.Load)
+
+	require.NoError(t, completer.Start(ctx))
+	t.Cleanup(completer.Stop)
+
+	require.NoError(t, completer.JobSetStateIfRunning(ctx, &jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now(), nil)))
+
+	// Long enough for several ticks of the completer's loop, including one
+	// where a batch below the start threshold would normally be completed.
+	select {
+	case <-subscribeCh:
+		require.FailNow(t, "Expected completion to be held")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	held.Store(false)
+
+	updates := riversharedtest.WaitOrTimeout(t, subscribeCh)
+	require.Len(t, updates, 1)
+	require.Equal(t, int64(1), updates[0].Job.ID)
+}
+
+func TestBatchCompleter_Backlog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	completer := NewBatchCompleter(riversharedtest.BaseServiceArchetype(t), "", &partialExecutorMock{}, &riverpilot.StandardPilot{}, make(chan []CompleterJobUpdated, 10))
+	completer.maxBacklog = 10
+
+	size, maxSize := completer.Backlog()
+	require.Zero(t, size)
+	require.Equal(t, 10, maxSize)
+
+	// The completer isn't started, so completions accumulate in its backlog.
+	require.NoError(t, completer.JobSetStateIfRunning(ctx, &jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now(), nil)))
+	require.NoError(t, completer.JobSetStateIfRunning(ctx, &jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(2, time.Now(), nil)))
+
+	size, maxSize = completer.Backlog()
+	require.Equal(t, 2, size)
+	require.Equal(t, 10, maxSize)
+}
+
+func TestBatchCompleter_HoldFuncOnStop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	execMock := &partialExecutorMock{}
+	execMock.JobSetStateIfRunningManyFunc = func(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
+		return nil, errors.New("completions shouldn't be sent while held")
+	}
+
+	subscribeCh := make(chan []CompleterJobUpdated, 10)
+	completer := NewBatchCompleter(riversharedtest.BaseServiceArchetype(t), "", execMock, &riverpilot.StandardPilot{}, subscribeCh)
+	completer.SetHoldFunc(func() bool { return true })
+
+	require.NoError(t, completer.Start(ctx))
+
+	require.NoError(t, completer.JobSetStateIfRunning(ctx, &jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now(), nil)))
+
+	completer.Stop()
+
+	// The subscribe channel is closed on stop without any updates.
+	_, ok := <-subscribeCh
+	require.False(t, ok)
+	require.False(t, execMock.JobSetStateIfRunningManyCalled)
+}
+
 func TestInlineCompleter(t *testing.T) {
 	t.Parallel()
 
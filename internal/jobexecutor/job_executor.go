@@ -7,17 +7,23 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/tidwall/gjson"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/execution"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/jobstats"
 	"github.com/riverqueue/river/internal/middlewarelookup"
+	"github.com/riverqueue/river/internal/poisonpill"
+	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/baseservice"
@@ -79,14 +85,79 @@ func MetadataUpdatesFromWorkContext(ctx context.Context) (map[string]any, bool)
 	return typedMetadataUpdates, true
 }
 
+// contextKeyProgress is the context key for the Progress of the job being
+// worked.
+const contextKeyProgress contextKey = "river_progress"
+
+// Progress holds the most recent progress recorded by a job's worker so that it
+// can be reported in live job status. Safe for concurrent use.
+type Progress struct {
+	value atomic.Pointer[json.RawMessage]
+}
+
+// Get returns the most recently recorded progress, or nil if none was
+// recorded.
+func (p *Progress) Get() json.RawMessage {
+	if value := p.value.Load(); value != nil {
+		return *value
+	}
+	return nil
+}
+
+// Set records progress, replacing any previously recorded.
+func (p *Progress) Set(progress json.RawMessage) {
+	p.value.Store(&progress)
+}
+
+// ProgressFromWorkContext returns the Progress of the job being worked.
+//
+// When run on a non-work context, it returns nil, false.
+func ProgressFromWorkContext(ctx context.Context) (*Progress, bool) {
+	progress, ok := ctx.Value(contextKeyProgress).(*Progress)
+	return progress, ok
+}
+
 type jobExecutorResult struct {
 	Err             error
 	MetadataUpdates map[string]any
 	NextRetry       time.Time
 	PanicTrace      string
 	PanicVal        any
+
+	// UnknownJobKind is set when the job's kind had no registered worker, so it
+	// was never worked.
+	UnknownJobKind bool
 }
 
+// UnknownJobKindAction is the action taken by an executor for a job whose kind
+// has no registered worker.
+type UnknownJobKindAction int
+
+const (
+	// UnknownJobKindActionRetry records an error on the job and retries it
+	// according to the retry policy like any other failure.
+	UnknownJobKindActionRetry UnknownJobKindAction = iota
+
+	// UnknownJobKindActionDiscard records an error on the job and discards it
+	// immediately regardless of its remaining attempts.
+	UnknownJobKindActionDiscard
+
+	// UnknownJobKindActionRelease gives the job's attempt back and reschedules
+	// it shortly in the future so that it can be picked up by another client
+	// that has a worker registered for it.
+	UnknownJobKindActionRelease
+)
+
+// UnknownJobKindReleaseDelay is how far in the future a job with an unknown
+// kind is rescheduled with UnknownJobKindActionRelease. It's long enough that
+// a client without the kind's worker doesn't spin on fetching the same job.
+const UnknownJobKindReleaseDelay = 10 * time.Second
+
+// attemptInfosMax is the maximum number of attempt infos kept in a job's
+// metadata, beyond which the oldest are dropped so that a job that's retried
+// or snoozed many times doesn't grow its metadata without bound.
+const attemptInfosMax = 25
+
 // ErrorStr returns an appropriate string to persist to the database based on
 // the type of internal failure (i.e. error or panic). Panics if called on a
 // non-errored result.
@@ -104,34 +175,107 @@ func (r *jobExecutorResult) ErrorStr() string {
 type JobExecutor struct {
 	baseservice.BaseService
 
+	// ArgsDecoder decodes job args according to the codec they were encoded
+	// with. Nil only decodes JSON.
+	ArgsDecoder *argscodec.Decoder
+
+	// AttemptInfo, if set, causes information about the attempt to be
+	// appended to the job's metadata when it finishes. It contains the fields
+	// that are the same for every attempt worked by the client, and the rest
+	// are filled in from the attempt. It may be nil.
+	AttemptInfo *rivertype.AttemptInfo
+
 	CancelFunc               context.CancelCauseFunc
 	ClientJobTimeout         time.Duration
 	Completer                jobcompleter.JobCompleter
 	ClientRetryPolicy        ClientRetryPolicy
 	DefaultClientRetryPolicy ClientRetryPolicy
 	ErrorHandler             ErrorHandler
-	HookLookupByJob          *hooklookup.JobHookLookup
-	HookLookupGlobal         hooklookup.HookLookupInterface
-	JobRow                   *rivertype.JobRow
-	MiddlewareLookupGlobal   middlewarelookup.MiddlewareLookupInterface
-	ProducerCallbacks        struct {
+
+	// ErrorMaxBytes is the maximum size of a recorded error message, beyond
+	// which it's truncated. Zero applies no limit.
+	ErrorMaxBytes int
+
+	HookLookupByJob        *hooklookup.JobHookLookup
+	HookLookupGlobal       hooklookup.HookLookupInterface
+	JobRow                 *rivertype.JobRow
+	MiddlewareLookupGlobal middlewarelookup.MiddlewareLookupInterface
+	ProducerCallbacks      struct {
 		JobDone func(jobRow *rivertype.JobRow)
 		Stuck   func()
 		Unstuck func()
 	}
+
+	// PoisonPillDetected is invoked after a job that panicked is discarded as
+	// a poison pill. It may be nil.
+	PoisonPillDetected func(ctx context.Context, job *rivertype.JobRow)
+
+	// PoisonPillMaxCrashes is the number of consecutive crashes after which a
+	// job that panics is discarded as a poison pill instead of being retried,
+	// counting the current one. Zero disables detection.
+	PoisonPillMaxCrashes int
+
+	// ReleaseOnStop causes jobs which error because their context was
+	// cancelled by the client stopping to be released back to available
+	// without consuming an attempt, rather than being retried with backoff.
+	ReleaseOnStop bool
+
+	// RetryBudgetReserve takes a token from the client's retry budget, if it
+	// has one, returning how long the job's retry must be deferred before the
+	// budget permits it. It may be nil.
+	RetryBudgetReserve func(ctx context.Context) (time.Duration, error)
+
 	SchedulerInterval      time.Duration
 	StuckThresholdOverride time.Duration
-	WorkerMiddleware       []rivertype.WorkerMiddleware
-	WorkUnit               workunit.WorkUnit
+
+	// TraceMaxBytes is the maximum size of a recorded panic trace, beyond which
+	// it's truncated. Zero applies no limit.
+	TraceMaxBytes int
+
+	// UnknownJobKindAction is the action taken if WorkUnit is nil because the
+	// job's kind has no registered worker.
+	UnknownJobKindAction UnknownJobKindAction
+
+	WorkerMiddleware []rivertype.WorkerMiddleware
+	WorkUnit         workunit.WorkUnit
 
 	// Meant to be used from within the job executor only.
-	start time.Time
-	stats *jobstats.JobStatistics // initialized by the executor, and handed off to completer
+	progress         Progress
+	releaseRequested atomic.Bool
+	start            time.Time
+	stats            *jobstats.JobStatistics // initialized by the executor, and handed off to completer
 }
 
-func (e *JobExecutor) Cancel(ctx context.Context) {
-	e.Logger.WarnContext(ctx, e.Name+": job cancelled remotely", slog.Int64("job_id", e.JobRow.ID))
-	e.CancelFunc(rivertype.ErrJobCancelledRemotely)
+// Cancel cancels the job's context with a cause of ErrJobCancelledRemotely, or
+// if a reason is given, a JobCancelledRemotelyError carrying it.
+func (e *JobExecutor) Cancel(ctx context.Context, reason string) {
+	e.Logger.WarnContext(ctx, e.Name+": job cancelled remotely", slog.Int64("job_id", e.JobRow.ID), slog.String("reason", reason))
+
+	if reason == "" {
+		e.CancelFunc(rivertype.ErrJobCancelledRemotely)
+		return
+	}
+	e.CancelFunc(rivertype.JobCancel(&rivertype.JobCancelledRemotelyError{Reason: reason}))
+}
+
+// ReleaseForResourceBudget cancels the job's context with a cause of
+// rivercommon.ErrResourceBudgetExceeded so that it's released back to available
+// if it returns an error as a result. Returns false without doing anything if
+// it's already been called for the job. Safe to call from any goroutine.
+func (e *JobExecutor) ReleaseForResourceBudget(ctx context.Context) bool {
+	if !e.releaseRequested.CompareAndSwap(false, true) {
+		return false
+	}
+
+	e.Logger.WarnContext(ctx, e.Name+": Job interrupted because client exceeded its resource budget", slog.Int64("job_id", e.JobRow.ID))
+	e.CancelFunc(rivercommon.ErrResourceBudgetExceeded)
+	return true
+}
+
+// Progress returns the progress most recently recorded by the job's worker, or
+// nil if none was recorded. Safe to call from any goroutine.
+func (e *JobExecutor) Progress() json.RawMessage {
+	return e.progress.Get()
 }
 
 func (e *JobExecutor) Execute(ctx context.Context) {
@@ -175,6 +319,7 @@ func (e *JobExecutor) Execute(ctx context.Context) {
 func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 	metadataUpdates := make(map[string]any)
 	ctx = context.WithValue(ctx, ContextKeyMetadataUpdates, metadataUpdates)
+	ctx = context.WithValue(ctx, contextKeyProgress, &e.progress)
 
 	defer func() {
 		if recovery := recover(); recovery != nil {
@@ -199,12 +344,22 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 		e.stats.RunDuration = e.Time.Now().Sub(e.start)
 	}()
 
+	// A job cancelled after it was fetched, but before it started, isn't
+	// worked at all.
+	if cause := context.Cause(ctx); errors.Is(cause, rivertype.ErrJobCancelledRemotely) {
+		e.Logger.InfoContext(ctx, e.Name+": Job cancelled remotely before it started; not working it",
+			slog.Int64("job_id", e.JobRow.ID),
+			slog.String("kind", e.JobRow.Kind),
+		)
+		return &jobExecutorResult{Err: cause, MetadataUpdates: metadataUpdates}
+	}
+
 	if e.WorkUnit == nil {
 		e.Logger.ErrorContext(ctx, e.Name+": Unhandled job kind",
 			slog.String("kind", e.JobRow.Kind),
 			slog.Int64("job_id", e.JobRow.ID),
 		)
-		return &jobExecutorResult{Err: &rivertype.UnknownJobKindError{Kind: e.JobRow.Kind}, MetadataUpdates: metadataUpdates}
+		return &jobExecutorResult{Err: &rivertype.UnknownJobKindError{Kind: e.JobRow.Kind}, MetadataUpdates: metadataUpdates, UnknownJobKind: true}
 	}
 
 	doInner := execution.Func(func(ctx context.Context) error {
@@ -219,7 +374,7 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 			}
 		}
 
-		if err := e.WorkUnit.UnmarshalJob(); err != nil {
+		if err := e.WorkUnit.UnmarshalJob(e.ArgsDecoder); err != nil {
 			return err
 		}
 
@@ -234,7 +389,12 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 			defer watchStuckCancel()
 		}
 
-		err := e.WorkUnit.Work(ctx)
+		var err error
+		if gracePeriod := e.WorkUnit.TimeoutGracePeriod(); jobTimeout > 0 && gracePeriod > 0 {
+			err = e.workWithHardTimeout(ctx, jobTimeout, gracePeriod)
+		} else {
+			err = e.WorkUnit.Work(ctx)
+		}
 
 		{
 			for _, hook := range append(
@@ -258,6 +418,60 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 	return &jobExecutorResult{Err: executeFunc(ctx), MetadataUpdates: metadataUpdates}
 }
 
+// Works the job in a separate goroutine so that it can be abandoned if it
+// doesn't return within its grace period after its context was cancelled by
+// its timeout. An abandoned job's goroutine is left running because there's no
+// way to stop it, but its result is discarded. A panic in the goroutine is
+// propagated to the caller so that it's recovered like any other.
+func (e *JobExecutor) workWithHardTimeout(ctx context.Context, jobTimeout, gracePeriod time.Duration) error {
+	type workResult struct {
+		err      error
+		panicVal any
+		panicked bool
+	}
+
+	// The goroutine records metadata updates to its own map, which is only
+	// merged in if it returns in time, so that an abandoned goroutine can't
+	// race with the job's result being reported.
+	metadataUpdates, _ := MetadataUpdatesFromWorkContext(ctx)
+	workMetadataUpdates := make(map[string]any)
+	workCtx := context.WithValue(ctx, ContextKeyMetadataUpdates, workMetadataUpdates)
+
+	// Buffered so that an abandoned goroutine can still send its result.
+	resultChan := make(chan workResult, 1)
+
+	go func() {
+		defer func() {
+			if recovery := recover(); recovery != nil {
+				resultChan <- workResult{panicVal: recovery, panicked: true}
+			}
+		}()
+
+		resultChan <- workResult{err: e.WorkUnit.Work(workCtx)}
+	}()
+
+	hardTimeoutTimer := time.NewTimer(jobTimeout + gracePeriod)
+	defer hardTimeoutTimer.Stop()
+
+	select {
+	case res := <-resultChan:
+		maps.Copy(metadataUpdates, workMetadataUpdates)
+		if res.panicked {
+			panic(res.panicVal)
+		}
+		return res.err
+
+	case <-hardTimeoutTimer.C:
+		e.Logger.ErrorContext(ctx, e.Name+": Job didn't return within its timeout grace period; failing it",
+			slog.Duration("grace_period", gracePeriod),
+			slog.Int64("job_id", e.JobRow.ID),
+			slog.String("kind", e.JobRow.Kind),
+			slog.Duration("timeout", jobTimeout),
+		)
+		return &rivertype.JobHardTimeoutError{GracePeriod: gracePeriod, Timeout: jobTimeout}
+	}
+}
+
 // Watches for jobs that may have become stuck. i.e. They've run longer than
 // their job timeout (plus a small margin) and don't appear to be responding to
 // context cancellation (unfortunately, quite an easy error to make in Go).
@@ -344,6 +558,13 @@ func (e *JobExecutor) invokeErrorHandler(ctx context.Context, res *jobExecutorRe
 func (e *JobExecutor) reportResult(ctx context.Context, jobRow *rivertype.JobRow, res *jobExecutorResult) {
 	var snoozeErr *rivertype.JobSnoozeError
 
+	if e.AttemptInfo != nil {
+		if res.MetadataUpdates == nil {
+			res.MetadataUpdates = make(map[string]any)
+		}
+		res.MetadataUpdates[rivertype.MetadataKeyAttemptInfos] = e.attemptInfos(jobRow)
+	}
+
 	marshalMetadataUpdates := func(metadataUpdates map[string]any) ([]byte, error) {
 		if len(metadataUpdates) == 0 {
 			return nil, nil
@@ -404,6 +625,20 @@ func (e *JobExecutor) reportResult(ctx context.Context, jobRow *rivertype.JobRow
 		return
 	}
 
+	if res.UnknownJobKind && e.UnknownJobKindAction == UnknownJobKindActionRelease {
+		e.reportUnknownJobKindReleased(ctx, jobRow, metadataUpdatesBytes)
+		return
+	}
+
+	if res.Err != nil && (e.ReleaseOnStop && errors.Is(context.Cause(ctx), rivercommon.ErrStop) ||
+		errors.Is(context.Cause(ctx), rivercommon.ErrResourceBudgetExceeded)) {
+		var cancelErr *rivertype.JobCancelError
+		if !errors.As(res.Err, &cancelErr) {
+			e.reportReleased(ctx, jobRow, res, metadataUpdatesBytes)
+			return
+		}
+	}
+
 	if res.Err != nil || res.PanicVal != nil {
 		e.reportError(ctx, jobRow, res, metadataUpdatesBytes)
 		return
@@ -418,10 +653,66 @@ func (e *JobExecutor) reportResult(ctx context.Context, jobRow *rivertype.JobRow
 	}
 }
 
+// Returns the job's recorded attempt infos with one for the current attempt
+// appended, keeping only the most recent attemptInfosMax.
+func (e *JobExecutor) attemptInfos(jobRow *rivertype.JobRow) []rivertype.AttemptInfo {
+	attemptInfo := *e.AttemptInfo
+	attemptInfo.At = e.start
+	if jobRow.AttemptedAt != nil {
+		attemptInfo.At = *jobRow.AttemptedAt
+	}
+	attemptInfo.Attempt = jobRow.Attempt
+	attemptInfo.QueueWaitDuration = e.stats.QueueWaitDuration
+
+	attemptInfos := append(jobRow.AttemptInfos(), attemptInfo)
+	if len(attemptInfos) > attemptInfosMax {
+		attemptInfos = attemptInfos[len(attemptInfos)-attemptInfosMax:]
+	}
+	return attemptInfos
+}
+
+// Releases a job that was interrupted by the client stopping or exceeding its
+// resource budget by making it immediately available again so that another
+// client can pick it up. Like a snooze, the attempt is given back and no error
+// is recorded because the job didn't fail on its own merits.
+func (e *JobExecutor) reportReleased(ctx context.Context, jobRow *rivertype.JobRow, res *jobExecutorResult, metadataUpdates []byte) {
+	e.Logger.InfoContext(ctx, e.Name+": Job interrupted by client; releasing for another client to work",
+		slog.String("cause", context.Cause(ctx).Error()),
+		slog.String("error", res.ErrorStr()),
+		slog.Int64("job_id", jobRow.ID),
+		slog.String("job_kind", jobRow.Kind),
+	)
+
+	if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateSnoozedAvailable(jobRow.ID, e.Time.Now(), jobRow.Attempt-1, metadataUpdates)); err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Error releasing job",
+			slog.String("err", err.Error()),
+			slog.Int64("job_id", jobRow.ID),
+		)
+	}
+}
+
+// Releases a job whose kind has no registered worker so that a client which
+// does have one can work it. The attempt is given back so that a client that
+// doesn't own the kind doesn't burn through the job's attempts.
+func (e *JobExecutor) reportUnknownJobKindReleased(ctx context.Context, jobRow *rivertype.JobRow, metadataUpdates []byte) {
+	e.Logger.DebugContext(ctx, e.Name+": Job kind not registered; releasing for another client to work",
+		slog.Int64("job_id", jobRow.ID),
+		slog.String("job_kind", jobRow.Kind),
+	)
+
+	if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateSnoozed(jobRow.ID, e.Time.Now().Add(UnknownJobKindReleaseDelay), jobRow.Attempt-1, metadataUpdates)); err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Error releasing job",
+			slog.String("err", err.Error()),
+			slog.Int64("job_id", jobRow.ID),
+		)
+	}
+}
+
 func (e *JobExecutor) reportError(ctx context.Context, jobRow *rivertype.JobRow, res *jobExecutorResult, metadataUpdates []byte) {
 	var (
-		cancelJob bool
-		cancelErr *rivertype.JobCancelError
+		cancelJob      bool
+		cancelErr      *rivertype.JobCancelError
+		snoozeLimitErr *rivertype.JobSnoozeLimitExceededError
 	)
 
 	logAttrs := []any{
@@ -452,8 +743,8 @@ func (e *JobExecutor) reportError(ctx context.Context, jobRow *rivertype.JobRow,
 	attemptErr := rivertype.AttemptError{
 		At:      e.start,
 		Attempt: jobRow.Attempt,
-		Error:   res.ErrorStr(),
-		Trace:   res.PanicTrace,
+		Error:   truncateMiddle(res.ErrorStr(), e.ErrorMaxBytes),
+		Trace:   truncateMiddle(res.PanicTrace, e.TraceMaxBytes),
 	}
 
 	errData, err := json.Marshal(attemptErr)
@@ -471,9 +762,24 @@ func (e *JobExecutor) reportError(ctx context.Context, jobRow *rivertype.JobRow,
 		return
 	}
 
-	if jobRow.Attempt >= jobRow.MaxAttempts {
+	poisonPill := res.PanicVal != nil && e.PoisonPillMaxCrashes > 0 &&
+		poisonpill.ConsecutiveCrashes(jobRow.Errors)+1 >= e.PoisonPillMaxCrashes
+
+	if jobRow.Attempt >= jobRow.MaxAttempts ||
+		poisonPill ||
+		(errors.As(res.Err, &snoozeLimitErr) && snoozeLimitErr.Discard) ||
+		(res.UnknownJobKind && e.UnknownJobKindAction == UnknownJobKindActionDiscard) {
 		if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateDiscarded(jobRow.ID, now, errData, metadataUpdates)); err != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": Failed to discard job and report error", logAttrs...)
+			return
+		}
+
+		if poisonPill {
+			e.Logger.WarnContext(ctx, e.Name+": Job discarded as poison pill after crashing repeatedly", logAttrs...)
+
+			if e.PoisonPillDetected != nil {
+				e.PoisonPillDetected(ctx, jobRow)
+			}
 		}
 		return
 	}
@@ -495,6 +801,25 @@ func (e *JobExecutor) reportError(ctx context.Context, jobRow *rivertype.JobRow,
 		nextRetryScheduledAt = e.DefaultClientRetryPolicy.NextRetry(jobRow)
 	}
 
+	if e.RetryBudgetReserve != nil {
+		delay, err := e.RetryBudgetReserve(ctx)
+		switch {
+		case err != nil:
+			// Failing open is preferable to leaving the job running with its
+			// error unrecorded.
+			e.Logger.WarnContext(ctx, e.Name+": Error reserving from retry budget; scheduling retry without it",
+				slog.String("err", err.Error()),
+				slog.Int64("job_id", jobRow.ID),
+			)
+		case delay > 0 && now.Add(delay).After(nextRetryScheduledAt):
+			e.Logger.DebugContext(ctx, e.Name+": Retry budget exhausted; deferring retry",
+				slog.Int64("job_id", jobRow.ID),
+				slog.Duration("delay", delay),
+			)
+			nextRetryScheduledAt = now.Add(delay)
+		}
+	}
+
 	// Normally, errored jobs are set `retryable` for the future and it's the
 	// scheduler's job to set them back to `available` so they can be reworked.
 	// This isn't friendly for smaller retry times though because it means that
@@ -512,6 +837,35 @@ func (e *JobExecutor) reportError(ctx context.Context, jobRow *rivertype.JobRow,
 	}
 }
 
+// truncateMiddle truncates s to at most maxBytes by removing bytes from its
+// middle, keeping its head and tail around a marker noting how many bytes were
+// removed. Cuts are made on rune boundaries so that valid UTF-8 stays valid. A
+// maxBytes of zero applies no limit.
+func truncateMiddle(s string, maxBytes int) string {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s
+	}
+
+	// The marker's length depends on the number of bytes removed, which in
+	// turn depends on the marker's length, so size it from an upper bound.
+	marker := fmt.Sprintf("\n... [%d bytes truncated] ...\n", len(s))
+	keep := maxBytes - len(marker)
+	if keep <= 0 {
+		return s[:maxBytes]
+	}
+
+	headEnd := keep - keep/2
+	for headEnd > 0 && !utf8.RuneStart(s[headEnd]) {
+		headEnd--
+	}
+	tailStart := len(s) - keep/2
+	for tailStart < len(s) && !utf8.RuneStart(s[tailStart]) {
+		tailStart++
+	}
+
+	return s[:headEnd] + fmt.Sprintf("\n... [%d bytes truncated] ...\n", tailStart-headEnd) + s[tailStart:]
+}
+
 type withJobsAndErrorsByID interface {
 	ErrorsByID() map[int64]error
 	Jobs() []*rivertype.JobRow
@@ -2,17 +2,22 @@ package jobexecutor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/middlewarelookup"
+	"github.com/riverqueue/river/internal/poisonpill"
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/riverinternaltest"
 	"github.com/riverqueue/river/internal/riverinternaltest/retrypolicytest"
@@ -32,10 +37,11 @@ import (
 // of the workUnit.  Unlike in other packages, this one does not make use of any
 // types from the top level river package (like `river.Job[T]`).
 type customizableWorkUnit struct {
-	middleware []rivertype.WorkerMiddleware
-	nextRetry  func() time.Time
-	timeout    time.Duration
-	work       func() error
+	middleware         []rivertype.WorkerMiddleware
+	nextRetry          func() time.Time
+	timeout            time.Duration
+	timeoutGracePeriod time.Duration
+	work               func() error
 }
 
 func (w *customizableWorkUnit) HookLookup(lookup *hooklookup.JobHookLookup) hooklookup.HookLookupInterface {
@@ -57,7 +63,11 @@ func (w *customizableWorkUnit) Timeout() time.Duration {
 	return w.timeout
 }
 
-func (w *customizableWorkUnit) UnmarshalJob() error {
+func (w *customizableWorkUnit) TimeoutGracePeriod() time.Duration {
+	return w.timeoutGracePeriod
+}
+
+func (w *customizableWorkUnit) UnmarshalJob(argsDecoder *argscodec.Decoder) error {
 	return nil
 }
 
@@ -246,6 +256,64 @@ func TestJobExecutor_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("RecordsAttemptInfo", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.AttemptInfo = &rivertype.AttemptInfo{ClientID: "client", ClientVersion: "v1.2.3", Hostname: "host", PID: 123}
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+
+		attemptInfos := job.AttemptInfos()
+		require.Len(t, attemptInfos, 1)
+		require.WithinDuration(t, *bundle.jobRow.AttemptedAt, attemptInfos[0].At, time.Microsecond)
+		require.Equal(t, bundle.jobRow.Attempt, attemptInfos[0].Attempt)
+		require.Equal(t, "client", attemptInfos[0].ClientID)
+		require.Equal(t, "v1.2.3", attemptInfos[0].ClientVersion)
+		require.Equal(t, "host", attemptInfos[0].Hostname)
+		require.Equal(t, 123, attemptInfos[0].PID)
+		require.Positive(t, attemptInfos[0].QueueWaitDuration)
+	})
+
+	t.Run("RecordsAttemptInfoKeepingMostRecent", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.AttemptInfo = &rivertype.AttemptInfo{ClientID: "client"}
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return errors.New("job error") }, nil).MakeUnit(bundle.jobRow)
+
+		previousAttemptInfos := make([]rivertype.AttemptInfo, attemptInfosMax)
+		for i := range previousAttemptInfos {
+			previousAttemptInfos[i] = rivertype.AttemptInfo{Attempt: i + 1, ClientID: "previous_client"}
+		}
+		metadata, err := json.Marshal(map[string]any{rivertype.MetadataKeyAttemptInfos: previousAttemptInfos})
+		require.NoError(t, err)
+		bundle.jobRow.Metadata = metadata
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+
+		attemptInfos := job.AttemptInfos()
+		require.Len(t, attemptInfos, attemptInfosMax)
+		require.Equal(t, 2, attemptInfos[0].Attempt) // oldest dropped
+		require.Equal(t, "client", attemptInfos[attemptInfosMax-1].ClientID)
+	})
+
 	t.Run("FirstError", func(t *testing.T) {
 		t.Parallel()
 
@@ -295,6 +363,100 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Equal(t, rivertype.JobStateRetryable, job.State)
 	})
 
+	t.Run("ErrorTruncatedToErrorMaxBytes", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.ErrorMaxBytes = 256
+
+		workerErr := errors.New("head" + strings.Repeat("x", 10_000) + "tail")
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return workerErr }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Len(t, job.Errors, 1)
+		require.LessOrEqual(t, len(job.Errors[0].Error), 256)
+		require.True(t, strings.HasPrefix(job.Errors[0].Error, "headxxx"))
+		require.True(t, strings.HasSuffix(job.Errors[0].Error, "xxxtail"))
+		require.Contains(t, job.Errors[0].Error, "bytes truncated")
+	})
+
+	t.Run("ErrorRetryDeferredByRetryBudget", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		now := executor.Time.StubNow(time.Now().UTC())
+
+		executor.RetryBudgetReserve = func(ctx context.Context) (time.Duration, error) { return time.Hour, nil }
+
+		workerErr := errors.New("job error")
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return workerErr }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, now.Add(time.Hour), job.ScheduledAt, time.Millisecond)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+	})
+
+	t.Run("ErrorRetryNotDeferredByRetryBudgetBeforeNextRetry", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		// Budget will be available before the job's retry policy would retry
+		// it anyway, so the retry is left as is.
+		executor.RetryBudgetReserve = func(ctx context.Context) (time.Duration, error) { return time.Millisecond, nil }
+
+		workerErr := errors.New("job error")
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return workerErr }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, executor.ClientRetryPolicy.NextRetry(bundle.jobRow), job.ScheduledAt, 1*time.Second)
+	})
+
+	t.Run("ErrorRetryBudgetErrorIgnored", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.RetryBudgetReserve = func(ctx context.Context) (time.Duration, error) { return 0, errors.New("budget error") }
+
+		workerErr := errors.New("job error")
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return workerErr }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, executor.ClientRetryPolicy.NextRetry(bundle.jobRow), job.ScheduledAt, 1*time.Second)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+	})
+
 	t.Run("ErrorSetsJobAvailableBelowSchedulerIntervalThreshold", func(t *testing.T) {
 		t.Parallel()
 
@@ -446,6 +608,164 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Empty(t, job.Errors)
 	})
 
+	t.Run("ReleaseOnStopMakesJobAvailableAndDecrementsAttempt", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.ReleaseOnStop = true
+		attemptBefore := bundle.jobRow.Attempt
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return context.Canceled }, nil).MakeUnit(bundle.jobRow)
+
+		stopCtx, stopCancel := context.WithCancelCause(ctx)
+		stopCancel(rivercommon.ErrStop)
+
+		executor.Execute(stopCtx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+		require.WithinDuration(t, time.Now(), job.ScheduledAt, 2*time.Second)
+		require.Equal(t, attemptBefore-1, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+
+	t.Run("ReleaseOnStopIgnoredForOtherCancellation", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.ReleaseOnStop = true
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return context.Canceled }, nil).MakeUnit(bundle.jobRow)
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		executor.Execute(cancelledCtx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+	})
+
+	t.Run("ReleaseOnStopDisabledRetriesJob", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return context.Canceled }, nil).MakeUnit(bundle.jobRow)
+
+		stopCtx, stopCancel := context.WithCancelCause(ctx)
+		stopCancel(rivercommon.ErrStop)
+
+		executor.Execute(stopCtx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+	})
+
+	t.Run("ReleaseForResourceBudgetMakesJobAvailableAndDecrementsAttempt", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		attemptBefore := bundle.jobRow.Attempt
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return context.Canceled }, nil).MakeUnit(bundle.jobRow)
+
+		jobCtx, jobCancel := context.WithCancelCause(ctx)
+		executor.CancelFunc = jobCancel
+
+		require.True(t, executor.ReleaseForResourceBudget(ctx))
+		require.False(t, executor.ReleaseForResourceBudget(ctx)) // already requested
+
+		executor.Execute(jobCtx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+		require.Equal(t, attemptBefore-1, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+
+	t.Run("UnknownJobKindRetry", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.WorkUnit = nil
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, (&rivertype.UnknownJobKindError{Kind: bundle.jobRow.Kind}).Error(), job.Errors[0].Error)
+	})
+
+	t.Run("UnknownJobKindDiscard", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.UnknownJobKindAction = UnknownJobKindActionDiscard
+		executor.WorkUnit = nil
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+		require.Len(t, job.Errors, 1)
+	})
+
+	t.Run("UnknownJobKindRelease", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+		executor.UnknownJobKindAction = UnknownJobKindActionRelease
+		executor.WorkUnit = nil
+		attemptBefore := bundle.jobRow.Attempt
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, job.State)
+		require.WithinDuration(t, time.Now().Add(UnknownJobKindReleaseDelay), job.ScheduledAt, 2*time.Second)
+		require.Equal(t, attemptBefore-1, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+
 	t.Run("ErrorWithCustomRetryPolicy", func(t *testing.T) {
 		t.Parallel()
 
@@ -840,6 +1160,86 @@ func TestJobExecutor_Execute(t *testing.T) {
 		riversharedtest.WaitOrTimeout(t, informProducerUnstuckReceived)
 	})
 
+	t.Run("HardTimeoutFailsJobNotReturningWithinGracePeriod", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		// Released once the test is over so that the abandoned goroutine exits.
+		workBlock := make(chan struct{})
+		t.Cleanup(func() { close(workBlock) })
+
+		executor.WorkUnit = &customizableWorkUnit{
+			timeout:            5 * time.Millisecond,
+			timeoutGracePeriod: 5 * time.Millisecond,
+			work: func() error {
+				<-workBlock
+				return nil
+			},
+		}
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, (&rivertype.JobHardTimeoutError{GracePeriod: 5 * time.Millisecond, Timeout: 5 * time.Millisecond}).Error(), job.Errors[0].Error)
+	})
+
+	t.Run("HardTimeoutNotTriggeredWhenReturningWithinGracePeriod", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = &customizableWorkUnit{
+			timeout:            5 * time.Millisecond,
+			timeoutGracePeriod: 5 * time.Second,
+			work: func() error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			},
+		}
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+	})
+
+	t.Run("HardTimeoutPropagatesPanic", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = &customizableWorkUnit{
+			timeout:            5 * time.Second,
+			timeoutGracePeriod: 5 * time.Second,
+			work:               func() error { panic("panic val") },
+		}
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, "panic val", job.Errors[0].Error)
+	})
+
 	t.Run("Panic", func(t *testing.T) {
 		t.Parallel()
 
@@ -861,6 +1261,28 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Contains(t, job.Errors[0].Trace, "internal/jobexecutor/job_executor.go")
 	})
 
+	t.Run("PanicTraceTruncatedToTraceMaxBytes", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.TraceMaxBytes = 256
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { panic("panic val") }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Len(t, job.Errors, 1)
+		require.LessOrEqual(t, len(job.Errors[0].Trace), 256)
+		require.Contains(t, job.Errors[0].Trace, "bytes truncated")
+	})
+
 	t.Run("PanicAgainAfterRetry", func(t *testing.T) {
 		t.Parallel()
 
@@ -903,6 +1325,68 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Equal(t, rivertype.JobStateDiscarded, job.State)
 	})
 
+	t.Run("PanicDiscardsPoisonPill", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		var poisonPillJob *rivertype.JobRow
+		executor.PoisonPillDetected = func(ctx context.Context, job *rivertype.JobRow) { poisonPillJob = job }
+		executor.PoisonPillMaxCrashes = 3
+
+		// A previous panic and a previous process crash, with an ordinary
+		// error before them that breaks the streak.
+		bundle.jobRow.Attempt = 4
+		bundle.jobRow.Errors = []rivertype.AttemptError{
+			{Attempt: 1, Error: "ordinary error"},
+			{Attempt: 2, Error: "panic val", Trace: "trace"},
+			{Attempt: 3, Error: poisonpill.ErrorRescued},
+		}
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { panic("panic val") }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), *job.FinalizedAt, 1*time.Second)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+
+		require.NotNil(t, poisonPillJob)
+		require.Equal(t, bundle.jobRow.ID, poisonPillJob.ID)
+	})
+
+	t.Run("PanicRetriesJobBelowPoisonPillMaxCrashes", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.PoisonPillDetected = func(ctx context.Context, job *rivertype.JobRow) { require.FailNow(t, "poison pill detected") }
+		executor.PoisonPillMaxCrashes = 3
+
+		bundle.jobRow.Attempt = 3
+		bundle.jobRow.Errors = []rivertype.AttemptError{
+			{Attempt: 1, Error: "panic val", Trace: "trace"},
+			{Attempt: 2, Error: "ordinary error"},
+		}
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { panic("panic val") }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     bundle.jobRow.ID,
+			Schema: "",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+	})
+
 	t.Run("PanicWithPanicHandler", func(t *testing.T) {
 		t.Parallel()
 
@@ -1011,7 +1495,7 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.ErrorIs(t, context.Cause(workCtx), errExecutorDefaultCancel)
 	})
 
-	runCancelTest := func(t *testing.T, returnErr error) *rivertype.JobRow { //nolint:thelper
+	runCancelTest := func(t *testing.T, returnErr error, reason string) *rivertype.JobRow { //nolint:thelper
 		executor, bundle := setup(t)
 
 		// ensure we still have remaining attempts:
@@ -1027,7 +1511,7 @@ func TestJobExecutor_Execute(t *testing.T) {
 
 		go func() {
 			<-jobStarted
-			executor.Cancel(ctx)
+			executor.Cancel(ctx, reason)
 			close(haveCancelled)
 		}()
 
@@ -1049,7 +1533,7 @@ func TestJobExecutor_Execute(t *testing.T) {
 	t.Run("RemoteCancellationViaCancel", func(t *testing.T) {
 		t.Parallel()
 
-		job := runCancelTest(t, errors.New("a non-nil error"))
+		job := runCancelTest(t, errors.New("a non-nil error"), "")
 
 		require.WithinDuration(t, time.Now(), *job.FinalizedAt, 2*time.Second)
 		require.Equal(t, rivertype.JobStateCancelled, job.State)
@@ -1061,16 +1545,55 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Empty(t, job.Errors[0].Trace)
 	})
 
+	t.Run("RemoteCancellationWithReason", func(t *testing.T) {
+		t.Parallel()
+
+		job := runCancelTest(t, errors.New("a non-nil error"), "no longer needed")
+
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, "JobCancelError: job cancelled remotely: no longer needed", job.Errors[0].Error)
+	})
+
 	t.Run("RemoteCancellationJobNotCancelledIfNoErrorReturned", func(t *testing.T) {
 		t.Parallel()
 
-		job := runCancelTest(t, nil)
+		job := runCancelTest(t, nil, "")
 
 		require.WithinDuration(t, time.Now(), *job.FinalizedAt, 2*time.Second)
 		require.Equal(t, rivertype.JobStateCompleted, job.State)
 		require.Empty(t, job.Errors)
 	})
 
+	t.Run("RemoteCancellationBeforeStartNotWorked", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		var workCalled bool
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error {
+			workCalled = true
+			return nil
+		}, nil).MakeUnit(bundle.jobRow)
+
+		workCtx, cancelFunc := context.WithCancelCause(ctx)
+		executor.CancelFunc = cancelFunc
+		t.Cleanup(func() { cancelFunc(nil) })
+
+		executor.Cancel(ctx, "no longer needed")
+
+		executor.Execute(workCtx)
+		riversharedtest.WaitOrTimeout(t, bundle.updateCh)
+
+		require.False(t, workCalled)
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: bundle.jobRow.ID, Schema: ""})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, "JobCancelError: job cancelled remotely: no longer needed", job.Errors[0].Error)
+	})
+
 	t.Run("WorkHooks", func(t *testing.T) {
 		t.Parallel()
 
@@ -1183,6 +1706,48 @@ func (f HookWorkBeginFunc) WorkBegin(ctx context.Context, job *rivertype.JobRow)
 
 func (f HookWorkBeginFunc) IsHook() bool { return true }
 
+func TestTruncateMiddle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoLimit", func(t *testing.T) {
+		t.Parallel()
+
+		s := strings.Repeat("x", 1_000)
+		require.Equal(t, s, truncateMiddle(s, 0))
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, "short", truncateMiddle("short", 5))
+	})
+
+	t.Run("KeepsHeadAndTail", func(t *testing.T) {
+		t.Parallel()
+
+		s := "head" + strings.Repeat("x", 1_000) + "tail"
+
+		truncated := truncateMiddle(s, 100)
+		require.LessOrEqual(t, len(truncated), 100)
+		require.True(t, strings.HasPrefix(truncated, "headxxx"))
+		require.True(t, strings.HasSuffix(truncated, "xxxtail"))
+
+		head, tail, ok := strings.Cut(truncated, "\n... [")
+		require.True(t, ok)
+		marker, tail, ok := strings.Cut(tail, " bytes truncated] ...\n")
+		require.True(t, ok)
+		require.Equal(t, strconv.Itoa(len(s)-len(head)-len(tail)), marker)
+	})
+
+	t.Run("CutsOnRuneBoundaries", func(t *testing.T) {
+		t.Parallel()
+
+		truncated := truncateMiddle(strings.Repeat("日本語", 100), 100)
+		require.LessOrEqual(t, len(truncated), 100)
+		require.True(t, utf8.ValidString(truncated))
+	})
+}
+
 type HookWorkEndFunc func(ctx context.Context, job *rivertype.JobRow, err error) error
 
 func (f HookWorkEndFunc) WorkEnd(ctx context.Context, job *rivertype.JobRow, err error) error {
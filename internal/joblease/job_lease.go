@@ -0,0 +1,92 @@
+// Package joblease contains operations on job leases that are shared between
+// producers working queues with a visibility timeout and riverlease, which
+// leases jobs to remote workers.
+package joblease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/internal/poisonpill"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// reapMax is the maximum number of jobs with expired leases reaped at once.
+const reapMax = 1_000
+
+// ReapExpired makes running jobs in the given queue whose leases have expired
+// available to be worked again, or discards them if they're out of attempts
+// (or cancels them if their cancellation was attempted). Returns the number of
+// jobs reaped.
+func ReapExpired(ctx context.Context, exec riverdriver.Executor, schema, queue string, now time.Time) (int, error) {
+	expiredJobs, err := exec.JobGetLeaseExpired(ctx, &riverdriver.JobGetLeaseExpiredParams{
+		Max:    reapMax,
+		Now:    now,
+		Queue:  queue,
+		Schema: schema,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting jobs with expired leases: %w", err)
+	}
+
+	if len(expiredJobs) < 1 {
+		return 0, nil
+	}
+
+	rescueManyParams := riverdriver.JobRescueManyParams{
+		ID:          make([]int64, 0, len(expiredJobs)),
+		Error:       make([][]byte, 0, len(expiredJobs)),
+		FinalizedAt: make([]*time.Time, 0, len(expiredJobs)),
+		ScheduledAt: make([]time.Time, 0, len(expiredJobs)),
+		Schema:      schema,
+		State:       make([]string, 0, len(expiredJobs)),
+	}
+
+	for _, job := range expiredJobs {
+		var metadata struct {
+			CancelAttemptedAt time.Time `json:"cancel_attempted_at"`
+		}
+		if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+			return 0, fmt.Errorf("error unmarshaling job metadata: %w", err)
+		}
+
+		errorData, err := json.Marshal(rivertype.AttemptError{
+			At:      now,
+			Attempt: max(job.Attempt, 0),
+			Error:   poisonpill.ErrorLeaseExpired,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("error marshaling error JSON: %w", err)
+		}
+
+		// Jobs with attempts remaining are made immediately available
+		// again, like a message becoming visible again in a queue after
+		// its visibility timeout lapses.
+		var (
+			finalizedAt *time.Time
+			scheduledAt = now
+			state       = rivertype.JobStateAvailable
+		)
+		switch {
+		case !metadata.CancelAttemptedAt.IsZero():
+			finalizedAt, scheduledAt, state = &now, job.ScheduledAt, rivertype.JobStateCancelled
+		case job.Attempt >= max(job.MaxAttempts, 0):
+			finalizedAt, scheduledAt, state = &now, job.ScheduledAt, rivertype.JobStateDiscarded
+		}
+
+		rescueManyParams.ID = append(rescueManyParams.ID, job.ID)
+		rescueManyParams.Error = append(rescueManyParams.Error, errorData)
+		rescueManyParams.FinalizedAt = append(rescueManyParams.FinalizedAt, finalizedAt)
+		rescueManyParams.ScheduledAt = append(rescueManyParams.ScheduledAt, scheduledAt)
+		rescueManyParams.State = append(rescueManyParams.State, string(state))
+	}
+
+	if _, err := exec.JobRescueMany(ctx, &rescueManyParams); err != nil {
+		return 0, fmt.Errorf("error reaping jobs with expired leases: %w", err)
+	}
+
+	return len(expiredJobs), nil
+}
@@ -3,7 +3,6 @@ package leadership
 import (
 	"cmp"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -13,6 +12,7 @@ import (
 
 	"github.com/riverqueue/river/internal/notifier"
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/testsignal"
@@ -24,24 +24,13 @@ import (
 )
 
 const (
-	electIntervalDefault            = 5 * time.Second
+	ElectIntervalDefault            = 5 * time.Second
+	ElectIntervalTTLPaddingDefault  = 10 * time.Second
 	electIntervalJitterDefault      = 1 * time.Second
-	electIntervalTTLPaddingDefault  = 10 * time.Second
+	electLowPriorityDelay           = 100 * time.Millisecond
 	leaderLocalDeadlineSafetyMargin = 1 * time.Second
 )
 
-type DBNotification struct {
-	Action   DBNotificationKind `json:"action"`
-	LeaderID string             `json:"leader_id"`
-}
-
-type DBNotificationKind string
-
-const (
-	DBNotificationKindRequestResign DBNotificationKind = "request_resign"
-	DBNotificationKindResigned      DBNotificationKind = "resigned"
-)
-
 type Notification struct {
 	IsLeader  bool
 	Timestamp time.Time
@@ -204,7 +193,10 @@ type Config struct {
 	ClientID            string
 	ElectInterval       time.Duration // period on which each elector attempts elect even without having received a resignation notification
 	ElectIntervalJitter time.Duration
+	Priority            int           // clients with a higher priority preempt leaders with a lower one
+	ReelectInterval     time.Duration // period on which the leader reelects itself; defaults to ElectInterval
 	Schema              string
+	TTL                 time.Duration // length of a leadership term; defaults to ReelectInterval plus padding
 }
 
 func (c *Config) mustValidate() *Config {
@@ -214,6 +206,18 @@ func (c *Config) mustValidate() *Config {
 	if c.ElectInterval <= 0 {
 		panic("Config.ElectInterval must be above zero")
 	}
+	if c.Priority < 0 {
+		panic("Config.Priority must be greater or equal to zero")
+	}
+	if c.ReelectInterval < 0 {
+		panic("Config.ReelectInterval must be greater or equal to zero")
+	}
+	if c.TTL < 0 {
+		panic("Config.TTL must be greater or equal to zero")
+	}
+	if c.TTL != 0 && c.TTL <= cmp.Or(c.ReelectInterval, c.ElectInterval) {
+		panic("Config.TTL must be greater than the reelect interval")
+	}
 
 	return c
 }
@@ -231,6 +235,7 @@ type Elector struct {
 	mu                   sync.Mutex
 	isLeader             bool
 	pendingRequestResign bool
+	preempted            bool
 	subscriptions        []*Subscription
 }
 
@@ -280,12 +285,17 @@ func (t leadershipTerm) reelectAttemptTimeout(now time.Time) time.Duration {
 // to the name of the database + schema combo and should be shared across all Clients
 // running with that combination. The id should be unique to the Client.
 func NewElector(archetype *baseservice.Archetype, exec riverdriver.Executor, notifier *notifier.Notifier, config *Config) *Elector {
+	electInterval := cmp.Or(config.ElectInterval, ElectIntervalDefault)
+
 	return baseservice.Init(archetype, &Elector{
 		config: (&Config{
 			ClientID:            config.ClientID,
-			ElectInterval:       cmp.Or(config.ElectInterval, electIntervalDefault),
-			ElectIntervalJitter: cmp.Or(config.ElectIntervalJitter, electIntervalJitterDefault),
+			ElectInterval:       electInterval,
+			ElectIntervalJitter: cmp.Or(config.ElectIntervalJitter, min(electIntervalJitterDefault, electInterval/5)),
+			Priority:            config.Priority,
+			ReelectInterval:     config.ReelectInterval,
 			Schema:              config.Schema,
+			TTL:                 config.TTL,
 		}).mustValidate(),
 		exec:     exec,
 		notifier: notifier,
@@ -374,6 +384,15 @@ func (e *Elector) Start(ctx context.Context) error {
 // runFollowerState is the follower side of the elector state machine. It keeps
 // attempting election until this client becomes leader or the elector stops.
 func (e *Elector) runFollowerState(ctx context.Context) (leadershipTerm, error) {
+	// A leader that resigned to make way for a higher priority client holds
+	// off on bidding so that the preempting client can win the election.
+	if e.takePreempted() {
+		serviceutil.CancellableSleep(ctx, electLowPriorityDelay+randutil.DurationBetween(0, 50*time.Millisecond))
+		if ctx.Err() != nil {
+			return leadershipTerm{}, ctx.Err()
+		}
+	}
+
 	var attempt int
 	for {
 		attempt++
@@ -409,6 +428,10 @@ func (e *Elector) runFollowerState(ctx context.Context) (leadershipTerm, error)
 		e.Logger.DebugContext(ctx, e.Name+": Leadership bid was unsuccessful (not an error)", "client_id", e.config.ClientID)
 		e.testSignals.DeniedLeadership.Signal(struct{}{})
 
+		if e.config.Priority > 0 {
+			e.sendPreempt(ctx)
+		}
+
 		select {
 		case <-serviceutil.CancellableSleepC(ctx, randutil.DurationBetween(e.config.ElectInterval, e.config.ElectInterval+e.config.ElectIntervalJitter)):
 			if ctx.Err() != nil { // context done
@@ -418,11 +441,50 @@ func (e *Elector) runFollowerState(ctx context.Context) (leadershipTerm, error)
 		case <-e.wakeupChan:
 			// Somebody just resigned, try to win the next election after a very
 			// short random interval (to prevent all clients from bidding at once).
-			serviceutil.CancellableSleep(ctx, randutil.DurationBetween(0, 50*time.Millisecond))
+			serviceutil.CancellableSleep(ctx, e.wakeupDelay())
 		}
 	}
 }
 
+// sendPreempt notifies the current leader that a client with this elector's
+// priority would like to take over. Leaders with a lower priority resign in
+// response, and others ignore the notification.
+func (e *Elector) sendPreempt(ctx context.Context) {
+	if e.notifier == nil {
+		return
+	}
+
+	payload, err := rivernotify.EncodeLeadership(&rivernotify.LeadershipPayload{
+		Action:   rivernotify.LeadershipActionPreempt,
+		LeaderID: e.config.ClientID,
+		Priority: e.config.Priority,
+	})
+	if err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Error encoding preempt notification", "client_id", e.config.ClientID, "err", err)
+		return
+	}
+
+	if err := e.exec.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: []string{payload},
+		Schema:  e.config.Schema,
+		Topic:   string(notifier.NotificationTopicLeadership),
+	}); err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Error sending preempt notification", "client_id", e.config.ClientID, "err", err)
+	}
+}
+
+// wakeupDelay is how long a follower waits before bidding after a resignation.
+// A short random interval prevents all clients from bidding at once, and
+// clients without a priority wait a little longer so that prioritized clients
+// are likely to win.
+func (e *Elector) wakeupDelay() time.Duration {
+	delay := randutil.DurationBetween(0, 50*time.Millisecond)
+	if e.config.Priority == 0 {
+		delay += electLowPriorityDelay
+	}
+	return delay
+}
+
 // Handles a leadership notification from the notifier.
 func (e *Elector) handleLeadershipNotification(ctx context.Context, topic notifier.NotificationTopic, payload string) {
 	if topic != notifier.NotificationTopicLeadership {
@@ -431,9 +493,9 @@ func (e *Elector) handleLeadershipNotification(ctx context.Context, topic notifi
 		return
 	}
 
-	notification := DBNotification{}
-	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
-		e.Logger.ErrorContext(ctx, e.Name+": Unable to unmarshal leadership notification", "client_id", e.config.ClientID, "err", err)
+	notification, err := rivernotify.DecodeLeadership(payload)
+	if err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Unable to decode leadership notification", "client_id", e.config.ClientID, "err", err)
 		return
 	}
 
@@ -446,13 +508,25 @@ func (e *Elector) handleLeadershipNotification(ctx context.Context, topic notifi
 	}
 
 	switch notification.Action {
-	case DBNotificationKindRequestResign:
+	case rivernotify.LeadershipActionPreempt:
+		if notification.Priority <= e.config.Priority {
+			return
+		}
+
 		if !e.markPendingRequestResign() {
 			return
 		}
 
+		e.Logger.InfoContext(ctx, e.Name+": Current leader preempted by client with higher priority", "client_id", e.config.ClientID, "preempting_client_id", notification.LeaderID)
+		e.markPreempted()
 		trySendWakeup(ctx, e.wakeupChan)
-	case DBNotificationKindResigned:
+	case rivernotify.LeadershipActionRequestResign:
+		if !e.markPendingRequestResign() {
+			return
+		}
+
+		trySendWakeup(ctx, e.wakeupChan)
+	case rivernotify.LeadershipActionResigned:
 		// If this a resignation from _this_ client, ignore the change.
 		if notification.LeaderID == e.config.ClientID {
 			return
@@ -486,7 +560,7 @@ func (e *Elector) runLeaderState(ctx context.Context, term leadershipTerm) error
 	defer timer.Stop()
 
 	numErrors := 0
-	waitDuration := e.config.ElectInterval
+	waitDuration := e.reelectInterval()
 
 	for {
 		resetTimer(timer, waitDuration)
@@ -566,7 +640,7 @@ func (e *Elector) runLeaderState(ctx context.Context, term leadershipTerm) error
 		numErrors = 0
 		term = newLeadershipTerm(leader.LeaderID, leader.ElectedAt, attemptStarted, e.leaderTTL())
 		e.testSignals.MaintainedLeadership.Signal(struct{}{})
-		waitDuration = e.config.ElectInterval
+		waitDuration = e.reelectInterval()
 	}
 }
 
@@ -643,6 +717,15 @@ func (e *Elector) errorSlogArgs(err error, attempt int, sleepDuration time.Durat
 	}
 }
 
+// IsLeader returns whether the elector currently believes that it holds
+// leadership.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.isLeader
+}
+
 func (e *Elector) Listen() *Subscription {
 	sub := &Subscription{
 		creationTime: time.Now().UTC(),
@@ -688,11 +771,18 @@ func (e *Elector) tryUnlisten(sub *Subscription) bool {
 	return false
 }
 
-// leaderTTL is at least the reelect run interval used by clients to try and gain
-// leadership or reelect themselves as leader, plus a little padding to account
-// to give the leader a little breathing room in its reelection loop.
+// leaderTTL is the configured TTL if there is one, and otherwise the reelect
+// interval used by the leader to reelect itself plus a little padding to give
+// the leader a little breathing room in its reelection loop.
 func (e *Elector) leaderTTL() time.Duration {
-	return e.config.ElectInterval + electIntervalTTLPaddingDefault
+	return cmp.Or(e.config.TTL, e.reelectInterval()+ElectIntervalTTLPaddingDefault)
+}
+
+// reelectInterval is the interval on which the leader reelects itself, which
+// is the configured reelect interval if there is one and the elect interval
+// otherwise.
+func (e *Elector) reelectInterval() time.Duration {
+	return cmp.Or(e.config.ReelectInterval, e.config.ElectInterval)
 }
 
 func (e *Elector) markPendingRequestResign() bool {
@@ -707,6 +797,13 @@ func (e *Elector) markPendingRequestResign() bool {
 	return true
 }
 
+func (e *Elector) markPreempted() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.preempted = true
+}
+
 func (e *Elector) publishLeadershipState(isLeader bool) {
 	notifyTime := time.Now().UTC()
 	e.mu.Lock()
@@ -734,6 +831,15 @@ func (e *Elector) clearPendingRequestResign() {
 	e.pendingRequestResign = false
 }
 
+func (e *Elector) takePreempted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	preempted := e.preempted
+	e.preempted = false
+	return preempted
+}
+
 func (e *Elector) takePendingRequestResign() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
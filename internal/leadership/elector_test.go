@@ -15,6 +15,7 @@ import (
 	"github.com/riverqueue/river/riverdbtest"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riversharedtest"
 	"github.com/riverqueue/river/rivershared/startstoptest"
@@ -162,11 +163,11 @@ func TestElectorHandleLeadershipNotification(t *testing.T) {
 		return data
 	}
 
-	validLeadershipChange := func() *DBNotification {
+	validLeadershipChange := func() *rivernotify.LeadershipPayload {
 		t.Helper()
 
-		return &DBNotification{
-			Action:   DBNotificationKindResigned,
+		return &rivernotify.LeadershipPayload{
+			Action:   rivernotify.LeadershipActionResigned,
 			LeaderID: "other-client-id",
 		}
 	}
@@ -543,8 +544,8 @@ func testElector[TElectorBundle any](
 	signalLeaderResigned := func(ctx context.Context, t *testing.T, elector *Elector, leaderID string) {
 		t.Helper()
 
-		payload, err := json.Marshal(DBNotification{
-			Action:   DBNotificationKindResigned,
+		payload, err := json.Marshal(rivernotify.LeadershipPayload{
+			Action:   rivernotify.LeadershipActionResigned,
 			LeaderID: leaderID,
 		})
 		require.NoError(t, err)
@@ -555,7 +556,7 @@ func testElector[TElectorBundle any](
 	signalRequestResign := func(ctx context.Context, t *testing.T, elector *Elector) {
 		t.Helper()
 
-		payload, err := json.Marshal(DBNotification{Action: DBNotificationKindRequestResign})
+		payload, err := json.Marshal(rivernotify.LeadershipPayload{Action: rivernotify.LeadershipActionRequestResign})
 		require.NoError(t, err)
 
 		elector.handleLeadershipNotification(ctx, notifier.NotificationTopicLeadership, string(payload))
@@ -666,6 +667,35 @@ func testElector[TElectorBundle any](
 		require.ErrorIs(t, err, rivertype.ErrNotFound)
 	})
 
+	t.Run("HigherPriorityPreemptsLeader", func(t *testing.T) {
+		t.Parallel()
+
+		elector1, bundle := setup(t, nil)
+		elector1.config.ClientID = "elector1"
+
+		startElector(ctx, t, elector1)
+		elector1.testSignals.GainedLeadership.WaitOrTimeout()
+
+		elector2 := makeElector(t, bundle.electorBundle)
+		elector2.config.ClientID = "elector2"
+		elector2.config.ElectInterval = 10 * time.Millisecond
+		elector2.config.ElectIntervalJitter = time.Millisecond
+		elector2.config.Priority = 1
+		elector2.exec = elector1.exec
+		elector2.testSignals.Init(t)
+
+		startElector(ctx, t, elector2)
+
+		elector1.testSignals.ResignedLeadership.WaitOrTimeout()
+		elector2.testSignals.GainedLeadership.WaitOrTimeout()
+
+		leader, err := bundle.exec.LeaderGetElectedLeader(ctx, &riverdriver.LeaderGetElectedLeaderParams{
+			Schema: elector1.config.Schema,
+		})
+		require.NoError(t, err)
+		require.Equal(t, elector2.config.ClientID, leader.LeaderID)
+	})
+
 	t.Run("IndependentBundlesAreIsolated", func(t *testing.T) {
 		t.Parallel()
 
@@ -778,6 +808,31 @@ func testElector[TElectorBundle any](
 		require.False(t, notification.IsLeader)
 	})
 
+	t.Run("PreemptIgnoredUnlessHigherPriority", func(t *testing.T) {
+		t.Parallel()
+
+		elector, _ := setup(t, nil)
+		elector.config.Priority = 2
+		elector.wakeupChan = make(chan struct{}, 1)
+		elector.publishLeadershipState(true)
+
+		signalPreempt := func(priority int) {
+			payload, err := json.Marshal(rivernotify.LeadershipPayload{Action: rivernotify.LeadershipActionPreempt, LeaderID: "other_client", Priority: priority})
+			require.NoError(t, err)
+
+			elector.handleLeadershipNotification(ctx, notifier.NotificationTopicLeadership, string(payload))
+		}
+
+		signalPreempt(1)
+		signalPreempt(2)
+		require.False(t, elector.takePendingRequestResign())
+		require.False(t, elector.takePreempted())
+
+		signalPreempt(3)
+		require.True(t, elector.takePendingRequestResign())
+		require.True(t, elector.takePreempted())
+	})
+
 	t.Run("RequestResignImmediatelyAfterElection", func(t *testing.T) {
 		t.Parallel()
 
@@ -889,4 +944,88 @@ func testElector[TElectorBundle any](
 		elector.Stop()
 		elector.testSignals.ResignedLeadership.WaitOrTimeout()
 	})
+
+	t.Run("SustainsLeadershipWithConfiguredReelectIntervalAndTTL", func(t *testing.T) {
+		t.Parallel()
+
+		elector, bundle := setup(t, nil)
+		elector.config.ElectInterval = time.Hour
+		elector.config.ReelectInterval = 10 * time.Millisecond
+		elector.config.TTL = 30 * time.Second
+
+		startElector(ctx, t, elector)
+		elector.testSignals.GainedLeadership.WaitOrTimeout()
+
+		elector.testSignals.MaintainedLeadership.WaitOrTimeout()
+		elector.testSignals.MaintainedLeadership.WaitOrTimeout()
+
+		leader, err := bundle.exec.LeaderGetElectedLeader(ctx, &riverdriver.LeaderGetElectedLeaderParams{
+			Schema: elector.config.Schema,
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(30*time.Second), leader.ExpiresAt, 5*time.Second)
+
+		elector.Stop()
+		elector.testSignals.ResignedLeadership.WaitOrTimeout()
+	})
+}
+
+func TestNewElector(t *testing.T) {
+	t.Parallel()
+
+	newElector := func(config *Config) *Elector {
+		return NewElector(riversharedtest.BaseServiceArchetype(t), nil, nil, config)
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		elector := newElector(&Config{ClientID: "test_client_id"})
+		require.Equal(t, ElectIntervalDefault, elector.config.ElectInterval)
+		require.Equal(t, electIntervalJitterDefault, elector.config.ElectIntervalJitter)
+		require.Equal(t, ElectIntervalDefault, elector.reelectInterval())
+		require.Equal(t, ElectIntervalDefault+ElectIntervalTTLPaddingDefault, elector.leaderTTL())
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Parallel()
+
+		elector := newElector(&Config{
+			ClientID:        "test_client_id",
+			ElectInterval:   500 * time.Millisecond,
+			ReelectInterval: 200 * time.Millisecond,
+			TTL:             time.Second,
+		})
+		require.Equal(t, 500*time.Millisecond, elector.config.ElectInterval)
+		require.Equal(t, 100*time.Millisecond, elector.config.ElectIntervalJitter) // scaled down with a short elect interval
+		require.Equal(t, 200*time.Millisecond, elector.reelectInterval())
+		require.Equal(t, time.Second, elector.leaderTTL())
+	})
+
+	t.Run("TTLDefaultsFromReelectInterval", func(t *testing.T) {
+		t.Parallel()
+
+		elector := newElector(&Config{ClientID: "test_client_id", ReelectInterval: time.Minute})
+		require.Equal(t, time.Minute+ElectIntervalTTLPaddingDefault, elector.leaderTTL())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithValue(t, "Config.Priority must be greater or equal to zero", func() {
+			newElector(&Config{ClientID: "test_client_id", Priority: -1})
+		})
+		require.PanicsWithValue(t, "Config.ReelectInterval must be greater or equal to zero", func() {
+			newElector(&Config{ClientID: "test_client_id", ReelectInterval: -1})
+		})
+		require.PanicsWithValue(t, "Config.TTL must be greater or equal to zero", func() {
+			newElector(&Config{ClientID: "test_client_id", TTL: -1})
+		})
+		require.PanicsWithValue(t, "Config.TTL must be greater than the reelect interval", func() {
+			newElector(&Config{ClientID: "test_client_id", ReelectInterval: time.Second, TTL: time.Second})
+		})
+		require.PanicsWithValue(t, "Config.TTL must be greater than the reelect interval", func() {
+			newElector(&Config{ClientID: "test_client_id", TTL: ElectIntervalDefault})
+		})
+	})
 }
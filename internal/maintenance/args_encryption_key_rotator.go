@@ -0,0 +1,278 @@
+package maintenance
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/internal/argscodec"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/circuitbreaker"
+	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivershared/util/serviceutil"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivershared/util/timeutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const argsEncryptionKeyRotatorIntervalDefault = time.Hour
+
+// ArgsEncryptionKeyRotatorTestSignals are internal signals used exclusively in
+// tests.
+type ArgsEncryptionKeyRotatorTestSignals struct {
+	RotatedBatch testsignal.TestSignal[struct{}] // notifies when runOnce finishes a batch
+}
+
+func (ts *ArgsEncryptionKeyRotatorTestSignals) Init(tb testutil.TestingTB) {
+	ts.RotatedBatch.Init(tb)
+}
+
+type ArgsEncryptionKeyRotatorConfig struct {
+	riversharedmaintenance.BatchSizes
+
+	// Encryptor encrypts and decrypts job args. Jobs with args encrypted with
+	// a key other than its current one are re-encrypted with it.
+	Encryptor argscodec.Encryptor
+
+	// Interval is the amount of time to wait between runs of the rotator.
+	Interval time.Duration
+
+	// Schema where River tables are located. Empty string omits schema, causing
+	// Postgres to default to `search_path`.
+	Schema string
+}
+
+func (c *ArgsEncryptionKeyRotatorConfig) mustValidate() *ArgsEncryptionKeyRotatorConfig {
+	c.MustValidate()
+
+	if c.Encryptor == nil {
+		panic("ArgsEncryptionKeyRotatorConfig.Encryptor must be set")
+	}
+	if c.Interval <= 0 {
+		panic("ArgsEncryptionKeyRotatorConfig.Interval must be above zero")
+	}
+
+	return c
+}
+
+// ArgsEncryptionKeyRotator periodically re-encrypts the args of jobs encrypted
+// with a key other than the encryptor's current one, so that older keys can be
+// retired without stranding jobs that are still waiting to be worked, like
+// ones scheduled far in the future.
+type ArgsEncryptionKeyRotator struct {
+	riversharedmaintenance.QueueMaintainerServiceBase
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	Config      *ArgsEncryptionKeyRotatorConfig
+	TestSignals ArgsEncryptionKeyRotatorTestSignals
+
+	exec riverdriver.Executor
+
+	// Circuit breaker that tracks consecutive timeout failures from the central
+	// query. The query starts by using the full/default batch size, but after
+	// this breaker trips (after N consecutive timeouts occur in a row), it
+	// switches to a smaller batch. We assume that a database that's degraded is
+	// likely to stay degraded over a longer term, so after the circuit breaks,
+	// it stays broken until the program is restarted.
+	reducedBatchSizeBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewArgsEncryptionKeyRotator(archetype *baseservice.Archetype, config *ArgsEncryptionKeyRotatorConfig, exec riverdriver.Executor) *ArgsEncryptionKeyRotator {
+	batchSizes := config.WithDefaults()
+
+	return baseservice.Init(archetype, &ArgsEncryptionKeyRotator{
+		Config: (&ArgsEncryptionKeyRotatorConfig{
+			BatchSizes: batchSizes,
+			Encryptor:  config.Encryptor,
+			Interval:   cmp.Or(config.Interval, argsEncryptionKeyRotatorIntervalDefault),
+			Schema:     config.Schema,
+		}).mustValidate(),
+		exec:                    exec,
+		reducedBatchSizeBreaker: riversharedmaintenance.ReducedBatchSizeBreaker(batchSizes),
+	})
+}
+
+// RunOnce synchronously runs a single pass of the rotator, re-encrypting the
+// args of jobs encrypted with keys other than the current one.
+func (s *ArgsEncryptionKeyRotator) RunOnce(ctx context.Context) error {
+	_, err := s.runOnce(ctx)
+	return err
+}
+
+func (s *ArgsEncryptionKeyRotator) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	s.StaggerStart(ctx)
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		s.Logger.DebugContext(ctx, s.Name+riversharedmaintenance.LogPrefixRunLoopStarted)
+		defer s.Logger.DebugContext(ctx, s.Name+riversharedmaintenance.LogPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.Config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			startedAt := s.Time.Now()
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error rotating args encryption keys", slog.String("error", err.Error()))
+				}
+				continue
+			}
+
+			s.SetLastRunAt(s.Time.Now())
+			s.RecordRun(ctx, &riversharedmaintenance.Run{FinishedAt: s.Time.Now(), NumRows: res.NumJobsRotated, StartedAt: startedAt})
+
+			if res.NumJobsRotated > 0 || res.NumJobsFailed > 0 {
+				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
+					slog.Int("num_jobs_failed", res.NumJobsFailed),
+					slog.Int("num_jobs_rotated", res.NumJobsRotated),
+				)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *ArgsEncryptionKeyRotator) batchSize() int {
+	if s.reducedBatchSizeBreaker.Open() {
+		return s.Config.Reduced
+	}
+	return s.Config.Default
+}
+
+type argsEncryptionKeyRotatorRunOnceResult struct {
+	NumJobsFailed  int
+	NumJobsRotated int
+}
+
+func (s *ArgsEncryptionKeyRotator) runOnce(ctx context.Context) (*argsEncryptionKeyRotatorRunOnceResult, error) {
+	res := &argsEncryptionKeyRotatorRunOnceResult{}
+
+	keyID := s.Config.Encryptor.KeyID()
+	if keyID == "" {
+		return nil, errors.New("ArgsEncryptor key ID cannot be empty")
+	}
+
+	// Jobs that fail to be re-encrypted, like because they were encrypted
+	// with a key the encryptor no longer knows about, remain stale, so each
+	// pass pages through jobs by ID to avoid fetching them again.
+	var afterID int64
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		jobs, err := func() ([]*rivertype.JobRow, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, riversharedmaintenance.TimeoutDefault)
+			defer cancelFunc()
+
+			jobs, err := s.exec.JobGetArgsEncryptionKeyStale(ctx, &riverdriver.JobGetArgsEncryptionKeyStaleParams{
+				AfterID:      afterID,
+				CurrentKeyID: keyID,
+				Max:          s.batchSize(),
+				Schema:       s.Config.Schema,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error getting jobs with stale args encryption keys: %w", err)
+			}
+
+			s.reducedBatchSizeBreaker.ResetIfNotOpen()
+
+			return jobs, nil
+		}()
+		if err != nil {
+			if riversharedmaintenance.IsBatchTimeout(err) {
+				s.reducedBatchSizeBreaker.Trip()
+			}
+
+			return nil, err
+		}
+
+		for _, job := range jobs {
+			afterID = job.ID
+
+			rotated, err := s.rotateJob(ctx, job, keyID)
+			if err != nil {
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					return nil, err
+				}
+
+				s.Logger.WarnContext(ctx, s.Name+": Error re-encrypting job args",
+					slog.String("error", err.Error()),
+					slog.Int64("job_id", job.ID),
+				)
+				res.NumJobsFailed++
+				continue
+			}
+
+			if rotated {
+				res.NumJobsRotated++
+			}
+		}
+
+		s.TestSignals.RotatedBatch.Signal(struct{}{})
+
+		// Fetched was less than query `LIMIT` which means work is done.
+		if len(jobs) < s.batchSize() {
+			break
+		}
+
+		serviceutil.CancellableSleep(ctx, randutil.DurationBetween(riversharedmaintenance.BatchBackoffMin, riversharedmaintenance.BatchBackoffMax))
+	}
+
+	return res, nil
+}
+
+// rotateJob re-encrypts a single job's args with the key keyID. Returns false
+// if the job was deleted or its args were changed since it was fetched, in
+// which case there's nothing to do.
+func (s *ArgsEncryptionKeyRotator) rotateJob(ctx context.Context, job *rivertype.JobRow, keyID string) (bool, error) {
+	previousKeyID := gjson.GetBytes(job.Metadata, rivertype.MetadataKeyArgsEncryptionKeyID).String()
+
+	encodedArgs, err := argscodec.Reencrypt(s.Config.Encryptor, job.EncodedArgs, previousKeyID, keyID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, riversharedmaintenance.TimeoutDefault)
+	defer cancelFunc()
+
+	if _, err := s.exec.JobUpdateArgsEncryptionKey(ctx, &riverdriver.JobUpdateArgsEncryptionKeyParams{
+		Args:          encodedArgs,
+		ID:            job.ID,
+		KeyID:         keyID,
+		PreviousKeyID: previousKeyID,
+		Schema:        s.Config.Schema,
+	}); err != nil {
+		if errors.Is(err, rivertype.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,236 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/argscodec"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// xorEncryptor is an insecure argscodec.Encryptor for tests that XORs data
+// with a single byte key.
+type xorEncryptor struct {
+	keyID string
+	keys  map[string]byte
+}
+
+func (e *xorEncryptor) KeyID() string { return e.keyID }
+
+func (e *xorEncryptor) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return e.xor(keyID, plaintext)
+}
+
+func (e *xorEncryptor) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return e.xor(keyID, ciphertext)
+}
+
+func (e *xorEncryptor) xor(keyID string, data []byte) ([]byte, error) {
+	key, ok := e.keys[keyID]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ key
+	}
+	return out, nil
+}
+
+func TestArgsEncryptionKeyRotator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testArgs struct {
+		Name string `json:"name"`
+	}
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*ArgsEncryptionKeyRotator, *testBundle) {
+		t.Helper()
+
+		tx := riverdbtest.TestTxPgx(ctx, t)
+		bundle := &testBundle{
+			exec: riverpgxv5.New(nil).UnwrapExecutor(tx),
+		}
+
+		rotator := NewArgsEncryptionKeyRotator(
+			riversharedtest.BaseServiceArchetype(t),
+			&ArgsEncryptionKeyRotatorConfig{
+				Encryptor: &xorEncryptor{keyID: "key2", keys: map[string]byte{"key1": 0x2a, "key2": 0x3b}},
+				Interval:  argsEncryptionKeyRotatorIntervalDefault,
+			},
+			bundle.exec)
+		rotator.StaggerStartupDisable(true)
+		rotator.TestSignals.Init(t)
+		t.Cleanup(rotator.Stop)
+
+		return rotator, bundle
+	}
+
+	insertEncryptedJob := func(t *testing.T, exec riverdriver.Executor, encryptor argscodec.Encryptor, name string) *rivertype.JobRow {
+		t.Helper()
+
+		encodedArgs, metadata, err := (&argscodec.Encoder{Encryptor: encryptor}).Encode(testArgs{Name: name}, nil)
+		require.NoError(t, err)
+
+		return testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: encodedArgs, Metadata: metadata})
+	}
+
+	decodeArgs := func(t *testing.T, rotator *ArgsEncryptionKeyRotator, job *rivertype.JobRow) testArgs {
+		t.Helper()
+
+		var args testArgs
+		require.NoError(t, argscodec.NewDecoder(nil, nil, rotator.Config.Encryptor).Decode(job, &args))
+		return args
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		rotator := NewArgsEncryptionKeyRotator(riversharedtest.BaseServiceArchetype(t), &ArgsEncryptionKeyRotatorConfig{
+			Encryptor: &xorEncryptor{},
+		}, nil)
+
+		require.Equal(t, argsEncryptionKeyRotatorIntervalDefault, rotator.Config.Interval)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, _ := setup(t)
+		rotator.Logger = riversharedtest.LoggerWarn(t)              // loop started/stop log is very noisy; suppress
+		rotator.TestSignals = ArgsEncryptionKeyRotatorTestSignals{} // deinit so channels don't fill
+
+		startstoptest.Stress(ctx, t, rotator)
+	})
+
+	t.Run("ReencryptsStaleJobs", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, bundle := setup(t)
+
+		oldEncryptor := &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}
+
+		staleJob := insertEncryptedJob(t, bundle.exec, oldEncryptor, "stale")
+		currentJob := insertEncryptedJob(t, bundle.exec, rotator.Config.Encryptor, "current")
+		unencryptedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"name":"unencrypted"}`)})
+
+		require.NoError(t, rotator.Start(ctx))
+
+		rotator.TestSignals.RotatedBatch.WaitOrTimeout()
+
+		staleJob, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: staleJob.ID})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"river:args_encryption_key_id":"key2"}`, string(staleJob.Metadata))
+		require.Equal(t, testArgs{Name: "stale"}, decodeArgs(t, rotator, staleJob))
+
+		currentJobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: currentJob.ID})
+		require.NoError(t, err)
+		require.Equal(t, currentJob.EncodedArgs, currentJobAfter.EncodedArgs)
+
+		unencryptedJobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: unencryptedJob.ID})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"unencrypted"}`, string(unencryptedJobAfter.EncodedArgs))
+	})
+
+	t.Run("ReencryptsInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, bundle := setup(t)
+		rotator.Config.Default = 10 // reduced size for test speed
+
+		oldEncryptor := &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numJobs := rotator.Config.Default + 1
+
+		jobs := make([]*rivertype.JobRow, numJobs)
+		for i := range numJobs {
+			jobs[i] = insertEncryptedJob(t, bundle.exec, oldEncryptor, "stale")
+		}
+
+		require.NoError(t, rotator.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		rotator.TestSignals.RotatedBatch.WaitOrTimeout()
+		rotator.TestSignals.RotatedBatch.WaitOrTimeout()
+
+		for _, job := range jobs {
+			job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.Equal(t, testArgs{Name: "stale"}, decodeArgs(t, rotator, job))
+		}
+	})
+
+	t.Run("SkipsJobsWithUnknownKeys", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, bundle := setup(t)
+
+		unknownJob := insertEncryptedJob(t, bundle.exec, &xorEncryptor{keyID: "key0", keys: map[string]byte{"key0": 0x1f}}, "unknown")
+		staleJob := insertEncryptedJob(t, bundle.exec, &xorEncryptor{keyID: "key1", keys: map[string]byte{"key1": 0x2a}}, "stale")
+
+		res, err := rotator.runOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, &argsEncryptionKeyRotatorRunOnceResult{NumJobsFailed: 1, NumJobsRotated: 1}, res)
+
+		unknownJobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: unknownJob.ID})
+		require.NoError(t, err)
+		require.Equal(t, unknownJob.EncodedArgs, unknownJobAfter.EncodedArgs)
+
+		staleJob, err = bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: staleJob.ID})
+		require.NoError(t, err)
+		require.Equal(t, testArgs{Name: "stale"}, decodeArgs(t, rotator, staleJob))
+	})
+
+	t.Run("CustomizableInterval", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, _ := setup(t)
+		rotator.Config.Interval = 1 * time.Microsecond
+
+		require.NoError(t, rotator.Start(ctx))
+
+		// This should trigger ~immediately every time:
+		for i := range 5 {
+			t.Logf("Iteration %d", i)
+			rotator.TestSignals.RotatedBatch.WaitOrTimeout()
+		}
+	})
+
+	t.Run("RespectsContextCancellation", func(t *testing.T) {
+		t.Parallel()
+
+		rotator, _ := setup(t)
+		rotator.Config.Interval = time.Minute // should only trigger once for the initial run
+
+		ctx, cancelFunc := context.WithCancel(ctx)
+
+		require.NoError(t, rotator.Start(ctx))
+
+		// To avoid a potential race, make sure to get a reference to the
+		// service's stopped channel _before_ cancellation as it's technically
+		// possible for the cancel to "win" and remove the stopped channel
+		// before we can start waiting on it.
+		stopped := rotator.Stopped()
+		cancelFunc()
+		riversharedtest.WaitOrTimeout(t, stopped)
+	})
+}
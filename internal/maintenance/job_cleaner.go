@@ -126,6 +126,13 @@ func NewJobCleaner(archetype *baseservice.Archetype, config *JobCleanerConfig, e
 	})
 }
 
+// RunOnce synchronously runs a single pass of the cleaner, deleting finalized
+// jobs that are past their retention period.
+func (s *JobCleaner) RunOnce(ctx context.Context) error {
+	_, err := s.runOnce(ctx)
+	return err
+}
+
 func (s *JobCleaner) Start(ctx context.Context) error { //nolint:dupl
 	ctx, shouldStart, started, stopped := s.StartInit(ctx)
 	if !shouldStart {
@@ -149,14 +156,21 @@ func (s *JobCleaner) Start(ctx context.Context) error { //nolint:dupl
 			case <-ticker.C:
 			}
 
+			startedAt := s.Time.Now()
+
 			res, err := s.runOnce(ctx)
 			if err != nil {
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
 				if !errors.Is(err, context.Canceled) {
 					s.Logger.ErrorContext(ctx, s.Name+": Error cleaning jobs", slog.String("error", err.Error()))
 				}
 				continue
 			}
 
+			s.SetLastRunAt(s.Time.Now())
+			s.RecordRun(ctx, &riversharedmaintenance.Run{FinishedAt: s.Time.Now(), NumRows: res.NumJobsDeleted, StartedAt: startedAt})
+
 			if res.NumJobsDeleted > 0 {
 				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
 					slog.Int("num_jobs_deleted", res.NumJobsDeleted),
@@ -216,7 +230,7 @@ func (s *JobCleaner) runOnce(ctx context.Context) (*jobCleanerRunOnceResult, err
 			return numDeleted, nil
 		}()
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
+			if riversharedmaintenance.IsBatchTimeout(err) {
 				s.reducedBatchSizeBreaker.Trip()
 			}
 
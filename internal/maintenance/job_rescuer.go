@@ -9,7 +9,9 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/poisonpill"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/baseservice"
@@ -43,6 +45,10 @@ func (ts *JobRescuerTestSignals) Init(tb testutil.TestingTB) {
 type JobRescuerConfig struct {
 	riversharedmaintenance.BatchSizes
 
+	// ArgsDecoder decodes the args of rescued jobs according to the codec they
+	// were encoded with. Nil only decodes JSON.
+	ArgsDecoder *argscodec.Decoder
+
 	// ClientRetryPolicy is the default retry policy to use for workers that don't
 	// override NextRetry.
 	ClientRetryPolicy jobexecutor.ClientRetryPolicy
@@ -50,6 +56,15 @@ type JobRescuerConfig struct {
 	// Interval is the amount of time to wait between runs of the rescuer.
 	Interval time.Duration
 
+	// PoisonPillDetected is invoked with each job discarded as a poison pill
+	// after it's been rescued. It may be nil.
+	PoisonPillDetected func(ctx context.Context, job *rivertype.JobRow)
+
+	// PoisonPillMaxCrashes is the number of consecutive crashes after which a
+	// rescued job is discarded as a poison pill instead of being retried,
+	// counting the one it's being rescued from. Zero disables detection.
+	PoisonPillMaxCrashes int
+
 	// RescueAfter is the amount of time for a job to be active before it is
 	// considered stuck and should be rescued.
 	RescueAfter time.Duration
@@ -106,18 +121,28 @@ func NewRescuer(archetype *baseservice.Archetype, config *JobRescuerConfig, exec
 
 	return baseservice.Init(archetype, &JobRescuer{
 		Config: (&JobRescuerConfig{
-			BatchSizes:          batchSizes,
-			ClientRetryPolicy:   config.ClientRetryPolicy,
-			Interval:            cmp.Or(config.Interval, JobRescuerIntervalDefault),
-			RescueAfter:         cmp.Or(config.RescueAfter, JobRescuerRescueAfterDefault),
-			Schema:              config.Schema,
-			WorkUnitFactoryFunc: config.WorkUnitFactoryFunc,
+			ArgsDecoder:          config.ArgsDecoder,
+			BatchSizes:           batchSizes,
+			ClientRetryPolicy:    config.ClientRetryPolicy,
+			Interval:             cmp.Or(config.Interval, JobRescuerIntervalDefault),
+			PoisonPillDetected:   config.PoisonPillDetected,
+			PoisonPillMaxCrashes: config.PoisonPillMaxCrashes,
+			RescueAfter:          cmp.Or(config.RescueAfter, JobRescuerRescueAfterDefault),
+			Schema:               config.Schema,
+			WorkUnitFactoryFunc:  config.WorkUnitFactoryFunc,
 		}).mustValidate(),
 		exec:                    exec,
 		reducedBatchSizeBreaker: riversharedmaintenance.ReducedBatchSizeBreaker(batchSizes),
 	})
 }
 
+// RunOnce synchronously runs a single pass of the rescuer, rescuing jobs that
+// have been running for longer than RescueAfter.
+func (s *JobRescuer) RunOnce(ctx context.Context) error {
+	_, err := s.runOnce(ctx)
+	return err
+}
+
 func (s *JobRescuer) Start(ctx context.Context) error {
 	ctx, shouldStart, started, stopped := s.StartInit(ctx)
 	if !shouldStart {
@@ -141,14 +166,21 @@ func (s *JobRescuer) Start(ctx context.Context) error {
 			case <-ticker.C:
 			}
 
+			startedAt := s.Time.Now()
+
 			res, err := s.runOnce(ctx)
 			if err != nil {
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
 				if !errors.Is(err, context.Canceled) {
 					s.Logger.ErrorContext(ctx, s.Name+": Error rescuing jobs", slog.String("error", err.Error()))
 				}
 				continue
 			}
 
+			s.SetLastRunAt(s.Time.Now())
+			s.RecordRun(ctx, &riversharedmaintenance.Run{FinishedAt: s.Time.Now(), NumRows: int(res.NumJobsCancelled + res.NumJobsDiscarded + res.NumJobsRetried), StartedAt: startedAt})
+
 			if res.NumJobsDiscarded > 0 || res.NumJobsRetried > 0 {
 				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
 					slog.Int64("num_jobs_discarded", res.NumJobsDiscarded),
@@ -184,7 +216,7 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 	for {
 		stuckJobs, err := s.getStuckJobs(ctx)
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
+			if riversharedmaintenance.IsBatchTimeout(err) {
 				s.reducedBatchSizeBreaker.Trip()
 			}
 
@@ -197,6 +229,8 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 
 		now := time.Now().UTC()
 
+		var poisonPillJobs []*rivertype.JobRow
+
 		rescueManyParams := riverdriver.JobRescueManyParams{
 			ID:          make([]int64, 0, len(stuckJobs)),
 			Error:       make([][]byte, 0, len(stuckJobs)),
@@ -215,7 +249,7 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 			errorData, err := json.Marshal(rivertype.AttemptError{
 				At:      now,
 				Attempt: max(job.Attempt, 0),
-				Error:   "Stuck job rescued by JobRescuer",
+				Error:   poisonpill.ErrorRescued,
 				Trace:   "",
 			})
 			if err != nil {
@@ -236,6 +270,15 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 				continue
 			}
 
+			// A job that keeps killing the process working it is discarded
+			// rather than being retried to crash another one.
+			if s.Config.PoisonPillMaxCrashes > 0 && poisonpill.ConsecutiveCrashes(job.Errors)+1 >= s.Config.PoisonPillMaxCrashes {
+				res.NumJobsDiscarded++
+				addRescueParam(rivertype.JobStateDiscarded, &now, job.ScheduledAt) // reused previous scheduled value
+				poisonPillJobs = append(poisonPillJobs, job)
+				continue
+			}
+
 			retryDecision, retryAt := s.makeRetryDecision(ctx, job, now)
 
 			switch retryDecision {
@@ -259,6 +302,12 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 			}
 		}
 
+		if s.Config.PoisonPillDetected != nil {
+			for _, job := range poisonPillJobs {
+				s.Config.PoisonPillDetected(ctx, job)
+			}
+		}
+
 		s.TestSignals.UpdatedBatch.Signal(struct{}{})
 
 		// Number of rows fetched was less than query `LIMIT` which means work is
@@ -277,10 +326,12 @@ func (s *JobRescuer) getStuckJobs(ctx context.Context) ([]*rivertype.JobRow, err
 	ctx, cancelFunc := context.WithTimeout(ctx, riversharedmaintenance.TimeoutDefault)
 	defer cancelFunc()
 
-	stuckHorizon := time.Now().Add(-s.Config.RescueAfter)
+	now := time.Now()
+	stuckHorizon := now.Add(-s.Config.RescueAfter)
 
 	return s.exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
 		Max:          s.batchSize(),
+		Now:          now,
 		Schema:       s.Config.Schema,
 		StuckHorizon: stuckHorizon,
 	})
@@ -307,7 +358,7 @@ func (s *JobRescuer) makeRetryDecision(ctx context.Context, job *rivertype.JobRo
 	}
 
 	workUnit := workUnitFactory.MakeUnit(job)
-	if err := workUnit.UnmarshalJob(); err != nil {
+	if err := workUnit.UnmarshalJob(s.Config.ArgsDecoder); err != nil {
 		s.Logger.ErrorContext(ctx, s.Name+": Error unmarshaling job args: %s"+err.Error(),
 			slog.String("job_kind", job.Kind), slog.Int64("job_id", job.ID))
 	}
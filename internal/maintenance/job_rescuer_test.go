@@ -2,6 +2,7 @@ package maintenance
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"testing"
@@ -9,7 +10,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
+	"github.com/riverqueue/river/internal/poisonpill"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdbtest"
 	"github.com/riverqueue/river/riverdriver"
@@ -46,8 +49,9 @@ func (w *callbackWorkUnit) HookLookup(cache *hooklookup.JobHookLookup) hooklooku
 func (w *callbackWorkUnit) Middleware() []rivertype.WorkerMiddleware { return nil }
 func (w *callbackWorkUnit) NextRetry() time.Time                     { return time.Now().Add(30 * time.Second) }
 func (w *callbackWorkUnit) Timeout() time.Duration                   { return w.timeout }
+func (w *callbackWorkUnit) TimeoutGracePeriod() time.Duration        { return 0 }
 func (w *callbackWorkUnit) Work(ctx context.Context) error           { return w.callback(ctx, w.jobRow) }
-func (w *callbackWorkUnit) UnmarshalJob() error                      { return nil }
+func (w *callbackWorkUnit) UnmarshalJob(*argscodec.Decoder) error    { return nil }
 
 type SimpleClientRetryPolicy struct{}
 
@@ -228,6 +232,52 @@ func TestJobRescuer(t *testing.T) {
 		require.Equal(t, rivertype.JobStateRetryable, notTimedOutJob2After.State)
 	})
 
+	t.Run("DiscardsPoisonPills", func(t *testing.T) {
+		t.Parallel()
+
+		rescuer, bundle := setup(t)
+
+		var poisonPillJobs []*rivertype.JobRow
+		rescuer.Config.PoisonPillDetected = func(ctx context.Context, job *rivertype.JobRow) { poisonPillJobs = append(poisonPillJobs, job) }
+		rescuer.Config.PoisonPillMaxCrashes = 3
+
+		mustMarshalError := func(attemptErr rivertype.AttemptError) []byte {
+			errData, err := json.Marshal(attemptErr)
+			require.NoError(t, err)
+			return errData
+		}
+
+		// Crashed twice before, so being rescued is its third crash in a row.
+		poisonPillJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), Attempt: ptrutil.Ptr(3), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), Errors: [][]byte{
+			mustMarshalError(rivertype.AttemptError{Attempt: 1, Error: "panic val", Trace: "trace"}),
+			mustMarshalError(rivertype.AttemptError{Attempt: 2, Error: poisonpill.ErrorLeaseExpired}),
+		}, MaxAttempts: ptrutil.Ptr(5)})
+
+		// Crashed twice, but not consecutively.
+		retriedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), Attempt: ptrutil.Ptr(3), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), Errors: [][]byte{
+			mustMarshalError(rivertype.AttemptError{Attempt: 1, Error: poisonpill.ErrorRescued}),
+			mustMarshalError(rivertype.AttemptError{Attempt: 2, Error: "ordinary error"}),
+		}, MaxAttempts: ptrutil.Ptr(5)})
+
+		require.NoError(t, rescuer.Start(ctx))
+
+		rescuer.TestSignals.FetchedBatch.WaitOrTimeout()
+		rescuer.TestSignals.UpdatedBatch.WaitOrTimeout()
+
+		poisonPillJobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: poisonPillJob.ID, Schema: rescuer.Config.Schema})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, poisonPillJobAfter.State)
+		require.NotNil(t, poisonPillJobAfter.FinalizedAt)
+		require.Len(t, poisonPillJobAfter.Errors, 3)
+
+		retriedJobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: retriedJob.ID, Schema: rescuer.Config.Schema})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, retriedJobAfter.State)
+
+		require.Len(t, poisonPillJobs, 1)
+		require.Equal(t, poisonPillJob.ID, poisonPillJobs[0].ID)
+	})
+
 	t.Run("RescuesInBatches", func(t *testing.T) {
 		t.Parallel()
 
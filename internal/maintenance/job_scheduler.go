@@ -106,6 +106,13 @@ func NewJobScheduler(archetype *baseservice.Archetype, config *JobSchedulerConfi
 	})
 }
 
+// RunOnce synchronously runs a single pass of the scheduler, making scheduled
+// and retryable jobs that have come due available.
+func (s *JobScheduler) RunOnce(ctx context.Context) error {
+	_, err := s.runOnce(ctx)
+	return err
+}
+
 func (s *JobScheduler) Start(ctx context.Context) error { //nolint:dupl
 	ctx, shouldStart, started, stopped := s.StartInit(ctx)
 	if !shouldStart {
@@ -129,14 +136,21 @@ func (s *JobScheduler) Start(ctx context.Context) error { //nolint:dupl
 			case <-ticker.C:
 			}
 
+			startedAt := s.Time.Now()
+
 			res, err := s.runOnce(ctx)
 			if err != nil {
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
 				if !errors.Is(err, context.Canceled) {
 					s.Logger.ErrorContext(ctx, s.Name+": Error scheduling jobs", slog.String("error", err.Error()))
 				}
 				continue
 			}
 
+			s.SetLastRunAt(s.Time.Now())
+			s.RecordRun(ctx, &riversharedmaintenance.Run{FinishedAt: s.Time.Now(), NumRows: res.NumCompletedJobsScheduled, StartedAt: startedAt})
+
 			if res.NumCompletedJobsScheduled > 0 {
 				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
 					slog.Int("num_jobs_scheduled", res.NumCompletedJobsScheduled),
@@ -214,7 +228,7 @@ func (s *JobScheduler) runOnce(ctx context.Context) (*schedulerRunOnceResult, er
 			return len(scheduledJobResults), execTx.Commit(ctx)
 		}()
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
+			if riversharedmaintenance.IsBatchTimeout(err) {
 				s.reducedBatchSizeBreaker.Trip()
 			}
 
@@ -310,6 +310,19 @@ func (s *PeriodicJobEnqueuer) removeJobLockFree(periodicJob *PeriodicJob, period
 	delete(s.periodicJobIDs, periodicJob.ID)
 }
 
+// RunOnce synchronously inserts jobs for every periodic job that's come due,
+// including multiple runs of the same periodic job if more than one of its
+// runs has elapsed since the last check. Periodic jobs are only scheduled once
+// the enqueuer has been started, so it has no effect before then.
+func (s *PeriodicJobEnqueuer) RunOnce(ctx context.Context) error {
+	for s.insertDueJobs(ctx) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *PeriodicJobEnqueuer) Start(ctx context.Context) error {
 	ctx, shouldStart, started, stopped := s.StartInit(ctx)
 	if !shouldStart {
@@ -447,48 +460,8 @@ func (s *PeriodicJobEnqueuer) Start(ctx context.Context) error {
 		for {
 			select {
 			case <-timerUntilNextRun.C:
-				var (
-					insertParamsMany        []*rivertype.JobInsertParams
-					periodicJobUpsertParams = &riverpilot.PeriodicJobUpsertManyParams{Schema: s.Config.Schema}
-				)
-
-				now := s.Time.Now()
-
-				// Add a small margin to the current time so we're not only
-				// running jobs that are already ready, but also ones ready at
-				// this exact moment or ready in the very near future.
-				nowWithMargin := now.Add(100 * time.Millisecond)
-
-				func() {
-					s.mu.RLock()
-					defer s.mu.RUnlock()
-
-					for _, periodicJob := range s.periodicJobs {
-						if periodicJob.nextRunAt.IsZero() || !periodicJob.nextRunAt.Before(nowWithMargin) {
-							continue
-						}
-
-						if insertParams, ok := s.insertParamsFromConstructor(ctx, periodicJob.ID, periodicJob.ConstructorFunc, periodicJob.nextRunAt); ok {
-							insertParamsMany = append(insertParamsMany, insertParams)
-						}
-
-						// Although we may have inserted a new job a little
-						// preemptively due to the margin applied above, try to stay
-						// as true as possible to the original schedule by using the
-						// original run time when calculating the next one.
-						periodicJob.nextRunAt = periodicJob.ScheduleFunc(periodicJob.nextRunAt)
-
-						if periodicJob.ID != "" {
-							periodicJobUpsertParams.Jobs = append(periodicJobUpsertParams.Jobs, &riverpilot.PeriodicJobUpsertParams{
-								ID:        periodicJob.ID,
-								NextRunAt: periodicJob.nextRunAt,
-								UpdatedAt: s.Time.Now(),
-							})
-						}
-					}
-				}()
-
-				s.insertBatch(ctx, insertParamsMany, periodicJobUpsertParams)
+				s.insertDueJobs(ctx)
+				s.SetLastRunAt(s.Time.Now())
 
 			case <-s.recalculateNextRun:
 				if !timerUntilNextRun.Stop() {
@@ -518,6 +491,60 @@ func (s *PeriodicJobEnqueuer) Start(ctx context.Context) error {
 	return nil
 }
 
+// Inserts jobs for any periodic jobs whose next run is due, and advances their
+// next run times. Returns the number of periodic jobs that were due.
+func (s *PeriodicJobEnqueuer) insertDueJobs(ctx context.Context) int {
+	var (
+		insertParamsMany        []*rivertype.JobInsertParams
+		numDue                  int
+		periodicJobUpsertParams = &riverpilot.PeriodicJobUpsertManyParams{Schema: s.Config.Schema}
+	)
+
+	now := s.Time.Now()
+
+	// Add a small margin to the current time so we're not only running jobs
+	// that are already ready, but also ones ready at this exact moment or ready
+	// in the very near future.
+	nowWithMargin := now.Add(100 * time.Millisecond)
+
+	func() {
+		// Take a full lock because next run times are modified, and this may be
+		// invoked by RunOnce concurrently with the run loop.
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, periodicJob := range s.periodicJobs {
+			if periodicJob.nextRunAt.IsZero() || !periodicJob.nextRunAt.Before(nowWithMargin) {
+				continue
+			}
+
+			numDue++
+
+			if insertParams, ok := s.insertParamsFromConstructor(ctx, periodicJob.ID, periodicJob.ConstructorFunc, periodicJob.nextRunAt); ok {
+				insertParamsMany = append(insertParamsMany, insertParams)
+			}
+
+			// Although we may have inserted a new job a little preemptively due
+			// to the margin applied above, try to stay as true as possible to
+			// the original schedule by using the original run time when
+			// calculating the next one.
+			periodicJob.nextRunAt = periodicJob.ScheduleFunc(periodicJob.nextRunAt)
+
+			if periodicJob.ID != "" {
+				periodicJobUpsertParams.Jobs = append(periodicJobUpsertParams.Jobs, &riverpilot.PeriodicJobUpsertParams{
+					ID:        periodicJob.ID,
+					NextRunAt: periodicJob.nextRunAt,
+					UpdatedAt: s.Time.Now(),
+				})
+			}
+		}
+	}()
+
+	s.insertBatch(ctx, insertParamsMany, periodicJobUpsertParams)
+
+	return numDue
+}
+
 func (s *PeriodicJobEnqueuer) insertBatch(ctx context.Context, insertParamsMany []*rivertype.JobInsertParams, periodicJobUpsertParams *riverpilot.PeriodicJobUpsertManyParams) {
 	if len(insertParamsMany) < 1 && len(periodicJobUpsertParams.Jobs) < 1 {
 		return
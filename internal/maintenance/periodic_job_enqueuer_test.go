@@ -381,6 +381,31 @@ func TestPeriodicJobEnqueuer(t *testing.T) {
 		svc.TestSignals.SkippedJob.WaitOrTimeout()
 	})
 
+	t.Run("RunOnceInsertsAllDueRuns", func(t *testing.T) {
+		t.Parallel()
+
+		svc, bundle := setup(t)
+
+		now := svc.Time.StubNow(time.Now().UTC())
+
+		_, err := svc.AddManySafely([]*PeriodicJob{
+			{ScheduleFunc: periodicIntervalSchedule(time.Hour), ConstructorFunc: jobConstructorFunc("periodic_job_1h", false)},
+		})
+		require.NoError(t, err)
+
+		startService(t, svc)
+		svc.TestSignals.EnteredLoop.WaitOrTimeout()
+
+		// Nothing is due yet.
+		require.NoError(t, svc.RunOnce(ctx))
+		requireNJobs(t, bundle, "periodic_job_1h", 0)
+
+		// Two runs have elapsed, and both are inserted.
+		svc.Time.StubNow(now.Add(2*time.Hour + time.Minute))
+		require.NoError(t, svc.RunOnce(ctx))
+		requireNJobs(t, bundle, "periodic_job_1h", 2)
+	})
+
 	t.Run("InitialScheduling", func(t *testing.T) {
 		t.Parallel()
 
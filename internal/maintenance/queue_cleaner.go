@@ -99,6 +99,13 @@ func NewQueueCleaner(archetype *baseservice.Archetype, config *QueueCleanerConfi
 	})
 }
 
+// RunOnce synchronously runs a single pass of the cleaner, deleting queues that
+// haven't been updated within the retention period.
+func (s *QueueCleaner) RunOnce(ctx context.Context) error {
+	_, err := s.runOnce(ctx)
+	return err
+}
+
 func (s *QueueCleaner) Start(ctx context.Context) error {
 	ctx, shouldStart, started, stopped := s.StartInit(ctx)
 	if !shouldStart {
@@ -122,14 +129,21 @@ func (s *QueueCleaner) Start(ctx context.Context) error {
 			case <-ticker.C:
 			}
 
+			startedAt := s.Time.Now()
+
 			res, err := s.runOnce(ctx)
 			if err != nil {
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
 				if !errors.Is(err, context.Canceled) {
 					s.Logger.ErrorContext(ctx, s.Name+": Error cleaning queues", slog.String("error", err.Error()))
 				}
 				continue
 			}
 
+			s.SetLastRunAt(s.Time.Now())
+			s.RecordRun(ctx, &riversharedmaintenance.Run{FinishedAt: s.Time.Now(), NumRows: len(res.QueuesDeleted), StartedAt: startedAt})
+
 			if len(res.QueuesDeleted) > 0 {
 				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
 					slog.String("queues_deleted", strings.Join(res.QueuesDeleted, ",")),
@@ -175,7 +189,7 @@ func (s *QueueCleaner) runOnce(ctx context.Context) (*queueCleanerRunOnceResult,
 			return queuesDeleted, nil
 		}()
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
+			if riversharedmaintenance.IsBatchTimeout(err) {
 				s.reducedBatchSizeBreaker.Trip()
 			}
 
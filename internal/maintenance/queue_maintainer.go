@@ -2,9 +2,12 @@ package maintenance
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/util/maputil"
 )
@@ -18,6 +21,7 @@ type QueueMaintainer struct {
 	baseservice.BaseService
 	startstop.BaseStartStop
 
+	services       []startstop.Service
 	servicesByName map[string]startstop.Service
 }
 
@@ -27,10 +31,56 @@ func NewQueueMaintainer(archetype *baseservice.Archetype, services []startstop.S
 		servicesByName[serviceName(service)] = service
 	}
 	return baseservice.Init(archetype, &QueueMaintainer{
+		services:       services,
 		servicesByName: servicesByName,
 	})
 }
 
+// LastRunTimes returns the time at which each service last completed a run,
+// keyed by the service's type name. Services that haven't completed a run
+// since they were initialized are omitted.
+func (m *QueueMaintainer) LastRunTimes() map[string]time.Time {
+	lastRunTimes := make(map[string]time.Time, len(m.servicesByName))
+	for _, service := range m.services {
+		if svcWithLastRunAt, ok := service.(withLastRunAt); ok {
+			if lastRunAt := svcWithLastRunAt.LastRunAt(); lastRunAt != nil {
+				lastRunTimes[reflect.TypeOf(service).Elem().Name()] = *lastRunAt
+			}
+		}
+	}
+	return lastRunTimes
+}
+
+// RunOnce synchronously runs a single pass of each maintenance service that
+// supports it, in the order the services were given to NewQueueMaintainer. It
+// doesn't require that the maintainer be started, and is used in tests to
+// bring time-based maintenance up to date after a stubbed clock has been
+// advanced instead of waiting for services' run loops to come around.
+func (m *QueueMaintainer) RunOnce(ctx context.Context) error {
+	for _, service := range m.services {
+		if svcWithRunOnce, ok := service.(withRunOnce); ok {
+			if err := svcWithRunOnce.RunOnce(ctx); err != nil {
+				return fmt.Errorf("error running %s: %w", serviceName(service), err)
+			}
+		}
+	}
+	return nil
+}
+
+// SetRunRecorder installs a recorder that's invoked with each run of services
+// that record their runs, along with the service's type name. It must be
+// called before the maintainer is started.
+func (m *QueueMaintainer) SetRunRecorder(recorder func(ctx context.Context, serviceName string, run *riversharedmaintenance.Run)) {
+	for _, service := range m.services {
+		if svcWithRunRecorder, ok := service.(withRunRecorder); ok {
+			name := reflect.TypeOf(service).Elem().Name()
+			svcWithRunRecorder.SetRunRecorder(func(ctx context.Context, run *riversharedmaintenance.Run) {
+				recorder(ctx, name, run)
+			})
+		}
+	}
+}
+
 // StaggerStartupDisable sets whether the short staggered sleep on start up
 // is disabled. This is useful in tests where the extra sleep involved in a
 // staggered start up is not helpful for test run time.
@@ -84,6 +134,21 @@ func serviceName(service startstop.Service) string {
 	return elem.PkgPath() + "." + elem.Name()
 }
 
+// withLastRunAt is an interface to a service that tracks when it last
+// completed a run.
+type withLastRunAt interface {
+	// LastRunAt returns the time at which the service last completed a run, or
+	// nil if it hasn't completed one.
+	LastRunAt() *time.Time
+}
+
+// withRunRecorder is an interface to a service that records its runs.
+type withRunRecorder interface {
+	// SetRunRecorder installs a recorder that's invoked with each of the
+	// service's runs.
+	SetRunRecorder(recorder riversharedmaintenance.RunRecorder)
+}
+
 // withStaggerStartupDisable is an interface to a service whose stagger startup
 // sleep can be disabled.
 type withStaggerStartupDisable interface {
@@ -92,3 +157,10 @@ type withStaggerStartupDisable interface {
 	// staggered start up is not helpful for test run time.
 	StaggerStartupDisable(disabled bool)
 }
+
+// withRunOnce is an interface to a service that can run a single pass of its
+// work synchronously, outside of its normal run loop.
+type withRunOnce interface {
+	// RunOnce synchronously runs a single pass of the service's work.
+	RunOnce(ctx context.Context) error
+}
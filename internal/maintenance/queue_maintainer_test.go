@@ -2,6 +2,7 @@ package maintenance
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -195,4 +196,76 @@ func TestQueueMaintainer(t *testing.T) {
 		maintainer.Stop()
 		testSvc.testSignals.returning.WaitOrTimeout()
 	})
+	t.Run("LastRunTimes", func(t *testing.T) {
+		t.Parallel()
+
+		testSvc := newTestService(t)
+		maintainer := setup(t, []startstop.Service{testSvc})
+
+		require.Empty(t, maintainer.LastRunTimes())
+
+		lastRunAt := time.Now().UTC()
+		testSvc.SetLastRunAt(lastRunAt)
+
+		require.Equal(t, map[string]time.Time{"testService": lastRunAt}, maintainer.LastRunTimes())
+	})
+
+	t.Run("SetRunRecorder", func(t *testing.T) {
+		t.Parallel()
+
+		testSvc := newTestService(t)
+		maintainer := setup(t, []startstop.Service{testSvc})
+
+		type recordedRun struct {
+			name string
+			run  *riversharedmaintenance.Run
+		}
+		var recorded []recordedRun
+		maintainer.SetRunRecorder(func(ctx context.Context, serviceName string, run *riversharedmaintenance.Run) {
+			recorded = append(recorded, recordedRun{name: serviceName, run: run})
+		})
+
+		run := &riversharedmaintenance.Run{FinishedAt: time.Now().UTC(), NumRows: 5, StartedAt: time.Now().UTC()}
+		testSvc.RecordRun(ctx, run)
+		require.Equal(t, []recordedRun{{name: "testService", run: run}}, recorded)
+
+		// Runs interrupted by the service stopping aren't recorded.
+		testSvc.RecordRun(ctx, &riversharedmaintenance.Run{Err: context.Canceled})
+		require.Len(t, recorded, 1)
+	})
+
+	t.Run("RunOnce", func(t *testing.T) {
+		t.Parallel()
+
+		var ran []string
+		var (
+			testSvc1 = &testServiceWithRunOnce{testService: newTestService(t), runOnceFunc: func(ctx context.Context) error { ran = append(ran, "svc1"); return nil }}
+			testSvc2 = newTestService(t) // doesn't implement RunOnce
+			testSvc3 = &testServiceWithRunOnce{testService: newTestService(t), runOnceFunc: func(ctx context.Context) error { ran = append(ran, "svc3"); return nil }}
+		)
+
+		maintainer := setup(t, []startstop.Service{testSvc1, testSvc2, testSvc3})
+
+		// Runs without the maintainer having been started.
+		require.NoError(t, maintainer.RunOnce(ctx))
+		require.Equal(t, []string{"svc1", "svc3"}, ran)
+	})
+
+	t.Run("RunOnceError", func(t *testing.T) {
+		t.Parallel()
+
+		testSvc := &testServiceWithRunOnce{testService: newTestService(t), runOnceFunc: func(ctx context.Context) error { return errors.New("run once error") }}
+
+		maintainer := setup(t, []startstop.Service{testSvc})
+
+		require.EqualError(t, maintainer.RunOnce(ctx), "error running github.com/riverqueue/river/internal/maintenance.testServiceWithRunOnce: run once error")
+	})
 }
+
+type testServiceWithRunOnce struct {
+	*testService
+
+	runOnceFunc func(ctx context.Context) error
+}
+
+func (s *testServiceWithRunOnce) RunOnce(ctx context.Context) error { return s.runOnceFunc(ctx) }
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -138,8 +139,12 @@ func (s *Reindexer) Start(ctx context.Context) error {
 		for {
 			select {
 			case <-timerUntilNextRun.C:
+				startedAt := s.Time.Now()
+
 				reindexableIndexNames, err := s.reindexableIndexNames(ctx)
 				if err != nil {
+					s.RecordRun(ctx, &riversharedmaintenance.Run{Err: err, FinishedAt: s.Time.Now(), StartedAt: startedAt})
+
 					if !errors.Is(err, context.Canceled) {
 						s.Logger.ErrorContext(ctx, s.Name+": Error listing reindexable indexes", slog.String("error", err.Error()))
 					}
@@ -147,15 +152,28 @@ func (s *Reindexer) Start(ctx context.Context) error {
 					continue
 				}
 
+				var (
+					numReindexed int
+					reindexErr   error
+				)
 				for _, indexName := range reindexableIndexNames {
-					if _, err := s.reindexOne(ctx, indexName); err != nil {
+					reindexed, err := s.reindexOne(ctx, indexName)
+					if err != nil {
 						if !errors.Is(err, context.Canceled) {
 							s.Logger.ErrorContext(ctx, s.Name+": Error reindexing", slog.String("error", err.Error()), slog.String("index_name", indexName))
 						}
+						if reindexErr == nil {
+							reindexErr = fmt.Errorf("error reindexing %s: %w", indexName, err)
+						}
 						continue
 					}
+					if reindexed {
+						numReindexed++
+					}
 				}
 
+				s.SetLastRunAt(s.Time.Now())
+				s.RecordRun(ctx, &riversharedmaintenance.Run{Err: reindexErr, FinishedAt: s.Time.Now(), NumRows: numReindexed, StartedAt: startedAt})
 				s.TestSignals.Reindexed.Signal(struct{}{})
 
 				// On each run, we calculate the new schedule based on the
@@ -117,6 +117,8 @@ func (s *SQLiteNotificationCleaner) Start(ctx context.Context) error { //nolint:
 				continue
 			}
 
+			s.SetLastRunAt(s.Time.Now())
+
 			if res.NumNotificationsDeleted > 0 {
 				s.Logger.InfoContext(ctx, s.Name+riversharedmaintenance.LogPrefixRanSuccessfully,
 					slog.Int("num_notifications_deleted", res.NumNotificationsDeleted),
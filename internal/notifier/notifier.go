@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/testsignal"
@@ -24,9 +25,9 @@ import (
 type NotificationTopic string
 
 const (
-	NotificationTopicControl    NotificationTopic = "river_control"
-	NotificationTopicInsert     NotificationTopic = "river_insert"
-	NotificationTopicLeadership NotificationTopic = "river_leadership"
+	NotificationTopicControl    = NotificationTopic(rivernotify.TopicControl)
+	NotificationTopicInsert     = NotificationTopic(rivernotify.TopicInsert)
+	NotificationTopicLeadership = NotificationTopic(rivernotify.TopicLeadership)
 )
 
 var notificationTopicAll = []NotificationTopic{ //nolint:gochecknoglobals
@@ -485,6 +486,15 @@ func (n *Notifier) sendInterruptAndReceiveResult(operation func() error) error {
 	}
 }
 
+// IsConnected returns whether the notifier currently has an established
+// listener connection.
+func (n *Notifier) IsConnected() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.isConnected
+}
+
 func (n *Notifier) Listen(ctx context.Context, topic NotificationTopic, notifyFunc NotifyFunc) (*Subscription, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -0,0 +1,38 @@
+// Package poisonpill detects poison pill jobs, which crash the process working
+// them every time they're worked, by counting the consecutive attempts of a job
+// that ended in a crash.
+package poisonpill
+
+import "github.com/riverqueue/river/rivertype"
+
+const (
+	// ErrorLeaseExpired is the error recorded on a job whose lease expired
+	// while it was running, like because the process working it died.
+	ErrorLeaseExpired = "Job lease expired"
+
+	// ErrorRescued is the error recorded on a job rescued by the job rescuer
+	// after being stuck running, like because the process working it died.
+	ErrorRescued = "Stuck job rescued by JobRescuer"
+)
+
+// ConsecutiveCrashes returns the number of the job's most recent attempts that
+// ended in a crash, stopping at the first that didn't.
+func ConsecutiveCrashes(errors []rivertype.AttemptError) int {
+	var numCrashes int
+	for i := len(errors) - 1; i >= 0; i-- {
+		if !IsCrash(&errors[i]) {
+			break
+		}
+		numCrashes++
+	}
+	return numCrashes
+}
+
+// IsCrash returns true if the attempt error was recorded for an attempt that
+// crashed, either by panicking (in which case a trace was recorded) or by the
+// process working it dying before it could be completed.
+func IsCrash(attemptErr *rivertype.AttemptError) bool {
+	return attemptErr.Trace != "" ||
+		attemptErr.Error == ErrorLeaseExpired ||
+		attemptErr.Error == ErrorRescued
+}
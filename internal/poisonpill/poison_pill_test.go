@@ -0,0 +1,36 @@
+package poisonpill
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestConsecutiveCrashes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errOrdinary     = rivertype.AttemptError{Error: "ordinary error"}
+		errPanic        = rivertype.AttemptError{Error: "panic val", Trace: "trace"}
+		errLeaseExpired = rivertype.AttemptError{Error: ErrorLeaseExpired}
+		errRescued      = rivertype.AttemptError{Error: ErrorRescued}
+	)
+
+	require.Zero(t, ConsecutiveCrashes(nil))
+	require.Zero(t, ConsecutiveCrashes([]rivertype.AttemptError{errOrdinary}))
+	require.Zero(t, ConsecutiveCrashes([]rivertype.AttemptError{errPanic, errOrdinary}))
+	require.Equal(t, 1, ConsecutiveCrashes([]rivertype.AttemptError{errOrdinary, errPanic}))
+	require.Equal(t, 3, ConsecutiveCrashes([]rivertype.AttemptError{errPanic, errLeaseExpired, errRescued}))
+	require.Equal(t, 2, ConsecutiveCrashes([]rivertype.AttemptError{errPanic, errOrdinary, errRescued, errPanic}))
+}
+
+func TestIsCrash(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, IsCrash(&rivertype.AttemptError{Error: "ordinary error"}))
+	require.True(t, IsCrash(&rivertype.AttemptError{Error: "panic val", Trace: "trace"}))
+	require.True(t, IsCrash(&rivertype.AttemptError{Error: ErrorLeaseExpired}))
+	require.True(t, IsCrash(&rivertype.AttemptError{Error: ErrorRescued}))
+}
@@ -59,6 +59,11 @@ type ContextKeyClient struct{}
 // cases like avoiding logging an error during a normal shutdown procedure.
 var ErrStop = errors.New("stop initiated")
 
+// ErrResourceBudgetExceeded is a special error injected by the client into the
+// CancelCauseFunc of a job that it's interrupting because it's exceeded its
+// resource budget. Jobs interrupted with it are released rather than errored.
+var ErrResourceBudgetExceeded = errors.New("resource budget exceeded")
+
 // UserSpecifiedIDOrKindRE is a regular expression to which the format of job
 // kinds and some other user-specified IDs (e.g. periodic job names) must
 // comply. Mainly, minimal special characters, and excluding spaces and commas
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/rivertype"
 )
@@ -24,7 +25,16 @@ type WorkUnit interface {
 	Middleware() []rivertype.WorkerMiddleware
 	NextRetry() time.Time
 	Timeout() time.Duration
-	UnmarshalJob() error
+
+	// TimeoutGracePeriod is how long the job is given to return after its
+	// context is cancelled by its timeout before it's failed with a hard
+	// timeout error. Zero means it's given as long as it needs.
+	TimeoutGracePeriod() time.Duration
+
+	// UnmarshalJob decodes the wrapped job's args using the given decoder,
+	// which may be nil to only decode JSON.
+	UnmarshalJob(argsDecoder *argscodec.Decoder) error
+
 	Work(ctx context.Context) error
 }
 
@@ -1,6 +1,8 @@
 package river
 
 import (
+	"time"
+
 	"github.com/riverqueue/river/rivertype"
 )
 
@@ -85,10 +87,126 @@ type JobArgsWithHooks interface {
 	Hooks() []rivertype.Hook
 }
 
+// JobArgsWithSchema is an extra interface that job args may implement to
+// describe their args when they're published to the job kind registry by a
+// client with Config.PublishJobKinds enabled. Producers and operators can then
+// look up which versions of a job's args are accepted by deployed clients with
+// Client.JobKindRegistry.
+//
+// Job args that don't implement it are published with an empty schema and
+// version.
+type JobArgsWithSchema interface {
+	// ArgsSchema returns a schema describing this job's args. Like hooks, it's
+	// extracted from a generic instance of the job, so should be based on the
+	// job type only.
+	ArgsSchema() JobArgsSchema
+}
+
+// JobArgsSchema is a schema describing a job's args, published to the job kind
+// registry for job args implementing JobArgsWithSchema.
+type JobArgsSchema struct {
+	// Schema is a JSON document describing the job's args, like a JSON Schema.
+	// River doesn't interpret it, but it must be valid JSON. Defaults to an
+	// empty object if not set.
+	Schema []byte
+
+	// Version is an optional version for the job's args. A kind is registered
+	// once per version, so clients on an older deploy that publish an older
+	// version don't overwrite the registration of a newer one.
+	Version string
+}
+
 // JobArgsWithInsertOpts is an extra interface that a job may implement on top
 // of JobArgs to provide insertion-time options for all jobs of this type.
+//
+// For the common case of a static queue, priority, or max attempts, a `river`
+// struct tag on a blank field can be used instead of implementing this
+// interface:
+//
+//	type EmailArgs struct {
+//		_ struct{} `river:"queue=email,priority=2,max_attempts=5"`
+//
+//		To string `json:"to"`
+//	}
+//
+// Options returned by InsertOpts take precedence over those from a struct tag.
 type JobArgsWithInsertOpts interface {
 	// InsertOpts returns options for all jobs of this job type, overriding any
 	// system defaults. These can also be overridden at insertion time.
 	InsertOpts() InsertOpts
 }
+
+// JobArgsWithTimeout is an extra interface that a job may implement on top of
+// JobArgs to derive the job's timeout from its args. It's useful where the
+// time a job needs depends on its payload, like the number of records it has
+// to process:
+//
+//	func (a BatchImportArgs) Timeout() time.Duration {
+//		return time.Minute + time.Duration(len(a.RecordIDs))*100*time.Millisecond
+//	}
+//
+// A non-zero timeout returned by the worker's Timeout method takes precedence
+// over one from args, so workers embedding WorkerDefaults will use the args
+// timeout. If both return zero, the client-level JobTimeout is used.
+type JobArgsWithTimeout interface {
+	// Timeout is the maximum amount of time the job is allowed to run before
+	// its context is cancelled. Zero defers to the client-level timeout, and
+	// -1 means the job's context will never time out.
+	Timeout() time.Duration
+}
+
+// JobArgsWithVersion is an extra interface that job args may implement to
+// version the shape of their args. It's useful for jobs that may be worked
+// long after they're inserted, like those scheduled far in the future, which
+// might otherwise outlive the args struct they were inserted with.
+//
+// The current version is recorded in a job's metadata when it's inserted.
+// Before a job is worked, if it was inserted with an older version (jobs
+// inserted before args implemented this interface are version 0), its encoded
+// args are passed through UpgradeArgs to convert them to the current shape
+// before they're unmarshaled:
+//
+//	func (EmailArgs) ArgsVersion() int { return 2 }
+//
+//	func (EmailArgs) UpgradeArgs(version int, encodedArgs []byte) ([]byte, error) {
+//		if version < 2 {
+//			// v1 had a single `to` string, but v2 takes a list.
+//			to := gjson.GetBytes(encodedArgs, "to")
+//			return sjson.SetBytes(encodedArgs, "to", []string{to.String()})
+//		}
+//		return encodedArgs, nil
+//	}
+//
+// A job inserted with a version newer than that known to the worker, like
+// during a deploy where producers are upgraded before workers, errors and is
+// retried according to its retry policy.
+type JobArgsWithVersion interface {
+	// ArgsVersion returns the current version of the args. Versions should
+	// start at 1 and increase each time the args' shape changes.
+	ArgsVersion() int
+
+	// UpgradeArgs converts encoded args from the given older version to the
+	// current one. It's invoked with args in the format they were encoded
+	// with, which is JSON unless Config.ArgsCodec is customized, and should
+	// return args in the same format. Like hooks, it's invoked on a generic
+	// instance of the job args, so should be based on the job type only.
+	UpgradeArgs(version int, encodedArgs []byte) ([]byte, error)
+}
+
+// JobArgsWithValidate is an extra interface that job args may implement to
+// validate themselves when they're inserted. Insert and InsertMany invoke
+// Validate on every job's args before anything is persisted, failing fast
+// with a *JobArgsValidationError wrapping any error returned, so malformed
+// args are rejected by their producer instead of erroring only once they're
+// worked:
+//
+//	func (a EmailArgs) Validate() error {
+//		if a.To == "" {
+//			return errors.New("to is required")
+//		}
+//		return nil
+//	}
+type JobArgsWithValidate interface {
+	// Validate returns an error if the args are invalid.
+	Validate() error
+}
@@ -77,7 +77,7 @@ func JobCompleteTx[TDriver riverdriver.Driver[TTx], TTx any, TArgs JobArgs](ctx
 	}
 	updatedJob := &Job[TArgs]{JobRow: rows[0]}
 
-	if err := json.Unmarshal(updatedJob.EncodedArgs, &updatedJob.Args); err != nil {
+	if err := client.argsDecoder.Decode(updatedJob.JobRow, &updatedJob.Args); err != nil {
 		return nil, err
 	}
 
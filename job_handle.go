@@ -0,0 +1,144 @@
+package river
+
+import (
+	"context"
+	"time"
+
+	"github.com/riverqueue/river/internal/argscodec"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const jobHandleWaitPollInterval = 200 * time.Millisecond
+
+// jobHandleClient is the subset of Client used by JobHandle, so that a handle
+// doesn't need to carry the client's transaction type.
+type jobHandleClient interface {
+	JobCancel(ctx context.Context, jobID int64) (*rivertype.JobRow, error)
+	JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error)
+}
+
+// JobHandle is a handle to an inserted job returned by InsertHandle and
+// InsertHandleTx. It bundles the job's ID with the client and schema it was
+// inserted through so that the job can be interacted with without threading
+// the client around separately, and decodes the job's args to T.
+//
+// A handle's methods operate outside of any transaction, so when the job was
+// inserted with InsertHandleTx, they won't find it until the transaction has
+// committed.
+type JobHandle[T JobArgs] struct {
+	// ID is the ID of the job.
+	ID int64
+
+	// Schema is the schema the job was inserted into.
+	Schema string
+
+	// UniqueSkippedAsDuplicate is true if the insert was skipped because a
+	// unique job already existed, in which case the handle refers to the
+	// existing job.
+	UniqueSkippedAsDuplicate bool
+
+	argsDecoder *argscodec.Decoder
+	client      jobHandleClient
+}
+
+// InsertHandle inserts a new job with the provided args like Client.Insert,
+// but returns a handle to the inserted job that's typed to its args.
+//
+//	handle, err := river.InsertHandle(ctx, client, MyArgs{}, nil)
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	job, err := handle.Wait(ctx)
+func InsertHandle[T JobArgs, TTx any](ctx context.Context, client *Client[TTx], args T, opts *InsertOpts) (*JobHandle[T], error) {
+	res, err := client.Insert(ctx, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newJobHandle[T](client, res), nil
+}
+
+// InsertHandleTx inserts a new job with the provided args on the given
+// transaction like Client.InsertTx, but returns a handle to the inserted job
+// that's typed to its args. The handle's methods operate outside of the
+// transaction, so they won't find the job until the transaction has committed.
+func InsertHandleTx[T JobArgs, TTx any](ctx context.Context, client *Client[TTx], tx TTx, args T, opts *InsertOpts) (*JobHandle[T], error) {
+	res, err := client.InsertTx(ctx, tx, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newJobHandle[T](client, res), nil
+}
+
+func newJobHandle[T JobArgs, TTx any](client *Client[TTx], res *rivertype.JobInsertResult) *JobHandle[T] {
+	return &JobHandle[T]{
+		ID:                       res.Job.ID,
+		Schema:                   client.config.Schema,
+		UniqueSkippedAsDuplicate: res.UniqueSkippedAsDuplicate,
+		argsDecoder:              client.argsDecoder,
+		client:                   client,
+	}
+}
+
+// Cancel cancels the job. See Client.JobCancel for details on how cancellation
+// behaves depending on the job's state.
+func (h *JobHandle[T]) Cancel(ctx context.Context) (*Job[T], error) {
+	jobRow, err := h.client.JobCancel(ctx, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	return h.toJob(jobRow)
+}
+
+// Get fetches the job's current state. Returns rivertype.ErrNotFound if the
+// job no longer exists.
+func (h *JobHandle[T]) Get(ctx context.Context) (*Job[T], error) {
+	jobRow, err := h.client.JobGet(ctx, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	return h.toJob(jobRow)
+}
+
+// Metadata fetches the job's current metadata, which may have changed since it
+// was inserted as it's worked.
+func (h *JobHandle[T]) Metadata(ctx context.Context) ([]byte, error) {
+	jobRow, err := h.client.JobGet(ctx, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	return jobRow.Metadata, nil
+}
+
+// Wait blocks until the job is finalized, meaning that it was completed,
+// cancelled, or discarded, then returns it. The job is polled for, so it may
+// be worked by any client. Returns the context's error if it's cancelled
+// first, or rivertype.ErrNotFound if the job is deleted before it's finalized.
+func (h *JobHandle[T]) Wait(ctx context.Context) (*Job[T], error) {
+	ticker := time.NewTicker(jobHandleWaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		jobRow, err := h.client.JobGet(ctx, h.ID)
+		if err != nil {
+			return nil, err
+		}
+		if jobRow.FinalizedAt != nil {
+			return h.toJob(jobRow)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *JobHandle[T]) toJob(jobRow *rivertype.JobRow) (*Job[T], error) {
+	job := &Job[T]{JobRow: jobRow}
+	if err := h.argsDecoder.Decode(jobRow, &job.Args); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
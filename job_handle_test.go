@@ -0,0 +1,167 @@
+package river
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_JobHandle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+
+		Name string `json:"name"`
+	}
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		return newTestClient(t, dbPool, config), &testBundle{dbPool: dbPool, schema: schema}
+	}
+
+	t.Run("InsertAndGet", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		handle, err := InsertHandle(ctx, client, JobArgs{Name: "name"}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)})
+		require.NoError(t, err)
+		require.NotZero(t, handle.ID)
+		require.Equal(t, bundle.schema, handle.Schema)
+		require.False(t, handle.UniqueSkippedAsDuplicate)
+
+		job, err := handle.Get(ctx)
+		require.NoError(t, err)
+		require.Equal(t, handle.ID, job.ID)
+		require.Equal(t, "name", job.Args.Name)
+
+		metadata, err := handle.Metadata(ctx)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar"}`, string(metadata))
+	})
+
+	t.Run("InsertTx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+
+		handle, err := InsertHandleTx(ctx, client, tx, JobArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+
+		// Not visible outside the transaction until it's committed.
+		_, err = handle.Get(ctx)
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		require.NoError(t, tx.Commit(ctx))
+
+		job, err := handle.Get(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "name", job.Args.Name)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		handle, err := InsertHandle(ctx, client, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		job, err := handle.Cancel(ctx)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+	})
+
+	t.Run("UniqueSkippedAsDuplicate", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		handle1, err := InsertHandle(ctx, client, JobArgs{Name: "name"}, insertOpts)
+		require.NoError(t, err)
+
+		handle2, err := InsertHandle(ctx, client, JobArgs{Name: "name"}, insertOpts)
+		require.NoError(t, err)
+		require.True(t, handle2.UniqueSkippedAsDuplicate)
+		require.Equal(t, handle1.ID, handle2.ID)
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		handle, err := InsertHandle(ctx, client, JobArgs{Name: "name"}, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, client.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, client.Stop(ctx)) })
+
+		job, err := handle.Wait(ctx)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+		require.Equal(t, "name", job.Args.Name)
+	})
+
+	t.Run("WaitContextCancelled", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		handle, err := InsertHandle(ctx, client, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 2*jobHandleWaitPollInterval)
+		defer cancel()
+
+		_, err = handle.Wait(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("WaitNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		handle, err := InsertHandle(ctx, client, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		_, err = client.JobDelete(ctx, handle.ID)
+		require.NoError(t, err)
+
+		_, err = handle.Wait(ctx)
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+	})
+}
@@ -0,0 +1,127 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
+)
+
+// JobKindRegistration is a job kind published to the job kind registry by a
+// client with Config.PublishJobKinds enabled. A kind has one registration for
+// each version of its args that's been published.
+type JobKindRegistration struct {
+	// ArgsSchema is the schema describing the job's args, as returned by
+	// JobArgsWithSchema. It's an empty JSON object for job args that don't
+	// implement JobArgsWithSchema.
+	ArgsSchema []byte
+
+	// ClientID is the ID of the client that most recently published the
+	// registration.
+	ClientID string
+
+	// CreatedAt is when the kind and version were first published.
+	CreatedAt time.Time
+
+	// Kind is the job kind.
+	Kind string
+
+	// UpdatedAt is when the kind and version were most recently published.
+	UpdatedAt time.Time
+
+	// Version is the version of the job's args, as returned by
+	// JobArgsWithSchema. It's empty for job args that don't implement
+	// JobArgsWithSchema.
+	Version string
+}
+
+// JobKindRegistry returns the job kinds published to the job kind registry by
+// clients with Config.PublishJobKinds enabled, ordered by kind and version. If
+// any kinds are given, only registrations for those kinds are returned.
+//
+//	registrations, err := client.JobKindRegistry(ctx, "email_send")
+//	if err != nil {
+//		// handle error
+//	}
+//	if len(registrations) < 1 {
+//		// no deployed client works email_send jobs
+//	}
+func (c *Client[TTx]) JobKindRegistry(ctx context.Context, kinds ...string) ([]*JobKindRegistration, error) {
+	return c.jobKindRegistry(ctx, c.driver.GetExecutor(), kinds)
+}
+
+// JobKindRegistryTx returns the job kinds published to the job kind registry by
+// clients with Config.PublishJobKinds enabled, ordered by kind and version. If
+// any kinds are given, only registrations for those kinds are returned.
+//
+//	registrations, err := client.JobKindRegistryTx(ctx, tx, "email_send")
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobKindRegistryTx(ctx context.Context, tx TTx, kinds ...string) ([]*JobKindRegistration, error) {
+	return c.jobKindRegistry(ctx, c.driver.UnwrapExecutor(tx), kinds)
+}
+
+func (c *Client[TTx]) jobKindRegistry(ctx context.Context, exec riverdriver.Executor, kinds []string) ([]*JobKindRegistration, error) {
+	registrations, err := exec.JobKindRegistrationList(ctx, &riverdriver.JobKindRegistrationListParams{
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kinds) > 0 {
+		registrations = slices.DeleteFunc(registrations, func(registration *riverdriver.JobKindRegistration) bool {
+			return !slices.Contains(kinds, registration.Kind)
+		})
+	}
+
+	return sliceutil.Map(registrations, func(registration *riverdriver.JobKindRegistration) *JobKindRegistration {
+		return &JobKindRegistration{
+			ArgsSchema: registration.ArgsSchema,
+			ClientID:   registration.ClientID,
+			CreatedAt:  registration.CreatedAt,
+			Kind:       registration.Kind,
+			UpdatedAt:  registration.UpdatedAt,
+			Version:    registration.Version,
+		}
+	}), nil
+}
+
+// Publishes the primary kinds of the client's registered workers to the job
+// kind registry, along with the schema of any job args implementing
+// JobArgsWithSchema.
+func (c *Client[TTx]) jobKindRegistryPublish(ctx context.Context) error {
+	kinds := c.config.Workers.primaryKinds()
+	if len(kinds) < 1 {
+		return nil
+	}
+
+	registrations := make([]*riverdriver.JobKindRegistrationUpsertParams, len(kinds))
+	for i, kind := range kinds {
+		registration := &riverdriver.JobKindRegistrationUpsertParams{Kind: kind}
+
+		if argsWithSchema, ok := c.config.Workers.workersMap[kind].jobArgs.(JobArgsWithSchema); ok {
+			schema := argsWithSchema.ArgsSchema()
+			if len(schema.Schema) > 0 && !json.Valid(schema.Schema) {
+				return fmt.Errorf("args schema for job kind %q is not valid JSON", kind)
+			}
+
+			registration.ArgsSchema = schema.Schema
+			registration.Version = schema.Version
+		}
+
+		registrations[i] = registration
+	}
+
+	return c.driver.GetExecutor().JobKindRegistrationUpsertMany(ctx, &riverdriver.JobKindRegistrationUpsertManyParams{
+		ClientID:      c.config.ID,
+		Now:           c.baseService.Time.NowOrNil(),
+		Registrations: registrations,
+		Schema:        c.config.Schema,
+	})
+}
@@ -0,0 +1,134 @@
+package river
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+type jobKindRegistryArgs struct{}
+
+func (jobKindRegistryArgs) Kind() string { return "job_kind_registry" }
+
+func (jobKindRegistryArgs) ArgsSchema() JobArgsSchema {
+	return JobArgsSchema{Schema: []byte(`{"type": "object"}`), Version: "v2"}
+}
+
+type jobKindRegistryInvalidArgs struct{}
+
+func (jobKindRegistryInvalidArgs) Kind() string { return "job_kind_registry_invalid" }
+
+func (jobKindRegistryInvalidArgs) ArgsSchema() JobArgsSchema {
+	return JobArgsSchema{Schema: []byte(`{"type":`)}
+}
+
+func TestClientJobKindRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[jobKindRegistryArgs]) error { return nil }))
+		config.PublishJobKinds = true
+
+		return newTestClient(t, dbPool, config), &testBundle{
+			dbPool: dbPool,
+		}
+	}
+
+	t.Run("PublishesOnStart", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		registrations, err := client.JobKindRegistry(ctx)
+		require.NoError(t, err)
+		require.Empty(t, registrations)
+
+		startClient(ctx, t, client)
+
+		registrations, err = client.JobKindRegistry(ctx)
+		require.NoError(t, err)
+		require.Len(t, registrations, 2)
+
+		require.JSONEq(t, `{"type": "object"}`, string(registrations[0].ArgsSchema))
+		require.Equal(t, client.ID(), registrations[0].ClientID)
+		require.Equal(t, (jobKindRegistryArgs{}).Kind(), registrations[0].Kind)
+		require.Equal(t, "v2", registrations[0].Version)
+
+		require.JSONEq(t, `{}`, string(registrations[1].ArgsSchema))
+		require.Equal(t, (noOpArgs{}).Kind(), registrations[1].Kind)
+		require.Empty(t, registrations[1].Version)
+	})
+
+	t.Run("FiltersByKind", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		startClient(ctx, t, client)
+
+		registrations, err := client.JobKindRegistry(ctx, (noOpArgs{}).Kind(), "does_not_exist")
+		require.NoError(t, err)
+		require.Len(t, registrations, 1)
+		require.Equal(t, (noOpArgs{}).Kind(), registrations[0].Kind)
+	})
+
+	t.Run("NotPublishedWhenDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		client.config.PublishJobKinds = false
+
+		startClient(ctx, t, client)
+
+		registrations, err := client.JobKindRegistry(ctx)
+		require.NoError(t, err)
+		require.Empty(t, registrations)
+	})
+
+	t.Run("InvalidSchemaErrorsOnStart", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[jobKindRegistryInvalidArgs]) error { return nil }))
+
+		require.EqualError(t, client.Start(ctx), `error publishing job kinds: args schema for job kind "job_kind_registry_invalid" is not valid JSON`)
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		startClient(ctx, t, client)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+		registrations, err := client.JobKindRegistryTx(ctx, tx, (jobKindRegistryArgs{}).Kind())
+		require.NoError(t, err)
+		require.Len(t, registrations, 1)
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/riverqueue/river/internal/dblist"
@@ -15,13 +17,28 @@ import (
 
 // JobListCursor is used to specify a starting point for a paginated
 // job list query.
+//
+// A cursor encodes the sort of the list it came from, and JobListParams.After
+// continues with that sort, so a cursor serialized into a URL for a page in a
+// UI resumes the same listing without also needing to carry the sort. Lists are
+// paginated by keyset, so jobs inserted or removed between fetching pages don't
+// cause rows to be skipped or duplicated.
 type JobListCursor struct {
 	id        int64
 	job       *rivertype.JobRow // used for JobListCursorFromJob path; not serialized
 	kind      string
+	priority  int
 	queue     string
 	sortField JobListOrderByField
-	time      time.Time // may be empty
+	sortOrder *SortOrder // nil for cursors serialized before sort order was included
+	state     rivertype.JobState
+
+	// thenSorts are secondary sorts added with JobListParams.ThenOrderBy, and
+	// thenValues the job's value for each.
+	thenSorts  []jobListSort
+	thenValues []any
+
+	time time.Time // may be empty
 }
 
 // JobListCursorFromJob creates a JobListCursor from a JobRow.
@@ -37,8 +54,10 @@ func jobListCursorFromJobAndParams(job *rivertype.JobRow, listParams *JobListPar
 
 	// Don't include a `default` so `exhaustive` lint can detect omissions.
 	switch listParams.sortField {
-	case JobListOrderByID:
+	case JobListOrderByID, JobListOrderByKind, JobListOrderByPriority, JobListOrderByQueue, JobListOrderByState:
 		cursorTime = ptrutil.Ptr(time.Time{})
+	case JobListOrderByCreatedAt:
+		cursorTime = &job.CreatedAt
 	case JobListOrderByTime:
 		cursorTime = ptrutil.Ptr(jobListTimeValue(job))
 	case JobListOrderByFinalizedAt:
@@ -53,22 +72,39 @@ func jobListCursorFromJobAndParams(job *rivertype.JobRow, listParams *JobListPar
 		panic("invalid sort field")
 	}
 
+	var thenValues []any
+	if len(listParams.thenSorts) > 0 {
+		thenValues = make([]any, len(listParams.thenSorts))
+		for i, thenSort := range listParams.thenSorts {
+			thenValues[i] = jobListSortValue(job, thenSort.field)
+		}
+	}
+
 	return &JobListCursor{
-		id:        job.ID,
-		kind:      job.Kind,
-		queue:     job.Queue,
-		sortField: listParams.sortField,
-		time:      *cursorTime,
+		id:         job.ID,
+		kind:       job.Kind,
+		priority:   job.Priority,
+		queue:      job.Queue,
+		sortField:  listParams.sortField,
+		sortOrder:  ptrutil.Ptr(listParams.sortOrder),
+		state:      job.State,
+		thenSorts:  listParams.thenSorts,
+		thenValues: thenValues,
+		time:       *cursorTime,
 	}
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler to decode the cursor from
 // a previously marshaled string.
 func (c *JobListCursor) UnmarshalText(text []byte) error {
-	dst := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
-	n, err := base64.StdEncoding.Decode(dst, text)
+	// Cursors are marshaled with URL encoding, but standard encoding was
+	// previously expected when decoding, so continue to accept it.
+	dst := make([]byte, base64.URLEncoding.DecodedLen(len(text)))
+	n, err := base64.URLEncoding.Decode(dst, text)
 	if err != nil {
-		return err
+		if n, err = base64.StdEncoding.Decode(dst, text); err != nil {
+			return err
+		}
 	}
 	dst = dst[:n]
 
@@ -76,12 +112,57 @@ func (c *JobListCursor) UnmarshalText(text []byte) error {
 	if err := json.Unmarshal(dst, &wrapperValue); err != nil {
 		return err
 	}
+
+	var sortOrder *SortOrder
+	switch wrapperValue.SortOrder {
+	case "":
+	case sortOrderAscString:
+		sortOrder = ptrutil.Ptr(SortOrderAsc)
+	case sortOrderDescString:
+		sortOrder = ptrutil.Ptr(SortOrderDesc)
+	default:
+		return fmt.Errorf("invalid cursor sort order: %q", wrapperValue.SortOrder)
+	}
+
+	if len(wrapperValue.ThenSorts) != len(wrapperValue.ThenValues) {
+		return errors.New("invalid cursor: mismatched secondary sorts and values")
+	}
+
+	var (
+		thenSorts  []jobListSort
+		thenValues []any
+	)
+	for i, thenSortJSON := range wrapperValue.ThenSorts {
+		thenSort := jobListSort{field: JobListOrderByField(thenSortJSON.Field)}
+		switch thenSortJSON.Order {
+		case sortOrderAscString:
+			thenSort.order = SortOrderAsc
+		case sortOrderDescString:
+			thenSort.order = SortOrderDesc
+		default:
+			return fmt.Errorf("invalid cursor sort order: %q", thenSortJSON.Order)
+		}
+
+		thenValue, err := jobListSortValueUnmarshal(thenSort.field, wrapperValue.ThenValues[i])
+		if err != nil {
+			return err
+		}
+
+		thenSorts = append(thenSorts, thenSort)
+		thenValues = append(thenValues, thenValue)
+	}
+
 	*c = JobListCursor{
-		id:        wrapperValue.ID,
-		kind:      wrapperValue.Kind,
-		queue:     wrapperValue.Queue,
-		sortField: JobListOrderByField(wrapperValue.SortField),
-		time:      wrapperValue.Time,
+		id:         wrapperValue.ID,
+		kind:       wrapperValue.Kind,
+		priority:   wrapperValue.Priority,
+		queue:      wrapperValue.Queue,
+		sortField:  JobListOrderByField(wrapperValue.SortField),
+		sortOrder:  sortOrder,
+		state:      rivertype.JobState(wrapperValue.State),
+		thenSorts:  thenSorts,
+		thenValues: thenValues,
+		time:       wrapperValue.Time,
 	}
 	return nil
 }
@@ -96,10 +177,24 @@ func (c JobListCursor) MarshalText() ([]byte, error) {
 	wrapperValue := jobListPaginationCursorJSON{
 		ID:        c.id,
 		Kind:      c.kind,
+		Priority:  c.priority,
 		Queue:     c.queue,
 		SortField: string(c.sortField),
+		State:     string(c.state),
 		Time:      c.time,
 	}
+	if c.sortOrder != nil {
+		wrapperValue.SortOrder = sortOrderString(*c.sortOrder)
+	}
+	for i, thenSort := range c.thenSorts {
+		thenValue, err := json.Marshal(c.thenValues[i])
+		if err != nil {
+			return nil, err
+		}
+
+		wrapperValue.ThenSorts = append(wrapperValue.ThenSorts, jobListSortJSON{Field: string(thenSort.field), Order: sortOrderString(thenSort.order)})
+		wrapperValue.ThenValues = append(wrapperValue.ThenValues, thenValue)
+	}
 	data, err := json.Marshal(wrapperValue)
 	if err != nil {
 		return nil, err
@@ -110,11 +205,34 @@ func (c JobListCursor) MarshalText() ([]byte, error) {
 }
 
 type jobListPaginationCursorJSON struct {
-	ID        int64     `json:"id"`
-	Kind      string    `json:"kind"`
-	Queue     string    `json:"queue"`
-	SortField string    `json:"sort_field"`
-	Time      time.Time `json:"time"`
+	ID         int64             `json:"id"`
+	Kind       string            `json:"kind"`
+	Priority   int               `json:"priority,omitempty"`
+	Queue      string            `json:"queue"`
+	SortField  string            `json:"sort_field"`
+	SortOrder  string            `json:"sort_order,omitempty"`
+	State      string            `json:"state,omitempty"`
+	ThenSorts  []jobListSortJSON `json:"then_sorts,omitempty"`
+	ThenValues []json.RawMessage `json:"then_values,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+type jobListSortJSON struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+// Representations of SortOrder in serialized cursors.
+const (
+	sortOrderAscString  = "asc"
+	sortOrderDescString = "desc"
+)
+
+func sortOrderString(sortOrder SortOrder) string {
+	if sortOrder == SortOrderDesc {
+		return sortOrderDescString
+	}
+	return sortOrderAscString
 }
 
 // SortOrder specifies the direction of a sort.
@@ -132,20 +250,40 @@ const (
 type JobListOrderByField string
 
 const (
+	// JobListOrderByCreatedAt specifies that the sort should be by
+	// `created_at`.
+	JobListOrderByCreatedAt JobListOrderByField = "created_at"
+
 	// JobListOrderByID specifies that the sort should be by job ID.
 	JobListOrderByID JobListOrderByField = "id"
 
+	// JobListOrderByKind specifies that the sort should be by `kind`.
+	JobListOrderByKind JobListOrderByField = "kind"
+
+	// JobListOrderByPriority specifies that the sort should be by `priority`.
+	// Jobs of equal priority are sorted by ID.
+	JobListOrderByPriority JobListOrderByField = "priority"
+
+	// JobListOrderByQueue specifies that the sort should be by `queue`.
+	JobListOrderByQueue JobListOrderByField = "queue"
+
 	// JobListOrderByFinalizedAt specifies that the sort should be by
 	// `finalized_at`.
 	//
 	// This option must be used in conjunction with filtering by only finalized
-	// job states.
+	// job states when used with OrderBy. With ThenOrderBy, jobs that aren't
+	// finalized sort as if their `finalized_at` is null.
 	JobListOrderByFinalizedAt JobListOrderByField = "finalized_at"
 
 	// JobListOrderByScheduledAt specifies that the sort should be by
 	// `scheduled_at`.
 	JobListOrderByScheduledAt JobListOrderByField = "scheduled_at"
 
+	// JobListOrderByState specifies that the sort should be by `state`. In
+	// Postgres, states sort in the order they're defined in the `river_job_state`
+	// enum rather than alphabetically.
+	JobListOrderByState JobListOrderByField = "state"
+
 	// JobListOrderByTime specifies that the sort should be by the "best fit"
 	// time field based on listed state. The best fit is determined by looking
 	// at the first value given to JobListParams.States. If multiple states are
@@ -166,6 +304,7 @@ const (
 //	params := NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc).First(100)
 type JobListParams struct {
 	after          *JobListCursor
+	countTotalMax  int32
 	ids            []int64
 	kinds          []string
 	metadataCalled bool
@@ -177,9 +316,16 @@ type JobListParams struct {
 	sortField      JobListOrderByField
 	sortOrder      SortOrder
 	states         []rivertype.JobState
+	thenSorts      []jobListSort
 	where          []dblist.WherePredicate
 }
 
+// jobListSort is a secondary sort added with JobListParams.ThenOrderBy.
+type jobListSort struct {
+	field JobListOrderByField
+	order SortOrder
+}
+
 // NewJobListParams creates a new JobListParams to return available jobs sorted
 // by time in ascending order, returning 100 jobs at most.
 func NewJobListParams() *JobListParams {
@@ -203,6 +349,7 @@ func NewJobListParams() *JobListParams {
 func (p *JobListParams) copy() *JobListParams {
 	return &JobListParams{
 		after:          p.after,
+		countTotalMax:  p.countTotalMax,
 		ids:            append([]int64(nil), p.ids...),
 		kinds:          append([]string(nil), p.kinds...),
 		metadataCalled: p.metadataCalled,
@@ -214,6 +361,7 @@ func (p *JobListParams) copy() *JobListParams {
 		sortOrder:      p.sortOrder,
 		schema:         p.schema,
 		states:         append([]rivertype.JobState(nil), p.states...),
+		thenSorts:      append([]jobListSort(nil), p.thenSorts...),
 		where:          append([]dblist.WherePredicate(nil), p.where...),
 	}
 }
@@ -240,45 +388,91 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 			case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded:
 			}
 		}
-		// This indicates the user overrode the States list with only non-finalized
+		// This indicates the user overrode the States list with non-finalized
 		// states prior to then requesting FinalizedAt ordering.
-		if len(currentNonFinalizedStates) == 0 {
+		if len(currentNonFinalizedStates) > 0 {
 			return nil, fmt.Errorf("cannot order by finalized_at with non-finalized state filters %+v", currentNonFinalizedStates)
 		}
 	}
 
-	var timeField string
+	var primaryColumn string
 	switch {
 	case p.sortField == JobListOrderByID:
-		// no time field
+		// no column other than ID
 
 	case len(p.states) > 0 && p.sortField == JobListOrderByTime:
-		timeField = jobListTimeFieldForState(p.states[0])
-		orderBy = append(orderBy, dblist.JobListOrderBy{Expr: timeField, Order: sortOrder})
+		primaryColumn = jobListTimeFieldForState(p.states[0])
 
 	default:
-		timeField = string(p.sortField)
-		orderBy = append(orderBy, dblist.JobListOrderBy{Expr: timeField, Order: sortOrder})
+		primaryColumn = string(p.sortField)
+	}
+
+	if primaryColumn != "" {
+		orderBy = append(orderBy, dblist.JobListOrderBy{Expr: primaryColumn, Order: sortOrder})
+	}
+
+	thenSortOrders := make([]dblist.SortOrder, len(p.thenSorts))
+	for i, thenSort := range p.thenSorts {
+		thenSortOrders[i] = dblist.SortOrderAsc
+		if thenSort.order == SortOrderDesc {
+			thenSortOrders[i] = dblist.SortOrderDesc
+		}
+
+		orderBy = append(orderBy, dblist.JobListOrderBy{
+			Expr:     string(thenSort.field),
+			Nullable: thenSort.field == JobListOrderByFinalizedAt,
+			Order:    thenSortOrders[i],
+		})
 	}
 
 	orderBy = append(orderBy, dblist.JobListOrderBy{Expr: "id", Order: sortOrder})
 
+	// Copied so that adding the cursor's predicate doesn't modify params,
+	// which may be used again.
+	where := append([]dblist.WherePredicate(nil), p.where...)
+
 	if p.after != nil {
-		namedArgs := map[string]any{"after_id": p.after.id}
-		if p.after.time.IsZero() { // order by ID only
-			if sortOrder == dblist.SortOrderAsc {
-				p.where = append(p.where, dblist.WherePredicate{NamedArgs: namedArgs, SQL: "(id > @after_id)"})
-			} else {
-				p.where = append(p.where, dblist.WherePredicate{NamedArgs: namedArgs, SQL: "(id < @after_id)"})
-			}
-		} else {
-			namedArgs["cursor_time"] = p.after.time
-			if sortOrder == dblist.SortOrderAsc {
-				p.where = append(p.where, dblist.WherePredicate{NamedArgs: namedArgs, SQL: fmt.Sprintf(`("%s" > @cursor_time OR ("%s" = @cursor_time AND "id" > @after_id))`, timeField, timeField)})
-			} else {
-				p.where = append(p.where, dblist.WherePredicate{NamedArgs: namedArgs, SQL: fmt.Sprintf(`("%s" < @cursor_time OR ("%s" = @cursor_time AND "id" < @after_id))`, timeField, timeField)})
+		if p.after.sortField != "" && (p.after.sortField != p.sortField ||
+			p.after.sortOrder != nil && *p.after.sortOrder != p.sortOrder ||
+			!slices.Equal(p.after.thenSorts, p.thenSorts)) {
+			return nil, errors.New("cursor is for a list with a different sort; call OrderBy before After, or omit it to use the cursor's sort")
+		}
+		if len(p.after.thenValues) != len(p.thenSorts) {
+			return nil, errors.New("cursor is missing values for secondary sorts")
+		}
+
+		keysetColumns := make([]jobListKeysetColumn, 0, len(p.thenSorts)+2)
+
+		switch p.sortField {
+		case JobListOrderByID:
+		case JobListOrderByKind:
+			keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "cursor_kind", column: primaryColumn, order: sortOrder, value: p.after.kind})
+		case JobListOrderByPriority:
+			keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "cursor_priority", column: primaryColumn, order: sortOrder, value: p.after.priority})
+		case JobListOrderByQueue:
+			keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "cursor_queue", column: primaryColumn, order: sortOrder, value: p.after.queue})
+		case JobListOrderByState:
+			keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "cursor_state", column: primaryColumn, order: sortOrder, value: string(p.after.state)})
+		case JobListOrderByCreatedAt, JobListOrderByFinalizedAt, JobListOrderByScheduledAt, JobListOrderByTime:
+			// A zero time indicates a cursor made for ordering by ID only.
+			if !p.after.time.IsZero() {
+				keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "cursor_time", column: primaryColumn, order: sortOrder, value: p.after.time})
 			}
 		}
+
+		for i, thenSort := range p.thenSorts {
+			keysetColumns = append(keysetColumns, jobListKeysetColumn{
+				arg:      fmt.Sprintf("cursor_then_%d", i),
+				column:   string(thenSort.field),
+				nullable: thenSort.field == JobListOrderByFinalizedAt,
+				order:    thenSortOrders[i],
+				value:    p.after.thenValues[i],
+			})
+		}
+
+		keysetColumns = append(keysetColumns, jobListKeysetColumn{arg: "after_id", column: "id", order: sortOrder, value: p.after.id})
+
+		where = append(where, jobListKeysetPredicate(keysetColumns))
 	}
 
 	return &dblist.JobListParams{
@@ -290,22 +484,58 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 		Queues:     p.queues,
 		Schema:     p.schema,
 		States:     p.states,
-		Where:      p.where,
+		Where:      where,
 	}, nil
 }
 
 // After returns an updated filter set that will only return jobs
 // after the given cursor.
+//
+// A cursor from JobListResult.LastCursor carries the sort of the list it came
+// from, which is applied to the returned filter set so that the next page
+// continues in the same order. A cursor from JobListCursorFromJob uses the sort
+// already configured, so OrderBy should be called before After.
 func (p *JobListParams) After(cursor *JobListCursor) *JobListParams {
 	paramsCopy := p.copy()
 	if cursor.job == nil {
 		paramsCopy.after = cursor
+		if cursor.sortField != "" {
+			paramsCopy.sortField = cursor.sortField
+			paramsCopy.thenSorts = cursor.thenSorts
+		}
+		if cursor.sortOrder != nil {
+			paramsCopy.sortOrder = *cursor.sortOrder
+		}
 	} else {
 		paramsCopy.after = jobListCursorFromJobAndParams(cursor.job, paramsCopy)
 	}
 	return paramsCopy
 }
 
+// CountTotal returns an updated filter set that also counts the total number of
+// jobs matching its filters, regardless of pagination, and returns it in
+// JobListResult.Total. This is useful for rendering pagination controls without
+// issuing a separate query.
+//
+// Matching jobs are counted exactly up to exactMax. Counting is expensive when
+// many jobs match, so beyond exactMax the total is an estimate from the
+// database's query planner instead, and JobListTotal.Exact is false. SQLite
+// doesn't provide planner estimates, so there the total is exactMax when more
+// jobs than that match.
+//
+// exactMax must be between 1 and 100_000, inclusive, or this will panic.
+func (p *JobListParams) CountTotal(exactMax int) *JobListParams {
+	if exactMax <= 0 {
+		panic("exactMax must be > 0")
+	}
+	if exactMax > 100_000 {
+		panic("exactMax must be <= 100_000")
+	}
+	paramsCopy := p.copy()
+	paramsCopy.countTotalMax = int32(exactMax)
+	return paramsCopy
+}
+
 // First returns an updated filter set that will only return the first
 // count jobs.
 //
@@ -365,13 +595,14 @@ func (p *JobListParams) Metadata(json string) *JobListParams {
 //
 // If ordering by FinalizedAt, the States filter will be set to only include
 // finalized job states unless it has already been overridden.
+//
+// OrderBy sets the primary sort and removes any secondary sorts added with
+// ThenOrderBy, so it should be called before ThenOrderBy.
 func (p *JobListParams) OrderBy(field JobListOrderByField, direction SortOrder) *JobListParams {
 	paramsCopy := p.copy()
 	switch field {
-	case JobListOrderByID, JobListOrderByTime, JobListOrderByScheduledAt:
-		paramsCopy.sortField = field
+	case JobListOrderByCreatedAt, JobListOrderByID, JobListOrderByKind, JobListOrderByPriority, JobListOrderByQueue, JobListOrderByScheduledAt, JobListOrderByState, JobListOrderByTime:
 	case JobListOrderByFinalizedAt:
-		paramsCopy.sortField = field
 		if !p.overrodeState {
 			paramsCopy.states = []rivertype.JobState{
 				rivertype.JobStateCancelled,
@@ -384,6 +615,7 @@ func (p *JobListParams) OrderBy(field JobListOrderByField, direction SortOrder)
 	}
 	paramsCopy.sortField = field
 	paramsCopy.sortOrder = direction
+	paramsCopy.thenSorts = nil
 	return paramsCopy
 }
 
@@ -405,6 +637,46 @@ func (p *JobListParams) Queues(queues ...string) *JobListParams {
 	return paramsCopy
 }
 
+// ThenOrderBy returns an updated filter set that sorts results by an
+// additional field and direction after those of OrderBy and previous calls to
+// ThenOrderBy, breaking ties between jobs with equal values in earlier sorts.
+// For example, to list jobs by highest priority, then oldest first:
+//
+//	listParams := river.NewJobListParams().
+//		OrderBy(river.JobListOrderByPriority, river.SortOrderAsc).
+//		ThenOrderBy(river.JobListOrderByCreatedAt, river.SortOrderAsc)
+//
+// Jobs are always sorted by ID last, in the direction of OrderBy. Cursors taken
+// from results of a list with secondary sorts include them, and paginate
+// correctly through jobs with equal values in any sorted field.
+//
+// Unlike OrderBy, sorting by FinalizedAt with ThenOrderBy doesn't change the
+// States filter. Jobs that aren't finalized have no `finalized_at`, and sort
+// after all others in ascending order and before them in descending order.
+//
+// Panics if field is JobListOrderByID or JobListOrderByTime, if the list is
+// sorted by JobListOrderByID, or if it's already sorted by field.
+func (p *JobListParams) ThenOrderBy(field JobListOrderByField, direction SortOrder) *JobListParams {
+	switch field {
+	case JobListOrderByCreatedAt, JobListOrderByFinalizedAt, JobListOrderByKind, JobListOrderByPriority, JobListOrderByQueue, JobListOrderByScheduledAt, JobListOrderByState:
+	case JobListOrderByID, JobListOrderByTime:
+		panic("cannot use " + string(field) + " as a secondary sort field")
+	default:
+		panic("invalid order by field")
+	}
+
+	if p.sortField == JobListOrderByID {
+		panic("cannot add a secondary sort to a list sorted by id, which is unique")
+	}
+	if field == p.sortField || slices.ContainsFunc(p.thenSorts, func(s jobListSort) bool { return s.field == field }) {
+		panic("list is already sorted by " + string(field))
+	}
+
+	paramsCopy := p.copy()
+	paramsCopy.thenSorts = append(paramsCopy.thenSorts, jobListSort{field: field, order: direction})
+	return paramsCopy
+}
+
 // States returns an updated filter set that will only return jobs in the given
 // states.
 func (p *JobListParams) States(states ...rivertype.JobState) *JobListParams {
@@ -466,6 +738,134 @@ func (p *JobListParams) Where(sql string, namedArgsMany ...NamedArgs) *JobListPa
 	return paramsCopy
 }
 
+// A column in the sort of a list along with the cursor's value for it, used to
+// build a keyset pagination predicate.
+type jobListKeysetColumn struct {
+	arg      string
+	column   string
+	nullable bool
+	order    dblist.SortOrder
+	value    any
+}
+
+// Builds a predicate selecting rows that sort after the cursor's values for the
+// given columns. Rows sort after the cursor if they're after it in the first
+// column, or equal in the first column and after it in the second, and so on:
+//
+//	(a > @a) OR (a = @a AND b > @b) OR (a = @a AND b = @b AND id > @id)
+//
+// Nulls in nullable columns are considered greater than any other value, to
+// match how they're sorted.
+func jobListKeysetPredicate(columns []jobListKeysetColumn) dblist.WherePredicate {
+	var (
+		disjuncts = make([]string, 0, len(columns))
+		equals    = make([]string, 0, len(columns))
+		namedArgs = make(map[string]any, len(columns))
+	)
+
+	for _, column := range columns {
+		isNull := column.nullable && column.value == nil
+		if !isNull {
+			namedArgs[column.arg] = column.value
+		}
+
+		var after string
+		switch {
+		case isNull && column.order == dblist.SortOrderDesc:
+			after = fmt.Sprintf(`"%s" IS NOT NULL`, column.column)
+		case isNull:
+			// Nothing sorts after null in ascending order.
+		case column.order == dblist.SortOrderDesc:
+			after = fmt.Sprintf(`"%s" < @%s`, column.column, column.arg)
+		case column.nullable:
+			after = fmt.Sprintf(`("%[1]s" > @%[2]s OR "%[1]s" IS NULL)`, column.column, column.arg)
+		default:
+			after = fmt.Sprintf(`"%s" > @%s`, column.column, column.arg)
+		}
+
+		if after != "" {
+			if len(equals) > 0 {
+				disjuncts = append(disjuncts, "("+strings.Join(append(equals, after), " AND ")+")")
+			} else {
+				disjuncts = append(disjuncts, after)
+			}
+		}
+
+		if isNull {
+			equals = append(equals, fmt.Sprintf(`"%s" IS NULL`, column.column))
+		} else {
+			equals = append(equals, fmt.Sprintf(`"%s" = @%s`, column.column, column.arg))
+		}
+	}
+
+	return dblist.WherePredicate{NamedArgs: namedArgs, SQL: "(" + strings.Join(disjuncts, " OR ") + ")"}
+}
+
+// Returns a job's value for a field sorted with JobListParams.ThenOrderBy, as
+// stored in a cursor.
+func jobListSortValue(job *rivertype.JobRow, field JobListOrderByField) any {
+	switch field {
+	case JobListOrderByCreatedAt:
+		return job.CreatedAt
+	case JobListOrderByFinalizedAt:
+		if job.FinalizedAt == nil {
+			return nil
+		}
+		return *job.FinalizedAt
+	case JobListOrderByKind:
+		return job.Kind
+	case JobListOrderByPriority:
+		return job.Priority
+	case JobListOrderByQueue:
+		return job.Queue
+	case JobListOrderByScheduledAt:
+		return job.ScheduledAt
+	case JobListOrderByState:
+		return string(job.State)
+	case JobListOrderByID, JobListOrderByTime:
+	}
+
+	panic("invalid secondary sort field: " + string(field))
+}
+
+// Decodes a value from a serialized cursor for a field sorted with
+// JobListParams.ThenOrderBy, restoring the type returned by jobListSortValue.
+func jobListSortValueUnmarshal(field JobListOrderByField, data json.RawMessage) (any, error) {
+	unmarshal := func(dst any) error {
+		if err := json.Unmarshal(data, dst); err != nil {
+			return fmt.Errorf("invalid cursor value for %s: %w", field, err)
+		}
+		return nil
+	}
+
+	switch field {
+	case JobListOrderByCreatedAt, JobListOrderByFinalizedAt, JobListOrderByScheduledAt:
+		var value *time.Time
+		if err := unmarshal(&value); err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, nil //nolint:nilnil
+		}
+		return *value, nil
+	case JobListOrderByPriority:
+		var value int
+		if err := unmarshal(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case JobListOrderByKind, JobListOrderByQueue, JobListOrderByState:
+		var value string
+		if err := unmarshal(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case JobListOrderByID, JobListOrderByTime:
+	}
+
+	return nil, fmt.Errorf("invalid cursor secondary sort field: %q", field)
+}
+
 func jobListTimeFieldForState(state rivertype.JobState) string {
 	// Don't include a `default` so `exhaustive` lint can detect omissions.
 	switch state {
@@ -1,6 +1,7 @@
 package river
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -171,6 +172,87 @@ func Test_JobListCursor_MarshalJSON(t *testing.T) {
 		require.Equal(t, cursor, unmarshaledParams)
 	})
 
+	t.Run("CanMarshalAndUnmarshalWithSort", func(t *testing.T) {
+		t.Parallel()
+
+		cursor := &JobListCursor{
+			id:        4,
+			kind:      "test_kind",
+			priority:  2,
+			queue:     "test_queue",
+			sortField: JobListOrderByPriority,
+			sortOrder: ptrutil.Ptr(SortOrderDesc),
+		}
+
+		text, err := json.Marshal(cursor)
+		require.NoError(t, err)
+
+		unmarshaledCursor := &JobListCursor{}
+		require.NoError(t, json.Unmarshal(text, unmarshaledCursor))
+
+		require.Equal(t, cursor, unmarshaledCursor)
+	})
+
+	t.Run("CanMarshalAndUnmarshalWithThenSorts", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now().UTC()
+		cursor := &JobListCursor{
+			id:        4,
+			kind:      "test_kind",
+			priority:  2,
+			queue:     "test_queue",
+			sortField: JobListOrderByState,
+			sortOrder: ptrutil.Ptr(SortOrderAsc),
+			state:     rivertype.JobStateCompleted,
+			thenSorts: []jobListSort{
+				{field: JobListOrderByFinalizedAt, order: SortOrderDesc},
+				{field: JobListOrderByScheduledAt, order: SortOrderAsc},
+				{field: JobListOrderByPriority, order: SortOrderAsc},
+				{field: JobListOrderByKind, order: SortOrderDesc},
+			},
+			thenValues: []any{nil, now, 2, "test_kind"},
+		}
+
+		text, err := json.Marshal(cursor)
+		require.NoError(t, err)
+
+		unmarshaledCursor := &JobListCursor{}
+		require.NoError(t, json.Unmarshal(text, unmarshaledCursor))
+
+		require.Equal(t, cursor, unmarshaledCursor)
+	})
+
+	t.Run("ErrorsOnMismatchedThenSorts", func(t *testing.T) {
+		t.Parallel()
+
+		text := base64.URLEncoding.EncodeToString([]byte(`{"id":4,"sort_field":"priority","then_sorts":[{"field":"kind","order":"asc"}]}`))
+
+		cursor := &JobListCursor{}
+		require.EqualError(t, cursor.UnmarshalText([]byte(text)), "invalid cursor: mismatched secondary sorts and values")
+	})
+
+	t.Run("UnmarshalsCursorWithoutSortOrder", func(t *testing.T) {
+		t.Parallel()
+
+		text := base64.URLEncoding.EncodeToString([]byte(`{"id":4,"kind":"test_kind","queue":"test_queue","sort_field":"id","time":"0001-01-01T00:00:00Z"}`))
+
+		cursor := &JobListCursor{}
+		require.NoError(t, cursor.UnmarshalText([]byte(text)))
+		require.Equal(t, int64(4), cursor.id)
+		require.Equal(t, JobListOrderByID, cursor.sortField)
+		require.Nil(t, cursor.sortOrder)
+	})
+
+	t.Run("ErrorsOnInvalidSortOrder", func(t *testing.T) {
+		t.Parallel()
+
+		text := base64.URLEncoding.EncodeToString([]byte(`{"id":4,"sort_field":"id","sort_order":"sideways"}`))
+
+		cursor := &JobListCursor{}
+		require.EqualError(t, cursor.UnmarshalText([]byte(text)), `invalid cursor sort order: "sideways"`)
+	})
+
 	t.Run("ErrorsOnJobOnlyCursor", func(t *testing.T) {
 		t.Parallel()
 
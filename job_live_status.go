@@ -0,0 +1,160 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivernotify"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Default time that JobLiveStatus waits for a response from the client working
+// a job if the context it was given has no deadline.
+const jobLiveStatusTimeoutDefault = 5 * time.Second
+
+// Progress is sent in a notification, which Postgres limits to 8000 bytes, so
+// it's kept well under that to leave room for the rest of the payload.
+const maxProgressSizeBytes = 4096
+
+// ErrJobNotRunning is returned by Client.JobLiveStatus when the job isn't
+// running, or when the client that last attempted it reports that it's not
+// working it.
+var ErrJobNotRunning = errors.New("job is not running")
+
+// JobLiveStatus is the live status of a running job as reported by the client
+// working it. It's returned by Client.JobLiveStatus.
+type JobLiveStatus struct {
+	// ClientID is the ID of the client working the job.
+	ClientID string
+
+	// Elapsed is the time since the job's current attempt started.
+	Elapsed time.Duration
+
+	// Host is the hostname of the machine running the client working the job.
+	// Empty if the client couldn't determine its hostname.
+	Host string
+
+	// Job is the job's row as it was in the database when its live status was
+	// requested.
+	Job *rivertype.JobRow
+
+	// Progress is the JSON-encoded progress most recently recorded by the
+	// job's worker with RecordProgress, or empty if none was recorded.
+	Progress json.RawMessage
+}
+
+// JobLiveStatus asks the client currently working a job for its live status,
+// including its elapsed time, the host it's running on, and any progress its
+// worker recorded with RecordProgress. It's meant as an operational debugging
+// aid for long-running jobs:
+//
+//	status, err := client.JobLiveStatus(ctx, jobID)
+//	if err != nil {
+//		// handle error
+//	}
+//	fmt.Printf("running on %s for %s: %s\n", status.Host, status.Elapsed, status.Progress)
+//
+// The request and response are exchanged over Postgres listen/notify, so both
+// this client and the one working the job must be started and not in PollOnly
+// mode. If ctx has no deadline, the response is waited on for up to 5 seconds.
+// An error is returned if no response arrives in time, which may happen if the
+// client working the job has stopped without releasing it.
+//
+// Returns ErrJobNotRunning if the job isn't running, and ErrNotFound if it
+// doesn't exist.
+func (c *Client[TTx]) JobLiveStatus(ctx context.Context, id int64) (*JobLiveStatus, error) {
+	if c.controlBus == nil {
+		return nil, errors.New("live job status requires listen/notify and isn't available in poll-only mode")
+	}
+
+	job, err := c.JobGet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.State != rivertype.JobStateRunning || len(job.AttemptedBy) < 1 {
+		return nil, ErrJobNotRunning
+	}
+
+	clientID := job.AttemptedBy[len(job.AttemptedBy)-1]
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, jobLiveStatusTimeoutDefault)
+		defer cancel()
+	}
+
+	status, err := c.controlBus.requestJobStatus(ctx, clientID, job)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrJobNotRunning
+	}
+
+	return &JobLiveStatus{
+		ClientID: clientID,
+		Elapsed:  time.Duration(status.ElapsedMS) * time.Millisecond,
+		Host:     status.Host,
+		Job:      job,
+		Progress: status.Progress,
+	}, nil
+}
+
+// Returns the live status of a job being worked by one of the client's
+// producers, or nil if it's not being worked. Used by the control bus to answer
+// live status requests from other clients.
+func (c *Client[TTx]) activeJobStatus(queue string, jobID int64) *rivernotify.JobStatus {
+	c.producersMu.RLock()
+	producer, ok := c.producersByQueueName[queue]
+	c.producersMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return producer.activeJobStatus(jobID)
+}
+
+// RecordProgress records the progress of a job as it's being worked so that
+// it's visible to Client.JobLiveStatus. Progress may be any JSON-encodable
+// value, like a count of items processed:
+//
+//	func (w *MyWorker) Work(ctx context.Context, job *river.Job[MyArgs]) error {
+//		for i, item := range job.Args.Items {
+//			if err := river.RecordProgress(ctx, map[string]int{"done": i, "total": len(job.Args.Items)}); err != nil {
+//				return err
+//			}
+//			...
+//		}
+//		return nil
+//	}
+//
+// Progress is held in memory by the client working the job and isn't stored in
+// the database, so recording it is cheap enough to do frequently. Each call
+// replaces progress recorded previously. Because it's sent in a notification,
+// encoded progress is limited to 4 KB.
+//
+// This function must be called within a Worker's Work function. It returns an
+// error if called anywhere else.
+func RecordProgress(ctx context.Context, progress any) error {
+	jobProgress, ok := jobexecutor.ProgressFromWorkContext(ctx)
+	if !ok {
+		return errors.New("RecordProgress must be called within a Worker")
+	}
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	if len(progressBytes) > maxProgressSizeBytes {
+		return fmt.Errorf("progress is too large: %d bytes (max %d bytes)", len(progressBytes), maxProgressSizeBytes)
+	}
+
+	jobProgress.Set(progressBytes)
+	return nil
+}
@@ -0,0 +1,289 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_Client_JobLiveStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	type testBundle struct {
+		dbPool         *pgxpool.Pool
+		exec           riverdriver.Executor
+		jobDoneChan    chan struct{}
+		jobStartedChan chan int64
+		schema         string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool         = riversharedtest.DBPool(ctx, t)
+			driver         = riverpgxv5.New(dbPool)
+			schema         = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config         = newTestConfig(t, schema)
+			jobDoneChan    = make(chan struct{})
+			jobStartedChan = make(chan int64, 1)
+		)
+
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			if err := RecordProgress(ctx, map[string]int{"done": 5, "total": 10}); err != nil {
+				return err
+			}
+
+			jobStartedChan <- job.ID
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-jobDoneChan:
+			}
+
+			return nil
+		}))
+
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool:         dbPool,
+			exec:           client.driver.GetExecutor(),
+			jobDoneChan:    jobDoneChan,
+			jobStartedChan: jobStartedChan,
+			schema:         schema,
+		}
+	}
+
+	t.Run("RunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobStartedChan)
+		t.Cleanup(func() { close(bundle.jobDoneChan) })
+
+		status, err := client.JobLiveStatus(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, client.ID(), status.ClientID)
+		require.GreaterOrEqual(t, status.Elapsed, time.Duration(0))
+		require.Equal(t, insertRes.Job.ID, status.Job.ID)
+		require.JSONEq(t, `{"done":5,"total":10}`, string(status.Progress))
+	})
+
+	t.Run("RunningJobFromOtherClient", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobStartedChan)
+		t.Cleanup(func() { close(bundle.jobDoneChan) })
+
+		otherConfig := newTestConfig(t, bundle.schema)
+		otherConfig.ID = "other_client"
+		otherConfig.Queues = map[string]QueueConfig{"other_queue": {MaxWorkers: 1}}
+
+		otherClient := newTestClient(t, bundle.dbPool, otherConfig)
+		startClient(ctx, t, otherClient)
+		riversharedtest.WaitOrTimeout(t, otherClient.baseStartStop.Started())
+
+		status, err := otherClient.JobLiveStatus(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, client.ID(), status.ClientID)
+		require.JSONEq(t, `{"done":5,"total":10}`, string(status.Progress))
+	})
+
+	t.Run("JobNotRunning", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Schema: bundle.schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		_, err := client.JobLiveStatus(ctx, job.ID)
+		require.ErrorIs(t, err, ErrJobNotRunning)
+	})
+
+	t.Run("JobNotWorkedByClient", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		// Running and attempted by the client according to the database, but
+		// not actually being worked by it.
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			AttemptedAt: ptrutil.Ptr(time.Now()),
+			AttemptedBy: []string{client.ID()},
+			Queue:       ptrutil.Ptr("not_worked_queue"),
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		_, err := client.JobLiveStatus(ctx, job.ID)
+		require.ErrorIs(t, err, ErrJobNotRunning)
+	})
+
+	t.Run("JobNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		_, err := client.JobLiveStatus(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		startClient(ctx, t, client)
+		riversharedtest.WaitOrTimeout(t, client.baseStartStop.Started())
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			AttemptedAt: ptrutil.Ptr(time.Now()),
+			AttemptedBy: []string{"stopped_client"},
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		_, err := client.JobLiveStatus(ctx, job.ID)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, `no live status response from client "stopped_client"`)
+	})
+
+	t.Run("ClientNotStarted", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			AttemptedAt: ptrutil.Ptr(time.Now()),
+			AttemptedBy: []string{"other_client"},
+			Schema:      bundle.schema,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		_, err := client.JobLiveStatus(ctx, job.ID)
+		require.EqualError(t, err, "client must be started to request live job status")
+	})
+
+	t.Run("PollOnly", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+		config.PollOnly = true
+
+		client := newTestClient(t, dbPool, config)
+
+		_, err := client.JobLiveStatus(ctx, 1)
+		require.EqualError(t, err, "live job status requires listen/notify and isn't available in poll-only mode")
+	})
+}
+
+func Test_RecordProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	// Runs a job whose worker records the given progress, returning the error
+	// from RecordProgress.
+	recordProgressInWorker := func(t *testing.T, progress any) error {
+		t.Helper()
+
+		var (
+			dbPool  = riversharedtest.DBPool(ctx, t)
+			driver  = riverpgxv5.New(dbPool)
+			schema  = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config  = newTestConfig(t, schema)
+			errChan = make(chan error, 1)
+		)
+
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			errChan <- RecordProgress(ctx, progress)
+			return nil
+		}))
+
+		client := newTestClient(t, dbPool, config)
+		startClient(ctx, t, client)
+
+		_, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		return riversharedtest.WaitOrTimeout(t, errChan)
+	}
+
+	t.Run("InWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, recordProgressInWorker(t, map[string]int{"done": 5}))
+	})
+
+	t.Run("NotInWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, RecordProgress(ctx, "progress"), "RecordProgress must be called within a Worker")
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, recordProgressInWorker(t, strings.Repeat("x", maxProgressSizeBytes)), "progress is too large: 4098 bytes (max 4096 bytes)")
+	})
+
+	t.Run("Unencodable", func(t *testing.T) {
+		t.Parallel()
+
+		var unsupportedTypeErr *json.UnsupportedTypeError
+		require.ErrorAs(t, recordProgressInWorker(t, make(chan int)), &unsupportedTypeErr)
+	})
+}
@@ -0,0 +1,108 @@
+package river
+
+import (
+	"context"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+// KindPauseOpts are optional settings for pausing or resuming a job kind.
+type KindPauseOpts struct{}
+
+// KindPause pauses the job kind with the given name across all queues. Jobs of
+// a paused kind remain available but aren't fetched by any client until the
+// kind is resumed, while other kinds in the same queues continue to be worked
+// as usual. It can be used to halt a misbehaving worker fleet-wide without
+// pausing entire queues. Jobs of the kind that are already running aren't
+// affected. Pausing a kind that's already paused is a no-op.
+//
+// Unlike pausing a queue, pausing a kind doesn't require a notification to
+// take effect because it's enforced by the query that fetches jobs.
+//
+// The provided context is used for the underlying database insert and can be
+// used to cancel the operation or apply a timeout. The opts are reserved for
+// future functionality.
+func (c *Client[TTx]) KindPause(ctx context.Context, kind string, opts *KindPauseOpts) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+
+	return c.kindPause(ctx, c.driver.GetExecutor(), kind)
+}
+
+// KindPauseTx pauses the job kind with the given name across all queues. Jobs
+// of a paused kind remain available but aren't fetched by any client until the
+// kind is resumed, while other kinds in the same queues continue to be worked
+// as usual. Pausing a kind that's already paused is a no-op.
+//
+// The kind is paused once the transaction commits.
+//
+// The provided context is used for the underlying database insert and can be
+// used to cancel the operation or apply a timeout. The opts are reserved for
+// future functionality.
+func (c *Client[TTx]) KindPauseTx(ctx context.Context, tx TTx, kind string, opts *KindPauseOpts) error {
+	return c.kindPause(ctx, c.driver.UnwrapExecutor(tx), kind)
+}
+
+func (c *Client[TTx]) kindPause(ctx context.Context, exec riverdriver.Executor, kind string) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	return exec.JobKindPause(ctx, &riverdriver.JobKindPauseParams{
+		Kind:   kind,
+		Now:    c.baseService.Time.NowOrNil(),
+		Schema: c.config.Schema,
+	})
+}
+
+// KindResume resumes the job kind with the given name after it was paused with
+// KindPause. Resuming a kind that isn't paused is a no-op.
+//
+// This client's producers fetch jobs of the kind immediately. Other clients
+// pick them up on their next fetch, which happens no later than their
+// configured FetchPollInterval.
+//
+// The provided context is used for the underlying database delete and can be
+// used to cancel the operation or apply a timeout. The opts are reserved for
+// future functionality.
+func (c *Client[TTx]) KindResume(ctx context.Context, kind string, opts *KindPauseOpts) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+
+	if err := c.kindResume(ctx, c.driver.GetExecutor(), kind); err != nil {
+		return err
+	}
+
+	for _, producer := range c.producersByQueueName {
+		producer.TriggerJobFetch()
+	}
+
+	return nil
+}
+
+// KindResumeTx resumes the job kind with the given name after it was paused
+// with KindPause. Resuming a kind that isn't paused is a no-op.
+//
+// The kind is resumed once the transaction commits, after which clients pick
+// up its jobs on their next fetch, which happens no later than their
+// configured FetchPollInterval.
+//
+// The provided context is used for the underlying database delete and can be
+// used to cancel the operation or apply a timeout. The opts are reserved for
+// future functionality.
+func (c *Client[TTx]) KindResumeTx(ctx context.Context, tx TTx, kind string, opts *KindPauseOpts) error {
+	return c.kindResume(ctx, c.driver.UnwrapExecutor(tx), kind)
+}
+
+func (c *Client[TTx]) kindResume(ctx context.Context, exec riverdriver.Executor, kind string) error {
+	if c.config.ReadOnly {
+		return ErrClientReadOnly
+	}
+
+	return exec.JobKindResume(ctx, &riverdriver.JobKindResumeParams{
+		Kind:   kind,
+		Schema: c.config.Schema,
+	})
+}
@@ -0,0 +1,121 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_Client_KindPause(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	setup := func(t *testing.T) *Client[pgx.Tx] {
+		t.Helper()
+
+		config := newTestConfig(t, "")
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		return runNewTestClient(ctx, t, config)
+	}
+
+	t.Run("PausedKindNotWorked", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		subscribeChan := subscribe(t, client)
+
+		require.NoError(t, client.KindPause(ctx, (noOpArgs{}).Kind(), nil))
+
+		pausedRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		// A job of another kind in the same queue is still worked.
+		otherRes, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, otherRes.Job.ID, event.Job.ID)
+
+		// Give the producer a few fetch polls in which it could've worked the
+		// paused job.
+		time.Sleep(3 * client.config.FetchPollInterval)
+
+		job, err := client.JobGet(ctx, pausedRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		require.NoError(t, client.KindResume(ctx, (noOpArgs{}).Kind(), nil))
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, pausedRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("PauseTxRolledBack", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		subscribeChan := subscribe(t, client)
+
+		tx, err := client.driver.GetExecutor().Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, client.KindPauseTx(ctx, client.driver.UnwrapTx(tx), (noOpArgs{}).Kind(), nil))
+		require.NoError(t, tx.Rollback(ctx))
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("ResumeTx", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		subscribeChan := subscribe(t, client)
+
+		require.NoError(t, client.KindPause(ctx, (noOpArgs{}).Kind(), nil))
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		tx, err := client.driver.GetExecutor().Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, client.KindResumeTx(ctx, client.driver.UnwrapTx(tx), (noOpArgs{}).Kind(), nil))
+		require.NoError(t, tx.Commit(ctx))
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, client.KindPause(ctx, (noOpArgs{}).Kind(), nil), errNoDriverDBPool)
+		require.ErrorIs(t, client.KindResume(ctx, (noOpArgs{}).Kind(), nil), errNoDriverDBPool)
+	})
+}
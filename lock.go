@@ -0,0 +1,72 @@
+package river
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivershared/util/hashutil"
+)
+
+// WithLock runs fn while holding a Postgres advisory lock identified by key,
+// which is useful for guarding a critical section inside a worker that must
+// not run concurrently with others using the same key, even across processes:
+//
+//	func (w *SyncAccountWorker) Work(ctx context.Context, job *river.Job[SyncAccountArgs]) error {
+//		return river.WithLock(ctx, "sync_account:"+job.Args.AccountID, func(ctx context.Context) error {
+//			...
+//		})
+//	}
+//
+// If another caller holds the lock, WithLock waits until it's released or ctx
+// is cancelled. The lock is released when fn returns, whether it returned an
+// error or not.
+//
+// Keys are hashed into a namespace that's separate from locks taken by River
+// itself, and incorporate Config.AdvisoryLockPrefix if set so that they can be
+// guaranteed not to conflict with an application's own advisory locks.
+//
+// The lock is held by a transaction that stays open for the duration of fn, so
+// each concurrent call occupies a database connection until it returns, and fn
+// should be kept short. WithLock can only be used with a context from a
+// Worker's Work method (or rivertest.WorkContext) because it needs the client
+// to access the database. It's only supported by Postgres drivers.
+func WithLock(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	locker, ok := ctx.Value(rivercommon.ContextKeyClient{}).(advisoryLocker)
+	if !ok {
+		return errClientNotInContext
+	}
+
+	return locker.withLock(ctx, key, fn)
+}
+
+// advisoryLocker is implemented by Client so that WithLock can be used without
+// knowing the client's transaction type.
+type advisoryLocker interface {
+	withLock(ctx context.Context, key string, fn func(ctx context.Context) error) error
+}
+
+func (c *Client[TTx]) withLock(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	if !c.driver.PoolIsSet() {
+		return errNoDriverDBPool
+	}
+
+	return dbutil.WithTx(ctx, c.driver.GetExecutor(), func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		if _, err := execTx.PGAdvisoryXactLock(ctx, withLockKey(c.config.AdvisoryLockPrefix, key)); err != nil {
+			return fmt.Errorf("error acquiring lock %q: %w", key, err)
+		}
+
+		return fn(ctx)
+	})
+}
+
+// withLockKey hashes a WithLock key to an advisory lock key. A namespace is
+// prepended so that user keys can't produce the same hash as locks taken
+// internally by River.
+func withLockKey(prefix int32, key string) int64 {
+	lockHash := hashutil.NewAdvisoryLockHash(prefix)
+	lockHash.Write([]byte("river_with_lock:" + key))
+	return lockHash.Key()
+}
@@ -0,0 +1,114 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestWithLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	setup := func(t *testing.T) context.Context {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+		)
+
+		return withClient(ctx, client)
+	}
+
+	t.Run("RunsFunc", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx := setup(t)
+
+		var called bool
+		require.NoError(t, WithLock(workCtx, "key", func(ctx context.Context) error {
+			called = true
+			return nil
+		}))
+		require.True(t, called)
+	})
+
+	t.Run("ReturnsFuncError", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx := setup(t)
+
+		require.EqualError(t, WithLock(workCtx, "key", func(ctx context.Context) error {
+			return errors.New("func error")
+		}), "func error")
+
+		// Lock was released despite the error.
+		require.NoError(t, WithLock(workCtx, "key", func(ctx context.Context) error { return nil }))
+	})
+
+	t.Run("ExcludesConcurrentHolders", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx := setup(t)
+
+		var (
+			acquired = make(chan struct{})
+			release  = make(chan struct{})
+			errChan  = make(chan error)
+		)
+		go func() {
+			errChan <- WithLock(workCtx, "key", func(ctx context.Context) error {
+				close(acquired)
+				<-release
+				return nil
+			})
+		}()
+
+		riversharedtest.WaitOrTimeout(t, acquired)
+
+		// A different key isn't blocked.
+		require.NoError(t, WithLock(workCtx, "other_key", func(ctx context.Context) error { return nil }))
+
+		timeoutCtx, cancel := context.WithTimeout(workCtx, 100*time.Millisecond)
+		defer cancel()
+
+		err := WithLock(timeoutCtx, "key", func(ctx context.Context) error {
+			require.FailNow(t, "lock should not have been acquired")
+			return nil
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errChan))
+
+		require.NoError(t, WithLock(workCtx, "key", func(ctx context.Context) error { return nil }))
+	})
+
+	t.Run("NoClientInContext", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, WithLock(ctx, "key", func(ctx context.Context) error { return nil }), errClientNotInContext)
+	})
+}
+
+func TestWithLockKey(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, withLockKey(0, "key"), withLockKey(0, "key"))
+	require.NotEqual(t, withLockKey(0, "key"), withLockKey(0, "other_key"))
+	require.NotEqual(t, withLockKey(0, "key"), withLockKey(123, "key"))
+
+	// A configured prefix occupies the upper 32 bits.
+	require.Equal(t, int64(123), withLockKey(123, "key")>>32)
+}
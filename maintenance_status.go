@@ -0,0 +1,107 @@
+package river
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
+)
+
+// MaintenanceServiceStatus is the most recent run of a maintenance service as
+// returned by Client.MaintenanceStatus.
+type MaintenanceServiceStatus struct {
+	// ClientID is the ID of the client that ran the service, which was leader
+	// at the time.
+	ClientID string
+
+	// ConsecutiveFailures is the number of runs in a row that have failed. It's
+	// reset to zero by a successful run.
+	ConsecutiveFailures int
+
+	// Error is the error the most recent run failed with. Empty if it
+	// succeeded.
+	Error string
+
+	// FinishedAt is when the most recent run finished.
+	FinishedAt time.Time
+
+	// LastSucceededAt is when the service most recently finished a successful
+	// run. Nil if it's never had one.
+	LastSucceededAt *time.Time
+
+	// Name is the name of the service, like "JobCleaner".
+	Name string
+
+	// NumRows is the number of rows affected by the most recent run, like the
+	// number of jobs deleted by the job cleaner or scheduled by the job
+	// scheduler. A service working through a backlog will show a high number
+	// here run after run.
+	NumRows int
+
+	// StartedAt is when the most recent run started.
+	StartedAt time.Time
+}
+
+// MaintenanceStatus returns the most recent run of each maintenance service,
+// ordered by name. Maintenance services like the job cleaner, job rescuer, job
+// scheduler, and reindexer run on the elected leader and record each run to
+// the database, so the status is the same regardless of which client it's
+// requested from. It can be used to verify that maintenance is keeping up, like
+// that the job cleaner is running successfully and isn't persistently deleting
+// full batches.
+//
+// Services that haven't yet completed a run aren't included.
+func (c *Client[TTx]) MaintenanceStatus(ctx context.Context) ([]*MaintenanceServiceStatus, error) {
+	if !c.driver.PoolIsSet() {
+		return nil, errNoDriverDBPool
+	}
+
+	statuses, err := c.driver.GetExecutor().MaintenanceStatusList(ctx, &riverdriver.MaintenanceStatusListParams{
+		Schema: c.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sliceutil.Map(statuses, func(status *riverdriver.MaintenanceStatus) *MaintenanceServiceStatus {
+		return &MaintenanceServiceStatus{
+			ClientID:            status.ClientID,
+			ConsecutiveFailures: status.ConsecutiveFailures,
+			Error:               ptrutil.ValOrDefault(status.Error, ""),
+			FinishedAt:          status.FinishedAt,
+			LastSucceededAt:     status.LastSucceededAt,
+			Name:                status.Name,
+			NumRows:             status.NumRows,
+			StartedAt:           status.StartedAt,
+		}
+	}), nil
+}
+
+// Records a run of a maintenance service to the database. A failure to record
+// is logged, but otherwise ignored so that it doesn't interfere with the
+// service.
+func (c *Client[TTx]) maintenanceRunRecord(ctx context.Context, serviceName string, run *riversharedmaintenance.Run) {
+	var errStr *string
+	if run.Err != nil {
+		errStr = ptrutil.Ptr(run.Err.Error())
+	}
+
+	if _, err := c.driver.GetExecutor().MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+		ClientID:   c.config.ID,
+		Error:      errStr,
+		FinishedAt: run.FinishedAt,
+		Name:       serviceName,
+		NumRows:    run.NumRows,
+		Schema:     c.config.Schema,
+		StartedAt:  run.StartedAt,
+	}); err != nil {
+		c.baseService.Logger.WarnContext(ctx, c.baseService.Name+": Error recording maintenance run",
+			slog.String("err", err.Error()),
+			slog.String("service", serviceName),
+		)
+	}
+}
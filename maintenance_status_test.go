@@ -0,0 +1,127 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedmaintenance"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+)
+
+func Test_Client_MaintenanceStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+		)
+
+		return client, &testBundle{
+			exec:   client.driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		statuses, err := client.MaintenanceStatus(ctx)
+		require.NoError(t, err)
+		require.Empty(t, statuses)
+	})
+
+	t.Run("RecordedRuns", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		var (
+			now       = time.Now().UTC()
+			startedAt = now.Add(-time.Second)
+		)
+
+		client.maintenanceRunRecord(ctx, "JobCleaner", &riversharedmaintenance.Run{FinishedAt: now, NumRows: 10, StartedAt: startedAt})
+		client.maintenanceRunRecord(ctx, "JobCleaner", &riversharedmaintenance.Run{Err: errors.New("error cleaning jobs"), FinishedAt: now.Add(time.Minute), StartedAt: startedAt.Add(time.Minute)})
+		client.maintenanceRunRecord(ctx, "JobScheduler", &riversharedmaintenance.Run{FinishedAt: now, NumRows: 3, StartedAt: startedAt})
+
+		statuses, err := client.MaintenanceStatus(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+
+		require.Equal(t, client.ID(), statuses[0].ClientID)
+		require.Equal(t, 1, statuses[0].ConsecutiveFailures)
+		require.Equal(t, "error cleaning jobs", statuses[0].Error)
+		require.WithinDuration(t, now.Add(time.Minute), statuses[0].FinishedAt, time.Millisecond)
+		require.NotNil(t, statuses[0].LastSucceededAt)
+		require.WithinDuration(t, now, *statuses[0].LastSucceededAt, time.Millisecond)
+		require.Equal(t, "JobCleaner", statuses[0].Name)
+		require.Zero(t, statuses[0].NumRows)
+		require.WithinDuration(t, startedAt.Add(time.Minute), statuses[0].StartedAt, time.Millisecond)
+
+		require.Zero(t, statuses[1].ConsecutiveFailures)
+		require.Empty(t, statuses[1].Error)
+		require.Equal(t, "JobScheduler", statuses[1].Name)
+		require.Equal(t, 3, statuses[1].NumRows)
+	})
+
+	t.Run("RecordedByLeader", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		client.testSignals.Init(t)
+
+		startClient(ctx, t, client)
+
+		client.queueMaintainerLeader.TestSignals.ElectedLeader.WaitOrTimeout()
+
+		require.Eventually(t, func() bool {
+			statuses, err := client.MaintenanceStatus(ctx)
+			require.NoError(t, err)
+			return len(statuses) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		statuses, err := bundle.exec.MaintenanceStatusList(ctx, &riverdriver.MaintenanceStatusListParams{
+			Schema: bundle.schema,
+		})
+		require.NoError(t, err)
+		for _, status := range statuses {
+			require.Equal(t, client.ID(), status.ClientID)
+			require.NotEmpty(t, status.Name)
+			require.Nil(t, status.Error)
+			require.Equal(t, ptrutil.Ptr(status.FinishedAt), status.LastSucceededAt)
+		}
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{})
+		require.NoError(t, err)
+
+		_, err = client.MaintenanceStatus(ctx)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+}
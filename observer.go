@@ -0,0 +1,101 @@
+package river
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/rivernotify"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// observer runs in place of producers and a completer on a read-only client.
+// It listens for control notifications and distributes queue pause, resume, and
+// metadata change events to subscriptions, but never touches the database
+// itself.
+type observer struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	notifier           *notifier.Notifier // may be nil in poll-only mode
+	queueEventCallback func(event *Event)
+	subscribeCh        chan<- []jobcompleter.CompleterJobUpdated
+}
+
+func newObserver(archetype *baseservice.Archetype, notifier *notifier.Notifier, queueEventCallback func(event *Event)) *observer {
+	return baseservice.Init(archetype, &observer{
+		notifier:           notifier,
+		queueEventCallback: queueEventCallback,
+	})
+}
+
+// ResetSubscribeChan sets the channel that the observer closes when it stops,
+// which lets the subscription manager know that there'll be no more job
+// updates. An observer never sends job updates, but the channel stands in for
+// the one a completer would otherwise close. It must only be called when the
+// observer is stopped.
+func (o *observer) ResetSubscribeChan(subscribeCh chan<- []jobcompleter.CompleterJobUpdated) {
+	o.subscribeCh = subscribeCh
+}
+
+func (o *observer) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := o.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	var controlSub *notifier.Subscription
+	if o.notifier != nil {
+		var err error
+		controlSub, err = o.notifier.Listen(ctx, notifier.NotificationTopicControl, o.handleControlNotification(ctx))
+		if err != nil {
+			stopped()
+			return err
+		}
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		o.Logger.DebugContext(ctx, o.Name+": Run loop started")
+		defer o.Logger.DebugContext(ctx, o.Name+": Run loop stopped")
+
+		if o.subscribeCh != nil {
+			defer close(o.subscribeCh)
+		}
+
+		if controlSub != nil {
+			defer controlSub.Unlisten(ctx)
+		}
+
+		<-ctx.Done()
+	}()
+
+	return nil
+}
+
+func (o *observer) handleControlNotification(ctx context.Context) func(notifier.NotificationTopic, string) {
+	return func(topic notifier.NotificationTopic, payload string) {
+		decoded, err := rivernotify.DecodeControl(payload)
+		if err != nil {
+			o.Logger.ErrorContext(ctx, o.Name+": Failed to decode control notification payload", slog.String("err", err.Error()))
+			return
+		}
+
+		switch decoded.Action {
+		case rivernotify.ControlActionMetadataChanged:
+			o.queueEventCallback(&Event{Kind: EventKindQueueMetadataChanged, Queue: &rivertype.Queue{Metadata: decoded.Metadata, Name: decoded.Queue}})
+		case rivernotify.ControlActionPause:
+			o.queueEventCallback(&Event{Kind: EventKindQueuePaused, Queue: &rivertype.Queue{Name: decoded.Queue}})
+		case rivernotify.ControlActionResume:
+			o.queueEventCallback(&Event{Kind: EventKindQueueResumed, Queue: &rivertype.Queue{Name: decoded.Queue}})
+		default:
+			// Other control actions like job cancellation are only relevant
+			// to clients working jobs.
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package river
+
+import (
+	"fmt"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const partitionKeyMaxLength = 255
+
+// setPartitionKey records the given partition key in job metadata, where it's
+// picked up by the fetch query to serialize work among jobs sharing the key.
+func setPartitionKey(metadata []byte, partitionKey string) ([]byte, error) {
+	if len(partitionKey) > partitionKeyMaxLength {
+		return nil, fmt.Errorf("partition key should be a maximum of %d characters long", partitionKeyMaxLength)
+	}
+
+	metadata, err := sjson.SetBytes(metadata, rivertype.MetadataKeyPartitionKey, partitionKey)
+	if err != nil {
+		return nil, fmt.Errorf("error setting partition key in metadata: %w", err)
+	}
+
+	return metadata, nil
+}
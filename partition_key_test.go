@@ -0,0 +1,135 @@
+package river
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type partitionKeyArgs struct {
+	CustomerID string `json:"customer_id"`
+}
+
+func (partitionKeyArgs) Kind() string { return "partition_key" }
+
+func (a partitionKeyArgs) InsertOpts() InsertOpts {
+	return InsertOpts{PartitionKey: "customer_" + a.CustomerID}
+}
+
+func Test_Client_PartitionKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		config *Config
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			config = newTestConfig(t, schema)
+		)
+
+		return newTestClient(t, dbPool, config), &testBundle{config: config, dbPool: dbPool}
+	}
+
+	t.Run("InsertOptsPartitionKey", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`), PartitionKey: "customer_123"})
+		require.NoError(t, err)
+		require.Equal(t, "customer_123", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyPartitionKey).String())
+		require.Equal(t, "bar", gjson.GetBytes(insertRes.Job.Metadata, "foo").String())
+	})
+
+	t.Run("JobArgsPartitionKey", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, partitionKeyArgs{CustomerID: "123"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "customer_123", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyPartitionKey).String())
+
+		// A partition key from InsertOpts takes precedence.
+		insertRes, err = client.Insert(ctx, partitionKeyArgs{CustomerID: "123"}, &InsertOpts{PartitionKey: "override"})
+		require.NoError(t, err)
+		require.Equal(t, "override", gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyPartitionKey).String())
+	})
+
+	t.Run("NoPartitionKey", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(insertRes.Job.Metadata, rivertype.MetadataKeyPartitionKey).Exists())
+	})
+
+	t.Run("PartitionKeyTooLongError", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{PartitionKey: strings.Repeat("x", 256)})
+		require.EqualError(t, err, "partition key should be a maximum of 255 characters long")
+	})
+
+	t.Run("WorkedOneAtATimeInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		var numActive atomic.Int32
+		jobWorkedChan := make(chan int64, 10)
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[partitionKeyArgs]) error {
+			if numActive.Add(1) > 1 {
+				t.Errorf("job %d started while another job in its partition was running", job.ID)
+			}
+			defer numActive.Add(-1)
+
+			time.Sleep(10 * time.Millisecond)
+			jobWorkedChan <- job.ID
+			return nil
+		}))
+
+		client := newTestClient(t, bundle.dbPool, bundle.config)
+
+		var insertParams []InsertManyParams
+		for range 5 {
+			insertParams = append(insertParams, InsertManyParams{Args: partitionKeyArgs{CustomerID: "123"}})
+		}
+
+		insertResults, err := client.InsertMany(ctx, insertParams)
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		jobIDs := riversharedtest.WaitOrTimeoutN(t, jobWorkedChan, len(insertResults))
+		for i, insertRes := range insertResults {
+			require.Equal(t, insertRes.Job.ID, jobIDs[i])
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// PayloadField identifies a job payload that's subject to size limits.
+type PayloadField string
+
+const (
+	// PayloadFieldArgs is a job's encoded args.
+	PayloadFieldArgs PayloadField = "args"
+
+	// PayloadFieldMetadata is a job's metadata.
+	PayloadFieldMetadata PayloadField = "metadata"
+)
+
+// PayloadSizeLimitAction determines what happens when a payload being inserted
+// exceeds its configured maximum size. See PayloadSizeLimits.
+type PayloadSizeLimitAction string
+
+const (
+	// PayloadSizeLimitActionOffload hands the oversized payload to
+	// PayloadSizeLimits.OffloadFunc, which should store it externally and
+	// return a smaller replacement (like a reference to where it was stored)
+	// that's inserted instead. Workers are responsible for resolving offloaded
+	// payloads.
+	PayloadSizeLimitActionOffload PayloadSizeLimitAction = "offload"
+
+	// PayloadSizeLimitActionReject returns a PayloadTooLargeError from the
+	// insert. This is the default.
+	PayloadSizeLimitActionReject PayloadSizeLimitAction = "reject"
+
+	// PayloadSizeLimitActionTruncate removes the largest top-level keys from
+	// the payload until it fits, and lists the removed keys in the job's
+	// metadata under rivertype.MetadataKeyTruncatedKeys. Keys reserved by River
+	// (those prefixed with `river:` along with a few older unprefixed ones like
+	// `snoozes`) are never removed because features depend on them, so if the
+	// payload can't be made to fit without them, the insert is rejected with a
+	// PayloadTooLargeError. Only valid for metadata because truncated args
+	// couldn't be worked.
+	PayloadSizeLimitActionTruncate PayloadSizeLimitAction = "truncate"
+)
+
+// PayloadSizeLimits configures maximum sizes for job payloads, enforced when
+// jobs are inserted. It protects the jobs table from being bloated by a single
+// misbehaving producer inserting huge rows.
+//
+// Limits are checked after InsertBegin hooks and job insert middleware have
+// run, so they apply to payloads as they'll be stored.
+type PayloadSizeLimits struct {
+	// ArgsAction is the action taken when encoded args exceed ArgsMaxBytes.
+	// May be PayloadSizeLimitActionReject or PayloadSizeLimitActionOffload.
+	//
+	// Defaults to PayloadSizeLimitActionReject.
+	ArgsAction PayloadSizeLimitAction
+
+	// ArgsMaxBytes is the maximum size of a job's encoded args in bytes.
+	//
+	// Defaults to zero, which applies no limit.
+	ArgsMaxBytes int
+
+	// MetadataAction is the action taken when metadata exceeds
+	// MetadataMaxBytes.
+	//
+	// Defaults to PayloadSizeLimitActionReject.
+	MetadataAction PayloadSizeLimitAction
+
+	// MetadataMaxBytes is the maximum size of a job's metadata in bytes.
+	//
+	// Defaults to zero, which applies no limit.
+	MetadataMaxBytes int
+
+	// OffloadFunc stores an oversized payload externally and returns a
+	// replacement JSON payload to be inserted in its place. The replacement
+	// must itself be within the configured maximum size. Required if either
+	// action is PayloadSizeLimitActionOffload.
+	OffloadFunc func(ctx context.Context, params *PayloadOffloadParams) ([]byte, error)
+}
+
+// PayloadOffloadParams are parameters for PayloadSizeLimits.OffloadFunc.
+type PayloadOffloadParams struct {
+	// Field is the payload being offloaded.
+	Field PayloadField
+
+	// Kind is the kind of the job being inserted.
+	Kind string
+
+	// Payload is the oversized JSON payload.
+	Payload []byte
+}
+
+// PayloadTooLargeError is returned on insert when a job's payload exceeds its
+// maximum size as configured by Config.PayloadSizeLimits.
+type PayloadTooLargeError struct {
+	// Field is the payload that was too large.
+	Field PayloadField
+
+	// Kind is the kind of the job being inserted.
+	Kind string
+
+	// MaxBytes is the configured maximum size of the payload.
+	MaxBytes int
+
+	// SizeBytes is the size of the payload.
+	SizeBytes int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("job %s of kind %q is %d bytes, larger than the maximum of %d bytes", e.Field, e.Kind, e.SizeBytes, e.MaxBytes)
+}
+
+// Is implements compatibility with errors.Is so that any PayloadTooLargeError
+// matches another regardless of its properties.
+func (e *PayloadTooLargeError) Is(target error) bool {
+	_, ok := target.(*PayloadTooLargeError)
+	return ok
+}
+
+func (l *PayloadSizeLimits) validate() error {
+	if l.ArgsMaxBytes < 0 {
+		return errors.New("PayloadSizeLimits.ArgsMaxBytes cannot be less than zero")
+	}
+	if l.MetadataMaxBytes < 0 {
+		return errors.New("PayloadSizeLimits.MetadataMaxBytes cannot be less than zero")
+	}
+
+	switch l.ArgsAction {
+	case "", PayloadSizeLimitActionOffload, PayloadSizeLimitActionReject:
+	case PayloadSizeLimitActionTruncate:
+		return errors.New("PayloadSizeLimits.ArgsAction cannot be PayloadSizeLimitActionTruncate")
+	default:
+		return fmt.Errorf("PayloadSizeLimits.ArgsAction %q is not valid", l.ArgsAction)
+	}
+
+	switch l.MetadataAction {
+	case "", PayloadSizeLimitActionOffload, PayloadSizeLimitActionReject, PayloadSizeLimitActionTruncate:
+	default:
+		return fmt.Errorf("PayloadSizeLimits.MetadataAction %q is not valid", l.MetadataAction)
+	}
+
+	if (l.ArgsAction == PayloadSizeLimitActionOffload || l.MetadataAction == PayloadSizeLimitActionOffload) && l.OffloadFunc == nil {
+		return errors.New("PayloadSizeLimits.OffloadFunc must be set if an action is PayloadSizeLimitActionOffload")
+	}
+
+	return nil
+}
+
+// enforce checks the payloads of the given insert params against configured
+// limits, taking the configured action for any that are too large. Params are
+// modified in place.
+func (l *PayloadSizeLimits) enforce(ctx context.Context, params *rivertype.JobInsertParams, stats *payloadSizeStats) error {
+	if l.ArgsMaxBytes > 0 && len(params.EncodedArgs) > l.ArgsMaxBytes {
+		encodedArgs, err := l.enforceField(ctx, params, PayloadFieldArgs, params.EncodedArgs, l.ArgsMaxBytes, l.ArgsAction, stats)
+		if err != nil {
+			return err
+		}
+		params.EncodedArgs = encodedArgs
+	}
+
+	if l.MetadataMaxBytes > 0 && len(params.Metadata) > l.MetadataMaxBytes {
+		metadata, err := l.enforceField(ctx, params, PayloadFieldMetadata, params.Metadata, l.MetadataMaxBytes, l.MetadataAction, stats)
+		if err != nil {
+			return err
+		}
+		params.Metadata = metadata
+	}
+
+	return nil
+}
+
+func (l *PayloadSizeLimits) enforceField(ctx context.Context, params *rivertype.JobInsertParams, field PayloadField, payload []byte, maxBytes int, action PayloadSizeLimitAction, stats *payloadSizeStats) ([]byte, error) {
+	tooLargeErr := &PayloadTooLargeError{Field: field, Kind: params.Kind, MaxBytes: maxBytes, SizeBytes: len(payload)}
+
+	switch cmp.Or(action, PayloadSizeLimitActionReject) {
+	case PayloadSizeLimitActionOffload:
+		replacement, err := l.OffloadFunc(ctx, &PayloadOffloadParams{Field: field, Kind: params.Kind, Payload: payload})
+		if err != nil {
+			return nil, fmt.Errorf("error offloading job %s: %w", field, err)
+		}
+		if len(replacement) > maxBytes {
+			return nil, fmt.Errorf("offloaded job %s replacement is still too large: %w", field,
+				&PayloadTooLargeError{Field: field, Kind: params.Kind, MaxBytes: maxBytes, SizeBytes: len(replacement)})
+		}
+		stats.numOffloaded.Add(1)
+		return replacement, nil
+
+	case PayloadSizeLimitActionTruncate:
+		truncated, ok := truncateMetadata(payload, maxBytes)
+		if !ok {
+			stats.numRejected.Add(1)
+			return nil, tooLargeErr
+		}
+		stats.numTruncated.Add(1)
+		return truncated, nil
+
+	case PayloadSizeLimitActionReject:
+	}
+
+	stats.numRejected.Add(1)
+	return nil, tooLargeErr
+}
+
+// Metadata keys used by River that predate the `river:` prefix convention.
+var metadataKeysReservedUnprefixed = []string{ //nolint:gochecknoglobals
+	"cancel_attempted_at",
+	"cancel_reason",
+	"output",
+	"scheduler_discarded",
+	"snoozes",
+	"unique_key_conflict",
+}
+
+// metadataKeyReserved returns true if the given metadata key is one that River
+// uses for its own bookkeeping.
+func metadataKeyReserved(key string) bool {
+	return strings.HasPrefix(key, "river:") || slices.Contains(metadataKeysReservedUnprefixed, key)
+}
+
+// truncateMetadata removes the largest top-level keys from metadata until it
+// fits in maxBytes, recording removed keys under
+// rivertype.MetadataKeyTruncatedKeys. Keys reserved by River are never removed.
+// Returns false if the metadata couldn't be made to fit.
+func truncateMetadata(metadata []byte, maxBytes int) ([]byte, bool) {
+	var metadataMap map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+		return nil, false
+	}
+	delete(metadataMap, rivertype.MetadataKeyTruncatedKeys)
+
+	keys := make([]string, 0, len(metadataMap))
+	for key := range metadataMap {
+		if !metadataKeyReserved(key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(len(metadataMap[b]), len(metadataMap[a])), // largest first
+			cmp.Compare(a, b),
+		)
+	})
+
+	truncatedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		delete(metadataMap, key)
+		truncatedKeys = append(truncatedKeys, key)
+
+		truncatedKeysJSON, err := json.Marshal(truncatedKeys)
+		if err != nil {
+			return nil, false
+		}
+		metadataMap[rivertype.MetadataKeyTruncatedKeys] = truncatedKeysJSON
+
+		truncated, err := json.Marshal(metadataMap)
+		if err != nil {
+			return nil, false
+		}
+		if len(truncated) <= maxBytes {
+			return truncated, true
+		}
+	}
+
+	return nil, false
+}
+
+// payloadSizeBucketBounds are the inclusive upper bounds of the buckets in
+// which inserted payload sizes are counted. A final unbounded bucket counts
+// payloads larger than the last bound.
+var payloadSizeBucketBounds = []int{ //nolint:gochecknoglobals
+	1 << 10,   // 1 KB
+	16 << 10,  // 16 KB
+	256 << 10, // 256 KB
+	1 << 20,   // 1 MB
+	4 << 20,   // 4 MB
+	16 << 20,  // 16 MB
+}
+
+// payloadSizeStats tracks the distribution of inserted payload sizes along
+// with the number of times limits were enforced.
+type payloadSizeStats struct {
+	args         [7]atomic.Int64 // len(payloadSizeBucketBounds) + 1
+	metadata     [7]atomic.Int64 // len(payloadSizeBucketBounds) + 1
+	numOffloaded atomic.Int64
+	numRejected  atomic.Int64
+	numTruncated atomic.Int64
+}
+
+func (s *payloadSizeStats) record(params *rivertype.JobInsertParams) {
+	s.args[payloadSizeBucketIndex(len(params.EncodedArgs))].Add(1)
+	s.metadata[payloadSizeBucketIndex(len(params.Metadata))].Add(1)
+}
+
+func (s *payloadSizeStats) toHealthStatus() *HealthStatusPayloadSizes {
+	buckets := func(counts *[7]atomic.Int64) []HealthStatusPayloadSizeBucket {
+		buckets := make([]HealthStatusPayloadSizeBucket, len(counts))
+		for i := range counts {
+			buckets[i].Count = counts[i].Load()
+			if i < len(payloadSizeBucketBounds) {
+				buckets[i].UpToBytes = payloadSizeBucketBounds[i]
+			}
+		}
+		return buckets
+	}
+
+	return &HealthStatusPayloadSizes{
+		Args:         buckets(&s.args),
+		Metadata:     buckets(&s.metadata),
+		NumOffloaded: s.numOffloaded.Load(),
+		NumRejected:  s.numRejected.Load(),
+		NumTruncated: s.numTruncated.Load(),
+	}
+}
+
+func payloadSizeBucketIndex(size int) int {
+	for i, bound := range payloadSizeBucketBounds {
+		if size <= bound {
+			return i
+		}
+	}
+	return len(payloadSizeBucketBounds)
+}
@@ -0,0 +1,242 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestPayloadSizeLimits_validate(t *testing.T) {
+	t.Parallel()
+
+	offloadFunc := func(ctx context.Context, params *PayloadOffloadParams) ([]byte, error) { return nil, nil }
+
+	require.NoError(t, (&PayloadSizeLimits{}).validate())
+	require.NoError(t, (&PayloadSizeLimits{ArgsAction: PayloadSizeLimitActionOffload, OffloadFunc: offloadFunc}).validate())
+	require.NoError(t, (&PayloadSizeLimits{MetadataAction: PayloadSizeLimitActionTruncate}).validate())
+
+	require.EqualError(t, (&PayloadSizeLimits{ArgsMaxBytes: -1}).validate(), "PayloadSizeLimits.ArgsMaxBytes cannot be less than zero")
+	require.EqualError(t, (&PayloadSizeLimits{MetadataMaxBytes: -1}).validate(), "PayloadSizeLimits.MetadataMaxBytes cannot be less than zero")
+	require.EqualError(t, (&PayloadSizeLimits{ArgsAction: PayloadSizeLimitActionTruncate}).validate(), "PayloadSizeLimits.ArgsAction cannot be PayloadSizeLimitActionTruncate")
+	require.EqualError(t, (&PayloadSizeLimits{ArgsAction: "invalid"}).validate(), `PayloadSizeLimits.ArgsAction "invalid" is not valid`)
+	require.EqualError(t, (&PayloadSizeLimits{MetadataAction: "invalid"}).validate(), `PayloadSizeLimits.MetadataAction "invalid" is not valid`)
+	require.EqualError(t, (&PayloadSizeLimits{MetadataAction: PayloadSizeLimitActionOffload}).validate(), "PayloadSizeLimits.OffloadFunc must be set if an action is PayloadSizeLimitActionOffload")
+}
+
+func TestPayloadSizeLimits_enforce(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		stats *payloadSizeStats
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		return &testBundle{stats: &payloadSizeStats{}}
+	}
+
+	largeArgs := []byte(`{"data":"` + strings.Repeat("x", 100) + `"}`)
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		params := &rivertype.JobInsertParams{EncodedArgs: []byte(`{}`), Kind: "kind", Metadata: []byte(`{}`)}
+		require.NoError(t, (&PayloadSizeLimits{ArgsMaxBytes: 10, MetadataMaxBytes: 10}).enforce(ctx, params, bundle.stats))
+		require.Equal(t, []byte(`{}`), params.EncodedArgs)
+		require.Zero(t, bundle.stats.numRejected.Load())
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		params := &rivertype.JobInsertParams{EncodedArgs: largeArgs, Kind: "kind", Metadata: []byte(`{}`)}
+		err := (&PayloadSizeLimits{ArgsMaxBytes: 10}).enforce(ctx, params, bundle.stats)
+		require.ErrorIs(t, err, &PayloadTooLargeError{})
+		require.Equal(t, &PayloadTooLargeError{Field: PayloadFieldArgs, Kind: "kind", MaxBytes: 10, SizeBytes: len(largeArgs)}, err)
+		require.EqualError(t, err, `job args of kind "kind" is 111 bytes, larger than the maximum of 10 bytes`)
+		require.Equal(t, int64(1), bundle.stats.numRejected.Load())
+	})
+
+	t.Run("Offload", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		var offloadParams *PayloadOffloadParams
+		limits := &PayloadSizeLimits{
+			ArgsAction:   PayloadSizeLimitActionOffload,
+			ArgsMaxBytes: 20,
+			OffloadFunc: func(ctx context.Context, params *PayloadOffloadParams) ([]byte, error) {
+				offloadParams = params
+				return []byte(`{"ref":"abc"}`), nil
+			},
+		}
+
+		params := &rivertype.JobInsertParams{EncodedArgs: largeArgs, Kind: "kind", Metadata: []byte(`{}`)}
+		require.NoError(t, limits.enforce(ctx, params, bundle.stats))
+		require.Equal(t, []byte(`{"ref":"abc"}`), params.EncodedArgs)
+		require.Equal(t, &PayloadOffloadParams{Field: PayloadFieldArgs, Kind: "kind", Payload: largeArgs}, offloadParams)
+		require.Equal(t, int64(1), bundle.stats.numOffloaded.Load())
+	})
+
+	t.Run("OffloadError", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		limits := &PayloadSizeLimits{
+			ArgsAction:   PayloadSizeLimitActionOffload,
+			ArgsMaxBytes: 20,
+			OffloadFunc: func(ctx context.Context, params *PayloadOffloadParams) ([]byte, error) {
+				return nil, errors.New("storage unavailable")
+			},
+		}
+
+		params := &rivertype.JobInsertParams{EncodedArgs: largeArgs, Kind: "kind", Metadata: []byte(`{}`)}
+		require.EqualError(t, limits.enforce(ctx, params, bundle.stats), "error offloading job args: storage unavailable")
+	})
+
+	t.Run("OffloadReplacementTooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		limits := &PayloadSizeLimits{
+			ArgsAction:   PayloadSizeLimitActionOffload,
+			ArgsMaxBytes: 20,
+			OffloadFunc: func(ctx context.Context, params *PayloadOffloadParams) ([]byte, error) {
+				return params.Payload, nil
+			},
+		}
+
+		params := &rivertype.JobInsertParams{EncodedArgs: largeArgs, Kind: "kind", Metadata: []byte(`{}`)}
+		require.ErrorIs(t, limits.enforce(ctx, params, bundle.stats), &PayloadTooLargeError{})
+	})
+
+	t.Run("TruncateMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		params := &rivertype.JobInsertParams{
+			EncodedArgs: []byte(`{}`),
+			Kind:        "kind",
+			Metadata:    []byte(`{"large":"` + strings.Repeat("x", 100) + `","small":"x"}`),
+		}
+		require.NoError(t, (&PayloadSizeLimits{MetadataAction: PayloadSizeLimitActionTruncate, MetadataMaxBytes: 60}).enforce(ctx, params, bundle.stats))
+		require.JSONEq(t, `{"river:truncated_keys":["large"],"small":"x"}`, string(params.Metadata))
+		require.Equal(t, int64(1), bundle.stats.numTruncated.Load())
+	})
+}
+
+func TestTruncateMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RemovesLargestKeysFirst", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := []byte(`{"a":"` + strings.Repeat("x", 50) + `","b":"` + strings.Repeat("x", 100) + `","c":"x"}`)
+
+		truncated, ok := truncateMetadata(metadata, 100)
+		require.True(t, ok)
+		require.JSONEq(t, `{"a":"`+strings.Repeat("x", 50)+`","c":"x","river:truncated_keys":["b"]}`, string(truncated))
+	})
+
+	t.Run("NeverRemovesReservedKeys", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := []byte(`{"river:internal":"` + strings.Repeat("x", 100) + `","snoozes":3,"user":"` + strings.Repeat("x", 50) + `"}`)
+
+		truncated, ok := truncateMetadata(metadata, 180)
+		require.True(t, ok)
+
+		var metadataMap map[string]any
+		require.NoError(t, json.Unmarshal(truncated, &metadataMap))
+		require.Contains(t, metadataMap, "river:internal")
+		require.Contains(t, metadataMap, "snoozes")
+		require.NotContains(t, metadataMap, "user")
+	})
+
+	t.Run("CannotFitWithoutReservedKeys", func(t *testing.T) {
+		t.Parallel()
+
+		metadata := []byte(`{"river:lease_expires_at":"` + strings.Repeat("x", 100) + `","user":"x"}`)
+
+		_, ok := truncateMetadata(metadata, 100)
+		require.False(t, ok)
+	})
+
+	t.Run("CannotFit", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := truncateMetadata([]byte(`{"a":"b"}`), 5)
+		require.False(t, ok)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := truncateMetadata([]byte(`not json`), 5)
+		require.False(t, ok)
+	})
+}
+
+func TestPayloadSizeStats(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, payloadSizeBucketIndex(0))
+	require.Equal(t, 0, payloadSizeBucketIndex(1<<10))
+	require.Equal(t, 1, payloadSizeBucketIndex(1<<10+1))
+	require.Equal(t, len(payloadSizeBucketBounds), payloadSizeBucketIndex(100<<20))
+
+	stats := &payloadSizeStats{}
+	stats.record(&rivertype.JobInsertParams{EncodedArgs: make([]byte, 2<<10), Metadata: []byte(`{}`)})
+	stats.numRejected.Add(1)
+
+	healthStatus := stats.toHealthStatus()
+	require.Len(t, healthStatus.Args, len(payloadSizeBucketBounds)+1)
+	require.Equal(t, HealthStatusPayloadSizeBucket{Count: 1, UpToBytes: 16 << 10}, healthStatus.Args[1])
+	require.Equal(t, HealthStatusPayloadSizeBucket{Count: 1, UpToBytes: 1 << 10}, healthStatus.Metadata[0])
+	require.Equal(t, HealthStatusPayloadSizeBucket{Count: 0, UpToBytes: 0}, healthStatus.Metadata[len(payloadSizeBucketBounds)])
+	require.Equal(t, int64(1), healthStatus.NumRejected)
+}
+
+func Test_Client_PayloadSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		dbPool = riversharedtest.DBPool(ctx, t)
+		driver = riverpgxv5.New(dbPool)
+		schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		config = newTestConfig(t, schema)
+	)
+	config.PayloadSizeLimits = &PayloadSizeLimits{ArgsMaxBytes: 2}
+
+	client := newTestClient(t, dbPool, config)
+
+	_, err := client.Insert(ctx, noOpArgs{Name: strings.Repeat("x", 10)}, nil)
+	require.ErrorIs(t, err, &PayloadTooLargeError{})
+
+	payloadSizes := client.Liveness(ctx).InsertPayloadSizes
+	require.NotNil(t, payloadSizes)
+	require.Equal(t, int64(1), payloadSizes.Args[0].Count)
+	require.Equal(t, int64(1), payloadSizes.NumRejected)
+}
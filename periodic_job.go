@@ -1,6 +1,7 @@
 package river
 
 import (
+	"context"
 	"time"
 
 	"github.com/riverqueue/river/internal/maintenance"
@@ -256,7 +257,15 @@ func (m *periodicJobInternalMapper) toInternal(periodicJob *PeriodicJob) *mainte
 			if args == nil {
 				return nil, maintenance.ErrNoJobToInsert
 			}
-			return insertParamsFromConfigArgsAndOptions(m.archetype, m.config, args, options)
+
+			// Periodic job constructors aren't invoked with a context, so
+			// middleware gets a background one.
+			params, err := m.config.runInsertParamsMiddleware(context.Background(), InsertManyParams{Args: args, InsertOpts: options})
+			if err != nil {
+				return nil, err
+			}
+
+			return insertParamsFromConfigArgsAndOptions(m.archetype, m.config, params.Args, params.InsertOpts)
 		},
 		RunOnStart:   opts.RunOnStart,
 		ScheduleFunc: periodicJob.scheduleFunc.Next,
@@ -0,0 +1,81 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const poisonPillMaxCrashesDefault = 3
+
+// PoisonPillConfig configures detection of poison pill jobs, which crash the
+// process working them every time they're worked, like a malformed payload
+// that makes its worker panic or run out of memory. Left unchecked, a poison
+// pill is retried until it runs out of attempts, crashing a process on every
+// attempt, and taking every other job that process was working down with it.
+//
+// A job has crashed if it panicked, or if it was rescued or its lease expired
+// because the process working it died before it could be completed. A job
+// that's crashed MaxCrashes times in a row is quarantined by being discarded
+// immediately instead of being retried, where it can be inspected and retried
+// with Client.JobRetry once its worker is fixed, and an EventKindJobPoisonPill
+// event is emitted. Its kind can optionally be paused as well.
+//
+// Jobs that panic are detected by the client working them. Jobs whose process
+// died are detected when they're rescued by the leader's job rescuer (see
+// Config.RescueStuckJobsAfter), so their events are only emitted by the
+// leader. Lease expiries in queues with a QueueConfig.VisibilityTimeout count
+// as crashes, but a job is only quarantined once it next panics or is rescued.
+type PoisonPillConfig struct {
+	// MaxCrashes is the number of consecutive crashes after which a job is
+	// quarantined as a poison pill.
+	//
+	// Defaults to 3.
+	MaxCrashes int
+
+	// PauseKind additionally pauses the kind of a job quarantined as a poison
+	// pill with Client.KindPause, so that no other jobs of the kind are worked
+	// until it's resumed with Client.KindResume. Useful for kinds where one
+	// poison pill likely means more, like after a bad deploy of its worker.
+	PauseKind bool
+}
+
+func (c *PoisonPillConfig) maxCrashes() int {
+	if c == nil {
+		return 0
+	}
+	return cmp.Or(c.MaxCrashes, poisonPillMaxCrashesDefault)
+}
+
+func (c *PoisonPillConfig) validate() error {
+	if c.MaxCrashes < 0 {
+		return errors.New("PoisonPill.MaxCrashes cannot be less than zero")
+	}
+	return nil
+}
+
+// handlePoisonPill is invoked after a job has been quarantined as a poison
+// pill, pausing its kind if configured and emitting an event.
+func (c *Client[TTx]) handlePoisonPill(ctx context.Context, job *rivertype.JobRow) {
+	if c.config.PoisonPill.PauseKind {
+		if err := c.kindPause(ctx, c.driver.GetExecutor(), job.Kind); err != nil {
+			c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Error pausing kind of poison pill job",
+				slog.String("err", err.Error()),
+				slog.Int64("job_id", job.ID),
+				slog.String("job_kind", job.Kind),
+			)
+		} else {
+			c.baseService.Logger.WarnContext(ctx, c.baseService.Name+": Paused kind of poison pill job",
+				slog.Int64("job_id", job.ID),
+				slog.String("job_kind", job.Kind),
+			)
+		}
+	}
+
+	if c.subscriptionManager != nil {
+		c.subscriptionManager.distributeEventWithContext(ctx, &Event{Kind: EventKindJobPoisonPill, Job: job})
+	}
+}
@@ -0,0 +1,112 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestPoisonPillConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&PoisonPillConfig{}).validate())
+	require.NoError(t, (&PoisonPillConfig{MaxCrashes: 1, PauseKind: true}).validate())
+
+	require.EqualError(t, (&PoisonPillConfig{MaxCrashes: -1}).validate(), "PoisonPill.MaxCrashes cannot be less than zero")
+}
+
+func TestPoisonPillConfig_maxCrashes(t *testing.T) {
+	t.Parallel()
+
+	require.Zero(t, (*PoisonPillConfig)(nil).maxCrashes())
+	require.Equal(t, poisonPillMaxCrashesDefault, (&PoisonPillConfig{}).maxCrashes())
+	require.Equal(t, 5, (&PoisonPillConfig{MaxCrashes: 5}).maxCrashes())
+}
+
+func Test_Client_PoisonPill(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+
+		Panic bool `json:"panic"`
+	}
+
+	t.Run("PanickingJobDiscardedAndKindPaused", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.PoisonPill = &PoisonPillConfig{MaxCrashes: 1, PauseKind: true}
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			if job.Args.Panic {
+				panic("poison pill")
+			}
+			return nil
+		}))
+
+		client := runNewTestClient(ctx, t, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobPoisonPill)
+		t.Cleanup(cancel)
+
+		poisonPillRes, err := client.Insert(ctx, JobArgs{Panic: true}, &InsertOpts{MaxAttempts: 5})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobPoisonPill, event.Kind)
+		require.Equal(t, poisonPillRes.Job.ID, event.Job.ID)
+
+		// Discarded on its first attempt despite having attempts remaining.
+		job, err := client.JobGet(ctx, poisonPillRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+		require.Equal(t, 1, job.Attempt)
+
+		// The kind was paused, so another job of it isn't worked.
+		otherRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		time.Sleep(3 * client.config.FetchPollInterval)
+
+		job, err = client.JobGet(ctx, otherRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		require.NoError(t, client.KindResume(ctx, (JobArgs{}).Kind(), nil))
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, otherRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("PanickingJobRetriedBelowMaxCrashes", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.PoisonPill = &PoisonPillConfig{MaxCrashes: 2}
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			panic("poison pill")
+		}))
+
+		client := runNewTestClient(ctx, t, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobFailed, EventKindJobPoisonPill)
+		t.Cleanup(cancel)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, &InsertOpts{MaxAttempts: 5})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateRetryable, event.Job.State)
+	})
+}
@@ -7,25 +7,33 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/joblease"
 	"github.com/riverqueue/river/internal/middlewarelookup"
 	"github.com/riverqueue/river/internal/notifier"
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/util/chanutil"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riverpilot"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivershared/util/hashutil"
+	"github.com/riverqueue/river/rivershared/util/maputil"
 	"github.com/riverqueue/river/rivershared/util/randutil"
 	"github.com/riverqueue/river/rivershared/util/serviceutil"
 	"github.com/riverqueue/river/rivershared/util/testutil"
@@ -41,16 +49,18 @@ const (
 
 // Test-only properties.
 type producerTestSignals struct {
-	DeletedExpiredQueueRecords testsignal.TestSignal[struct{}]             // notifies when the producer deletes expired queue records
-	JobFetchTriggered          testsignal.TestSignal[struct{}]             // notifies when the producer's fetch limiter is triggered via triggerJobFetch
-	MetadataChanged            testsignal.TestSignal[struct{}]             // notifies when the producer detects a metadata change
-	Paused                     testsignal.TestSignal[struct{}]             // notifies when the producer is paused
-	PolledQueueConfig          testsignal.TestSignal[struct{}]             // notifies when the producer polls for queue settings
-	QueueControlEventTriggered testsignal.TestSignal[*controlEventPayload] // notifies when a queue control event is triggered via triggerQueueControlEvent
-	ReportedProducerStatus     testsignal.TestSignal[struct{}]             // notifies when the producer reports its own status
-	ReportedQueueStatus        testsignal.TestSignal[struct{}]             // notifies when the producer reports queue status
-	Resumed                    testsignal.TestSignal[struct{}]             // notifies when the producer is resumed
-	StartedExecutors           testsignal.TestSignal[struct{}]             // notifies when runOnce finishes a pass
+	DeletedExpiredQueueRecords testsignal.TestSignal[struct{}]                    // notifies when the producer deletes expired queue records
+	JobFetchTriggered          testsignal.TestSignal[struct{}]                    // notifies when the producer's fetch limiter is triggered via triggerJobFetch
+	MetadataChanged            testsignal.TestSignal[struct{}]                    // notifies when the producer detects a metadata change
+	Paused                     testsignal.TestSignal[struct{}]                    // notifies when the producer is paused
+	PolledQueueConfig          testsignal.TestSignal[struct{}]                    // notifies when the producer polls for queue settings
+	QueueControlEventTriggered testsignal.TestSignal[*rivernotify.ControlPayload] // notifies when a queue control event is triggered via triggerQueueControlEvent
+	ReapedExpiredLeases        testsignal.TestSignal[struct{}]                    // notifies when the producer checks for and reaps jobs with expired leases
+	RenewedLeases              testsignal.TestSignal[struct{}]                    // notifies when the producer renews leases on its active jobs
+	ReportedProducerStatus     testsignal.TestSignal[struct{}]                    // notifies when the producer reports its own status
+	ReportedQueueStatus        testsignal.TestSignal[struct{}]                    // notifies when the producer reports queue status
+	Resumed                    testsignal.TestSignal[struct{}]                    // notifies when the producer is resumed
+	StartedExecutors           testsignal.TestSignal[struct{}]                    // notifies when runOnce finishes a pass
 }
 
 func (ts *producerTestSignals) Init(tb testutil.TestingTB) {
@@ -60,6 +70,8 @@ func (ts *producerTestSignals) Init(tb testutil.TestingTB) {
 	ts.Paused.Init(tb)
 	ts.PolledQueueConfig.Init(tb)
 	ts.QueueControlEventTriggered.Init(tb)
+	ts.ReapedExpiredLeases.Init(tb)
+	ts.RenewedLeases.Init(tb)
 	ts.ReportedQueueStatus.Init(tb)
 	ts.ReportedProducerStatus.Init(tb)
 	ts.Resumed.Init(tb)
@@ -67,10 +79,46 @@ func (ts *producerTestSignals) Init(tb testutil.TestingTB) {
 }
 
 type producerConfig struct {
+	// AdaptiveFetchBatchSize sizes fetches according to recent throughput and
+	// the completer's backlog rather than always fetching as many jobs as
+	// there are free slots.
+	AdaptiveFetchBatchSize bool
+
+	// AdvisoryLockPrefix is used to derive the advisory lock that serializes
+	// fetches in the queue across clients when global limits are configured.
+	AdvisoryLockPrefix int32
+
+	// ArgsDecoder decodes job args according to the codec they were encoded
+	// with. Nil only decodes JSON.
+	ArgsDecoder *argscodec.Decoder
+
+	// AttemptInfo is recorded in the metadata of each worked job with
+	// Config.RecordAttemptInfo. It may be nil.
+	AttemptInfo *rivertype.AttemptInfo
+
+	// CircuitOpen reports whether the client's database circuit breaker is
+	// open, in which case fetches are skipped. It may be nil.
+	CircuitOpen func() bool
+
 	ClientID     string
 	Completer    jobcompleter.JobCompleter
 	ErrorHandler ErrorHandler
 
+	// CompleterBacklog returns the number of completions waiting to be sent
+	// to the database and the maximum backlog. It may be nil.
+	CompleterBacklog func() (size, maxSize int)
+
+	// CompleterBackpressureEngaged reports whether fetching should pause
+	// because of a large completer backlog. It may be nil.
+	CompleterBackpressureEngaged func() bool
+
+	// ErrorSizeLimits are maximum sizes for recorded error messages and panic
+	// traces. It may be nil.
+	ErrorSizeLimits *ErrorSizeLimits
+
+	FairnessKey     string
+	FairnessWeights map[string]int
+
 	// FetchCooldown is the minimum amount of time to wait between fetches of new
 	// jobs. Jobs will only be fetched *at most* this often, but if no new jobs
 	// are coming in via LISTEN/NOTIFY then fetches may be delayed as long as
@@ -82,15 +130,26 @@ type producerConfig struct {
 	// LISTEN/NOTIFY, but this provides a fallback.
 	FetchPollInterval time.Duration
 
+	GlobalMaxWorkers       int
+	GlobalMaxWorkersByKind map[string]int
 	HookLookupByJob        *hooklookup.JobHookLookup
 	HookLookupGlobal       hooklookup.HookLookupInterface
 	JobTimeout             time.Duration
+	Labels                 map[string]string
 	MaxWorkers             int
+	MaxWorkersByKind       map[string]int
 	MiddlewareLookupGlobal middlewarelookup.MiddlewareLookupInterface
 
 	// Notifier is a notifier for subscribing to new job inserts and job
 	// control. If nil, the producer will operate in poll-only mode.
 	Notifier *notifier.Notifier
+
+	// PoisonPillDetected is invoked after a job that panicked is discarded
+	// as a poison pill for having crashed PoisonPillMaxCrashes times in a
+	// row. Detection is disabled if PoisonPillMaxCrashes is zero.
+	PoisonPillDetected   func(ctx context.Context, job *rivertype.JobRow)
+	PoisonPillMaxCrashes int
+
 	// ProducerReportInterval is the amount of time between periodic reports
 	// of the producer status.
 	ProducerReportInterval time.Duration
@@ -106,12 +165,49 @@ type producerConfig struct {
 	QueuePollInterval time.Duration
 	// QueueReportInterval is the amount of time between periodic reports
 	// of the queue status.
-	QueueReportInterval          time.Duration
+	QueueReportInterval time.Duration
+	ReleaseJobsOnStop   bool
+
+	// ResourceBudgetExceeded reports whether the client has exceeded the
+	// resource budget of its Config.ResourceGuard, in which case fetches are
+	// skipped. It may be nil.
+	ResourceBudgetExceeded func() bool
+
+	// RetryBudgetLimiter is a limiter from which errored jobs take a token
+	// before being scheduled for retry, deferring their retry if none is
+	// available. It may be nil.
+	RetryBudgetLimiter *Limiter
+
 	RetryPolicy                  ClientRetryPolicy
 	SchedulerInterval            time.Duration
 	Schema                       string
 	StaleProducerRetentionPeriod time.Duration
-	Workers                      *Workers
+
+	// UnknownJobKindPolicy determines what happens to fetched jobs whose kind
+	// has no registered worker. UnknownJobKindWorkFunc works them when it's
+	// UnknownJobKindPolicyCatchAll.
+	UnknownJobKindPolicy   UnknownJobKindPolicy
+	UnknownJobKindWorkFunc func(ctx context.Context, job *rivertype.JobRow) error
+
+	// UnknownJobKindsFetched is incremented for each fetched job whose kind has
+	// no registered worker. It may be nil.
+	UnknownJobKindsFetched *atomic.Int64
+
+	// VisibilityTimeout enables a lease-based execution model when non-zero.
+	// The producer periodically renews a lease of this duration on each of
+	// its active jobs, and reaps jobs in its queue whose leases have expired.
+	VisibilityTimeout time.Duration
+
+	// WorkerQueuesEnforced causes fetched jobs in a queue their worker isn't
+	// bound to with WorkerWithQueues to be errored instead of worked.
+	WorkerQueuesEnforced bool
+
+	// WorkerSlots pools worker slots across the client's queues with
+	// Config.WorkStealing. When set, the producer acquires its slots from the
+	// pool rather than being limited to MaxWorkers. Nil disables work stealing.
+	WorkerSlots *workerSlotPool
+
+	Workers *Workers
 }
 
 func (c *producerConfig) mustValidate() *producerConfig {
@@ -160,6 +256,9 @@ func (c *producerConfig) mustValidate() *producerConfig {
 	if c.StaleProducerRetentionPeriod <= 0 {
 		panic("producerConfig.StaleProducerRetentionPeriod must be greater than zero")
 	}
+	if c.VisibilityTimeout < 0 {
+		panic("producerConfig.VisibilityTimeout must be greater or equal to zero")
+	}
 	if c.Workers == nil {
 		panic("producerConfig.Workers is required")
 	}
@@ -178,8 +277,11 @@ type producer struct {
 	baseservice.BaseService
 	startstop.BaseStartStop
 
-	// Jobs which are currently being worked. Only used by main goroutine.
-	activeJobs map[int64]*jobexecutor.JobExecutor
+	// Jobs which are currently being worked. Only modified by the main
+	// goroutine, which must hold activeJobsMu while doing so. Other goroutines
+	// must also hold activeJobsMu to read it.
+	activeJobs   map[int64]*jobexecutor.JobExecutor
+	activeJobsMu sync.Mutex
 
 	completer    jobcompleter.JobCompleter
 	config       *producerConfig
@@ -191,9 +293,12 @@ type producer struct {
 	pilot        riverpilot.Pilot
 	workers      *Workers
 
-	// Receives job IDs to cancel. Written by notifier goroutine, only read from
-	// main goroutine.
-	cancelCh chan int64
+	// Chooses fetch batch sizes. Only set with AdaptiveFetchBatchSize.
+	fetchBatchSizer *fetchBatchSizer
+
+	// Receives requests to cancel jobs. Written by notifier goroutine, only
+	// read from main goroutine.
+	cancelCh chan *rivernotify.ControlPayload
 
 	// Set to true when the producer thinks it should trigger another fetch as
 	// soon as slots are available. This is written and read by the main
@@ -211,11 +316,15 @@ type producer struct {
 	numJobsActive atomic.Int32
 	numJobsStuck  atomic.Int32
 
+	// The number of jobs actively being worked by kind, tracked only for kinds
+	// limited by MaxWorkersByKind. Only accessed by the main goroutine.
+	numJobsActiveByKind map[string]int
+
 	numJobsRan atomic.Uint64
 	paused     bool
 	// Receives control messages from the notifier goroutine. Written by notifier
 	// goroutine, only read from main goroutine.
-	queueControlCh chan *controlEventPayload
+	queueControlCh chan *rivernotify.ControlPayload
 	retryPolicy    ClientRetryPolicy
 	testSignals    producerTestSignals
 }
@@ -233,19 +342,26 @@ func newProducer(archetype *baseservice.Archetype, exec riverdriver.Executor, pi
 		errorHandler = &errorHandlerAdapter{config.ErrorHandler}
 	}
 
+	var fetchBatchSizer *fetchBatchSizer
+	if config.AdaptiveFetchBatchSize {
+		fetchBatchSizer = newFetchBatchSizer(config.MaxWorkers, config.FetchPollInterval)
+	}
+
 	return baseservice.Init(archetype, &producer{
-		activeJobs:     make(map[int64]*jobexecutor.JobExecutor),
-		cancelCh:       make(chan int64, 1000),
-		completer:      config.Completer,
-		config:         config.mustValidate(),
-		exec:           exec,
-		errorHandler:   errorHandler,
-		jobResultCh:    make(chan *rivertype.JobRow, config.MaxWorkers),
-		jobTimeout:     config.JobTimeout,
-		pilot:          pilot,
-		queueControlCh: make(chan *controlEventPayload, 100),
-		retryPolicy:    config.RetryPolicy,
-		workers:        config.Workers,
+		activeJobs:          make(map[int64]*jobexecutor.JobExecutor),
+		cancelCh:            make(chan *rivernotify.ControlPayload, 1000),
+		completer:           config.Completer,
+		config:              config.mustValidate(),
+		exec:                exec,
+		errorHandler:        errorHandler,
+		fetchBatchSizer:     fetchBatchSizer,
+		jobResultCh:         make(chan *rivertype.JobRow, config.MaxWorkers),
+		jobTimeout:          config.JobTimeout,
+		numJobsActiveByKind: make(map[string]int),
+		pilot:               pilot,
+		queueControlCh:      make(chan *rivernotify.ControlPayload, 100),
+		retryPolicy:         config.RetryPolicy,
+		workers:             config.Workers,
 	})
 }
 
@@ -344,9 +460,9 @@ func (p *producer) StartWorkContext(fetchCtx, workCtx context.Context) error {
 		var err error
 
 		handleInsertNotification := func(topic notifier.NotificationTopic, payload string) {
-			var decoded insertPayload
-			if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
-				p.Logger.ErrorContext(workCtx, p.Name+": Failed to unmarshal insert notification payload", slog.String("err", err.Error()))
+			decoded, err := rivernotify.DecodeInsert(payload)
+			if err != nil {
+				p.Logger.ErrorContext(workCtx, p.Name+": Failed to decode insert notification payload", slog.String("err", err.Error()))
 				return
 			}
 			if decoded.Queue != p.config.Queue {
@@ -406,6 +522,11 @@ func (p *producer) StartWorkContext(fetchCtx, workCtx context.Context) error {
 		subroutineWG.Add(1)
 		go p.reportProducerStatusLoop(subroutineCtx, &subroutineWG)
 
+		if p.config.VisibilityTimeout > 0 {
+			subroutineWG.Add(1)
+			go p.leaseLoop(subroutineCtx, &subroutineWG)
+		}
+
 		if p.config.Notifier == nil {
 			p.Logger.DebugContext(subroutineCtx, p.Name+": No notifier configured; starting in poll mode", "client_id", p.config.ClientID)
 
@@ -445,52 +566,32 @@ func (p *producer) TriggerJobFetch() {
 // listen/notify. This is used by clients using drivers that don't support
 // listeners to wake a producer immediately after a queue control event was
 // known to be performed so the producer doesn't have to wait on polling.
-func (p *producer) TriggerQueueControlEvent(controlEvent *controlEventPayload) {
+func (p *producer) TriggerQueueControlEvent(controlEvent *rivernotify.ControlPayload) {
 	p.queueControlCh <- controlEvent
 	p.testSignals.QueueControlEventTriggered.Signal(controlEvent)
 }
 
-type controlAction string
-
-const (
-	controlActionCancel          controlAction = "cancel"
-	controlActionMetadataChanged controlAction = "metadata_changed"
-	controlActionPause           controlAction = "pause"
-	controlActionResume          controlAction = "resume"
-)
-
-type controlEventPayload struct {
-	Action   controlAction   `json:"action"`
-	JobID    int64           `json:"job_id,omitempty"`
-	Metadata json.RawMessage `json:"metadata,omitempty"`
-	Queue    string          `json:"queue"`
-}
-
-type insertPayload struct {
-	Queue string `json:"queue"`
-}
-
 func (p *producer) handleControlNotification(workCtx context.Context) func(notifier.NotificationTopic, string) {
 	return func(topic notifier.NotificationTopic, payload string) {
-		var decoded controlEventPayload
-		if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
-			p.Logger.ErrorContext(workCtx, p.Name+": Failed to unmarshal job control notification payload", slog.String("err", err.Error()))
+		decoded, err := rivernotify.DecodeControl(payload)
+		if err != nil {
+			p.Logger.ErrorContext(workCtx, p.Name+": Failed to decode job control notification payload", slog.String("err", err.Error()))
 			return
 		}
 
 		switch decoded.Action {
-		case controlActionMetadataChanged, controlActionPause, controlActionResume:
+		case rivernotify.ControlActionMetadataChanged, rivernotify.ControlActionPause, rivernotify.ControlActionResume:
 			if decoded.Queue != rivercommon.AllQueuesString && decoded.Queue != p.config.Queue {
 				p.Logger.DebugContext(workCtx, p.Name+": Queue control notification for other queue", slog.String("action", string(decoded.Action)))
 				return
 			}
 			select {
 			case <-workCtx.Done():
-			case p.queueControlCh <- &decoded:
+			case p.queueControlCh <- decoded:
 			default:
 				p.Logger.WarnContext(workCtx, p.Name+": Queue control notification dropped due to full buffer", slog.String("action", string(decoded.Action)))
 			}
-		case controlActionCancel:
+		case rivernotify.ControlActionCancel:
 			if decoded.Queue != p.config.Queue {
 				p.Logger.DebugContext(workCtx, p.Name+": Received job cancel notification for other queue",
 					slog.String("action", string(decoded.Action)),
@@ -501,10 +602,13 @@ func (p *producer) handleControlNotification(workCtx context.Context) func(notif
 			}
 			select {
 			case <-workCtx.Done():
-			case p.cancelCh <- decoded.JobID:
+			case p.cancelCh <- decoded:
 			default:
 				p.Logger.WarnContext(workCtx, p.Name+": Job cancel notification dropped due to full buffer", slog.Int64("job_id", decoded.JobID))
 			}
+		case rivernotify.ControlActionCustom, rivernotify.ControlActionJobStatusRequest, rivernotify.ControlActionJobStatusResponse:
+			// Custom control messages and job status requests are handled by
+			// the client's control bus.
 		default:
 			p.Logger.DebugContext(workCtx, p.Name+": Received job control notification with unknown action",
 				slog.String("action", string(decoded.Action)),
@@ -527,12 +631,12 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context) {
 			return
 		case msg := <-p.queueControlCh:
 			switch msg.Action {
-			case controlActionCancel:
+			case rivernotify.ControlActionCancel:
 				// This path is only expected to take effect in poll-only mode, and
 				// only works for the case of a single process. Multi-process setups
 				// will have to wait for the next poll event for a cancel to take effect.
-				p.maybeCancelJob(workCtx, msg.JobID)
-			case controlActionMetadataChanged:
+				p.maybeCancelJob(workCtx, msg.JobID, msg.Reason)
+			case rivernotify.ControlActionMetadataChanged:
 				p.Logger.DebugContext(workCtx, p.Name+": Queue metadata changed", slog.String("queue", p.config.Queue), slog.String("queue_in_message", msg.Queue))
 				p.testSignals.MetadataChanged.Signal(struct{}{})
 				if err := p.pilot.QueueMetadataChanged(workCtx, p.exec, &riverpilot.QueueMetadataChangedParams{
@@ -541,7 +645,10 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context) {
 				}); err != nil {
 					p.Logger.ErrorContext(workCtx, p.Name+": Error updating queue metadata with pilot", slog.String("queue", p.config.Queue), slog.String("err", err.Error()))
 				}
-			case controlActionPause:
+				if p.config.QueueEventCallback != nil {
+					p.config.QueueEventCallback(&Event{Kind: EventKindQueueMetadataChanged, Queue: &rivertype.Queue{Metadata: msg.Metadata, Name: p.config.Queue}})
+				}
+			case rivernotify.ControlActionPause:
 				if p.paused {
 					continue
 				}
@@ -551,7 +658,7 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context) {
 				if p.config.QueueEventCallback != nil {
 					p.config.QueueEventCallback(&Event{Kind: EventKindQueuePaused, Queue: &rivertype.Queue{Name: p.config.Queue}})
 				}
-			case controlActionResume:
+			case rivernotify.ControlActionResume:
 				if !p.paused {
 					continue
 				}
@@ -565,8 +672,8 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context) {
 			default:
 				p.Logger.DebugContext(workCtx, p.Name+": Unknown queue control action", "action", msg.Action)
 			}
-		case jobID := <-p.cancelCh:
-			p.maybeCancelJob(workCtx, jobID)
+		case msg := <-p.cancelCh:
+			p.maybeCancelJob(workCtx, msg.JobID, msg.Reason)
 		case <-p.fetchLimiter.C():
 			p.innerFetchLoop(workCtx, fetchResultCh)
 			// Ensure we can't start another fetch when fetchCtx is done, even if
@@ -623,6 +730,22 @@ func (p *producer) jitteredFetchPollInterval() time.Duration {
 }
 
 func (p *producer) innerFetchLoop(workCtx context.Context, fetchResultCh chan producerFetchResult) {
+	// Don't fetch while the database is considered unavailable. The fetch poll
+	// loop keeps triggering fetches, so they resume once the circuit closes.
+	if p.config.CircuitOpen != nil && p.config.CircuitOpen() {
+		return
+	}
+
+	// Similarly, don't take on more work while over the resource budget.
+	if p.config.ResourceBudgetExceeded != nil && p.config.ResourceBudgetExceeded() {
+		return
+	}
+
+	// Or while completions of jobs that have already been worked are backed up.
+	if p.config.CompleterBackpressureEngaged != nil && p.config.CompleterBackpressureEngaged() {
+		return
+	}
+
 	var limit int
 	if p.paused {
 		limit = 0
@@ -637,28 +760,70 @@ func (p *producer) innerFetchLoop(workCtx context.Context, fetchResultCh chan pr
 		}
 	}
 
-	go p.dispatchWork(workCtx, limit, fetchResultCh)
+	// Kinds at their limit aren't fetched, and no more jobs of other limited
+	// kinds are fetched than they have room for. One of their jobs finishing
+	// doesn't free up a slot in the usual sense, so make sure that it triggers
+	// another fetch.
+	kindsExcluded, maxToLockByKind := p.kindLimits()
+	if len(kindsExcluded) > 0 {
+		p.fetchWhenSlotsAreAvailable = true
+	}
+
+	go p.dispatchWork(workCtx, limit, kindsExcluded, maxToLockByKind, fetchResultCh)
+
+	// Cancellations received while the fetch is in flight may be for jobs
+	// being fetched, which aren't active yet, so they're kept until the fetch
+	// returns so that any such jobs are cancelled before being worked.
+	var cancelledDuringFetch map[int64]string
 
 	for {
 		select {
 		case result := <-fetchResultCh:
+			// Give back any slots acquired from the pool that the fetch didn't
+			// fill.
+			if p.config.WorkerSlots != nil && limit > len(result.jobs) {
+				p.config.WorkerSlots.release(p.config.Queue, limit-len(result.jobs))
+			}
+
+			if p.fetchBatchSizer != nil && result.err == nil {
+				p.fetchBatchSizer.recordFetch(p.Time.Now(), limit, len(result.jobs), p.numJobsRan.Load())
+			}
+
 			if result.err != nil {
 				p.Logger.ErrorContext(workCtx, p.Name+": Error fetching jobs", slog.String("err", result.err.Error()), slog.String("queue", p.config.Queue))
 			} else if len(result.jobs) > 0 {
-				p.startNewExecutors(workCtx, result.jobs)
+				p.startNewExecutors(workCtx, result.jobs, cancelledDuringFetch)
+
+				// Same as above for kinds that this fetch brought to their
+				// limit.
+				if kindsAtLimit, _ := p.kindLimits(); len(kindsAtLimit) > 0 {
+					p.fetchWhenSlotsAreAvailable = true
+				}
 
 				if len(result.jobs) == limit {
 					// Fetch returned the maximum number of jobs that were requested,
 					// implying there may be more in the queue. Trigger another fetch when
 					// slots are available.
 					p.fetchWhenSlotsAreAvailable = true
+
+					// An adaptive batch may have been smaller than the number of
+					// free slots, in which case don't wait for one to free up.
+					if p.fetchBatchSizer != nil && (p.config.WorkerSlots != nil || int(p.numJobsActive.Load()) < p.config.MaxWorkers) {
+						p.fetchLimiter.Call()
+					}
 				}
 			}
 			return
 		case result := <-p.jobResultCh:
 			p.removeActiveJob(result)
-		case jobID := <-p.cancelCh:
-			p.maybeCancelJob(workCtx, jobID)
+		case msg := <-p.cancelCh:
+			if _, ok := p.activeJobs[msg.JobID]; !ok {
+				if cancelledDuringFetch == nil {
+					cancelledDuringFetch = make(map[int64]string)
+				}
+				cancelledDuringFetch[msg.JobID] = msg.Reason
+			}
+			p.maybeCancelJob(workCtx, msg.JobID, msg.Reason)
 		}
 	}
 }
@@ -723,27 +888,101 @@ func (p *producer) finalizeShutdown(ctx context.Context) {
 	p.Logger.WarnContext(ctx, p.Name+": Failed to cleanly shutdown producer after all attempts")
 }
 
+// ActiveJobIDs returns the IDs of jobs currently being worked by the producer,
+// sorted in ascending order. Safe to call from any goroutine.
+func (p *producer) ActiveJobIDs() []int64 {
+	p.activeJobsMu.Lock()
+	defer p.activeJobsMu.Unlock()
+
+	ids := maputil.Keys(p.activeJobs)
+	slices.Sort(ids)
+	return ids
+}
+
+// Returns the executors of jobs currently being worked by the producer. Safe to
+// call from any goroutine.
+func (p *producer) activeJobExecutors() []*jobexecutor.JobExecutor {
+	p.activeJobsMu.Lock()
+	defer p.activeJobsMu.Unlock()
+
+	return maputil.Values(p.activeJobs)
+}
+
+// Returns the IDs and attempts of jobs currently being worked by the producer
+// so that their leases can be renewed. Safe to call from any goroutine.
+func (p *producer) activeJobLeases() ([]int64, []int) {
+	p.activeJobsMu.Lock()
+	defer p.activeJobsMu.Unlock()
+
+	var (
+		attempts = make([]int, 0, len(p.activeJobs))
+		ids      = make([]int64, 0, len(p.activeJobs))
+	)
+	for id, executor := range p.activeJobs {
+		attempts = append(attempts, executor.JobRow.Attempt)
+		ids = append(ids, id)
+	}
+	return ids, attempts
+}
+
+// Returns the live status of the job with the given ID if it's currently being
+// worked by the producer, or nil otherwise. Safe to call from any goroutine.
+func (p *producer) activeJobStatus(id int64) *rivernotify.JobStatus {
+	p.activeJobsMu.Lock()
+	executor, ok := p.activeJobs[id]
+	p.activeJobsMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	var elapsed time.Duration
+	if executor.JobRow.AttemptedAt != nil {
+		elapsed = p.Time.Now().Sub(*executor.JobRow.AttemptedAt)
+	}
+
+	return &rivernotify.JobStatus{
+		ElapsedMS: elapsed.Milliseconds(),
+		Progress:  executor.Progress(),
+	}
+}
+
 func (p *producer) addActiveJob(id int64, executor *jobexecutor.JobExecutor) {
 	p.numJobsActive.Add(1)
+	if _, ok := p.config.MaxWorkersByKind[executor.JobRow.Kind]; ok {
+		p.numJobsActiveByKind[executor.JobRow.Kind]++
+	}
+
+	p.activeJobsMu.Lock()
 	p.activeJobs[id] = executor
+	p.activeJobsMu.Unlock()
 }
 
 func (p *producer) removeActiveJob(job *rivertype.JobRow) {
+	p.activeJobsMu.Lock()
 	delete(p.activeJobs, job.ID)
+	p.activeJobsMu.Unlock()
+
 	p.numJobsActive.Add(-1)
+	if _, ok := p.config.MaxWorkersByKind[job.Kind]; ok {
+		p.numJobsActiveByKind[job.Kind]--
+	}
 	p.numJobsRan.Add(1)
 	p.state.JobFinish(job)
+
+	if p.config.WorkerSlots != nil {
+		p.config.WorkerSlots.release(p.config.Queue, 1)
+	}
 }
 
-func (p *producer) maybeCancelJob(ctx context.Context, id int64) {
+func (p *producer) maybeCancelJob(ctx context.Context, id int64, reason string) {
 	executor, ok := p.activeJobs[id]
 	if !ok {
 		return
 	}
-	executor.Cancel(ctx)
+	executor.Cancel(ctx, reason)
 }
 
-func (p *producer) dispatchWork(workCtx context.Context, count int, fetchResultCh chan<- producerFetchResult) {
+func (p *producer) dispatchWork(workCtx context.Context, count int, kindsExcluded []string, maxToLockByKind map[string]int, fetchResultCh chan<- producerFetchResult) {
 	// This intentionally removes any deadlines or cancellation from the parent
 	// context because we don't want it to get cancelled if the producer is asked
 	// to shut down. In that situation, we want to finish fetching any jobs we are
@@ -753,19 +992,12 @@ func (p *producer) dispatchWork(workCtx context.Context, count int, fetchResultC
 	// back to the queue.
 	ctx := context.WithoutCancel(workCtx)
 
-	// Maximum size of the `attempted_by` array on each job row. This maximum is
-	// rarely hit, but exists to protect against degenerate cases.
-	const maxAttemptedBy = 100
+	if p.config.GlobalMaxWorkers > 0 || len(p.config.GlobalMaxWorkersByKind) > 0 {
+		fetchResultCh <- p.fetchWithGlobalLimits(ctx, count, kindsExcluded, maxToLockByKind)
+		return
+	}
 
-	jobs, err := p.pilot.JobGetAvailable(ctx, p.exec, p.state, &riverdriver.JobGetAvailableParams{
-		ClientID:       p.config.ClientID,
-		MaxAttemptedBy: maxAttemptedBy,
-		MaxToLock:      count,
-		Now:            p.Time.NowOrNil(),
-		Queue:          p.config.Queue,
-		ProducerID:     p.id.Load(),
-		Schema:         p.config.Schema,
-	})
+	jobs, err := p.fetch(ctx, p.exec, count, kindsExcluded, maxToLockByKind)
 	if err != nil {
 		fetchResultCh <- producerFetchResult{err: err}
 		return
@@ -774,6 +1006,112 @@ func (p *producer) dispatchWork(workCtx context.Context, count int, fetchResultC
 	fetchResultCh <- producerFetchResult{jobs: jobs}
 }
 
+func (p *producer) fetch(ctx context.Context, exec riverdriver.Executor, count int, kindsExcluded []string, maxToLockByKind map[string]int) ([]*rivertype.JobRow, error) {
+	// Maximum size of the `attempted_by` array on each job row. This maximum is
+	// rarely hit, but exists to protect against degenerate cases.
+	const maxAttemptedBy = 100
+
+	return p.pilot.JobGetAvailable(ctx, exec, p.state, &riverdriver.JobGetAvailableParams{
+		ClientID:        p.config.ClientID,
+		FairnessKey:     p.config.FairnessKey,
+		FairnessWeights: p.config.FairnessWeights,
+		KindsExcluded:   kindsExcluded,
+		Labels:          p.config.Labels,
+		MaxAttemptedBy:  maxAttemptedBy,
+		MaxToLock:       count,
+		MaxToLockByKind: maxToLockByKind,
+		Now:             p.Time.NowOrNil(),
+		Queue:           p.config.Queue,
+		ProducerID:      p.id.Load(),
+		Schema:          p.config.Schema,
+	})
+}
+
+// Fetches jobs while respecting GlobalMaxWorkers and GlobalMaxWorkersByKind,
+// which limit the number of jobs running in the queue across all clients.
+// Running jobs are counted and new ones locked in a transaction holding an
+// advisory lock for the queue so that clients fetching at the same time can't
+// together exceed a limit.
+func (p *producer) fetchWithGlobalLimits(ctx context.Context, count int, kindsExcluded []string, maxToLockByKind map[string]int) producerFetchResult {
+	// Counting stops at a limit because any more running jobs than that makes
+	// no difference.
+	countRunning := func(ctx context.Context, execTx riverdriver.ExecutorTx, limit int, whereClause string, namedArgs map[string]any) (int, error) {
+		return execTx.JobCountMatching(ctx, &riverdriver.JobCountMatchingParams{
+			Max:         int32(min(limit, math.MaxInt32)), //nolint:gosec
+			NamedArgs:   namedArgs,
+			Schema:      p.config.Schema,
+			WhereClause: "state = 'running' AND queue = @queue" + whereClause,
+		})
+	}
+
+	var result producerFetchResult
+	result.err = dbutil.WithTx(ctx, p.exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		// SQLite has no advisory locks, but only allows one writer at a time
+		// anyway.
+		if _, err := execTx.PGAdvisoryXactLock(ctx, p.globalLimitLockKey()); err != nil && !errors.Is(err, riverdriver.ErrNotImplemented) {
+			return fmt.Errorf("error acquiring global limit lock: %w", err)
+		}
+
+		if p.config.GlobalMaxWorkers > 0 {
+			numRunning, err := countRunning(ctx, execTx, p.config.GlobalMaxWorkers, "", map[string]any{"queue": p.config.Queue})
+			if err != nil {
+				return err
+			}
+
+			count = min(count, p.config.GlobalMaxWorkers-numRunning)
+			if count <= 0 {
+				return nil
+			}
+		}
+
+		// Kinds with a global limit are fetched only up to their room left
+		// across all clients, or up to their local limit if that's lower.
+		kindsExcluded = slices.Clone(kindsExcluded)
+		maxToLockByKind = maps.Clone(maxToLockByKind)
+		for kind, maxWorkers := range p.config.GlobalMaxWorkersByKind {
+			if slices.Contains(kindsExcluded, kind) {
+				continue
+			}
+
+			numRunning, err := countRunning(ctx, execTx, maxWorkers, " AND kind = @kind", map[string]any{"kind": kind, "queue": p.config.Queue})
+			if err != nil {
+				return err
+			}
+
+			if numRunning >= maxWorkers {
+				kindsExcluded = append(kindsExcluded, kind)
+				delete(maxToLockByKind, kind)
+				continue
+			}
+
+			if maxToLockByKind == nil {
+				maxToLockByKind = make(map[string]int, len(p.config.GlobalMaxWorkersByKind))
+			}
+			if localMaxToLock, ok := maxToLockByKind[kind]; !ok || maxWorkers-numRunning < localMaxToLock {
+				maxToLockByKind[kind] = maxWorkers - numRunning
+			}
+		}
+		slices.Sort(kindsExcluded)
+
+		var err error
+		result.jobs, err = p.fetch(ctx, execTx, count, kindsExcluded, maxToLockByKind)
+		return err
+	})
+	if result.err != nil {
+		return producerFetchResult{err: result.err}
+	}
+
+	return result
+}
+
+// Returns the key of the advisory lock that serializes fetches in the queue
+// when global limits are configured.
+func (p *producer) globalLimitLockKey() int64 {
+	lockHash := hashutil.NewAdvisoryLockHash(p.config.AdvisoryLockPrefix)
+	lockHash.Write([]byte("river_global_limit:" + p.config.Schema + "." + p.config.Queue))
+	return lockHash.Key()
+}
+
 // Periodically logs an informational log line giving some insight into the
 // current state of the producer.
 func (p *producer) heartbeatLogLoop(ctx context.Context, wg *sync.WaitGroup) {
@@ -810,29 +1148,58 @@ func (p *producer) heartbeatLogLoop(ctx context.Context, wg *sync.WaitGroup) {
 	}
 }
 
-func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.JobRow) {
+// Starts executors for fetched jobs. Jobs in cancelledDuringFetch, which maps
+// job IDs to cancellation reasons and may be nil, are cancelled before they
+// start so that they're not worked.
+func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.JobRow, cancelledDuringFetch map[int64]string) {
 	for _, job := range jobs {
 		workInfo, ok := p.workers.workersMap[job.Kind]
 
 		var workUnit workunit.WorkUnit
-		if ok {
+		switch {
+		case ok:
 			workUnit = workInfo.workUnitFactory.MakeUnit(job)
+
+			if middleware := p.workers.middlewareByKind[workInfo.jobArgs.Kind()]; len(middleware) > 0 {
+				workUnit = &kindMiddlewareWorkUnit{WorkUnit: workUnit, middleware: middleware}
+			}
+
+			if p.config.WorkerQueuesEnforced && !workInfo.queueAllowed(job.Queue) {
+				workUnit = &workerQueueMismatchWorkUnit{WorkUnit: workUnit, jobRow: job, queues: workInfo.queues}
+			}
+
+		case p.config.UnknownJobKindPolicy == UnknownJobKindPolicyCatchAll:
+			workUnit = &unknownJobKindWorkUnit{jobRow: job, workFunc: p.config.UnknownJobKindWorkFunc}
+		}
+
+		if !ok && p.config.UnknownJobKindsFetched != nil {
+			p.config.UnknownJobKindsFetched.Add(1)
 		}
 
 		// jobCancel will always be called by the executor to prevent leaks.
 		jobCtx, jobCancel := context.WithCancelCause(workCtx)
 
+		var retryBudgetReserve func(ctx context.Context) (time.Duration, error)
+		if p.config.RetryBudgetLimiter != nil {
+			retryBudgetReserve = p.config.RetryBudgetLimiter.Reserve
+		}
+
 		executor := baseservice.Init(&p.Archetype, &jobexecutor.JobExecutor{
+			ArgsDecoder:              p.config.ArgsDecoder,
+			AttemptInfo:              p.config.AttemptInfo,
 			CancelFunc:               jobCancel,
 			ClientJobTimeout:         p.jobTimeout,
 			ClientRetryPolicy:        p.retryPolicy,
 			Completer:                p.completer,
 			DefaultClientRetryPolicy: &DefaultClientRetryPolicy{},
 			ErrorHandler:             p.errorHandler,
+			ErrorMaxBytes:            p.config.ErrorSizeLimits.errorMaxBytes(),
 			HookLookupByJob:          p.config.HookLookupByJob,
 			HookLookupGlobal:         p.config.HookLookupGlobal,
 			MiddlewareLookupGlobal:   p.config.MiddlewareLookupGlobal,
 			JobRow:                   job,
+			PoisonPillDetected:       p.config.PoisonPillDetected,
+			PoisonPillMaxCrashes:     p.config.PoisonPillMaxCrashes,
 			ProducerCallbacks: struct {
 				JobDone func(jobRow *rivertype.JobRow)
 				Stuck   func()
@@ -842,11 +1209,19 @@ func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.
 				Stuck:   func() { p.numJobsStuck.Add(1) },
 				Unstuck: func() { p.numJobsStuck.Add(-1) },
 			},
-			SchedulerInterval: p.config.SchedulerInterval,
-			WorkUnit:          workUnit,
+			ReleaseOnStop:        p.config.ReleaseJobsOnStop,
+			RetryBudgetReserve:   retryBudgetReserve,
+			SchedulerInterval:    p.config.SchedulerInterval,
+			TraceMaxBytes:        p.config.ErrorSizeLimits.traceMaxBytes(),
+			UnknownJobKindAction: p.config.UnknownJobKindPolicy.executorAction(),
+			WorkUnit:             workUnit,
 		})
 		p.addActiveJob(job.ID, executor)
 
+		if reason, ok := cancelledDuringFetch[job.ID]; ok {
+			executor.Cancel(workCtx, reason)
+		}
+
 		go executor.Execute(jobCtx)
 	}
 
@@ -855,8 +1230,50 @@ func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.
 	p.testSignals.StartedExecutors.Signal(struct{}{})
 }
 
+// Returns the maximum number of jobs to fetch. With work stealing, slots are
+// acquired from the client's pool, allowing the producer to work more than
+// MaxWorkers jobs by borrowing idle slots from other queues. Acquired slots
+// are given back as jobs finish or if the fetch doesn't fill them.
 func (p *producer) maxJobsToFetch() int {
-	return p.config.MaxWorkers - int(p.numJobsActive.Load())
+	batchSize := p.config.MaxWorkers
+	if p.fetchBatchSizer != nil {
+		var completerBacklogFraction float64
+		if p.config.CompleterBacklog != nil {
+			if size, maxSize := p.config.CompleterBacklog(); maxSize > 0 {
+				completerBacklogFraction = float64(size) / float64(maxSize)
+			}
+		}
+		batchSize = p.fetchBatchSizer.next(completerBacklogFraction)
+	}
+
+	if p.config.WorkerSlots != nil {
+		return p.config.WorkerSlots.acquire(p.config.Queue, batchSize)
+	}
+
+	return min(batchSize, p.config.MaxWorkers-int(p.numJobsActive.Load()))
+}
+
+// Returns kinds that have as many active jobs as allowed by MaxWorkersByKind,
+// which shouldn't be fetched, along with the number of jobs that can still be
+// fetched for each of the other kinds in MaxWorkersByKind.
+func (p *producer) kindLimits() ([]string, map[string]int) {
+	var (
+		kindsAtLimit    []string
+		maxToLockByKind map[string]int
+	)
+	for kind, maxWorkers := range p.config.MaxWorkersByKind {
+		if p.numJobsActiveByKind[kind] >= maxWorkers {
+			kindsAtLimit = append(kindsAtLimit, kind)
+			continue
+		}
+
+		if maxToLockByKind == nil {
+			maxToLockByKind = make(map[string]int, len(p.config.MaxWorkersByKind))
+		}
+		maxToLockByKind[kind] = maxWorkers - p.numJobsActiveByKind[kind]
+	}
+	slices.Sort(kindsAtLimit)
+	return kindsAtLimit, maxToLockByKind
 }
 
 func (p *producer) handleWorkerDone(job *rivertype.JobRow) {
@@ -898,11 +1315,11 @@ func (p *producer) pollForSettingChanges(ctx context.Context, wg *sync.WaitGroup
 			// Look for a change in the paused state:
 			shouldBePaused := (updatedQueue.PausedAt != nil)
 			if lastPaused != shouldBePaused {
-				action := controlActionPause
+				action := rivernotify.ControlActionPause
 				if !shouldBePaused {
-					action = controlActionResume
+					action = rivernotify.ControlActionResume
 				}
-				payload := &controlEventPayload{
+				payload := &rivernotify.ControlPayload{
 					Action: action,
 					Queue:  p.config.Queue,
 				}
@@ -922,8 +1339,8 @@ func (p *producer) pollForSettingChanges(ctx context.Context, wg *sync.WaitGroup
 
 			// Look for a change in the queue's metadata:
 			if !metadataEqual(lastMetadata, updatedQueue.Metadata) {
-				payload := &controlEventPayload{
-					Action:   controlActionMetadataChanged,
+				payload := &rivernotify.ControlPayload{
+					Action:   rivernotify.ControlActionMetadataChanged,
 					Queue:    p.config.Queue,
 					Metadata: updatedQueue.Metadata,
 				}
@@ -935,7 +1352,7 @@ func (p *producer) pollForSettingChanges(ctx context.Context, wg *sync.WaitGroup
 				case p.queueControlCh <- payload:
 					lastMetadata = updatedQueue.Metadata
 				default:
-					p.Logger.WarnContext(ctx, p.Name+": Queue control notification dropped due to full buffer", slog.String("action", string(controlActionMetadataChanged)))
+					p.Logger.WarnContext(ctx, p.Name+": Queue control notification dropped due to full buffer", slog.String("action", string(rivernotify.ControlActionMetadataChanged)))
 				}
 			}
 
@@ -944,6 +1361,86 @@ func (p *producer) pollForSettingChanges(ctx context.Context, wg *sync.WaitGroup
 	}
 }
 
+// Runs when the queue is configured with a visibility timeout. Renews leases on
+// the producer's active jobs often enough that they'll never expire while the
+// producer is healthy, and reaps jobs in the queue whose leases have expired,
+// which is a sign that the client working them has gone away.
+func (p *producer) leaseLoop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Renew at a third of the visibility timeout so that a single failed
+	// renewal doesn't cause a lease to expire.
+	ticker := timeutil.NewTickerWithInitialTick(ctx, p.config.VisibilityTimeout/3)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.renewLeasesOnce(ctx)
+			p.reapExpiredLeasesOnce(ctx)
+		}
+	}
+}
+
+func (p *producer) renewLeasesOnce(ctx context.Context) {
+	ids, attempts := p.activeJobLeases()
+	if len(ids) < 1 {
+		p.testSignals.RenewedLeases.Signal(struct{}{})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := p.exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+		Attempt:        attempts,
+		ID:             ids,
+		LeaseExpiresAt: p.Time.Now().Add(p.config.VisibilityTimeout),
+		Schema:         p.config.Schema,
+	}); err != nil {
+		if errors.Is(context.Cause(ctx), startstop.ErrStop) {
+			return
+		}
+		p.Logger.ErrorContext(ctx, p.Name+": Error renewing job leases",
+			slog.String("err", err.Error()),
+			slog.Int("num_jobs", len(ids)),
+			slog.String("queue", p.config.Queue),
+		)
+		return
+	}
+
+	p.testSignals.RenewedLeases.Signal(struct{}{})
+}
+
+func (p *producer) reapExpiredLeasesOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	numReaped, err := joblease.ReapExpired(ctx, p.exec, p.config.Schema, p.config.Queue, p.Time.Now().UTC())
+	if err != nil {
+		if errors.Is(context.Cause(ctx), startstop.ErrStop) {
+			return
+		}
+		p.Logger.ErrorContext(ctx, p.Name+": Error reaping expired leases",
+			slog.String("err", err.Error()),
+			slog.String("queue", p.config.Queue),
+		)
+		return
+	}
+
+	if numReaped > 0 {
+		p.Logger.InfoContext(ctx, p.Name+": Reaped jobs with expired leases",
+			slog.Int("num_jobs", numReaped),
+			slog.String("queue", p.config.Queue),
+		)
+
+		// Jobs made available again can be worked right away.
+		p.fetchLimiter.Call()
+	}
+
+	p.testSignals.ReapedExpiredLeases.Signal(struct{}{})
+}
+
 func (p *producer) reportProducerStatusLoop(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobcompleter"
@@ -21,6 +24,7 @@ import (
 	"github.com/riverqueue/river/riverdbtest"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivernotify"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riverpilot"
 	"github.com/riverqueue/river/rivershared/riversharedtest"
@@ -464,6 +468,60 @@ func testProducer(t *testing.T, makeProducer func(ctx context.Context, t *testin
 		require.Equal(t, rivertype.JobStateRetryable, update.Job.State)
 	})
 
+	t.Run("CancelledDuringFetchNotWorked", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+
+		pilot := &fetchBlockingPilot{
+			fetchStarted:   make(chan struct{}, 1),
+			fetchUnblocked: make(chan struct{}),
+		}
+		producer.pilot = pilot
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		var workCalled atomic.Bool
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			workCalled.Store(true)
+			return nil
+		}))
+
+		mustInsert(ctx, t, producer, bundle, &JobArgs{})
+
+		jobs, err := bundle.exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(&JobArgs{}).Kind()},
+			Schema: producer.config.Schema,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		job := jobs[0]
+
+		startProducer(t, ctx, ctx, producer)
+
+		unblockFetch := sync.OnceFunc(func() { close(pilot.fetchUnblocked) })
+		t.Cleanup(unblockFetch)
+
+		riversharedtest.WaitOrTimeout(t, pilot.fetchStarted)
+
+		// The job is cancelled while the fetch that returns it is in flight.
+		producer.cancelCh <- &rivernotify.ControlPayload{
+			Action: rivernotify.ControlActionCancel,
+			JobID:  job.ID,
+			Queue:  bundle.queue,
+		}
+		require.Eventually(t, func() bool { return len(producer.cancelCh) == 0 }, 5*time.Second, 10*time.Millisecond)
+
+		unblockFetch()
+
+		update := riversharedtest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, job.ID, update.Job.ID)
+		require.Equal(t, rivertype.JobStateCancelled, update.Job.State)
+		require.False(t, workCalled.Load())
+	})
+
 	t.Run("MaxWorkers", func(t *testing.T) {
 		t.Parallel()
 
@@ -517,6 +575,300 @@ func testProducer(t *testing.T, makeProducer func(ctx context.Context, t *testin
 		require.Zero(t, producer.maxJobsToFetch()) // zero because all slots are occupied
 	})
 
+	t.Run("MaxWorkersByKind", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			maxWorkersByKind = 2
+			numJobs          = 5
+		)
+
+		producer, bundle := setup(t)
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		producer.config.MaxWorkersByKind = map[string]int{(&JobArgs{}).Kind(): maxWorkersByKind}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		// Jobs in excess of the kind's limit are never fetched.
+		updatedJobs, err := bundle.exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(&JobArgs{}).Kind()},
+			Schema: producer.config.Schema,
+		})
+		require.NoError(t, err)
+
+		jobStateCounts := make(map[rivertype.JobState]int)
+
+		for _, updatedJob := range updatedJobs {
+			jobStateCounts[updatedJob.State]++
+		}
+
+		require.Equal(t, maxWorkersByKind, jobStateCounts[rivertype.JobStateRunning])
+		require.Equal(t, numJobs-maxWorkersByKind, jobStateCounts[rivertype.JobStateAvailable])
+
+		require.Equal(t, maxWorkersByKind, int(producer.numJobsActive.Load()))
+	})
+
+	t.Run("GlobalMaxWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			globalMaxWorkers = 3
+			numJobs          = 5
+		)
+
+		producer, bundle := setup(t)
+		producer.config.GlobalMaxWorkers = globalMaxWorkers
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// A job being worked by another client counts toward the limit.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Queue:  &bundle.queue,
+			Schema: producer.config.Schema,
+			State:  ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		updatedJobs, err := bundle.exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(&JobArgs{}).Kind()},
+			Schema: producer.config.Schema,
+		})
+		require.NoError(t, err)
+
+		jobStateCounts := make(map[rivertype.JobState]int)
+
+		for _, updatedJob := range updatedJobs {
+			jobStateCounts[updatedJob.State]++
+		}
+
+		require.Equal(t, globalMaxWorkers-1, jobStateCounts[rivertype.JobStateRunning])
+		require.Equal(t, numJobs-(globalMaxWorkers-1), jobStateCounts[rivertype.JobStateAvailable])
+
+		require.Equal(t, globalMaxWorkers-1, int(producer.numJobsActive.Load()))
+	})
+
+	t.Run("GlobalMaxWorkersByKind", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			globalMaxWorkersByKind = 2
+			numJobs                = 5
+		)
+
+		producer, bundle := setup(t)
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		producer.config.GlobalMaxWorkersByKind = map[string]int{(&JobArgs{}).Kind(): globalMaxWorkersByKind}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// A job of the kind being worked by another client counts toward the
+		// limit.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Kind:   ptrutil.Ptr((&JobArgs{}).Kind()),
+			Queue:  &bundle.queue,
+			Schema: producer.config.Schema,
+			State:  ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		// Jobs in excess of the kind's limit are never fetched.
+		updatedJobs, err := bundle.exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(&JobArgs{}).Kind()},
+			Schema: producer.config.Schema,
+		})
+		require.NoError(t, err)
+
+		jobStateCounts := make(map[rivertype.JobState]int)
+
+		for _, updatedJob := range updatedJobs {
+			jobStateCounts[updatedJob.State]++
+		}
+
+		// One running job is the other client's.
+		require.Equal(t, globalMaxWorkersByKind, jobStateCounts[rivertype.JobStateRunning])
+		require.Equal(t, numJobs-(globalMaxWorkersByKind-1), jobStateCounts[rivertype.JobStateAvailable])
+
+		require.Equal(t, globalMaxWorkersByKind-1, int(producer.numJobsActive.Load()))
+	})
+
+	t.Run("FairnessKey", func(t *testing.T) {
+		t.Parallel()
+
+		const maxWorkers = 2
+
+		producer, bundle := setup(t)
+		producer.config.FairnessKey = "tenant_id"
+		producer.config.MaxWorkers = maxWorkers
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// Tenant "a" has a backlog of jobs ahead of tenant "b", but each
+		// tenant gets one of the two available workers.
+		for _, tenantID := range []string{"a", "a", "a", "b"} {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				Kind:        ptrutil.Ptr((&JobArgs{}).Kind()),
+				Metadata:    []byte(`{"tenant_id":"` + tenantID + `"}`),
+				Queue:       &bundle.queue,
+				ScheduledAt: &bundle.timeBeforeStart,
+				Schema:      producer.config.Schema,
+			})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		updatedJobs, err := bundle.exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{(&JobArgs{}).Kind()},
+			Schema: producer.config.Schema,
+		})
+		require.NoError(t, err)
+
+		var runningTenantIDs []string
+		for _, updatedJob := range updatedJobs {
+			if updatedJob.State == rivertype.JobStateRunning {
+				runningTenantIDs = append(runningTenantIDs, gjson.GetBytes(updatedJob.Metadata, "tenant_id").String())
+			}
+		}
+		slices.Sort(runningTenantIDs)
+		require.Equal(t, []string{"a", "b"}, runningTenantIDs)
+	})
+
+	t.Run("VisibilityTimeoutRenewsLeases", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.VisibilityTimeout = 300 * time.Millisecond
+
+		type JobArgs struct {
+			testutil.JobArgsReflectKind[JobArgs]
+		}
+
+		jobStartedChan := make(chan int64)
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		workCtx, workCancel := context.WithCancel(ctx)
+		defer workCancel()
+
+		mustInsert(ctx, t, producer, bundle, &JobArgs{})
+
+		startProducer(t, ctx, workCtx, producer)
+
+		jobID := riversharedtest.WaitOrTimeout(t, jobStartedChan)
+
+		// Wait for two renewals to guarantee that one happened after the job
+		// became active.
+		producer.testSignals.RenewedLeases.WaitOrTimeout()
+		producer.testSignals.RenewedLeases.WaitOrTimeout()
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: jobID, Schema: producer.config.Schema})
+		require.NoError(t, err)
+		require.True(t, gjson.GetBytes(job.Metadata, "river:lease_expires_at").Exists())
+	})
+
+	t.Run("VisibilityTimeoutReapsExpiredLeases", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.VisibilityTimeout = 300 * time.Millisecond
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		// A job left running by a client that went away without finishing it.
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Attempt:     ptrutil.Ptr(1),
+			AttemptedAt: ptrutil.Ptr(bundle.timeBeforeStart.Add(-time.Minute)),
+			EncodedArgs: []byte("{}"),
+			Kind:        ptrutil.Ptr((&noOpArgs{}).Kind()),
+			Queue:       &bundle.queue,
+			Schema:      producer.config.Schema,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+		require.NoError(t, bundle.exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+			Attempt:        []int{job.Attempt},
+			ID:             []int64{job.ID},
+			LeaseExpiresAt: bundle.timeBeforeStart.Add(-30 * time.Second),
+			Schema:         producer.config.Schema,
+		}))
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.ReapedExpiredLeases.WaitOrTimeout()
+
+		// The reaped job is made available and worked again.
+		update := riversharedtest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, job.ID, update.Job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+		require.Equal(t, 2, update.Job.Attempt)
+		require.Len(t, update.Job.Errors, 1)
+		require.Equal(t, "Job lease expired", update.Job.Errors[0].Error)
+	})
+
 	t.Run("StartStopStress", func(t *testing.T) {
 		t.Parallel()
 
@@ -714,6 +1066,41 @@ func TestProducer_jitteredFetchPollInterval(t *testing.T) {
 	}
 }
 
+func TestProducer_kindLimits(t *testing.T) {
+	t.Parallel()
+
+	prod := &producer{
+		config: &producerConfig{
+			MaxWorkersByKind: map[string]int{"kind1": 2, "kind2": 3, "kind3": 1},
+		},
+		numJobsActiveByKind: map[string]int{"kind1": 2, "kind2": 1},
+	}
+
+	kindsAtLimit, maxToLockByKind := prod.kindLimits()
+	require.Equal(t, []string{"kind1"}, kindsAtLimit)
+	require.Equal(t, map[string]int{"kind2": 2, "kind3": 1}, maxToLockByKind)
+}
+
+// fetchBlockingPilot is a pilot whose fetches block until fetchUnblocked is
+// closed, signaling fetchStarted (if it has room) as each one starts.
+type fetchBlockingPilot struct {
+	riverpilot.StandardPilot
+
+	fetchStarted   chan struct{}
+	fetchUnblocked chan struct{}
+}
+
+func (p *fetchBlockingPilot) JobGetAvailable(ctx context.Context, exec riverdriver.Executor, state riverpilot.ProducerState, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	select {
+	case p.fetchStarted <- struct{}{}:
+	default:
+	}
+
+	<-p.fetchUnblocked
+
+	return p.StandardPilot.JobGetAvailable(ctx, exec, state, params)
+}
+
 func emitQueueNotification(t *testing.T, ctx context.Context, exec riverdriver.Executor, schema, queue, action string, metadata []byte) {
 	t.Helper()
 
@@ -0,0 +1,303 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	rateAnomalyIntervalDefault        = 1 * time.Minute
+	rateAnomalyMinCountDefault        = 10
+	rateAnomalySmoothingDefault       = 0.2
+	rateAnomalyThresholdDefault       = 3.0
+	rateAnomalyWarmupIntervalsDefault = 5
+
+	// Number of intervals that a kind's rate may go without any activity
+	// before it's forgotten, so that kinds that are no longer inserted or
+	// worked don't accumulate.
+	rateAnomalyIdleIntervalsMax = 60
+)
+
+// RateAnomalyMetric is a rate of job activity watched for anomalies by
+// Config.RateAnomalyMonitor.
+type RateAnomalyMetric string
+
+const (
+	// RateAnomalyMetricFailures is the rate at which jobs of a kind worked by
+	// the client fail, including failures that'll be retried.
+	RateAnomalyMetricFailures RateAnomalyMetric = "failures"
+
+	// RateAnomalyMetricInserts is the rate at which jobs of a kind are
+	// inserted by the client.
+	RateAnomalyMetricInserts RateAnomalyMetric = "inserts"
+)
+
+// RateAnomaly contains information about a kind whose insert or failure rate
+// has deviated sharply from its recent baseline. It's sent with
+// EventKindRateAnomaly events.
+type RateAnomaly struct {
+	// Baseline is the number of occurrences per interval that were expected
+	// based on recent intervals.
+	Baseline float64
+
+	// Count is the number of occurrences in the most recent interval.
+	Count int
+
+	// Interval is the length of the interval over which occurrences are
+	// counted.
+	Interval time.Duration
+
+	// Kind is the job kind whose rate deviated.
+	Kind string
+
+	// Metric is the rate that deviated.
+	Metric RateAnomalyMetric
+}
+
+// RateAnomalyMonitorConfig configures a monitor that watches the rates at
+// which jobs of each kind are inserted and fail, emitting an
+// EventKindRateAnomaly event when one of them rises sharply above its recent
+// baseline, like when a producer starts inserting runaway jobs or a newly
+// deployed worker starts failing.
+//
+// Rates are counted over a fixed interval and each kind's baseline is an
+// exponentially weighted moving average of its recent intervals. Only the
+// inserts and failures of the client that the monitor is configured on are
+// counted, so insert anomalies are only detected on clients that both insert
+// and are started.
+type RateAnomalyMonitorConfig struct {
+	// Interval is the length of the interval over which inserts and failures
+	// are counted and compared against baselines.
+	//
+	// Defaults to 1 minute.
+	Interval time.Duration
+
+	// MinCount is the minimum number of occurrences in an interval for a rate
+	// to be considered anomalous. It keeps small absolute changes to a low
+	// baseline, like one failure going to four, from being reported.
+	//
+	// Defaults to 10.
+	MinCount int
+
+	// Smoothing is the weight given to the most recent interval when updating
+	// a baseline, between 0 and 1. Higher values adapt to changes in rates
+	// more quickly, but also absorb anomalies into baselines more quickly.
+	//
+	// Defaults to 0.2.
+	Smoothing float64
+
+	// Threshold is the multiple of its baseline that a rate must exceed to be
+	// considered anomalous. Must be greater than 1.
+	//
+	// Defaults to 3.
+	Threshold float64
+
+	// WarmupIntervals is the number of intervals that a kind must have been
+	// observed for before its baseline is trusted and anomalies are reported.
+	//
+	// Defaults to 5.
+	WarmupIntervals int
+}
+
+func (c *RateAnomalyMonitorConfig) validate() error {
+	if c.Interval < 0 {
+		return errors.New("RateAnomalyMonitor.Interval cannot be less than zero")
+	}
+	if c.MinCount < 0 {
+		return errors.New("RateAnomalyMonitor.MinCount cannot be less than zero")
+	}
+	if c.Smoothing < 0 || c.Smoothing > 1 {
+		return errors.New("RateAnomalyMonitor.Smoothing must be between 0 and 1")
+	}
+	if c.Threshold != 0 && c.Threshold <= 1 {
+		return errors.New("RateAnomalyMonitor.Threshold must be greater than 1")
+	}
+	if c.WarmupIntervals < 0 {
+		return errors.New("RateAnomalyMonitor.WarmupIntervals cannot be less than zero")
+	}
+	return nil
+}
+
+func (c *RateAnomalyMonitorConfig) withDefaults() *RateAnomalyMonitorConfig {
+	return &RateAnomalyMonitorConfig{
+		Interval:        cmp.Or(c.Interval, rateAnomalyIntervalDefault),
+		MinCount:        cmp.Or(c.MinCount, rateAnomalyMinCountDefault),
+		Smoothing:       cmp.Or(c.Smoothing, rateAnomalySmoothingDefault),
+		Threshold:       cmp.Or(c.Threshold, rateAnomalyThresholdDefault),
+		WarmupIntervals: cmp.Or(c.WarmupIntervals, rateAnomalyWarmupIntervalsDefault),
+	}
+}
+
+type rateAnomalyKey struct {
+	kind   string
+	metric RateAnomalyMetric
+}
+
+// rateAnomalyBaseline is the state tracked for the rate of a single kind and
+// metric.
+type rateAnomalyBaseline struct {
+	anomalous     bool
+	baseline      float64
+	idleIntervals int
+	numIntervals  int
+}
+
+// rateAnomalyMonitor counts inserts and failures by kind, comparing each
+// interval's counts against exponentially weighted moving averages of
+// previous intervals and emitting EventKindRateAnomaly when a count exceeds
+// its baseline by the configured threshold. An event is only emitted when a
+// rate first becomes anomalous, and not again until it's returned to normal.
+type rateAnomalyMonitor struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	config *RateAnomalyMonitorConfig
+
+	// eventCallback receives anomaly events. Set after construction so that
+	// it can be pointed at the subscription manager.
+	eventCallback func(event *Event)
+
+	// Counts for the current interval. Kinds that have been active but had no
+	// occurrences of a metric are present with a count of zero so that they
+	// build a baseline of zero.
+	countsMu sync.Mutex
+	counts   map[rateAnomalyKey]int
+
+	// Only accessed from the run loop.
+	baselines map[rateAnomalyKey]*rateAnomalyBaseline
+}
+
+func newRateAnomalyMonitor(archetype *baseservice.Archetype, config *RateAnomalyMonitorConfig) *rateAnomalyMonitor {
+	return baseservice.Init(archetype, &rateAnomalyMonitor{
+		baselines: make(map[rateAnomalyKey]*rateAnomalyBaseline),
+		config:    config.withDefaults(),
+		counts:    make(map[rateAnomalyKey]int),
+	})
+}
+
+// RecordInserts counts inserted jobs.
+func (m *rateAnomalyMonitor) RecordInserts(insertParams []*rivertype.JobInsertParams) {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+
+	for _, params := range insertParams {
+		m.counts[rateAnomalyKey{kind: params.Kind, metric: RateAnomalyMetricInserts}]++
+	}
+}
+
+// RecordJobUpdates counts failed jobs from completer updates. Jobs that didn't
+// fail are also recorded so that their kinds build a baseline of no failures.
+func (m *rateAnomalyMonitor) RecordJobUpdates(updates []jobcompleter.CompleterJobUpdated) {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+
+	for _, update := range updates {
+		key := rateAnomalyKey{kind: update.Job.Kind, metric: RateAnomalyMetricFailures}
+
+		count := m.counts[key]
+		if !update.Snoozed && (update.Job.State == rivertype.JobStateAvailable ||
+			update.Job.State == rivertype.JobStateDiscarded ||
+			update.Job.State == rivertype.JobStateRetryable) {
+			count++
+		}
+		m.counts[key] = count
+	}
+}
+
+func (m *rateAnomalyMonitor) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := m.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			m.evaluate(ctx)
+		}
+	}()
+
+	return nil
+}
+
+// evaluate closes out the current interval, comparing its counts against
+// baselines before folding them into the baselines.
+func (m *rateAnomalyMonitor) evaluate(ctx context.Context) {
+	m.countsMu.Lock()
+	counts := m.counts
+	m.counts = make(map[rateAnomalyKey]int, len(counts))
+	m.countsMu.Unlock()
+
+	for key, count := range counts {
+		if _, ok := m.baselines[key]; !ok {
+			// The first interval seeds the baseline directly rather than
+			// averaging against zero.
+			m.baselines[key] = &rateAnomalyBaseline{baseline: float64(count)}
+		}
+	}
+
+	for key, baseline := range m.baselines {
+		count, active := counts[key]
+		if !active {
+			baseline.idleIntervals++
+			if baseline.idleIntervals > rateAnomalyIdleIntervalsMax {
+				delete(m.baselines, key)
+				continue
+			}
+		} else {
+			baseline.idleIntervals = 0
+		}
+
+		anomalous := baseline.numIntervals >= m.config.WarmupIntervals &&
+			count >= m.config.MinCount &&
+			float64(count) > m.config.Threshold*baseline.baseline
+
+		if anomalous && !baseline.anomalous {
+			m.Logger.WarnContext(ctx, m.Name+": Job rate anomaly detected",
+				slog.Float64("baseline", baseline.baseline),
+				slog.Int("count", count),
+				slog.String("kind", key.kind),
+				slog.String("metric", string(key.metric)),
+			)
+
+			if m.eventCallback != nil {
+				m.eventCallback(&Event{
+					Kind: EventKindRateAnomaly,
+					RateAnomaly: &RateAnomaly{
+						Baseline: baseline.baseline,
+						Count:    count,
+						Interval: m.config.Interval,
+						Kind:     key.kind,
+						Metric:   key.metric,
+					},
+				})
+			}
+		}
+		baseline.anomalous = anomalous
+
+		if baseline.numIntervals > 0 {
+			baseline.baseline = m.config.Smoothing*float64(count) + (1-m.config.Smoothing)*baseline.baseline
+		}
+		baseline.numIntervals++
+	}
+}
@@ -0,0 +1,202 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestRateAnomalyMonitorConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&RateAnomalyMonitorConfig{}).validate())
+	require.NoError(t, (&RateAnomalyMonitorConfig{Interval: time.Second, MinCount: 1, Smoothing: 1, Threshold: 1.5, WarmupIntervals: 1}).validate())
+
+	require.EqualError(t, (&RateAnomalyMonitorConfig{Interval: -1}).validate(), "RateAnomalyMonitor.Interval cannot be less than zero")
+	require.EqualError(t, (&RateAnomalyMonitorConfig{MinCount: -1}).validate(), "RateAnomalyMonitor.MinCount cannot be less than zero")
+	require.EqualError(t, (&RateAnomalyMonitorConfig{Smoothing: -0.1}).validate(), "RateAnomalyMonitor.Smoothing must be between 0 and 1")
+	require.EqualError(t, (&RateAnomalyMonitorConfig{Smoothing: 1.1}).validate(), "RateAnomalyMonitor.Smoothing must be between 0 and 1")
+	require.EqualError(t, (&RateAnomalyMonitorConfig{Threshold: 1}).validate(), "RateAnomalyMonitor.Threshold must be greater than 1")
+	require.EqualError(t, (&RateAnomalyMonitorConfig{WarmupIntervals: -1}).validate(), "RateAnomalyMonitor.WarmupIntervals cannot be less than zero")
+}
+
+func TestRateAnomalyMonitor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		events []*Event
+	}
+
+	setup := func(t *testing.T) (*rateAnomalyMonitor, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{}
+
+		monitor := newRateAnomalyMonitor(riversharedtest.BaseServiceArchetype(t), &RateAnomalyMonitorConfig{
+			MinCount:        10,
+			Threshold:       3,
+			WarmupIntervals: 3,
+		})
+		monitor.eventCallback = func(event *Event) { bundle.events = append(bundle.events, event) }
+
+		return monitor, bundle
+	}
+
+	recordInserts := func(monitor *rateAnomalyMonitor, kind string, num int) {
+		insertParams := make([]*rivertype.JobInsertParams, num)
+		for i := range insertParams {
+			insertParams[i] = &rivertype.JobInsertParams{Kind: kind}
+		}
+		monitor.RecordInserts(insertParams)
+	}
+
+	recordUpdates := func(monitor *rateAnomalyMonitor, kind string, state rivertype.JobState, num int) {
+		updates := make([]jobcompleter.CompleterJobUpdated, num)
+		for i := range updates {
+			updates[i] = jobcompleter.CompleterJobUpdated{Job: &rivertype.JobRow{Kind: kind, State: state}}
+		}
+		monitor.RecordJobUpdates(updates)
+	}
+
+	t.Run("InsertSpike", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, bundle := setup(t)
+
+		for range 3 {
+			recordInserts(monitor, "kind", 10)
+			monitor.evaluate(ctx)
+		}
+		require.Empty(t, bundle.events)
+
+		recordInserts(monitor, "kind", 100)
+		monitor.evaluate(ctx)
+
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, EventKindRateAnomaly, bundle.events[0].Kind)
+		require.Equal(t, &RateAnomaly{
+			Baseline: 10,
+			Count:    100,
+			Interval: rateAnomalyIntervalDefault,
+			Kind:     "kind",
+			Metric:   RateAnomalyMetricInserts,
+		}, bundle.events[0].RateAnomaly)
+
+		// Not sent again while the rate remains anomalous.
+		recordInserts(monitor, "kind", 1_000)
+		monitor.evaluate(ctx)
+		require.Len(t, bundle.events, 1)
+	})
+
+	t.Run("SentAgainAfterReturningToNormal", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, bundle := setup(t)
+
+		for range 3 {
+			recordInserts(monitor, "kind", 10)
+			monitor.evaluate(ctx)
+		}
+
+		recordInserts(monitor, "kind", 100)
+		monitor.evaluate(ctx)
+		require.Len(t, bundle.events, 1)
+
+		recordInserts(monitor, "kind", 10)
+		monitor.evaluate(ctx)
+		require.Len(t, bundle.events, 1)
+
+		recordInserts(monitor, "kind", 1_000)
+		monitor.evaluate(ctx)
+		require.Len(t, bundle.events, 2)
+	})
+
+	t.Run("FailureSpikeFromZero", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, bundle := setup(t)
+
+		// Completed jobs build a baseline of zero failures.
+		for range 3 {
+			recordUpdates(monitor, "kind", rivertype.JobStateCompleted, 10)
+			monitor.evaluate(ctx)
+		}
+
+		recordUpdates(monitor, "kind", rivertype.JobStateRetryable, 10)
+		monitor.evaluate(ctx)
+
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, &RateAnomaly{
+			Baseline: 0,
+			Count:    10,
+			Interval: rateAnomalyIntervalDefault,
+			Kind:     "kind",
+			Metric:   RateAnomalyMetricFailures,
+		}, bundle.events[0].RateAnomaly)
+	})
+
+	t.Run("BelowMinCount", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, bundle := setup(t)
+
+		for range 3 {
+			recordUpdates(monitor, "kind", rivertype.JobStateRetryable, 1)
+			monitor.evaluate(ctx)
+		}
+
+		recordUpdates(monitor, "kind", rivertype.JobStateRetryable, 9)
+		monitor.evaluate(ctx)
+
+		require.Empty(t, bundle.events)
+	})
+
+	t.Run("NotSentDuringWarmup", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, bundle := setup(t)
+
+		recordInserts(monitor, "kind", 10)
+		monitor.evaluate(ctx)
+
+		recordInserts(monitor, "kind", 100)
+		monitor.evaluate(ctx)
+
+		require.Empty(t, bundle.events)
+	})
+
+	t.Run("IdleKindsForgotten", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, _ := setup(t)
+
+		recordInserts(monitor, "kind", 10)
+		monitor.evaluate(ctx)
+		require.Len(t, monitor.baselines, 1)
+
+		for range rateAnomalyIdleIntervalsMax {
+			monitor.evaluate(ctx)
+		}
+		require.Len(t, monitor.baselines, 1)
+
+		monitor.evaluate(ctx)
+		require.Empty(t, monitor.baselines)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		monitor, _ := setup(t)
+
+		startstoptest.Stress(ctx, t, monitor)
+	})
+}
@@ -0,0 +1,138 @@
+package river
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+// Limiter is a rate limiter whose state is stored in the database so that it's
+// shared by every client using the same database and schema. It's useful for
+// respecting third-party API quotas from workers running across a whole fleet,
+// which per-process limiters can't do. Get one with Client.Limiter:
+//
+//	stripeLimiter := client.Limiter("stripe-api", 100, time.Second)
+//
+//	func (w *ChargeWorker) Work(ctx context.Context, job *river.Job[ChargeArgs]) error {
+//		if err := w.stripeLimiter.Wait(ctx); err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Limits are enforced with a token bucket that allows up to the limit's number
+// of operations in a burst, then refills continuously at the limit's rate. Each
+// call to Wait or Reserve takes a token with a single database round trip.
+// Limiters with the same name share a bucket, so every use of a name should
+// be configured with the same limit.
+type Limiter struct {
+	burst    float64
+	exec     riverdriver.Executor
+	name     string
+	rate     float64 // tokens per second
+	readOnly bool
+	schema   string
+	time     baseservice.TimeGeneratorWithStub
+}
+
+// Limiter returns a rate limiter shared by every client using the same
+// database and schema that permits limit operations per period. For example,
+// `client.Limiter("stripe-api", 100, time.Second)` permits 100 operations per
+// second, including a burst of up to 100 at once.
+//
+// Limiters are cheap, holding no state of their own, so it's fine to get one
+// each time it's needed. Panics if name is empty or 128 characters or longer,
+// or if limit or per are less than or equal to zero.
+func (c *Client[TTx]) Limiter(name string, limit int, per time.Duration) *Limiter {
+	if name == "" || len(name) >= 128 {
+		panic("limiter name must be between 1 and 127 characters")
+	}
+	if limit <= 0 {
+		panic("limiter limit must be greater than zero")
+	}
+	if per <= 0 {
+		panic("limiter period must be greater than zero")
+	}
+
+	var exec riverdriver.Executor
+	if c.driver.PoolIsSet() {
+		exec = c.driver.GetExecutor()
+	}
+
+	return &Limiter{
+		burst:    float64(limit),
+		exec:     exec,
+		name:     name,
+		rate:     float64(limit) / per.Seconds(),
+		readOnly: c.config.ReadOnly,
+		schema:   c.config.Schema,
+		time:     c.baseService.Time,
+	}
+}
+
+// Reserve takes a token from the limiter and returns how long the caller must
+// wait before performing its operation, which is zero if the operation may be
+// performed immediately. The token is consumed whether or not the caller
+// waits, so Reserve is useful for deferring work instead of blocking, like by
+// snoozing a job for the returned duration:
+//
+//	delay, err := w.stripeLimiter.Reserve(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if delay > 0 {
+//		return river.JobSnooze(delay)
+//	}
+func (l *Limiter) Reserve(ctx context.Context) (time.Duration, error) {
+	if l.exec == nil {
+		return 0, errNoDriverDBPool
+	}
+	if l.readOnly {
+		return 0, ErrClientReadOnly
+	}
+
+	tokens, err := l.exec.RateLimitReserve(ctx, &riverdriver.RateLimitReserveParams{
+		Burst:  l.burst,
+		Name:   l.name,
+		Now:    l.time.NowOrNil(),
+		Rate:   l.rate,
+		Schema: l.schema,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error reserving from limiter %q: %w", l.name, err)
+	}
+
+	if tokens >= 0 {
+		return 0, nil
+	}
+
+	// A negative token count is a deficit that's paid off once enough time has
+	// passed for it to have been refilled.
+	return time.Duration(-tokens / l.rate * float64(time.Second)), nil
+}
+
+// Wait blocks until the limiter permits an operation or ctx is done, in which
+// case it returns ctx's error. A token is consumed even if ctx is done before
+// the wait completes.
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay, err := l.Reserve(ctx)
+	if err != nil {
+		return err
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package river
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+		)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			schema: schema,
+		}
+	}
+
+	t.Run("BurstThenDelay", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		now := client.baseService.Time.StubNow(time.Now().UTC())
+
+		limiter := client.Limiter("limiter", 3, time.Minute)
+
+		for range 3 {
+			delay, err := limiter.Reserve(ctx)
+			require.NoError(t, err)
+			require.Zero(t, delay)
+		}
+
+		delay, err := limiter.Reserve(ctx)
+		require.NoError(t, err)
+		require.InDelta(t, 20*time.Second, delay, float64(time.Millisecond))
+
+		delay, err = limiter.Reserve(ctx)
+		require.NoError(t, err)
+		require.InDelta(t, 40*time.Second, delay, float64(time.Millisecond))
+
+		// After a full period, the deficit of two tokens has been paid off and
+		// one more has been refilled.
+		client.baseService.Time.StubNow(now.Add(time.Minute))
+
+		delay, err = limiter.Reserve(ctx)
+		require.NoError(t, err)
+		require.Zero(t, delay)
+
+		delay, err = limiter.Reserve(ctx)
+		require.NoError(t, err)
+		require.InDelta(t, 20*time.Second, delay, float64(time.Millisecond))
+	})
+
+	t.Run("RefillCappedAtBurst", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		now := client.baseService.Time.StubNow(time.Now().UTC())
+
+		limiter := client.Limiter("limiter", 2, time.Minute)
+
+		_, err := limiter.Reserve(ctx)
+		require.NoError(t, err)
+
+		client.baseService.Time.StubNow(now.Add(time.Hour))
+
+		for range 2 {
+			delay, err := limiter.Reserve(ctx)
+			require.NoError(t, err)
+			require.Zero(t, delay)
+		}
+
+		delay, err := limiter.Reserve(ctx)
+		require.NoError(t, err)
+		require.Positive(t, delay)
+	})
+
+	t.Run("SharedAcrossClients", func(t *testing.T) {
+		t.Parallel()
+
+		client1, bundle := setup(t)
+		client2 := newTestClient(t, bundle.dbPool, newTestConfig(t, bundle.schema))
+
+		delay, err := client1.Limiter("limiter", 1, time.Hour).Reserve(ctx)
+		require.NoError(t, err)
+		require.Zero(t, delay)
+
+		delay, err = client2.Limiter("limiter", 1, time.Hour).Reserve(ctx)
+		require.NoError(t, err)
+		require.Positive(t, delay)
+
+		// A limiter with a different name has its own bucket.
+		delay, err = client2.Limiter("other_limiter", 1, time.Hour).Reserve(ctx)
+		require.NoError(t, err)
+		require.Zero(t, delay)
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		limiter := client.Limiter("limiter", 1, time.Hour)
+		require.NoError(t, limiter.Wait(ctx))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, limiter.Wait(timeoutCtx), context.DeadlineExceeded)
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{})
+		require.NoError(t, err)
+
+		_, err = client.Limiter("limiter", 1, time.Second).Reserve(ctx)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+
+	t.Run("InvalidArgs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		require.PanicsWithValue(t, "limiter name must be between 1 and 127 characters", func() {
+			client.Limiter("", 1, time.Second)
+		})
+		require.PanicsWithValue(t, "limiter name must be between 1 and 127 characters", func() {
+			client.Limiter(strings.Repeat("x", 128), 1, time.Second)
+		})
+		require.PanicsWithValue(t, "limiter limit must be greater than zero", func() {
+			client.Limiter("limiter", 0, time.Second)
+		})
+		require.PanicsWithValue(t, "limiter period must be greater than zero", func() {
+			client.Limiter("limiter", 1, 0)
+		})
+	})
+}
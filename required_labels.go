@@ -0,0 +1,26 @@
+package river
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// setRequiredLabels records the given required labels in job metadata, where
+// they're picked up by the fetch query so that the job is only fetched by
+// clients whose Config.Labels include all of them.
+func setRequiredLabels(metadata []byte, requiredLabels map[string]string) ([]byte, error) {
+	if _, ok := requiredLabels[""]; ok {
+		return nil, errors.New("required labels cannot contain an empty key")
+	}
+
+	metadata, err := sjson.SetBytes(metadata, rivertype.MetadataKeyRequiredLabels, requiredLabels)
+	if err != nil {
+		return nil, fmt.Errorf("error setting required labels in metadata: %w", err)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,152 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type requiredLabelsArgs struct{}
+
+func (requiredLabelsArgs) Kind() string { return "required_labels" }
+
+func (requiredLabelsArgs) InsertOpts() InsertOpts {
+	return InsertOpts{RequiredLabels: map[string]string{"gpu": "true"}}
+}
+
+func TestSetRequiredLabels(t *testing.T) {
+	t.Parallel()
+
+	metadata, err := setRequiredLabels([]byte(`{"foo":"bar"}`), map[string]string{"gpu": "true", "region": "eu"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar","river:required_labels":{"gpu":"true","region":"eu"}}`, string(metadata))
+
+	_, err = setRequiredLabels([]byte(`{}`), map[string]string{"": "true"})
+	require.EqualError(t, err, "required labels cannot contain an empty key")
+}
+
+func Test_insertParamsFromConfigArgsAndOptions_RequiredLabels(t *testing.T) {
+	t.Parallel()
+
+	archetype := riversharedtest.BaseServiceArchetype(t)
+	config := newTestConfig(t, "")
+
+	requiredLabels := func(insertParams *rivertype.JobInsertParams) string {
+		return gjson.GetBytes(insertParams.Metadata, rivertype.MetadataKeyRequiredLabels).Raw
+	}
+
+	t.Run("InsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{RequiredLabels: map[string]string{"region": "eu"}})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"region":"eu"}`, requiredLabels(insertParams))
+	})
+
+	t.Run("JobArgs", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, requiredLabelsArgs{}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"gpu":"true"}`, requiredLabels(insertParams))
+
+		// Labels from InsertOpts take precedence.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, config, requiredLabelsArgs{}, &InsertOpts{RequiredLabels: map[string]string{"region": "eu"}})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"region":"eu"}`, requiredLabels(insertParams))
+	})
+
+	t.Run("InsertOptsByKind", func(t *testing.T) {
+		t.Parallel()
+
+		kindConfig := &Config{
+			InsertOptsByKind: map[string]InsertOpts{
+				(noOpArgs{}).Kind():           {RequiredLabels: map[string]string{"gpu": "true"}},
+				(requiredLabelsArgs{}).Kind(): {RequiredLabels: map[string]string{"gpu": "false"}},
+			},
+		}
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, kindConfig, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"gpu":"true"}`, requiredLabels(insertParams))
+
+		// Labels from job args take precedence.
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, kindConfig, requiredLabelsArgs{}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"gpu":"true"}`, requiredLabels(insertParams))
+	})
+
+	t.Run("NoRequiredLabels", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{RequiredLabels: map[string]string{}})
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(insertParams.Metadata, rivertype.MetadataKeyRequiredLabels).Exists())
+	})
+
+	t.Run("EmptyKeyError", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{RequiredLabels: map[string]string{"": "true"}})
+		require.EqualError(t, err, "required labels cannot contain an empty key")
+	})
+}
+
+func Test_Client_RequiredLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	t.Run("WorkedOnlyByMatchingClient", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			schema = riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil)
+			config = newTestConfig(t, schema)
+		)
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		client := newTestClient(t, dbPool, config)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, &InsertOpts{RequiredLabels: map[string]string{"gpu": "true"}})
+		require.NoError(t, err)
+
+		// A client without the required label doesn't work the job.
+		startClient(ctx, t, client)
+		time.Sleep(3 * client.config.FetchPollInterval)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		require.NoError(t, client.Stop(ctx))
+
+		labeledConfig := newTestConfig(t, schema)
+		labeledConfig.Labels = map[string]string{"gpu": "true", "region": "eu"}
+		AddWorker(labeledConfig.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		labeledClient := newTestClient(t, dbPool, labeledConfig)
+
+		subscribeChan, cancel := labeledClient.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, labeledClient)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+}
@@ -0,0 +1,299 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+)
+
+const (
+	resourceGuardIntervalDefault        = 1 * time.Second
+	resourceGuardResumeThresholdDefault = 0.8
+)
+
+// ResourceBudget contains a client's resource usage along with the budget it
+// was measured against. It's sent with EventKindResourceBudgetExceeded and
+// EventKindResourceBudgetRecovered events.
+type ResourceBudget struct {
+	// Goroutines is the number of goroutines in the process.
+	Goroutines int
+
+	// MaxGoroutines is the configured ResourceGuardConfig.MaxGoroutines. Zero
+	// if goroutines aren't budgeted.
+	MaxGoroutines int
+
+	// MaxMemoryBytes is the configured ResourceGuardConfig.MaxMemoryBytes.
+	// Zero if memory isn't budgeted.
+	MaxMemoryBytes int64
+
+	// MemoryBytes is the memory mapped by the Go runtime for the process that
+	// hasn't been released back to the operating system.
+	MemoryBytes int64
+}
+
+// ResourceGuardConfig configures a guard that keeps a client within a budget
+// of memory and goroutines, protecting the process from being killed for
+// running out of memory when it's working jobs that use a lot of it. See
+// Config.ResourceGuard.
+//
+// Usage is measured for the whole process, so the budget should leave room for
+// anything else the process does besides working jobs. When usage exceeds the
+// budget, the client stops fetching new jobs and an
+// EventKindResourceBudgetExceeded event is emitted. Once usage falls back below
+// ResumeThreshold of the budget, fetching resumes and an
+// EventKindResourceBudgetRecovered event is emitted.
+type ResourceGuardConfig struct {
+	// CancelJobs additionally interrupts running jobs while usage exceeds the
+	// budget, starting with the most recently started, one per Interval until
+	// usage is back under budget. Interrupted jobs have their context
+	// cancelled, and if they return an error as a result, they're released
+	// back to available without consuming an attempt so that another client
+	// can work them. Jobs that don't respect context cancellation can't be
+	// interrupted.
+	CancelJobs bool
+
+	// Interval is how often usage is measured.
+	//
+	// Defaults to 1 second.
+	Interval time.Duration
+
+	// MaxGoroutines is the number of goroutines in the process above which
+	// the client stops fetching jobs. Zero doesn't budget goroutines.
+	MaxGoroutines int
+
+	// MaxMemoryBytes is the memory used by the process in bytes above which
+	// the client stops fetching jobs. Memory used is measured as the memory
+	// mapped by the Go runtime that hasn't been released back to the
+	// operating system, which is close to the process' resident set size for
+	// most programs. Zero doesn't budget memory.
+	MaxMemoryBytes int64
+
+	// ResumeThreshold is the fraction of the budget, between 0 and 1, below
+	// which usage must fall for the client to resume fetching jobs after
+	// exceeding it. It keeps the client from flapping between stopping and
+	// resuming when usage hovers around the budget.
+	//
+	// Defaults to 0.8.
+	ResumeThreshold float64
+}
+
+func (c *ResourceGuardConfig) validate() error {
+	if c.Interval < 0 {
+		return errors.New("ResourceGuard.Interval cannot be less than zero")
+	}
+	if c.MaxGoroutines < 0 {
+		return errors.New("ResourceGuard.MaxGoroutines cannot be less than zero")
+	}
+	if c.MaxMemoryBytes < 0 {
+		return errors.New("ResourceGuard.MaxMemoryBytes cannot be less than zero")
+	}
+	if c.MaxGoroutines == 0 && c.MaxMemoryBytes == 0 {
+		return errors.New("ResourceGuard must set at least one of MaxGoroutines or MaxMemoryBytes")
+	}
+	if c.ResumeThreshold < 0 || c.ResumeThreshold > 1 {
+		return errors.New("ResourceGuard.ResumeThreshold must be between 0 and 1")
+	}
+	return nil
+}
+
+func (c *ResourceGuardConfig) withDefaults() *ResourceGuardConfig {
+	return &ResourceGuardConfig{
+		CancelJobs:      c.CancelJobs,
+		Interval:        cmp.Or(c.Interval, resourceGuardIntervalDefault),
+		MaxGoroutines:   c.MaxGoroutines,
+		MaxMemoryBytes:  c.MaxMemoryBytes,
+		ResumeThreshold: cmp.Or(c.ResumeThreshold, resourceGuardResumeThresholdDefault),
+	}
+}
+
+// resourceGuard periodically measures the process' memory and goroutines
+// against a budget. While they exceed it, producers stop fetching jobs, and if
+// configured, running jobs are interrupted one at a time until they're back
+// under budget.
+type resourceGuard struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	config *ResourceGuardConfig
+
+	// eventCallback receives budget events. Set after construction so that
+	// it can be pointed at the subscription manager.
+	eventCallback func(event *Event)
+
+	// releaseJobFunc interrupts one running job, returning false if there
+	// were none left to interrupt. Set after construction because it needs
+	// the client's producers.
+	releaseJobFunc func(ctx context.Context) bool
+
+	// measureFunc measures current resource usage. Overridable in tests.
+	measureFunc func() (goroutines int, memoryBytes int64)
+
+	exceeded atomic.Bool
+}
+
+func newResourceGuard(archetype *baseservice.Archetype, config *ResourceGuardConfig) *resourceGuard {
+	return baseservice.Init(archetype, &resourceGuard{
+		config:      config.withDefaults(),
+		measureFunc: measureResourceUsage,
+	})
+}
+
+// IsExceeded returns true if the budget is currently exceeded. Safe to call on
+// a nil guard for clients that don't have one.
+func (g *resourceGuard) IsExceeded() bool {
+	return g != nil && g.exceeded.Load()
+}
+
+func (g *resourceGuard) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := g.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		ticker := time.NewTicker(g.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			g.evaluate(ctx)
+		}
+	}()
+
+	return nil
+}
+
+// evaluate measures resource usage and compares it against the budget,
+// transitioning between exceeded and recovered, and interrupting a job if
+// configured to while usage is over budget.
+func (g *resourceGuard) evaluate(ctx context.Context) {
+	goroutines, memoryBytes := g.measureFunc()
+
+	budget := &ResourceBudget{
+		Goroutines:     goroutines,
+		MaxGoroutines:  g.config.MaxGoroutines,
+		MaxMemoryBytes: g.config.MaxMemoryBytes,
+		MemoryBytes:    memoryBytes,
+	}
+
+	overBudget := g.isOver(budget, 1)
+
+	if !g.exceeded.Load() {
+		if !overBudget {
+			return
+		}
+
+		g.exceeded.Store(true)
+
+		g.Logger.WarnContext(ctx, g.Name+": Resource budget exceeded; pausing job fetching",
+			slog.Int("goroutines", goroutines),
+			slog.Int("max_goroutines", g.config.MaxGoroutines),
+			slog.Int64("max_memory_bytes", g.config.MaxMemoryBytes),
+			slog.Int64("memory_bytes", memoryBytes),
+		)
+
+		if g.eventCallback != nil {
+			g.eventCallback(&Event{Kind: EventKindResourceBudgetExceeded, ResourceBudget: budget})
+		}
+	} else if !g.isOver(budget, g.config.ResumeThreshold) {
+		g.exceeded.Store(false)
+
+		g.Logger.InfoContext(ctx, g.Name+": Resource usage back under budget; resuming job fetching",
+			slog.Int("goroutines", goroutines),
+			slog.Int64("memory_bytes", memoryBytes),
+		)
+
+		if g.eventCallback != nil {
+			g.eventCallback(&Event{Kind: EventKindResourceBudgetRecovered, ResourceBudget: budget})
+		}
+		return
+	}
+
+	if overBudget && g.config.CancelJobs && g.releaseJobFunc != nil {
+		g.releaseJobFunc(ctx)
+	}
+}
+
+// isOver returns true if any budgeted resource is over the given fraction of
+// its budget.
+func (g *resourceGuard) isOver(budget *ResourceBudget, fraction float64) bool {
+	return (budget.MaxGoroutines > 0 && float64(budget.Goroutines) > fraction*float64(budget.MaxGoroutines)) ||
+		(budget.MaxMemoryBytes > 0 && float64(budget.MemoryBytes) > fraction*float64(budget.MaxMemoryBytes))
+}
+
+// measureResourceUsage returns the number of goroutines in the process and the
+// memory mapped by the Go runtime that hasn't been released to the operating
+// system. Unlike runtime.ReadMemStats, reading runtime metrics doesn't stop
+// the world.
+func measureResourceUsage() (int, int64) {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+
+	var memoryBytes int64
+	if samples[0].Value.Kind() == metrics.KindUint64 && samples[1].Value.Kind() == metrics.KindUint64 {
+		memoryBytes = int64(samples[0].Value.Uint64() - samples[1].Value.Uint64()) //nolint:gosec
+	}
+
+	return runtime.NumGoroutine(), memoryBytes
+}
+
+// releaseNewestJob interrupts the most recently started job being worked by
+// any of the client's producers for Config.ResourceGuard, skipping jobs that
+// have already been interrupted but haven't yet returned. Returns false if
+// there was no job to interrupt.
+func (c *Client[TTx]) releaseNewestJob(ctx context.Context) bool {
+	var executors []*jobexecutor.JobExecutor
+	func() {
+		c.producersMu.RLock()
+		defer c.producersMu.RUnlock()
+
+		for _, producer := range c.producersByQueueName {
+			executors = append(executors, producer.activeJobExecutors()...)
+		}
+	}()
+
+	slices.SortFunc(executors, func(a, b *jobexecutor.JobExecutor) int {
+		return compareAttemptedAt(b.JobRow.AttemptedAt, a.JobRow.AttemptedAt)
+	})
+
+	for _, executor := range executors {
+		if executor.ReleaseForResourceBudget(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareAttemptedAt compares two attempted at times, ordering nil first.
+func compareAttemptedAt(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
@@ -0,0 +1,292 @@
+package river
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestResourceGuardConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&ResourceGuardConfig{MaxGoroutines: 1}).validate())
+	require.NoError(t, (&ResourceGuardConfig{MaxMemoryBytes: 1}).validate())
+	require.NoError(t, (&ResourceGuardConfig{CancelJobs: true, Interval: time.Second, MaxGoroutines: 1, MaxMemoryBytes: 1, ResumeThreshold: 1}).validate())
+
+	require.EqualError(t, (&ResourceGuardConfig{}).validate(), "ResourceGuard must set at least one of MaxGoroutines or MaxMemoryBytes")
+	require.EqualError(t, (&ResourceGuardConfig{Interval: -1, MaxGoroutines: 1}).validate(), "ResourceGuard.Interval cannot be less than zero")
+	require.EqualError(t, (&ResourceGuardConfig{MaxGoroutines: -1}).validate(), "ResourceGuard.MaxGoroutines cannot be less than zero")
+	require.EqualError(t, (&ResourceGuardConfig{MaxMemoryBytes: -1}).validate(), "ResourceGuard.MaxMemoryBytes cannot be less than zero")
+	require.EqualError(t, (&ResourceGuardConfig{MaxGoroutines: 1, ResumeThreshold: -0.1}).validate(), "ResourceGuard.ResumeThreshold must be between 0 and 1")
+	require.EqualError(t, (&ResourceGuardConfig{MaxGoroutines: 1, ResumeThreshold: 1.1}).validate(), "ResourceGuard.ResumeThreshold must be between 0 and 1")
+}
+
+func TestResourceGuard(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		events      []*Event
+		goroutines  int
+		memoryBytes int64
+		numReleased int
+	}
+
+	setup := func(t *testing.T, config *ResourceGuardConfig) (*resourceGuard, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{}
+
+		guard := newResourceGuard(riversharedtest.BaseServiceArchetype(t), config)
+		guard.eventCallback = func(event *Event) { bundle.events = append(bundle.events, event) }
+		guard.measureFunc = func() (int, int64) { return bundle.goroutines, bundle.memoryBytes }
+		guard.releaseJobFunc = func(ctx context.Context) bool {
+			bundle.numReleased++
+			return true
+		}
+
+		return guard, bundle
+	}
+
+	t.Run("ExceedsAndRecovers", func(t *testing.T) {
+		t.Parallel()
+
+		guard, bundle := setup(t, &ResourceGuardConfig{MaxGoroutines: 100, MaxMemoryBytes: 1_000})
+
+		bundle.goroutines, bundle.memoryBytes = 100, 1_000
+		guard.evaluate(ctx)
+		require.False(t, guard.IsExceeded())
+		require.Empty(t, bundle.events)
+
+		bundle.memoryBytes = 1_001
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Len(t, bundle.events, 1)
+		require.Equal(t, EventKindResourceBudgetExceeded, bundle.events[0].Kind)
+		require.Equal(t, &ResourceBudget{
+			Goroutines:     100,
+			MaxGoroutines:  100,
+			MaxMemoryBytes: 1_000,
+			MemoryBytes:    1_001,
+		}, bundle.events[0].ResourceBudget)
+
+		// Not sent again while still exceeded.
+		bundle.memoryBytes = 2_000
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Len(t, bundle.events, 1)
+
+		// Under budget, but not yet under the resume threshold of 80%.
+		bundle.memoryBytes = 900
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Len(t, bundle.events, 1)
+
+		// Memory is under the resume threshold, but goroutines aren't.
+		bundle.memoryBytes = 800
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Len(t, bundle.events, 1)
+
+		bundle.goroutines = 80
+		guard.evaluate(ctx)
+		require.False(t, guard.IsExceeded())
+		require.Len(t, bundle.events, 2)
+		require.Equal(t, EventKindResourceBudgetRecovered, bundle.events[1].Kind)
+		require.Equal(t, &ResourceBudget{
+			Goroutines:     80,
+			MaxGoroutines:  100,
+			MaxMemoryBytes: 1_000,
+			MemoryBytes:    800,
+		}, bundle.events[1].ResourceBudget)
+	})
+
+	t.Run("CustomResumeThreshold", func(t *testing.T) {
+		t.Parallel()
+
+		guard, bundle := setup(t, &ResourceGuardConfig{MaxGoroutines: 100, ResumeThreshold: 0.5})
+
+		bundle.goroutines = 101
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+
+		bundle.goroutines = 51
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+
+		bundle.goroutines = 50
+		guard.evaluate(ctx)
+		require.False(t, guard.IsExceeded())
+	})
+
+	t.Run("CancelJobsReleasesJobsWhileOverBudget", func(t *testing.T) {
+		t.Parallel()
+
+		guard, bundle := setup(t, &ResourceGuardConfig{CancelJobs: true, MaxGoroutines: 100})
+
+		bundle.goroutines = 100
+		guard.evaluate(ctx)
+		require.Zero(t, bundle.numReleased)
+
+		// One job is released per evaluation while over budget.
+		bundle.goroutines = 101
+		guard.evaluate(ctx)
+		require.Equal(t, 1, bundle.numReleased)
+		guard.evaluate(ctx)
+		require.Equal(t, 2, bundle.numReleased)
+
+		// But not once back under budget, even if still exceeded.
+		bundle.goroutines = 90
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Equal(t, 2, bundle.numReleased)
+	})
+
+	t.Run("CancelJobsDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		guard, bundle := setup(t, &ResourceGuardConfig{MaxGoroutines: 100})
+
+		bundle.goroutines = 101
+		guard.evaluate(ctx)
+		require.True(t, guard.IsExceeded())
+		require.Zero(t, bundle.numReleased)
+	})
+
+	t.Run("NilGuardNotExceeded", func(t *testing.T) {
+		t.Parallel()
+
+		require.False(t, (*resourceGuard)(nil).IsExceeded())
+	})
+}
+
+func TestMeasureResourceUsage(t *testing.T) {
+	t.Parallel()
+
+	goroutines, memoryBytes := measureResourceUsage()
+	require.Positive(t, goroutines)
+	require.Positive(t, memoryBytes)
+}
+
+func Test_Client_ResourceGuard(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	type testBundle struct {
+		goroutines atomic.Int64
+	}
+
+	newClient := func(t *testing.T, config *Config) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+		config.ResourceGuard.Interval = 10 * time.Millisecond
+		config.ResourceGuard.MaxGoroutines = 100
+		config.Schema = schema
+
+		client, err := NewClient(driver, config)
+		require.NoError(t, err)
+
+		bundle := &testBundle{}
+		client.resourceGuard.measureFunc = func() (int, int64) { return int(bundle.goroutines.Load()), 0 }
+
+		return client, bundle
+	}
+
+	t.Run("StopsFetchingWhileExceeded", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.ResourceGuard = &ResourceGuardConfig{}
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error { return nil }))
+
+		client, bundle := newClient(t, config)
+		bundle.goroutines.Store(101)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindResourceBudgetExceeded, EventKindResourceBudgetRecovered)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindResourceBudgetExceeded, event.Kind)
+		require.Equal(t, 101, event.ResourceBudget.Goroutines)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		// Give the producer a few fetch polls in which it could've worked the
+		// job.
+		time.Sleep(3 * client.config.FetchPollInterval)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		bundle.goroutines.Store(10)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindResourceBudgetRecovered, event.Kind)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("CancelJobsReleasesRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, "")
+		config.ResourceGuard = &ResourceGuardConfig{CancelJobs: true}
+
+		jobStarted := make(chan struct{})
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			close(jobStarted)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		client, bundle := newClient(t, config)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, jobStarted)
+
+		bundle.goroutines.Store(101)
+
+		// Released back to available without consuming an attempt, and not
+		// worked again while the budget is exceeded.
+		require.Eventually(t, func() bool {
+			job, err := client.JobGet(ctx, insertRes.Job.ID)
+			require.NoError(t, err)
+			return job.State == rivertype.JobStateAvailable
+		}, 5*time.Second, 10*time.Millisecond)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Zero(t, job.Attempt)
+		require.Empty(t, job.Errors)
+	})
+}
@@ -116,7 +116,7 @@ func resumableSetStepTx[TTx any, TArgs JobArgs](ctx context.Context, tx TTx, job
 	}
 
 	result := &Job[TArgs]{JobRow: updatedJob}
-	if err := json.Unmarshal(result.EncodedArgs, &result.Args); err != nil {
+	if err := client.argsDecoder.Decode(result.JobRow, &result.Args); err != nil {
 		return nil, err
 	}
 
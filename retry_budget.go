@@ -0,0 +1,53 @@
+package river
+
+import (
+	"errors"
+	"time"
+)
+
+// retryBudgetLimiterName is the name of the rate limit backing a client's
+// RetryBudget. It's shared by every client using the same database and schema.
+const retryBudgetLimiterName = "river:retry_budget"
+
+// RetryBudget caps the total number of job retries across every client using
+// the same database and schema. When a shared dependency fails, thousands of
+// jobs can error at once and be retried on similar schedules, producing a retry
+// storm that hammers both the database and the struggling dependency. With a
+// budget in place, retries beyond it aren't dropped, but are deferred until
+// budget becomes available.
+//
+// The budget is enforced with the same database-backed token bucket as
+// Client.Limiter, allowing up to Limit retries in a burst and refilling
+// continuously at Limit per Period. Each errored job takes a token when its
+// retry is scheduled, and a job that finds the budget exhausted has its retry
+// pushed back to when a token will have been refilled for it (or left as is if
+// its retry policy already schedules it later than that).
+//
+// Snoozes and jobs that are discarded or cancelled don't consume budget.
+type RetryBudget struct {
+	// Limit is the maximum number of retries to schedule per Period. Must be
+	// greater than zero.
+	Limit int
+
+	// Period is the period over which Limit applies.
+	//
+	// Defaults to one minute.
+	Period time.Duration
+}
+
+func (b *RetryBudget) validate() error {
+	if b.Limit <= 0 {
+		return errors.New("RetryBudget.Limit must be greater than zero")
+	}
+	if b.Period < 0 {
+		return errors.New("RetryBudget.Period cannot be less than zero")
+	}
+	return nil
+}
+
+func (b *RetryBudget) periodOrDefault() time.Duration {
+	if b.Period == 0 {
+		return time.Minute
+	}
+	return b.Period
+}
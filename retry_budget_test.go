@@ -0,0 +1,59 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestRetryBudget_validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&RetryBudget{Limit: 1}).validate())
+	require.NoError(t, (&RetryBudget{Limit: 100, Period: time.Second}).validate())
+
+	require.EqualError(t, (&RetryBudget{}).validate(), "RetryBudget.Limit must be greater than zero")
+	require.EqualError(t, (&RetryBudget{Limit: 1, Period: -1}).validate(), "RetryBudget.Period cannot be less than zero")
+}
+
+type retryBudgetArgs struct{}
+
+func (retryBudgetArgs) Kind() string { return "retry_budget" }
+
+func Test_Client_RetryBudget(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	config := newTestConfig(t, "")
+	config.RetryBudget = &RetryBudget{Limit: 1, Period: time.Hour}
+	AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[retryBudgetArgs]) error {
+		return errors.New("job error")
+	}))
+
+	client := runNewTestClient(ctx, t, config)
+
+	subscribeChan, cancel := client.Subscribe(EventKindJobFailed)
+	t.Cleanup(cancel)
+
+	_, err := client.InsertMany(ctx, []InsertManyParams{{Args: retryBudgetArgs{}}, {Args: retryBudgetArgs{}}})
+	require.NoError(t, err)
+
+	events := riversharedtest.WaitOrTimeoutN(t, subscribeChan, 2)
+
+	// The first retry is within budget and scheduled by the retry policy, but
+	// the budget is then exhausted, so the second is deferred until it's
+	// been refilled an hour later.
+	var numDeferred int
+	for _, event := range events {
+		if time.Until(event.Job.ScheduledAt) > 30*time.Minute {
+			numDeferred++
+		}
+	}
+	require.Equal(t, 1, numDeferred)
+}
@@ -0,0 +1,202 @@
+// Package riverclaimcheck provides a middleware that offloads large job args
+// to an external blob store like S3 or GCS, leaving only a reference to them
+// in the job row (the "claim check" pattern).
+package riverclaimcheck
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	deleteTimeout   = 30 * time.Second
+	keyPrefix       = "river/args/"
+	metadataKey     = "river:claim_check"
+	minBytesDefault = 256 * 1024
+)
+
+// placeholderArgs are the args stored in the job row in place of args that
+// were offloaded.
+var placeholderArgs = []byte("{}") //nolint:gochecknoglobals
+
+// BlobStore stores offloaded job args. Implementations are usually backed by
+// an object store like S3 or GCS.
+type BlobStore interface {
+	// Delete removes the blob stored under key. It's called after the job
+	// the blob belongs to completes. Deleting a blob that doesn't exist
+	// should not return an error.
+	Delete(ctx context.Context, key string) error
+
+	// Get retrieves the blob stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores data under key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Middleware offloads the args of inserted jobs to a BlobStore when their
+// encoded size reaches a threshold, storing a reference to the blob in the
+// job's metadata and placeholder args in the row. Before an offloaded job is
+// worked, its args are retrieved from the store so that the worker sees them
+// as usual, and after it completes successfully the blob is deleted.
+//
+// Middleware must be installed on the client (in Config.Middleware) so that
+// it's invoked both on insert and when jobs are worked.
+//
+// Args are offloaded in their final encoded form, so they're compressed or
+// encrypted first if the client is configured to do so. Unique keys for jobs
+// unique by args are derived from args before they're offloaded.
+//
+// Some caveats:
+//
+//   - Args are stored before the insert transaction commits, so a rolled
+//     back insert orphans its blob. Stores should be configured with a
+//     lifecycle policy to expire old blobs if this is a concern.
+//   - Blobs of jobs that are cancelled or discarded are kept so that the
+//     jobs can be retried, and are likewise only removed by a lifecycle
+//     policy.
+//   - Blobs are deleted once a job's worker returns successfully. If the
+//     job's completion then fails to be persisted and it's rescued to be
+//     worked again, its args can no longer be retrieved and it errors.
+//   - Features that read args directly from the job row, like
+//     Client.JobGet, see placeholder args (an empty JSON object) instead of
+//     the offloaded ones.
+type Middleware struct {
+	baseservice.BaseService
+	rivertype.Middleware
+
+	config *MiddlewareConfig
+	store  BlobStore
+}
+
+// MiddlewareConfig is configuration for Middleware.
+type MiddlewareConfig struct {
+	// KeyPrefix is a prefix for the keys that blobs are stored under.
+	//
+	// Defaults to "river/args/".
+	KeyPrefix string
+
+	// MinBytes is the minimum size of a job's encoded args for them to be
+	// offloaded to the blob store.
+	//
+	// Defaults to 256 KiB.
+	MinBytes int
+}
+
+// NewMiddleware initializes a new Middleware that offloads large args to the
+// given store.
+//
+//	riverclaimcheck.NewMiddleware(store, &riverclaimcheck.MiddlewareConfig{
+//		MinBytes: 64 * 1024,
+//	})
+func NewMiddleware(store BlobStore, config *MiddlewareConfig) *Middleware {
+	if config == nil {
+		config = &MiddlewareConfig{}
+	}
+
+	return &Middleware{
+		config: &MiddlewareConfig{
+			KeyPrefix: cmp.Or(config.KeyPrefix, keyPrefix),
+			MinBytes:  cmp.Or(config.MinBytes, minBytesDefault),
+		},
+		store: store,
+	}
+}
+
+func (m *Middleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(ctx context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	// Keys of offloaded blobs, indexed the same as manyParams.
+	keys := make([]string, len(manyParams))
+
+	for i, params := range manyParams {
+		if len(params.EncodedArgs) < m.config.MinBytes {
+			continue
+		}
+
+		key, err := m.newKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.store.Put(ctx, key, params.EncodedArgs); err != nil {
+			return nil, fmt.Errorf("error storing args in blob store: %w", err)
+		}
+
+		metadata, err := sjson.SetBytes(params.Metadata, metadataKey, key)
+		if err != nil {
+			return nil, fmt.Errorf("error setting claim check in metadata: %w", err)
+		}
+
+		keys[i] = key
+		params.EncodedArgs = placeholderArgs
+		params.Metadata = metadata
+	}
+
+	results, err := doInner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs skipped as unique duplicates were never inserted, so their blobs
+	// aren't referenced by anything.
+	for i, result := range results {
+		if result.UniqueSkippedAsDuplicate && keys[i] != "" {
+			m.deleteBlob(ctx, keys[i])
+		}
+	}
+
+	return results, nil
+}
+
+func (m *Middleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	key := gjson.GetBytes(job.Metadata, metadataKey).String()
+	if key == "" {
+		return doInner(ctx)
+	}
+
+	encodedArgs, err := m.store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error retrieving args from blob store: %w", err)
+	}
+	job.EncodedArgs = encodedArgs
+
+	if err := doInner(ctx); err != nil {
+		return err
+	}
+
+	m.deleteBlob(ctx, key)
+
+	return nil
+}
+
+// deleteBlob deletes the blob stored under key, logging rather than returning
+// an error because the operation it's cleaning up after already succeeded.
+func (m *Middleware) deleteBlob(ctx context.Context, key string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deleteTimeout)
+	defer cancel()
+
+	if err := m.store.Delete(ctx, key); err != nil {
+		m.Logger.WarnContext(ctx, m.Name+": Error deleting args from blob store",
+			slog.String("error", err.Error()),
+			slog.String("key", key),
+		)
+	}
+}
+
+func (m *Middleware) newKey() (string, error) {
+	var randBytes [16]byte
+	if _, err := rand.Read(randBytes[:]); err != nil {
+		return "", fmt.Errorf("error generating blob key: %w", err)
+	}
+	return m.config.KeyPrefix + hex.EncodeToString(randBytes[:]), nil
+}
@@ -0,0 +1,256 @@
+package riverclaimcheck
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// memoryBlobStore is a BlobStore that keeps blobs in memory.
+type memoryBlobStore struct {
+	blobs map[string][]byte
+	mu    sync.Mutex
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+func (s *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *memoryBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return data, nil
+}
+
+func (s *memoryBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memoryBlobStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.blobs)
+}
+
+type claimCheckArgs struct {
+	Payload string `json:"payload"`
+}
+
+func (claimCheckArgs) Kind() string { return "claim_check" }
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		store *memoryBlobStore
+	}
+
+	setup := func(t *testing.T) (*Middleware, *testBundle) {
+		t.Helper()
+
+		store := newMemoryBlobStore()
+		middleware := NewMiddleware(store, &MiddlewareConfig{MinBytes: 100})
+		baseservice.Init(riversharedtest.BaseServiceArchetype(t), middleware)
+
+		return middleware, &testBundle{store: store}
+	}
+
+	largeArgs := []byte(`{"payload":"` + strings.Repeat("x", 100) + `"}`)
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		middleware := NewMiddleware(newMemoryBlobStore(), nil)
+		require.Equal(t, keyPrefix, middleware.config.KeyPrefix)
+		require.Equal(t, minBytesDefault, middleware.config.MinBytes)
+	})
+
+	t.Run("OffloadsLargeArgs", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		var (
+			largeParams = &rivertype.JobInsertParams{EncodedArgs: largeArgs, Metadata: []byte(`{"foo":"bar"}`)}
+			smallParams = &rivertype.JobInsertParams{EncodedArgs: []byte(`{"payload":"small"}`), Metadata: []byte(`{}`)}
+		)
+
+		_, err := middleware.InsertMany(ctx, []*rivertype.JobInsertParams{largeParams, smallParams}, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return []*rivertype.JobInsertResult{{Job: &rivertype.JobRow{}}, {Job: &rivertype.JobRow{}}}, nil
+		})
+		require.NoError(t, err)
+
+		key := gjson.GetBytes(largeParams.Metadata, metadataKey).String()
+		require.True(t, strings.HasPrefix(key, keyPrefix))
+		require.Equal(t, "bar", gjson.GetBytes(largeParams.Metadata, "foo").String())
+		require.Equal(t, placeholderArgs, largeParams.EncodedArgs)
+
+		blob, err := bundle.store.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, largeArgs, blob)
+
+		require.JSONEq(t, `{"payload":"small"}`, string(smallParams.EncodedArgs))
+		require.False(t, gjson.GetBytes(smallParams.Metadata, metadataKey).Exists())
+		require.Equal(t, 1, bundle.store.len())
+	})
+
+	t.Run("DeletesBlobOfUniqueSkippedDuplicate", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		_, err := middleware.InsertMany(ctx, []*rivertype.JobInsertParams{{EncodedArgs: largeArgs, Metadata: []byte(`{}`)}}, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return []*rivertype.JobInsertResult{{Job: &rivertype.JobRow{}, UniqueSkippedAsDuplicate: true}}, nil
+		})
+		require.NoError(t, err)
+		require.Zero(t, bundle.store.len())
+	})
+
+	t.Run("InsertErrorReturned", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t)
+
+		_, err := middleware.InsertMany(ctx, []*rivertype.JobInsertParams{{EncodedArgs: largeArgs, Metadata: []byte(`{}`)}}, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, errors.New("insert error")
+		})
+		require.EqualError(t, err, "insert error")
+	})
+
+	t.Run("RetrievesArgsAndDeletesOnSuccess", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		require.NoError(t, bundle.store.Put(ctx, "key", largeArgs))
+
+		job := &rivertype.JobRow{EncodedArgs: placeholderArgs, Metadata: []byte(`{"river:claim_check":"key"}`)}
+
+		err := middleware.Work(ctx, job, func(ctx context.Context) error {
+			require.Equal(t, largeArgs, job.EncodedArgs)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Zero(t, bundle.store.len())
+	})
+
+	t.Run("KeepsBlobOnError", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		require.NoError(t, bundle.store.Put(ctx, "key", largeArgs))
+
+		job := &rivertype.JobRow{EncodedArgs: placeholderArgs, Metadata: []byte(`{"river:claim_check":"key"}`)}
+
+		err := middleware.Work(ctx, job, func(ctx context.Context) error {
+			return errors.New("work error")
+		})
+		require.EqualError(t, err, "work error")
+		require.Equal(t, 1, bundle.store.len())
+	})
+
+	t.Run("BlobNotFoundError", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t)
+
+		job := &rivertype.JobRow{EncodedArgs: placeholderArgs, Metadata: []byte(`{"river:claim_check":"key"}`)}
+
+		err := middleware.Work(ctx, job, func(ctx context.Context) error {
+			require.FailNow(t, "doInner should not have been called")
+			return nil
+		})
+		require.EqualError(t, err, "error retrieving args from blob store: blob not found")
+	})
+
+	t.Run("WorksJobWithoutClaimCheck", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t)
+
+		job := &rivertype.JobRow{EncodedArgs: []byte(`{"payload":"small"}`), Metadata: []byte(`{}`)}
+
+		var called bool
+		require.NoError(t, middleware.Work(ctx, job, func(ctx context.Context) error {
+			called = true
+			return nil
+		}))
+		require.True(t, called)
+		require.JSONEq(t, `{"payload":"small"}`, string(job.EncodedArgs))
+	})
+}
+
+func TestMiddlewareWithClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		dbPool       = riversharedtest.DBPool(ctx, t)
+		driver       = riverpgxv5.New(dbPool)
+		schema       = riverdbtest.TestSchema(ctx, t, driver, nil)
+		store        = newMemoryBlobStore()
+		workedChan   = make(chan claimCheckArgs, 1)
+		workers      = river.NewWorkers()
+		largePayload = strings.Repeat("x", 100)
+	)
+
+	river.AddWorker(workers, river.WorkFunc(func(ctx context.Context, job *river.Job[claimCheckArgs]) error {
+		workedChan <- job.Args
+		return nil
+	}))
+
+	client, err := river.NewClient[pgx.Tx](driver, &river.Config{
+		Logger:     riversharedtest.Logger(t),
+		Middleware: []rivertype.Middleware{NewMiddleware(store, &MiddlewareConfig{MinBytes: 100})},
+		Queues:     map[string]river.QueueConfig{river.QueueDefault: {MaxWorkers: 1}},
+		Schema:     schema,
+		TestOnly:   true,
+		Workers:    workers,
+	})
+	require.NoError(t, err)
+
+	insertRes, err := client.Insert(ctx, claimCheckArgs{Payload: largePayload}, nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(insertRes.Job.EncodedArgs))
+	require.Equal(t, 1, store.len())
+
+	require.NoError(t, client.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, client.Stop(ctx)) })
+
+	require.Equal(t, claimCheckArgs{Payload: largePayload}, riversharedtest.WaitOrTimeout(t, workedChan))
+}
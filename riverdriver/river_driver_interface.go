@@ -34,6 +34,19 @@ const MigrationLineMain = "main"
 var (
 	ErrClosedPool     = errors.New("underlying driver pool is closed")
 	ErrNotImplemented = errors.New("driver does not implement this functionality")
+
+	// ErrStatementTimeout is wrapped by errors returned when a database
+	// cancels a statement because it exceeded a statement timeout or a lock
+	// timeout, like Postgres' `statement_timeout` and `lock_timeout`.
+	ErrStatementTimeout = errors.New("statement exceeded database timeout")
+
+	// ErrTransient is wrapped by errors returned when an operation failed for
+	// a reason that's expected to be temporary and which guarantees that the
+	// operation had no effect, like a serialization failure, a deadlock, or a
+	// connection error that occurred before anything was sent to the database.
+	// Operations that fail with it may be safely retried if they weren't part
+	// of a transaction.
+	ErrTransient = errors.New("transient database error")
 )
 
 // Driver provides a database driver for use with river.Client.
@@ -128,6 +141,12 @@ type Driver[TTx any] interface {
 	// API is not stable. DO NOT USE.
 	PoolSet(dbPool any) error
 
+	// PoolStats returns point-in-time statistics about the driver's database
+	// pool, or nil if the driver has no pool.
+	//
+	// API is not stable. DO NOT USE.
+	PoolStats() *PoolStats
+
 	// SQLFragmentColumnIn generates an SQL fragment to be included as a
 	// predicate in a `WHERE` query for the existence of a set of values in a
 	// column like `id IN (...)`. The actual implementation depends on support
@@ -207,29 +226,112 @@ type Executor interface {
 	// API is not stable. DO NOT USE.
 	IndexReindex(ctx context.Context, params *IndexReindexParams) error
 
+	// JobActivateMany moves the given jobs out of the `pending` state, setting
+	// their scheduled time to ScheduledAt. Jobs are made `available` if
+	// ScheduledAt has already passed, and `scheduled` otherwise. Jobs that
+	// aren't `pending` are left untouched and aren't returned.
+	JobActivateMany(ctx context.Context, params *JobActivateManyParams) ([]*rivertype.JobRow, error)
+
 	JobCancel(ctx context.Context, params *JobCancelParams) (*rivertype.JobRow, error)
+
+	// JobChangeQueueMany moves up to Max non-running jobs matching WhereClause
+	// to Queue. Jobs already in Queue are never matched so that callers can
+	// move jobs in batches by invoking it until no jobs are returned.
+	JobChangeQueueMany(ctx context.Context, params *JobChangeQueueManyParams) ([]*rivertype.JobRow, error)
+
 	JobCountByAllStates(ctx context.Context, params *JobCountByAllStatesParams) (map[rivertype.JobState]int, error)
 	JobCountByQueueAndState(ctx context.Context, params *JobCountByQueueAndStateParams) ([]*JobCountByQueueAndStateResult, error)
 	JobCountByState(ctx context.Context, params *JobCountByStateParams) (int, error)
+
+	// JobCountMatching counts jobs matching the given where clause, stopping
+	// at Max so that counting a large number of matches stays bounded.
+	JobCountMatching(ctx context.Context, params *JobCountMatchingParams) (int, error)
+
+	// JobCountMatchingEstimate estimates the number of jobs matching the given
+	// where clause using the database's query planner, which is fast
+	// regardless of how many jobs match, but may be inaccurate. Max is
+	// ignored.
+	//
+	// Drivers for databases that can't produce an estimate return
+	// ErrNotImplemented.
+	JobCountMatchingEstimate(ctx context.Context, params *JobCountMatchingParams) (int, error)
+
+	JobCountRunningByClient(ctx context.Context, params *JobCountRunningByClientParams) ([]*JobCountRunningByClientResult, error)
 	JobDelete(ctx context.Context, params *JobDeleteParams) (*rivertype.JobRow, error)
 	JobDeleteBefore(ctx context.Context, params *JobDeleteBeforeParams) (int, error)
 	JobDeleteMany(ctx context.Context, params *JobDeleteManyParams) ([]*rivertype.JobRow, error)
+
+	// JobGetArgsEncryptionKeyStale gets jobs with args encrypted with a key
+	// other than the current one, ordered by ID and starting after AfterID.
+	JobGetArgsEncryptionKeyStale(ctx context.Context, params *JobGetArgsEncryptionKeyStaleParams) ([]*rivertype.JobRow, error)
+
 	JobGetAvailable(ctx context.Context, params *JobGetAvailableParams) ([]*rivertype.JobRow, error)
+
+	// JobGetByExternalID gets a job by the external ID recorded in its
+	// metadata. Returns rivertype.ErrNotFound if there's no such job.
+	JobGetByExternalID(ctx context.Context, params *JobGetByExternalIDParams) (*rivertype.JobRow, error)
+
 	JobGetByID(ctx context.Context, params *JobGetByIDParams) (*rivertype.JobRow, error)
 	JobGetByIDMany(ctx context.Context, params *JobGetByIDManyParams) ([]*rivertype.JobRow, error)
 	JobGetByKindMany(ctx context.Context, params *JobGetByKindManyParams) ([]*rivertype.JobRow, error)
+	JobGetLeaseExpired(ctx context.Context, params *JobGetLeaseExpiredParams) ([]*rivertype.JobRow, error)
 	JobGetStuck(ctx context.Context, params *JobGetStuckParams) ([]*rivertype.JobRow, error)
 	JobInsertFastMany(ctx context.Context, params *JobInsertFastManyParams) ([]*JobInsertFastResult, error)
+
+	// JobInsertFastManyCopyFrom inserts jobs like JobInsertFastMany, but
+	// sends them to the database with COPY FROM, which is considerably faster
+	// and uses less memory for very large batches. Jobs are copied into a
+	// temporary staging table before being moved into the jobs table so that
+	// unique conflicts are handled and inserted jobs are returned the same as
+	// with JobInsertFastMany.
+	//
+	// Jobs with a ScheduledIn have their scheduled time computed from the
+	// database's clock, which COPY FROM can't do, so batches containing any
+	// fall back to JobInsertFastMany.
+	//
+	// Drivers that don't support COPY FROM return ErrNotImplemented.
+	JobInsertFastManyCopyFrom(ctx context.Context, params *JobInsertFastManyParams) ([]*JobInsertFastResult, error)
+
 	JobInsertFastManyNoReturning(ctx context.Context, params *JobInsertFastManyParams) (int, error)
 	JobInsertFull(ctx context.Context, params *JobInsertFullParams) (*rivertype.JobRow, error)
 	JobInsertFullMany(ctx context.Context, jobs *JobInsertFullManyParams) ([]*rivertype.JobRow, error)
 	JobKindList(ctx context.Context, params *JobKindListParams) ([]string, error)
+
+	// JobKindPause pauses a job kind so that jobs of the kind aren't fetched
+	// from any queue until it's resumed. Pausing a kind that's already paused
+	// is a no-op.
+	JobKindPause(ctx context.Context, params *JobKindPauseParams) error
+
+	// JobKindRegistrationList lists the job kinds published to the job kind
+	// registry, ordered by kind and version.
+	JobKindRegistrationList(ctx context.Context, params *JobKindRegistrationListParams) ([]*JobKindRegistration, error)
+
+	// JobKindRegistrationUpsertMany publishes job kinds to the job kind
+	// registry. A registration that already exists for the same kind and
+	// version has its args schema, client ID, and updated at timestamp
+	// replaced.
+	JobKindRegistrationUpsertMany(ctx context.Context, params *JobKindRegistrationUpsertManyParams) error
+
+	// JobKindResume resumes a job kind previously paused with JobKindPause.
+	// Resuming a kind that isn't paused is a no-op.
+	JobKindResume(ctx context.Context, params *JobKindResumeParams) error
+
+	JobKindStorageUsage(ctx context.Context, params *JobKindStorageUsageParams) ([]*JobKindStorageUsageResult, error)
+	JobLeaseRenewMany(ctx context.Context, params *JobLeaseRenewManyParams) error
 	JobList(ctx context.Context, params *JobListParams) ([]*rivertype.JobRow, error)
 	JobRescueMany(ctx context.Context, params *JobRescueManyParams) (*struct{}, error)
 	JobRetry(ctx context.Context, params *JobRetryParams) (*rivertype.JobRow, error)
 	JobSchedule(ctx context.Context, params *JobScheduleParams) ([]*JobScheduleResult, error)
 	JobSetStateIfRunningMany(ctx context.Context, params *JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error)
 	JobUpdate(ctx context.Context, params *JobUpdateParams) (*rivertype.JobRow, error)
+
+	// JobUpdateArgsEncryptionKey replaces a job's args with args encrypted
+	// with a new key and records the new key in its metadata, but only if its
+	// args are still encrypted with PreviousKeyID. Returns
+	// rivertype.ErrNotFound if the job doesn't exist or its args were
+	// encrypted with a different key.
+	JobUpdateArgsEncryptionKey(ctx context.Context, params *JobUpdateArgsEncryptionKeyParams) (*rivertype.JobRow, error)
+
 	JobUpdateFull(ctx context.Context, params *JobUpdateFullParams) (*rivertype.JobRow, error)
 	LeaderAttemptElect(ctx context.Context, params *LeaderElectParams) (*Leader, error)
 	LeaderAttemptReelect(ctx context.Context, params *LeaderReelectParams) (*Leader, error)
@@ -238,6 +340,16 @@ type Executor interface {
 	LeaderInsert(ctx context.Context, params *LeaderInsertParams) (*Leader, error)
 	LeaderResign(ctx context.Context, params *LeaderResignParams) (bool, error)
 
+	// MaintenanceStatusList lists the most recent run of each maintenance
+	// service, ordered by service name.
+	MaintenanceStatusList(ctx context.Context, params *MaintenanceStatusListParams) ([]*MaintenanceStatus, error)
+
+	// MaintenanceStatusRecordRun records a run of a maintenance service,
+	// replacing its previous run. Consecutive failures are counted and the last
+	// successful run is kept so that a service that's been failing for some
+	// time can be distinguished from one with a single failure.
+	MaintenanceStatusRecordRun(ctx context.Context, params *MaintenanceStatusRecordRunParams) (*MaintenanceStatus, error)
+
 	// MigrationDeleteAssumingMainMany deletes many migrations assuming
 	// everything is on the main line. This is suitable for use in databases on
 	// a version before the `line` column exists.
@@ -274,6 +386,20 @@ type Executor interface {
 	NotifyMany(ctx context.Context, params *NotifyManyParams) error
 	PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}, error)
 
+	// PGTransactionTimeoutsGet gets the lock and statement timeouts in effect
+	// for the current session or transaction. Drivers for databases other than
+	// Postgres return ErrNotImplemented.
+	PGTransactionTimeoutsGet(ctx context.Context) (*PGTransactionTimeouts, error)
+
+	// PGTransactionTimeoutsSet sets lock and statement timeouts for the rest of
+	// the current transaction. An empty timeout leaves its setting unchanged.
+	// Drivers for databases other than Postgres return ErrNotImplemented.
+	PGTransactionTimeoutsSet(ctx context.Context, timeouts *PGTransactionTimeouts) error
+
+	// Ping checks that the database can be reached by running a trivial
+	// query.
+	Ping(ctx context.Context) error
+
 	QueueCreateOrSetUpdatedAt(ctx context.Context, params *QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error)
 	QueueDeleteExpired(ctx context.Context, params *QueueDeleteExpiredParams) ([]string, error)
 	QueueGet(ctx context.Context, params *QueueGetParams) (*rivertype.Queue, error)
@@ -284,6 +410,13 @@ type Executor interface {
 	QueueUpdate(ctx context.Context, params *QueueUpdateParams) (*rivertype.Queue, error)
 	QueryRow(ctx context.Context, sql string, args ...any) Row
 
+	// RateLimitReserve takes a token from the named rate limit's token bucket,
+	// which is refilled at Rate tokens per second up to a maximum of Burst, and
+	// returns the number of tokens remaining. The result is negative if no
+	// token was available, in which case the caller should wait until enough
+	// tokens to cover the deficit would have been refilled.
+	RateLimitReserve(ctx context.Context, params *RateLimitReserveParams) (float64, error)
+
 	SchemaCreate(ctx context.Context, params *SchemaCreateParams) error
 	SchemaDrop(ctx context.Context, params *SchemaDropParams) error
 	SchemaGetExpired(ctx context.Context, params *SchemaGetExpiredParams) ([]string, error)
@@ -357,12 +490,34 @@ type IndexesExistParams struct {
 	Schema     string
 }
 
+type JobActivateManyParams struct {
+	ID          []int64
+	Now         *time.Time
+	ScheduledAt time.Time
+	Schema      string
+}
+
 type JobCancelParams struct {
 	ID                int64
 	CancelAttemptedAt time.Time
 	ControlTopic      string
 	Now               *time.Time
-	Schema            string
+
+	// Reason is an optional human-readable explanation for the cancellation.
+	// When non-empty, it's stored in the job's metadata as `cancel_reason` and
+	// included in the control notification sent to the client working the job.
+	Reason string
+
+	Schema string
+}
+
+type JobChangeQueueManyParams struct {
+	Max           int32
+	NamedArgs     map[string]any
+	OrderByClause string
+	Queue         string
+	Schema        string
+	WhereClause   string
 }
 
 type JobCountByAllStatesParams struct {
@@ -376,8 +531,14 @@ type JobCountByQueueAndStateParams struct {
 
 type JobCountByQueueAndStateResult struct {
 	CountAvailable int64
-	CountRunning   int64
-	Queue          string
+
+	// CountClientsWithRunningJobs is the number of distinct clients with at
+	// least one job running in the queue, as determined by the last entry in
+	// each running job's `attempted_by`. Idle clients aren't counted.
+	CountClientsWithRunningJobs int64
+
+	CountRunning int64
+	Queue        string
 }
 
 type JobCountByStateParams struct {
@@ -385,6 +546,26 @@ type JobCountByStateParams struct {
 	State  rivertype.JobState
 }
 
+type JobCountMatchingParams struct {
+	Max         int32
+	NamedArgs   map[string]any
+	Schema      string
+	WhereClause string
+}
+
+type JobCountRunningByClientParams struct {
+	Schema string
+}
+
+// JobCountRunningByClientResult is the number of jobs being run by a single
+// client, as determined by the last entry in each running job's
+// `attempted_by`.
+type JobCountRunningByClientResult struct {
+	ClientID        string
+	CountRunning    int64
+	LastAttemptedAt time.Time
+}
+
 type JobDeleteParams struct {
 	ID     int64
 	Schema string
@@ -411,14 +592,51 @@ type JobDeleteManyParams struct {
 	WhereClause   string
 }
 
+type JobGetArgsEncryptionKeyStaleParams struct {
+	AfterID      int64
+	CurrentKeyID string
+	Max          int
+	Schema       string
+}
+
 type JobGetAvailableParams struct {
-	ClientID       string
+	ClientID string
+
+	// FairnessKey is a metadata key whose values fetched jobs are interleaved
+	// across so that jobs with one value can't crowd out jobs with others.
+	// Fetching isn't fair when empty.
+	FairnessKey string
+
+	// FairnessWeights are relative weights for values of FairnessKey. Values
+	// that don't appear have a weight of 1.
+	FairnessWeights map[string]int
+
+	// KindsExcluded are job kinds that won't be fetched, like kinds that have
+	// reached their concurrency limit in the fetching producer.
+	KindsExcluded []string
+
+	// Labels are the fetching client's labels. Jobs with required labels in
+	// their metadata are only fetched if all of them appear here with
+	// matching values.
+	Labels map[string]string
+
 	MaxAttemptedBy int
 	MaxToLock      int
-	Now            *time.Time
-	ProducerID     int64
-	Queue          string
-	Schema         string
+
+	// MaxToLockByKind limits the number of jobs of particular kinds that are
+	// locked, like kinds with limited concurrency in the fetching producer.
+	// Kinds that don't appear are limited only by MaxToLock.
+	MaxToLockByKind map[string]int
+
+	Now        *time.Time
+	ProducerID int64
+	Queue      string
+	Schema     string
+}
+
+type JobGetByExternalIDParams struct {
+	ExternalID string
+	Schema     string
 }
 
 type JobGetByIDParams struct {
@@ -436,8 +654,18 @@ type JobGetByKindManyParams struct {
 	Schema string
 }
 
+// JobGetLeaseExpiredParams are parameters for JobGetLeaseExpired, which gets
+// running jobs in a queue whose lease (as set by JobLeaseRenewMany) has expired.
+type JobGetLeaseExpiredParams struct {
+	Max    int
+	Now    time.Time
+	Queue  string
+	Schema string
+}
+
 type JobGetStuckParams struct {
 	Max          int
+	Now          time.Time
 	Schema       string
 	StuckHorizon time.Time
 }
@@ -456,6 +684,7 @@ type JobInsertFastParams struct {
 	Priority     int
 	Queue        string
 	ScheduledAt  *time.Time
+	ScheduledIn  time.Duration
 	State        rivertype.JobState
 	Tags         []string
 	UniqueKey    []byte
@@ -506,6 +735,71 @@ type JobKindListParams struct {
 	Schema  string
 }
 
+type JobKindPauseParams struct {
+	Kind   string
+	Now    *time.Time
+	Schema string
+}
+
+// JobKindRegistration is a job kind and version published to the job kind
+// registry by a client able to work it.
+type JobKindRegistration struct {
+	ArgsSchema []byte
+	ClientID   string
+	CreatedAt  time.Time
+	Kind       string
+	UpdatedAt  time.Time
+	Version    string
+}
+
+type JobKindRegistrationListParams struct {
+	Schema string
+}
+
+type JobKindRegistrationUpsertParams struct {
+	ArgsSchema []byte
+	Kind       string
+	Version    string
+}
+
+type JobKindRegistrationUpsertManyParams struct {
+	ClientID      string
+	Now           *time.Time
+	Registrations []*JobKindRegistrationUpsertParams
+	Schema        string
+}
+
+type JobKindResumeParams struct {
+	Kind   string
+	Schema string
+}
+
+type JobKindStorageUsageParams struct {
+	Schema string
+}
+
+// JobKindStorageUsageResult is the storage used by jobs of a single kind. Byte
+// counts are the sum of each column's stored size, which may be compressed.
+type JobKindStorageUsageResult struct {
+	ArgsBytes     int64
+	Count         int64
+	ErrorsBytes   int64
+	Kind          string
+	MetadataBytes int64
+}
+
+// JobLeaseRenewManyParams are parameters for JobLeaseRenewMany, which sets the
+// lease expiry of running jobs. Attempt must be the same length as ID, and a
+// job's lease is only renewed if its attempt still matches, which prevents a
+// worker from renewing the lease of a job that's since expired and been
+// fetched again elsewhere.
+type JobLeaseRenewManyParams struct {
+	Attempt        []int
+	ID             []int64
+	LeaseExpiresAt time.Time
+	Schema         string
+}
+
 type JobListParams struct {
 	Max           int32
 	NamedArgs     map[string]any
@@ -657,8 +951,18 @@ type JobUpdateParams struct {
 	Schema          string
 }
 
+type JobUpdateArgsEncryptionKeyParams struct {
+	Args          []byte
+	ID            int64
+	KeyID         string
+	PreviousKeyID string
+	Schema        string
+}
+
 type JobUpdateFullParams struct {
 	ID                  int64
+	ArgsDoUpdate        bool
+	Args                []byte
 	AttemptDoUpdate     bool
 	Attempt             int
 	AttemptedAtDoUpdate bool
@@ -731,6 +1035,32 @@ type LeaderResignParams struct {
 	Schema          string
 }
 
+// MaintenanceStatus is the most recent run of a maintenance service.
+type MaintenanceStatus struct {
+	ClientID            string
+	ConsecutiveFailures int
+	Error               *string
+	FinishedAt          time.Time
+	LastSucceededAt     *time.Time
+	Name                string
+	NumRows             int
+	StartedAt           time.Time
+}
+
+type MaintenanceStatusListParams struct {
+	Schema string
+}
+
+type MaintenanceStatusRecordRunParams struct {
+	ClientID   string
+	Error      *string
+	FinishedAt time.Time
+	Name       string
+	NumRows    int
+	Schema     string
+	StartedAt  time.Time
+}
+
 // Migration represents a River migration.
 //
 // API is not stable. DO NOT USE.
@@ -795,6 +1125,13 @@ type NotificationDeleteBeforeParams struct {
 	Schema           string
 }
 
+// PGTransactionTimeouts are Postgres' `lock_timeout` and `statement_timeout`
+// settings, in any format accepted by Postgres like "1s" or "500ms".
+type PGTransactionTimeouts struct {
+	LockTimeout      string
+	StatementTimeout string
+}
+
 type ProducerKeepAliveParams struct {
 	ID                    int64
 	QueueName             string
@@ -802,6 +1139,31 @@ type ProducerKeepAliveParams struct {
 	StaleUpdatedAtHorizon time.Time
 }
 
+// PoolStats are statistics about a driver's database pool.
+type PoolStats struct {
+	// NumAcquired is the number of connections currently checked out of the
+	// pool.
+	NumAcquired int
+
+	// NumMax is the maximum number of connections that the pool may hold, or
+	// zero if the pool is unbounded.
+	NumMax int
+
+	// NumWaits is the cumulative number of connection acquisitions that had to
+	// wait because every connection was checked out.
+	NumWaits int64
+
+	// WaitDuration is the cumulative time spent by connection acquisitions
+	// waiting because every connection was checked out.
+	WaitDuration time.Duration
+}
+
+// Exhausted returns true if every connection the pool may hold is checked
+// out, so that new operations have to wait for one to be returned.
+func (s *PoolStats) Exhausted() bool {
+	return s.NumMax > 0 && s.NumAcquired >= s.NumMax
+}
+
 type QueueCreateOrSetUpdatedAtParams struct {
 	Metadata  []byte
 	Name      string
@@ -854,6 +1216,14 @@ type QueueUpdateParams struct {
 	Schema           string
 }
 
+type RateLimitReserveParams struct {
+	Burst  float64
+	Name   string
+	Now    *time.Time
+	Rate   float64
+	Schema string
+}
+
 type Row interface {
 	Scan(dest ...any) error
 }
@@ -906,7 +1276,7 @@ func MigrationLineMainTruncateTables(version int) []string {
 	case 5, 6:
 		return []string{"river_job", "river_leader", "river_queue", "river_client", "river_client_queue"}
 	case 0, 7:
-		return []string{"river_job", "river_leader", "river_queue", "river_notification"}
+		return []string{"river_job", "river_leader", "river_queue", "river_notification", "river_rate_limit", "river_job_kind", "river_job_kind_pause", "river_maintenance_status"}
 	}
 
 	panic(fmt.Sprintf("unrecognized migration version: %d", version))
@@ -5,6 +5,7 @@
 package dbsqlc
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"time"
@@ -79,6 +80,20 @@ type RiverJob struct {
 	UniqueStates *int
 }
 
+type RiverJobKind struct {
+	Kind       string
+	Version    string
+	ArgsSchema string
+	ClientID   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RiverJobKindPause struct {
+	Kind     string
+	PausedAt time.Time
+}
+
 type RiverLeader struct {
 	ElectedAt time.Time
 	ExpiresAt time.Time
@@ -86,6 +101,17 @@ type RiverLeader struct {
 	Name      string
 }
 
+type RiverMaintenanceStatus struct {
+	Name                string
+	ClientID            string
+	ConsecutiveFailures int32
+	Error               sql.NullString
+	FinishedAt          time.Time
+	LastSucceededAt     *time.Time
+	NumRows             int64
+	StartedAt           time.Time
+}
+
 type RiverMigration struct {
 	Line      string
 	Version   int64
@@ -106,3 +132,9 @@ type RiverQueue struct {
 	PausedAt  *time.Time
 	UpdatedAt time.Time
 }
+
+type RiverRateLimit struct {
+	Name      string
+	Tokens    float64
+	UpdatedAt time.Time
+}
@@ -13,6 +13,64 @@ import (
 	"github.com/lib/pq"
 )
 
+const jobActivateMany = `-- name: JobActivateMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    scheduled_at = $1::timestamptz,
+    state = CASE WHEN $1::timestamptz <= coalesce($2::timestamptz, now()) THEN 'available' ELSE 'scheduled' END
+WHERE id = any($3::bigint[])
+    AND state = 'pending'
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+`
+
+type JobActivateManyParams struct {
+	ScheduledAt time.Time
+	Now         *time.Time
+	ID          []int64
+}
+
+func (q *Queries) JobActivateMany(ctx context.Context, db DBTX, arg *JobActivateManyParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobActivateMany, arg.ScheduledAt, arg.Now, pq.Array(arg.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobCancel = `-- name: JobCancel :one
 WITH locked_job AS (
     SELECT
@@ -26,7 +84,7 @@ notification AS (
         id,
         pg_notify(
             concat(coalesce($2::text, current_schema()), '.', $3::text),
-            json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text
+            json_strip_nulls(json_build_object('action', 'cancel', 'job_id', id, 'queue', queue, 'reason', nullif($4::text, ''), 'v', 1))::text
         )
     FROM
         locked_job
@@ -40,10 +98,11 @@ updated_job AS (
         -- If the job is actively running, we want to let its current client and
         -- producer handle the cancellation. Otherwise, immediately cancel it.
         state = CASE WHEN state = 'running' THEN state ELSE 'cancelled' END,
-        finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE coalesce($4::timestamptz, now()) END,
+        finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE coalesce($5::timestamptz, now()) END,
         -- Mark the job as cancelled by query so that the rescuer knows not to
         -- rescue it, even if it gets stuck in the running state:
-        metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], $5::jsonb, true)
+        metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], $6::jsonb, true)
+            || CASE WHEN $4::text = '' THEN '{}'::jsonb ELSE jsonb_build_object('cancel_reason', $4::text) END
     FROM notification
     WHERE river_job.id = notification.id
     RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
@@ -61,6 +120,7 @@ type JobCancelParams struct {
 	ID                int64
 	Schema            sql.NullString
 	ControlTopic      string
+	Reason            string
 	Now               *time.Time
 	CancelAttemptedAt string
 }
@@ -70,6 +130,7 @@ func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams)
 		arg.ID,
 		arg.Schema,
 		arg.ControlTopic,
+		arg.Reason,
 		arg.Now,
 		arg.CancelAttemptedAt,
 	)
@@ -97,6 +158,76 @@ func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams)
 	return &i, err
 }
 
+const jobChangeQueueMany = `-- name: JobChangeQueueMany :many
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+        AND queue != $1::text
+        AND state != 'running'
+    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+    LIMIT $2::int
+    FOR UPDATE
+    SKIP LOCKED
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET queue = $1::text
+    WHERE id IN (SELECT id FROM jobs_to_update)
+    RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM updated_jobs
+ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+`
+
+type JobChangeQueueManyParams struct {
+	Queue string
+	Max   int32
+}
+
+func (q *Queries) JobChangeQueueMany(ctx context.Context, db DBTX, arg *JobChangeQueueManyParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobChangeQueueMany, arg.Queue, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobCountByAllStates = `-- name: JobCountByAllStates :many
 SELECT state, count(*)
 FROM /* TEMPLATE: schema */ river_job
@@ -139,7 +270,9 @@ WITH all_queues AS (
 running_job_counts AS (
     SELECT
         queue,
-        COUNT(*) AS count
+        COUNT(*) AS count,
+        -- The client working a job is the last one to have attempted it.
+        COUNT(DISTINCT attempted_by[array_length(attempted_by, 1)]) AS count_clients_with_running_jobs
     FROM /* TEMPLATE: schema */river_job
     WHERE queue = ANY($1::text[])
         AND state = 'running'
@@ -160,7 +293,8 @@ available_job_counts AS (
 SELECT
     all_queues.queue,
     COALESCE(available_job_counts.count, 0) AS count_available,
-    COALESCE(running_job_counts.count, 0) AS count_running
+    COALESCE(running_job_counts.count, 0) AS count_running,
+    COALESCE(running_job_counts.count_clients_with_running_jobs, 0) AS count_clients_with_running_jobs
 FROM
     all_queues
 LEFT JOIN
@@ -171,9 +305,10 @@ ORDER BY all_queues.queue ASC
 `
 
 type JobCountByQueueAndStateRow struct {
-	Queue          string
-	CountAvailable int64
-	CountRunning   int64
+	Queue                       string
+	CountAvailable              int64
+	CountRunning                int64
+	CountClientsWithRunningJobs int64
 }
 
 func (q *Queries) JobCountByQueueAndState(ctx context.Context, db DBTX, queueNames []string) ([]*JobCountByQueueAndStateRow, error) {
@@ -185,7 +320,12 @@ func (q *Queries) JobCountByQueueAndState(ctx context.Context, db DBTX, queueNam
 	var items []*JobCountByQueueAndStateRow
 	for rows.Next() {
 		var i JobCountByQueueAndStateRow
-		if err := rows.Scan(&i.Queue, &i.CountAvailable, &i.CountRunning); err != nil {
+		if err := rows.Scan(
+			&i.Queue,
+			&i.CountAvailable,
+			&i.CountRunning,
+			&i.CountClientsWithRunningJobs,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -212,6 +352,65 @@ func (q *Queries) JobCountByState(ctx context.Context, db DBTX, state RiverJobSt
 	return count, err
 }
 
+const jobCountMatching = `-- name: JobCountMatching :one
+SELECT count(*)
+FROM (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+    LIMIT $1::int
+) AS matching_job
+`
+
+func (q *Queries) JobCountMatching(ctx context.Context, db DBTX, max int32) (int64, error) {
+	row := db.QueryRowContext(ctx, jobCountMatching, max)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const jobCountRunningByClient = `-- name: JobCountRunningByClient :many
+SELECT
+    -- The client working a job is the last one to have attempted it.
+    attempted_by[array_length(attempted_by, 1)]::text AS client_id,
+    count(*) AS count_running,
+    max(attempted_at)::timestamptz AS last_attempted_at
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND attempted_by IS NOT NULL
+GROUP BY client_id
+ORDER BY client_id ASC
+`
+
+type JobCountRunningByClientRow struct {
+	ClientID        string
+	CountRunning    int64
+	LastAttemptedAt time.Time
+}
+
+func (q *Queries) JobCountRunningByClient(ctx context.Context, db DBTX) ([]*JobCountRunningByClientRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountRunningByClient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountRunningByClientRow
+	for rows.Next() {
+		var i JobCountRunningByClientRow
+		if err := rows.Scan(&i.ClientID, &i.CountRunning, &i.LastAttemptedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobDelete = `-- name: JobDelete :one
 WITH job_to_delete AS (
     SELECT id
@@ -312,32 +511,358 @@ func (q *Queries) JobDeleteBefore(ctx context.Context, db DBTX, arg *JobDeleteBe
 	)
 }
 
-const jobDeleteMany = `-- name: JobDeleteMany :many
-WITH jobs_to_delete AS (
-    SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
-    FROM /* TEMPLATE: schema */river_job
-    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
-        AND state != 'running'
-    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
-    LIMIT $1::int
-    FOR UPDATE
-    SKIP LOCKED
+const jobDeleteMany = `-- name: JobDeleteMany :many
+WITH jobs_to_delete AS (
+    SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+        AND state != 'running'
+    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+    LIMIT $1::int
+    FOR UPDATE
+    SKIP LOCKED
+),
+deleted_jobs AS (
+    DELETE FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (SELECT id FROM jobs_to_delete)
+    RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE id IN (SELECT id FROM deleted_jobs)
+ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+`
+
+// this last SELECT step is necessary because there's no other way to define
+// order records come back from a DELETE statement
+func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, max int32) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobDeleteMany, max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetArgsEncryptionKeyStale = `-- name: JobGetArgsEncryptionKeyStale :many
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata ->> 'river:args_encryption_key_id' <> $1::text
+    AND id > $2::bigint
+ORDER BY id
+LIMIT $3::integer
+`
+
+type JobGetArgsEncryptionKeyStaleParams struct {
+	CurrentKeyID string
+	AfterID      int64
+	Max          int32
+}
+
+func (q *Queries) JobGetArgsEncryptionKeyStale(ctx context.Context, db DBTX, arg *JobGetArgsEncryptionKeyStaleParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetArgsEncryptionKeyStale, arg.CurrentKeyID, arg.AfterID, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailable = `-- name: JobGetAvailable :many
+WITH locked_jobs AS (
+    SELECT
+        id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+    FROM
+        /* TEMPLATE: schema */river_job
+    WHERE
+        state = 'available'
+        AND queue = $4::text
+        AND scheduled_at <= coalesce($1::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all($6::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ $7::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
+    ORDER BY
+        priority ASC,
+        scheduled_at ASC,
+        id ASC
+    LIMIT $5::integer
+    FOR UPDATE
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce($1::timestamptz, now()),
+    attempted_by = array_append(
+        CASE WHEN array_length(river_job.attempted_by, 1) >= $2::int
+        -- +2 instead of +1 because Postgres array indexing starts at 1, not 0.
+        THEN river_job.attempted_by[array_length(river_job.attempted_by, 1) + 2 - $2:]
+        ELSE river_job.attempted_by
+        END,
+        $3::text
+    )
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+`
+
+type JobGetAvailableParams struct {
+	Now            *time.Time
+	MaxAttemptedBy int32
+	AttemptedBy    string
+	Queue          string
+	MaxToLock      int32
+	KindsExcluded  []string
+	Labels         string
+}
+
+func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvailableParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailable,
+		arg.Now,
+		arg.MaxAttemptedBy,
+		arg.AttemptedBy,
+		arg.Queue,
+		arg.MaxToLock,
+		pq.Array(arg.KindsExcluded),
+		arg.Labels,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailableFair = `-- name: JobGetAvailableFair :many
+WITH candidate_jobs AS (
+    SELECT
+        id,
+        row_number() OVER (
+            PARTITION BY metadata ->> $7::text, priority
+            ORDER BY scheduled_at ASC, id ASC
+        ) AS fairness_rank,
+        coalesce(($8::jsonb ->> (metadata ->> $7::text))::integer, 1) AS fairness_weight,
+        row_number() OVER (
+            PARTITION BY kind
+            ORDER BY priority ASC, scheduled_at ASC, id ASC
+        ) AS kind_rank,
+        coalesce(($10::jsonb ->> kind)::integer, $5::integer) AS kind_max_to_lock
+    FROM
+        /* TEMPLATE: schema */river_job
+    WHERE
+        state = 'available'
+        AND queue = $4::text
+        AND scheduled_at <= coalesce($1::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all($6::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ $9::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
 ),
-deleted_jobs AS (
-    DELETE FROM /* TEMPLATE: schema */river_job
-    WHERE id IN (SELECT id FROM jobs_to_delete)
-    RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN candidate_jobs ON candidate_jobs.id = river_job.id
+    WHERE
+        -- Rechecked once the row is locked in case the job was fetched
+        -- elsewhere after candidates were selected.
+        river_job.state = 'available'
+        AND candidate_jobs.kind_rank <= candidate_jobs.kind_max_to_lock
+    ORDER BY
+        river_job.priority ASC,
+        candidate_jobs.fairness_rank::double precision / candidate_jobs.fairness_weight ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
+    LIMIT $5::integer
+    FOR UPDATE OF river_job
+    SKIP LOCKED
 )
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
-FROM /* TEMPLATE: schema */river_job
-WHERE id IN (SELECT id FROM deleted_jobs)
-ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce($1::timestamptz, now()),
+    attempted_by = array_append(
+        CASE WHEN array_length(river_job.attempted_by, 1) >= $2::int
+        -- +2 instead of +1 because Postgres array indexing starts at 1, not 0.
+        THEN river_job.attempted_by[array_length(river_job.attempted_by, 1) + 2 - $2:]
+        ELSE river_job.attempted_by
+        END,
+        $3::text
+    )
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
 `
 
-// this last SELECT step is necessary because there's no other way to define
-// order records come back from a DELETE statement
-func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, max int32) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobDeleteMany, max)
+type JobGetAvailableFairParams struct {
+	Now             *time.Time
+	MaxAttemptedBy  int32
+	AttemptedBy     string
+	Queue           string
+	MaxToLock       int32
+	KindsExcluded   []string
+	FairnessKey     string
+	FairnessWeights string
+	Labels          string
+	MaxToLockByKind string
+}
+
+func (q *Queries) JobGetAvailableFair(ctx context.Context, db DBTX, arg *JobGetAvailableFairParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableFair,
+		arg.Now,
+		arg.MaxAttemptedBy,
+		arg.AttemptedBy,
+		arg.Queue,
+		arg.MaxToLock,
+		pq.Array(arg.KindsExcluded),
+		arg.FairnessKey,
+		arg.FairnessWeights,
+		arg.Labels,
+		arg.MaxToLockByKind,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -378,22 +903,63 @@ func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, max int32) ([]*Riv
 	return items, nil
 }
 
-const jobGetAvailable = `-- name: JobGetAvailable :many
-WITH locked_jobs AS (
+const jobGetAvailableLimitedByKind = `-- name: JobGetAvailableLimitedByKind :many
+WITH candidate_jobs AS (
     SELECT
-        id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+        id,
+        row_number() OVER (
+            PARTITION BY kind
+            ORDER BY priority ASC, scheduled_at ASC, id ASC
+        ) AS kind_rank,
+        coalesce(($8::jsonb ->> kind)::integer, $5::integer) AS kind_max_to_lock
     FROM
         /* TEMPLATE: schema */river_job
     WHERE
         state = 'available'
         AND queue = $4::text
         AND scheduled_at <= coalesce($1::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all($6::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ $7::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
+),
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN candidate_jobs ON candidate_jobs.id = river_job.id
+    WHERE
+        -- Rechecked once the row is locked in case the job was fetched
+        -- elsewhere after candidates were selected.
+        river_job.state = 'available'
+        -- Jobs ranked past their kind's limit aren't locked. One within it
+        -- that's skipped because it's locked elsewhere doesn't make room for
+        -- another, so fewer jobs than the limit may be locked, but never
+        -- more.
+        AND candidate_jobs.kind_rank <= candidate_jobs.kind_max_to_lock
     ORDER BY
-        priority ASC,
-        scheduled_at ASC,
-        id ASC
+        river_job.priority ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
     LIMIT $5::integer
-    FOR UPDATE
+    FOR UPDATE OF river_job
     SKIP LOCKED
 )
 UPDATE
@@ -418,21 +984,27 @@ RETURNING
     river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
 `
 
-type JobGetAvailableParams struct {
-	Now            *time.Time
-	MaxAttemptedBy int32
-	AttemptedBy    string
-	Queue          string
-	MaxToLock      int32
+type JobGetAvailableLimitedByKindParams struct {
+	Now             *time.Time
+	MaxAttemptedBy  int32
+	AttemptedBy     string
+	Queue           string
+	MaxToLock       int32
+	KindsExcluded   []string
+	Labels          string
+	MaxToLockByKind string
 }
 
-func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvailableParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetAvailable,
+func (q *Queries) JobGetAvailableLimitedByKind(ctx context.Context, db DBTX, arg *JobGetAvailableLimitedByKindParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableLimitedByKind,
 		arg.Now,
 		arg.MaxAttemptedBy,
 		arg.AttemptedBy,
 		arg.Queue,
 		arg.MaxToLock,
+		pq.Array(arg.KindsExcluded),
+		arg.Labels,
+		arg.MaxToLockByKind,
 	)
 	if err != nil {
 		return nil, err
@@ -474,6 +1046,39 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 	return items, nil
 }
 
+const jobGetByExternalID = `-- name: JobGetByExternalID :one
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata ->> 'river:external_id' = $1::text
+LIMIT 1
+`
+
+func (q *Queries) JobGetByExternalID(ctx context.Context, db DBTX, externalID string) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobGetByExternalID, externalID)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
 const jobGetByID = `-- name: JobGetByID :one
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -605,22 +1210,91 @@ func (q *Queries) JobGetByKindMany(ctx context.Context, db DBTX, kind []string)
 	return items, nil
 }
 
+const jobGetLeaseExpired = `-- name: JobGetLeaseExpired :many
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND queue = $1
+    -- A lease is tied to the attempt that took it, so one left over by a
+    -- previous attempt doesn't count as expired for the current one.
+    AND (metadata ->> 'river:lease_attempt')::smallint = attempt
+    AND (metadata ->> 'river:lease_expires_at')::timestamptz < $2::timestamptz
+ORDER BY id
+LIMIT $3
+`
+
+type JobGetLeaseExpiredParams struct {
+	Queue string
+	Now   time.Time
+	Max   int32
+}
+
+func (q *Queries) JobGetLeaseExpired(ctx context.Context, db DBTX, arg *JobGetLeaseExpiredParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetLeaseExpired, arg.Queue, arg.Now, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetStuck = `-- name: JobGetStuck :many
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
     AND attempted_at < $1::timestamptz
+    -- A job whose current attempt holds an unexpired lease is still being
+    -- renewed by the client working it, so it's left until the lease lapses.
+    AND NOT coalesce(
+        (metadata ->> 'river:lease_attempt')::smallint = attempt
+            AND (metadata ->> 'river:lease_expires_at')::timestamptz >= $2::timestamptz,
+        false
+    )
 ORDER BY id
-LIMIT $2
+LIMIT $3
 `
 
 type JobGetStuckParams struct {
 	StuckHorizon time.Time
+	Now          time.Time
 	Max          int32
 }
 
 func (q *Queries) JobGetStuck(ctx context.Context, db DBTX, arg *JobGetStuckParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetStuck, arg.StuckHorizon, arg.Max)
+	rows, err := db.QueryContext(ctx, jobGetStuck, arg.StuckHorizon, arg.Now, arg.Max)
 	if err != nil {
 		return nil, err
 	}
@@ -676,7 +1350,8 @@ WITH raw_job_data AS (
         unnest($10::text[]) AS state,
         unnest($11::text[]) AS tags,
         unnest($12::bytea[]) AS unique_key,
-        unnest($13::integer[]) AS unique_states
+        unnest($13::integer[]) AS unique_states,
+        unnest($14::bigint[]) AS scheduled_in
 )
 INSERT INTO /* TEMPLATE: schema */river_job(
     id,
@@ -701,7 +1376,9 @@ INSERT INTO /* TEMPLATE: schema */river_job(
     coalesce(metadata, '{}'::jsonb) AS metadata,
     priority,
     queue,
-    coalesce(nullif(scheduled_at, '0001-01-01 00:00:00 +0000'), now()) AS scheduled_at,
+    -- ` + "`" + `scheduled_in` + "`" + ` is in microseconds and schedules relative to the
+    -- database's clock for jobs without an explicit ` + "`" + `scheduled_at` + "`" + `.
+    coalesce(nullif(scheduled_at, '0001-01-01 00:00:00 +0000'), now() + scheduled_in * interval '1 microsecond') AS scheduled_at,
     state::/* TEMPLATE: schema */river_job_state,
     string_to_array(tags, ',')::varchar(255)[],
     -- ` + "`" + `nullif` + "`" + ` is required for ` + "`" + `lib/pq` + "`" + `, which doesn't do a good job of reading
@@ -733,6 +1410,7 @@ type JobInsertFastManyParams struct {
 	Tags         []string
 	UniqueKey    [][]byte
 	UniqueStates []int32
+	ScheduledIn  []int64
 }
 
 type JobInsertFastManyRow struct {
@@ -755,6 +1433,7 @@ func (q *Queries) JobInsertFastMany(ctx context.Context, db DBTX, arg *JobInsert
 		pq.Array(arg.Tags),
 		pq.Array(arg.UniqueKey),
 		pq.Array(arg.UniqueStates),
+		pq.Array(arg.ScheduledIn),
 	)
 	if err != nil {
 		return nil, err
@@ -1160,6 +1839,79 @@ func (q *Queries) JobKindList(ctx context.Context, db DBTX, arg *JobKindListPara
 	return items, nil
 }
 
+const jobKindStorageUsage = `-- name: JobKindStorageUsage :many
+SELECT
+    kind,
+    count(*) AS count,
+    coalesce(sum(pg_column_size(args)), 0)::bigint AS args_bytes,
+    coalesce(sum(pg_column_size(errors)), 0)::bigint AS errors_bytes,
+    coalesce(sum(pg_column_size(metadata)), 0)::bigint AS metadata_bytes
+FROM /* TEMPLATE: schema */river_job
+GROUP BY kind
+ORDER BY kind ASC
+`
+
+type JobKindStorageUsageRow struct {
+	Kind          string
+	Count         int64
+	ArgsBytes     int64
+	ErrorsBytes   int64
+	MetadataBytes int64
+}
+
+func (q *Queries) JobKindStorageUsage(ctx context.Context, db DBTX) ([]*JobKindStorageUsageRow, error) {
+	rows, err := db.QueryContext(ctx, jobKindStorageUsage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobKindStorageUsageRow
+	for rows.Next() {
+		var i JobKindStorageUsageRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Count,
+			&i.ArgsBytes,
+			&i.ErrorsBytes,
+			&i.MetadataBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobLeaseRenewMany = `-- name: JobLeaseRenewMany :exec
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = river_job.metadata || jsonb_build_object('river:lease_attempt', river_job.attempt, 'river:lease_expires_at', $1::timestamptz)
+FROM (
+    SELECT
+        unnest($2::bigint[]) AS id,
+        unnest($3::smallint[]) AS attempt
+) AS lease
+WHERE river_job.id = lease.id
+    AND river_job.attempt = lease.attempt
+    AND river_job.state = 'running'
+`
+
+type JobLeaseRenewManyParams struct {
+	LeaseExpiresAt time.Time
+	ID             []int64
+	Attempt        []int16
+}
+
+func (q *Queries) JobLeaseRenewMany(ctx context.Context, db DBTX, arg *JobLeaseRenewManyParams) error {
+	_, err := db.ExecContext(ctx, jobLeaseRenewMany, arg.LeaseExpiresAt, pq.Array(arg.ID), pq.Array(arg.Attempt))
+	return err
+}
+
 const jobList = `-- name: JobList :many
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -1660,22 +2412,73 @@ func (q *Queries) JobUpdate(ctx context.Context, db DBTX, arg *JobUpdateParams)
 	return &i, err
 }
 
+const jobUpdateArgsEncryptionKey = `-- name: JobUpdateArgsEncryptionKey :one
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    args = $1::jsonb,
+    metadata = jsonb_set(metadata, '{river:args_encryption_key_id}', to_jsonb($2::text))
+WHERE id = $3
+    AND metadata ->> 'river:args_encryption_key_id' = $4::text
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+`
+
+type JobUpdateArgsEncryptionKeyParams struct {
+	Args          string
+	KeyID         string
+	ID            int64
+	PreviousKeyID string
+}
+
+func (q *Queries) JobUpdateArgsEncryptionKey(ctx context.Context, db DBTX, arg *JobUpdateArgsEncryptionKeyParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobUpdateArgsEncryptionKey,
+		arg.Args,
+		arg.KeyID,
+		arg.ID,
+		arg.PreviousKeyID,
+	)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
 const jobUpdateFull = `-- name: JobUpdateFull :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
-    attempt = CASE WHEN $1::boolean THEN $2 ELSE attempt END,
-    attempted_at = CASE WHEN $3::boolean THEN $4 ELSE attempted_at END,
-    attempted_by = CASE WHEN $5::boolean THEN $6 ELSE attempted_by END,
-    errors = CASE WHEN $7::boolean THEN $8::jsonb[] ELSE errors END,
-    finalized_at = CASE WHEN $9::boolean THEN $10 ELSE finalized_at END,
-    max_attempts = CASE WHEN $11::boolean THEN $12 ELSE max_attempts END,
-    metadata = CASE WHEN $13::boolean THEN $14::jsonb ELSE metadata END,
-    state = CASE WHEN $15::boolean THEN $16::/* TEMPLATE: schema */river_job_state ELSE state END
-WHERE id = $17
+    args = CASE WHEN $1::boolean THEN $2::jsonb ELSE args END,
+    attempt = CASE WHEN $3::boolean THEN $4 ELSE attempt END,
+    attempted_at = CASE WHEN $5::boolean THEN $6 ELSE attempted_at END,
+    attempted_by = CASE WHEN $7::boolean THEN $8 ELSE attempted_by END,
+    errors = CASE WHEN $9::boolean THEN $10::jsonb[] ELSE errors END,
+    finalized_at = CASE WHEN $11::boolean THEN $12 ELSE finalized_at END,
+    max_attempts = CASE WHEN $13::boolean THEN $14 ELSE max_attempts END,
+    metadata = CASE WHEN $15::boolean THEN $16::jsonb ELSE metadata END,
+    state = CASE WHEN $17::boolean THEN $18::/* TEMPLATE: schema */river_job_state ELSE state END
+WHERE id = $19
 RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 `
 
 type JobUpdateFullParams struct {
+	ArgsDoUpdate        bool
+	Args                string
 	AttemptDoUpdate     bool
 	Attempt             int16
 	AttemptedAtDoUpdate bool
@@ -1699,6 +2502,8 @@ type JobUpdateFullParams struct {
 // of parameters and therefore may be more suitable for testing than production.
 func (q *Queries) JobUpdateFull(ctx context.Context, db DBTX, arg *JobUpdateFullParams) (*RiverJob, error) {
 	row := db.QueryRowContext(ctx, jobUpdateFull,
+		arg.ArgsDoUpdate,
+		arg.Args,
 		arg.AttemptDoUpdate,
 		arg.Attempt,
 		arg.AttemptedAtDoUpdate,
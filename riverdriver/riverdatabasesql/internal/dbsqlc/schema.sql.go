@@ -106,6 +106,15 @@ func (q *Queries) IndexesExist(ctx context.Context, db DBTX, arg *IndexesExistPa
 	return items, nil
 }
 
+const ping = `-- name: Ping :exec
+SELECT 1
+`
+
+func (q *Queries) Ping(ctx context.Context, db DBTX) error {
+	_, err := db.ExecContext(ctx, ping)
+	return err
+}
+
 const schemaGetExpired = `-- name: SchemaGetExpired :many
 SELECT schema_name::text
 FROM information_schema.schemata
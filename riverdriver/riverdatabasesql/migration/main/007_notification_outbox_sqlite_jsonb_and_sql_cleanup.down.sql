@@ -1,3 +1,39 @@
+--
+-- Job partition keys rollback.
+--
+
+DROP INDEX /* TEMPLATE: schema */river_job_partition_key_idx;
+
+--
+-- Job external IDs rollback.
+--
+
+DROP INDEX /* TEMPLATE: schema */river_job_external_id_idx;
+
+--
+-- Job kind pauses rollback.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_job_kind_pause;
+
+--
+-- Maintenance status rollback.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_maintenance_status;
+
+--
+-- Job kind registry rollback.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_job_kind;
+
+--
+-- Rate limits rollback.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_rate_limit;
+
 --
 -- SQL cleanup rollback.
 --
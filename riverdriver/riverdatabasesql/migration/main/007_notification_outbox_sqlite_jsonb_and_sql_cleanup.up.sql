@@ -42,3 +42,69 @@ ALTER TABLE /* TEMPLATE: schema */river_job
 
 ALTER TABLE /* TEMPLATE: schema */river_queue
     ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
+
+--
+-- Rate limits.
+--
+
+CREATE UNLOGGED TABLE /* TEMPLATE: schema */river_rate_limit (
+    name text PRIMARY KEY,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+--
+-- Job kind registry.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_job_kind (
+    kind text NOT NULL,
+    version text NOT NULL DEFAULT '',
+    args_schema jsonb NOT NULL DEFAULT '{}',
+    client_id text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (kind, version),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+--
+-- Maintenance status.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_maintenance_status (
+    name text PRIMARY KEY,
+    client_id text NOT NULL,
+    consecutive_failures integer NOT NULL DEFAULT 0,
+    error text,
+    finished_at timestamptz NOT NULL,
+    last_succeeded_at timestamptz,
+    num_rows bigint NOT NULL DEFAULT 0,
+    started_at timestamptz NOT NULL,
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+--
+-- Job kind pauses.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_job_kind_pause (
+    kind text PRIMARY KEY,
+    paused_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+--
+-- Job external IDs.
+--
+
+CREATE UNIQUE INDEX river_job_external_id_idx ON /* TEMPLATE: schema */river_job ((metadata ->> 'river:external_id'))
+    WHERE (metadata ->> 'river:external_id') IS NOT NULL;
+
+--
+-- Job partition keys.
+--
+
+CREATE INDEX river_job_partition_key_idx ON /* TEMPLATE: schema */river_job ((metadata ->> 'river:partition_key'), id)
+    WHERE (metadata ->> 'river:partition_key') IS NOT NULL;
@@ -159,6 +159,25 @@ func exerciseExecutorTx[TTx any](ctx context.Context, t *testing.T,
 
 			require.NoError(t, exec.Exec(ctx, "SELECT $1 || $2", "foo", "bar"))
 		})
+
+		t.Run("StatementTimeout", func(t *testing.T) {
+			t.Parallel()
+
+			{
+				driver, _ := driverWithSchema(ctx, t, nil)
+				if driver.DatabaseName() == riverdriver.DatabaseNameSQLite {
+					t.Logf("Skipping StatementTimeout test for SQLite")
+					return
+				}
+			}
+
+			exec := setup(ctx, t)
+
+			require.NoError(t, exec.Exec(ctx, "SET LOCAL statement_timeout = '10ms'"))
+
+			err := exec.Exec(ctx, "SELECT pg_sleep(1)")
+			require.ErrorIs(t, err, riverdriver.ErrStatementTimeout)
+		})
 	})
 
 	t.Run("PGAdvisoryXactLock", func(t *testing.T) {
@@ -214,6 +233,78 @@ func exerciseExecutorTx[TTx any](ctx context.Context, t *testing.T,
 		require.True(t, tryAcquireLock(otherExec))
 	})
 
+	t.Run("PGTransactionTimeouts", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("GetsAndSetsTimeouts", func(t *testing.T) {
+			t.Parallel()
+
+			{
+				driver, _ := driverWithSchema(ctx, t, nil)
+				if driver.DatabaseName() == riverdriver.DatabaseNameSQLite {
+					t.Logf("Skipping PGTransactionTimeouts test for SQLite")
+					return
+				}
+			}
+
+			exec := setup(ctx, t)
+
+			require.NoError(t, exec.PGTransactionTimeoutsSet(ctx, &riverdriver.PGTransactionTimeouts{
+				LockTimeout:      "2s",
+				StatementTimeout: "3s",
+			}))
+
+			timeouts, err := exec.PGTransactionTimeoutsGet(ctx)
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.PGTransactionTimeouts{LockTimeout: "2s", StatementTimeout: "3s"}, timeouts)
+
+			// An empty timeout leaves its setting unchanged.
+			require.NoError(t, exec.PGTransactionTimeoutsSet(ctx, &riverdriver.PGTransactionTimeouts{
+				StatementTimeout: "4s",
+			}))
+
+			timeouts, err = exec.PGTransactionTimeoutsGet(ctx)
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.PGTransactionTimeouts{LockTimeout: "2s", StatementTimeout: "4s"}, timeouts)
+
+			// Timeouts apply to statements in the transaction.
+			require.NoError(t, exec.PGTransactionTimeoutsSet(ctx, &riverdriver.PGTransactionTimeouts{
+				StatementTimeout: "10ms",
+			}))
+
+			err = exec.Exec(ctx, "SELECT pg_sleep(1)")
+			require.ErrorIs(t, err, riverdriver.ErrStatementTimeout)
+		})
+
+		t.Run("NotImplementedForSQLite", func(t *testing.T) {
+			t.Parallel()
+
+			{
+				driver, _ := driverWithSchema(ctx, t, nil)
+				if driver.DatabaseName() != riverdriver.DatabaseNameSQLite {
+					t.Logf("Skipping SQLite-only PGTransactionTimeouts test")
+					return
+				}
+			}
+
+			exec := setup(ctx, t)
+
+			_, err := exec.PGTransactionTimeoutsGet(ctx)
+			require.ErrorIs(t, err, riverdriver.ErrNotImplemented)
+
+			err = exec.PGTransactionTimeoutsSet(ctx, &riverdriver.PGTransactionTimeouts{StatementTimeout: "1s"})
+			require.ErrorIs(t, err, riverdriver.ErrNotImplemented)
+		})
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(ctx, t)
+
+		require.NoError(t, exec.Ping(ctx))
+	})
+
 	t.Run("QueryRow", func(t *testing.T) {
 		t.Parallel()
 
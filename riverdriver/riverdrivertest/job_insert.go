@@ -2,6 +2,7 @@ package riverdrivertest
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"reflect"
@@ -179,6 +180,31 @@ func exerciseJobInsert[TTx any](ctx context.Context, t *testing.T,
 			}
 		})
 
+		t.Run("ScheduledIn", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			results, err := exec.JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: []*riverdriver.JobInsertFastParams{
+					{
+						EncodedArgs: []byte(`{"encoded": "args"}`),
+						Kind:        "test_kind",
+						MaxAttempts: rivercommon.MaxAttemptsDefault,
+						Metadata:    []byte(`{"meta": "data"}`),
+						Priority:    rivercommon.PriorityDefault,
+						Queue:       rivercommon.QueueDefault,
+						ScheduledIn: time.Hour,
+						State:       rivertype.JobStateScheduled,
+						Tags:        []string{"tag"},
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.WithinDuration(t, time.Now().UTC().Add(time.Hour), results[0].Job.ScheduledAt, 2*time.Second)
+		})
+
 		t.Run("UniqueConflict", func(t *testing.T) {
 			t.Parallel()
 
@@ -264,6 +290,168 @@ func exerciseJobInsert[TTx any](ctx context.Context, t *testing.T,
 		})
 	})
 
+	t.Run("JobInsertFastManyCopyFrom", func(t *testing.T) {
+		t.Parallel()
+
+		makeInsertParams := func(num int, uniqueKeyPrefix string) []*riverdriver.JobInsertFastParams {
+			insertParams := make([]*riverdriver.JobInsertFastParams, num)
+			for i := range insertParams {
+				insertParams[i] = &riverdriver.JobInsertFastParams{
+					EncodedArgs:  []byte(`{"encoded": "args"}`),
+					Kind:         "test_kind",
+					MaxAttempts:  rivercommon.MaxAttemptsDefault,
+					Metadata:     []byte(`{"meta": "data"}`),
+					Priority:     rivercommon.PriorityDefault,
+					Queue:        rivercommon.QueueDefault,
+					State:        rivertype.JobStateAvailable,
+					Tags:         []string{"tag"},
+					UniqueKey:    []byte(uniqueKeyPrefix + strconv.Itoa(i)),
+					UniqueStates: 0xff,
+				}
+			}
+			return insertParams
+		}
+
+		t.Run("AllArgs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				idStart = rand.Int64()
+				now     = time.Now().UTC()
+			)
+
+			insertParams := makeInsertParams(10, "unique-key-copy-from-")
+			for i, params := range insertParams {
+				params.ID = ptrutil.Ptr(idStart + int64(i))
+				params.CreatedAt = ptrutil.Ptr(now.Add(time.Duration(i) * 5 * time.Second))
+				params.ScheduledAt = ptrutil.Ptr(now.Add(time.Duration(i) * time.Minute))
+			}
+
+			resultRows, err := exec.JobInsertFastManyCopyFrom(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: insertParams,
+			})
+			if errors.Is(err, riverdriver.ErrNotImplemented) {
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resultRows, len(insertParams))
+
+			for i, result := range resultRows {
+				require.False(t, result.UniqueSkippedAsDuplicate)
+				job := result.Job
+
+				require.Equal(t, idStart+int64(i), job.ID)
+				require.Equal(t, 0, job.Attempt)
+				require.Nil(t, job.AttemptedAt)
+				require.Empty(t, job.AttemptedBy)
+				require.WithinDuration(t, now.Add(time.Duration(i)*5*time.Second), job.CreatedAt, time.Millisecond)
+				require.JSONEq(t, `{"encoded": "args"}`, string(job.EncodedArgs))
+				require.Empty(t, job.Errors)
+				require.Nil(t, job.FinalizedAt)
+				require.Equal(t, "test_kind", job.Kind)
+				require.Equal(t, rivercommon.MaxAttemptsDefault, job.MaxAttempts)
+				require.JSONEq(t, `{"meta": "data"}`, string(job.Metadata))
+				require.Equal(t, rivercommon.PriorityDefault, job.Priority)
+				require.Equal(t, rivercommon.QueueDefault, job.Queue)
+				require.WithinDuration(t, now.Add(time.Duration(i)*time.Minute), job.ScheduledAt, time.Millisecond)
+				require.Equal(t, rivertype.JobStateAvailable, job.State)
+				require.Equal(t, []string{"tag"}, job.Tags)
+				require.Equal(t, []byte("unique-key-copy-from-"+strconv.Itoa(i)), job.UniqueKey)
+				require.Equal(t, rivertype.JobStates(), job.UniqueStates)
+			}
+		})
+
+		t.Run("MissingValuesDefaultAsExpected", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			insertParams := makeInsertParams(10, "")
+			for _, params := range insertParams {
+				params.UniqueKey = nil
+				params.UniqueStates = 0x00
+			}
+
+			resultRows, err := exec.JobInsertFastManyCopyFrom(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: insertParams,
+			})
+			if errors.Is(err, riverdriver.ErrNotImplemented) {
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resultRows, len(insertParams))
+
+			for _, result := range resultRows {
+				job := result.Job
+				require.NotZero(t, job.ID)
+				require.WithinDuration(t, time.Now().UTC(), job.CreatedAt, 2*time.Second)
+				require.WithinDuration(t, time.Now().UTC(), job.ScheduledAt, 2*time.Second)
+				require.Nil(t, job.UniqueKey)
+				require.Empty(t, job.UniqueStates)
+			}
+		})
+
+		t.Run("ScheduledInFallsBackToInsert", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			insertParams := makeInsertParams(2, "")
+			for _, params := range insertParams {
+				params.ScheduledIn = time.Hour
+				params.State = rivertype.JobStateScheduled
+				params.UniqueKey = nil
+				params.UniqueStates = 0x00
+			}
+
+			resultRows, err := exec.JobInsertFastManyCopyFrom(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: insertParams,
+			})
+			if errors.Is(err, riverdriver.ErrNotImplemented) {
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resultRows, len(insertParams))
+
+			for _, result := range resultRows {
+				require.WithinDuration(t, time.Now().UTC().Add(time.Hour), result.Job.ScheduledAt, 2*time.Second)
+			}
+		})
+
+		t.Run("UniqueConflict", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			results1, err := exec.JobInsertFastManyCopyFrom(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: makeInsertParams(2, "unique-key-copy-from-conflict-"),
+			})
+			if errors.Is(err, riverdriver.ErrNotImplemented) {
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results1, 2)
+			require.False(t, results1[0].UniqueSkippedAsDuplicate)
+			require.False(t, results1[1].UniqueSkippedAsDuplicate)
+
+			// Second batch overlaps the first on its first job only.
+			insertParams := makeInsertParams(2, "unique-key-copy-from-conflict-")
+			insertParams[1].UniqueKey = []byte("unique-key-copy-from-conflict-new")
+
+			results2, err := exec.JobInsertFastManyCopyFrom(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: insertParams,
+			})
+			require.NoError(t, err)
+			require.Len(t, results2, 2)
+			require.True(t, results2[0].UniqueSkippedAsDuplicate)
+			require.Equal(t, results1[0].Job.ID, results2[0].Job.ID)
+			require.False(t, results2[1].UniqueSkippedAsDuplicate)
+			require.NotEqual(t, results1[1].Job.ID, results2[1].Job.ID)
+		})
+	})
+
 	t.Run("JobInsertFastManyNoReturning", func(t *testing.T) {
 		t.Parallel()
 
@@ -533,6 +721,37 @@ func exerciseJobInsert[TTx any](ctx context.Context, t *testing.T,
 			}
 		})
 
+		t.Run("ScheduledIn", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			count, err := exec.JobInsertFastManyNoReturning(ctx, &riverdriver.JobInsertFastManyParams{
+				Jobs: []*riverdriver.JobInsertFastParams{
+					{
+						EncodedArgs: []byte(`{"encoded": "args"}`),
+						Kind:        "test_kind",
+						MaxAttempts: rivercommon.MaxAttemptsDefault,
+						Metadata:    []byte(`{"meta": "data"}`),
+						Priority:    rivercommon.PriorityDefault,
+						Queue:       rivercommon.QueueDefault,
+						ScheduledIn: time.Hour,
+						State:       rivertype.JobStateScheduled,
+						Tags:        []string{"tag"},
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, count)
+
+			jobsAfter, err := exec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+				Kind: []string{"test_kind"},
+			})
+			require.NoError(t, err)
+			require.Len(t, jobsAfter, 1)
+			require.WithinDuration(t, time.Now().UTC().Add(time.Hour), jobsAfter[0].ScheduledAt, 2*time.Second)
+		})
+
 		t.Run("AlternateSchema", func(t *testing.T) {
 			t.Parallel()
 
@@ -0,0 +1,96 @@
+package riverdrivertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+func exerciseJobKindRegistration[TTx any](ctx context.Context, t *testing.T, executorWithTx func(ctx context.Context, t *testing.T) (riverdriver.Executor, riverdriver.Driver[TTx])) {
+	t.Helper()
+
+	t.Run("JobKindRegistrationList", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("Empty", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			registrations, err := exec.JobKindRegistrationList(ctx, &riverdriver.JobKindRegistrationListParams{})
+			require.NoError(t, err)
+			require.Empty(t, registrations)
+		})
+	})
+
+	t.Run("JobKindRegistrationUpsertMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("InsertsAndUpdates", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			now := time.Now().UTC()
+
+			require.NoError(t, exec.JobKindRegistrationUpsertMany(ctx, &riverdriver.JobKindRegistrationUpsertManyParams{
+				ClientID: "client1",
+				Now:      &now,
+				Registrations: []*riverdriver.JobKindRegistrationUpsertParams{
+					{ArgsSchema: []byte(`{"type": "object"}`), Kind: "kind2", Version: "v1"},
+					{Kind: "kind1"},
+				},
+			}))
+
+			registrations, err := exec.JobKindRegistrationList(ctx, &riverdriver.JobKindRegistrationListParams{})
+			require.NoError(t, err)
+			require.Len(t, registrations, 2)
+
+			require.JSONEq(t, `{}`, string(registrations[0].ArgsSchema))
+			require.Equal(t, "client1", registrations[0].ClientID)
+			require.WithinDuration(t, now, registrations[0].CreatedAt, time.Millisecond)
+			require.Equal(t, "kind1", registrations[0].Kind)
+			require.WithinDuration(t, now, registrations[0].UpdatedAt, time.Millisecond)
+			require.Empty(t, registrations[0].Version)
+
+			require.JSONEq(t, `{"type": "object"}`, string(registrations[1].ArgsSchema))
+			require.Equal(t, "kind2", registrations[1].Kind)
+			require.Equal(t, "v1", registrations[1].Version)
+
+			// A second client publishing the same kind and version takes it
+			// over, while a new version is registered alongside the old one.
+			later := now.Add(time.Minute)
+
+			require.NoError(t, exec.JobKindRegistrationUpsertMany(ctx, &riverdriver.JobKindRegistrationUpsertManyParams{
+				ClientID: "client2",
+				Now:      &later,
+				Registrations: []*riverdriver.JobKindRegistrationUpsertParams{
+					{ArgsSchema: []byte(`{"type": "array"}`), Kind: "kind2", Version: "v1"},
+					{Kind: "kind2", Version: "v2"},
+				},
+			}))
+
+			registrations, err = exec.JobKindRegistrationList(ctx, &riverdriver.JobKindRegistrationListParams{})
+			require.NoError(t, err)
+			require.Len(t, registrations, 3)
+
+			require.Equal(t, "client1", registrations[0].ClientID)
+			require.Equal(t, "kind1", registrations[0].Kind)
+
+			require.JSONEq(t, `{"type": "array"}`, string(registrations[1].ArgsSchema))
+			require.Equal(t, "client2", registrations[1].ClientID)
+			require.WithinDuration(t, now, registrations[1].CreatedAt, time.Millisecond)
+			require.Equal(t, "kind2", registrations[1].Kind)
+			require.WithinDuration(t, later, registrations[1].UpdatedAt, time.Millisecond)
+			require.Equal(t, "v1", registrations[1].Version)
+
+			require.Equal(t, "client2", registrations[2].ClientID)
+			require.Equal(t, "kind2", registrations[2].Kind)
+			require.Equal(t, "v2", registrations[2].Version)
+		})
+	})
+}
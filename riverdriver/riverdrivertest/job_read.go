@@ -117,6 +117,32 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			require.Equal(t, int64(1), countsByQueue[1].CountRunning)
 		})
 
+		t.Run("CountsDistinctClientsRunningJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			runningJob := func(attemptedBy ...string) {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: attemptedBy, Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			}
+
+			runningJob("client1")
+			runningJob("client1")
+			runningJob("client2")
+			runningJob("client1", "client3") // only the last attempt counts
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client4"}, Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+
+			countsByQueue, err := exec.JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+				QueueNames: []string{"queue1"},
+				Schema:     "",
+			})
+			require.NoError(t, err)
+
+			require.Len(t, countsByQueue, 1)
+			require.Equal(t, int64(3), countsByQueue[0].CountClientsWithRunningJobs)
+			require.Equal(t, int64(4), countsByQueue[0].CountRunning)
+		})
+
 		t.Run("IncludesRequestedQueuesThatHaveNoJobs", func(t *testing.T) {
 			t.Parallel()
 
@@ -206,6 +232,144 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 		})
 	})
 
+	t.Run("JobCountMatching", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CountsMatchingJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+
+			numJobs, err := exec.JobCountMatching(ctx, &riverdriver.JobCountMatchingParams{
+				Max:         100,
+				NamedArgs:   map[string]any{"kind": "kind1"},
+				WhereClause: "kind = @kind",
+			})
+			require.NoError(t, err)
+			require.Equal(t, 2, numJobs)
+		})
+
+		t.Run("StopsAtMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			}
+
+			numJobs, err := exec.JobCountMatching(ctx, &riverdriver.JobCountMatchingParams{
+				Max:         2,
+				WhereClause: "true",
+			})
+			require.NoError(t, err)
+			require.Equal(t, 2, numJobs)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobCountMatching(ctx, &riverdriver.JobCountMatchingParams{
+				Max:         100,
+				Schema:      "custom_schema",
+				WhereClause: "true",
+			})
+			requireMissingRelation(t, err, "custom_schema", "river_job")
+		})
+	})
+
+	t.Run("JobCountMatchingEstimate", func(t *testing.T) {
+		t.Parallel()
+
+		exec, bundle := setup(ctx, t)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+
+		numJobs, err := exec.JobCountMatchingEstimate(ctx, &riverdriver.JobCountMatchingParams{
+			NamedArgs:   map[string]any{"kind": "kind1"},
+			WhereClause: "kind = @kind",
+		})
+		if bundle.driver.DatabaseName() == riverdriver.DatabaseNameSQLite {
+			require.ErrorIs(t, err, riverdriver.ErrNotImplemented)
+			return
+		}
+		require.NoError(t, err)
+
+		// The planner's estimate depends on table statistics, so only check
+		// that it's plausible rather than exact.
+		require.GreaterOrEqual(t, numJobs, 0)
+	})
+
+	t.Run("JobCountRunningByClient", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			now       = time.Now().UTC()
+			nowMinus1 = now.Add(-1 * time.Minute)
+		)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &nowMinus1, AttemptedBy: []string{"client1"}, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &now, AttemptedBy: []string{"client2", "client1"}, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &now, AttemptedBy: []string{"client2"}, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &now, AttemptedBy: []string{"client3"}, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		counts, err := exec.JobCountRunningByClient(ctx, &riverdriver.JobCountRunningByClientParams{})
+		require.NoError(t, err)
+		require.Len(t, counts, 2)
+
+		require.Equal(t, "client1", counts[0].ClientID)
+		require.Equal(t, int64(2), counts[0].CountRunning)
+		require.WithinDuration(t, now, counts[0].LastAttemptedAt, time.Millisecond)
+
+		require.Equal(t, "client2", counts[1].ClientID)
+		require.Equal(t, int64(1), counts[1].CountRunning)
+		require.WithinDuration(t, now, counts[1].LastAttemptedAt, time.Millisecond)
+	})
+
+	t.Run("JobGetArgsEncryptionKeyStale", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		staleJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:args_encryption_key_id":"key1"}`)})
+		staleJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:args_encryption_key_id":"key1"}`)})
+		staleJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:args_encryption_key_id":"key0"}`)})
+
+		// Not returned because encrypted with the current key.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:args_encryption_key_id":"key2"}`)})
+
+		// Not returned because not encrypted.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+		// Max two stale.
+		staleJobs, err := exec.JobGetArgsEncryptionKeyStale(ctx, &riverdriver.JobGetArgsEncryptionKeyStaleParams{
+			CurrentKeyID: "key2",
+			Max:          2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{staleJob1.ID, staleJob2.ID},
+			sliceutil.Map(staleJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		// Paginated with AfterID.
+		staleJobs, err = exec.JobGetArgsEncryptionKeyStale(ctx, &riverdriver.JobGetArgsEncryptionKeyStaleParams{
+			AfterID:      staleJob2.ID,
+			CurrentKeyID: "key2",
+			Max:          2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{staleJob3.ID},
+			sliceutil.Map(staleJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+	})
+
 	t.Run("JobGetAvailable", func(t *testing.T) {
 		t.Parallel()
 
@@ -253,6 +417,138 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			require.Len(t, jobRows, 1)
 		})
 
+		t.Run("ConstrainedToUnpausedKinds", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+
+			// Pausing an already paused kind is a no-op.
+			for range 2 {
+				require.NoError(t, exec.JobKindPause(ctx, &riverdriver.JobKindPauseParams{Kind: "kind2"}))
+			}
+
+			// Only the job of the kind that isn't paused is found.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, job1.ID, jobRows[0].ID)
+
+			// Resuming a kind that isn't paused is a no-op.
+			require.NoError(t, exec.JobKindResume(ctx, &riverdriver.JobKindResumeParams{Kind: "kind1"}))
+			require.NoError(t, exec.JobKindResume(ctx, &riverdriver.JobKindResumeParams{Kind: "kind2"}))
+
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, "kind2", jobRows[0].Kind)
+		})
+
+		t.Run("ConstrainedToKindsNotExcluded", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind3")})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				KindsExcluded:  []string{"kind2", "kind3"},
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, job1.ID, jobRows[0].ID)
+		})
+
+		t.Run("ConstrainedToMaxToLockByKind", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			kind1Job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			kind1Job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			kind2Job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+			kind3Job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind3")})
+			kind3Job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind3")})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        testClientID,
+				MaxAttemptedBy:  maxAttemptedBy,
+				MaxToLock:       maxToLock,
+				MaxToLockByKind: map[string]int{"kind1": 2, "kind2": 1},
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{kind1Job1.ID, kind1Job2.ID, kind2Job1.ID, kind3Job1.ID, kind3Job2.ID}, jobIDs)
+		})
+
+		t.Run("ConstrainedToPartitionOrder", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			partitionMetadata := func(partitionKey string) []byte {
+				return []byte(`{"` + rivertype.MetadataKeyPartitionKey + `":"` + partitionKey + `"}`)
+			}
+
+			// Only the first job in partition1 is eligible.
+			partition1Job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition1")})
+
+			// A job is already running in partition2, so nothing is eligible.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition2"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition2")})
+
+			// The earlier job in partition3 is finalized, so the later one is
+			// eligible.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Metadata: partitionMetadata("partition3"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			partition3Job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition3")})
+
+			// An earlier job in partition4 is waiting to be retried, so the
+			// later one isn't eligible.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition4"), ScheduledAt: ptrutil.Ptr(time.Now().Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: partitionMetadata("partition4")})
+
+			// Jobs without a partition key are unconstrained.
+			unpartitionedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{partition1Job1.ID, partition3Job2.ID, unpartitionedJob.ID}, jobIDs)
+		})
+
 		t.Run("ConstrainedToQueue", func(t *testing.T) {
 			t.Parallel()
 
@@ -273,6 +569,53 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			require.Empty(t, jobRows)
 		})
 
+		t.Run("ConstrainedToRequiredLabels", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			requiredLabelsMetadata := func(requiredLabels string) []byte {
+				return []byte(`{"` + rivertype.MetadataKeyRequiredLabels + `":` + requiredLabels + `}`)
+			}
+
+			gpuJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"gpu":"true"}`)})
+			gpuAndRegionJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"gpu":"true","region":"eu"}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"gpu":"false"}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"region":"us"}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"zone":"a"}`)})
+
+			// Jobs without required labels are worked by any client.
+			unlabeledJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				Labels:         map[string]string{"gpu": "true", "region": "eu", "other": "label"},
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{gpuJob.ID, gpuAndRegionJob.ID, unlabeledJob.ID}, jobIDs)
+
+			// A client without labels only works jobs without required labels.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: requiredLabelsMetadata(`{"gpu":"true"}`)})
+			unlabeledJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      maxToLock,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, unlabeledJob2.ID, jobRows[0].ID)
+		})
+
 		t.Run("ConstrainedToScheduledAtBeforeNow", func(t *testing.T) {
 			t.Parallel()
 
@@ -367,6 +710,129 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			require.Equal(t, 3, jobRows[0].Priority, "expected final job to have priority 3")
 		})
 
+		t.Run("FairnessKey", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tenantMetadata := func(tenantID string) []byte {
+				return []byte(`{"tenant_id":"` + tenantID + `"}`)
+			}
+
+			tenantAJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+			tenantBJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("b")})
+			noTenantJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			// Tenant "a" was first to insert, but each tenant (including jobs
+			// without one) gets a job before any gets a second.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				FairnessKey:    "tenant_id",
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      3,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{tenantAJob1.ID, tenantBJob1.ID, noTenantJob1.ID}, jobIDs)
+		})
+
+		t.Run("FairnessWeights", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tenantMetadata := func(tenantID string) []byte {
+				return []byte(`{"tenant_id":"` + tenantID + `"}`)
+			}
+
+			tenantBJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("b")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("b")})
+			tenantAJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+			tenantAJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a")})
+
+			// Tenant "a" has a weight of 2, so it gets two jobs in the time
+			// that tenant "b" gets one.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        testClientID,
+				FairnessKey:     "tenant_id",
+				FairnessWeights: map[string]int{"a": 2},
+				MaxAttemptedBy:  maxAttemptedBy,
+				MaxToLock:       3,
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{tenantBJob1.ID, tenantAJob1.ID, tenantAJob2.ID}, jobIDs)
+		})
+
+		t.Run("FairnessKeyRespectsPriority", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tenantMetadata := func(tenantID string) []byte {
+				return []byte(`{"tenant_id":"` + tenantID + `"}`)
+			}
+
+			tenantAJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a"), Priority: ptrutil.Ptr(1)})
+			tenantAJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("a"), Priority: ptrutil.Ptr(1)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: tenantMetadata("b"), Priority: ptrutil.Ptr(2)})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       testClientID,
+				FairnessKey:    "tenant_id",
+				MaxAttemptedBy: maxAttemptedBy,
+				MaxToLock:      2,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{tenantAJob1.ID, tenantAJob2.ID}, jobIDs)
+		})
+
+		t.Run("FairnessKeyConstrainedToMaxToLockByKind", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tenantMetadata := func(tenantID string) []byte {
+				return []byte(`{"tenant_id":"` + tenantID + `"}`)
+			}
+
+			tenantAJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: tenantMetadata("a")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: tenantMetadata("b")})
+			tenantBJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Metadata: tenantMetadata("b")})
+
+			// Only one kind1 job is locked even though there's room for more.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        testClientID,
+				FairnessKey:     "tenant_id",
+				MaxAttemptedBy:  maxAttemptedBy,
+				MaxToLock:       maxToLock,
+				MaxToLockByKind: map[string]int{"kind1": 1},
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			// Returned rows aren't necessarily in order.
+			jobIDs := sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID })
+			sort.Slice(jobIDs, func(i, j int) bool { return jobIDs[i] < jobIDs[j] })
+			require.Equal(t, []int64{tenantAJob1.ID, tenantBJob2.ID}, jobIDs)
+		})
+
 		t.Run("AttemptedByAtMaxTruncated", func(t *testing.T) {
 			t.Parallel()
 
@@ -436,6 +902,47 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 		})
 	})
 
+	t.Run("JobGetByExternalID", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("FetchesAnExistingJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:external_id":"ext_1"}`)})
+
+			// Not returned.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:external_id":"ext_2"}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			fetchedJob, err := exec.JobGetByExternalID(ctx, &riverdriver.JobGetByExternalIDParams{ExternalID: "ext_1"})
+			require.NoError(t, err)
+			require.Equal(t, job.ID, fetchedJob.ID)
+		})
+
+		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job, err := exec.JobGetByExternalID(ctx, &riverdriver.JobGetByExternalIDParams{ExternalID: "ext_1"})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+			require.Nil(t, job)
+		})
+
+		t.Run("ExternalIDUnique", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:external_id":"ext_1"}`)})
+
+			_, err := exec.JobInsertFull(ctx, testfactory.Job_Build(t, &testfactory.JobOpts{Metadata: []byte(`{"river:external_id":"ext_1"}`)}))
+			require.Error(t, err)
+		})
+	})
+
 	t.Run("JobGetByID", func(t *testing.T) {
 		t.Parallel()
 
@@ -508,6 +1015,86 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
 	})
 
+	t.Run("JobGetLeaseExpired", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			now         = time.Now().UTC()
+			attemptedAt = now.Add(-10 * time.Minute)
+			queue       = "lease_queue"
+		)
+
+		renewLease := func(job *rivertype.JobRow, leaseExpiresAt time.Time) {
+			t.Helper()
+
+			require.NoError(t, exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+				Attempt:        []int{job.Attempt},
+				ID:             []int64{job.ID},
+				LeaseExpiresAt: leaseExpiresAt,
+			}))
+		}
+
+		runningJobOpts := func(attemptedAt time.Time, queue string) *testfactory.JobOpts {
+			return &testfactory.JobOpts{
+				Attempt:     ptrutil.Ptr(1),
+				AttemptedAt: &attemptedAt,
+				Queue:       &queue,
+				State:       ptrutil.Ptr(rivertype.JobStateRunning),
+			}
+		}
+
+		expiredJob1 := testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, queue))
+		renewLease(expiredJob1, now.Add(-1*time.Minute))
+
+		expiredJob2 := testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, queue))
+		renewLease(expiredJob2, now.Add(-1*time.Minute))
+
+		// Not returned because lease hasn't expired yet.
+		notExpiredJob := testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, queue))
+		renewLease(notExpiredJob, now.Add(1*time.Minute))
+
+		// Not returned because in a different queue.
+		otherQueueJob := testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, "other_queue"))
+		renewLease(otherQueueJob, now.Add(-1*time.Minute))
+
+		// Not returned because it never had a lease.
+		_ = testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, queue))
+
+		// Not returned because its lease is left over from a previous attempt,
+		// even though the current attempt started before the lease expired.
+		staleLeaseJob := testfactory.Job(ctx, t, exec, runningJobOpts(attemptedAt, queue))
+		renewLease(staleLeaseJob, now.Add(-1*time.Minute))
+		_, err := exec.JobUpdateFull(ctx, &riverdriver.JobUpdateFullParams{
+			ID:                  staleLeaseJob.ID,
+			Attempt:             2,
+			AttemptDoUpdate:     true,
+			AttemptedAt:         ptrutil.Ptr(now.Add(-2 * time.Minute)),
+			AttemptedAtDoUpdate: true,
+		})
+		require.NoError(t, err)
+
+		expiredJobs, err := exec.JobGetLeaseExpired(ctx, &riverdriver.JobGetLeaseExpiredParams{
+			Max:   10,
+			Now:   now,
+			Queue: queue,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{expiredJob1.ID, expiredJob2.ID},
+			sliceutil.Map(expiredJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		// Respects max.
+		expiredJobs, err = exec.JobGetLeaseExpired(ctx, &riverdriver.JobGetLeaseExpiredParams{
+			Max:   1,
+			Now:   now,
+			Queue: queue,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{expiredJob1.ID},
+			sliceutil.Map(expiredJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+	})
+
 	t.Run("JobGetStuck", func(t *testing.T) {
 		t.Parallel()
 
@@ -540,6 +1127,7 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 		// Max two stuck
 		stuckJobs, err := exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
 			Max:          2,
+			Now:          time.Now().UTC(),
 			StuckHorizon: horizon,
 		})
 		require.NoError(t, err)
@@ -547,6 +1135,65 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 			sliceutil.Map(stuckJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
 	})
 
+	t.Run("JobGetStuckSkipsUnexpiredLeases", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			now         = time.Now().UTC()
+			attemptedAt = now.Add(-2 * time.Hour)
+			horizon     = now.Add(-1 * time.Hour)
+		)
+
+		renewLease := func(job *rivertype.JobRow, leaseExpiresAt time.Time) {
+			t.Helper()
+
+			require.NoError(t, exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+				Attempt:        []int{job.Attempt},
+				ID:             []int64{job.ID},
+				LeaseExpiresAt: leaseExpiresAt,
+			}))
+		}
+
+		runningJobOpts := &testfactory.JobOpts{
+			Attempt:     ptrutil.Ptr(1),
+			AttemptedAt: &attemptedAt,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		}
+
+		// Stuck because it never had a lease.
+		unleasedJob := testfactory.Job(ctx, t, exec, runningJobOpts)
+
+		// Stuck because its lease has expired.
+		expiredLeaseJob := testfactory.Job(ctx, t, exec, runningJobOpts)
+		renewLease(expiredLeaseJob, now.Add(-1*time.Minute))
+
+		// Stuck because its unexpired lease is left over from a previous attempt.
+		staleLeaseJob := testfactory.Job(ctx, t, exec, runningJobOpts)
+		renewLease(staleLeaseJob, now.Add(1*time.Minute))
+		_, err := exec.JobUpdateFull(ctx, &riverdriver.JobUpdateFullParams{
+			ID:              staleLeaseJob.ID,
+			Attempt:         2,
+			AttemptDoUpdate: true,
+		})
+		require.NoError(t, err)
+
+		// Not stuck because its lease is still being renewed, even though it
+		// was attempted before the horizon.
+		leasedJob := testfactory.Job(ctx, t, exec, runningJobOpts)
+		renewLease(leasedJob, now.Add(1*time.Minute))
+
+		stuckJobs, err := exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
+			Max:          10,
+			Now:          now,
+			StuckHorizon: horizon,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{unleasedJob.ID, expiredLeaseJob.ID, staleLeaseJob.ID},
+			sliceutil.Map(stuckJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+	})
+
 	t.Run("JobKindList", func(t *testing.T) {
 		t.Parallel()
 
@@ -613,6 +1260,35 @@ func exerciseJobRead[TTx any](ctx context.Context, t *testing.T, executorWithTx
 		})
 	})
 
+	t.Run("JobKindStorageUsage", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_b")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+			EncodedArgs: []byte(`{"data":"large"}`),
+			Errors:      [][]byte{[]byte(`{"error":"message"}`)},
+			Kind:        ptrutil.Ptr("kind_a"),
+		})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_a")})
+
+		usages, err := exec.JobKindStorageUsage(ctx, &riverdriver.JobKindStorageUsageParams{})
+		require.NoError(t, err)
+		require.Len(t, usages, 2)
+
+		require.Equal(t, "kind_a", usages[0].Kind) // sorted by kind
+		require.Equal(t, int64(2), usages[0].Count)
+		require.Positive(t, usages[0].ArgsBytes)
+		require.Positive(t, usages[0].ErrorsBytes)
+		require.Positive(t, usages[0].MetadataBytes)
+
+		require.Equal(t, "kind_b", usages[1].Kind)
+		require.Equal(t, int64(1), usages[1].Count)
+		require.Zero(t, usages[1].ErrorsBytes)
+		require.Greater(t, usages[0].ArgsBytes, usages[1].ArgsBytes)
+	})
+
 	t.Run("JobList", func(t *testing.T) {
 		t.Parallel()
 
@@ -37,6 +37,82 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 		}
 	}
 
+	t.Run("JobActivateMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("ActivatesPendingJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			var (
+				job1 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+				job2 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+				job3 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+			)
+
+			jobs, err := exec.JobActivateMany(ctx, &riverdriver.JobActivateManyParams{
+				ID:          []int64{job1.ID, job2.ID},
+				Now:         &now,
+				ScheduledAt: now.Add(-1 * time.Minute),
+			})
+			require.NoError(t, err)
+			require.Len(t, jobs, 2)
+			for _, job := range jobs {
+				require.Equal(t, rivertype.JobStateAvailable, job.State)
+				require.WithinDuration(t, now.Add(-1*time.Minute), job.ScheduledAt, time.Millisecond)
+			}
+
+			job3Updated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job3.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStatePending, job3Updated.State) // not included
+		})
+
+		t.Run("SchedulesFutureJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			jobs, err := exec.JobActivateMany(ctx, &riverdriver.JobActivateManyParams{
+				ID:          []int64{job.ID},
+				Now:         &now,
+				ScheduledAt: now.Add(1 * time.Hour),
+			})
+			require.NoError(t, err)
+			require.Len(t, jobs, 1)
+			require.Equal(t, rivertype.JobStateScheduled, jobs[0].State)
+			require.WithinDuration(t, now.Add(1*time.Hour), jobs[0].ScheduledAt, time.Millisecond)
+		})
+
+		t.Run("IgnoresNonPendingJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			jobs, err := exec.JobActivateMany(ctx, &riverdriver.JobActivateManyParams{
+				ID:          []int64{job.ID},
+				Now:         &now,
+				ScheduledAt: now,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobs)
+
+			jobUpdated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, jobUpdated.State)
+		})
+	})
+
 	t.Run("JobCancel", func(t *testing.T) {
 		t.Parallel()
 
@@ -100,6 +176,29 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 			require.Equal(t, "unique-key", string(jobAfter.UniqueKey))
 		})
 
+		t.Run("RecordsReason", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+			nowStr := now.Format(time.RFC3339Nano)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				State: ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			jobAfter, err := exec.JobCancel(ctx, &riverdriver.JobCancelParams{
+				ID:                job.ID,
+				CancelAttemptedAt: now,
+				ControlTopic:      string(notifier.NotificationTopicControl),
+				Reason:            "no longer needed",
+			})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, jobAfter.State)
+			require.JSONEq(t, fmt.Sprintf(`{"cancel_attempted_at":%q,"cancel_reason":"no longer needed"}`, nowStr), string(jobAfter.Metadata))
+		})
+
 		for _, startingState := range []rivertype.JobState{
 			rivertype.JobStateCancelled,
 			rivertype.JobStateCompleted,
@@ -142,6 +241,158 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 		})
 	})
 
+	t.Run("JobChangeQueueMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("ChangesQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"meta": "data"}`),
+				Queue:    ptrutil.Ptr("wrong_queue"),
+				State:    ptrutil.Ptr(rivertype.JobStateScheduled),
+			})
+
+			// Does not match predicate (makes sure where clause is working).
+			otherJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("wrong_queue")})
+
+			updatedJobs, err := exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+				Max:           100,
+				NamedArgs:     map[string]any{"job_id_123": job.ID},
+				OrderByClause: "id",
+				Queue:         "right_queue",
+				WhereClause:   "id = @job_id_123",
+			})
+			require.NoError(t, err)
+			require.Len(t, updatedJobs, 1)
+
+			updatedJob := updatedJobs[0]
+			require.Equal(t, job.ID, updatedJob.ID)
+			require.Equal(t, job.Metadata, updatedJob.Metadata)
+			require.Equal(t, "right_queue", updatedJob.Queue)
+			require.Equal(t, rivertype.JobStateScheduled, updatedJob.State)
+
+			updatedJob, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.Equal(t, "right_queue", updatedJob.Queue)
+
+			// Non-matching job should remain in its original queue.
+			otherJob, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: otherJob.ID})
+			require.NoError(t, err)
+			require.Equal(t, "wrong_queue", otherJob.Queue)
+		})
+
+		t.Run("IgnoresRunningJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			updatedJobs, err := exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+				Max:           100,
+				NamedArgs:     map[string]any{"job_id": job.ID},
+				OrderByClause: "id",
+				Queue:         "right_queue",
+				WhereClause:   "id = @job_id",
+			})
+			require.NoError(t, err)
+			require.Empty(t, updatedJobs)
+		})
+
+		t.Run("IgnoresJobsAlreadyInQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("right_queue")})
+
+			updatedJobs, err := exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+				Max:           100,
+				OrderByClause: "id",
+				Queue:         "right_queue",
+				WhereClause:   "true",
+			})
+			require.NoError(t, err)
+			require.Empty(t, updatedJobs)
+		})
+
+		t.Run("MaxAndSortedResults", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				job1 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+				job2 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+				job3 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			)
+
+			updatedJobs, err := exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+				Max:           2,
+				OrderByClause: "id",
+				Queue:         "right_queue",
+				WhereClause:   "true",
+			})
+			require.NoError(t, err)
+			require.Len(t, updatedJobs, 2)
+			require.Equal(t, job1.ID, updatedJobs[0].ID)
+			require.Equal(t, job2.ID, updatedJobs[1].ID)
+
+			updatedJobs, err = exec.JobChangeQueueMany(ctx, &riverdriver.JobChangeQueueManyParams{
+				Max:           2,
+				OrderByClause: "id",
+				Queue:         "right_queue",
+				WhereClause:   "true",
+			})
+			require.NoError(t, err)
+			require.Len(t, updatedJobs, 1)
+			require.Equal(t, job3.ID, updatedJobs[0].ID)
+		})
+	})
+
+	t.Run("JobLeaseRenewMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		leaseExpiresAt := time.Now().UTC().Add(1 * time.Minute)
+
+		var (
+			job1 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			job2 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			job3 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			job4 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		)
+
+		require.NoError(t, exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+			Attempt:        []int{1, 1, 1},
+			ID:             []int64{job1.ID, job2.ID, job3.ID},
+			LeaseExpiresAt: leaseExpiresAt,
+		}))
+
+		leaseExists := func(job *rivertype.JobRow) bool {
+			t.Helper()
+
+			updatedJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			return gjson.GetBytes(updatedJob.Metadata, "river:lease_expires_at").Exists()
+		}
+
+		require.True(t, leaseExists(job1))
+		require.False(t, leaseExists(job2)) // attempt mismatch
+
+		// The lease is tied to the attempt that took it.
+		updatedJob1, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job1.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), gjson.GetBytes(updatedJob1.Metadata, "river:lease_attempt").Int())
+
+		require.False(t, leaseExists(job3)) // not running
+		require.False(t, leaseExists(job4)) // not included
+	})
+
 	t.Run("JobRescueMany", func(t *testing.T) {
 		t.Parallel()
 
@@ -997,6 +1248,54 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 		})
 	})
 
+	t.Run("JobUpdateArgsEncryptionKey", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("UpdatesArgsAndKey", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				EncodedArgs: []byte(`"b2xk"`),
+				Metadata:    []byte(`{"foo":"bar","river:args_encryption_key_id":"key1"}`),
+			})
+
+			updatedJob, err := exec.JobUpdateArgsEncryptionKey(ctx, &riverdriver.JobUpdateArgsEncryptionKeyParams{
+				Args:          []byte(`"bmV3"`),
+				ID:            job.ID,
+				KeyID:         "key2",
+				PreviousKeyID: "key1",
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `"bmV3"`, string(updatedJob.EncodedArgs))
+			require.JSONEq(t, `{"foo":"bar","river:args_encryption_key_id":"key2"}`, string(updatedJob.Metadata))
+		})
+
+		t.Run("PreviousKeyMismatch", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				EncodedArgs: []byte(`"b2xk"`),
+				Metadata:    []byte(`{"river:args_encryption_key_id":"key2"}`),
+			})
+
+			_, err := exec.JobUpdateArgsEncryptionKey(ctx, &riverdriver.JobUpdateArgsEncryptionKeyParams{
+				Args:          []byte(`"bmV3"`),
+				ID:            job.ID,
+				KeyID:         "key3",
+				PreviousKeyID: "key1",
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			job, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.JSONEq(t, `"b2xk"`, string(job.EncodedArgs))
+		})
+	})
+
 	t.Run("JobUpdateFull", func(t *testing.T) {
 		t.Parallel()
 
@@ -1011,6 +1310,8 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 
 			updatedJob, err := exec.JobUpdateFull(ctx, &riverdriver.JobUpdateFullParams{
 				ID:                  job.ID,
+				ArgsDoUpdate:        true,
+				Args:                []byte(`{"new":"args"}`),
 				AttemptDoUpdate:     true,
 				Attempt:             7,
 				AttemptedAtDoUpdate: true,
@@ -1029,6 +1330,7 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 				State:               rivertype.JobStateDiscarded,
 			})
 			require.NoError(t, err)
+			require.JSONEq(t, `{"new":"args"}`, string(updatedJob.EncodedArgs))
 			require.Equal(t, 7, updatedJob.Attempt)
 			require.WithinDuration(t, now, *updatedJob.AttemptedAt, bundle.driver.TimePrecision())
 			require.Equal(t, []string{"worker1"}, updatedJob.AttemptedBy)
@@ -1050,6 +1352,7 @@ func exerciseJobUpdate[TTx any](ctx context.Context, t *testing.T, executorWithT
 				ID: job.ID,
 			})
 			require.NoError(t, err)
+			require.JSONEq(t, string(job.EncodedArgs), string(updatedJob.EncodedArgs))
 			require.Equal(t, job.Attempt, updatedJob.Attempt)
 			require.Nil(t, updatedJob.AttemptedAt)
 			require.Empty(t, updatedJob.AttemptedBy)
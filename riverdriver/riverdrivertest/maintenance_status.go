@@ -0,0 +1,142 @@
+package riverdrivertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+)
+
+func exerciseMaintenanceStatus[TTx any](ctx context.Context, t *testing.T, executorWithTx func(ctx context.Context, t *testing.T) (riverdriver.Executor, riverdriver.Driver[TTx])) {
+	t.Helper()
+
+	t.Run("MaintenanceStatusList", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("Empty", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			statuses, err := exec.MaintenanceStatusList(ctx, &riverdriver.MaintenanceStatusListParams{})
+			require.NoError(t, err)
+			require.Empty(t, statuses)
+		})
+
+		t.Run("OrderedByName", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			now := time.Now().UTC()
+
+			for _, name := range []string{"JobScheduler", "JobCleaner", "Reindexer"} {
+				_, err := exec.MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+					ClientID:   "client1",
+					FinishedAt: now,
+					Name:       name,
+					StartedAt:  now,
+				})
+				require.NoError(t, err)
+			}
+
+			statuses, err := exec.MaintenanceStatusList(ctx, &riverdriver.MaintenanceStatusListParams{})
+			require.NoError(t, err)
+			require.Len(t, statuses, 3)
+			require.Equal(t, "JobCleaner", statuses[0].Name)
+			require.Equal(t, "JobScheduler", statuses[1].Name)
+			require.Equal(t, "Reindexer", statuses[2].Name)
+		})
+	})
+
+	t.Run("MaintenanceStatusRecordRun", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("SuccessesAndFailures", func(t *testing.T) {
+			t.Parallel()
+
+			exec, driver := executorWithTx(ctx, t)
+
+			var (
+				now       = time.Now().UTC()
+				startedAt = now.Add(-5 * time.Second)
+			)
+
+			status, err := exec.MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+				ClientID:   "client1",
+				FinishedAt: now,
+				Name:       "JobCleaner",
+				NumRows:    123,
+				StartedAt:  startedAt,
+			})
+			require.NoError(t, err)
+			require.Equal(t, "client1", status.ClientID)
+			require.Zero(t, status.ConsecutiveFailures)
+			require.Nil(t, status.Error)
+			require.WithinDuration(t, now, status.FinishedAt, driver.TimePrecision())
+			require.NotNil(t, status.LastSucceededAt)
+			require.WithinDuration(t, now, *status.LastSucceededAt, driver.TimePrecision())
+			require.Equal(t, "JobCleaner", status.Name)
+			require.Equal(t, 123, status.NumRows)
+			require.WithinDuration(t, startedAt, status.StartedAt, driver.TimePrecision())
+
+			// Failures replace the last run, but keep the last success and
+			// count up until the next success.
+			for i := range 2 {
+				status, err = exec.MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+					ClientID:   "client2",
+					Error:      ptrutil.Ptr("error cleaning jobs"),
+					FinishedAt: now.Add(time.Duration(i+1) * time.Minute),
+					Name:       "JobCleaner",
+					StartedAt:  startedAt.Add(time.Duration(i+1) * time.Minute),
+				})
+				require.NoError(t, err)
+				require.Equal(t, "client2", status.ClientID)
+				require.Equal(t, i+1, status.ConsecutiveFailures)
+				require.Equal(t, ptrutil.Ptr("error cleaning jobs"), status.Error)
+				require.WithinDuration(t, now.Add(time.Duration(i+1)*time.Minute), status.FinishedAt, driver.TimePrecision())
+				require.NotNil(t, status.LastSucceededAt)
+				require.WithinDuration(t, now, *status.LastSucceededAt, driver.TimePrecision())
+				require.Zero(t, status.NumRows)
+			}
+
+			later := now.Add(time.Hour)
+
+			status, err = exec.MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+				ClientID:   "client2",
+				FinishedAt: later,
+				Name:       "JobCleaner",
+				NumRows:    5,
+				StartedAt:  later,
+			})
+			require.NoError(t, err)
+			require.Zero(t, status.ConsecutiveFailures)
+			require.Nil(t, status.Error)
+			require.NotNil(t, status.LastSucceededAt)
+			require.WithinDuration(t, later, *status.LastSucceededAt, driver.TimePrecision())
+			require.Equal(t, 5, status.NumRows)
+		})
+
+		t.Run("FirstRunFailed", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			status, err := exec.MaintenanceStatusRecordRun(ctx, &riverdriver.MaintenanceStatusRecordRunParams{
+				ClientID:   "client1",
+				Error:      ptrutil.Ptr("error scheduling jobs"),
+				FinishedAt: time.Now().UTC(),
+				Name:       "JobScheduler",
+				StartedAt:  time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, status.ConsecutiveFailures)
+			require.Equal(t, ptrutil.Ptr("error scheduling jobs"), status.Error)
+			require.Nil(t, status.LastSucceededAt)
+		})
+	})
+}
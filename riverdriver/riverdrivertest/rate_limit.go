@@ -0,0 +1,73 @@
+package riverdrivertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+func exerciseRateLimit[TTx any](ctx context.Context, t *testing.T, executorWithTx func(ctx context.Context, t *testing.T) (riverdriver.Executor, riverdriver.Driver[TTx])) {
+	t.Helper()
+
+	t.Run("RateLimitReserve", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("TakesTokensAndRefills", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			now := time.Now().UTC()
+
+			reserve := func(now time.Time) float64 {
+				t.Helper()
+
+				tokens, err := exec.RateLimitReserve(ctx, &riverdriver.RateLimitReserveParams{
+					Burst: 2,
+					Name:  "limiter",
+					Now:   &now,
+					Rate:  1, // per second
+				})
+				require.NoError(t, err)
+				return tokens
+			}
+
+			require.InDelta(t, 1.0, reserve(now), 0.01)
+			require.InDelta(t, 0.0, reserve(now), 0.01)
+			require.InDelta(t, -1.0, reserve(now), 0.01)
+
+			// Refills at one token per second.
+			require.InDelta(t, -0.5, reserve(now.Add(1500*time.Millisecond)), 0.01)
+
+			// Refill is capped at burst.
+			require.InDelta(t, 1.0, reserve(now.Add(time.Hour)), 0.01)
+		})
+
+		t.Run("NamesAreIndependent", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := executorWithTx(ctx, t)
+
+			for _, name := range []string{"limiter1", "limiter1", "limiter2"} {
+				_, err := exec.RateLimitReserve(ctx, &riverdriver.RateLimitReserveParams{
+					Burst: 1,
+					Name:  name,
+					Rate:  1,
+				})
+				require.NoError(t, err)
+			}
+
+			tokens, err := exec.RateLimitReserve(ctx, &riverdriver.RateLimitReserveParams{
+				Burst: 1,
+				Name:  "limiter2",
+				Rate:  0.001,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, -1.0, tokens, 0.01)
+		})
+	})
+}
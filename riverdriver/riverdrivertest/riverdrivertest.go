@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -42,8 +43,11 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 	exerciseJobRead(ctx, t, executorWithTx)
 	exerciseJobUpdate(ctx, t, executorWithTx)
 	exerciseJobDelete(ctx, t, executorWithTx)
+	exerciseJobKindRegistration(ctx, t, executorWithTx)
 	exerciseLeader(ctx, t, executorWithTx)
+	exerciseMaintenanceStatus(ctx, t, executorWithTx)
 	exerciseQueue(ctx, t, executorWithTx)
+	exerciseRateLimit(ctx, t, executorWithTx)
 }
 
 const testClientID = "test-client-id"
@@ -80,6 +84,22 @@ func exerciseDriverPool[TTx any](ctx context.Context, t *testing.T,
 		})
 	})
 
+	t.Run("PoolStats", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("PoolStatsOnDriverWithSchema", func(t *testing.T) {
+			t.Parallel()
+
+			driver, _ := driverWithSchema(ctx, t, nil)
+			stats := driver.PoolStats()
+			require.NotNil(t, stats)
+			require.GreaterOrEqual(t, stats.NumAcquired, 0)
+			require.GreaterOrEqual(t, stats.NumMax, 0)
+			require.GreaterOrEqual(t, stats.NumWaits, int64(0))
+			require.GreaterOrEqual(t, stats.WaitDuration, time.Duration(0))
+		})
+	})
+
 	t.Run("SupportsListenNotify", func(t *testing.T) {
 		t.Parallel()
 
@@ -51,3 +51,47 @@ func (r iteratorForJobInsertFastManyCopyFrom) Err() error {
 func (q *Queries) JobInsertFastManyCopyFrom(ctx context.Context, db DBTX, arg []*JobInsertFastManyCopyFromParams) (int64, error) {
 	return db.CopyFrom(ctx, []string{"river_job"}, []string{"args", "created_at", "kind", "max_attempts", "metadata", "priority", "queue", "scheduled_at", "state", "tags", "unique_key", "unique_states"}, &iteratorForJobInsertFastManyCopyFrom{rows: arg})
 }
+
+// iteratorForJobInsertStagingCopyFrom implements pgx.CopyFromSource.
+type iteratorForJobInsertStagingCopyFrom struct {
+	rows                 []*JobInsertStagingCopyFromParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForJobInsertStagingCopyFrom) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForJobInsertStagingCopyFrom) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].Args,
+		r.rows[0].CreatedAt,
+		r.rows[0].Kind,
+		r.rows[0].MaxAttempts,
+		r.rows[0].Metadata,
+		r.rows[0].Priority,
+		r.rows[0].Queue,
+		r.rows[0].ScheduledAt,
+		r.rows[0].State,
+		r.rows[0].Tags,
+		r.rows[0].UniqueKey,
+		r.rows[0].UniqueStates,
+	}, nil
+}
+
+func (r iteratorForJobInsertStagingCopyFrom) Err() error {
+	return nil
+}
+
+func (q *Queries) JobInsertStagingCopyFrom(ctx context.Context, db DBTX, arg []*JobInsertStagingCopyFromParams) (int64, error) {
+	return db.CopyFrom(ctx, []string{"river_job_insert_staging"}, []string{"id", "args", "created_at", "kind", "max_attempts", "metadata", "priority", "queue", "scheduled_at", "state", "tags", "unique_key", "unique_states"}, &iteratorForJobInsertStagingCopyFrom{rows: arg})
+}
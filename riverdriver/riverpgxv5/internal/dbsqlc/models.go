@@ -81,6 +81,37 @@ type RiverJob struct {
 	UniqueStates pgtype.Bits
 }
 
+type RiverJobInsertStaging struct {
+	Ordinal      int64
+	ID           int64
+	Args         []byte
+	CreatedAt    time.Time
+	Kind         string
+	MaxAttempts  int16
+	Metadata     []byte
+	Priority     int16
+	Queue        string
+	ScheduledAt  time.Time
+	State        string
+	Tags         []string
+	UniqueKey    []byte
+	UniqueStates int32
+}
+
+type RiverJobKind struct {
+	Kind       string
+	Version    string
+	ArgsSchema []byte
+	ClientID   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RiverJobKindPause struct {
+	Kind     string
+	PausedAt time.Time
+}
+
 type RiverLeader struct {
 	ElectedAt time.Time
 	ExpiresAt time.Time
@@ -88,6 +119,17 @@ type RiverLeader struct {
 	Name      string
 }
 
+type RiverMaintenanceStatus struct {
+	Name                string
+	ClientID            string
+	ConsecutiveFailures int32
+	Error               pgtype.Text
+	FinishedAt          time.Time
+	LastSucceededAt     *time.Time
+	NumRows             int64
+	StartedAt           time.Time
+}
+
 type RiverMigration struct {
 	Line      string
 	Version   int64
@@ -108,3 +150,9 @@ type RiverQueue struct {
 	PausedAt  *time.Time
 	UpdatedAt time.Time
 }
+
+type RiverRateLimit struct {
+	Name      string
+	Tokens    float64
+	UpdatedAt time.Time
+}
@@ -12,3 +12,13 @@ SELECT pg_notify(
     topic_to_notify.payload
   )
 FROM topic_to_notify;
+
+-- name: PGTransactionTimeoutsGet :one
+SELECT
+    current_setting('lock_timeout')::text AS lock_timeout,
+    current_setting('statement_timeout')::text AS statement_timeout;
+
+-- name: PGTransactionTimeoutsSet :exec
+SELECT
+    set_config('lock_timeout', coalesce(nullif(@lock_timeout::text, ''), current_setting('lock_timeout')), true),
+    set_config('statement_timeout', coalesce(nullif(@statement_timeout::text, ''), current_setting('statement_timeout')), true);
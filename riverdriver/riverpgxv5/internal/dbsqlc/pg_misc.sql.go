@@ -43,3 +43,37 @@ func (q *Queries) PGNotifyMany(ctx context.Context, db DBTX, arg *PGNotifyManyPa
 	_, err := db.Exec(ctx, pGNotifyMany, arg.Schema, arg.Topic, arg.Payload)
 	return err
 }
+
+const pGTransactionTimeoutsGet = `-- name: PGTransactionTimeoutsGet :one
+SELECT
+    current_setting('lock_timeout')::text AS lock_timeout,
+    current_setting('statement_timeout')::text AS statement_timeout
+`
+
+type PGTransactionTimeoutsGetRow struct {
+	LockTimeout      string
+	StatementTimeout string
+}
+
+func (q *Queries) PGTransactionTimeoutsGet(ctx context.Context, db DBTX) (*PGTransactionTimeoutsGetRow, error) {
+	row := db.QueryRow(ctx, pGTransactionTimeoutsGet)
+	var i PGTransactionTimeoutsGetRow
+	err := row.Scan(&i.LockTimeout, &i.StatementTimeout)
+	return &i, err
+}
+
+const pGTransactionTimeoutsSet = `-- name: PGTransactionTimeoutsSet :exec
+SELECT
+    set_config('lock_timeout', coalesce(nullif($1::text, ''), current_setting('lock_timeout')), true),
+    set_config('statement_timeout', coalesce(nullif($2::text, ''), current_setting('statement_timeout')), true)
+`
+
+type PGTransactionTimeoutsSetParams struct {
+	LockTimeout      string
+	StatementTimeout string
+}
+
+func (q *Queries) PGTransactionTimeoutsSet(ctx context.Context, db DBTX, arg *PGTransactionTimeoutsSetParams) error {
+	_, err := db.Exec(ctx, pGTransactionTimeoutsSet, arg.LockTimeout, arg.StatementTimeout)
+	return err
+}
@@ -37,6 +37,15 @@ CREATE TABLE river_job (
     CONSTRAINT kind_length CHECK (char_length(kind) > 0 AND char_length(kind) < 128)
 );
 
+-- name: JobActivateMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    scheduled_at = @scheduled_at::timestamptz,
+    state = CASE WHEN @scheduled_at::timestamptz <= coalesce(sqlc.narg('now')::timestamptz, now()) THEN 'available' ELSE 'scheduled' END
+WHERE id = any(@id::bigint[])
+    AND state = 'pending'
+RETURNING *;
+
 -- name: JobCancel :one
 WITH locked_job AS (
     SELECT
@@ -50,7 +59,7 @@ notification AS (
         id,
         pg_notify(
             concat(coalesce(sqlc.narg('schema')::text, current_schema()), '.', @control_topic::text),
-            json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text
+            json_strip_nulls(json_build_object('action', 'cancel', 'job_id', id, 'queue', queue, 'reason', nullif(@reason::text, ''), 'v', 1))::text
         )
     FROM
         locked_job
@@ -68,6 +77,7 @@ updated_job AS (
         -- Mark the job as cancelled by query so that the rescuer knows not to
         -- rescue it, even if it gets stuck in the running state:
         metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], @cancel_attempted_at::jsonb, true)
+            || CASE WHEN @reason::text = '' THEN '{}'::jsonb ELSE jsonb_build_object('cancel_reason', @reason::text) END
     FROM notification
     WHERE river_job.id = notification.id
     RETURNING river_job.*
@@ -80,6 +90,28 @@ UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobChangeQueueMany :many
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+        AND queue != @queue::text
+        AND state != 'running'
+    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+    LIMIT @max::int
+    FOR UPDATE
+    SKIP LOCKED
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET queue = @queue::text
+    WHERE id IN (SELECT id FROM jobs_to_update)
+    RETURNING *
+)
+SELECT *
+FROM updated_jobs
+ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */;
+
 -- name: JobCountByAllStates :many
 SELECT state, count(*)
 FROM /* TEMPLATE: schema */ river_job
@@ -93,7 +125,9 @@ WITH all_queues AS (
 running_job_counts AS (
     SELECT
         queue,
-        COUNT(*) AS count
+        COUNT(*) AS count,
+        -- The client working a job is the last one to have attempted it.
+        COUNT(DISTINCT attempted_by[array_length(attempted_by, 1)]) AS count_clients_with_running_jobs
     FROM /* TEMPLATE: schema */river_job
     WHERE queue = ANY(@queue_names::text[])
         AND state = 'running'
@@ -114,7 +148,8 @@ available_job_counts AS (
 SELECT
     all_queues.queue,
     COALESCE(available_job_counts.count, 0) AS count_available,
-    COALESCE(running_job_counts.count, 0) AS count_running
+    COALESCE(running_job_counts.count, 0) AS count_running,
+    COALESCE(running_job_counts.count_clients_with_running_jobs, 0) AS count_clients_with_running_jobs
 FROM
     all_queues
 LEFT JOIN
@@ -128,6 +163,27 @@ SELECT count(*)
 FROM /* TEMPLATE: schema */river_job
 WHERE state = @state;
 
+-- name: JobCountMatching :one
+SELECT count(*)
+FROM (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+    LIMIT @max::int
+) AS matching_job;
+
+-- name: JobCountRunningByClient :many
+SELECT
+    -- The client working a job is the last one to have attempted it.
+    attempted_by[array_length(attempted_by, 1)]::text AS client_id,
+    count(*) AS count_running,
+    max(attempted_at)::timestamptz AS last_attempted_at
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND attempted_by IS NOT NULL
+GROUP BY client_id
+ORDER BY client_id ASC;
+
 -- name: JobDelete :one
 WITH job_to_delete AS (
     SELECT id
@@ -197,6 +253,14 @@ FROM /* TEMPLATE: schema */river_job
 WHERE id IN (SELECT id FROM deleted_jobs)
 ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */;
 
+-- name: JobGetArgsEncryptionKeyStale :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata ->> 'river:args_encryption_key_id' <> @current_key_id::text
+    AND id > @after_id::bigint
+ORDER BY id
+LIMIT @max::integer;
+
 -- name: JobGetAvailable :many
 WITH locked_jobs AS (
     SELECT
@@ -207,6 +271,26 @@ WITH locked_jobs AS (
         state = 'available'
         AND queue = @queue::text
         AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all(@kinds_excluded::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ @labels::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
     ORDER BY
         priority ASC,
         scheduled_at ASC,
@@ -236,6 +320,174 @@ WHERE
 RETURNING
     river_job.*;
 
+-- name: JobGetAvailableFair :many
+WITH candidate_jobs AS (
+    SELECT
+        id,
+        row_number() OVER (
+            PARTITION BY metadata ->> @fairness_key::text, priority
+            ORDER BY scheduled_at ASC, id ASC
+        ) AS fairness_rank,
+        coalesce((@fairness_weights::jsonb ->> (metadata ->> @fairness_key::text))::integer, 1) AS fairness_weight,
+        row_number() OVER (
+            PARTITION BY kind
+            ORDER BY priority ASC, scheduled_at ASC, id ASC
+        ) AS kind_rank,
+        coalesce((@max_to_lock_by_kind::jsonb ->> kind)::integer, @max_to_lock::integer) AS kind_max_to_lock
+    FROM
+        /* TEMPLATE: schema */river_job
+    WHERE
+        state = 'available'
+        AND queue = @queue::text
+        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all(@kinds_excluded::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ @labels::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
+),
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN candidate_jobs ON candidate_jobs.id = river_job.id
+    WHERE
+        -- Rechecked once the row is locked in case the job was fetched
+        -- elsewhere after candidates were selected.
+        river_job.state = 'available'
+        AND candidate_jobs.kind_rank <= candidate_jobs.kind_max_to_lock
+    ORDER BY
+        river_job.priority ASC,
+        candidate_jobs.fairness_rank::double precision / candidate_jobs.fairness_weight ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
+    LIMIT @max_to_lock::integer
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(sqlc.narg('now')::timestamptz, now()),
+    attempted_by = array_append(
+        CASE WHEN array_length(river_job.attempted_by, 1) >= @max_attempted_by::int
+        -- +2 instead of +1 because Postgres array indexing starts at 1, not 0.
+        THEN river_job.attempted_by[array_length(river_job.attempted_by, 1) + 2 - @max_attempted_by:]
+        ELSE river_job.attempted_by
+        END,
+        @attempted_by::text
+    )
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.*;
+
+-- name: JobGetAvailableLimitedByKind :many
+WITH candidate_jobs AS (
+    SELECT
+        id,
+        row_number() OVER (
+            PARTITION BY kind
+            ORDER BY priority ASC, scheduled_at ASC, id ASC
+        ) AS kind_rank,
+        coalesce((@max_to_lock_by_kind::jsonb ->> kind)::integer, @max_to_lock::integer) AS kind_max_to_lock
+    FROM
+        /* TEMPLATE: schema */river_job
+    WHERE
+        state = 'available'
+        AND queue = @queue::text
+        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind <> all(@kinds_excluded::text[])
+        AND (
+            metadata -> 'river:required_labels' IS NULL
+            OR metadata -> 'river:required_labels' <@ @labels::jsonb
+        )
+        AND (
+            metadata ->> 'river:partition_key' IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE partition_job.metadata ->> 'river:partition_key' = river_job.metadata ->> 'river:partition_key'
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
+),
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN candidate_jobs ON candidate_jobs.id = river_job.id
+    WHERE
+        -- Rechecked once the row is locked in case the job was fetched
+        -- elsewhere after candidates were selected.
+        river_job.state = 'available'
+        -- Jobs ranked past their kind's limit aren't locked. One within it
+        -- that's skipped because it's locked elsewhere doesn't make room for
+        -- another, so fewer jobs than the limit may be locked, but never
+        -- more.
+        AND candidate_jobs.kind_rank <= candidate_jobs.kind_max_to_lock
+    ORDER BY
+        river_job.priority ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
+    LIMIT @max_to_lock::integer
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(sqlc.narg('now')::timestamptz, now()),
+    attempted_by = array_append(
+        CASE WHEN array_length(river_job.attempted_by, 1) >= @max_attempted_by::int
+        -- +2 instead of +1 because Postgres array indexing starts at 1, not 0.
+        THEN river_job.attempted_by[array_length(river_job.attempted_by, 1) + 2 - @max_attempted_by:]
+        ELSE river_job.attempted_by
+        END,
+        @attempted_by::text
+    )
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.*;
+
+-- name: JobGetByExternalID :one
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata ->> 'river:external_id' = @external_id::text
+LIMIT 1;
+
 -- name: JobGetByID :one
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -254,11 +506,30 @@ FROM /* TEMPLATE: schema */river_job
 WHERE kind = any(@kind::text[])
 ORDER BY id;
 
+-- name: JobGetLeaseExpired :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND queue = @queue
+    -- A lease is tied to the attempt that took it, so one left over by a
+    -- previous attempt doesn't count as expired for the current one.
+    AND (metadata ->> 'river:lease_attempt')::smallint = attempt
+    AND (metadata ->> 'river:lease_expires_at')::timestamptz < @now::timestamptz
+ORDER BY id
+LIMIT @max;
+
 -- name: JobGetStuck :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
     AND attempted_at < @stuck_horizon::timestamptz
+    -- A job whose current attempt holds an unexpired lease is still being
+    -- renewed by the client working it, so it's left until the lease lapses.
+    AND NOT coalesce(
+        (metadata ->> 'river:lease_attempt')::smallint = attempt
+            AND (metadata ->> 'river:lease_expires_at')::timestamptz >= @now::timestamptz,
+        false
+    )
 ORDER BY id
 LIMIT @max;
 
@@ -277,7 +548,8 @@ WITH raw_job_data AS (
         unnest(@state::text[]) AS state,
         unnest(@tags::text[]) AS tags,
         unnest(@unique_key::bytea[]) AS unique_key,
-        unnest(@unique_states::integer[]) AS unique_states
+        unnest(@unique_states::integer[]) AS unique_states,
+        unnest(@scheduled_in::bigint[]) AS scheduled_in
 )
 INSERT INTO /* TEMPLATE: schema */river_job(
     id,
@@ -302,7 +574,9 @@ INSERT INTO /* TEMPLATE: schema */river_job(
     coalesce(metadata, '{}'::jsonb) AS metadata,
     priority,
     queue,
-    coalesce(nullif(scheduled_at, '0001-01-01 00:00:00 +0000'), now()) AS scheduled_at,
+    -- `scheduled_in` is in microseconds and schedules relative to the
+    -- database's clock for jobs without an explicit `scheduled_at`.
+    coalesce(nullif(scheduled_at, '0001-01-01 00:00:00 +0000'), now() + scheduled_in * interval '1 microsecond') AS scheduled_at,
     state::/* TEMPLATE: schema */river_job_state,
     string_to_array(tags, ',')::varchar(255)[],
     -- `nullif` is required for `lib/pq`, which doesn't do a good job of reading
@@ -467,6 +741,29 @@ WHERE (@match = '' OR kind ILIKE '%' || @match || '%')
 ORDER BY kind ASC
 LIMIT @max;
 
+-- name: JobKindStorageUsage :many
+SELECT
+    kind,
+    count(*) AS count,
+    coalesce(sum(pg_column_size(args)), 0)::bigint AS args_bytes,
+    coalesce(sum(pg_column_size(errors)), 0)::bigint AS errors_bytes,
+    coalesce(sum(pg_column_size(metadata)), 0)::bigint AS metadata_bytes
+FROM /* TEMPLATE: schema */river_job
+GROUP BY kind
+ORDER BY kind ASC;
+
+-- name: JobLeaseRenewMany :exec
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = river_job.metadata || jsonb_build_object('river:lease_attempt', river_job.attempt, 'river:lease_expires_at', @lease_expires_at::timestamptz)
+FROM (
+    SELECT
+        unnest(@id::bigint[]) AS id,
+        unnest(@attempt::smallint[]) AS attempt
+) AS lease
+WHERE river_job.id = lease.id
+    AND river_job.attempt = lease.attempt
+    AND river_job.state = 'running';
+
 -- name: JobList :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -712,11 +1009,21 @@ FROM
 WHERE river_job.id = locked_job.id
 RETURNING river_job.*;
 
+-- name: JobUpdateArgsEncryptionKey :one
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    args = @args::jsonb,
+    metadata = jsonb_set(metadata, '{river:args_encryption_key_id}', to_jsonb(@key_id::text))
+WHERE id = @id
+    AND metadata ->> 'river:args_encryption_key_id' = @previous_key_id::text
+RETURNING *;
+
 -- A generalized update for any property on a job. This brings in a large number
 -- of parameters and therefore may be more suitable for testing than production.
 -- name: JobUpdateFull :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
+    args = CASE WHEN @args_do_update::boolean THEN @args::jsonb ELSE args END,
     attempt = CASE WHEN @attempt_do_update::boolean THEN @attempt ELSE attempt END,
     attempted_at = CASE WHEN @attempted_at_do_update::boolean THEN @attempted_at ELSE attempted_at END,
     attempted_by = CASE WHEN @attempted_by_do_update::boolean THEN @attempted_by ELSE attempted_by END,
@@ -26,3 +26,100 @@ INSERT INTO river_job(
     @unique_key,
     @unique_states
 );
+
+-- Staging table for JobInsertFastManyCopyFrom, which is copied into
+-- before jobs are moved to `river_job` so that an insert can use `COPY FROM`
+-- while still handling unique conflicts and returning inserted rows. Dropped
+-- at the end of the transaction it's created in.
+-- name: JobInsertStagingCreate :exec
+CREATE TEMPORARY TABLE river_job_insert_staging (
+    ordinal bigint GENERATED ALWAYS AS IDENTITY,
+    id bigint NOT NULL,
+    args jsonb NOT NULL,
+    created_at timestamptz NOT NULL,
+    kind text NOT NULL,
+    max_attempts smallint NOT NULL,
+    metadata jsonb NOT NULL,
+    priority smallint NOT NULL,
+    queue text NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    state text NOT NULL,
+    tags varchar(255)[] NOT NULL,
+    unique_key bytea,
+    unique_states integer NOT NULL
+) ON COMMIT DROP;
+
+-- name: JobInsertStagingCopyFrom :copyfrom
+INSERT INTO river_job_insert_staging(
+    id,
+    args,
+    created_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state,
+    tags,
+    unique_key,
+    unique_states
+) VALUES (
+    @id,
+    @args,
+    @created_at,
+    @kind,
+    @max_attempts,
+    @metadata,
+    @priority,
+    @queue,
+    @scheduled_at,
+    @state,
+    @tags,
+    @unique_key,
+    @unique_states
+);
+
+-- name: JobInsertStagingDrop :exec
+DROP TABLE river_job_insert_staging;
+
+-- Moves staged jobs into `river_job` in the order they were staged, with the
+-- same handling of unique conflicts as JobInsertFastMany.
+-- name: JobInsertFromStaging :many
+INSERT INTO /* TEMPLATE: schema */river_job(
+    id,
+    args,
+    created_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state,
+    tags,
+    unique_key,
+    unique_states
+) SELECT
+    coalesce(nullif(id, 0), nextval('/* TEMPLATE: schema */river_job_id_seq'::regclass)),
+    args,
+    created_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state::/* TEMPLATE: schema */river_job_state,
+    tags,
+    unique_key,
+    nullif(unique_states, 0)::bit(8)
+FROM river_job_insert_staging
+ORDER BY ordinal
+ON CONFLICT (unique_key)
+    WHERE unique_key IS NOT NULL
+        AND unique_states IS NOT NULL
+        AND /* TEMPLATE: schema */river_job_state_in_bitmask(unique_states, state)
+    -- Something needs to be updated for a row to be returned on a conflict.
+    DO UPDATE SET kind = EXCLUDED.kind
+RETURNING sqlc.embed(river_job), (xmax != 0) AS unique_skipped_as_duplicate;
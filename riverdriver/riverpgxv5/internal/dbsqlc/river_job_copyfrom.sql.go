@@ -6,6 +6,7 @@
 package dbsqlc
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -25,3 +26,143 @@ type JobInsertFastManyCopyFromParams struct {
 	UniqueKey    []byte
 	UniqueStates pgtype.Bits
 }
+
+const jobInsertFromStaging = `-- name: JobInsertFromStaging :many
+INSERT INTO /* TEMPLATE: schema */river_job(
+    id,
+    args,
+    created_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state,
+    tags,
+    unique_key,
+    unique_states
+) SELECT
+    coalesce(nullif(id, 0), nextval('/* TEMPLATE: schema */river_job_id_seq'::regclass)),
+    args,
+    created_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state::/* TEMPLATE: schema */river_job_state,
+    tags,
+    unique_key,
+    nullif(unique_states, 0)::bit(8)
+FROM river_job_insert_staging
+ORDER BY ordinal
+ON CONFLICT (unique_key)
+    WHERE unique_key IS NOT NULL
+        AND unique_states IS NOT NULL
+        AND /* TEMPLATE: schema */river_job_state_in_bitmask(unique_states, state)
+    -- Something needs to be updated for a row to be returned on a conflict.
+    DO UPDATE SET kind = EXCLUDED.kind
+RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states, (xmax != 0) AS unique_skipped_as_duplicate
+`
+
+type JobInsertFromStagingRow struct {
+	RiverJob                 RiverJob
+	UniqueSkippedAsDuplicate bool
+}
+
+// Moves staged jobs into `river_job` in the order they were staged, with the
+// same handling of unique conflicts as JobInsertFastMany.
+func (q *Queries) JobInsertFromStaging(ctx context.Context, db DBTX) ([]*JobInsertFromStagingRow, error) {
+	rows, err := db.Query(ctx, jobInsertFromStaging)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobInsertFromStagingRow
+	for rows.Next() {
+		var i JobInsertFromStagingRow
+		if err := rows.Scan(
+			&i.RiverJob.ID,
+			&i.RiverJob.Args,
+			&i.RiverJob.Attempt,
+			&i.RiverJob.AttemptedAt,
+			&i.RiverJob.AttemptedBy,
+			&i.RiverJob.CreatedAt,
+			&i.RiverJob.Errors,
+			&i.RiverJob.FinalizedAt,
+			&i.RiverJob.Kind,
+			&i.RiverJob.MaxAttempts,
+			&i.RiverJob.Metadata,
+			&i.RiverJob.Priority,
+			&i.RiverJob.Queue,
+			&i.RiverJob.State,
+			&i.RiverJob.ScheduledAt,
+			&i.RiverJob.Tags,
+			&i.RiverJob.UniqueKey,
+			&i.RiverJob.UniqueStates,
+			&i.UniqueSkippedAsDuplicate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type JobInsertStagingCopyFromParams struct {
+	ID           int64
+	Args         []byte
+	CreatedAt    time.Time
+	Kind         string
+	MaxAttempts  int16
+	Metadata     []byte
+	Priority     int16
+	Queue        string
+	ScheduledAt  time.Time
+	State        string
+	Tags         []string
+	UniqueKey    []byte
+	UniqueStates int32
+}
+
+const jobInsertStagingCreate = `-- name: JobInsertStagingCreate :exec
+CREATE TEMPORARY TABLE river_job_insert_staging (
+    ordinal bigint GENERATED ALWAYS AS IDENTITY,
+    id bigint NOT NULL,
+    args jsonb NOT NULL,
+    created_at timestamptz NOT NULL,
+    kind text NOT NULL,
+    max_attempts smallint NOT NULL,
+    metadata jsonb NOT NULL,
+    priority smallint NOT NULL,
+    queue text NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    state text NOT NULL,
+    tags varchar(255)[] NOT NULL,
+    unique_key bytea,
+    unique_states integer NOT NULL
+) ON COMMIT DROP
+`
+
+// Staging table for JobInsertFastManyCopyFrom, which is copied into
+// before jobs are moved to `river_job` so that an insert can use `COPY FROM`
+// while still handling unique conflicts and returning inserted rows. Dropped
+// at the end of the transaction it's created in.
+func (q *Queries) JobInsertStagingCreate(ctx context.Context, db DBTX) error {
+	_, err := db.Exec(ctx, jobInsertStagingCreate)
+	return err
+}
+
+const jobInsertStagingDrop = `-- name: JobInsertStagingDrop :exec
+DROP TABLE river_job_insert_staging
+`
+
+func (q *Queries) JobInsertStagingDrop(ctx context.Context, db DBTX) error {
+	_, err := db.Exec(ctx, jobInsertStagingDrop)
+	return err
+}
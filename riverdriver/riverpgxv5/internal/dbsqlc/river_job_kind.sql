@@ -0,0 +1,57 @@
+CREATE TABLE river_job_kind (
+    kind text NOT NULL,
+    version text NOT NULL DEFAULT '',
+    args_schema jsonb NOT NULL DEFAULT '{}',
+    client_id text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (kind, version),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+
+CREATE TABLE river_job_kind_pause (
+    kind text PRIMARY KEY,
+    paused_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+-- name: JobKindRegistrationList :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job_kind
+ORDER BY kind ASC, version ASC;
+
+-- name: JobKindRegistrationUpsertMany :exec
+INSERT INTO /* TEMPLATE: schema */river_job_kind (
+    kind,
+    version,
+    args_schema,
+    client_id,
+    created_at,
+    updated_at
+) SELECT
+    unnest(@kind::text[]),
+    unnest(@version::text[]),
+    unnest(@args_schema::jsonb[]),
+    @client_id::text,
+    coalesce(sqlc.narg('now')::timestamptz, now()),
+    coalesce(sqlc.narg('now')::timestamptz, now())
+ON CONFLICT (kind, version) DO UPDATE
+SET
+    args_schema = EXCLUDED.args_schema,
+    client_id = EXCLUDED.client_id,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: JobKindPause :exec
+INSERT INTO /* TEMPLATE: schema */river_job_kind_pause (
+    kind,
+    paused_at
+) VALUES (
+    @kind,
+    coalesce(sqlc.narg('now')::timestamptz, now())
+)
+ON CONFLICT (kind) DO NOTHING;
+
+-- name: JobKindResume :exec
+DELETE FROM /* TEMPLATE: schema */river_job_kind_pause
+WHERE kind = @kind;
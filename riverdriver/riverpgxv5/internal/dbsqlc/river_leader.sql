@@ -62,7 +62,7 @@ WITH currently_held_leaders AS (
 notified_resignations AS (
     SELECT pg_notify(
         concat(coalesce(sqlc.narg('schema')::text, current_schema()), '.', @leadership_topic::text),
-        json_build_object('leader_id', leader_id, 'action', 'resigned')::text
+        json_build_object('leader_id', leader_id, 'action', 'resigned', 'v', 1)::text
     )
     FROM currently_held_leaders
 )
@@ -160,7 +160,7 @@ WITH currently_held_leaders AS (
 notified_resignations AS (
     SELECT pg_notify(
         concat(coalesce($3::text, current_schema()), '.', $4::text),
-        json_build_object('leader_id', leader_id, 'action', 'resigned')::text
+        json_build_object('leader_id', leader_id, 'action', 'resigned', 'v', 1)::text
     )
     FROM currently_held_leaders
 )
@@ -0,0 +1,47 @@
+CREATE TABLE river_maintenance_status (
+    name text PRIMARY KEY,
+    client_id text NOT NULL,
+    consecutive_failures integer NOT NULL DEFAULT 0,
+    error text,
+    finished_at timestamptz NOT NULL,
+    last_succeeded_at timestamptz,
+    num_rows bigint NOT NULL DEFAULT 0,
+    started_at timestamptz NOT NULL,
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+-- name: MaintenanceStatusList :many
+SELECT *
+FROM /* TEMPLATE: schema */river_maintenance_status
+ORDER BY name ASC;
+
+-- name: MaintenanceStatusRecordRun :one
+INSERT INTO /* TEMPLATE: schema */river_maintenance_status (
+    name,
+    client_id,
+    consecutive_failures,
+    error,
+    finished_at,
+    last_succeeded_at,
+    num_rows,
+    started_at
+) VALUES (
+    @name,
+    @client_id,
+    CASE WHEN sqlc.narg('error')::text IS NULL THEN 0 ELSE 1 END,
+    sqlc.narg('error')::text,
+    @finished_at,
+    CASE WHEN sqlc.narg('error')::text IS NULL THEN @finished_at::timestamptz ELSE NULL END,
+    @num_rows,
+    @started_at
+)
+ON CONFLICT (name) DO UPDATE
+SET
+    client_id = EXCLUDED.client_id,
+    consecutive_failures = CASE WHEN EXCLUDED.error IS NULL THEN 0 ELSE river_maintenance_status.consecutive_failures + 1 END,
+    error = EXCLUDED.error,
+    finished_at = EXCLUDED.finished_at,
+    last_succeeded_at = coalesce(EXCLUDED.last_succeeded_at, river_maintenance_status.last_succeeded_at),
+    num_rows = EXCLUDED.num_rows,
+    started_at = EXCLUDED.started_at
+RETURNING *;
@@ -0,0 +1,27 @@
+CREATE UNLOGGED TABLE river_rate_limit (
+    name text PRIMARY KEY,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+-- Takes a token from a rate limit's bucket, first refilling it for the time
+-- elapsed since it was last updated. Tokens go negative when none are
+-- available, representing a debt that callers wait out.
+-- name: RateLimitReserve :one
+INSERT INTO /* TEMPLATE: schema */river_rate_limit (
+    name,
+    tokens,
+    updated_at
+) VALUES (
+    @name,
+    @burst::double precision - 1,
+    coalesce(sqlc.narg('now')::timestamptz, now())
+) ON CONFLICT (name) DO UPDATE
+SET
+    tokens = least(
+        @burst::double precision,
+        river_rate_limit.tokens + @rate::double precision * extract(epoch FROM EXCLUDED.updated_at - river_rate_limit.updated_at)::double precision
+    ) - 1,
+    updated_at = EXCLUDED.updated_at
+RETURNING tokens;
@@ -32,6 +32,9 @@ SELECT index_names.index_name::text AS index_name,
        ) AS exists
 FROM index_names;
 
+-- name: Ping :exec
+SELECT 1;
+
 -- name: SchemaGetExpired :many
 SELECT schema_name::text
 FROM information_schema.schemata
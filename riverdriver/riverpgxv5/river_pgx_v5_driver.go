@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io/fs"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -92,6 +93,19 @@ func (d *Driver) GetMigrationTruncateTables(line string, version int) []string {
 func (d *Driver) PoolIsSet() bool          { return d.dbPool != nil }
 func (d *Driver) PoolSet(dbPool any) error { return riverdriver.ErrNotImplemented }
 
+func (d *Driver) PoolStats() *riverdriver.PoolStats {
+	if d.dbPool == nil {
+		return nil
+	}
+	stat := d.dbPool.Stat()
+	return &riverdriver.PoolStats{
+		NumAcquired:  int(stat.AcquiredConns()),
+		NumMax:       int(stat.MaxConns()),
+		NumWaits:     stat.EmptyAcquireCount(),
+		WaitDuration: stat.EmptyAcquireWaitTime(),
+	}
+}
+
 func (d *Driver) SQLFragmentColumnIn(column string, values any) (string, any, error) {
 	return fmt.Sprintf("%s = any(@%s)", column, column), values, nil
 }
@@ -196,6 +210,18 @@ func (e *Executor) IndexesExist(ctx context.Context, params *riverdriver.Indexes
 	return exists, nil
 }
 
+func (e *Executor) JobActivateMany(ctx context.Context, params *riverdriver.JobActivateManyParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobActivateMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobActivateManyParams{
+		ID:          params.ID,
+		Now:         params.Now,
+		ScheduledAt: params.ScheduledAt,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelParams) (*rivertype.JobRow, error) {
 	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
 	if err != nil {
@@ -207,6 +233,7 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 		CancelAttemptedAt: cancelledAt,
 		ControlTopic:      params.ControlTopic,
 		Now:               params.Now,
+		Reason:            params.Reason,
 		Schema:            pgtype.Text{String: params.Schema, Valid: params.Schema != ""},
 	})
 	if err != nil {
@@ -215,6 +242,22 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobChangeQueueMany(ctx context.Context, params *riverdriver.JobChangeQueueManyParams) ([]*rivertype.JobRow, error) {
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"order_by_clause": {Value: params.OrderByClause},
+		"where_clause":    {Value: params.WhereClause},
+	}, params.NamedArgs)
+
+	jobs, err := dbsqlc.New().JobChangeQueueMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobChangeQueueManyParams{
+		Max:   params.Max,
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobCountByAllStates(ctx context.Context, params *riverdriver.JobCountByAllStatesParams) (map[rivertype.JobState]int, error) {
 	counts, err := dbsqlc.New().JobCountByAllStates(schemaTemplateParam(ctx, params.Schema), e.dbtx)
 	if err != nil {
@@ -238,9 +281,10 @@ func (e *Executor) JobCountByQueueAndState(ctx context.Context, params *riverdri
 	results := make([]*riverdriver.JobCountByQueueAndStateResult, len(rows))
 	for i, row := range rows {
 		results[i] = &riverdriver.JobCountByQueueAndStateResult{
-			CountAvailable: row.CountAvailable,
-			CountRunning:   row.CountRunning,
-			Queue:          row.Queue,
+			CountAvailable:              row.CountAvailable,
+			CountClientsWithRunningJobs: row.CountClientsWithRunningJobs,
+			CountRunning:                row.CountRunning,
+			Queue:                       row.Queue,
 		}
 	}
 	return results, nil
@@ -254,6 +298,63 @@ func (e *Executor) JobCountByState(ctx context.Context, params *riverdriver.JobC
 	return int(numJobs), nil
 }
 
+func (e *Executor) JobCountMatching(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"where_clause": {Value: params.WhereClause},
+	}, params.NamedArgs)
+
+	numJobs, err := dbsqlc.New().JobCountMatching(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.Max)
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return int(numJobs), nil
+}
+
+// Not a sqlc query because sqlc doesn't support EXPLAIN.
+const jobCountMatchingEstimateSQL = `EXPLAIN (FORMAT JSON)
+SELECT 1
+FROM /* TEMPLATE: schema */river_job
+WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */`
+
+func (e *Executor) JobCountMatchingEstimate(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"where_clause": {Value: params.WhereClause},
+	}, params.NamedArgs)
+
+	var planBytes []byte
+	if err := e.dbtx.QueryRow(schemaTemplateParam(ctx, params.Schema), jobCountMatchingEstimateSQL).Scan(&planBytes); err != nil {
+		return 0, interpretError(err)
+	}
+
+	var plans []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(planBytes, &plans); err != nil {
+		return 0, fmt.Errorf("error decoding query plan: %w", err)
+	}
+	if len(plans) < 1 {
+		return 0, errors.New("expected query plan to contain at least one plan")
+	}
+
+	return int(plans[0].Plan.PlanRows), nil
+}
+
+func (e *Executor) JobCountRunningByClient(ctx context.Context, params *riverdriver.JobCountRunningByClientParams) ([]*riverdriver.JobCountRunningByClientResult, error) {
+	rows, err := dbsqlc.New().JobCountRunningByClient(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(rows, func(row *dbsqlc.JobCountRunningByClientRow) *riverdriver.JobCountRunningByClientResult {
+		return &riverdriver.JobCountRunningByClientResult{
+			ClientID:        row.ClientID,
+			CountRunning:    row.CountRunning,
+			LastAttemptedAt: row.LastAttemptedAt.UTC(),
+		}
+	}), nil
+}
+
 func (e *Executor) JobDelete(ctx context.Context, params *riverdriver.JobDeleteParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobDelete(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
 	if err != nil {
@@ -296,9 +397,94 @@ func (e *Executor) JobDeleteMany(ctx context.Context, params *riverdriver.JobDel
 	return sliceutil.MapError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetArgsEncryptionKeyStale(ctx context.Context, params *riverdriver.JobGetArgsEncryptionKeyStaleParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobGetArgsEncryptionKeyStale(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetArgsEncryptionKeyStaleParams{
+		AfterID:      params.AfterID,
+		CurrentKeyID: params.CurrentKeyID,
+		Max:          int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	kindsExcluded := params.KindsExcluded
+	if kindsExcluded == nil {
+		kindsExcluded = []string{}
+	}
+
+	labelsMap := params.Labels
+	if labelsMap == nil {
+		labelsMap = map[string]string{}
+	}
+
+	labels, err := json.Marshal(labelsMap)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling labels: %w", err)
+	}
+
+	maxToLockByKindMap := params.MaxToLockByKind
+	if maxToLockByKindMap == nil {
+		maxToLockByKindMap = map[string]int{}
+	}
+
+	maxToLockByKind, err := json.Marshal(maxToLockByKindMap)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling max to lock by kind: %w", err)
+	}
+
+	if params.FairnessKey != "" {
+		fairnessWeightsMap := params.FairnessWeights
+		if fairnessWeightsMap == nil {
+			fairnessWeightsMap = map[string]int{}
+		}
+
+		fairnessWeights, err := json.Marshal(fairnessWeightsMap)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling fairness weights: %w", err)
+		}
+
+		jobs, err := dbsqlc.New().JobGetAvailableFair(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableFairParams{
+			AttemptedBy:     params.ClientID,
+			FairnessKey:     params.FairnessKey,
+			FairnessWeights: fairnessWeights,
+			KindsExcluded:   kindsExcluded,
+			Labels:          labels,
+			MaxAttemptedBy:  int32(min(params.MaxAttemptedBy, math.MaxInt32)), //nolint:gosec
+			MaxToLock:       int32(min(params.MaxToLock, math.MaxInt32)),      //nolint:gosec
+			MaxToLockByKind: maxToLockByKind,
+			Now:             params.Now,
+			Queue:           params.Queue,
+		})
+		if err != nil {
+			return nil, interpretError(err)
+		}
+		return sliceutil.MapError(jobs, jobRowFromInternal)
+	}
+
+	if len(params.MaxToLockByKind) > 0 {
+		jobs, err := dbsqlc.New().JobGetAvailableLimitedByKind(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableLimitedByKindParams{
+			AttemptedBy:     params.ClientID,
+			KindsExcluded:   kindsExcluded,
+			Labels:          labels,
+			MaxAttemptedBy:  int32(min(params.MaxAttemptedBy, math.MaxInt32)), //nolint:gosec
+			MaxToLock:       int32(min(params.MaxToLock, math.MaxInt32)),      //nolint:gosec
+			MaxToLockByKind: maxToLockByKind,
+			Now:             params.Now,
+			Queue:           params.Queue,
+		})
+		if err != nil {
+			return nil, interpretError(err)
+		}
+		return sliceutil.MapError(jobs, jobRowFromInternal)
+	}
+
 	jobs, err := dbsqlc.New().JobGetAvailable(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableParams{
 		AttemptedBy:    params.ClientID,
+		KindsExcluded:  kindsExcluded,
+		Labels:         labels,
 		MaxAttemptedBy: int32(min(params.MaxAttemptedBy, math.MaxInt32)), //nolint:gosec
 		MaxToLock:      int32(min(params.MaxToLock, math.MaxInt32)),      //nolint:gosec
 		Now:            params.Now,
@@ -310,6 +496,14 @@ func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobG
 	return sliceutil.MapError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetByExternalID(ctx context.Context, params *riverdriver.JobGetByExternalIDParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobGetByExternalID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ExternalID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
 func (e *Executor) JobGetByID(ctx context.Context, params *riverdriver.JobGetByIDParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobGetByID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
 	if err != nil {
@@ -334,9 +528,22 @@ func (e *Executor) JobGetByKindMany(ctx context.Context, params *riverdriver.Job
 	return sliceutil.MapError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetLeaseExpired(ctx context.Context, params *riverdriver.JobGetLeaseExpiredParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobGetLeaseExpired(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetLeaseExpiredParams{
+		Max:   int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:   params.Now,
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
 	jobs, err := dbsqlc.New().JobGetStuck(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetStuckParams{
 		Max:          int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:          params.Now,
 		StuckHorizon: params.StuckHorizon,
 	})
 	if err != nil {
@@ -360,6 +567,7 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params *riverdriver.Jo
 		Tags:         make([]string, len(params.Jobs)),
 		UniqueKey:    make([][]byte, len(params.Jobs)),
 		UniqueStates: make([]int32, len(params.Jobs)),
+		ScheduledIn:  make([]int64, len(params.Jobs)),
 	}
 	now := time.Now().UTC()
 	for i := range len(params.Jobs) {
@@ -370,9 +578,14 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params *riverdriver.Jo
 			createdAt = *params.CreatedAt
 		}
 
+		// A zero scheduled_at with a positive scheduled_in has the database
+		// compute the time relative to its own clock.
 		scheduledAt := now
-		if params.ScheduledAt != nil {
+		switch {
+		case params.ScheduledAt != nil:
 			scheduledAt = *params.ScheduledAt
+		case params.ScheduledIn > 0:
+			scheduledAt = time.Time{}
 		}
 
 		tags := params.Tags
@@ -395,6 +608,7 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params *riverdriver.Jo
 		insertJobsParams.Tags[i] = strings.Join(tags, ",")
 		insertJobsParams.UniqueKey[i] = sliceutil.FirstNonEmpty(params.UniqueKey)
 		insertJobsParams.UniqueStates[i] = int32(params.UniqueStates)
+		insertJobsParams.ScheduledIn[i] = params.ScheduledIn.Microseconds()
 	}
 
 	items, err := dbsqlc.New().JobInsertFastMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, insertJobsParams)
@@ -411,7 +625,96 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params *riverdriver.Jo
 	})
 }
 
+func (e *Executor) JobInsertFastManyCopyFrom(ctx context.Context, params *riverdriver.JobInsertFastManyParams) ([]*riverdriver.JobInsertFastResult, error) {
+	if slices.ContainsFunc(params.Jobs, func(job *riverdriver.JobInsertFastParams) bool { return job.ScheduledIn > 0 }) {
+		return e.JobInsertFastMany(ctx, params)
+	}
+
+	stagingParams := make([]*dbsqlc.JobInsertStagingCopyFromParams, len(params.Jobs))
+	now := time.Now().UTC()
+
+	for i := range len(params.Jobs) {
+		params := params.Jobs[i]
+
+		createdAt := now
+		if params.CreatedAt != nil {
+			createdAt = *params.CreatedAt
+		}
+
+		scheduledAt := now
+		if params.ScheduledAt != nil {
+			scheduledAt = *params.ScheduledAt
+		}
+
+		tags := params.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		defaultObject := []byte("{}")
+
+		stagingParams[i] = &dbsqlc.JobInsertStagingCopyFromParams{
+			ID:           ptrutil.ValOrDefault(params.ID, 0),
+			Args:         sliceutil.FirstNonEmpty(params.EncodedArgs, defaultObject),
+			CreatedAt:    createdAt,
+			Kind:         params.Kind,
+			MaxAttempts:  int16(min(params.MaxAttempts, math.MaxInt16)), //nolint:gosec
+			Metadata:     sliceutil.FirstNonEmpty(params.Metadata, defaultObject),
+			Priority:     int16(min(params.Priority, math.MaxInt16)), //nolint:gosec
+			Queue:        params.Queue,
+			ScheduledAt:  scheduledAt,
+			State:        string(params.State),
+			Tags:         tags,
+			UniqueKey:    sliceutil.FirstNonEmpty(params.UniqueKey),
+			UniqueStates: int32(params.UniqueStates),
+		}
+	}
+
+	// Jobs are copied into a temporary staging table and then moved into
+	// river_job with a single insert so that unique conflicts are handled and
+	// inserted rows returned, neither of which COPY FROM supports directly.
+	// The staging table is dropped on commit, so this must run in a
+	// transaction.
+	return dbutil.WithTxV(ctx, e, func(ctx context.Context, execTx riverdriver.ExecutorTx) ([]*riverdriver.JobInsertFastResult, error) {
+		dbtx := execTx.(*ExecutorTx).dbtx //nolint:forcetypeassert
+
+		if err := dbsqlc.New().JobInsertStagingCreate(ctx, dbtx); err != nil {
+			return nil, interpretError(err)
+		}
+
+		if _, err := dbsqlc.New().JobInsertStagingCopyFrom(ctx, dbtx, stagingParams); err != nil {
+			return nil, interpretError(err)
+		}
+
+		items, err := dbsqlc.New().JobInsertFromStaging(schemaTemplateParam(ctx, params.Schema), dbtx)
+		if err != nil {
+			return nil, interpretError(err)
+		}
+
+		// Dropped explicitly in case the transaction goes on to insert another
+		// batch, which would otherwise find the table already exists.
+		if err := dbsqlc.New().JobInsertStagingDrop(ctx, dbtx); err != nil {
+			return nil, interpretError(err)
+		}
+
+		return sliceutil.MapError(items, func(row *dbsqlc.JobInsertFromStagingRow) (*riverdriver.JobInsertFastResult, error) {
+			job, err := jobRowFromInternal(&row.RiverJob)
+			if err != nil {
+				return nil, err
+			}
+			return &riverdriver.JobInsertFastResult{Job: job, UniqueSkippedAsDuplicate: row.UniqueSkippedAsDuplicate}, nil
+		})
+	})
+}
+
 func (e *Executor) JobInsertFastManyNoReturning(ctx context.Context, params *riverdriver.JobInsertFastManyParams) (int, error) {
+	// Jobs scheduled relative to database time need the scheduled_in column
+	// only supported by JobInsertFastMany.
+	if slices.ContainsFunc(params.Jobs, func(job *riverdriver.JobInsertFastParams) bool { return job.ScheduledIn > 0 }) {
+		results, err := e.JobInsertFastMany(ctx, params)
+		return len(results), err
+	}
+
 	insertJobsParams := make([]*dbsqlc.JobInsertFastManyCopyFromParams, len(params.Jobs))
 	now := time.Now().UTC()
 
@@ -549,6 +852,77 @@ func (e *Executor) JobKindList(ctx context.Context, params *riverdriver.JobKindL
 	return kinds, nil
 }
 
+func (e *Executor) JobKindPause(ctx context.Context, params *riverdriver.JobKindPauseParams) error {
+	if err := dbsqlc.New().JobKindPause(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobKindPauseParams{
+		Kind: params.Kind,
+		Now:  params.Now,
+	}); err != nil {
+		return interpretError(err)
+	}
+	return nil
+}
+
+func (e *Executor) JobKindRegistrationList(ctx context.Context, params *riverdriver.JobKindRegistrationListParams) ([]*riverdriver.JobKindRegistration, error) {
+	registrations, err := dbsqlc.New().JobKindRegistrationList(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(registrations, jobKindRegistrationFromInternal), nil
+}
+
+func (e *Executor) JobKindRegistrationUpsertMany(ctx context.Context, params *riverdriver.JobKindRegistrationUpsertManyParams) error {
+	upsertParams := &dbsqlc.JobKindRegistrationUpsertManyParams{
+		ArgsSchema: make([][]byte, len(params.Registrations)),
+		ClientID:   params.ClientID,
+		Kind:       make([]string, len(params.Registrations)),
+		Now:        params.Now,
+		Version:    make([]string, len(params.Registrations)),
+	}
+
+	for i, registration := range params.Registrations {
+		upsertParams.ArgsSchema[i] = sliceutil.FirstNonEmpty(registration.ArgsSchema, []byte("{}"))
+		upsertParams.Kind[i] = registration.Kind
+		upsertParams.Version[i] = registration.Version
+	}
+
+	if err := dbsqlc.New().JobKindRegistrationUpsertMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, upsertParams); err != nil {
+		return interpretError(err)
+	}
+	return nil
+}
+
+func (e *Executor) JobKindResume(ctx context.Context, params *riverdriver.JobKindResumeParams) error {
+	if err := dbsqlc.New().JobKindResume(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.Kind); err != nil {
+		return interpretError(err)
+	}
+	return nil
+}
+
+func (e *Executor) JobKindStorageUsage(ctx context.Context, params *riverdriver.JobKindStorageUsageParams) ([]*riverdriver.JobKindStorageUsageResult, error) {
+	rows, err := dbsqlc.New().JobKindStorageUsage(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(rows, func(row *dbsqlc.JobKindStorageUsageRow) *riverdriver.JobKindStorageUsageResult {
+		return &riverdriver.JobKindStorageUsageResult{
+			ArgsBytes:     row.ArgsBytes,
+			Count:         row.Count,
+			ErrorsBytes:   row.ErrorsBytes,
+			Kind:          row.Kind,
+			MetadataBytes: row.MetadataBytes,
+		}
+	}), nil
+}
+
+func (e *Executor) JobLeaseRenewMany(ctx context.Context, params *riverdriver.JobLeaseRenewManyParams) error {
+	err := dbsqlc.New().JobLeaseRenewMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobLeaseRenewManyParams{
+		Attempt:        sliceutil.Map(params.Attempt, func(a int) int16 { return int16(min(a, math.MaxInt16)) }), //nolint:gosec
+		ID:             params.ID,
+		LeaseExpiresAt: params.LeaseExpiresAt,
+	})
+	return interpretError(err)
+}
+
 func (e *Executor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
 	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
 		"order_by_clause": {Value: params.OrderByClause},
@@ -669,7 +1043,25 @@ func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobUpdateArgsEncryptionKey(ctx context.Context, params *riverdriver.JobUpdateArgsEncryptionKeyParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobUpdateArgsEncryptionKey(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateArgsEncryptionKeyParams{
+		Args:          params.Args,
+		ID:            params.ID,
+		KeyID:         params.KeyID,
+		PreviousKeyID: params.PreviousKeyID,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
 func (e *Executor) JobUpdateFull(ctx context.Context, params *riverdriver.JobUpdateFullParams) (*rivertype.JobRow, error) {
+	args := params.Args
+	if args == nil {
+		args = []byte("{}")
+	}
+
 	metadata := params.Metadata
 	if metadata == nil {
 		metadata = []byte("{}")
@@ -677,6 +1069,8 @@ func (e *Executor) JobUpdateFull(ctx context.Context, params *riverdriver.JobUpd
 
 	job, err := dbsqlc.New().JobUpdateFull(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateFullParams{
 		ID:                  params.ID,
+		ArgsDoUpdate:        params.ArgsDoUpdate,
+		Args:                args,
 		AttemptedAtDoUpdate: params.AttemptedAtDoUpdate,
 		Attempt:             int16(min(params.Attempt, math.MaxInt16)), //nolint:gosec
 		AttemptDoUpdate:     params.AttemptDoUpdate,
@@ -769,6 +1163,29 @@ func (e *Executor) LeaderResign(ctx context.Context, params *riverdriver.LeaderR
 	return numResigned > 0, nil
 }
 
+func (e *Executor) MaintenanceStatusList(ctx context.Context, params *riverdriver.MaintenanceStatusListParams) ([]*riverdriver.MaintenanceStatus, error) {
+	statuses, err := dbsqlc.New().MaintenanceStatusList(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(statuses, maintenanceStatusFromInternal), nil
+}
+
+func (e *Executor) MaintenanceStatusRecordRun(ctx context.Context, params *riverdriver.MaintenanceStatusRecordRunParams) (*riverdriver.MaintenanceStatus, error) {
+	status, err := dbsqlc.New().MaintenanceStatusRecordRun(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.MaintenanceStatusRecordRunParams{
+		Name:       params.Name,
+		ClientID:   params.ClientID,
+		Error:      pgtype.Text{String: ptrutil.ValOrDefault(params.Error, ""), Valid: params.Error != nil},
+		FinishedAt: params.FinishedAt,
+		NumRows:    int64(params.NumRows),
+		StartedAt:  params.StartedAt,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return maintenanceStatusFromInternal(status), nil
+}
+
 func (e *Executor) MigrationDeleteAssumingMainMany(ctx context.Context, params *riverdriver.MigrationDeleteAssumingMainManyParams) ([]*riverdriver.Migration, error) {
 	migrations, err := dbsqlc.New().RiverMigrationDeleteAssumingMainMany(schemaTemplateParam(ctx, params.Schema), e.dbtx,
 		sliceutil.Map(params.Versions, func(v int) int64 { return int64(v) }))
@@ -862,6 +1279,28 @@ func (e *Executor) PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}
 	return &struct{}{}, interpretError(err)
 }
 
+func (e *Executor) PGTransactionTimeoutsGet(ctx context.Context) (*riverdriver.PGTransactionTimeouts, error) {
+	timeouts, err := dbsqlc.New().PGTransactionTimeoutsGet(ctx, e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.PGTransactionTimeouts{
+		LockTimeout:      timeouts.LockTimeout,
+		StatementTimeout: timeouts.StatementTimeout,
+	}, nil
+}
+
+func (e *Executor) PGTransactionTimeoutsSet(ctx context.Context, timeouts *riverdriver.PGTransactionTimeouts) error {
+	return interpretError(dbsqlc.New().PGTransactionTimeoutsSet(ctx, e.dbtx, &dbsqlc.PGTransactionTimeoutsSetParams{
+		LockTimeout:      timeouts.LockTimeout,
+		StatementTimeout: timeouts.StatementTimeout,
+	}))
+}
+
+func (e *Executor) Ping(ctx context.Context) error {
+	return interpretError(dbsqlc.New().Ping(ctx, e.dbtx))
+}
+
 func (e *Executor) QueueCreateOrSetUpdatedAt(ctx context.Context, params *riverdriver.QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error) {
 	queue, err := dbsqlc.New().QueueCreateOrSetUpdatedAt(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.QueueCreateOrSetUpdatedAtParams{
 		Metadata:  params.Metadata,
@@ -964,6 +1403,19 @@ func (e *Executor) QueryRow(ctx context.Context, sql string, args ...any) riverd
 	return e.dbtx.QueryRow(ctx, sql, args...)
 }
 
+func (e *Executor) RateLimitReserve(ctx context.Context, params *riverdriver.RateLimitReserveParams) (float64, error) {
+	tokens, err := dbsqlc.New().RateLimitReserve(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.RateLimitReserveParams{
+		Burst: params.Burst,
+		Name:  params.Name,
+		Now:   params.Now,
+		Rate:  params.Rate,
+	})
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return tokens, nil
+}
+
 func (e *Executor) SchemaCreate(ctx context.Context, params *riverdriver.SchemaCreateParams) error {
 	_, err := e.dbtx.Exec(ctx, "CREATE SCHEMA "+dbutil.SafeIdentifier(params.Schema))
 	return interpretError(err)
@@ -1214,9 +1666,35 @@ func interpretError(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return rivertype.ErrNotFound
 	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErrIsStatementTimeout(pgErr):
+			return fmt.Errorf("%w: %w", riverdriver.ErrStatementTimeout, err)
+		case pgErr.Code == "40001" || pgErr.Code == "40P01": // serialization_failure, deadlock_detected
+			return fmt.Errorf("%w: %w", riverdriver.ErrTransient, err)
+		}
+	}
+	if err != nil && pgconn.SafeToRetry(err) {
+		return fmt.Errorf("%w: %w", riverdriver.ErrTransient, err)
+	}
 	return err
 }
 
+// Returns true if the error is Postgres cancelling a statement because it
+// exceeded `statement_timeout` or `lock_timeout`. Cancellations for other
+// reasons, like a user request, share the `query_canceled` code with statement
+// timeouts, so those are distinguished by message.
+func pgErrIsStatementTimeout(pgErr *pgconn.PgError) bool {
+	switch pgErr.Code {
+	case "55P03": // lock_not_available
+		return strings.Contains(pgErr.Message, "lock timeout")
+	case "57014": // query_canceled
+		return strings.Contains(pgErr.Message, "statement timeout")
+	}
+	return false
+}
+
 func jobRowFromInternal(internal *dbsqlc.RiverJob) (*rivertype.JobRow, error) {
 	var attemptedAt *time.Time
 	if internal.AttemptedAt != nil {
@@ -1272,6 +1750,28 @@ func leaderFromInternal(internal *dbsqlc.RiverLeader) *riverdriver.Leader {
 	}
 }
 
+func maintenanceStatusFromInternal(internal *dbsqlc.RiverMaintenanceStatus) *riverdriver.MaintenanceStatus {
+	var errStr *string
+	if internal.Error.Valid {
+		errStr = &internal.Error.String
+	}
+	var lastSucceededAt *time.Time
+	if internal.LastSucceededAt != nil {
+		t := internal.LastSucceededAt.UTC()
+		lastSucceededAt = &t
+	}
+	return &riverdriver.MaintenanceStatus{
+		ClientID:            internal.ClientID,
+		ConsecutiveFailures: int(internal.ConsecutiveFailures),
+		Error:               errStr,
+		FinishedAt:          internal.FinishedAt.UTC(),
+		LastSucceededAt:     lastSucceededAt,
+		Name:                internal.Name,
+		NumRows:             int(internal.NumRows),
+		StartedAt:           internal.StartedAt.UTC(),
+	}
+}
+
 func migrationFromInternal(internal *dbsqlc.RiverMigration) *riverdriver.Migration {
 	return &riverdriver.Migration{
 		CreatedAt: internal.CreatedAt.UTC(),
@@ -1280,6 +1780,17 @@ func migrationFromInternal(internal *dbsqlc.RiverMigration) *riverdriver.Migrati
 	}
 }
 
+func jobKindRegistrationFromInternal(internal *dbsqlc.RiverJobKind) *riverdriver.JobKindRegistration {
+	return &riverdriver.JobKindRegistration{
+		ArgsSchema: internal.ArgsSchema,
+		ClientID:   internal.ClientID,
+		CreatedAt:  internal.CreatedAt.UTC(),
+		Kind:       internal.Kind,
+		UpdatedAt:  internal.UpdatedAt.UTC(),
+		Version:    internal.Version,
+	}
+}
+
 func queueFromInternal(internal *dbsqlc.RiverQueue) *rivertype.Queue {
 	var pausedAt *time.Time
 	if internal.PausedAt != nil {
@@ -29,6 +29,20 @@ type RiverJob struct {
 	UniqueStates *int64
 }
 
+type RiverJobKind struct {
+	Kind       string
+	Version    string
+	ArgsSchema []byte
+	ClientID   string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RiverJobKindPause struct {
+	Kind     string
+	PausedAt time.Time
+}
+
 type RiverLeader struct {
 	ElectedAt time.Time
 	ExpiresAt time.Time
@@ -36,6 +50,17 @@ type RiverLeader struct {
 	Name      string
 }
 
+type RiverMaintenanceStatus struct {
+	Name                string
+	ClientID            string
+	ConsecutiveFailures int64
+	Error               *string
+	FinishedAt          time.Time
+	LastSucceededAt     *time.Time
+	NumRows             int64
+	StartedAt           time.Time
+}
+
 type RiverMigration struct {
 	Line      string
 	Version   int64
@@ -57,6 +82,12 @@ type RiverQueue struct {
 	UpdatedAt time.Time
 }
 
+type RiverRateLimit struct {
+	Name      string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
 type SqliteMaster struct {
 	Type     *string
 	Name     *string
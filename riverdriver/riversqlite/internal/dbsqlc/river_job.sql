@@ -35,6 +35,15 @@ CREATE TABLE river_job (
 -- I had to invert the last 'AND' expression below (was an 'ANT NOT) due to an
 -- sqlc bug. Something about sqlc's SQLite parser cannot detect a parameter
 -- inside an `AND NOT`.
+-- name: JobActivateMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    scheduled_at = cast(@scheduled_at AS text),
+    state = CASE WHEN cast(@scheduled_at AS text) <= coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec')) THEN 'available' ELSE 'scheduled' END
+WHERE id IN (sqlc.slice('id'))
+    AND state = 'pending'
+RETURNING *;
+
 -- name: JobCancel :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -44,12 +53,29 @@ SET
     finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec')) END,
     -- Mark the job as cancelled by query so that the rescuer knows not to
     -- rescue it, even if it gets stuck in the running state:
-    metadata = jsonb_set(metadata, '$.cancel_attempted_at', cast(@cancel_attempted_at AS text))
+    metadata = CASE
+        WHEN cast(@reason AS text) = '' THEN jsonb_set(metadata, '$.cancel_attempted_at', cast(@cancel_attempted_at AS text))
+        ELSE jsonb_set(metadata, '$.cancel_attempted_at', cast(@cancel_attempted_at AS text), '$.cancel_reason', cast(@reason AS text))
+    END
 WHERE id = @id
     AND state NOT IN ('cancelled', 'completed', 'discarded')
     AND finalized_at IS NULL
 RETURNING *;
 
+-- name: JobChangeQueueMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET queue = @queue
+WHERE id IN (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+        AND queue != @queue
+        AND state != 'running'
+    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+    LIMIT @max
+)
+RETURNING *;
+
 -- name: JobCountByAllStates :many
 SELECT state, count(*)
 FROM /* TEMPLATE: schema */river_job
@@ -60,7 +86,9 @@ WITH queue_stats AS (
     SELECT
         river_job.queue,
         COUNT(CASE WHEN river_job.state = 'available' THEN 1 END) AS count_available,
-        COUNT(CASE WHEN river_job.state = 'running' THEN 1 END) AS count_running
+        COUNT(CASE WHEN river_job.state = 'running' THEN 1 END) AS count_running,
+        -- The client working a job is the last one to have attempted it.
+        COUNT(DISTINCT CASE WHEN river_job.state = 'running' THEN json_extract(river_job.attempted_by, '$[#-1]') END) AS count_clients_with_running_jobs
     FROM /* TEMPLATE: schema */river_job
     WHERE river_job.queue IN (sqlc.slice('queue_names'))
     GROUP BY river_job.queue
@@ -69,7 +97,8 @@ WITH queue_stats AS (
 SELECT
     cast(queue AS text) AS queue,
     count_available,
-    count_running
+    count_running,
+    count_clients_with_running_jobs
 FROM queue_stats
 ORDER BY queue ASC;
 
@@ -78,6 +107,27 @@ SELECT count(*)
 FROM /* TEMPLATE: schema */river_job
 WHERE state = @state;
 
+-- name: JobCountMatching :one
+SELECT count(*)
+FROM (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+    LIMIT @max
+) AS matching_job;
+
+-- name: JobCountRunningByClient :many
+SELECT
+    -- The client working a job is the last one to have attempted it.
+    cast(json_extract(attempted_by, '$[#-1]') AS text) AS client_id,
+    count(*) AS count_running,
+    cast(max(unixepoch(attempted_at, 'subsec')) AS real) AS last_attempted_at
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND attempted_by IS NOT NULL
+GROUP BY client_id
+ORDER BY client_id ASC;
+
 -- Differs by necessity from other drivers because SQLite doesn't support
 -- `DELETE` inside CTEs so we can't delete if running but select otherwise.
 -- Instead, the driver uses a transaction to optimisticaly try a delete, but
@@ -136,6 +186,14 @@ RETURNING *;
 -- Differs from the Postgres version in that we don't have `FOR UPDATE SKIP
 -- LOCKED`. It doesn't exist in SQLite, but more aptly, there's only one writer
 -- on SQLite at a time, so nothing else has the rows locked.
+-- name: JobGetArgsEncryptionKeyStale :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE json_extract(metadata, '$."river:args_encryption_key_id"') <> cast(@current_key_id AS text)
+    AND id > @after_id
+ORDER BY id
+LIMIT @max;
+
 -- name: JobGetAvailable :many
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -155,6 +213,27 @@ WHERE id IN (
         AND river_job.queue = @queue
         AND scheduled_at <= coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec'))
         AND state = 'available'
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind NOT IN (SELECT value FROM json_each(cast(@kinds_excluded AS blob)))
+        AND NOT EXISTS (
+            SELECT 1
+            FROM json_each(metadata, '$."river:required_labels"') AS required_label
+            WHERE required_label.value IS NOT json_extract(cast(@labels AS blob), '$.' || json_quote(required_label.key))
+        )
+        AND (
+            json_extract(metadata, '$."river:partition_key"') IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
     ORDER BY
         priority ASC,
         scheduled_at ASC,
@@ -163,6 +242,138 @@ WHERE id IN (
 )
 RETURNING *;
 
+-- name: JobGetAvailableFair :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec')),
+
+    -- This is replaced in the driver to work around sqlc bugs for SQLite. See
+    -- comments there for more details.
+    attempted_by = /* TEMPLATE_BEGIN: attempted_by_clause */ attempted_by /* TEMPLATE_END */,
+
+    state = 'running'
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT
+            id,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY json_extract(metadata, '$.' || json_quote(cast(@fairness_key AS text))), priority
+                ORDER BY scheduled_at ASC, id ASC
+            ) AS fairness_rank,
+            coalesce(json_extract(cast(@fairness_weights AS blob), '$.' || json_quote(cast(json_extract(metadata, '$.' || json_quote(cast(@fairness_key AS text))) AS text))), 1) AS fairness_weight,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank,
+            coalesce(json_extract(cast(@max_to_lock_by_kind AS blob), '$.' || json_quote(kind)), @max_to_lock) AS kind_max_to_lock
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            priority >= 0
+            AND river_job.queue = @queue
+            AND scheduled_at <= coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec'))
+            AND state = 'available'
+            AND NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job_kind_pause
+                WHERE river_job_kind_pause.kind = river_job.kind
+            )
+            AND kind NOT IN (SELECT value FROM json_each(cast(@kinds_excluded AS blob)))
+            AND NOT EXISTS (
+                SELECT 1
+                FROM json_each(metadata, '$."river:required_labels"') AS required_label
+                WHERE required_label.value IS NOT json_extract(cast(@labels AS blob), '$.' || json_quote(required_label.key))
+            )
+            AND (
+                json_extract(metadata, '$."river:partition_key"') IS NULL
+                OR NOT EXISTS (
+                    SELECT 1
+                    FROM /* TEMPLATE: schema */river_job AS partition_job
+                    WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                        AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                        AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+                )
+            )
+    ) AS candidate_jobs
+    WHERE kind_rank <= kind_max_to_lock
+    ORDER BY
+        priority ASC,
+        cast(fairness_rank AS real) / fairness_weight ASC,
+        scheduled_at ASC,
+        id ASC
+    LIMIT @max_to_lock
+)
+RETURNING *;
+
+-- name: JobGetAvailableLimitedByKind :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec')),
+
+    -- This is replaced in the driver to work around sqlc bugs for SQLite. See
+    -- comments there for more details.
+    attempted_by = /* TEMPLATE_BEGIN: attempted_by_clause */ attempted_by /* TEMPLATE_END */,
+
+    state = 'running'
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT
+            id,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank,
+            coalesce(json_extract(cast(@max_to_lock_by_kind AS blob), '$.' || json_quote(kind)), @max_to_lock) AS kind_max_to_lock
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            priority >= 0
+            AND river_job.queue = @queue
+            AND scheduled_at <= coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec'))
+            AND state = 'available'
+            AND NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job_kind_pause
+                WHERE river_job_kind_pause.kind = river_job.kind
+            )
+            AND kind NOT IN (SELECT value FROM json_each(cast(@kinds_excluded AS blob)))
+            AND NOT EXISTS (
+                SELECT 1
+                FROM json_each(metadata, '$."river:required_labels"') AS required_label
+                WHERE required_label.value IS NOT json_extract(cast(@labels AS blob), '$.' || json_quote(required_label.key))
+            )
+            AND (
+                json_extract(metadata, '$."river:partition_key"') IS NULL
+                OR NOT EXISTS (
+                    SELECT 1
+                    FROM /* TEMPLATE: schema */river_job AS partition_job
+                    WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                        AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                        AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+                )
+            )
+    ) AS candidate_jobs
+    WHERE kind_rank <= kind_max_to_lock
+    ORDER BY
+        priority ASC,
+        scheduled_at ASC,
+        id ASC
+    LIMIT @max_to_lock
+)
+RETURNING *;
+
+-- name: JobGetByExternalID :one
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE json_extract(metadata, '$."river:external_id"') = cast(@external_id AS text)
+LIMIT 1;
+
 -- name: JobGetByID :one
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -181,11 +392,30 @@ FROM /* TEMPLATE: schema */river_job
 WHERE kind IN (sqlc.slice('kind'))
 ORDER BY id;
 
+-- name: JobGetLeaseExpired :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND queue = @queue
+    -- A lease is tied to the attempt that took it, so one left over by a
+    -- previous attempt doesn't count as expired for the current one.
+    AND json_extract(metadata, '$."river:lease_attempt"') = attempt
+    AND json_extract(metadata, '$."river:lease_expires_at"') < cast(@now AS text)
+ORDER BY id
+LIMIT @max;
+
 -- name: JobGetStuck :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
     AND attempted_at < cast(@stuck_horizon AS text)
+    -- A job whose current attempt holds an unexpired lease is still being
+    -- renewed by the client working it, so it's left until the lease lapses.
+    AND NOT coalesce(
+        json_extract(metadata, '$."river:lease_attempt"') = attempt
+            AND json_extract(metadata, '$."river:lease_expires_at"') >= cast(@now AS text),
+        false
+    )
 ORDER BY id
 LIMIT @max;
 
@@ -262,7 +492,7 @@ SELECT
     jsonb(json_extract(value, '$.metadata')),
     cast(json_extract(value, '$.priority') AS integer),
     cast(json_extract(value, '$.queue') AS text),
-    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec')),
+    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec', printf('%+.6f seconds', coalesce(json_extract(value, '$.scheduled_in_seconds'), 0)))),
     cast(json_extract(value, '$.state') AS text),
     jsonb(json_extract(value, '$.tags')),
     CASE WHEN length(cast(json_extract(value, '$.unique_key') AS text)) = 0 THEN NULL ELSE unhex(cast(json_extract(value, '$.unique_key') AS text)) END,
@@ -354,7 +584,7 @@ SELECT
     jsonb(json_extract(value, '$.metadata')),
     cast(json_extract(value, '$.priority') AS integer),
     cast(json_extract(value, '$.queue') AS text),
-    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec')),
+    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec', printf('%+.6f seconds', coalesce(json_extract(value, '$.scheduled_in_seconds'), 0)))),
     cast(json_extract(value, '$.state') AS text),
     jsonb(json_extract(value, '$.tags')),
     CASE WHEN length(cast(json_extract(value, '$.unique_key') AS text)) = 0 THEN NULL ELSE unhex(cast(json_extract(value, '$.unique_key') AS text)) END,
@@ -466,6 +696,26 @@ WHERE (cast(@match AS text) = '' OR LOWER(kind) LIKE '%' || LOWER(cast(@match AS
 ORDER BY kind ASC
 LIMIT @max;
 
+-- name: JobKindStorageUsage :many
+SELECT
+    kind,
+    count(*) AS count,
+    cast(coalesce(sum(length(args)), 0) AS integer) AS args_bytes,
+    cast(coalesce(sum(length(errors)), 0) AS integer) AS errors_bytes,
+    cast(coalesce(sum(length(metadata)), 0) AS integer) AS metadata_bytes
+FROM /* TEMPLATE: schema */river_job
+GROUP BY kind
+ORDER BY kind ASC;
+
+-- Renew a job's lease. Like JobRescue, this would ideally operate on many jobs
+-- at once, but is run in a loop by the driver instead.
+-- name: JobLeaseRenew :exec
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = jsonb_set(metadata, '$."river:lease_attempt"', attempt, '$."river:lease_expires_at"', cast(@lease_expires_at AS text))
+WHERE id = @id
+    AND attempt = @attempt
+    AND state = 'running';
+
 -- name: JobList :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -627,11 +877,21 @@ SET
 WHERE id = @id
 RETURNING *;
 
+-- name: JobUpdateArgsEncryptionKey :one
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    args = jsonb(@args),
+    metadata = jsonb_set(metadata, '$."river:args_encryption_key_id"', cast(@key_id AS text))
+WHERE id = @id
+    AND json_extract(metadata, '$."river:args_encryption_key_id"') = cast(@previous_key_id AS text)
+RETURNING *;
+
 -- A generalized update for any property on a job. This brings in a large number
 -- of parameters and therefore may be more suitable for testing than production.
 -- name: JobUpdateFull :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
+    args = CASE WHEN cast(@args_do_update AS boolean) THEN jsonb(@args) ELSE args END,
     attempt = CASE WHEN cast(@attempt_do_update AS boolean) THEN @attempt ELSE attempt END,
     attempted_at = CASE WHEN cast(@attempted_at_do_update AS boolean) THEN @attempted_at ELSE attempted_at END,
     attempted_by = CASE WHEN cast(@attempted_by_do_update AS boolean) THEN jsonb(@attempted_by) ELSE attempted_by END,
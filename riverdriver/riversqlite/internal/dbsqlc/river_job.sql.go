@@ -12,6 +12,76 @@ import (
 	"time"
 )
 
+const jobActivateMany = `-- name: JobActivateMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    scheduled_at = cast(?1 AS text),
+    state = CASE WHEN cast(?1 AS text) <= coalesce(cast(?2 AS text), datetime('now', 'subsec')) THEN 'available' ELSE 'scheduled' END
+WHERE id IN (/*SLICE:id*/?)
+    AND state = 'pending'
+RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+`
+
+type JobActivateManyParams struct {
+	ScheduledAt string
+	Now         *string
+	ID          []int64
+}
+
+func (q *Queries) JobActivateMany(ctx context.Context, db DBTX, arg *JobActivateManyParams) ([]*RiverJob, error) {
+	query := jobActivateMany
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.ScheduledAt)
+	queryParams = append(queryParams, arg.Now)
+	if len(arg.ID) > 0 {
+		for _, v := range arg.ID {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:id*/?", strings.Repeat(",?", len(arg.ID))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:id*/?", "NULL", 1)
+	}
+	rows, err := db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobCancel = `-- name: JobCancel :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -21,8 +91,11 @@ SET
     finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE coalesce(cast(?1 AS text), datetime('now', 'subsec')) END,
     -- Mark the job as cancelled by query so that the rescuer knows not to
     -- rescue it, even if it gets stuck in the running state:
-    metadata = jsonb_set(metadata, '$.cancel_attempted_at', cast(?2 AS text))
-WHERE id = ?3
+    metadata = CASE
+        WHEN cast(?2 AS text) = '' THEN jsonb_set(metadata, '$.cancel_attempted_at', cast(?3 AS text))
+        ELSE jsonb_set(metadata, '$.cancel_attempted_at', cast(?3 AS text), '$.cancel_reason', cast(?2 AS text))
+    END
+WHERE id = ?4
     AND state NOT IN ('cancelled', 'completed', 'discarded')
     AND finalized_at IS NULL
 RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
@@ -30,6 +103,7 @@ RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at,
 
 type JobCancelParams struct {
 	Now               *string
+	Reason            string
 	CancelAttemptedAt string
 	ID                int64
 }
@@ -43,7 +117,12 @@ type JobCancelParams struct {
 // sqlc bug. Something about sqlc's SQLite parser cannot detect a parameter
 // inside an `AND NOT`.
 func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams) (*RiverJob, error) {
-	row := db.QueryRowContext(ctx, jobCancel, arg.Now, arg.CancelAttemptedAt, arg.ID)
+	row := db.QueryRowContext(ctx, jobCancel,
+		arg.Now,
+		arg.Reason,
+		arg.CancelAttemptedAt,
+		arg.ID,
+	)
 	var i RiverJob
 	err := row.Scan(
 		&i.ID,
@@ -68,6 +147,68 @@ func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams)
 	return &i, err
 }
 
+const jobChangeQueueMany = `-- name: JobChangeQueueMany :many
+UPDATE /* TEMPLATE: schema */river_job
+SET queue = ?1
+WHERE id IN (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+        AND queue != ?1
+        AND state != 'running'
+    ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
+    LIMIT ?2
+)
+RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+`
+
+type JobChangeQueueManyParams struct {
+	Queue string
+	Max   int64
+}
+
+func (q *Queries) JobChangeQueueMany(ctx context.Context, db DBTX, arg *JobChangeQueueManyParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobChangeQueueMany, arg.Queue, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobCountByAllStates = `-- name: JobCountByAllStates :many
 SELECT state, count(*)
 FROM /* TEMPLATE: schema */river_job
@@ -107,7 +248,9 @@ WITH queue_stats AS (
     SELECT
         river_job.queue,
         COUNT(CASE WHEN river_job.state = 'available' THEN 1 END) AS count_available,
-        COUNT(CASE WHEN river_job.state = 'running' THEN 1 END) AS count_running
+        COUNT(CASE WHEN river_job.state = 'running' THEN 1 END) AS count_running,
+        -- The client working a job is the last one to have attempted it.
+        COUNT(DISTINCT CASE WHEN river_job.state = 'running' THEN json_extract(river_job.attempted_by, '$[#-1]') END) AS count_clients_with_running_jobs
     FROM /* TEMPLATE: schema */river_job
     WHERE river_job.queue IN (/*SLICE:queue_names*/?)
     GROUP BY river_job.queue
@@ -116,15 +259,17 @@ WITH queue_stats AS (
 SELECT
     cast(queue AS text) AS queue,
     count_available,
-    count_running
+    count_running,
+    count_clients_with_running_jobs
 FROM queue_stats
 ORDER BY queue ASC
 `
 
 type JobCountByQueueAndStateRow struct {
-	Queue          string
-	CountAvailable int64
-	CountRunning   int64
+	Queue                       string
+	CountAvailable              int64
+	CountRunning                int64
+	CountClientsWithRunningJobs int64
 }
 
 func (q *Queries) JobCountByQueueAndState(ctx context.Context, db DBTX, queueNames []string) ([]*JobCountByQueueAndStateRow, error) {
@@ -146,7 +291,12 @@ func (q *Queries) JobCountByQueueAndState(ctx context.Context, db DBTX, queueNam
 	var items []*JobCountByQueueAndStateRow
 	for rows.Next() {
 		var i JobCountByQueueAndStateRow
-		if err := rows.Scan(&i.Queue, &i.CountAvailable, &i.CountRunning); err != nil {
+		if err := rows.Scan(
+			&i.Queue,
+			&i.CountAvailable,
+			&i.CountRunning,
+			&i.CountClientsWithRunningJobs,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -173,6 +323,65 @@ func (q *Queries) JobCountByState(ctx context.Context, db DBTX, state string) (i
 	return count, err
 }
 
+const jobCountMatching = `-- name: JobCountMatching :one
+SELECT count(*)
+FROM (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+    LIMIT ?1
+) AS matching_job
+`
+
+func (q *Queries) JobCountMatching(ctx context.Context, db DBTX, max int64) (int64, error) {
+	row := db.QueryRowContext(ctx, jobCountMatching, max)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const jobCountRunningByClient = `-- name: JobCountRunningByClient :many
+SELECT
+    -- The client working a job is the last one to have attempted it.
+    cast(json_extract(attempted_by, '$[#-1]') AS text) AS client_id,
+    count(*) AS count_running,
+    cast(max(unixepoch(attempted_at, 'subsec')) AS real) AS last_attempted_at
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND attempted_by IS NOT NULL
+GROUP BY client_id
+ORDER BY client_id ASC
+`
+
+type JobCountRunningByClientRow struct {
+	ClientID        string
+	CountRunning    int64
+	LastAttemptedAt float64
+}
+
+func (q *Queries) JobCountRunningByClient(ctx context.Context, db DBTX) ([]*JobCountRunningByClientRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountRunningByClient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountRunningByClientRow
+	for rows.Next() {
+		var i JobCountRunningByClientRow
+		if err := rows.Scan(&i.ClientID, &i.CountRunning, &i.LastAttemptedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobDelete = `-- name: JobDelete :one
 DELETE
 FROM /* TEMPLATE: schema */river_job
@@ -327,6 +536,63 @@ func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, max int64) ([]*Riv
 	return items, nil
 }
 
+const jobGetArgsEncryptionKeyStale = `-- name: JobGetArgsEncryptionKeyStale :many
+SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE json_extract(metadata, '$."river:args_encryption_key_id"') <> cast(?1 AS text)
+    AND id > ?2
+ORDER BY id
+LIMIT ?3
+`
+
+type JobGetArgsEncryptionKeyStaleParams struct {
+	CurrentKeyID string
+	AfterID      int64
+	Max          int64
+}
+
+func (q *Queries) JobGetArgsEncryptionKeyStale(ctx context.Context, db DBTX, arg *JobGetArgsEncryptionKeyStaleParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetArgsEncryptionKeyStale, arg.CurrentKeyID, arg.AfterID, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetAvailable = `-- name: JobGetAvailable :many
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -346,6 +612,27 @@ WHERE id IN (
         AND river_job.queue = ?2
         AND scheduled_at <= coalesce(cast(?1 AS text), datetime('now', 'subsec'))
         AND state = 'available'
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_kind_pause
+            WHERE river_job_kind_pause.kind = river_job.kind
+        )
+        AND kind NOT IN (SELECT value FROM json_each(cast(?4 AS blob)))
+        AND NOT EXISTS (
+            SELECT 1
+            FROM json_each(metadata, '$."river:required_labels"') AS required_label
+            WHERE required_label.value IS NOT json_extract(cast(?5 AS blob), '$.' || json_quote(required_label.key))
+        )
+        AND (
+            json_extract(metadata, '$."river:partition_key"') IS NULL
+            OR NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job AS partition_job
+                WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                    AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                    AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+            )
+        )
     ORDER BY
         priority ASC,
         scheduled_at ASC,
@@ -356,16 +643,18 @@ RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at,
 `
 
 type JobGetAvailableParams struct {
-	Now       *string
-	Queue     string
-	MaxToLock int64
+	Now           *string
+	Queue         string
+	MaxToLock     int64
+	KindsExcluded []byte
+	Labels        []byte
 }
 
 // Differs from the Postgres version in that we don't have `FOR UPDATE SKIP
 // LOCKED`. It doesn't exist in SQLite, but more aptly, there's only one writer
 // on SQLite at a time, so nothing else has the rows locked.
 func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvailableParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetAvailable, arg.Now, arg.Queue, arg.MaxToLock)
+	rows, err := db.QueryContext(ctx, jobGetAvailable, arg.Now, arg.Queue, arg.MaxToLock, arg.KindsExcluded, arg.Labels)
 	if err != nil {
 		return nil, err
 	}
@@ -406,6 +695,271 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 	return items, nil
 }
 
+const jobGetAvailableFair = `-- name: JobGetAvailableFair :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(cast(?1 AS text), datetime('now', 'subsec')),
+
+    -- This is replaced in the driver to work around sqlc bugs for SQLite. See
+    -- comments there for more details.
+    attempted_by = /* TEMPLATE_BEGIN: attempted_by_clause */ attempted_by /* TEMPLATE_END */,
+
+    state = 'running'
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT
+            id,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY json_extract(metadata, '$.' || json_quote(cast(?5 AS text))), priority
+                ORDER BY scheduled_at ASC, id ASC
+            ) AS fairness_rank,
+            coalesce(json_extract(cast(?6 AS blob), '$.' || json_quote(cast(json_extract(metadata, '$.' || json_quote(cast(?5 AS text))) AS text))), 1) AS fairness_weight,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank,
+            coalesce(json_extract(cast(?8 AS blob), '$.' || json_quote(kind)), ?3) AS kind_max_to_lock
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            priority >= 0
+            AND river_job.queue = ?2
+            AND scheduled_at <= coalesce(cast(?1 AS text), datetime('now', 'subsec'))
+            AND state = 'available'
+            AND NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job_kind_pause
+                WHERE river_job_kind_pause.kind = river_job.kind
+            )
+            AND kind NOT IN (SELECT value FROM json_each(cast(?4 AS blob)))
+            AND NOT EXISTS (
+                SELECT 1
+                FROM json_each(metadata, '$."river:required_labels"') AS required_label
+                WHERE required_label.value IS NOT json_extract(cast(?7 AS blob), '$.' || json_quote(required_label.key))
+            )
+            AND (
+                json_extract(metadata, '$."river:partition_key"') IS NULL
+                OR NOT EXISTS (
+                    SELECT 1
+                    FROM /* TEMPLATE: schema */river_job AS partition_job
+                    WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                        AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                        AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+                )
+            )
+    ) AS candidate_jobs
+    WHERE kind_rank <= kind_max_to_lock
+    ORDER BY
+        priority ASC,
+        cast(fairness_rank AS real) / fairness_weight ASC,
+        scheduled_at ASC,
+        id ASC
+    LIMIT ?3
+)
+RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+`
+
+type JobGetAvailableFairParams struct {
+	Now             *string
+	Queue           string
+	MaxToLock       int64
+	KindsExcluded   []byte
+	FairnessKey     string
+	FairnessWeights []byte
+	Labels          []byte
+	MaxToLockByKind []byte
+}
+
+func (q *Queries) JobGetAvailableFair(ctx context.Context, db DBTX, arg *JobGetAvailableFairParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableFair, arg.Now, arg.Queue, arg.MaxToLock, arg.KindsExcluded, arg.FairnessKey, arg.FairnessWeights, arg.Labels, arg.MaxToLockByKind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailableLimitedByKind = `-- name: JobGetAvailableLimitedByKind :many
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    attempt = river_job.attempt + 1,
+    attempted_at = coalesce(cast(?1 AS text), datetime('now', 'subsec')),
+
+    -- This is replaced in the driver to work around sqlc bugs for SQLite. See
+    -- comments there for more details.
+    attempted_by = /* TEMPLATE_BEGIN: attempted_by_clause */ attempted_by /* TEMPLATE_END */,
+
+    state = 'running'
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT
+            id,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank,
+            coalesce(json_extract(cast(?6 AS blob), '$.' || json_quote(kind)), ?3) AS kind_max_to_lock
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            priority >= 0
+            AND river_job.queue = ?2
+            AND scheduled_at <= coalesce(cast(?1 AS text), datetime('now', 'subsec'))
+            AND state = 'available'
+            AND NOT EXISTS (
+                SELECT 1
+                FROM /* TEMPLATE: schema */river_job_kind_pause
+                WHERE river_job_kind_pause.kind = river_job.kind
+            )
+            AND kind NOT IN (SELECT value FROM json_each(cast(?4 AS blob)))
+            AND NOT EXISTS (
+                SELECT 1
+                FROM json_each(metadata, '$."river:required_labels"') AS required_label
+                WHERE required_label.value IS NOT json_extract(cast(?5 AS blob), '$.' || json_quote(required_label.key))
+            )
+            AND (
+                json_extract(metadata, '$."river:partition_key"') IS NULL
+                OR NOT EXISTS (
+                    SELECT 1
+                    FROM /* TEMPLATE: schema */river_job AS partition_job
+                    WHERE json_extract(partition_job.metadata, '$."river:partition_key"') = json_extract(river_job.metadata, '$."river:partition_key"')
+                        AND partition_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+                        AND (partition_job.state = 'running' OR partition_job.id < river_job.id)
+                )
+            )
+    ) AS candidate_jobs
+    WHERE kind_rank <= kind_max_to_lock
+    ORDER BY
+        priority ASC,
+        scheduled_at ASC,
+        id ASC
+    LIMIT ?3
+)
+RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+`
+
+type JobGetAvailableLimitedByKindParams struct {
+	Now             *string
+	Queue           string
+	MaxToLock       int64
+	KindsExcluded   []byte
+	Labels          []byte
+	MaxToLockByKind []byte
+}
+
+func (q *Queries) JobGetAvailableLimitedByKind(ctx context.Context, db DBTX, arg *JobGetAvailableLimitedByKindParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableLimitedByKind, arg.Now, arg.Queue, arg.MaxToLock, arg.KindsExcluded, arg.Labels, arg.MaxToLockByKind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetByExternalID = `-- name: JobGetByExternalID :one
+SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE json_extract(metadata, '$."river:external_id"') = cast(?1 AS text)
+LIMIT 1
+`
+
+func (q *Queries) JobGetByExternalID(ctx context.Context, db DBTX, externalID string) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobGetByExternalID, externalID)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		&i.AttemptedBy,
+		&i.CreatedAt,
+		&i.Errors,
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		&i.Tags,
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
 const jobGetByID = `-- name: JobGetByID :one
 SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -557,22 +1111,91 @@ func (q *Queries) JobGetByKindMany(ctx context.Context, db DBTX, kind []string)
 	return items, nil
 }
 
+const jobGetLeaseExpired = `-- name: JobGetLeaseExpired :many
+SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'
+    AND queue = ?1
+    -- A lease is tied to the attempt that took it, so one left over by a
+    -- previous attempt doesn't count as expired for the current one.
+    AND json_extract(metadata, '$."river:lease_attempt"') = attempt
+    AND json_extract(metadata, '$."river:lease_expires_at"') < cast(?2 AS text)
+ORDER BY id
+LIMIT ?3
+`
+
+type JobGetLeaseExpiredParams struct {
+	Queue string
+	Now   string
+	Max   int64
+}
+
+func (q *Queries) JobGetLeaseExpired(ctx context.Context, db DBTX, arg *JobGetLeaseExpiredParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetLeaseExpired, arg.Queue, arg.Now, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			&i.AttemptedBy,
+			&i.CreatedAt,
+			&i.Errors,
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			&i.Tags,
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetStuck = `-- name: JobGetStuck :many
 SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
     AND attempted_at < cast(?1 AS text)
+    -- A job whose current attempt holds an unexpired lease is still being
+    -- renewed by the client working it, so it's left until the lease lapses.
+    AND NOT coalesce(
+        json_extract(metadata, '$."river:lease_attempt"') = attempt
+            AND json_extract(metadata, '$."river:lease_expires_at"') >= cast(?2 AS text),
+        false
+    )
 ORDER BY id
-LIMIT ?2
+LIMIT ?3
 `
 
 type JobGetStuckParams struct {
 	StuckHorizon string
+	Now          string
 	Max          int64
 }
 
 func (q *Queries) JobGetStuck(ctx context.Context, db DBTX, arg *JobGetStuckParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetStuck, arg.StuckHorizon, arg.Max)
+	rows, err := db.QueryContext(ctx, jobGetStuck, arg.StuckHorizon, arg.Now, arg.Max)
 	if err != nil {
 		return nil, err
 	}
@@ -743,7 +1366,7 @@ SELECT
     jsonb(json_extract(value, '$.metadata')),
     cast(json_extract(value, '$.priority') AS integer),
     cast(json_extract(value, '$.queue') AS text),
-    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec')),
+    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec', printf('%+.6f seconds', coalesce(json_extract(value, '$.scheduled_in_seconds'), 0)))),
     cast(json_extract(value, '$.state') AS text),
     jsonb(json_extract(value, '$.tags')),
     CASE WHEN length(cast(json_extract(value, '$.unique_key') AS text)) = 0 THEN NULL ELSE unhex(cast(json_extract(value, '$.unique_key') AS text)) END,
@@ -834,7 +1457,7 @@ SELECT
     jsonb(json_extract(value, '$.metadata')),
     cast(json_extract(value, '$.priority') AS integer),
     cast(json_extract(value, '$.queue') AS text),
-    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec')),
+    coalesce(cast(json_extract(value, '$.scheduled_at') AS text), datetime('now', 'subsec', printf('%+.6f seconds', coalesce(json_extract(value, '$.scheduled_in_seconds'), 0)))),
     cast(json_extract(value, '$.state') AS text),
     jsonb(json_extract(value, '$.tags')),
     CASE WHEN length(cast(json_extract(value, '$.unique_key') AS text)) = 0 THEN NULL ELSE unhex(cast(json_extract(value, '$.unique_key') AS text)) END,
@@ -1188,6 +1811,76 @@ func (q *Queries) JobKindList(ctx context.Context, db DBTX, arg *JobKindListPara
 	return items, nil
 }
 
+const jobKindStorageUsage = `-- name: JobKindStorageUsage :many
+SELECT
+    kind,
+    count(*) AS count,
+    cast(coalesce(sum(length(args)), 0) AS integer) AS args_bytes,
+    cast(coalesce(sum(length(errors)), 0) AS integer) AS errors_bytes,
+    cast(coalesce(sum(length(metadata)), 0) AS integer) AS metadata_bytes
+FROM /* TEMPLATE: schema */river_job
+GROUP BY kind
+ORDER BY kind ASC
+`
+
+type JobKindStorageUsageRow struct {
+	Kind          string
+	Count         int64
+	ArgsBytes     int64
+	ErrorsBytes   int64
+	MetadataBytes int64
+}
+
+func (q *Queries) JobKindStorageUsage(ctx context.Context, db DBTX) ([]*JobKindStorageUsageRow, error) {
+	rows, err := db.QueryContext(ctx, jobKindStorageUsage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobKindStorageUsageRow
+	for rows.Next() {
+		var i JobKindStorageUsageRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Count,
+			&i.ArgsBytes,
+			&i.ErrorsBytes,
+			&i.MetadataBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobLeaseRenew = `-- name: JobLeaseRenew :exec
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = jsonb_set(metadata, '$."river:lease_attempt"', attempt, '$."river:lease_expires_at"', cast(?1 AS text))
+WHERE id = ?2
+    AND attempt = ?3
+    AND state = 'running'
+`
+
+type JobLeaseRenewParams struct {
+	LeaseExpiresAt string
+	ID             int64
+	Attempt        int64
+}
+
+// Renew a job's lease. Like JobRescue, this would ideally operate on many jobs
+// at once, but is run in a loop by the driver instead.
+func (q *Queries) JobLeaseRenew(ctx context.Context, db DBTX, arg *JobLeaseRenewParams) error {
+	_, err := db.ExecContext(ctx, jobLeaseRenew, arg.LeaseExpiresAt, arg.ID, arg.Attempt)
+	return err
+}
+
 const jobList = `-- name: JobList :many
 SELECT id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -1758,22 +2451,73 @@ func (q *Queries) JobUpdate(ctx context.Context, db DBTX, arg *JobUpdateParams)
 	return &i, err
 }
 
+const jobUpdateArgsEncryptionKey = `-- name: JobUpdateArgsEncryptionKey :one
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    args = jsonb(?1),
+    metadata = jsonb_set(metadata, '$."river:args_encryption_key_id"', cast(?2 AS text))
+WHERE id = ?3
+    AND json_extract(metadata, '$."river:args_encryption_key_id"') = cast(?4 AS text)
+RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
+`
+
+type JobUpdateArgsEncryptionKeyParams struct {
+	Args          interface{}
+	KeyID         string
+	ID            int64
+	PreviousKeyID string
+}
+
+func (q *Queries) JobUpdateArgsEncryptionKey(ctx context.Context, db DBTX, arg *JobUpdateArgsEncryptionKeyParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobUpdateArgsEncryptionKey,
+		arg.Args,
+		arg.KeyID,
+		arg.ID,
+		arg.PreviousKeyID,
+	)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		&i.AttemptedBy,
+		&i.CreatedAt,
+		&i.Errors,
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		&i.Tags,
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
 const jobUpdateFull = `-- name: JobUpdateFull :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
-    attempt = CASE WHEN cast(?1 AS boolean) THEN ?2 ELSE attempt END,
-    attempted_at = CASE WHEN cast(?3 AS boolean) THEN ?4 ELSE attempted_at END,
-    attempted_by = CASE WHEN cast(?5 AS boolean) THEN jsonb(?6) ELSE attempted_by END,
-    errors = CASE WHEN cast(?7 AS boolean) THEN jsonb(?8) ELSE errors END,
-    finalized_at = CASE WHEN cast(?9 AS boolean) THEN ?10 ELSE finalized_at END,
-    max_attempts = CASE WHEN cast(?11 AS boolean) THEN ?12 ELSE max_attempts END,
-    metadata = CASE WHEN cast(?13 AS boolean) THEN jsonb(?14) ELSE metadata END,
-    state = CASE WHEN cast(?15 AS boolean) THEN ?16 ELSE state END
-WHERE id = ?17
+    args = CASE WHEN cast(?1 AS boolean) THEN jsonb(?2) ELSE args END,
+    attempt = CASE WHEN cast(?3 AS boolean) THEN ?4 ELSE attempt END,
+    attempted_at = CASE WHEN cast(?5 AS boolean) THEN ?6 ELSE attempted_at END,
+    attempted_by = CASE WHEN cast(?7 AS boolean) THEN jsonb(?8) ELSE attempted_by END,
+    errors = CASE WHEN cast(?9 AS boolean) THEN jsonb(?10) ELSE errors END,
+    finalized_at = CASE WHEN cast(?11 AS boolean) THEN ?12 ELSE finalized_at END,
+    max_attempts = CASE WHEN cast(?13 AS boolean) THEN ?14 ELSE max_attempts END,
+    metadata = CASE WHEN cast(?15 AS boolean) THEN jsonb(?16) ELSE metadata END,
+    state = CASE WHEN cast(?17 AS boolean) THEN ?18 ELSE state END
+WHERE id = ?19
 RETURNING id, json(args), attempt, attempted_at, json(attempted_by), created_at, json(errors), finalized_at, kind, max_attempts, json(metadata), priority, queue, state, scheduled_at, json(tags), unique_key, unique_states
 `
 
 type JobUpdateFullParams struct {
+	ArgsDoUpdate        bool
+	Args                interface{}
 	AttemptDoUpdate     bool
 	Attempt             int64
 	AttemptedAtDoUpdate bool
@@ -1797,6 +2541,8 @@ type JobUpdateFullParams struct {
 // of parameters and therefore may be more suitable for testing than production.
 func (q *Queries) JobUpdateFull(ctx context.Context, db DBTX, arg *JobUpdateFullParams) (*RiverJob, error) {
 	row := db.QueryRowContext(ctx, jobUpdateFull,
+		arg.ArgsDoUpdate,
+		arg.Args,
 		arg.AttemptDoUpdate,
 		arg.Attempt,
 		arg.AttemptedAtDoUpdate,
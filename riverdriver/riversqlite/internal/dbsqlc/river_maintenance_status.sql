@@ -0,0 +1,47 @@
+CREATE TABLE river_maintenance_status (
+    name text PRIMARY KEY NOT NULL,
+    client_id text NOT NULL,
+    consecutive_failures integer NOT NULL DEFAULT 0,
+    error text,
+    finished_at timestamp NOT NULL,
+    last_succeeded_at timestamp,
+    num_rows integer NOT NULL DEFAULT 0,
+    started_at timestamp NOT NULL,
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+-- name: MaintenanceStatusList :many
+SELECT *
+FROM /* TEMPLATE: schema */river_maintenance_status
+ORDER BY name ASC;
+
+-- name: MaintenanceStatusRecordRun :one
+INSERT INTO /* TEMPLATE: schema */river_maintenance_status (
+    name,
+    client_id,
+    consecutive_failures,
+    error,
+    finished_at,
+    last_succeeded_at,
+    num_rows,
+    started_at
+) VALUES (
+    @name,
+    @client_id,
+    CASE WHEN cast(sqlc.narg('error') AS text) IS NULL THEN 0 ELSE 1 END,
+    cast(sqlc.narg('error') AS text),
+    cast(@finished_at AS text),
+    CASE WHEN cast(sqlc.narg('error') AS text) IS NULL THEN cast(@finished_at AS text) ELSE NULL END,
+    @num_rows,
+    cast(@started_at AS text)
+)
+ON CONFLICT (name) DO UPDATE
+SET
+    client_id = EXCLUDED.client_id,
+    consecutive_failures = CASE WHEN EXCLUDED.error IS NULL THEN 0 ELSE river_maintenance_status.consecutive_failures + 1 END,
+    error = EXCLUDED.error,
+    finished_at = EXCLUDED.finished_at,
+    last_succeeded_at = coalesce(EXCLUDED.last_succeeded_at, river_maintenance_status.last_succeeded_at),
+    num_rows = EXCLUDED.num_rows,
+    started_at = EXCLUDED.started_at
+RETURNING *;
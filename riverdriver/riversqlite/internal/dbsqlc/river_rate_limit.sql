@@ -0,0 +1,27 @@
+CREATE TABLE river_rate_limit (
+    name text PRIMARY KEY NOT NULL,
+    tokens real NOT NULL,
+    updated_at timestamp NOT NULL DEFAULT (datetime('now', 'subsec')),
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+-- Takes a token from a rate limit's bucket, first refilling it for the time
+-- elapsed since it was last updated. Tokens go negative when none are
+-- available, representing a debt that callers wait out.
+-- name: RateLimitReserve :one
+INSERT INTO /* TEMPLATE: schema */river_rate_limit (
+    name,
+    tokens,
+    updated_at
+) VALUES (
+    @name,
+    cast(@burst AS real) - 1,
+    coalesce(cast(sqlc.narg('now') AS text), datetime('now', 'subsec'))
+) ON CONFLICT (name) DO UPDATE
+SET
+    tokens = min(
+        cast(@burst AS real),
+        river_rate_limit.tokens + cast(@rate AS real) * (unixepoch(EXCLUDED.updated_at, 'subsec') - unixepoch(river_rate_limit.updated_at, 'subsec'))
+    ) - 1,
+    updated_at = EXCLUDED.updated_at
+RETURNING tokens;
@@ -13,6 +13,9 @@ SELECT EXISTS (
     FROM /* TEMPLATE: schema */sqlite_master WHERE type = 'index' AND name = cast(@index AS text)
 );
 
+-- name: Ping :exec
+SELECT 1;
+
 -- name: TableExists :one
 SELECT EXISTS (
     SELECT 1
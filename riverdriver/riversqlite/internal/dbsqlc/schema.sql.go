@@ -23,6 +23,15 @@ func (q *Queries) IndexExists(ctx context.Context, db DBTX, index string) (bool,
 	return exists, err
 }
 
+const ping = `-- name: Ping :exec
+SELECT 1
+`
+
+func (q *Queries) Ping(ctx context.Context, db DBTX) error {
+	_, err := db.ExecContext(ctx, ping)
+	return err
+}
+
 const tableExists = `-- name: TableExists :one
 SELECT EXISTS (
     SELECT 1
@@ -275,3 +275,69 @@ SET updated_at = updated_at_old;
 
 ALTER TABLE /* TEMPLATE: schema */river_queue
     DROP COLUMN updated_at_old;
+
+--
+-- Rate limits.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_rate_limit (
+    name text PRIMARY KEY NOT NULL,
+    tokens real NOT NULL,
+    updated_at timestamp NOT NULL DEFAULT (datetime('now', 'subsec')),
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+--
+-- Job kind registry.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_job_kind (
+    kind text NOT NULL,
+    version text NOT NULL DEFAULT '',
+    args_schema blob NOT NULL DEFAULT (jsonb('{}')),
+    client_id text NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (datetime('now', 'subsec')),
+    updated_at timestamp NOT NULL DEFAULT (datetime('now', 'subsec')),
+    PRIMARY KEY (kind, version),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+--
+-- Maintenance status.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_maintenance_status (
+    name text PRIMARY KEY NOT NULL,
+    client_id text NOT NULL,
+    consecutive_failures integer NOT NULL DEFAULT 0,
+    error text,
+    finished_at timestamp NOT NULL,
+    last_succeeded_at timestamp,
+    num_rows integer NOT NULL DEFAULT 0,
+    started_at timestamp NOT NULL,
+    CONSTRAINT name_length CHECK (length(name) > 0 AND length(name) < 128)
+);
+
+--
+-- Job kind pauses.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_job_kind_pause (
+    kind text PRIMARY KEY NOT NULL,
+    paused_at timestamp NOT NULL DEFAULT (datetime('now', 'subsec')),
+    CONSTRAINT kind_length CHECK (length(kind) > 0 AND length(kind) < 128)
+);
+
+--
+-- Job external IDs.
+--
+
+CREATE UNIQUE INDEX /* TEMPLATE: schema */river_job_external_id_idx ON river_job (json_extract(metadata, '$."river:external_id"'))
+    WHERE json_extract(metadata, '$."river:external_id"') IS NOT NULL;
+
+--
+-- Job partition keys.
+--
+
+CREATE INDEX /* TEMPLATE: schema */river_job_partition_key_idx ON river_job (json_extract(metadata, '$."river:partition_key"'), id)
+    WHERE json_extract(metadata, '$."river:partition_key"') IS NOT NULL;
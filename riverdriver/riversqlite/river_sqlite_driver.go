@@ -119,6 +119,19 @@ func (d *Driver) PoolSet(dbPool any) error {
 	return nil
 }
 
+func (d *Driver) PoolStats() *riverdriver.PoolStats {
+	if d.dbPool == nil {
+		return nil
+	}
+	stats := d.dbPool.Stats()
+	return &riverdriver.PoolStats{
+		NumAcquired:  stats.InUse,
+		NumMax:       stats.MaxOpenConnections,
+		NumWaits:     stats.WaitCount,
+		WaitDuration: stats.WaitDuration,
+	}
+}
+
 func (d *Driver) SQLFragmentColumnIn(column string, values any) (string, any, error) {
 	arg, err := json.Marshal(values)
 	if err != nil {
@@ -244,6 +257,18 @@ func (e *Executor) IndexesExist(ctx context.Context, params *riverdriver.Indexes
 	return exists, nil
 }
 
+func (e *Executor) JobActivateMany(ctx context.Context, params *riverdriver.JobActivateManyParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobActivateMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobActivateManyParams{
+		ID:          params.ID,
+		Now:         timeStringNullable(params.Now),
+		ScheduledAt: timeString(params.ScheduledAt),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelParams) (*rivertype.JobRow, error) {
 	// Unlike Postgres, this must be carried out in two operations because
 	// SQLite doesn't support CTEs containing `UPDATE`. As long as the job
@@ -263,6 +288,7 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 			ID:                params.ID,
 			CancelAttemptedAt: string(cancelledAt),
 			Now:               timeStringNullable(params.Now),
+			Reason:            params.Reason,
 		})
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
@@ -282,6 +308,25 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 	})
 }
 
+func (e *Executor) JobChangeQueueMany(ctx context.Context, params *riverdriver.JobChangeQueueManyParams) ([]*rivertype.JobRow, error) {
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"order_by_clause": {Value: params.OrderByClause},
+		"where_clause":    {Value: params.WhereClause},
+	}, params.NamedArgs)
+
+	jobs, err := dbsqlc.New().JobChangeQueueMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobChangeQueueManyParams{
+		Max:   int64(params.Max),
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	// SQLite doesn't support `UPDATE` in CTEs, so as with JobDeleteMany, order
+	// post-operation before returning from driver.
+	slices.SortFunc(jobs, func(j1, j2 *dbsqlc.RiverJob) int { return int(j1.ID - j2.ID) })
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobCountByAllStates(ctx context.Context, params *riverdriver.JobCountByAllStatesParams) (map[rivertype.JobState]int, error) {
 	counts, err := dbsqlc.New().JobCountByAllStates(schemaTemplateParam(ctx, params.Schema), e.dbtx)
 	if err != nil {
@@ -311,18 +356,9 @@ func (e *Executor) JobCountByQueueAndState(ctx context.Context, params *riverdri
 	// `json_each(...)` to produce equivalent SQL. The SQLite SQL query therefore
 	// returns only queues with matching rows, and this wrapper fills in missing
 	// queues to match PostgreSQL behavior.
-	countsByQueue := make(map[string]struct {
-		CountAvailable int64
-		CountRunning   int64
-	}, len(rows))
+	countsByQueue := make(map[string]*dbsqlc.JobCountByQueueAndStateRow, len(rows))
 	for _, row := range rows {
-		countsByQueue[row.Queue] = struct {
-			CountAvailable int64
-			CountRunning   int64
-		}{
-			CountAvailable: row.CountAvailable,
-			CountRunning:   row.CountRunning,
-		}
+		countsByQueue[row.Queue] = row
 	}
 
 	queueNames := slices.Clone(params.QueueNames)
@@ -336,6 +372,7 @@ func (e *Executor) JobCountByQueueAndState(ctx context.Context, params *riverdri
 		}
 		if counts, ok := countsByQueue[queueName]; ok {
 			result.CountAvailable = counts.CountAvailable
+			result.CountClientsWithRunningJobs = counts.CountClientsWithRunningJobs
 			result.CountRunning = counts.CountRunning
 		}
 
@@ -353,6 +390,36 @@ func (e *Executor) JobCountByState(ctx context.Context, params *riverdriver.JobC
 	return int(numJobs), nil
 }
 
+func (e *Executor) JobCountMatching(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"where_clause": {Value: params.WhereClause},
+	}, params.NamedArgs)
+
+	numJobs, err := dbsqlc.New().JobCountMatching(schemaTemplateParam(ctx, params.Schema), e.dbtx, int64(params.Max))
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return int(numJobs), nil
+}
+
+func (e *Executor) JobCountMatchingEstimate(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	return 0, riverdriver.ErrNotImplemented
+}
+
+func (e *Executor) JobCountRunningByClient(ctx context.Context, params *riverdriver.JobCountRunningByClientParams) ([]*riverdriver.JobCountRunningByClientResult, error) {
+	rows, err := dbsqlc.New().JobCountRunningByClient(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(rows, func(row *dbsqlc.JobCountRunningByClientRow) *riverdriver.JobCountRunningByClientResult {
+		return &riverdriver.JobCountRunningByClientResult{
+			ClientID:        row.ClientID,
+			CountRunning:    row.CountRunning,
+			LastAttemptedAt: time.UnixMicro(int64(row.LastAttemptedAt * 1_000_000)).UTC(),
+		}
+	}), nil
+}
+
 func (e *Executor) JobDelete(ctx context.Context, params *riverdriver.JobDeleteParams) (*rivertype.JobRow, error) {
 	// Unlike Postgres, this must be carried out in two operations because
 	// SQLite doesn't support CTEs containing `DELETE`. As long as the job
@@ -465,6 +532,18 @@ var jobGetAvailableAttemptedBySQL = strings.TrimSpace(`
     )
 `)
 
+func (e *Executor) JobGetArgsEncryptionKeyStale(ctx context.Context, params *riverdriver.JobGetArgsEncryptionKeyStaleParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobGetArgsEncryptionKeyStale(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetArgsEncryptionKeyStaleParams{
+		AfterID:      params.AfterID,
+		CurrentKeyID: params.CurrentKeyID,
+		Max:          int64(params.Max),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
 	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
 		"attempted_by_clause": {
@@ -476,10 +555,84 @@ func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobG
 		"max_attempted_by": params.MaxAttemptedBy,
 	})
 
+	kindsExcludedSlice := params.KindsExcluded
+	if kindsExcludedSlice == nil {
+		kindsExcludedSlice = []string{}
+	}
+
+	kindsExcluded, err := json.Marshal(kindsExcludedSlice)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling excluded kinds: %w", err)
+	}
+
+	labelsMap := params.Labels
+	if labelsMap == nil {
+		labelsMap = map[string]string{}
+	}
+
+	labels, err := json.Marshal(labelsMap)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling labels: %w", err)
+	}
+
+	maxToLockByKindMap := params.MaxToLockByKind
+	if maxToLockByKindMap == nil {
+		maxToLockByKindMap = map[string]int{}
+	}
+
+	maxToLockByKind, err := json.Marshal(maxToLockByKindMap)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling max to lock by kind: %w", err)
+	}
+
+	if params.FairnessKey != "" {
+		fairnessWeightsMap := params.FairnessWeights
+		if fairnessWeightsMap == nil {
+			fairnessWeightsMap = map[string]int{}
+		}
+
+		fairnessWeights, err := json.Marshal(fairnessWeightsMap)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling fairness weights: %w", err)
+		}
+
+		jobs, err := dbsqlc.New().JobGetAvailableFair(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableFairParams{
+			FairnessKey:     params.FairnessKey,
+			FairnessWeights: fairnessWeights,
+			KindsExcluded:   kindsExcluded,
+			Labels:          labels,
+			MaxToLock:       int64(params.MaxToLock),
+			MaxToLockByKind: maxToLockByKind,
+			Now:             timeStringNullable(params.Now),
+			Queue:           params.Queue,
+		})
+		if err != nil {
+			return nil, interpretError(err)
+		}
+		return sliceutil.MapError(jobs, jobRowFromInternal)
+	}
+
+	if len(params.MaxToLockByKind) > 0 {
+		jobs, err := dbsqlc.New().JobGetAvailableLimitedByKind(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableLimitedByKindParams{
+			KindsExcluded:   kindsExcluded,
+			Labels:          labels,
+			MaxToLock:       int64(params.MaxToLock),
+			MaxToLockByKind: maxToLockByKind,
+			Now:             timeStringNullable(params.Now),
+			Queue:           params.Queue,
+		})
+		if err != nil {
+			return nil, interpretError(err)
+		}
+		return sliceutil.MapError(jobs, jobRowFromInternal)
+	}
+
 	jobs, err := dbsqlc.New().JobGetAvailable(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableParams{
-		MaxToLock: int64(params.MaxToLock),
-		Now:       timeStringNullable(params.Now),
-		Queue:     params.Queue,
+		KindsExcluded: kindsExcluded,
+		Labels:        labels,
+		MaxToLock:     int64(params.MaxToLock),
+		Now:           timeStringNullable(params.Now),
+		Queue:         params.Queue,
 	})
 	if err != nil {
 		return nil, interpretError(err)
@@ -487,6 +640,14 @@ func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobG
 	return sliceutil.MapError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetByExternalID(ctx context.Context, params *riverdriver.JobGetByExternalIDParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobGetByExternalID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ExternalID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
 func (e *Executor) JobGetByID(ctx context.Context, params *riverdriver.JobGetByIDParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobGetByID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
 	if err != nil {
@@ -511,9 +672,22 @@ func (e *Executor) JobGetByKindMany(ctx context.Context, params *riverdriver.Job
 	return sliceutil.MapError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetLeaseExpired(ctx context.Context, params *riverdriver.JobGetLeaseExpiredParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobGetLeaseExpired(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetLeaseExpiredParams{
+		Max:   int64(params.Max),
+		Now:   timeString(params.Now),
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.MapError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
 	jobs, err := dbsqlc.New().JobGetStuck(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetStuckParams{
 		Max:          int64(params.Max),
+		Now:          timeString(params.Now),
 		StuckHorizon: timeString(params.StuckHorizon),
 	})
 	if err != nil {
@@ -553,6 +727,10 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params *riverdriver.Jo
 	})
 }
 
+func (e *Executor) JobInsertFastManyCopyFrom(ctx context.Context, params *riverdriver.JobInsertFastManyParams) ([]*riverdriver.JobInsertFastResult, error) {
+	return nil, riverdriver.ErrNotImplemented
+}
+
 func (e *Executor) JobInsertFastManyNoReturning(ctx context.Context, params *riverdriver.JobInsertFastManyParams) (int, error) {
 	jobsParam, err := sqliteJobInsertFastManyJobsParam(params.Jobs, "")
 	if err != nil {
@@ -651,6 +829,89 @@ func (e *Executor) JobKindList(ctx context.Context, params *riverdriver.JobKindL
 	return kinds, nil
 }
 
+func (e *Executor) JobKindPause(ctx context.Context, params *riverdriver.JobKindPauseParams) error {
+	if err := dbsqlc.New().JobKindPause(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobKindPauseParams{
+		Kind: params.Kind,
+		Now:  timeStringNullable(params.Now),
+	}); err != nil {
+		return interpretError(err)
+	}
+	return nil
+}
+
+func (e *Executor) JobKindRegistrationList(ctx context.Context, params *riverdriver.JobKindRegistrationListParams) ([]*riverdriver.JobKindRegistration, error) {
+	registrations, err := dbsqlc.New().JobKindRegistrationList(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(registrations, jobKindRegistrationFromInternal), nil
+}
+
+func (e *Executor) JobKindRegistrationUpsertMany(ctx context.Context, params *riverdriver.JobKindRegistrationUpsertManyParams) error {
+	return dbutil.WithTx(ctx, e, func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		ctx = schemaTemplateParam(ctx, params.Schema)
+		dbtx := templateReplaceWrapper{dbtx: e.driver.UnwrapTx(execTx), replacer: &e.driver.replacer}
+
+		// Should be a batch operation, but that's currently impossible with SQLite/sqlc. https://github.com/sqlc-dev/sqlc/issues/3802
+		for _, registration := range params.Registrations {
+			if err := dbsqlc.New().JobKindRegistrationUpsert(ctx, dbtx, &dbsqlc.JobKindRegistrationUpsertParams{
+				ArgsSchema: sliceutil.FirstNonEmpty(registration.ArgsSchema, []byte("{}")),
+				ClientID:   params.ClientID,
+				Kind:       registration.Kind,
+				Now:        timeStringNullable(params.Now),
+				Version:    registration.Version,
+			}); err != nil {
+				return interpretError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (e *Executor) JobKindResume(ctx context.Context, params *riverdriver.JobKindResumeParams) error {
+	if err := dbsqlc.New().JobKindResume(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.Kind); err != nil {
+		return interpretError(err)
+	}
+	return nil
+}
+
+func (e *Executor) JobKindStorageUsage(ctx context.Context, params *riverdriver.JobKindStorageUsageParams) ([]*riverdriver.JobKindStorageUsageResult, error) {
+	rows, err := dbsqlc.New().JobKindStorageUsage(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(rows, func(row *dbsqlc.JobKindStorageUsageRow) *riverdriver.JobKindStorageUsageResult {
+		return &riverdriver.JobKindStorageUsageResult{
+			ArgsBytes:     row.ArgsBytes,
+			Count:         row.Count,
+			ErrorsBytes:   row.ErrorsBytes,
+			Kind:          row.Kind,
+			MetadataBytes: row.MetadataBytes,
+		}
+	}), nil
+}
+
+func (e *Executor) JobLeaseRenewMany(ctx context.Context, params *riverdriver.JobLeaseRenewManyParams) error {
+	return dbutil.WithTx(ctx, e, func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		ctx = schemaTemplateParam(ctx, params.Schema)
+		dbtx := templateReplaceWrapper{dbtx: e.driver.UnwrapTx(execTx), replacer: &e.driver.replacer}
+
+		// Should be a batch operation, but that's currently impossible with SQLite/sqlc. https://github.com/sqlc-dev/sqlc/issues/3802
+		for i := range params.ID {
+			if err := dbsqlc.New().JobLeaseRenew(ctx, dbtx, &dbsqlc.JobLeaseRenewParams{
+				Attempt:        int64(params.Attempt[i]),
+				ID:             params.ID[i],
+				LeaseExpiresAt: timeString(params.LeaseExpiresAt),
+			}); err != nil {
+				return interpretError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
 func (e *Executor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
 	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
 		"order_by_clause": {Value: params.OrderByClause},
@@ -917,6 +1178,19 @@ func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobUpdateArgsEncryptionKey(ctx context.Context, params *riverdriver.JobUpdateArgsEncryptionKeyParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobUpdateArgsEncryptionKey(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateArgsEncryptionKeyParams{
+		Args:          params.Args,
+		ID:            params.ID,
+		KeyID:         params.KeyID,
+		PreviousKeyID: params.PreviousKeyID,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
 func (e *Executor) JobUpdateFull(ctx context.Context, params *riverdriver.JobUpdateFullParams) (*rivertype.JobRow, error) {
 	attemptedAt := params.AttemptedAt
 	if attemptedAt != nil {
@@ -943,8 +1217,15 @@ func (e *Executor) JobUpdateFull(ctx context.Context, params *riverdriver.JobUpd
 		metadata = []byte("{}")
 	}
 
+	args := params.Args
+	if args == nil {
+		args = []byte("{}")
+	}
+
 	job, err := dbsqlc.New().JobUpdateFull(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateFullParams{
 		ID:                  params.ID,
+		ArgsDoUpdate:        params.ArgsDoUpdate,
+		Args:                args,
 		Attempt:             int64(params.Attempt),
 		AttemptDoUpdate:     params.AttemptDoUpdate,
 		AttemptedAt:         attemptedAt,
@@ -1035,6 +1316,29 @@ func (e *Executor) LeaderResign(ctx context.Context, params *riverdriver.LeaderR
 	return numResigned > 0, nil
 }
 
+func (e *Executor) MaintenanceStatusList(ctx context.Context, params *riverdriver.MaintenanceStatusListParams) ([]*riverdriver.MaintenanceStatus, error) {
+	statuses, err := dbsqlc.New().MaintenanceStatusList(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return sliceutil.Map(statuses, maintenanceStatusFromInternal), nil
+}
+
+func (e *Executor) MaintenanceStatusRecordRun(ctx context.Context, params *riverdriver.MaintenanceStatusRecordRunParams) (*riverdriver.MaintenanceStatus, error) {
+	status, err := dbsqlc.New().MaintenanceStatusRecordRun(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.MaintenanceStatusRecordRunParams{
+		Name:       params.Name,
+		ClientID:   params.ClientID,
+		Error:      params.Error,
+		FinishedAt: timeString(params.FinishedAt),
+		NumRows:    int64(params.NumRows),
+		StartedAt:  timeString(params.StartedAt),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return maintenanceStatusFromInternal(status), nil
+}
+
 func (e *Executor) MigrationDeleteAssumingMainMany(ctx context.Context, params *riverdriver.MigrationDeleteAssumingMainManyParams) ([]*riverdriver.Migration, error) {
 	migrations, err := dbsqlc.New().RiverMigrationDeleteAssumingMainMany(schemaTemplateParam(ctx, params.Schema), e.dbtx,
 		sliceutil.Map(params.Versions, func(v int) int64 { return int64(v) }))
@@ -1149,6 +1453,18 @@ func (e *Executor) PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}
 	return nil, riverdriver.ErrNotImplemented
 }
 
+func (e *Executor) PGTransactionTimeoutsGet(ctx context.Context) (*riverdriver.PGTransactionTimeouts, error) {
+	return nil, riverdriver.ErrNotImplemented
+}
+
+func (e *Executor) PGTransactionTimeoutsSet(ctx context.Context, timeouts *riverdriver.PGTransactionTimeouts) error {
+	return riverdriver.ErrNotImplemented
+}
+
+func (e *Executor) Ping(ctx context.Context) error {
+	return interpretError(dbsqlc.New().Ping(ctx, e.dbtx))
+}
+
 func (e *Executor) QueueCreateOrSetUpdatedAt(ctx context.Context, params *riverdriver.QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error) {
 	queue, err := dbsqlc.New().QueueCreateOrSetUpdatedAt(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.QueueCreateOrSetUpdatedAtParams{
 		Metadata:  sliceutil.FirstNonEmpty(params.Metadata, []byte("{}")),
@@ -1259,6 +1575,19 @@ func (e *Executor) QueryRow(ctx context.Context, sql string, args ...any) riverd
 	return e.dbtx.QueryRowContext(ctx, sql, args...)
 }
 
+func (e *Executor) RateLimitReserve(ctx context.Context, params *riverdriver.RateLimitReserveParams) (float64, error) {
+	tokens, err := dbsqlc.New().RateLimitReserve(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.RateLimitReserveParams{
+		Burst: params.Burst,
+		Name:  params.Name,
+		Now:   timeStringNullable(params.Now),
+		Rate:  params.Rate,
+	})
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return tokens, nil
+}
+
 func (e *Executor) SchemaCreate(ctx context.Context, params *riverdriver.SchemaCreateParams) error {
 	return nil
 }
@@ -1501,6 +1830,9 @@ func sqliteJobInsertFastManyJobsParam(jobs []*riverdriver.JobInsertFastParams, u
 			"unique_key":    hex.EncodeToString(job.UniqueKey),
 			"unique_states": int64(job.UniqueStates),
 		}
+		if job.ScheduledIn > 0 {
+			jobsParam[i]["scheduled_in_seconds"] = job.ScheduledIn.Seconds()
+		}
 	}
 
 	return json.Marshal(jobsParam)
@@ -1627,6 +1959,25 @@ func leaderFromInternal(internal *dbsqlc.RiverLeader) *riverdriver.Leader {
 	}
 }
 
+func maintenanceStatusFromInternal(internal *dbsqlc.RiverMaintenanceStatus) *riverdriver.MaintenanceStatus {
+	errStr := internal.Error
+	var lastSucceededAt *time.Time
+	if internal.LastSucceededAt != nil {
+		t := internal.LastSucceededAt.UTC()
+		lastSucceededAt = &t
+	}
+	return &riverdriver.MaintenanceStatus{
+		ClientID:            internal.ClientID,
+		ConsecutiveFailures: int(internal.ConsecutiveFailures),
+		Error:               errStr,
+		FinishedAt:          internal.FinishedAt.UTC(),
+		LastSucceededAt:     lastSucceededAt,
+		Name:                internal.Name,
+		NumRows:             int(internal.NumRows),
+		StartedAt:           internal.StartedAt.UTC(),
+	}
+}
+
 func migrationFromInternal(internal *dbsqlc.RiverMigration) *riverdriver.Migration {
 	return &riverdriver.Migration{
 		CreatedAt: internal.CreatedAt.UTC(),
@@ -1645,6 +1996,17 @@ func schemaTemplateParam(ctx context.Context, schema string) context.Context {
 	}, nil)
 }
 
+func jobKindRegistrationFromInternal(internal *dbsqlc.RiverJobKind) *riverdriver.JobKindRegistration {
+	return &riverdriver.JobKindRegistration{
+		ArgsSchema: internal.ArgsSchema,
+		ClientID:   internal.ClientID,
+		CreatedAt:  internal.CreatedAt.UTC(),
+		Kind:       internal.Kind,
+		UpdatedAt:  internal.UpdatedAt.UTC(),
+		Version:    internal.Version,
+	}
+}
+
 func queueFromInternal(internal *dbsqlc.RiverQueue) *rivertype.Queue {
 	var pausedAt *time.Time
 	if internal.PausedAt != nil {
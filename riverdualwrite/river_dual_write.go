@@ -0,0 +1,302 @@
+// Package riverdualwrite provides middleware that mirrors jobs inserted to one
+// River database or schema to a second one, and gradually shifts work between
+// them, for migrating a busy installation to a new database without downtime.
+package riverdualwrite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
+	"github.com/riverqueue/river/rivershared/uniquestates"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const metadataKey = "river:dual_write"
+
+// Config is configuration for Middleware.
+type Config struct {
+	// Schema is the schema where River tables are located in the secondary
+	// database.
+	//
+	// Defaults to empty, which causes the secondary's search path to be used.
+	Schema string
+
+	// SecondaryPercent is the initial percentage of inserted jobs, from 0 to
+	// 100, that are owned by the secondary database. It can be changed while
+	// the client is running with Middleware.SetSecondaryPercent. A job's owner
+	// is decided when it's inserted and doesn't change afterwards.
+	//
+	// Defaults to 0, so all jobs are worked in the primary database.
+	SecondaryPercent int
+}
+
+// Owner is the database that's responsible for working a dual-written job.
+type Owner string
+
+const (
+	// OwnerPrimary indicates that a job is worked in the primary database,
+	// which is the one that it was originally inserted to.
+	OwnerPrimary Owner = "primary"
+
+	// OwnerSecondary indicates that a job is worked in the secondary database,
+	// which is the one that it was mirrored to.
+	OwnerSecondary Owner = "secondary"
+)
+
+// DualWrite is stored to the metadata of both copies of a dual-written job.
+type DualWrite struct {
+	// Key identifies a dual-written job. It's the same for both copies, and
+	// is used to derive the unique key that keeps a job from being mirrored
+	// more than once.
+	Key string `json:"key"`
+
+	// Owner is the database that works the job. The copy in the other
+	// database is completed without being worked.
+	Owner Owner `json:"owner"`
+}
+
+// DualWriteMetadata extracts dual-write information from a job. Returns false
+// if the job wasn't dual-written.
+func DualWriteMetadata(job *rivertype.JobRow) (*DualWrite, bool) {
+	var metadata struct {
+		DualWrite *DualWrite `json:"river:dual_write"`
+	}
+	if err := json.Unmarshal(job.Metadata, &metadata); err != nil || metadata.DualWrite == nil {
+		return nil, false
+	}
+	return metadata.DualWrite, true
+}
+
+// Middleware mirrors jobs inserted by the primary client to a secondary River
+// database or schema. It's installed on the client of the primary database:
+//
+//	dualWrite := riverdualwrite.NewMiddleware(riverpgxv5.New(secondaryPool), &riverdualwrite.Config{})
+//
+//	client, err := river.NewClient(riverpgxv5.New(primaryPool), &river.Config{
+//		Middleware: []rivertype.Middleware{dualWrite},
+//		...
+//	})
+//
+// Clients working the secondary database install SecondaryMiddleware.
+//
+// Each inserted job is assigned an owner based on the percentage configured
+// with SetSecondaryPercent at the time it's inserted, and only its owner
+// works it. The copy in the other database is completed without being worked.
+// Ownership is fixed at insert time and stored to both copies, so changing the
+// percentage only affects jobs inserted afterwards. Jobs already inserted
+// aren't moved between databases, and in particular lowering the percentage
+// to back out of a migration leaves jobs that were assigned to the secondary
+// to be worked there. A migration starts at 0 percent, is raised gradually to
+// 100 percent while watching the secondary's workers, and is complete once
+// the primary's remaining jobs have been worked, after which clients can
+// insert directly to the secondary.
+//
+// Mirroring is idempotent: a mirrored job is inserted with a unique key
+// derived from a key stored to both copies' metadata, so a job that already
+// carries dual-write metadata (like one being inserted again after a failed
+// attempt) won't be mirrored twice. Jobs skipped as duplicates in the primary
+// aren't mirrored. Uniqueness isn't otherwise enforced in the secondary.
+//
+// Mirroring happens after a job is inserted to the primary but before the
+// primary's transaction commits, and a mirroring failure fails the insert.
+// The secondary can't take part in the primary's transaction though, and its
+// copy is committed immediately. A job inserted with InsertTx whose
+// transaction is later rolled back therefore leaves an orphaned copy in the
+// secondary. The orphan carries the owner it was assigned, so if that's
+// OwnerPrimary it's completed by the secondary without being worked, but if
+// it's OwnerSecondary it's worked even though the job was never inserted to
+// the primary. Installations that rely on rolling back inserts should keep
+// the percentage at 0 until that's no longer the case so that orphaned
+// copies are never worked.
+type Middleware struct {
+	river.MiddlewareDefaults
+
+	config               *Config
+	exec                 riverdriver.Executor
+	secondaryPercent     atomic.Int32
+	supportsListenNotify bool
+}
+
+// NewMiddleware initializes a new Middleware that mirrors jobs to the database
+// of the given driver. It panics if Config.SecondaryPercent is out of range.
+func NewMiddleware[TTx any](secondaryDriver riverdriver.Driver[TTx], config *Config) *Middleware {
+	middleware := &Middleware{
+		config:               config,
+		exec:                 secondaryDriver.GetExecutor(),
+		supportsListenNotify: secondaryDriver.SupportsListenNotify(),
+	}
+	middleware.SetSecondaryPercent(config.SecondaryPercent)
+	return middleware
+}
+
+// SecondaryPercent returns the current percentage of inserted jobs that are
+// owned by the secondary database.
+func (m *Middleware) SecondaryPercent() int {
+	return int(m.secondaryPercent.Load())
+}
+
+// SetSecondaryPercent sets the percentage of subsequently inserted jobs, from
+// 0 to 100, that are owned by the secondary database. It's safe to call while
+// the client is running. Jobs that were already inserted keep the owner they
+// were assigned at insert time regardless of the new percentage. It panics if
+// percent is out of range.
+func (m *Middleware) SetSecondaryPercent(percent int) {
+	if percent < 0 || percent > 100 {
+		panic("riverdualwrite: secondary percent must be between 0 and 100")
+	}
+	m.secondaryPercent.Store(int32(percent)) //nolint:gosec // range checked above
+}
+
+func (m *Middleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	secondaryPercent := m.SecondaryPercent()
+
+	dualWrites := make([]*DualWrite, len(manyParams))
+	for i, params := range manyParams {
+		if dualWrite, ok := DualWriteMetadata(&rivertype.JobRow{Metadata: params.Metadata}); ok {
+			dualWrites[i] = dualWrite
+			continue
+		}
+
+		dualWrite := &DualWrite{Key: randutil.Hex(16), Owner: OwnerPrimary}
+		if randutil.IntBetween(0, 100) < secondaryPercent {
+			dualWrite.Owner = OwnerSecondary
+		}
+
+		metadata, err := sjson.SetBytes(params.Metadata, metadataKey, dualWrite)
+		if err != nil {
+			return nil, fmt.Errorf("error setting dual-write metadata: %w", err)
+		}
+
+		dualWrites[i] = dualWrite
+		params.Metadata = metadata
+	}
+
+	results, err := doInner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.mirror(ctx, manyParams, results, dualWrites); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Inserts copies of newly inserted jobs to the secondary database.
+func (m *Middleware) mirror(ctx context.Context, manyParams []*rivertype.JobInsertParams, results []*rivertype.JobInsertResult, dualWrites []*DualWrite) error {
+	var (
+		mirrorParams = make([]*riverdriver.JobInsertFastParams, 0, len(results))
+		queues       = make([]string, 0, len(results))
+	)
+	for i, result := range results {
+		if result.UniqueSkippedAsDuplicate {
+			continue
+		}
+
+		job := result.Job
+		uniqueKey := sha256.Sum256([]byte("river:dual_write:" + dualWrites[i].Key))
+
+		mirrorParams = append(mirrorParams, &riverdriver.JobInsertFastParams{
+			Args:        manyParams[i].Args,
+			EncodedArgs: job.EncodedArgs,
+			Kind:        job.Kind,
+			MaxAttempts: job.MaxAttempts,
+			Metadata:    job.Metadata,
+			Priority:    job.Priority,
+			Queue:       job.Queue,
+			ScheduledAt: &job.ScheduledAt,
+			State:       job.State,
+			Tags:        job.Tags,
+			UniqueKey:   uniqueKey[:],
+			// All states so that a job isn't mirrored again even after it's
+			// been worked to completion in the secondary.
+			UniqueStates: uniquestates.UniqueStatesToBitmask(rivertype.JobStates()),
+		})
+
+		if job.State == rivertype.JobStateAvailable && dualWrites[i].Owner == OwnerSecondary {
+			queues = append(queues, job.Queue)
+		}
+	}
+
+	if len(mirrorParams) < 1 {
+		return nil
+	}
+
+	return dbutil.WithTx(ctx, m.exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+		mirrorResults, err := execTx.JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+			Jobs:   mirrorParams,
+			Schema: m.config.Schema,
+		})
+		if err != nil {
+			return fmt.Errorf("error inserting mirrored jobs: %w", err)
+		}
+		if len(mirrorResults) != len(mirrorParams) {
+			return errors.New("unexpected number of results from inserting mirrored jobs")
+		}
+
+		if !m.supportsListenNotify || len(queues) < 1 {
+			return nil
+		}
+
+		payloads := make([]string, len(queues))
+		for i, queue := range queues {
+			payloads[i], err = rivernotify.EncodeInsert(&rivernotify.InsertPayload{Queue: queue})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := execTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+			Payload: payloads,
+			Schema:  m.config.Schema,
+			Topic:   string(notifier.NotificationTopicInsert),
+		}); err != nil {
+			return fmt.Errorf("error notifying of mirrored jobs: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (m *Middleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	return workIfOwner(ctx, job, OwnerPrimary, doInner)
+}
+
+// SecondaryMiddleware is installed on clients working the secondary database
+// while jobs are being dual-written to it by Middleware. It skips jobs owned
+// by the primary database so that each job is only worked once.
+type SecondaryMiddleware struct {
+	river.MiddlewareDefaults
+}
+
+// NewSecondaryMiddleware initializes a new SecondaryMiddleware.
+func NewSecondaryMiddleware() *SecondaryMiddleware {
+	return &SecondaryMiddleware{}
+}
+
+func (m *SecondaryMiddleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	return workIfOwner(ctx, job, OwnerSecondary, doInner)
+}
+
+// Works a job unless it was dual-written and is owned by the other database,
+// in which case it's completed without being worked.
+func workIfOwner(ctx context.Context, job *rivertype.JobRow, owner Owner, doInner func(context.Context) error) error {
+	if dualWrite, ok := DualWriteMetadata(job); ok && dualWrite.Owner != owner {
+		return nil
+	}
+
+	return doInner(ctx)
+}
@@ -0,0 +1,317 @@
+package riverdualwrite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type dualWriteArgs struct{}
+
+func (dualWriteArgs) Kind() string { return "dual_write" }
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		secondaryExec   riverdriver.Executor
+		secondarySchema string
+	}
+
+	setup := func(t *testing.T, config *Config) (*Middleware, *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool          = riversharedtest.DBPool(ctx, t)
+			secondaryDriver = riverpgxv5.New(dbPool)
+			secondarySchema = riverdbtest.TestSchema(ctx, t, secondaryDriver, nil)
+		)
+
+		config.Schema = secondarySchema
+
+		return NewMiddleware(secondaryDriver, config), &testBundle{
+			secondaryExec:   secondaryDriver.GetExecutor(),
+			secondarySchema: secondarySchema,
+		}
+	}
+
+	// Simulates an insert to the primary database by returning a job row for
+	// each of the given params.
+	insertPrimary := func(manyParams []*rivertype.JobInsertParams, uniqueSkippedAsDuplicate bool) func(context.Context) ([]*rivertype.JobInsertResult, error) {
+		return func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			results := make([]*rivertype.JobInsertResult, len(manyParams))
+			for i, params := range manyParams {
+				results[i] = &rivertype.JobInsertResult{
+					Job: &rivertype.JobRow{
+						ID:          int64(i + 1),
+						EncodedArgs: params.EncodedArgs,
+						Kind:        params.Kind,
+						MaxAttempts: params.MaxAttempts,
+						Metadata:    params.Metadata,
+						Priority:    params.Priority,
+						Queue:       params.Queue,
+						ScheduledAt: time.Now(),
+						State:       params.State,
+						Tags:        params.Tags,
+					},
+					UniqueSkippedAsDuplicate: uniqueSkippedAsDuplicate,
+				}
+			}
+			return results, nil
+		}
+	}
+
+	makeParams := func() *rivertype.JobInsertParams {
+		return &rivertype.JobInsertParams{
+			EncodedArgs: []byte(`{"message":"hello"}`),
+			Kind:        "dual_write",
+			MaxAttempts: 5,
+			Metadata:    []byte(`{"foo":"bar"}`),
+			Priority:    2,
+			Queue:       "custom_queue",
+			State:       rivertype.JobStateAvailable,
+			Tags:        []string{"tag1"},
+		}
+	}
+
+	t.Run("MirrorsJob", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, &Config{})
+
+		manyParams := []*rivertype.JobInsertParams{makeParams()}
+		results, err := middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, false))
+		require.NoError(t, err)
+
+		primaryDualWrite, ok := DualWriteMetadata(results[0].Job)
+		require.True(t, ok)
+		require.Equal(t, OwnerPrimary, primaryDualWrite.Owner)
+
+		mirroredJobs, err := bundle.secondaryExec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{"dual_write"},
+			Schema: bundle.secondarySchema,
+		})
+		require.NoError(t, err)
+		require.Len(t, mirroredJobs, 1)
+
+		mirroredJob := mirroredJobs[0]
+		require.JSONEq(t, `{"message":"hello"}`, string(mirroredJob.EncodedArgs))
+		require.Equal(t, 5, mirroredJob.MaxAttempts)
+		require.Equal(t, 2, mirroredJob.Priority)
+		require.Equal(t, "custom_queue", mirroredJob.Queue)
+		require.Equal(t, rivertype.JobStateAvailable, mirroredJob.State)
+		require.Equal(t, []string{"tag1"}, mirroredJob.Tags)
+
+		secondaryDualWrite, ok := DualWriteMetadata(mirroredJob)
+		require.True(t, ok)
+		require.Equal(t, primaryDualWrite, secondaryDualWrite)
+
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(mirroredJob.Metadata, &metadata))
+		require.Equal(t, "bar", metadata["foo"])
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, &Config{})
+
+		manyParams := []*rivertype.JobInsertParams{makeParams()}
+		_, err := middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, false))
+		require.NoError(t, err)
+
+		// Params now carry dual-write metadata, as they would if the insert
+		// were being retried, so the job isn't mirrored again.
+		_, err = middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, false))
+		require.NoError(t, err)
+
+		numJobs, err := bundle.secondaryExec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+			Schema: bundle.secondarySchema,
+			State:  rivertype.JobStateAvailable,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("UniqueSkippedAsDuplicateNotMirrored", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, &Config{})
+
+		manyParams := []*rivertype.JobInsertParams{makeParams()}
+		_, err := middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, true))
+		require.NoError(t, err)
+
+		numJobs, err := bundle.secondaryExec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+			Schema: bundle.secondarySchema,
+			State:  rivertype.JobStateAvailable,
+		})
+		require.NoError(t, err)
+		require.Zero(t, numJobs)
+	})
+
+	t.Run("SecondaryPercent", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t, &Config{SecondaryPercent: 100})
+		require.Equal(t, 100, middleware.SecondaryPercent())
+
+		manyParams := []*rivertype.JobInsertParams{makeParams(), makeParams()}
+		results, err := middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, false))
+		require.NoError(t, err)
+
+		for _, result := range results {
+			dualWrite, ok := DualWriteMetadata(result.Job)
+			require.True(t, ok)
+			require.Equal(t, OwnerSecondary, dualWrite.Owner)
+		}
+
+		middleware.SetSecondaryPercent(0)
+
+		manyParams = []*rivertype.JobInsertParams{makeParams()}
+		results, err = middleware.InsertMany(ctx, manyParams, insertPrimary(manyParams, false))
+		require.NoError(t, err)
+
+		dualWrite, ok := DualWriteMetadata(results[0].Job)
+		require.True(t, ok)
+		require.Equal(t, OwnerPrimary, dualWrite.Owner)
+	})
+
+	t.Run("RolledBackInsertTxLeavesOrphanInSecondary", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, &Config{})
+
+		var (
+			dbPool        = riversharedtest.DBPool(ctx, t)
+			primaryDriver = riverpgxv5.New(dbPool)
+			primarySchema = riverdbtest.TestSchema(ctx, t, primaryDriver, nil)
+		)
+
+		client, err := river.NewClient(primaryDriver, &river.Config{
+			Middleware: []rivertype.Middleware{middleware},
+			Schema:     primarySchema,
+		})
+		require.NoError(t, err)
+
+		tx, err := dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+		_, err = client.InsertTx(ctx, tx, dualWriteArgs{}, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Rollback(ctx))
+
+		primaryJobs, err := primaryDriver.GetExecutor().JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{"dual_write"},
+			Schema: primarySchema,
+		})
+		require.NoError(t, err)
+		require.Empty(t, primaryJobs)
+
+		// The secondary's copy was committed independently of the primary's
+		// transaction, so it survives the rollback.
+		mirroredJobs, err := bundle.secondaryExec.JobGetByKindMany(ctx, &riverdriver.JobGetByKindManyParams{
+			Kind:   []string{"dual_write"},
+			Schema: bundle.secondarySchema,
+		})
+		require.NoError(t, err)
+		require.Len(t, mirroredJobs, 1)
+
+		// It was assigned to the primary at 0 percent though, so the secondary
+		// completes it without working it.
+		dualWrite, ok := DualWriteMetadata(mirroredJobs[0])
+		require.True(t, ok)
+		require.Equal(t, OwnerPrimary, dualWrite.Owner)
+
+		var worked bool
+		require.NoError(t, NewSecondaryMiddleware().Work(ctx, mirroredJobs[0], func(ctx context.Context) error {
+			worked = true
+			return nil
+		}))
+		require.False(t, worked)
+	})
+
+	t.Run("PanicsOnSecondaryPercentOutOfRange", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithValue(t, "riverdualwrite: secondary percent must be between 0 and 100", func() {
+			NewMiddleware(riverpgxv5.New(nil), &Config{SecondaryPercent: 101})
+		})
+
+		middleware := NewMiddleware(riverpgxv5.New(nil), &Config{})
+		require.PanicsWithValue(t, "riverdualwrite: secondary percent must be between 0 and 100", func() {
+			middleware.SetSecondaryPercent(-1)
+		})
+	})
+}
+
+func TestWorkIfOwner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	work := func(t *testing.T, middleware interface {
+		Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error
+	}, metadata string,
+	) bool {
+		t.Helper()
+
+		var worked bool
+		require.NoError(t, middleware.Work(ctx, &rivertype.JobRow{Metadata: []byte(metadata)}, func(ctx context.Context) error {
+			worked = true
+			return nil
+		}))
+		return worked
+	}
+
+	t.Run("Primary", func(t *testing.T) {
+		t.Parallel()
+
+		middleware := NewMiddleware(riverpgxv5.New(nil), &Config{})
+		require.True(t, work(t, middleware, `{}`))
+		require.True(t, work(t, middleware, `{"river:dual_write":{"key":"abc","owner":"primary"}}`))
+		require.False(t, work(t, middleware, `{"river:dual_write":{"key":"abc","owner":"secondary"}}`))
+	})
+
+	t.Run("Secondary", func(t *testing.T) {
+		t.Parallel()
+
+		middleware := NewSecondaryMiddleware()
+		require.True(t, work(t, middleware, `{}`))
+		require.False(t, work(t, middleware, `{"river:dual_write":{"key":"abc","owner":"primary"}}`))
+		require.True(t, work(t, middleware, `{"river:dual_write":{"key":"abc","owner":"secondary"}}`))
+	})
+}
+
+func TestDualWriteMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DualWritten", func(t *testing.T) {
+		t.Parallel()
+
+		dualWrite, ok := DualWriteMetadata(&rivertype.JobRow{Metadata: []byte(`{"river:dual_write":{"key":"abc","owner":"secondary"}}`)})
+		require.True(t, ok)
+		require.Equal(t, &DualWrite{Key: "abc", Owner: OwnerSecondary}, dualWrite)
+	})
+
+	t.Run("NotDualWritten", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := DualWriteMetadata(&rivertype.JobRow{Metadata: []byte(`{}`)})
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,195 @@
+// Package riverforward provides a worker that forwards jobs inserted in one
+// River database or schema to a different one to be worked there.
+package riverforward
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivernotify"
+	"github.com/riverqueue/river/rivershared/uniquestates"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const metadataKey = "river:forwarded_from"
+
+// Config is configuration for Worker.
+type Config struct {
+	// QueueFunc optionally selects the queue that a forwarded job is inserted
+	// to in the destination database. It can be used to implement execution
+	// policies like routing jobs to a queue that's only worked by clients in a
+	// particular region.
+	//
+	// Defaults to the job's queue in the source database.
+	QueueFunc func(job *rivertype.JobRow) string
+
+	// Schema is the schema where River tables are located in the destination
+	// database.
+	//
+	// Defaults to empty, which causes the destination's search path to be used.
+	Schema string
+
+	// Source is a name identifying the database and schema that jobs are being
+	// forwarded from. It's combined with a source job's ID to make sure that
+	// each job is forwarded exactly once even if forwarding is retried, so it
+	// must be unique amongst all sources forwarding to the same destination.
+	//
+	// Required.
+	Source string
+}
+
+// ForwardedFrom is stored to a forwarded job's metadata, identifying the job
+// it was forwarded from.
+type ForwardedFrom struct {
+	// JobID is the ID of the job in the source database.
+	JobID int64 `json:"job_id"`
+
+	// Source is the configured Config.Source of the worker that forwarded the
+	// job.
+	Source string `json:"source"`
+}
+
+// ForwardedFromMetadata extracts information on where a job was forwarded
+// from. Returns false if the job wasn't forwarded.
+func ForwardedFromMetadata(job *rivertype.JobRow) (*ForwardedFrom, bool) {
+	var metadata struct {
+		ForwardedFrom *ForwardedFrom `json:"river:forwarded_from"`
+	}
+	if err := json.Unmarshal(job.Metadata, &metadata); err != nil || metadata.ForwardedFrom == nil {
+		return nil, false
+	}
+	return metadata.ForwardedFrom, true
+}
+
+// Output is recorded as the output of a source job after it's been forwarded.
+type Output struct {
+	// ForwardedJobID is the ID of the job in the destination database.
+	ForwardedJobID int64 `json:"forwarded_job_id"`
+}
+
+// Worker is a worker that forwards jobs of a particular kind to a different
+// River database or schema, where they're worked by the destination's own
+// clients. It's registered in place of the kind's usual worker on the source
+// client:
+//
+//	river.AddWorker(workers, riverforward.NewWorker[SortArgs](riverpgxv5.New(destinationPool), &riverforward.Config{
+//		Source: "us-east-1",
+//	}))
+//
+// Forwarding is useful for gradually migrating jobs between clusters (a kind's
+// worker on the old cluster is swapped for a forwarding worker), or for making
+// sure that jobs are worked in a particular region.
+//
+// Forwarded jobs keep their args, kind, max attempts, metadata, priority, and
+// tags, and are inserted as available. Forwarding is idempotent: a forwarded
+// job is inserted with a unique key derived from Config.Source and the source
+// job's ID, so a forwarding attempt that's retried after failing to complete
+// in the source won't insert a duplicate as long as the first forwarded job
+// hasn't yet been removed from the destination by its job cleaner. Once
+// forwarded, the source job is completed with Output recorded as its output.
+type Worker[T river.JobArgs] struct {
+	river.WorkerDefaults[T]
+
+	config               *Config
+	exec                 riverdriver.Executor
+	supportsListenNotify bool
+}
+
+// NewWorker initializes a new Worker that forwards jobs to the database of
+// the given driver. It panics if Config.Source isn't set.
+func NewWorker[T river.JobArgs, TTx any](driver riverdriver.Driver[TTx], config *Config) *Worker[T] {
+	if config.Source == "" {
+		panic("riverforward: Config.Source must be set")
+	}
+
+	return &Worker[T]{
+		config:               config,
+		exec:                 driver.GetExecutor(),
+		supportsListenNotify: driver.SupportsListenNotify(),
+	}
+}
+
+func (w *Worker[T]) Work(ctx context.Context, job *river.Job[T]) error {
+	forwardedJob, err := w.forward(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	return river.RecordOutput(ctx, &Output{ForwardedJobID: forwardedJob.ID})
+}
+
+// Inserts a job to the destination database, or returns the job previously
+// inserted in case the job's already been forwarded.
+func (w *Worker[T]) forward(ctx context.Context, job *river.Job[T]) (*rivertype.JobRow, error) {
+	metadata, err := sjson.SetBytes(job.Metadata, metadataKey, &ForwardedFrom{JobID: job.ID, Source: w.config.Source})
+	if err != nil {
+		return nil, fmt.Errorf("error setting forwarded metadata: %w", err)
+	}
+
+	queue := job.Queue
+	if w.config.QueueFunc != nil {
+		queue = w.config.QueueFunc(job.JobRow)
+	}
+
+	uniqueKey := sha256.Sum256([]byte("river:forward:" + w.config.Source + ":" + strconv.FormatInt(job.ID, 10)))
+
+	return dbutil.WithTxV(ctx, w.exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) (*rivertype.JobRow, error) {
+		results, err := execTx.JobInsertFastMany(ctx, &riverdriver.JobInsertFastManyParams{
+			Jobs: []*riverdriver.JobInsertFastParams{
+				{
+					Args:        job.Args,
+					EncodedArgs: job.EncodedArgs,
+					Kind:        job.Kind,
+					MaxAttempts: job.MaxAttempts,
+					Metadata:    metadata,
+					Priority:    job.Priority,
+					Queue:       queue,
+					State:       rivertype.JobStateAvailable,
+					Tags:        job.Tags,
+					UniqueKey:   uniqueKey[:],
+					// All states so that a job isn't forwarded again even after
+					// it's been worked to completion in the destination.
+					UniqueStates: uniquestates.UniqueStatesToBitmask(rivertype.JobStates()),
+				},
+			},
+			Schema: w.config.Schema,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error inserting forwarded job: %w", err)
+		}
+		if len(results) < 1 {
+			return nil, errors.New("no result from inserting forwarded job")
+		}
+
+		if results[0].UniqueSkippedAsDuplicate {
+			return results[0].Job, nil
+		}
+
+		if w.supportsListenNotify {
+			payload, err := rivernotify.EncodeInsert(&rivernotify.InsertPayload{Queue: queue})
+			if err != nil {
+				return nil, err
+			}
+
+			if err := execTx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+				Payload: []string{payload},
+				Schema:  w.config.Schema,
+				Topic:   string(notifier.NotificationTopicInsert),
+			}); err != nil {
+				return nil, fmt.Errorf("error notifying of forwarded job: %w", err)
+			}
+		}
+
+		return results[0].Job, nil
+	})
+}
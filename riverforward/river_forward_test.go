@@ -0,0 +1,173 @@
+package riverforward
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type forwardArgs struct {
+	Message string `json:"message"`
+}
+
+func (forwardArgs) Kind() string { return "forward" }
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		destinationExec   riverdriver.Executor
+		destinationSchema string
+		tx                pgx.Tx
+	}
+
+	setup := func(t *testing.T, config *Config) (*Worker[forwardArgs], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool            = riversharedtest.DBPool(ctx, t)
+			destinationDriver = riverpgxv5.New(dbPool)
+			destinationSchema = riverdbtest.TestSchema(ctx, t, destinationDriver, nil)
+		)
+
+		if config.Source == "" {
+			config.Source = "source"
+		}
+		config.Schema = destinationSchema
+
+		return NewWorker[forwardArgs](destinationDriver, config), &testBundle{
+			destinationExec:   destinationDriver.GetExecutor(),
+			destinationSchema: destinationSchema,
+			tx:                riverdbtest.TestTxPgx(ctx, t),
+		}
+	}
+
+	t.Run("ForwardsJob", func(t *testing.T) {
+		t.Parallel()
+
+		worker, bundle := setup(t, &Config{})
+
+		testWorker := rivertest.NewWorker(t, riverpgxv5.New(nil), &river.Config{}, worker)
+
+		workRes, err := testWorker.Work(ctx, t, bundle.tx, forwardArgs{Message: "hello"}, &river.InsertOpts{
+			MaxAttempts: 5,
+			Metadata:    []byte(`{"foo":"bar"}`),
+			Priority:    2,
+			Queue:       "custom_queue",
+			Tags:        []string{"tag1"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, river.EventKindJobCompleted, workRes.EventKind)
+
+		var output Output
+		require.NoError(t, json.Unmarshal(workRes.Job.Output(), &output))
+
+		forwardedJob, err := bundle.destinationExec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     output.ForwardedJobID,
+			Schema: bundle.destinationSchema,
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"message":"hello"}`, string(forwardedJob.EncodedArgs))
+		require.Equal(t, (forwardArgs{}).Kind(), forwardedJob.Kind)
+		require.Equal(t, 5, forwardedJob.MaxAttempts)
+		require.Equal(t, 2, forwardedJob.Priority)
+		require.Equal(t, "custom_queue", forwardedJob.Queue)
+		require.Equal(t, rivertype.JobStateAvailable, forwardedJob.State)
+		require.Equal(t, []string{"tag1"}, forwardedJob.Tags)
+
+		forwardedFrom, ok := ForwardedFromMetadata(forwardedJob)
+		require.True(t, ok)
+		require.Equal(t, &ForwardedFrom{JobID: workRes.Job.ID, Source: "source"}, forwardedFrom)
+
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(forwardedJob.Metadata, &metadata))
+		require.Equal(t, "bar", metadata["foo"])
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		worker, bundle := setup(t, &Config{})
+
+		job := &river.Job[forwardArgs]{
+			Args:   forwardArgs{Message: "hello"},
+			JobRow: &rivertype.JobRow{ID: 123, EncodedArgs: []byte(`{"message":"hello"}`), Kind: (forwardArgs{}).Kind(), MaxAttempts: 25, Queue: river.QueueDefault},
+		}
+
+		forwardedJob1, err := worker.forward(ctx, job)
+		require.NoError(t, err)
+
+		forwardedJob2, err := worker.forward(ctx, job)
+		require.NoError(t, err)
+		require.Equal(t, forwardedJob1.ID, forwardedJob2.ID)
+
+		numJobs, err := bundle.destinationExec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+			Schema: bundle.destinationSchema,
+			State:  rivertype.JobStateAvailable,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+
+		// A job with the same ID from a different source is forwarded
+		// separately.
+		worker.config.Source = "other_source"
+
+		forwardedJob3, err := worker.forward(ctx, job)
+		require.NoError(t, err)
+		require.NotEqual(t, forwardedJob1.ID, forwardedJob3.ID)
+	})
+
+	t.Run("QueueFunc", func(t *testing.T) {
+		t.Parallel()
+
+		worker, _ := setup(t, &Config{
+			QueueFunc: func(job *rivertype.JobRow) string { return job.Queue + "_us_east_1" },
+		})
+
+		forwardedJob, err := worker.forward(ctx, &river.Job[forwardArgs]{
+			JobRow: &rivertype.JobRow{ID: 123, Kind: (forwardArgs{}).Kind(), MaxAttempts: 25, Queue: river.QueueDefault},
+		})
+		require.NoError(t, err)
+		require.Equal(t, river.QueueDefault+"_us_east_1", forwardedJob.Queue)
+	})
+
+	t.Run("PanicsWithoutSource", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithValue(t, "riverforward: Config.Source must be set", func() {
+			NewWorker[forwardArgs](riverpgxv5.New(nil), &Config{})
+		})
+	})
+}
+
+func TestForwardedFromMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Forwarded", func(t *testing.T) {
+		t.Parallel()
+
+		forwardedFrom, ok := ForwardedFromMetadata(&rivertype.JobRow{Metadata: []byte(`{"river:forwarded_from":{"job_id":123,"source":"source"}}`)})
+		require.True(t, ok)
+		require.Equal(t, &ForwardedFrom{JobID: 123, Source: "source"}, forwardedFrom)
+	})
+
+	t.Run("NotForwarded", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := ForwardedFromMetadata(&rivertype.JobRow{Metadata: []byte(`{}`)})
+		require.False(t, ok)
+	})
+}
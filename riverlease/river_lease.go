@@ -0,0 +1,527 @@
+// Package riverlease provides an HTTP handler that leases jobs to remote
+// workers, for environments where workers can't connect to the database
+// themselves, like those running in restricted networks.
+package riverlease
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/joblease"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	IDDefault            = "riverlease"
+	LeaseDurationDefault = 5 * time.Minute
+	MaxJobsDefault       = 100
+	MaxWaitDefault       = 30 * time.Second
+	PollIntervalDefault  = 1 * time.Second
+)
+
+// maxAttemptedBy is the maximum number of workers recorded in a job's
+// attempted_by, the same as used by clients.
+const maxAttemptedBy = 100
+
+// Config is configuration for Handler.
+type Config struct {
+	// ID is recorded to the attempted_by of leased jobs in place of the ID of
+	// the client that would otherwise have worked them.
+	//
+	// Defaults to "riverlease".
+	ID string
+
+	// LeaseDuration is how long a worker holds a job after leasing it or
+	// extending its lease. A job whose lease expires before it's completed or
+	// failed is made available again to be leased by another worker, or
+	// discarded if it's out of attempts.
+	//
+	// Defaults to 5 minutes.
+	LeaseDuration time.Duration
+
+	// MaxJobs is the maximum number of jobs that can be leased in one request.
+	//
+	// Defaults to 100.
+	MaxJobs int
+
+	// MaxWait is the maximum time that a lease request waits for jobs to
+	// become available before returning empty-handed.
+	//
+	// Defaults to 30 seconds.
+	MaxWait time.Duration
+
+	// PollInterval is how often a waiting lease request checks for new jobs.
+	//
+	// Defaults to 1 second.
+	PollInterval time.Duration
+
+	// Queues optionally restricts the queues that jobs can be leased from.
+	//
+	// Defaults to allowing any queue.
+	Queues []string
+
+	// RetryPolicy determines when failed jobs are retried.
+	//
+	// Defaults to river.DefaultClientRetryPolicy.
+	RetryPolicy river.ClientRetryPolicy
+
+	// Schema is the schema where River tables are located.
+	//
+	// Defaults to empty, which causes the database's search path to be used.
+	Schema string
+}
+
+func (c *Config) withDefaults() *Config {
+	return &Config{
+		ID:            cmp.Or(c.ID, IDDefault),
+		LeaseDuration: cmp.Or(c.LeaseDuration, LeaseDurationDefault),
+		MaxJobs:       cmp.Or(c.MaxJobs, MaxJobsDefault),
+		MaxWait:       cmp.Or(c.MaxWait, MaxWaitDefault),
+		PollInterval:  cmp.Or(c.PollInterval, PollIntervalDefault),
+		Queues:        c.Queues,
+		RetryPolicy:   cmp.Or[river.ClientRetryPolicy](c.RetryPolicy, &river.DefaultClientRetryPolicy{}),
+		Schema:        c.Schema,
+	}
+}
+
+// JobLease identifies a leased job. Attempt is the job's attempt when it was
+// leased, which distinguishes the lease from any later one on the same job
+// after the lease expired.
+type JobLease struct {
+	Attempt int   `json:"attempt"`
+	ID      int64 `json:"id"`
+}
+
+// LeaseRequest is the body of a request to lease jobs.
+type LeaseRequest struct {
+	// MaxJobs is the maximum number of jobs to lease. Defaults to and is
+	// limited by Config.MaxJobs.
+	MaxJobs int `json:"max_jobs"`
+
+	// Queue is the queue to lease jobs from. Required.
+	Queue string `json:"queue"`
+
+	// WaitMS is how long in milliseconds to wait for jobs to become available
+	// if there are none right away. Limited by Config.MaxWait.
+	WaitMS int64 `json:"wait_ms"`
+}
+
+// LeaseResponse is the response to a request to lease jobs.
+type LeaseResponse struct {
+	Jobs []*LeasedJob `json:"jobs"`
+}
+
+// LeasedJob is a job leased to a worker.
+type LeasedJob struct {
+	// Args are the job's encoded args as stored in the database. They're not
+	// decoded with any configured args codec.
+	Args           json.RawMessage `json:"args"`
+	Attempt        int             `json:"attempt"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ID             int64           `json:"id"`
+	Kind           string          `json:"kind"`
+	LeaseExpiresAt time.Time       `json:"lease_expires_at"`
+	MaxAttempts    int             `json:"max_attempts"`
+	Metadata       json.RawMessage `json:"metadata"`
+	Priority       int             `json:"priority"`
+	Queue          string          `json:"queue"`
+	Tags           []string        `json:"tags"`
+}
+
+// ExtendRequest is the body of a request to extend the leases of jobs.
+type ExtendRequest struct {
+	Jobs []JobLease `json:"jobs"`
+}
+
+// ExtendResponse is the response to a request to extend the leases of jobs.
+type ExtendResponse struct {
+	// LeaseExpiresAt is when the extended leases expire.
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+
+	// Lost are the IDs of jobs whose leases couldn't be extended because
+	// they'd already expired. Workers should stop working these jobs.
+	Lost []int64 `json:"lost"`
+}
+
+// CompleteRequest is the body of a request to complete a leased job.
+type CompleteRequest struct {
+	JobLease
+
+	// Output is optional output recorded to the job like river.RecordOutput.
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// FailRequest is the body of a request to fail a leased job.
+type FailRequest struct {
+	JobLease
+
+	// Error is recorded to the job's errors. Required.
+	Error string `json:"error"`
+}
+
+// StateResponse is the response to a request to complete or fail a leased
+// job, containing the job's new state.
+type StateResponse struct {
+	State rivertype.JobState `json:"state"`
+}
+
+// ErrorResponse is the response to a request that failed.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// errLeaseLost is returned when completing or failing a job whose lease has
+// expired.
+var errLeaseLost = errors.New("job lease has expired")
+
+// Handler is an http.Handler implementing a lease protocol that lets remote
+// workers work jobs over HTTP instead of with a client connected to the
+// database. It complements rather than replaces clients, and a queue can be
+// worked by both at once. Jobs are leased for Config.LeaseDuration, during
+// which a worker must complete or fail them, or extend their lease:
+//
+//	POST /lease    LeaseRequest    -> LeaseResponse
+//	POST /extend   ExtendRequest   -> ExtendResponse
+//	POST /complete CompleteRequest -> StateResponse
+//	POST /fail     FailRequest     -> StateResponse
+//
+// Lease requests long poll, waiting up to their requested time for jobs to
+// become available. Jobs whose leases expire are made available again by the
+// next lease request for their queue. Completing or failing a job whose lease
+// has expired responds with 409 Conflict.
+//
+// The handler does no authentication or authorization of its own, so it
+// should be wrapped in middleware that does, or only be reachable by trusted
+// workers. It's mounted under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/river/", http.StripPrefix("/river", riverlease.NewHandler(riverpgxv5.New(dbPool), &riverlease.Config{
+//		Queues: []string{"remote"},
+//	})))
+type Handler struct {
+	config *Config
+	exec   riverdriver.Executor
+	mux    *http.ServeMux
+}
+
+// NewHandler initializes a new Handler leasing jobs from the database of the
+// given driver.
+func NewHandler[TTx any](driver riverdriver.Driver[TTx], config *Config) *Handler {
+	handler := &Handler{
+		config: config.withDefaults(),
+		exec:   driver.GetExecutor(),
+		mux:    http.NewServeMux(),
+	}
+
+	handler.mux.HandleFunc("POST /complete", handleJSON(handler.complete))
+	handler.mux.HandleFunc("POST /extend", handleJSON(handler.extend))
+	handler.mux.HandleFunc("POST /fail", handleJSON(handler.fail))
+	handler.mux.HandleFunc("POST /lease", handleJSON(handler.lease))
+
+	return handler
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) complete(ctx context.Context, req *CompleteRequest) (*StateResponse, error) {
+	var metadataUpdates []byte
+	if len(req.Output) > 0 {
+		if !json.Valid(req.Output) {
+			return nil, &httpError{status: http.StatusBadRequest, message: "output must be valid JSON"}
+		}
+
+		var err error
+		metadataUpdates, err = json.Marshal(map[string]json.RawMessage{rivertype.MetadataKeyOutput: req.Output})
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling output: %w", err)
+		}
+	}
+
+	return h.setState(ctx, req.JobLease, func(job *rivertype.JobRow, now time.Time) (*riverdriver.JobSetStateIfRunningParams, error) {
+		return riverdriver.JobSetStateCompleted(job.ID, now, metadataUpdates), nil
+	})
+}
+
+func (h *Handler) extend(ctx context.Context, req *ExtendRequest) (*ExtendResponse, error) {
+	leaseExpiresAt := time.Now().Add(h.config.LeaseDuration).UTC()
+
+	if len(req.Jobs) < 1 {
+		return &ExtendResponse{LeaseExpiresAt: leaseExpiresAt, Lost: []int64{}}, nil
+	}
+
+	var (
+		attempts = make([]int, len(req.Jobs))
+		ids      = make([]int64, len(req.Jobs))
+	)
+	for i, lease := range req.Jobs {
+		attempts[i], ids[i] = lease.Attempt, lease.ID
+	}
+
+	if err := h.exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+		Attempt:        attempts,
+		ID:             ids,
+		LeaseExpiresAt: leaseExpiresAt,
+		Schema:         h.config.Schema,
+	}); err != nil {
+		return nil, fmt.Errorf("error extending job leases: %w", err)
+	}
+
+	jobs, err := h.exec.JobGetByIDMany(ctx, &riverdriver.JobGetByIDManyParams{
+		ID:     ids,
+		Schema: h.config.Schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting jobs: %w", err)
+	}
+
+	held := make(map[JobLease]struct{}, len(jobs))
+	for _, job := range jobs {
+		if job.State == rivertype.JobStateRunning {
+			held[JobLease{Attempt: job.Attempt, ID: job.ID}] = struct{}{}
+		}
+	}
+
+	lost := []int64{}
+	for _, lease := range req.Jobs {
+		if _, ok := held[lease]; !ok {
+			lost = append(lost, lease.ID)
+		}
+	}
+
+	return &ExtendResponse{LeaseExpiresAt: leaseExpiresAt, Lost: lost}, nil
+}
+
+func (h *Handler) fail(ctx context.Context, req *FailRequest) (*StateResponse, error) {
+	if req.Error == "" {
+		return nil, &httpError{status: http.StatusBadRequest, message: "error is required"}
+	}
+
+	return h.setState(ctx, req.JobLease, func(job *rivertype.JobRow, now time.Time) (*riverdriver.JobSetStateIfRunningParams, error) {
+		errData, err := json.Marshal(rivertype.AttemptError{
+			At:      now,
+			Attempt: job.Attempt,
+			Error:   req.Error,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling error JSON: %w", err)
+		}
+
+		if job.Attempt >= max(job.MaxAttempts, 0) {
+			return riverdriver.JobSetStateDiscarded(job.ID, now, errData, nil), nil
+		}
+
+		return riverdriver.JobSetStateErrorRetryable(job.ID, h.config.RetryPolicy.NextRetry(job).UTC(), errData, nil), nil
+	})
+}
+
+func (h *Handler) lease(ctx context.Context, req *LeaseRequest) (*LeaseResponse, error) {
+	if req.Queue == "" {
+		return nil, &httpError{status: http.StatusBadRequest, message: "queue is required"}
+	}
+	if len(h.config.Queues) > 0 && !slices.Contains(h.config.Queues, req.Queue) {
+		return nil, &httpError{status: http.StatusForbidden, message: fmt.Sprintf("jobs can't be leased from queue %q", req.Queue)}
+	}
+
+	maxJobs := h.config.MaxJobs
+	if req.MaxJobs > 0 {
+		maxJobs = min(req.MaxJobs, maxJobs)
+	}
+
+	wait := min(time.Duration(max(req.WaitMS, 0))*time.Millisecond, h.config.MaxWait)
+
+	if _, err := joblease.ReapExpired(ctx, h.exec, h.config.Schema, req.Queue, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := h.leaseOnce(ctx, req.Queue, maxJobs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &LeaseResponse{Jobs: []*LeasedJob{}}, nil
+			}
+			return nil, err
+		}
+		if len(jobs) > 0 {
+			return &LeaseResponse{Jobs: jobs}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &LeaseResponse{Jobs: []*LeasedJob{}}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaseOnce fetches available jobs and leases them in the same transaction so
+// that they're never running without a lease.
+func (h *Handler) leaseOnce(ctx context.Context, queue string, maxJobs int) ([]*LeasedJob, error) {
+	return dbutil.WithTxV(ctx, h.exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) ([]*LeasedJob, error) {
+		jobs, err := execTx.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+			ClientID:       h.config.ID,
+			MaxAttemptedBy: maxAttemptedBy,
+			MaxToLock:      maxJobs,
+			Queue:          queue,
+			Schema:         h.config.Schema,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching jobs: %w", err)
+		}
+		if len(jobs) < 1 {
+			return nil, nil
+		}
+
+		leaseExpiresAt := time.Now().Add(h.config.LeaseDuration).UTC()
+
+		var (
+			attempts = make([]int, len(jobs))
+			ids      = make([]int64, len(jobs))
+		)
+		for i, job := range jobs {
+			attempts[i], ids[i] = job.Attempt, job.ID
+		}
+
+		if err := execTx.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+			Attempt:        attempts,
+			ID:             ids,
+			LeaseExpiresAt: leaseExpiresAt,
+			Schema:         h.config.Schema,
+		}); err != nil {
+			return nil, fmt.Errorf("error leasing jobs: %w", err)
+		}
+
+		leasedJobs := make([]*LeasedJob, len(jobs))
+		for i, job := range jobs {
+			leasedJobs[i] = &LeasedJob{
+				Args:           job.EncodedArgs,
+				Attempt:        job.Attempt,
+				CreatedAt:      job.CreatedAt,
+				ID:             job.ID,
+				Kind:           job.Kind,
+				LeaseExpiresAt: leaseExpiresAt,
+				MaxAttempts:    job.MaxAttempts,
+				Metadata:       job.Metadata,
+				Priority:       job.Priority,
+				Queue:          job.Queue,
+				Tags:           job.Tags,
+			}
+		}
+		return leasedJobs, nil
+	})
+}
+
+// setState finalizes or schedules for retry a job whose lease is still held,
+// returning errLeaseLost otherwise.
+func (h *Handler) setState(ctx context.Context, lease JobLease, paramsFunc func(job *rivertype.JobRow, now time.Time) (*riverdriver.JobSetStateIfRunningParams, error)) (*StateResponse, error) {
+	return dbutil.WithTxV(ctx, h.exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) (*StateResponse, error) {
+		now := time.Now().UTC()
+
+		// Renewing the lease locks the job if it's still held so that it can't
+		// expire and be reaped between checking it and setting its state.
+		if err := execTx.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+			Attempt:        []int{lease.Attempt},
+			ID:             []int64{lease.ID},
+			LeaseExpiresAt: now.Add(h.config.LeaseDuration),
+			Schema:         h.config.Schema,
+		}); err != nil {
+			return nil, fmt.Errorf("error renewing job lease: %w", err)
+		}
+
+		job, err := execTx.JobGetByID(ctx, &riverdriver.JobGetByIDParams{
+			ID:     lease.ID,
+			Schema: h.config.Schema,
+		})
+		if err != nil {
+			if errors.Is(err, rivertype.ErrNotFound) {
+				return nil, &httpError{status: http.StatusNotFound, message: "job not found"}
+			}
+			return nil, fmt.Errorf("error getting job: %w", err)
+		}
+		if job.State != rivertype.JobStateRunning || job.Attempt != lease.Attempt {
+			return nil, &httpError{status: http.StatusConflict, message: errLeaseLost.Error()}
+		}
+
+		params, err := paramsFunc(job, now)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs, err := execTx.JobSetStateIfRunningMany(ctx, &riverdriver.JobSetStateIfRunningManyParams{
+			ID:              []int64{params.ID},
+			Attempt:         []*int{params.Attempt},
+			ErrData:         [][]byte{params.ErrData},
+			FinalizedAt:     []*time.Time{params.FinalizedAt},
+			MetadataDoMerge: []bool{params.MetadataDoMerge},
+			MetadataUpdates: [][]byte{params.MetadataUpdates},
+			Now:             &now,
+			ScheduledAt:     []*time.Time{params.ScheduledAt},
+			Schema:          h.config.Schema,
+			State:           []rivertype.JobState{params.State},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error setting job state: %w", err)
+		}
+		if len(jobs) < 1 {
+			return nil, &httpError{status: http.StatusConflict, message: errLeaseLost.Error()}
+		}
+
+		return &StateResponse{State: jobs[0].State}, nil
+	})
+}
+
+// httpError is an error returned to a worker with a specific status code.
+// Other errors are returned as 500s.
+type httpError struct {
+	message string
+	status  int
+}
+
+func (e *httpError) Error() string { return e.message }
+
+// handleJSON adapts a function taking and returning JSON bodies to an
+// http.HandlerFunc.
+func handleJSON[TReq, TRes any](handleFunc func(ctx context.Context, req *TReq) (*TRes, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req TReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid request body: " + err.Error()})
+			return
+		}
+
+		res, err := handleFunc(r.Context(), &req)
+		if err != nil {
+			var httpErr *httpError
+			if errors.As(err, &httpErr) {
+				writeJSON(w, httpErr.status, &ErrorResponse{Error: httpErr.message})
+				return
+			}
+
+			writeJSON(w, http.StatusInternalServerError, &ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,300 @@
+package riverlease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec   riverdriver.Executor
+		schema string
+	}
+
+	setup := func(t *testing.T, config *Config) (*Handler, *testBundle) {
+		t.Helper()
+
+		var (
+			driver = riverpgxv5.New(riversharedtest.DBPool(ctx, t))
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+
+		config.Schema = schema
+
+		return NewHandler(driver, config), &testBundle{
+			exec:   driver.GetExecutor(),
+			schema: schema,
+		}
+	}
+
+	// Sends a request to the handler, decoding its response body into res.
+	// Returns the response's status code.
+	request := func(t *testing.T, handler *Handler, path string, req, res any) int {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(reqBytes)))
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), res))
+
+		return recorder.Code
+	}
+
+	leaseOne := func(t *testing.T, handler *Handler) *LeasedJob {
+		t.Helper()
+
+		var res LeaseResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/lease", &LeaseRequest{MaxJobs: 1, Queue: "remote"}, &res))
+		require.Len(t, res.Jobs, 1)
+		return res.Jobs[0]
+	}
+
+	t.Run("LeasesJobs", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"job_num":1}`), Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"job_num":2}`), Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("other"), Schema: bundle.schema})
+
+		var res LeaseResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/lease", &LeaseRequest{Queue: "remote"}, &res))
+		require.Len(t, res.Jobs, 2)
+		require.Equal(t, []int64{job1.ID, job2.ID}, []int64{res.Jobs[0].ID, res.Jobs[1].ID})
+		require.JSONEq(t, `{"job_num":1}`, string(res.Jobs[0].Args))
+		require.Equal(t, 1, res.Jobs[0].Attempt)
+		require.WithinDuration(t, time.Now().Add(LeaseDurationDefault), res.Jobs[0].LeaseExpiresAt, 10*time.Second)
+
+		updatedJob, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job1.ID, Schema: bundle.schema})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, updatedJob.State)
+		require.Equal(t, []string{IDDefault}, updatedJob.AttemptedBy)
+		require.Equal(t, int64(1), gjson.GetBytes(updatedJob.Metadata, "river:lease_attempt").Int())
+	})
+
+	t.Run("LeaseLimitedByMaxJobs", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{MaxJobs: 2})
+
+		for range 3 {
+			testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		}
+
+		var res LeaseResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/lease", &LeaseRequest{MaxJobs: 10, Queue: "remote"}, &res))
+		require.Len(t, res.Jobs, 2)
+	})
+
+	t.Run("LeaseWaitsForJobs", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{PollInterval: 50 * time.Millisecond})
+
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			if _, err := bundle.exec.JobInsertFull(ctx, testfactory.Job_Build(t, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})); err != nil {
+				t.Errorf("error inserting job: %s", err)
+			}
+		}()
+
+		var res LeaseResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/lease", &LeaseRequest{Queue: "remote", WaitMS: 10_000}, &res))
+		require.Len(t, res.Jobs, 1)
+	})
+
+	t.Run("LeaseReturnsEmptyAfterWait", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{PollInterval: 50 * time.Millisecond})
+
+		var res LeaseResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/lease", &LeaseRequest{Queue: "remote", WaitMS: 100}, &res))
+		require.Empty(t, res.Jobs)
+	})
+
+	t.Run("LeaseReapsExpiredLeases", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Attempt:  ptrutil.Ptr(1),
+			Metadata: []byte(`{"river:lease_attempt":1,"river:lease_expires_at":"2000-01-01T00:00:00Z"}`),
+			Queue:    ptrutil.Ptr("remote"),
+			Schema:   bundle.schema,
+			State:    ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		leasedJob := leaseOne(t, handler)
+		require.Equal(t, job.ID, leasedJob.ID)
+		require.Equal(t, 2, leasedJob.Attempt)
+	})
+
+	t.Run("LeaseQueueNotAllowed", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{Queues: []string{"remote"}})
+
+		var res ErrorResponse
+		require.Equal(t, http.StatusForbidden, request(t, handler, "/lease", &LeaseRequest{Queue: "other"}, &res))
+		require.Equal(t, `jobs can't be leased from queue "other"`, res.Error)
+	})
+
+	t.Run("LeaseQueueRequired", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{})
+
+		var res ErrorResponse
+		require.Equal(t, http.StatusBadRequest, request(t, handler, "/lease", &LeaseRequest{}, &res))
+		require.Equal(t, "queue is required", res.Error)
+	})
+
+	t.Run("Extend", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{LeaseDuration: time.Minute})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		leasedJob := leaseOne(t, handler)
+
+		var res ExtendResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/extend", &ExtendRequest{Jobs: []JobLease{
+			{Attempt: leasedJob.Attempt, ID: leasedJob.ID},
+			{Attempt: leasedJob.Attempt - 1, ID: leasedJob.ID}, // stale attempt
+			{Attempt: 1, ID: 123_456_789},                      // nonexistent job
+		}}, &res))
+		require.Equal(t, []int64{leasedJob.ID, 123_456_789}, res.Lost)
+		require.True(t, res.LeaseExpiresAt.After(leasedJob.LeaseExpiresAt))
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		leasedJob := leaseOne(t, handler)
+
+		var res StateResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/complete", &CompleteRequest{
+			JobLease: JobLease{Attempt: leasedJob.Attempt, ID: leasedJob.ID},
+			Output:   json.RawMessage(`{"result":"ok"}`),
+		}, &res))
+		require.Equal(t, rivertype.JobStateCompleted, res.State)
+
+		updatedJob, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: leasedJob.ID, Schema: bundle.schema})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, updatedJob.State)
+		require.JSONEq(t, `{"result":"ok"}`, string(updatedJob.Output()))
+	})
+
+	t.Run("CompleteLeaseLost", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		leasedJob := leaseOne(t, handler)
+
+		var res ErrorResponse
+		require.Equal(t, http.StatusConflict, request(t, handler, "/complete", &CompleteRequest{
+			JobLease: JobLease{Attempt: leasedJob.Attempt - 1, ID: leasedJob.ID},
+		}, &res))
+		require.Equal(t, "job lease has expired", res.Error)
+	})
+
+	t.Run("CompleteNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{})
+
+		var res ErrorResponse
+		require.Equal(t, http.StatusNotFound, request(t, handler, "/complete", &CompleteRequest{
+			JobLease: JobLease{Attempt: 1, ID: 123_456_789},
+		}, &res))
+	})
+
+	t.Run("FailRetryable", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		leasedJob := leaseOne(t, handler)
+
+		var res StateResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/fail", &FailRequest{
+			JobLease: JobLease{Attempt: leasedJob.Attempt, ID: leasedJob.ID},
+			Error:    "remote failure",
+		}, &res))
+		require.Equal(t, rivertype.JobStateRetryable, res.State)
+
+		updatedJob, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: leasedJob.ID, Schema: bundle.schema})
+		require.NoError(t, err)
+		require.Len(t, updatedJob.Errors, 1)
+		require.Equal(t, "remote failure", updatedJob.Errors[0].Error)
+		require.True(t, updatedJob.ScheduledAt.After(time.Now()))
+	})
+
+	t.Run("FailDiscardsOutOfAttempts", func(t *testing.T) {
+		t.Parallel()
+
+		handler, bundle := setup(t, &Config{})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{MaxAttempts: ptrutil.Ptr(1), Queue: ptrutil.Ptr("remote"), Schema: bundle.schema})
+		leasedJob := leaseOne(t, handler)
+
+		var res StateResponse
+		require.Equal(t, http.StatusOK, request(t, handler, "/fail", &FailRequest{
+			JobLease: JobLease{Attempt: leasedJob.Attempt, ID: leasedJob.ID},
+			Error:    "remote failure",
+		}, &res))
+		require.Equal(t, rivertype.JobStateDiscarded, res.State)
+	})
+
+	t.Run("FailErrorRequired", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{})
+
+		var res ErrorResponse
+		require.Equal(t, http.StatusBadRequest, request(t, handler, "/fail", &FailRequest{JobLease: JobLease{Attempt: 1, ID: 1}}, &res))
+		require.Equal(t, "error is required", res.Error)
+	})
+
+	t.Run("InvalidRequestBody", func(t *testing.T) {
+		t.Parallel()
+
+		handler, _ := setup(t, &Config{})
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequestWithContext(ctx, http.MethodPost, "/lease", bytes.NewReader([]byte("not json"))))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
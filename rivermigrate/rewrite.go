@@ -0,0 +1,290 @@
+package rivermigrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/sqlctemplate"
+	"github.com/riverqueue/river/rivershared/util/dbutil"
+)
+
+const rewriteBatchSizeDefault = 10_000
+
+// RewriteStep is a step in an online rewrite run by Migrator.Rewrite. Exactly
+// one of BatchSQL or SQL must be set.
+type RewriteStep struct {
+	// BatchSQL is SQL that's run repeatedly against consecutive ranges of
+	// `river_job` IDs, each in its own transaction, so that no batch holds
+	// locks for long. It should use the named parameters `@min_id` (exclusive)
+	// and `@max_id` (inclusive) to select the range of jobs to operate on, and
+	// may use `/* TEMPLATE: schema */` to reference the configured schema. For
+	// example, to backfill a new column:
+	//
+	//	UPDATE /* TEMPLATE: schema */river_job
+	//	SET new_column = old_column
+	//	WHERE id > @min_id AND id <= @max_id
+	//
+	// The range of IDs covered is determined when the step starts, so jobs
+	// inserted while it's running must get a correct value by other means,
+	// like a column default or by being inserted by an updated client.
+	BatchSQL string
+
+	// Name identifies the step in progress reports and when resuming. It must
+	// be unique amongst a rewrite's steps.
+	Name string
+
+	// SQL is SQL that's run once, outside of a transaction, so that it may
+	// contain statements like `CREATE INDEX CONCURRENTLY`. It may use
+	// `/* TEMPLATE: schema */` to reference the configured schema. A step
+	// that's interrupted is run again when resuming, so statements should be
+	// written to tolerate that, like with `IF NOT EXISTS`.
+	SQL string
+}
+
+// RewriteOpts are options for Migrator.Rewrite.
+type RewriteOpts struct {
+	// BatchSize is the number of `river_job` IDs covered by each batch of a
+	// BatchSQL step.
+	//
+	// Defaults to 10,000.
+	BatchSize int
+
+	// BatchSleep is an optional pause between batches that gives the database
+	// room to serve other traffic, like workers and replication.
+	//
+	// Defaults to no pause.
+	BatchSleep time.Duration
+
+	// OnProgress is invoked after each batch of a BatchSQL step, and after each
+	// step completes. The last progress reported can be persisted and passed
+	// to ResumeFrom to resume an interrupted rewrite.
+	OnProgress func(progress *RewriteProgress)
+
+	// ResumeFrom resumes a rewrite from previously reported progress. Steps
+	// before the one in progress are skipped, and a BatchSQL step continues
+	// from its last completed batch.
+	ResumeFrom *RewriteProgress
+}
+
+// RewriteProgress is progress reported by Migrator.Rewrite.
+type RewriteProgress struct {
+	// Done is whether the step has completed.
+	Done bool
+
+	// LastID is the last `river_job` ID covered by a completed batch of a
+	// BatchSQL step. Zero for SQL steps.
+	LastID int64
+
+	// MaxID is the greatest `river_job` ID when a BatchSQL step started, and
+	// the ID at which the step will be done. Zero for SQL steps.
+	MaxID int64
+
+	// Step is the name of the step in progress.
+	Step string
+}
+
+// RewriteResult is the result of a rewrite.
+type RewriteResult struct {
+	// Steps are the steps that were run, in order. Steps skipped when resuming
+	// aren't included.
+	Steps []RewriteStepResult
+}
+
+// RewriteStepResult is information on a rewrite step that was run.
+type RewriteStepResult struct {
+	// Duration is the amount of time it took to run the step.
+	Duration time.Duration
+
+	// Name is the name of the step.
+	Name string
+
+	// NumBatches is the number of batches run for a BatchSQL step. Zero for
+	// SQL steps.
+	NumBatches int
+}
+
+// Rewrite runs an online rewrite of River's tables made up of steps like
+// adding a column, backfilling it in bounded batches, and building indexes
+// concurrently. It's meant for heavy schema changes on large `river_job`
+// tables where a normal migration would hold exclusive locks for a long time.
+// Unlike Migrate, steps aren't run in a single transaction and no version is
+// recorded in `river_migration`.
+//
+// A rewrite that's interrupted can be resumed by passing the last progress
+// reported to RewriteOpts.OnProgress as RewriteOpts.ResumeFrom.
+func (m *Migrator[TTx]) Rewrite(ctx context.Context, steps []RewriteStep, opts *RewriteOpts) (*RewriteResult, error) {
+	if opts == nil {
+		opts = &RewriteOpts{}
+	}
+
+	if opts.BatchSize < 0 {
+		return nil, errors.New("RewriteOpts.BatchSize cannot be less than zero")
+	}
+
+	stepNames := make(map[string]struct{}, len(steps))
+	for _, step := range steps {
+		if step.Name == "" {
+			return nil, errors.New("rewrite step must have a name")
+		}
+		if _, ok := stepNames[step.Name]; ok {
+			return nil, fmt.Errorf("duplicate rewrite step name: %s", step.Name)
+		}
+		stepNames[step.Name] = struct{}{}
+
+		if (step.BatchSQL == "") == (step.SQL == "") {
+			return nil, fmt.Errorf("rewrite step %s must have exactly one of BatchSQL or SQL", step.Name)
+		}
+	}
+
+	var resumeIndex int
+	if opts.ResumeFrom != nil {
+		resumeIndex = -1
+		for i, step := range steps {
+			if step.Name == opts.ResumeFrom.Step {
+				resumeIndex = i
+				break
+			}
+		}
+		if resumeIndex == -1 {
+			return nil, fmt.Errorf("rewrite step to resume from not found: %s", opts.ResumeFrom.Step)
+		}
+		if opts.ResumeFrom.Done {
+			resumeIndex++
+		}
+	}
+
+	exec := m.driver.GetExecutor()
+
+	res := &RewriteResult{Steps: make([]RewriteStepResult, 0, len(steps))}
+
+	for i := resumeIndex; i < len(steps); i++ {
+		step := steps[i]
+
+		var resumeProgress *RewriteProgress
+		if i == resumeIndex && opts.ResumeFrom != nil && !opts.ResumeFrom.Done {
+			resumeProgress = opts.ResumeFrom
+		}
+
+		var (
+			numBatches int
+			start      = time.Now()
+		)
+
+		if step.SQL != "" {
+			if err := exec.Exec(ctx, m.rewriteSQL(ctx, step.SQL)); err != nil {
+				return nil, fmt.Errorf("error running rewrite step %s: %w", step.Name, err)
+			}
+
+			if opts.OnProgress != nil {
+				opts.OnProgress(&RewriteProgress{Done: true, Step: step.Name})
+			}
+		} else {
+			var err error
+			numBatches, err = m.rewriteBatches(ctx, exec, step, opts, resumeProgress)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		duration := time.Since(start)
+
+		m.Logger.InfoContext(ctx, m.Name+": Completed rewrite step",
+			slog.Duration("duration", duration),
+			slog.Int("num_batches", numBatches),
+			slog.String("step", step.Name),
+		)
+
+		res.Steps = append(res.Steps, RewriteStepResult{Duration: duration, Name: step.Name, NumBatches: numBatches})
+	}
+
+	return res, nil
+}
+
+// Runs a BatchSQL step over consecutive ranges of job IDs up to the greatest
+// ID at the time it starts, returning the number of batches run.
+func (m *Migrator[TTx]) rewriteBatches(ctx context.Context, exec riverdriver.Executor, step RewriteStep, opts *RewriteOpts, resumeProgress *RewriteProgress) (int, error) {
+	batchSize := int64(cmp.Or(opts.BatchSize, rewriteBatchSizeDefault))
+
+	var lastID, maxID int64
+	if resumeProgress != nil {
+		lastID, maxID = resumeProgress.LastID, resumeProgress.MaxID
+	} else {
+		if err := exec.QueryRow(ctx, m.rewriteSQL(ctx, "SELECT coalesce(max(id), 0) FROM /* TEMPLATE: schema */river_job")).Scan(&maxID); err != nil {
+			return 0, fmt.Errorf("error getting max job ID for rewrite step %s: %w", step.Name, err)
+		}
+	}
+
+	var numBatches int
+	for lastID < maxID {
+		batchMaxID := min(lastID+batchSize, maxID)
+
+		sql, args := m.rewriteSQLWithArgs(ctx, step.BatchSQL, map[string]any{
+			"max_id": batchMaxID,
+			"min_id": lastID,
+		})
+
+		if err := dbutil.WithTx(ctx, exec, func(ctx context.Context, execTx riverdriver.ExecutorTx) error {
+			return execTx.Exec(ctx, sql, args...)
+		}); err != nil {
+			return numBatches, fmt.Errorf("error running rewrite step %s for IDs %d to %d: %w", step.Name, lastID, batchMaxID, err)
+		}
+
+		lastID = batchMaxID
+		numBatches++
+
+		m.Logger.DebugContext(ctx, m.Name+": Completed rewrite batch",
+			slog.Int64("last_id", lastID),
+			slog.Int64("max_id", maxID),
+			slog.String("step", step.Name),
+		)
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(&RewriteProgress{LastID: lastID, MaxID: maxID, Step: step.Name})
+		}
+
+		if opts.BatchSleep > 0 && lastID < maxID {
+			select {
+			case <-ctx.Done():
+				return numBatches, ctx.Err()
+			case <-time.After(opts.BatchSleep):
+			}
+		}
+	}
+
+	if opts.OnProgress != nil {
+		opts.OnProgress(&RewriteProgress{Done: true, LastID: lastID, MaxID: maxID, Step: step.Name})
+	}
+
+	return numBatches, nil
+}
+
+// Renders the schema template in rewrite SQL.
+func (m *Migrator[TTx]) rewriteSQL(ctx context.Context, sql string) string {
+	sql, _ = m.rewriteSQLWithArgs(ctx, sql, nil)
+	return sql
+}
+
+// Renders the schema template and named args in rewrite SQL, returning args
+// to pass along with it.
+func (m *Migrator[TTx]) rewriteSQLWithArgs(ctx context.Context, sql string, namedArgs map[string]any) (string, []any) {
+	if !strings.Contains(sql, "/* TEMPLATE: schema */") && len(namedArgs) < 1 {
+		return sql, nil
+	}
+
+	var schema string
+	if m.schema != "" {
+		schema = dbutil.SafeIdentifier(m.schema) + "."
+	}
+
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"schema": {Value: schema},
+	}, namedArgs)
+
+	return m.replacer.Run(ctx, m.driver.ArgPlaceholder(), sql, nil)
+}
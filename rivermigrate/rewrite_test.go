@@ -0,0 +1,160 @@
+package rivermigrate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+)
+
+func TestMigratorRewrite(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		schema string
+	}
+
+	setup := func(t *testing.T) (*Migrator[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riversharedtest.DBPool(ctx, t)
+		schema := "river_rewrite_test_" + randutil.Hex(8)
+		_, err := dbPool.Exec(ctx, "CREATE SCHEMA "+schema)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := dbPool.Exec(ctx, fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+			require.NoError(t, err)
+		})
+
+		migrator, err := New(riverpgxv5.New(dbPool), &Config{
+			Logger: riversharedtest.Logger(t),
+			Schema: schema,
+		})
+		require.NoError(t, err)
+
+		_, err = migrator.Migrate(ctx, DirectionUp, nil)
+		require.NoError(t, err)
+
+		_, err = dbPool.Exec(ctx, fmt.Sprintf(
+			"INSERT INTO %s.river_job (args, kind, max_attempts) SELECT '{}', 'kind_' || i, 25 FROM generate_series(1, 25) AS i", schema))
+		require.NoError(t, err)
+
+		return migrator, &testBundle{
+			dbPool: dbPool,
+			schema: schema,
+		}
+	}
+
+	steps := []RewriteStep{
+		{Name: "add_column", SQL: "ALTER TABLE /* TEMPLATE: schema */river_job ADD COLUMN IF NOT EXISTS kind_copy text"},
+		{Name: "backfill", BatchSQL: "UPDATE /* TEMPLATE: schema */river_job SET kind_copy = kind WHERE id > @min_id AND id <= @max_id"},
+		{Name: "add_index", SQL: "CREATE INDEX CONCURRENTLY IF NOT EXISTS river_job_kind_copy ON /* TEMPLATE: schema */river_job (kind_copy)"},
+	}
+
+	requireBackfilled := func(t *testing.T, bundle *testBundle, expected int) {
+		t.Helper()
+
+		var numBackfilled int
+		require.NoError(t, bundle.dbPool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s.river_job WHERE kind_copy = kind", bundle.schema)).Scan(&numBackfilled))
+		require.Equal(t, expected, numBackfilled)
+	}
+
+	t.Run("RunsStepsInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		migrator, bundle := setup(t)
+
+		var progress []*RewriteProgress
+		res, err := migrator.Rewrite(ctx, steps, &RewriteOpts{
+			BatchSize:  10,
+			OnProgress: func(p *RewriteProgress) { progress = append(progress, p) },
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Steps, 3)
+		require.Equal(t, "add_column", res.Steps[0].Name)
+		require.Equal(t, 3, res.Steps[1].NumBatches)
+
+		requireBackfilled(t, bundle, 25)
+
+		var maxID int64
+		require.NoError(t, bundle.dbPool.QueryRow(ctx, fmt.Sprintf("SELECT max(id) FROM %s.river_job", bundle.schema)).Scan(&maxID))
+
+		require.Len(t, progress, 6)
+		require.Equal(t, &RewriteProgress{Done: true, Step: "add_column"}, progress[0])
+		require.Equal(t, &RewriteProgress{LastID: 10, MaxID: maxID, Step: "backfill"}, progress[1])
+		require.Equal(t, &RewriteProgress{Done: true, LastID: maxID, MaxID: maxID, Step: "backfill"}, progress[4])
+		require.Equal(t, &RewriteProgress{Done: true, Step: "add_index"}, progress[5])
+	})
+
+	t.Run("ResumesFromProgress", func(t *testing.T) {
+		t.Parallel()
+
+		migrator, bundle := setup(t)
+
+		_, err := bundle.dbPool.Exec(ctx, fmt.Sprintf("ALTER TABLE %s.river_job ADD COLUMN kind_copy text", bundle.schema))
+		require.NoError(t, err)
+
+		var maxID int64
+		require.NoError(t, bundle.dbPool.QueryRow(ctx, fmt.Sprintf("SELECT max(id) FROM %s.river_job", bundle.schema)).Scan(&maxID))
+
+		res, err := migrator.Rewrite(ctx, steps, &RewriteOpts{
+			BatchSize:  10,
+			ResumeFrom: &RewriteProgress{LastID: 20, MaxID: maxID, Step: "backfill"},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Steps, 2)
+		require.Equal(t, "backfill", res.Steps[0].Name)
+		require.Equal(t, 1, res.Steps[0].NumBatches)
+
+		// Only jobs after the resumed batch were backfilled.
+		requireBackfilled(t, bundle, int(maxID-20))
+	})
+
+	t.Run("ResumesAfterDoneStep", func(t *testing.T) {
+		t.Parallel()
+
+		migrator, _ := setup(t)
+
+		res, err := migrator.Rewrite(ctx, steps[:1], &RewriteOpts{
+			ResumeFrom: &RewriteProgress{Done: true, Step: "add_column"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.Steps)
+	})
+
+	t.Run("InvalidSteps", func(t *testing.T) {
+		t.Parallel()
+
+		migrator, err := New(riverpgxv5.New(nil), nil)
+		require.NoError(t, err)
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{SQL: "SELECT 1"}}, nil)
+		require.EqualError(t, err, "rewrite step must have a name")
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{Name: "step", SQL: "SELECT 1"}, {Name: "step", SQL: "SELECT 1"}}, nil)
+		require.EqualError(t, err, "duplicate rewrite step name: step")
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{Name: "step"}}, nil)
+		require.EqualError(t, err, "rewrite step step must have exactly one of BatchSQL or SQL")
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{Name: "step", BatchSQL: "SELECT 1", SQL: "SELECT 1"}}, nil)
+		require.EqualError(t, err, "rewrite step step must have exactly one of BatchSQL or SQL")
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{Name: "step", SQL: "SELECT 1"}}, &RewriteOpts{ResumeFrom: &RewriteProgress{Step: "other"}})
+		require.EqualError(t, err, "rewrite step to resume from not found: other")
+
+		_, err = migrator.Rewrite(ctx, []RewriteStep{{Name: "step", SQL: "SELECT 1"}}, &RewriteOpts{BatchSize: -1})
+		require.EqualError(t, err, "RewriteOpts.BatchSize cannot be less than zero")
+	})
+}
@@ -0,0 +1,266 @@
+// Package rivernotify defines the payloads of the notifications that River
+// clients send each other through Postgres listen/notify, along with functions
+// to encode and decode them. It allows programs not written in Go to produce
+// notifications that River clients understand (for example, an insert
+// notification after inserting jobs with raw SQL so they're worked
+// immediately) and to observe the notifications River sends.
+//
+// Notifications are sent on a channel made of the River schema and a topic
+// separated by a dot, like `public.river_insert`. When no schema is
+// configured, the connection's current schema is used.
+//
+// # Versioning
+//
+// Payloads are JSON objects carrying a version in the "v" key. Fields may be
+// added to a payload without changing its version, so consumers should ignore
+// keys they don't recognize. The version is only incremented when a payload
+// changes in a way that's incompatible with existing consumers, and Decode
+// functions return ErrUnsupportedVersion for payloads with a version newer
+// than the one they understand rather than misinterpreting them. A payload
+// without a version is treated as version 1, which is what River sent before
+// payloads were versioned.
+package rivernotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Version is the latest version of notification payloads. Payloads encoded by
+// this package are stamped with it.
+const Version = 1
+
+// ErrUnsupportedVersion is returned when decoding a payload whose version is
+// newer than Version, which usually means it was sent by a newer version of
+// River.
+var ErrUnsupportedVersion = errors.New("unsupported notification payload version")
+
+// Topic is a topic on which notifications are sent.
+type Topic string
+
+const (
+	// TopicControl carries ControlPayload notifications used to control
+	// queues and jobs, like pausing a queue or cancelling a running job.
+	TopicControl Topic = "river_control"
+
+	// TopicInsert carries InsertPayload notifications sent when jobs are made
+	// available in a queue so that clients working it fetch them immediately.
+	TopicInsert Topic = "river_insert"
+
+	// TopicLeadership carries LeadershipPayload notifications used to
+	// coordinate leader election.
+	TopicLeadership Topic = "river_leadership"
+)
+
+// Channel returns the name of the channel on which notifications for topic
+// are sent for the given River schema.
+func Channel(schema string, topic Topic) string {
+	return schema + "." + string(topic)
+}
+
+// ControlAction is the action of a ControlPayload.
+type ControlAction string
+
+const (
+	// ControlActionCancel cancels the running job JobID in Queue.
+	ControlActionCancel ControlAction = "cancel"
+
+	// ControlActionCustom is a custom control message named Name sent by an
+	// application, delivered to all clients or only to ClientID if set.
+	ControlActionCustom ControlAction = "custom"
+
+	// ControlActionJobStatusRequest asks the client ClientID for the live
+	// status of its running job JobID in Queue. The client responds with a
+	// ControlActionJobStatusResponse targeted to ReplyTo and carrying the same
+	// RequestID.
+	ControlActionJobStatusRequest ControlAction = "job_status_request"
+
+	// ControlActionJobStatusResponse is a response to a
+	// ControlActionJobStatusRequest, targeted to the requesting client
+	// ClientID. JobStatus is nil if the job wasn't running on the responding
+	// client.
+	ControlActionJobStatusResponse ControlAction = "job_status_response"
+
+	// ControlActionMetadataChanged indicates that the metadata of Queue was
+	// changed to Metadata.
+	ControlActionMetadataChanged ControlAction = "metadata_changed"
+
+	// ControlActionPause pauses Queue, or all queues if Queue is "*".
+	ControlActionPause ControlAction = "pause"
+
+	// ControlActionResume resumes Queue, or all queues if Queue is "*".
+	ControlActionResume ControlAction = "resume"
+)
+
+// ControlPayload is the payload of a notification on TopicControl.
+type ControlPayload struct {
+	// Action is the action to take.
+	Action ControlAction `json:"action"`
+
+	// ClientID is the ID of the client a custom control message or job status
+	// request or response is targeted to, or empty if it's broadcast to all
+	// clients.
+	ClientID string `json:"client_id,omitempty"`
+
+	// JobID is the ID of the job to act on for job actions like cancel.
+	JobID int64 `json:"job_id,omitempty"`
+
+	// JobStatus is the live status of a job for
+	// ControlActionJobStatusResponse, or nil if the job wasn't running.
+	JobStatus *JobStatus `json:"job_status,omitempty"`
+
+	// Metadata is the new metadata of the queue for
+	// ControlActionMetadataChanged.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Name is the name of a custom control message.
+	Name string `json:"name,omitempty"`
+
+	// Payload is the application-defined payload of a custom control message.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Queue is the name of the queue acted on, or of the queue of the job
+	// acted on.
+	Queue string `json:"queue"`
+
+	// Reason is an optional explanation for a job cancellation.
+	Reason string `json:"reason,omitempty"`
+
+	// ReplyTo is the ID of the client to which a response to
+	// ControlActionJobStatusRequest should be sent.
+	ReplyTo string `json:"reply_to,omitempty"`
+
+	// RequestID correlates a ControlActionJobStatusResponse with the
+	// ControlActionJobStatusRequest it answers.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Version is the version of the payload. It's set automatically by
+	// EncodeControl.
+	Version int `json:"v,omitempty"`
+}
+
+// JobStatus is the live status of a running job reported by the client
+// working it.
+type JobStatus struct {
+	// ElapsedMS is the number of milliseconds since the job's current attempt
+	// started.
+	ElapsedMS int64 `json:"elapsed_ms"`
+
+	// Host is the hostname of the machine running the client working the job.
+	Host string `json:"host,omitempty"`
+
+	// Progress is the progress most recently recorded by the job's worker, or
+	// empty if none was recorded.
+	Progress json.RawMessage `json:"progress,omitempty"`
+}
+
+// InsertPayload is the payload of a notification on TopicInsert.
+type InsertPayload struct {
+	// Queue is the name of the queue in which jobs became available.
+	Queue string `json:"queue"`
+
+	// Version is the version of the payload. It's set automatically by
+	// EncodeInsert.
+	Version int `json:"v,omitempty"`
+}
+
+// LeadershipAction is the action of a LeadershipPayload.
+type LeadershipAction string
+
+const (
+	// LeadershipActionPreempt is sent by a client that's not leader to ask
+	// the leader to resign if its priority is lower than Priority.
+	LeadershipActionPreempt LeadershipAction = "preempt"
+
+	// LeadershipActionRequestResign asks the current leader to resign.
+	LeadershipActionRequestResign LeadershipAction = "request_resign"
+
+	// LeadershipActionResigned is sent when the leader LeaderID resigns so
+	// that other clients may try to become leader right away.
+	LeadershipActionResigned LeadershipAction = "resigned"
+)
+
+// LeadershipPayload is the payload of a notification on TopicLeadership.
+type LeadershipPayload struct {
+	// Action is the action that occurred or is requested.
+	Action LeadershipAction `json:"action"`
+
+	// LeaderID is the ID of the client that resigned for
+	// LeadershipActionResigned, or of the client sending the notification for
+	// LeadershipActionPreempt.
+	LeaderID string `json:"leader_id"`
+
+	// Priority is the leader election priority of the client sending a
+	// LeadershipActionPreempt.
+	Priority int `json:"priority,omitempty"`
+
+	// Version is the version of the payload. It's set automatically by
+	// EncodeLeadership.
+	Version int `json:"v,omitempty"`
+}
+
+// DecodeControl decodes a notification payload received on TopicControl.
+func DecodeControl(payload string) (*ControlPayload, error) {
+	return decode[ControlPayload](payload, func(p *ControlPayload) *int { return &p.Version })
+}
+
+// DecodeInsert decodes a notification payload received on TopicInsert.
+func DecodeInsert(payload string) (*InsertPayload, error) {
+	return decode[InsertPayload](payload, func(p *InsertPayload) *int { return &p.Version })
+}
+
+// DecodeLeadership decodes a notification payload received on
+// TopicLeadership.
+func DecodeLeadership(payload string) (*LeadershipPayload, error) {
+	return decode[LeadershipPayload](payload, func(p *LeadershipPayload) *int { return &p.Version })
+}
+
+// EncodeControl encodes a payload to be sent on TopicControl, stamping it with
+// the current Version.
+func EncodeControl(payload *ControlPayload) (string, error) {
+	payloadCopy := *payload
+	payloadCopy.Version = Version
+	return encode(&payloadCopy)
+}
+
+// EncodeInsert encodes a payload to be sent on TopicInsert, stamping it with
+// the current Version.
+func EncodeInsert(payload *InsertPayload) (string, error) {
+	payloadCopy := *payload
+	payloadCopy.Version = Version
+	return encode(&payloadCopy)
+}
+
+// EncodeLeadership encodes a payload to be sent on TopicLeadership, stamping
+// it with the current Version.
+func EncodeLeadership(payload *LeadershipPayload) (string, error) {
+	payloadCopy := *payload
+	payloadCopy.Version = Version
+	return encode(&payloadCopy)
+}
+
+func decode[T any](payload string, versionPtr func(*T) *int) (*T, error) {
+	var decoded T
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		return nil, fmt.Errorf("error decoding notification payload: %w", err)
+	}
+
+	version := versionPtr(&decoded)
+	switch {
+	case *version == 0:
+		*version = 1
+	case *version > Version:
+		return nil, fmt.Errorf("%w: %d (latest supported is %d)", ErrUnsupportedVersion, *version, Version)
+	}
+
+	return &decoded, nil
+}
+
+func encode(payload any) (string, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("error encoding notification payload: %w", err)
+	}
+	return string(payloadBytes), nil
+}
@@ -0,0 +1,185 @@
+package rivernotify
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannel(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "public.river_insert", Channel("public", TopicInsert))
+	require.Equal(t, "custom_schema.river_control", Channel("custom_schema", TopicControl))
+}
+
+func TestControl(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := EncodeControl(&ControlPayload{
+			Action:   ControlActionMetadataChanged,
+			Metadata: json.RawMessage(`{"foo":"bar"}`),
+			Queue:    "default",
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"action":"metadata_changed","metadata":{"foo":"bar"},"queue":"default","v":1}`, payload)
+
+		decoded, err := DecodeControl(payload)
+		require.NoError(t, err)
+		require.Equal(t, &ControlPayload{
+			Action:   ControlActionMetadataChanged,
+			Metadata: json.RawMessage(`{"foo":"bar"}`),
+			Queue:    "default",
+			Version:  1,
+		}, decoded)
+	})
+
+	t.Run("RoundTripJobStatus", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := EncodeControl(&ControlPayload{
+			Action:   ControlActionJobStatusResponse,
+			ClientID: "client_1",
+			JobID:    123,
+			JobStatus: &JobStatus{
+				ElapsedMS: 1500,
+				Host:      "worker-1",
+				Progress:  json.RawMessage(`{"done":5}`),
+			},
+			Queue:     "default",
+			RequestID: "client_1_1",
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"action":"job_status_response","client_id":"client_1","job_id":123,"job_status":{"elapsed_ms":1500,"host":"worker-1","progress":{"done":5}},"queue":"default","request_id":"client_1_1","v":1}`, payload)
+
+		decoded, err := DecodeControl(payload)
+		require.NoError(t, err)
+		require.Equal(t, &JobStatus{
+			ElapsedMS: 1500,
+			Host:      "worker-1",
+			Progress:  json.RawMessage(`{"done":5}`),
+		}, decoded.JobStatus)
+		require.Equal(t, "client_1_1", decoded.RequestID)
+	})
+
+	t.Run("EncodeDoesNotModifyInput", func(t *testing.T) {
+		t.Parallel()
+
+		input := &ControlPayload{Action: ControlActionPause, Queue: "default"}
+		_, err := EncodeControl(input)
+		require.NoError(t, err)
+		require.Zero(t, input.Version)
+	})
+
+	t.Run("DecodeUnversioned", func(t *testing.T) {
+		t.Parallel()
+
+		decoded, err := DecodeControl(`{"action":"cancel","job_id":123,"queue":"default","reason":"no longer needed"}`)
+		require.NoError(t, err)
+		require.Equal(t, &ControlPayload{
+			Action:  ControlActionCancel,
+			JobID:   123,
+			Queue:   "default",
+			Reason:  "no longer needed",
+			Version: 1,
+		}, decoded)
+	})
+
+	t.Run("DecodeIgnoresUnknownFields", func(t *testing.T) {
+		t.Parallel()
+
+		decoded, err := DecodeControl(`{"action":"pause","queue":"default","new_field":"value","v":1}`)
+		require.NoError(t, err)
+		require.Equal(t, &ControlPayload{Action: ControlActionPause, Queue: "default", Version: 1}, decoded)
+	})
+
+	t.Run("DecodeUnsupportedVersion", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DecodeControl(`{"action":"pause","queue":"default","v":2}`)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+		require.EqualError(t, err, "unsupported notification payload version: 2 (latest supported is 1)")
+	})
+
+	t.Run("DecodeInvalidJSON", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DecodeControl(`{`)
+		require.ErrorContains(t, err, "error decoding notification payload")
+	})
+}
+
+func TestInsert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := EncodeInsert(&InsertPayload{Queue: "default"})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"queue":"default","v":1}`, payload)
+
+		decoded, err := DecodeInsert(payload)
+		require.NoError(t, err)
+		require.Equal(t, &InsertPayload{Queue: "default", Version: 1}, decoded)
+	})
+
+	t.Run("DecodeUnversioned", func(t *testing.T) {
+		t.Parallel()
+
+		decoded, err := DecodeInsert(`{"queue": "default"}`)
+		require.NoError(t, err)
+		require.Equal(t, &InsertPayload{Queue: "default", Version: 1}, decoded)
+	})
+
+	t.Run("DecodeUnsupportedVersion", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DecodeInsert(`{"queue":"default","v":2}`)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+}
+
+func TestLeadership(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := EncodeLeadership(&LeadershipPayload{
+			Action:   LeadershipActionPreempt,
+			LeaderID: "client_1",
+			Priority: 5,
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"action":"preempt","leader_id":"client_1","priority":5,"v":1}`, payload)
+
+		decoded, err := DecodeLeadership(payload)
+		require.NoError(t, err)
+		require.Equal(t, &LeadershipPayload{
+			Action:   LeadershipActionPreempt,
+			LeaderID: "client_1",
+			Priority: 5,
+			Version:  1,
+		}, decoded)
+	})
+
+	t.Run("DecodeUnversioned", func(t *testing.T) {
+		t.Parallel()
+
+		decoded, err := DecodeLeadership(`{"leader_id":"client_1","action":"resigned"}`)
+		require.NoError(t, err)
+		require.Equal(t, &LeadershipPayload{Action: LeadershipActionResigned, LeaderID: "client_1", Version: 1}, decoded)
+	})
+
+	t.Run("DecodeUnsupportedVersion", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DecodeLeadership(`{"action":"resigned","leader_id":"client_1","v":2}`)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+}
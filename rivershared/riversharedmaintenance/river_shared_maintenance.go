@@ -3,8 +3,11 @@ package riversharedmaintenance
 import (
 	"cmp"
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
+	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/circuitbreaker"
 	"github.com/riverqueue/river/rivershared/util/randutil"
@@ -113,15 +116,77 @@ func ReducedBatchSizeBreaker(batchSizes BatchSizes) *circuitbreaker.CircuitBreak
 	})
 }
 
+// IsBatchTimeout returns true if err indicates that a batch operation ran out
+// of time, either because its context's deadline was exceeded or because the
+// database cancelled it for exceeding a statement or lock timeout. Services
+// should trip their ReducedBatchSizeBreaker on these errors. Batches run in
+// their own statement, so one that's cancelled has no effect and its work is
+// picked up by the next run.
+func IsBatchTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, riverdriver.ErrStatementTimeout)
+}
+
 // QueueMaintainerServiceBase is a struct that should be embedded on all queue
 // maintainer services. Its main use is to provide a StaggerStart function that
 // should be called on service start to avoid thundering herd problems.
 type QueueMaintainerServiceBase struct {
 	baseservice.BaseService
 
+	lastRunAt              atomic.Pointer[time.Time]
+	runRecorder            RunRecorder
 	staggerStartupDisabled bool
 }
 
+// Run is the outcome of a single pass of a maintenance service's run loop.
+type Run struct {
+	// Err is the error that the run failed with, or nil if it succeeded.
+	Err error
+
+	// FinishedAt is when the run finished.
+	FinishedAt time.Time
+
+	// NumRows is the number of rows the run affected, like the number of jobs
+	// deleted by the job cleaner.
+	NumRows int
+
+	// StartedAt is when the run started.
+	StartedAt time.Time
+}
+
+// RunRecorder records runs of a maintenance service so that they can be
+// inspected from outside the process running it.
+type RunRecorder func(ctx context.Context, run *Run)
+
+// LastRunAt returns the time at which the service last completed a run, or nil
+// if it hasn't completed one since it was initialized.
+func (s *QueueMaintainerServiceBase) LastRunAt() *time.Time {
+	return s.lastRunAt.Load()
+}
+
+// SetLastRunAt records the time at which the service completed a run. Services
+// should call it at the end of each successful pass of their run loop.
+func (s *QueueMaintainerServiceBase) SetLastRunAt(lastRunAt time.Time) {
+	s.lastRunAt.Store(&lastRunAt)
+}
+
+// RecordRun records a run of the service with the recorder installed by
+// SetRunRecorder, if there is one. Services should call it at the end of each
+// pass of their run loop, whether it succeeded or not. Runs interrupted because
+// the service is stopping aren't recorded.
+func (s *QueueMaintainerServiceBase) RecordRun(ctx context.Context, run *Run) {
+	if s.runRecorder == nil || errors.Is(run.Err, context.Canceled) {
+		return
+	}
+
+	s.runRecorder(ctx, run)
+}
+
+// SetRunRecorder installs a recorder that's invoked by RecordRun. It must be
+// called before the service is started.
+func (s *QueueMaintainerServiceBase) SetRunRecorder(recorder RunRecorder) {
+	s.runRecorder = recorder
+}
+
 // StaggerStart is called when queue maintainer services start. It jitters by
 // sleeping for a short random period so services don't all perform their first
 // run at exactly the same time.
@@ -0,0 +1,265 @@
+package rivertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// ErrFaultInjected is the error returned by operations that fail due to a
+// fault injected by FaultInjectingDriver.
+var ErrFaultInjected = errors.New("rivertest: injected fault")
+
+// FaultConfig configures the faults that a FaultInjectingDriver injects. Each
+// fault is configured as a probability between 0 and 1 that any given
+// occurrence of the operation it targets will fail. A zero probability (the
+// default) never injects the fault, and a probability of 1 always does.
+type FaultConfig struct {
+	// CompletionConflictProbability is the probability that setting the
+	// final state of a batch of worked jobs fails as if it had conflicted
+	// with a concurrent transaction. The client's completer retries failed
+	// batches a small number of times before giving up, after which the jobs
+	// are left running until rescued, so high probabilities should be used
+	// with care.
+	CompletionConflictProbability float64
+
+	// ElectionLossProbability is the probability that an attempt to be
+	// elected or reelected leader fails as if another client held leadership.
+	// A failed reelection causes the current leader to lose leadership and
+	// stop its maintenance services.
+	ElectionLossProbability float64
+
+	// FetchErrorProbability is the probability that a producer's attempt to
+	// fetch available jobs fails with an error.
+	FetchErrorProbability float64
+
+	// NotificationDropProbability is the probability that a notification
+	// received by the client's listener is silently dropped, as might happen
+	// if the listener's connection were briefly lost. Clients fall back to
+	// polling, so dropped notifications should delay work, but not stop it.
+	NotificationDropProbability float64
+
+	// Rand is a source of randomness used to decide whether to inject faults.
+	// Set it to a generator with a fixed seed to make a sequence of injected
+	// faults reproducible across test runs, although nondeterminism in the
+	// order of operations in a running client means that the operations
+	// faults are injected into may still vary.
+	//
+	// Defaults to the top-level math/rand/v2 generator.
+	Rand *rand.Rand
+}
+
+func (c *FaultConfig) validate() error {
+	for name, probability := range map[string]float64{
+		"CompletionConflictProbability": c.CompletionConflictProbability,
+		"ElectionLossProbability":       c.ElectionLossProbability,
+		"FetchErrorProbability":         c.FetchErrorProbability,
+		"NotificationDropProbability":   c.NotificationDropProbability,
+	} {
+		if probability < 0 || probability > 1 {
+			return fmt.Errorf("FaultConfig.%s must be between 0 and 1", name)
+		}
+	}
+	return nil
+}
+
+// FaultInjectingDriver wraps a driver to inject failures at defined points in
+// a client's operation so that an application's behavior under partial River
+// failures can be verified in tests:
+//
+//	driver := rivertest.NewFaultInjectingDriver(riverpgxv5.New(dbPool), &rivertest.FaultConfig{
+//		CompletionConflictProbability: 0.1,
+//		FetchErrorProbability:         0.1,
+//	})
+//
+//	client, err := river.NewClient(driver, config)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Faults can be tuned while a client is running with SetConfig, so a test can
+// start a client in a healthy state, degrade it, then check that it recovers.
+//
+// Fault injection is only intended for use in tests. Operations not targeted
+// by a configured fault are passed through to the wrapped driver unchanged.
+type FaultInjectingDriver[TTx any] struct {
+	riverdriver.Driver[TTx]
+
+	config   *FaultConfig
+	configMu sync.RWMutex
+	randMu   sync.Mutex
+}
+
+// NewFaultInjectingDriver wraps the given driver so that it injects faults
+// according to config. It panics if any of config's probabilities aren't
+// between 0 and 1.
+func NewFaultInjectingDriver[TTx any](driver riverdriver.Driver[TTx], config *FaultConfig) *FaultInjectingDriver[TTx] {
+	if config == nil {
+		config = &FaultConfig{}
+	}
+	if err := config.validate(); err != nil {
+		panic(err)
+	}
+
+	return &FaultInjectingDriver[TTx]{
+		Driver: driver,
+		config: config,
+	}
+}
+
+// GetExecutor gets an executor for the driver that injects faults.
+//
+// API is not stable. DO NOT USE.
+func (d *FaultInjectingDriver[TTx]) GetExecutor() riverdriver.Executor {
+	return &faultExecutor{Executor: d.Driver.GetExecutor(), injector: d}
+}
+
+// GetListener gets a listener for the driver that injects faults.
+//
+// API is not stable. DO NOT USE.
+func (d *FaultInjectingDriver[TTx]) GetListener(params *riverdriver.GetListenenerParams) riverdriver.Listener {
+	return &faultListener{Listener: d.Driver.GetListener(params), injector: d}
+}
+
+// SetConfig replaces the driver's fault configuration, taking effect
+// immediately, including for executors and listeners that have already been
+// handed out. It panics if any of config's probabilities aren't between 0 and
+// 1.
+func (d *FaultInjectingDriver[TTx]) SetConfig(config *FaultConfig) {
+	if err := config.validate(); err != nil {
+		panic(err)
+	}
+
+	d.configMu.Lock()
+	defer d.configMu.Unlock()
+
+	d.config = config
+}
+
+// UnwrapExecutor gets an executor that injects faults from a driver
+// transaction.
+//
+// API is not stable. DO NOT USE.
+func (d *FaultInjectingDriver[TTx]) UnwrapExecutor(tx TTx) riverdriver.ExecutorTx {
+	execTx := d.Driver.UnwrapExecutor(tx)
+	return &faultExecutorTx{faultExecutor: &faultExecutor{Executor: execTx, injector: d}, tx: execTx}
+}
+
+// UnwrapTx gets a driver transaction from an executor.
+//
+// API is not stable. DO NOT USE.
+func (d *FaultInjectingDriver[TTx]) UnwrapTx(execTx riverdriver.ExecutorTx) TTx {
+	if faultTx, ok := execTx.(*faultExecutorTx); ok {
+		execTx = faultTx.tx
+	}
+	return d.Driver.UnwrapTx(execTx)
+}
+
+// Rolls the dice on whether a fault with the probability selected from the
+// current configuration by probabilityFunc should be injected.
+func (d *FaultInjectingDriver[TTx]) shouldInject(probabilityFunc func(config *FaultConfig) float64) bool {
+	d.configMu.RLock()
+	var (
+		probability = probabilityFunc(d.config)
+		randSource  = d.config.Rand
+	)
+	d.configMu.RUnlock()
+
+	switch {
+	case probability <= 0:
+		return false
+	case probability >= 1:
+		return true
+	case randSource == nil:
+		return rand.Float64() < probability
+	}
+
+	// rand.Rand isn't safe for concurrent use.
+	d.randMu.Lock()
+	defer d.randMu.Unlock()
+
+	return randSource.Float64() < probability
+}
+
+// faultInjector is implemented by FaultInjectingDriver so that its executors
+// and listeners, which aren't generic, can consult it.
+type faultInjector interface {
+	shouldInject(probabilityFunc func(config *FaultConfig) float64) bool
+}
+
+type faultExecutor struct {
+	riverdriver.Executor
+
+	injector faultInjector
+}
+
+func (e *faultExecutor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
+	execTx, err := e.Executor.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &faultExecutorTx{faultExecutor: &faultExecutor{Executor: execTx, injector: e.injector}, tx: execTx}, nil
+}
+
+func (e *faultExecutor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	if e.injector.shouldInject(func(config *FaultConfig) float64 { return config.FetchErrorProbability }) {
+		return nil, fmt.Errorf("error fetching jobs: %w", ErrFaultInjected)
+	}
+	return e.Executor.JobGetAvailable(ctx, params)
+}
+
+func (e *faultExecutor) JobSetStateIfRunningMany(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
+	if e.injector.shouldInject(func(config *FaultConfig) float64 { return config.CompletionConflictProbability }) {
+		return nil, fmt.Errorf("error setting job states due to conflict: %w", ErrFaultInjected)
+	}
+	return e.Executor.JobSetStateIfRunningMany(ctx, params)
+}
+
+// Leader election signals that another client holds leadership by returning
+// ErrNotFound, so return that to simulate a lost election.
+func (e *faultExecutor) LeaderAttemptElect(ctx context.Context, params *riverdriver.LeaderElectParams) (*riverdriver.Leader, error) {
+	if e.injector.shouldInject(func(config *FaultConfig) float64 { return config.ElectionLossProbability }) {
+		return nil, rivertype.ErrNotFound
+	}
+	return e.Executor.LeaderAttemptElect(ctx, params)
+}
+
+func (e *faultExecutor) LeaderAttemptReelect(ctx context.Context, params *riverdriver.LeaderReelectParams) (*riverdriver.Leader, error) {
+	if e.injector.shouldInject(func(config *FaultConfig) float64 { return config.ElectionLossProbability }) {
+		return nil, rivertype.ErrNotFound
+	}
+	return e.Executor.LeaderAttemptReelect(ctx, params)
+}
+
+type faultExecutorTx struct {
+	*faultExecutor
+
+	tx riverdriver.ExecutorTx
+}
+
+func (e *faultExecutorTx) Commit(ctx context.Context) error   { return e.tx.Commit(ctx) }
+func (e *faultExecutorTx) Rollback(ctx context.Context) error { return e.tx.Rollback(ctx) }
+
+type faultListener struct {
+	riverdriver.Listener
+
+	injector faultInjector
+}
+
+func (l *faultListener) WaitForNotification(ctx context.Context) (*riverdriver.Notification, error) {
+	for {
+		notification, err := l.Listener.WaitForNotification(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !l.injector.shouldInject(func(config *FaultConfig) float64 { return config.NotificationDropProbability }) {
+			return notification, nil
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package rivertest
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestFaultInjectingDriver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		schema string
+	}
+
+	setup := func(t *testing.T, config *FaultConfig) (*FaultInjectingDriver[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+
+		return NewFaultInjectingDriver(driver, config), &testBundle{
+			schema: schema,
+		}
+	}
+
+	t.Run("PanicsOnInvalidProbability", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithError(t, "FaultConfig.FetchErrorProbability must be between 0 and 1", func() {
+			NewFaultInjectingDriver(riverpgxv5.New(nil), &FaultConfig{FetchErrorProbability: 1.5})
+		})
+	})
+
+	t.Run("PassesThroughWithoutFaults", func(t *testing.T) {
+		t.Parallel()
+
+		driver, bundle := setup(t, nil)
+
+		jobs, err := driver.GetExecutor().JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+			ClientID:       "client-id",
+			MaxAttemptedBy: 100,
+			MaxToLock:      10,
+			Queue:          river.QueueDefault,
+			Schema:         bundle.schema,
+		})
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("InjectsExecutorFaults", func(t *testing.T) {
+		t.Parallel()
+
+		driver, bundle := setup(t, &FaultConfig{
+			CompletionConflictProbability: 1,
+			ElectionLossProbability:       1,
+			FetchErrorProbability:         1,
+		})
+
+		exec := driver.GetExecutor()
+
+		_, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{Queue: river.QueueDefault, Schema: bundle.schema})
+		require.ErrorIs(t, err, ErrFaultInjected)
+
+		_, err = exec.JobSetStateIfRunningMany(ctx, &riverdriver.JobSetStateIfRunningManyParams{Schema: bundle.schema})
+		require.ErrorIs(t, err, ErrFaultInjected)
+
+		_, err = exec.LeaderAttemptElect(ctx, &riverdriver.LeaderElectParams{Schema: bundle.schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		_, err = exec.LeaderAttemptReelect(ctx, &riverdriver.LeaderReelectParams{Schema: bundle.schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		// Faults are also injected in transactions, which can be unwrapped
+		// back to the underlying driver's transaction type.
+		execTx, err := exec.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, execTx.Rollback(ctx)) })
+
+		_, err = execTx.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{Queue: river.QueueDefault, Schema: bundle.schema})
+		require.ErrorIs(t, err, ErrFaultInjected)
+
+		require.NotNil(t, driver.UnwrapTx(execTx))
+	})
+
+	t.Run("SetConfig", func(t *testing.T) {
+		t.Parallel()
+
+		driver, bundle := setup(t, &FaultConfig{FetchErrorProbability: 1})
+
+		exec := driver.GetExecutor()
+
+		_, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{Queue: river.QueueDefault, Schema: bundle.schema})
+		require.ErrorIs(t, err, ErrFaultInjected)
+
+		driver.SetConfig(&FaultConfig{})
+
+		_, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+			ClientID:       "client-id",
+			MaxAttemptedBy: 100,
+			MaxToLock:      10,
+			Queue:          river.QueueDefault,
+			Schema:         bundle.schema,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("DropsNotifications", func(t *testing.T) {
+		t.Parallel()
+
+		driver, bundle := setup(t, &FaultConfig{NotificationDropProbability: 1})
+
+		listener := driver.GetListener(&riverdriver.GetListenenerParams{Schema: bundle.schema})
+		require.NoError(t, listener.Connect(ctx))
+		t.Cleanup(func() { require.NoError(t, listener.Close(ctx)) })
+
+		require.NoError(t, listener.Listen(ctx, "topic1"))
+
+		require.NoError(t, driver.GetExecutor().NotifyMany(ctx, &riverdriver.NotifyManyParams{Topic: "topic1", Payload: []string{"payload1"}, Schema: bundle.schema}))
+
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		notification, err := listener.WaitForNotification(waitCtx)
+		require.ErrorIs(t, err, context.DeadlineExceeded, "Expected no notification, but got: %+v", notification)
+	})
+
+	t.Run("ClientWorksJobsDespiteFaults", func(t *testing.T) {
+		t.Parallel()
+
+		driver, bundle := setup(t, &FaultConfig{
+			FetchErrorProbability:       0.5,
+			NotificationDropProbability: 0.5,
+			Rand:                        rand.New(rand.NewPCG(1, 2)), //nolint:gosec
+		})
+
+		workers := river.NewWorkers()
+		river.AddWorker(workers, river.WorkFunc(func(ctx context.Context, job *river.Job[Job1Args]) error {
+			return nil
+		}))
+
+		client, err := river.NewClient(driver, &river.Config{
+			FetchCooldown:     10 * time.Millisecond,
+			FetchPollInterval: 50 * time.Millisecond,
+			Logger:            riversharedtest.LoggerWarn(t),
+			Queues:            map[string]river.QueueConfig{river.QueueDefault: {MaxWorkers: 10}},
+			Schema:            bundle.schema,
+			TestOnly:          true,
+			Workers:           workers,
+		})
+		require.NoError(t, err)
+
+		subscribeChan, subscribeCancel := client.Subscribe(river.EventKindJobCompleted)
+		t.Cleanup(subscribeCancel)
+
+		require.NoError(t, client.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, client.Stop(ctx)) })
+
+		const numJobs = 10
+
+		_, err = client.InsertMany(ctx, func() []river.InsertManyParams {
+			params := make([]river.InsertManyParams, numJobs)
+			for i := range params {
+				params[i] = river.InsertManyParams{Args: Job1Args{String: "foo"}}
+			}
+			return params
+		}())
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeoutN(t, subscribeChan, numJobs)
+	})
+}
@@ -0,0 +1,854 @@
+package rivertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// ErrNoCannedResponse is returned by a RecordingExecutor call when no canned
+// response was queued for it with Respond, and there's no underlying executor
+// to delegate to.
+var ErrNoCannedResponse = errors.New("rivertest: no canned response queued for call and no underlying executor to delegate to")
+
+// RecordedCall is a single call made to a RecordingExecutor.
+type RecordedCall struct {
+	// Err is the error returned by the call, if any.
+	Err error
+
+	// Method is the name of the executor method that was called, like
+	// "JobGetAvailable".
+	Method string
+
+	// Params are the parameters the method was called with. For most
+	// methods, this is a pointer to the method's params struct like
+	// *riverdriver.JobGetAvailableParams. For Exec and QueryRow it's a
+	// *SQLParams, for PGAdvisoryXactLock it's the int64 lock key, for
+	// PGTransactionTimeoutsSet it's a *riverdriver.PGTransactionTimeouts, and
+	// for Begin, Commit, PGTransactionTimeoutsGet, Ping, and Rollback it's
+	// nil.
+	Params any
+
+	// Result is the non-error value returned by the call. It's nil for
+	// methods that only return an error.
+	Result any
+}
+
+// SQLParams are the parameters of a raw SQL call to an executor like Exec or
+// QueryRow.
+type SQLParams struct {
+	Args []any
+	SQL  string
+}
+
+// RecordingDriver wraps a driver so that every call made to its executors is
+// recorded, and so that executor calls can be answered with canned responses
+// instead of a database. It lets code that takes a riverdriver.Executor or a
+// client be unit tested while asserting on its exact driver interactions:
+//
+//	driver := rivertest.NewRecordingDriver(riverpgxv5.New(nil))
+//	driver.Executor().Respond("JobInsertFastMany", []*riverdriver.JobInsertFastResult{
+//		{Job: &rivertype.JobRow{ID: 123}},
+//	}, nil)
+//	driver.Executor().Respond("NotifyMany", nil, nil)
+//
+//	client, err := river.NewClient(driver, &river.Config{})
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	var tx pgx.Tx // nil transaction; no database is needed
+//	if _, err := client.InsertTx(ctx, tx, SortArgs{}, nil); err != nil {
+//		// handle error
+//	}
+//
+//	calls := driver.Executor().CallsForMethod("JobInsertFastMany")
+//
+// If the wrapped driver has a database pool, calls without a canned response
+// are delegated to it. Otherwise they return ErrNoCannedResponse.
+//
+// All executors handed out by the driver, including transactions, share a
+// single RecordingExecutor's recorded calls and canned responses.
+type RecordingDriver[TTx any] struct {
+	riverdriver.Driver[TTx]
+
+	exec *RecordingExecutor
+}
+
+// NewRecordingDriver wraps the given driver so that its executor calls are
+// recorded.
+func NewRecordingDriver[TTx any](driver riverdriver.Driver[TTx]) *RecordingDriver[TTx] {
+	var exec riverdriver.Executor
+	if driver.PoolIsSet() {
+		exec = driver.GetExecutor()
+	}
+
+	return &RecordingDriver[TTx]{
+		Driver: driver,
+		exec:   NewRecordingExecutor(exec),
+	}
+}
+
+// Executor returns the driver's RecordingExecutor, which can be used to queue
+// canned responses and inspect recorded calls.
+func (d *RecordingDriver[TTx]) Executor() *RecordingExecutor { return d.exec }
+
+// GetExecutor gets the driver's recording executor.
+//
+// API is not stable. DO NOT USE.
+func (d *RecordingDriver[TTx]) GetExecutor() riverdriver.Executor { return d.exec }
+
+// UnwrapExecutor gets a recording executor from a driver transaction. A nil
+// transaction may be unwrapped, in which case calls must be answered by
+// canned responses.
+//
+// API is not stable. DO NOT USE.
+func (d *RecordingDriver[TTx]) UnwrapExecutor(tx TTx) riverdriver.ExecutorTx {
+	var execTx riverdriver.ExecutorTx
+	if any(tx) != nil {
+		execTx = d.Driver.UnwrapExecutor(tx)
+	}
+	return d.exec.newTx(execTx)
+}
+
+// UnwrapTx gets a driver transaction from an executor.
+//
+// API is not stable. DO NOT USE.
+func (d *RecordingDriver[TTx]) UnwrapTx(execTx riverdriver.ExecutorTx) TTx {
+	if recordingTx, ok := execTx.(*RecordingExecutorTx); ok {
+		if recordingTx.tx == nil {
+			var tx TTx
+			return tx
+		}
+		execTx = recordingTx.tx
+	}
+	return d.Driver.UnwrapTx(execTx)
+}
+
+// RecordingExecutor is a riverdriver.Executor that records every call made to
+// it, and which can answer calls with canned responses queued with Respond.
+// Calls without a canned response are delegated to an underlying executor if
+// there is one, and return ErrNoCannedResponse otherwise.
+//
+// It's safe for concurrent use.
+type RecordingExecutor struct {
+	exec riverdriver.Executor // may be nil
+	log  *recordingLog
+}
+
+// NewRecordingExecutor returns a RecordingExecutor that delegates calls
+// without a canned response to exec. exec may be nil, in which case every
+// call must be answered by a canned response.
+func NewRecordingExecutor(exec riverdriver.Executor) *RecordingExecutor {
+	return &RecordingExecutor{
+		exec: exec,
+		log: &recordingLog{
+			responses: make(map[string][]*cannedResponse),
+		},
+	}
+}
+
+// Calls returns every call recorded so far, in the order they were made.
+func (e *RecordingExecutor) Calls() []*RecordedCall {
+	e.log.mu.Lock()
+	defer e.log.mu.Unlock()
+
+	calls := make([]*RecordedCall, len(e.log.calls))
+	copy(calls, e.log.calls)
+	return calls
+}
+
+// CallsForMethod returns every call to the given executor method recorded so
+// far, in the order they were made.
+func (e *RecordingExecutor) CallsForMethod(method string) []*RecordedCall {
+	e.log.mu.Lock()
+	defer e.log.mu.Unlock()
+
+	var calls []*RecordedCall
+	for _, call := range e.log.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears all recorded calls and any canned responses that haven't yet
+// been used.
+func (e *RecordingExecutor) Reset() {
+	e.log.mu.Lock()
+	defer e.log.mu.Unlock()
+
+	e.log.calls = nil
+	e.log.responses = make(map[string][]*cannedResponse)
+}
+
+// Respond queues a canned response for the next call to the given executor
+// method, which returns result and err instead of being delegated. Multiple
+// responses for the same method are used in the order they were queued, and
+// each is used only once.
+//
+// result must be assignable to the method's non-error return type (e.g.
+// []*rivertype.JobRow for JobGetAvailable), or nil to return the type's zero
+// value. It's ignored for methods that only return an error, and for Begin,
+// which always starts a transaction that isn't backed by a database when
+// answered by a canned response without an error.
+func (e *RecordingExecutor) Respond(method string, result any, err error) {
+	e.log.mu.Lock()
+	defer e.log.mu.Unlock()
+
+	e.log.responses[method] = append(e.log.responses[method], &cannedResponse{err: err, result: result})
+}
+
+func (e *RecordingExecutor) newTx(execTx riverdriver.ExecutorTx) *RecordingExecutorTx {
+	recordingTx := &RecordingExecutorTx{
+		RecordingExecutor: &RecordingExecutor{log: e.log},
+		tx:                execTx,
+	}
+
+	// Careful to only set an executor if there's a transaction so that the
+	// executor's nil checks work as expected.
+	if execTx != nil {
+		recordingTx.exec = execTx
+	}
+
+	return recordingTx
+}
+
+func (e *RecordingExecutor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
+	var (
+		execTx riverdriver.ExecutorTx
+		err    error
+	)
+	if response, ok := e.log.popResponse("Begin"); ok {
+		err = response.err
+	} else if e.exec != nil {
+		execTx, err = e.exec.Begin(ctx)
+	}
+
+	var recordingTx *RecordingExecutorTx
+	if err == nil {
+		recordingTx = e.newTx(execTx)
+	}
+
+	e.log.record(&RecordedCall{Err: err, Method: "Begin", Result: recordingTx})
+
+	if err != nil {
+		return nil, err
+	}
+	return recordingTx, nil
+}
+
+func (e *RecordingExecutor) ColumnExists(ctx context.Context, params *riverdriver.ColumnExistsParams) (bool, error) {
+	return recordCall(e, "ColumnExists", params, func() (bool, error) {
+		return e.exec.ColumnExists(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) Exec(ctx context.Context, sql string, args ...any) error {
+	return recordCallNoResult(e, "Exec", &SQLParams{Args: args, SQL: sql}, func() error {
+		return e.exec.Exec(ctx, sql, args...)
+	})
+}
+
+func (e *RecordingExecutor) IndexDropIfExists(ctx context.Context, params *riverdriver.IndexDropIfExistsParams) error {
+	return recordCallNoResult(e, "IndexDropIfExists", params, func() error {
+		return e.exec.IndexDropIfExists(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) IndexExists(ctx context.Context, params *riverdriver.IndexExistsParams) (bool, error) {
+	return recordCall(e, "IndexExists", params, func() (bool, error) {
+		return e.exec.IndexExists(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) IndexesExist(ctx context.Context, params *riverdriver.IndexesExistParams) (map[string]bool, error) {
+	return recordCall(e, "IndexesExist", params, func() (map[string]bool, error) {
+		return e.exec.IndexesExist(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) IndexReindex(ctx context.Context, params *riverdriver.IndexReindexParams) error {
+	return recordCallNoResult(e, "IndexReindex", params, func() error {
+		return e.exec.IndexReindex(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobActivateMany(ctx context.Context, params *riverdriver.JobActivateManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobActivateMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobActivateMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCancel(ctx context.Context, params *riverdriver.JobCancelParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobCancel", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobCancel(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobChangeQueueMany(ctx context.Context, params *riverdriver.JobChangeQueueManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobChangeQueueMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobChangeQueueMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountByAllStates(ctx context.Context, params *riverdriver.JobCountByAllStatesParams) (map[rivertype.JobState]int, error) {
+	return recordCall(e, "JobCountByAllStates", params, func() (map[rivertype.JobState]int, error) {
+		return e.exec.JobCountByAllStates(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountByQueueAndState(ctx context.Context, params *riverdriver.JobCountByQueueAndStateParams) ([]*riverdriver.JobCountByQueueAndStateResult, error) {
+	return recordCall(e, "JobCountByQueueAndState", params, func() ([]*riverdriver.JobCountByQueueAndStateResult, error) {
+		return e.exec.JobCountByQueueAndState(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountByState(ctx context.Context, params *riverdriver.JobCountByStateParams) (int, error) {
+	return recordCall(e, "JobCountByState", params, func() (int, error) {
+		return e.exec.JobCountByState(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountMatching(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	return recordCall(e, "JobCountMatching", params, func() (int, error) {
+		return e.exec.JobCountMatching(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountMatchingEstimate(ctx context.Context, params *riverdriver.JobCountMatchingParams) (int, error) {
+	return recordCall(e, "JobCountMatchingEstimate", params, func() (int, error) {
+		return e.exec.JobCountMatchingEstimate(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobCountRunningByClient(ctx context.Context, params *riverdriver.JobCountRunningByClientParams) ([]*riverdriver.JobCountRunningByClientResult, error) {
+	return recordCall(e, "JobCountRunningByClient", params, func() ([]*riverdriver.JobCountRunningByClientResult, error) {
+		return e.exec.JobCountRunningByClient(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobDelete(ctx context.Context, params *riverdriver.JobDeleteParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobDelete", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobDelete(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobDeleteBeforeParams) (int, error) {
+	return recordCall(e, "JobDeleteBefore", params, func() (int, error) {
+		return e.exec.JobDeleteBefore(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobDeleteMany(ctx context.Context, params *riverdriver.JobDeleteManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobDeleteMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobDeleteMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetArgsEncryptionKeyStale(ctx context.Context, params *riverdriver.JobGetArgsEncryptionKeyStaleParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetArgsEncryptionKeyStale", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetArgsEncryptionKeyStale(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetAvailable", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetAvailable(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetByExternalID(ctx context.Context, params *riverdriver.JobGetByExternalIDParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetByExternalID", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobGetByExternalID(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetByID(ctx context.Context, params *riverdriver.JobGetByIDParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetByID", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobGetByID(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetByIDMany(ctx context.Context, params *riverdriver.JobGetByIDManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetByIDMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetByIDMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetByKindMany(ctx context.Context, params *riverdriver.JobGetByKindManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetByKindMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetByKindMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetLeaseExpired(ctx context.Context, params *riverdriver.JobGetLeaseExpiredParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetLeaseExpired", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetLeaseExpired(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobGetStuck", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobGetStuck(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobInsertFastMany(ctx context.Context, params *riverdriver.JobInsertFastManyParams) ([]*riverdriver.JobInsertFastResult, error) {
+	return recordCall(e, "JobInsertFastMany", params, func() ([]*riverdriver.JobInsertFastResult, error) {
+		return e.exec.JobInsertFastMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobInsertFastManyCopyFrom(ctx context.Context, params *riverdriver.JobInsertFastManyParams) ([]*riverdriver.JobInsertFastResult, error) {
+	return recordCall(e, "JobInsertFastManyCopyFrom", params, func() ([]*riverdriver.JobInsertFastResult, error) {
+		return e.exec.JobInsertFastManyCopyFrom(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobInsertFastManyNoReturning(ctx context.Context, params *riverdriver.JobInsertFastManyParams) (int, error) {
+	return recordCall(e, "JobInsertFastManyNoReturning", params, func() (int, error) {
+		return e.exec.JobInsertFastManyNoReturning(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobInsertFull(ctx context.Context, params *riverdriver.JobInsertFullParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobInsertFull", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobInsertFull(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobInsertFullMany(ctx context.Context, params *riverdriver.JobInsertFullManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobInsertFullMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobInsertFullMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindList(ctx context.Context, params *riverdriver.JobKindListParams) ([]string, error) {
+	return recordCall(e, "JobKindList", params, func() ([]string, error) {
+		return e.exec.JobKindList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindPause(ctx context.Context, params *riverdriver.JobKindPauseParams) error {
+	return recordCallNoResult(e, "JobKindPause", params, func() error {
+		return e.exec.JobKindPause(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindRegistrationList(ctx context.Context, params *riverdriver.JobKindRegistrationListParams) ([]*riverdriver.JobKindRegistration, error) {
+	return recordCall(e, "JobKindRegistrationList", params, func() ([]*riverdriver.JobKindRegistration, error) {
+		return e.exec.JobKindRegistrationList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindRegistrationUpsertMany(ctx context.Context, params *riverdriver.JobKindRegistrationUpsertManyParams) error {
+	return recordCallNoResult(e, "JobKindRegistrationUpsertMany", params, func() error {
+		return e.exec.JobKindRegistrationUpsertMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindResume(ctx context.Context, params *riverdriver.JobKindResumeParams) error {
+	return recordCallNoResult(e, "JobKindResume", params, func() error {
+		return e.exec.JobKindResume(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobKindStorageUsage(ctx context.Context, params *riverdriver.JobKindStorageUsageParams) ([]*riverdriver.JobKindStorageUsageResult, error) {
+	return recordCall(e, "JobKindStorageUsage", params, func() ([]*riverdriver.JobKindStorageUsageResult, error) {
+		return e.exec.JobKindStorageUsage(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobLeaseRenewMany(ctx context.Context, params *riverdriver.JobLeaseRenewManyParams) error {
+	return recordCallNoResult(e, "JobLeaseRenewMany", params, func() error {
+		return e.exec.JobLeaseRenewMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobList", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobRescueMany(ctx context.Context, params *riverdriver.JobRescueManyParams) (*struct{}, error) {
+	return recordCall(e, "JobRescueMany", params, func() (*struct{}, error) { return e.exec.JobRescueMany(ctx, params) })
+}
+
+func (e *RecordingExecutor) JobRetry(ctx context.Context, params *riverdriver.JobRetryParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobRetry", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobRetry(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobSchedule(ctx context.Context, params *riverdriver.JobScheduleParams) ([]*riverdriver.JobScheduleResult, error) {
+	return recordCall(e, "JobSchedule", params, func() ([]*riverdriver.JobScheduleResult, error) {
+		return e.exec.JobSchedule(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobSetStateIfRunningMany(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
+	return recordCall(e, "JobSetStateIfRunningMany", params, func() ([]*rivertype.JobRow, error) {
+		return e.exec.JobSetStateIfRunningMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobUpdate", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobUpdate(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobUpdateArgsEncryptionKey(ctx context.Context, params *riverdriver.JobUpdateArgsEncryptionKeyParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobUpdateArgsEncryptionKey", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobUpdateArgsEncryptionKey(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) JobUpdateFull(ctx context.Context, params *riverdriver.JobUpdateFullParams) (*rivertype.JobRow, error) {
+	return recordCall(e, "JobUpdateFull", params, func() (*rivertype.JobRow, error) {
+		return e.exec.JobUpdateFull(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderAttemptElect(ctx context.Context, params *riverdriver.LeaderElectParams) (*riverdriver.Leader, error) {
+	return recordCall(e, "LeaderAttemptElect", params, func() (*riverdriver.Leader, error) {
+		return e.exec.LeaderAttemptElect(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderAttemptReelect(ctx context.Context, params *riverdriver.LeaderReelectParams) (*riverdriver.Leader, error) {
+	return recordCall(e, "LeaderAttemptReelect", params, func() (*riverdriver.Leader, error) {
+		return e.exec.LeaderAttemptReelect(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderDeleteExpired(ctx context.Context, params *riverdriver.LeaderDeleteExpiredParams) (int, error) {
+	return recordCall(e, "LeaderDeleteExpired", params, func() (int, error) {
+		return e.exec.LeaderDeleteExpired(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderGetElectedLeader(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error) {
+	return recordCall(e, "LeaderGetElectedLeader", params, func() (*riverdriver.Leader, error) {
+		return e.exec.LeaderGetElectedLeader(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderInsert(ctx context.Context, params *riverdriver.LeaderInsertParams) (*riverdriver.Leader, error) {
+	return recordCall(e, "LeaderInsert", params, func() (*riverdriver.Leader, error) {
+		return e.exec.LeaderInsert(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) LeaderResign(ctx context.Context, params *riverdriver.LeaderResignParams) (bool, error) {
+	return recordCall(e, "LeaderResign", params, func() (bool, error) {
+		return e.exec.LeaderResign(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MaintenanceStatusList(ctx context.Context, params *riverdriver.MaintenanceStatusListParams) ([]*riverdriver.MaintenanceStatus, error) {
+	return recordCall(e, "MaintenanceStatusList", params, func() ([]*riverdriver.MaintenanceStatus, error) {
+		return e.exec.MaintenanceStatusList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MaintenanceStatusRecordRun(ctx context.Context, params *riverdriver.MaintenanceStatusRecordRunParams) (*riverdriver.MaintenanceStatus, error) {
+	return recordCall(e, "MaintenanceStatusRecordRun", params, func() (*riverdriver.MaintenanceStatus, error) {
+		return e.exec.MaintenanceStatusRecordRun(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationDeleteAssumingMainMany(ctx context.Context, params *riverdriver.MigrationDeleteAssumingMainManyParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationDeleteAssumingMainMany", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationDeleteAssumingMainMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationDeleteByLineAndVersionMany(ctx context.Context, params *riverdriver.MigrationDeleteByLineAndVersionManyParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationDeleteByLineAndVersionMany", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationDeleteByLineAndVersionMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationGetAllAssumingMain(ctx context.Context, params *riverdriver.MigrationGetAllAssumingMainParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationGetAllAssumingMain", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationGetAllAssumingMain(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationGetByLine(ctx context.Context, params *riverdriver.MigrationGetByLineParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationGetByLine", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationGetByLine(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationInsertMany(ctx context.Context, params *riverdriver.MigrationInsertManyParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationInsertMany", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationInsertMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) MigrationInsertManyAssumingMain(ctx context.Context, params *riverdriver.MigrationInsertManyAssumingMainParams) ([]*riverdriver.Migration, error) {
+	return recordCall(e, "MigrationInsertManyAssumingMain", params, func() ([]*riverdriver.Migration, error) {
+		return e.exec.MigrationInsertManyAssumingMain(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) NotificationDeleteBefore(ctx context.Context, params *riverdriver.NotificationDeleteBeforeParams) (int, error) {
+	return recordCall(e, "NotificationDeleteBefore", params, func() (int, error) {
+		return e.exec.NotificationDeleteBefore(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) NotifyMany(ctx context.Context, params *riverdriver.NotifyManyParams) error {
+	return recordCallNoResult(e, "NotifyMany", params, func() error {
+		return e.exec.NotifyMany(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}, error) {
+	return recordCall(e, "PGAdvisoryXactLock", key, func() (*struct{}, error) { return e.exec.PGAdvisoryXactLock(ctx, key) })
+}
+
+func (e *RecordingExecutor) PGTransactionTimeoutsGet(ctx context.Context) (*riverdriver.PGTransactionTimeouts, error) {
+	return recordCall(e, "PGTransactionTimeoutsGet", nil, func() (*riverdriver.PGTransactionTimeouts, error) {
+		return e.exec.PGTransactionTimeoutsGet(ctx)
+	})
+}
+
+func (e *RecordingExecutor) PGTransactionTimeoutsSet(ctx context.Context, timeouts *riverdriver.PGTransactionTimeouts) error {
+	return recordCallNoResult(e, "PGTransactionTimeoutsSet", timeouts, func() error {
+		return e.exec.PGTransactionTimeoutsSet(ctx, timeouts)
+	})
+}
+
+func (e *RecordingExecutor) Ping(ctx context.Context) error {
+	return recordCallNoResult(e, "Ping", nil, func() error { return e.exec.Ping(ctx) })
+}
+
+func (e *RecordingExecutor) QueueCreateOrSetUpdatedAt(ctx context.Context, params *riverdriver.QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error) {
+	return recordCall(e, "QueueCreateOrSetUpdatedAt", params, func() (*rivertype.Queue, error) {
+		return e.exec.QueueCreateOrSetUpdatedAt(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueDeleteExpired(ctx context.Context, params *riverdriver.QueueDeleteExpiredParams) ([]string, error) {
+	return recordCall(e, "QueueDeleteExpired", params, func() ([]string, error) {
+		return e.exec.QueueDeleteExpired(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueGet(ctx context.Context, params *riverdriver.QueueGetParams) (*rivertype.Queue, error) {
+	return recordCall(e, "QueueGet", params, func() (*rivertype.Queue, error) {
+		return e.exec.QueueGet(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueList(ctx context.Context, params *riverdriver.QueueListParams) ([]*rivertype.Queue, error) {
+	return recordCall(e, "QueueList", params, func() ([]*rivertype.Queue, error) {
+		return e.exec.QueueList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueNameList(ctx context.Context, params *riverdriver.QueueNameListParams) ([]string, error) {
+	return recordCall(e, "QueueNameList", params, func() ([]string, error) {
+		return e.exec.QueueNameList(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueuePause(ctx context.Context, params *riverdriver.QueuePauseParams) error {
+	return recordCallNoResult(e, "QueuePause", params, func() error {
+		return e.exec.QueuePause(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueResume(ctx context.Context, params *riverdriver.QueueResumeParams) error {
+	return recordCallNoResult(e, "QueueResume", params, func() error {
+		return e.exec.QueueResume(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) QueueUpdate(ctx context.Context, params *riverdriver.QueueUpdateParams) (*rivertype.Queue, error) {
+	return recordCall(e, "QueueUpdate", params, func() (*rivertype.Queue, error) {
+		return e.exec.QueueUpdate(ctx, params)
+	})
+}
+
+// QueryRow is recorded like other calls, but because it doesn't return an
+// error, a canned response's error or ErrNoCannedResponse is instead returned
+// from the returned row's Scan.
+func (e *RecordingExecutor) QueryRow(ctx context.Context, sql string, args ...any) riverdriver.Row {
+	row, err := recordCall(e, "QueryRow", &SQLParams{Args: args, SQL: sql}, func() (riverdriver.Row, error) {
+		return e.exec.QueryRow(ctx, sql, args...), nil
+	})
+	if err != nil {
+		return &errRow{err: err}
+	}
+	return row
+}
+
+func (e *RecordingExecutor) RateLimitReserve(ctx context.Context, params *riverdriver.RateLimitReserveParams) (float64, error) {
+	return recordCall(e, "RateLimitReserve", params, func() (float64, error) {
+		return e.exec.RateLimitReserve(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) SchemaCreate(ctx context.Context, params *riverdriver.SchemaCreateParams) error {
+	return recordCallNoResult(e, "SchemaCreate", params, func() error {
+		return e.exec.SchemaCreate(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) SchemaDrop(ctx context.Context, params *riverdriver.SchemaDropParams) error {
+	return recordCallNoResult(e, "SchemaDrop", params, func() error {
+		return e.exec.SchemaDrop(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) SchemaGetExpired(ctx context.Context, params *riverdriver.SchemaGetExpiredParams) ([]string, error) {
+	return recordCall(e, "SchemaGetExpired", params, func() ([]string, error) {
+		return e.exec.SchemaGetExpired(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) TableExists(ctx context.Context, params *riverdriver.TableExistsParams) (bool, error) {
+	return recordCall(e, "TableExists", params, func() (bool, error) {
+		return e.exec.TableExists(ctx, params)
+	})
+}
+
+func (e *RecordingExecutor) TableTruncate(ctx context.Context, params *riverdriver.TableTruncateParams) error {
+	return recordCallNoResult(e, "TableTruncate", params, func() error {
+		return e.exec.TableTruncate(ctx, params)
+	})
+}
+
+// RecordingExecutorTx is a transaction started from a RecordingExecutor. It
+// records calls to the same log as the executor it was started from.
+type RecordingExecutorTx struct {
+	*RecordingExecutor
+
+	tx riverdriver.ExecutorTx // may be nil
+}
+
+func (e *RecordingExecutorTx) Commit(ctx context.Context) error {
+	return e.end("Commit", func() error { return e.tx.Commit(ctx) })
+}
+
+func (e *RecordingExecutorTx) Rollback(ctx context.Context) error {
+	return e.end("Rollback", func() error { return e.tx.Rollback(ctx) })
+}
+
+// Ends the transaction by commit or rollback. Unlike other calls, ending a
+// transaction that isn't backed by a database succeeds without a canned
+// response so that transactional code can be exercised without one.
+func (e *RecordingExecutorTx) end(method string, endFunc func() error) error {
+	var err error
+	if response, ok := e.log.popResponse(method); ok {
+		err = response.err
+	} else if e.tx != nil {
+		err = endFunc()
+	}
+
+	e.log.record(&RecordedCall{Err: err, Method: method})
+
+	return err
+}
+
+// errRow is a riverdriver.Row that returns an error on Scan.
+type errRow struct {
+	err error
+}
+
+func (r *errRow) Scan(dest ...any) error { return r.err }
+
+type cannedResponse struct {
+	err    error
+	result any
+}
+
+// recordingLog holds the calls and canned responses shared between a
+// RecordingExecutor and any transactions started from it.
+type recordingLog struct {
+	calls     []*RecordedCall
+	mu        sync.Mutex
+	responses map[string][]*cannedResponse
+}
+
+func (l *recordingLog) popResponse(method string) (*cannedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	responses := l.responses[method]
+	if len(responses) < 1 {
+		return nil, false
+	}
+
+	l.responses[method] = responses[1:]
+	return responses[0], true
+}
+
+func (l *recordingLog) record(call *RecordedCall) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls = append(l.calls, call)
+}
+
+// Records a call to method, answering it with a canned response if one was
+// queued, delegating it with call if there's an underlying executor, or
+// returning ErrNoCannedResponse otherwise.
+func recordCall[T any](e *RecordingExecutor, method string, params any, call func() (T, error)) (T, error) {
+	var (
+		result T
+		err    error
+	)
+
+	response, ok := e.log.popResponse(method)
+	switch {
+	case ok:
+		err = response.err
+		if response.result != nil {
+			typedResult, ok := response.result.(T)
+			if !ok {
+				err = fmt.Errorf("canned response for %s has type %T, but expected %T", method, response.result, result)
+				break
+			}
+			result = typedResult
+		}
+	case e.exec == nil:
+		err = fmt.Errorf("%w: %s", ErrNoCannedResponse, method)
+	default:
+		result, err = call()
+	}
+
+	e.log.record(&RecordedCall{Err: err, Method: method, Params: params, Result: result})
+
+	return result, err
+}
+
+// Like recordCall, but for methods that only return an error.
+func recordCallNoResult(e *RecordingExecutor, method string, params any, call func() error) error {
+	var err error
+
+	response, ok := e.log.popResponse(method)
+	switch {
+	case ok:
+		err = response.err
+	case e.exec == nil:
+		err = fmt.Errorf("%w: %s", ErrNoCannedResponse, method)
+	default:
+		err = call()
+	}
+
+	e.log.record(&RecordedCall{Err: err, Method: method, Params: params})
+
+	return err
+}
@@ -0,0 +1,209 @@
+package rivertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestRecordingDriver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("DelegatesAndRecordsCalls", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = NewRecordingDriver(riverpgxv5.New(dbPool))
+			schema = riverdbtest.TestSchema(ctx, t, riverpgxv5.New(dbPool), nil)
+		)
+
+		exec := driver.GetExecutor()
+
+		_, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 123, Schema: schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		exists, err := exec.TableExists(ctx, &riverdriver.TableExistsParams{Schema: schema, Table: "river_job"})
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		calls := driver.Executor().Calls()
+		require.Len(t, calls, 2)
+		require.Equal(t, &RecordedCall{
+			Err:    rivertype.ErrNotFound,
+			Method: "JobGetByID",
+			Params: &riverdriver.JobGetByIDParams{ID: 123, Schema: schema},
+			Result: (*rivertype.JobRow)(nil),
+		}, calls[0])
+		require.Equal(t, "TableExists", calls[1].Method)
+		require.True(t, calls[1].Result.(bool)) //nolint:forcetypeassert
+	})
+
+	t.Run("TransactionCallsRecorded", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			tx     = riverdbtest.TestTxPgx(ctx, t)
+			driver = NewRecordingDriver(riverpgxv5.New(nil))
+		)
+
+		execTx := driver.UnwrapExecutor(tx)
+
+		require.NoError(t, execTx.Exec(ctx, "SELECT 1"))
+
+		require.Equal(t, tx, driver.UnwrapTx(execTx))
+
+		calls := driver.Executor().CallsForMethod("Exec")
+		require.Len(t, calls, 1)
+		require.Equal(t, &SQLParams{SQL: "SELECT 1"}, calls[0].Params)
+	})
+
+	t.Run("ClientInsertWithoutDatabase", func(t *testing.T) {
+		t.Parallel()
+
+		driver := NewRecordingDriver(riverpgxv5.New(nil))
+
+		client, err := river.NewClient(driver, &river.Config{})
+		require.NoError(t, err)
+
+		driver.Executor().Respond("JobInsertFastMany", []*riverdriver.JobInsertFastResult{
+			{Job: &rivertype.JobRow{ID: 123, Kind: (Job1Args{}).Kind()}},
+		}, nil)
+		driver.Executor().Respond("NotifyMany", nil, nil)
+
+		var tx pgx.Tx
+		insertRes, err := client.InsertTx(ctx, tx, Job1Args{String: "foo"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(123), insertRes.Job.ID)
+
+		calls := driver.Executor().CallsForMethod("JobInsertFastMany")
+		require.Len(t, calls, 1)
+
+		params, ok := calls[0].Params.(*riverdriver.JobInsertFastManyParams)
+		require.True(t, ok)
+		require.Len(t, params.Jobs, 1)
+		require.Equal(t, (Job1Args{}).Kind(), params.Jobs[0].Kind)
+		require.JSONEq(t, `{"string":"foo"}`, string(params.Jobs[0].EncodedArgs))
+	})
+}
+
+func TestRecordingExecutor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *RecordingExecutor {
+		t.Helper()
+
+		return NewRecordingExecutor(nil)
+	}
+
+	t.Run("CannedResponses", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		exec.Respond("JobGetByID", &rivertype.JobRow{ID: 1}, nil)
+		exec.Respond("JobGetByID", nil, rivertype.ErrNotFound)
+
+		job, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), job.ID)
+
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 2})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		// Responses are used up.
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 3})
+		require.ErrorIs(t, err, ErrNoCannedResponse)
+
+		require.Len(t, exec.Calls(), 3)
+		require.Len(t, exec.CallsForMethod("JobGetByID"), 3)
+		require.Empty(t, exec.CallsForMethod("JobGetAvailable"))
+	})
+
+	t.Run("CannedResponseWrongType", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		exec.Respond("JobGetByID", "not a job", nil)
+
+		_, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 1})
+		require.EqualError(t, err, "canned response for JobGetByID has type string, but expected *rivertype.JobRow")
+	})
+
+	t.Run("ErrorOnlyMethod", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		exec.Respond("QueuePause", nil, errors.New("pause error"))
+
+		require.EqualError(t, exec.QueuePause(ctx, &riverdriver.QueuePauseParams{Name: "default"}), "pause error")
+		require.Equal(t, []*RecordedCall{
+			{Err: errors.New("pause error"), Method: "QueuePause", Params: &riverdriver.QueuePauseParams{Name: "default"}},
+		}, exec.Calls())
+	})
+
+	t.Run("QueryRowErrorReturnedFromScan", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		var val int
+		require.ErrorIs(t, exec.QueryRow(ctx, "SELECT $1", 1).Scan(&val), ErrNoCannedResponse)
+		require.Equal(t, &SQLParams{Args: []any{1}, SQL: "SELECT $1"}, exec.Calls()[0].Params)
+	})
+
+	t.Run("TransactionWithoutDatabase", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		execTx, err := exec.Begin(ctx)
+		require.NoError(t, err)
+
+		exec.Respond("JobDelete", &rivertype.JobRow{ID: 1}, nil)
+
+		_, err = execTx.JobDelete(ctx, &riverdriver.JobDeleteParams{ID: 1})
+		require.NoError(t, err)
+
+		require.NoError(t, execTx.Commit(ctx))
+
+		methods := make([]string, 0, 3)
+		for _, call := range exec.Calls() {
+			methods = append(methods, call.Method)
+		}
+		require.Equal(t, []string{"Begin", "JobDelete", "Commit"}, methods)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		t.Parallel()
+
+		exec := setup(t)
+
+		exec.Respond("JobGetByID", &rivertype.JobRow{ID: 1}, nil)
+		_, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 1})
+		require.NoError(t, err)
+
+		exec.Respond("JobGetByID", &rivertype.JobRow{ID: 2}, nil)
+		exec.Reset()
+		require.Empty(t, exec.Calls())
+
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: 2})
+		require.ErrorIs(t, err, ErrNoCannedResponse)
+	})
+}
@@ -4,7 +4,6 @@ package rivertest
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"slices"
 	"strings"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/util/sliceutil"
@@ -182,7 +182,7 @@ func requireInsertedErr[TDriver riverdriver.Driver[TTx], TTx any, TArgs river.Jo
 	jobRow := jobRows[0]
 
 	var actualArgs TArgs
-	if err := json.Unmarshal(jobRow.EncodedArgs, &actualArgs); err != nil {
+	if err := argscodec.NewDecoder(nil, nil, nil).Decode(jobRow, &actualArgs); err != nil {
 		return nil, fmt.Errorf("error unmarshaling job args: %w", err)
 	}
 
@@ -290,7 +290,7 @@ func requireNotInsertedErr[TDriver riverdriver.Driver[TTx], TTx any, TArgs river
 	// been inserted, and the test succeeds.
 	for _, jobRow := range jobRows {
 		var actualArgs TArgs
-		if err := json.Unmarshal(jobRow.EncodedArgs, &actualArgs); err != nil {
+		if err := argscodec.NewDecoder(nil, nil, nil).Decode(jobRow, &actualArgs); err != nil {
 			return fmt.Errorf("error unmarshaling job args: %w", err)
 		}
 
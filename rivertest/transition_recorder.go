@@ -0,0 +1,178 @@
+package rivertest
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// The maximum amount of time RequireTransitioned waits for a job to reach the
+// expected sequence of states.
+const transitionRecorderTimeout = 5 * time.Second
+
+// TransitionRecorder records the sequence of states that each job worked by a
+// client transitions through so that tests can make assertions on a job's
+// lifecycle without having to coordinate event subscriptions themselves.
+//
+// Transitions are reconstructed from the client's job events. A job's
+// recorded sequence starts when it first becomes available to be worked, so
+// a job that was scheduled before it was worked shows as starting in the
+// available state. Jobs that are never worked by the client (e.g. a job
+// cancelled before it runs) aren't recorded.
+type TransitionRecorder struct {
+	mu          sync.Mutex
+	tb          testing.TB
+	timeout     time.Duration
+	transitions map[int64][]rivertype.JobState
+	updatedChan chan struct{} // closed and replaced every time a transition is recorded
+}
+
+// NewTransitionRecorder subscribes to job events from the given client and
+// begins recording job state transitions. The subscription is torn down when
+// the test completes.
+//
+// The recorder should be created before the client starts working jobs of
+// interest:
+//
+//	recorder := rivertest.NewTransitionRecorder(t, client)
+//
+//	if err := client.Start(ctx); err != nil {
+//		// handle error
+//	}
+//
+//	insertRes, err := client.Insert(ctx, SortArgs{}, nil)
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	recorder.RequireTransitioned(insertRes.Job.ID,
+//		rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCompleted)
+func NewTransitionRecorder[TTx any](tb testing.TB, client *river.Client[TTx]) *TransitionRecorder {
+	tb.Helper()
+
+	recorder := newTransitionRecorder(tb)
+
+	subscribeChan, subscribeCancel := client.Subscribe(
+		river.EventKindJobCancelled,
+		river.EventKindJobCompleted,
+		river.EventKindJobFailed,
+		river.EventKindJobSnoozed,
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for event := range subscribeChan {
+			recorder.record(event.Job)
+		}
+	}()
+
+	tb.Cleanup(func() {
+		subscribeCancel()
+		<-done
+	})
+
+	return recorder
+}
+
+func newTransitionRecorder(tb testing.TB) *TransitionRecorder {
+	return &TransitionRecorder{
+		tb:          tb,
+		timeout:     transitionRecorderTimeout,
+		transitions: make(map[int64][]rivertype.JobState),
+		updatedChan: make(chan struct{}),
+	}
+}
+
+// RequireTransitioned requires that the job with the given ID transitioned
+// through exactly the given sequence of states. Because jobs are worked
+// asynchronously, it waits up to a few seconds for the job to reach the end
+// of the expected sequence, but fails immediately if the recorded sequence
+// diverges from it.
+func (r *TransitionRecorder) RequireTransitioned(jobID int64, states ...rivertype.JobState) {
+	r.tb.Helper()
+	r.requireTransitioned(r.tb, jobID, states...)
+}
+
+func (r *TransitionRecorder) requireTransitioned(t testingT, jobID int64, states ...rivertype.JobState) {
+	t.Helper()
+
+	timeout := time.After(r.timeout)
+
+	for {
+		r.mu.Lock()
+		var (
+			actual      = slices.Clone(r.transitions[jobID])
+			updatedChan = r.updatedChan
+		)
+		r.mu.Unlock()
+
+		switch {
+		case slices.Equal(actual, states):
+			return
+
+		// The job has only made it part way through the expected sequence. Wait
+		// for more transitions.
+		case len(actual) < len(states) && slices.Equal(actual, states[:len(actual)]):
+
+		default:
+			failuref(t, "Job %d expected to transition through %s, but transitioned through %s",
+				jobID, formatJobStates(states), formatJobStates(actual))
+			return
+		}
+
+		select {
+		case <-timeout:
+			failuref(t, "Job %d expected to transition through %s, but only transitioned through %s after %s",
+				jobID, formatJobStates(states), formatJobStates(actual), r.timeout)
+			return
+		case <-updatedChan:
+		}
+	}
+}
+
+// Transitions returns the sequence of states recorded so far for the job with
+// the given ID, or nil if none have been recorded.
+func (r *TransitionRecorder) Transitions(jobID int64) []rivertype.JobState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.transitions[jobID])
+}
+
+// Records the transitions leading up to and including the job's state as
+// reported by a job event. Every event is the result of a work attempt, so
+// the job must have been available and then running immediately beforehand.
+func (r *TransitionRecorder) record(job *rivertype.JobRow) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := r.transitions[job.ID]
+
+	// Jobs that were retryable or scheduled must have been made available
+	// before being worked again.
+	if len(states) < 1 ||
+		states[len(states)-1] == rivertype.JobStateRetryable ||
+		states[len(states)-1] == rivertype.JobStateScheduled {
+		states = append(states, rivertype.JobStateAvailable)
+	}
+
+	r.transitions[job.ID] = append(states, rivertype.JobStateRunning, job.State)
+
+	close(r.updatedChan)
+	r.updatedChan = make(chan struct{})
+}
+
+func formatJobStates(states []rivertype.JobState) string {
+	if len(states) < 1 {
+		return "(none)"
+	}
+	return strings.Join(sliceutil.Map(states, func(s rivertype.JobState) string { return string(s) }), " → ")
+}
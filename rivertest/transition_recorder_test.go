@@ -0,0 +1,150 @@
+package rivertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestTransitionRecorder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		mockT *testutil.MockT
+	}
+
+	setup := func(t *testing.T) (*TransitionRecorder, *testBundle) {
+		t.Helper()
+
+		recorder := newTransitionRecorder(t)
+		recorder.timeout = 50 * time.Millisecond
+
+		return recorder, &testBundle{
+			mockT: testutil.NewMockT(t),
+		}
+	}
+
+	t.Run("RecordsClientJobTransitions", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+		)
+
+		workers := river.NewWorkers()
+		river.AddWorker(workers, river.WorkFunc(func(ctx context.Context, job *river.Job[Job1Args]) error {
+			return nil
+		}))
+		river.AddWorker(workers, river.WorkFunc(func(ctx context.Context, job *river.Job[Job2Args]) error {
+			return river.JobCancel(nil)
+		}))
+
+		client, err := river.NewClient(driver, &river.Config{
+			Logger:   riversharedtest.Logger(t),
+			Queues:   map[string]river.QueueConfig{river.QueueDefault: {MaxWorkers: 10}},
+			Schema:   schema,
+			TestOnly: true,
+			Workers:  workers,
+		})
+		require.NoError(t, err)
+
+		recorder := NewTransitionRecorder(t, client)
+
+		require.NoError(t, client.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, client.Stop(ctx)) })
+
+		insertRes1, err := client.Insert(ctx, Job1Args{String: "foo"}, nil)
+		require.NoError(t, err)
+		insertRes2, err := client.Insert(ctx, Job2Args{Int: 123}, nil)
+		require.NoError(t, err)
+
+		recorder.RequireTransitioned(insertRes1.Job.ID,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCompleted)
+		recorder.RequireTransitioned(insertRes2.Job.ID,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCancelled)
+	})
+
+	t.Run("InfersAvailableBeforeEachAttempt", func(t *testing.T) {
+		t.Parallel()
+
+		recorder, bundle := setup(t)
+
+		recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateRetryable})
+		recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateScheduled}) // snoozed
+		recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateAvailable}) // snoozed with zero duration
+		recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateCompleted})
+
+		recorder.requireTransitioned(bundle.mockT, 1,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateRetryable,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateScheduled,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateAvailable,
+			rivertype.JobStateRunning, rivertype.JobStateCompleted,
+		)
+		require.False(t, bundle.mockT.Failed)
+
+		require.Equal(t, []rivertype.JobState{
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateRetryable,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateScheduled,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateAvailable,
+			rivertype.JobStateRunning, rivertype.JobStateCompleted,
+		}, recorder.Transitions(1))
+		require.Nil(t, recorder.Transitions(2))
+	})
+
+	t.Run("WaitsForTransitions", func(t *testing.T) {
+		t.Parallel()
+
+		recorder, bundle := setup(t)
+		recorder.timeout = 5 * time.Second
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateCompleted})
+		}()
+
+		recorder.requireTransitioned(bundle.mockT, 1,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCompleted)
+		require.False(t, bundle.mockT.Failed)
+	})
+
+	t.Run("FailsOnDivergence", func(t *testing.T) {
+		t.Parallel()
+
+		recorder, bundle := setup(t)
+
+		recorder.record(&rivertype.JobRow{ID: 1, State: rivertype.JobStateDiscarded})
+
+		recorder.requireTransitioned(bundle.mockT, 1,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCompleted)
+		require.True(t, bundle.mockT.Failed)
+		require.Equal(t,
+			failureString("Job 1 expected to transition through available → running → completed, but transitioned through available → running → discarded")+"\n",
+			bundle.mockT.LogOutput())
+	})
+
+	t.Run("FailsOnTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		recorder, bundle := setup(t)
+
+		recorder.requireTransitioned(bundle.mockT, 1,
+			rivertype.JobStateAvailable, rivertype.JobStateRunning, rivertype.JobStateCompleted)
+		require.True(t, bundle.mockT.Failed)
+		require.Equal(t,
+			failureString("Job 1 expected to transition through available → running → completed, but only transitioned through (none) after 50ms")+"\n",
+			bundle.mockT.LogOutput())
+	})
+}
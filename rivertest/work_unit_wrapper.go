@@ -2,10 +2,10 @@ package rivertest
 
 import (
 	"context"
-	"encoding/json"
 	"time"
 
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/rivertype"
@@ -57,10 +57,17 @@ func (w *wrapperWorkUnit[T]) NextRetry() time.Time           { return w.worker.N
 func (w *wrapperWorkUnit[T]) Timeout() time.Duration         { return w.worker.Timeout(w.job) }
 func (w *wrapperWorkUnit[T]) Work(ctx context.Context) error { return w.worker.Work(ctx, w.job) }
 
-func (w *wrapperWorkUnit[T]) UnmarshalJob() error {
+func (w *wrapperWorkUnit[T]) TimeoutGracePeriod() time.Duration {
+	if workerWithGracePeriod, ok := w.worker.(river.WorkerWithTimeoutGracePeriod); ok {
+		return workerWithGracePeriod.TimeoutGracePeriod()
+	}
+	return 0
+}
+
+func (w *wrapperWorkUnit[T]) UnmarshalJob(argsDecoder *argscodec.Decoder) error {
 	w.job = &river.Job[T]{
 		JobRow: w.jobRow,
 	}
 
-	return json.Unmarshal(w.jobRow.EncodedArgs, &w.job.Args)
+	return argsDecoder.Decode(w.jobRow, &w.job.Args)
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/execution"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobcompleter"
@@ -181,6 +182,9 @@ func (w *Worker[T, TTx]) workJob(ctx context.Context, tb testing.TB, tx TTx, job
 	ctx = WorkContext(ctx, w.client)
 	// TODO: remove ContextKeyInsideTestWorker
 	ctx = context.WithValue(ctx, execution.ContextKeyInsideTestWorker{}, true)
+	if w.config.WorkContext != nil {
+		ctx = w.config.WorkContext(ctx)
+	}
 
 	// jobCancel will always be called by the executor to prevent leaks.
 	jobCtx, jobCancel := context.WithCancelCause(ctx)
@@ -189,7 +193,23 @@ func (w *Worker[T, TTx]) workJob(ctx context.Context, tb testing.TB, tx TTx, job
 
 	var resultErr error
 
+	argsCodecs := []argscodec.Codec{w.config.ArgsCodec}
+	for _, codec := range w.config.ArgsCodecsDecodeOnly {
+		argsCodecs = append(argsCodecs, codec)
+	}
+
+	var argsCompressors []argscodec.Compressor
+	if w.config.ArgsCompression != nil && w.config.ArgsCompression.Compressor != nil {
+		argsCompressors = append(argsCompressors, w.config.ArgsCompression.Compressor)
+	}
+
+	var argsEncryptor argscodec.Encryptor
+	if w.config.ArgsEncryptor != nil {
+		argsEncryptor = w.config.ArgsEncryptor
+	}
+
 	executor := baseservice.Init(archetype, &jobexecutor.JobExecutor{
+		ArgsDecoder:              argscodec.NewDecoder(argsCodecs, argsCompressors, argsEncryptor),
 		CancelFunc:               jobCancel,
 		ClientJobTimeout:         w.config.JobTimeout,
 		ClientRetryPolicy:        w.config.RetryPolicy,
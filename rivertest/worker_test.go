@@ -451,6 +451,29 @@ func TestWorker_Work(t *testing.T) {
 		require.True(t, middlewareWithBaseServiceCalled)
 	})
 
+	t.Run("WorkUsesWorkContext", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		type privateKey string
+
+		bundle.config.WorkContext = func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, privateKey("service"), "from_work_context")
+		}
+
+		var (
+			worker = river.WorkFunc(func(ctx context.Context, job *river.Job[testArgs]) error {
+				require.Equal(t, "from_work_context", ctx.Value(privateKey("service")))
+				return nil
+			})
+			testWorker = NewWorker(t, bundle.driver, bundle.config, worker)
+		)
+
+		_, err := testWorker.Work(ctx, t, bundle.tx, testArgs{Value: "test"}, nil)
+		require.NoError(t, err)
+	})
+
 	// Honors config.Schema: River lives in a named schema, worked through a
 	// transaction with an empty search_path so tables resolve only via schema
 	// qualification. Needs its own infrastructure, so it skips setup.
@@ -40,6 +40,32 @@ func (e *JobCancelError) Is(target error) bool {
 
 func (e *JobCancelError) Unwrap() error { return e.err }
 
+// JobCancelledRemotelyError is the cause of a job context's cancellation when
+// the job was cancelled remotely along with a reason. It's wrapped in a
+// JobCancelError, so it still matches ErrJobCancelledRemotely with errors.Is,
+// and the reason can be extracted from a job's context with errors.As:
+//
+//	var cancelledErr *rivertype.JobCancelledRemotelyError
+//	if errors.As(context.Cause(ctx), &cancelledErr) {
+//		fmt.Printf("job cancelled: %s\n", cancelledErr.Reason)
+//	}
+type JobCancelledRemotelyError struct {
+	// Reason is the reason given for the cancellation.
+	Reason string
+}
+
+func (e *JobCancelledRemotelyError) Error() string {
+	if e.Reason == "" {
+		return "job cancelled remotely"
+	}
+	return "job cancelled remotely: " + e.Reason
+}
+
+func (e *JobCancelledRemotelyError) Is(target error) bool {
+	_, ok := target.(*JobCancelledRemotelyError)
+	return ok
+}
+
 // JobSnoozeError is the error type returned by JobSnooze. It should not be
 // initialized directly, but is returned from the [JobSnooze] function and can
 // be used for test assertions.
@@ -57,6 +83,49 @@ func (e *JobSnoozeError) Is(target error) bool {
 	return ok
 }
 
+// JobHardTimeoutError is the error recorded for a job that didn't return
+// within its grace period after its context was cancelled for exceeding its
+// timeout (see river.WorkerWithTimeoutGracePeriod). The job is failed with it
+// and retried according to its retry policy, even though its Work function may
+// still be running.
+type JobHardTimeoutError struct {
+	// GracePeriod is the time the job was given to return after its timeout.
+	GracePeriod time.Duration
+
+	// Timeout is the job's timeout, after which its context was cancelled.
+	Timeout time.Duration
+}
+
+func (e *JobHardTimeoutError) Error() string {
+	return fmt.Sprintf("job exceeded its timeout of %s and didn't return within its grace period of %s", e.Timeout, e.GracePeriod)
+}
+
+func (e *JobHardTimeoutError) Is(target error) bool {
+	_, ok := target.(*JobHardTimeoutError)
+	return ok
+}
+
+// JobSnoozeLimitExceededError is the error recorded for a job whose worker
+// snoozed it more times than allowed by the worker's snooze limit (see
+// river.WorkerWithSnoozeLimit). The job is errored with it in place of being
+// snoozed again, and is discarded instead of retried if Discard is set.
+type JobSnoozeLimitExceededError struct {
+	// Discard is whether the job is discarded rather than retried.
+	Discard bool
+
+	// MaxSnoozes is the number of snoozes that the job was allowed.
+	MaxSnoozes int
+}
+
+func (e *JobSnoozeLimitExceededError) Error() string {
+	return fmt.Sprintf("job exceeded its snooze limit of %d", e.MaxSnoozes)
+}
+
+func (e *JobSnoozeLimitExceededError) Is(target error) bool {
+	_, ok := target.(*JobSnoozeLimitExceededError)
+	return ok
+}
+
 // UnknownJobKindError is returned when a Client fetches and attempts to
 // work a job that has not been registered on the Client's Workers bundle (using
 // AddWorker).
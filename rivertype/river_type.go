@@ -7,12 +7,61 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
+// MetadataKeyArgsCodec is the metadata key used to store the name of the codec
+// that a job's args were encoded with when it's something other than JSON. See
+// river.ArgsCodec.
+const MetadataKeyArgsCodec = "river:args_codec"
+
+// MetadataKeyArgsCompression is the metadata key used to store the name of the
+// compressor that a job's args were compressed with, if they were compressed.
+// See river.ArgsCompressionConfig.
+const MetadataKeyArgsCompression = "river:args_compression"
+
+// MetadataKeyArgsEncryptionKeyID is the metadata key used to store the ID of
+// the key that a job's args were encrypted with, if they were encrypted. See
+// river.ArgsEncryptor.
+const MetadataKeyArgsEncryptionKeyID = "river:args_encryption_key_id"
+
+// MetadataKeyArgsVersion is the metadata key used to store the version of a
+// job's args at the time it was inserted, for job args implementing
+// river.JobArgsWithVersion.
+const MetadataKeyArgsVersion = "river:args_version"
+
+// MetadataKeyAttemptInfos is the metadata key used to store information about
+// the environment in which each of a job's recent attempts were worked, which
+// is recorded when the client's Config.RecordAttemptInfo is enabled.
+const MetadataKeyAttemptInfos = "river:attempt_infos"
+
+// MetadataKeyCheckpoint is the metadata key used to store a job's most recent
+// checkpoint, persisted with river.Checkpoint.
+const MetadataKeyCheckpoint = "river:checkpoint"
+
+// MetadataKeyExternalID is the metadata key used to store a job's external
+// ID, a unique identifier for the job that's suitable for exposing outside of
+// the system in place of its sequential ID. See river.InsertOpts.ExternalID.
+const MetadataKeyExternalID = "river:external_id"
+
+// MetadataKeyPartitionKey is the metadata key used to store a job's partition
+// key. Jobs sharing a partition key are worked one at a time in the order they
+// were inserted. See river.InsertOpts.PartitionKey.
+const MetadataKeyPartitionKey = "river:partition_key"
+
+// MetadataKeyRequiredLabels is the metadata key used to store the labels a
+// client must have to work a job. See river.InsertOpts.RequiredLabels.
+const MetadataKeyRequiredLabels = "river:required_labels"
+
 // MetadataKeyOutput is the metadata key used to store recorded job output.
 const MetadataKeyOutput = "output"
 
+// MetadataKeyTruncatedKeys is the metadata key used to list the keys that were
+// removed from a job's metadata at insert because it exceeded the client's
+// configured maximum metadata size. See river.PayloadSizeLimitActionTruncate.
+const MetadataKeyTruncatedKeys = "river:truncated_keys"
+
 // ErrNotFound is returned when a query by ID does not match any existing
 // rows. For example, attempting to cancel a job that doesn't exist will
 // return this error.
@@ -36,11 +85,16 @@ type JobArgs interface {
 type JobInsertResult struct {
 	// Job is a struct containing the database persisted properties of the
 	// inserted job.
+	//
+	// If the insert was skipped because of a unique conflict (see
+	// UniqueSkippedAsDuplicate), Job is instead the full existing job that the
+	// insert conflicted with, so that it can be inspected or linked to without
+	// having to look it up separately.
 	Job *JobRow
 
 	// UniqueSkippedAsDuplicate is true if for a unique job, the insertion was
 	// skipped due to an equivalent job matching unique property already being
-	// present.
+	// present. In this case, Job is the existing job.
 	UniqueSkippedAsDuplicate bool
 }
 
@@ -156,6 +210,80 @@ func (j *JobRow) Output() []byte {
 	return metadata.Output
 }
 
+// AttemptInfos returns information about the environment in which each of the
+// job's recent attempts were worked, in the order they were worked, if the
+// clients that worked them had Config.RecordAttemptInfo enabled. Returns nil if
+// none were recorded.
+func (j *JobRow) AttemptInfos() []AttemptInfo {
+	attemptInfos, err := MetadataAs[[]AttemptInfo](j, MetadataKeyAttemptInfos)
+	if err != nil {
+		return nil
+	}
+
+	return attemptInfos
+}
+
+// Checkpoint returns the checkpoint most recently persisted for the job with
+// river.Checkpoint, if any. The return value is a raw JSON payload, or nil if
+// no checkpoint was persisted.
+func (j *JobRow) Checkpoint() []byte {
+	checkpoint, ok := j.MetadataGet(MetadataKeyCheckpoint)
+	if !ok {
+		return nil
+	}
+
+	return checkpoint
+}
+
+// MetadataGet returns the raw JSON value in the job's metadata at the path
+// given by keys, each of which descends into a nested object, and true if a
+// value was found. It returns false if any key along the path is missing or
+// the value containing it isn't an object.
+//
+//	traceID, ok := job.MetadataGet("tracing", "trace_id")
+func (j *JobRow) MetadataGet(keys ...string) (json.RawMessage, bool) {
+	value := json.RawMessage(j.Metadata)
+	for _, key := range keys {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(value, &object); err != nil {
+			return nil, false
+		}
+
+		var ok bool
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// MetadataAs decodes the value in a job's metadata at the path given by keys
+// into a new T. With no keys, the job's entire metadata is decoded. If no
+// value exists at the path, the returned error wraps ErrNotFound.
+//
+//	tenantID, err := rivertype.MetadataAs[int64](job, "tenant_id")
+//
+//	type AppMetadata struct {
+//		TenantID int64  `json:"tenant_id"`
+//		TraceID  string `json:"trace_id"`
+//	}
+//	appMetadata, err := rivertype.MetadataAs[AppMetadata](job)
+func MetadataAs[T any](job *JobRow, keys ...string) (T, error) {
+	var val T
+
+	raw, ok := job.MetadataGet(keys...)
+	if !ok {
+		return val, fmt.Errorf("metadata path %q: %w", keys, ErrNotFound)
+	}
+
+	if err := json.Unmarshal(raw, &val); err != nil {
+		return val, fmt.Errorf("error unmarshaling metadata path %q: %w", keys, err)
+	}
+
+	return val, nil
+}
+
 // JobState is the state of a job. Jobs start their lifecycle as either
 // JobStateAvailable or JobStateScheduled, and if all goes well, transition to
 // JobStateCompleted after they're worked.
@@ -235,6 +363,40 @@ func JobStates() []JobState {
 	}
 }
 
+// AttemptInfo is information about the environment in which a single job
+// attempt was worked, useful for tracing a problem with a job back to the
+// process and deployment that worked it. It's recorded when the attempt
+// finishes, so it's not recorded for attempts whose process crashed before
+// then, but those will still have an AttemptError from the job being rescued.
+type AttemptInfo struct {
+	// At is the time at which the attempt started (maps to AttemptedAt on a job
+	// row).
+	At time.Time `json:"at"`
+
+	// Attempt is the attempt number of the attempt (maps to Attempt on a job
+	// row). The same number may be recorded more than once for a job that
+	// snoozed, because snoozing gives the attempt back.
+	Attempt int `json:"attempt"`
+
+	// ClientID is the ID of the client that worked the attempt.
+	ClientID string `json:"client_id"`
+
+	// ClientVersion is the version of River that the client that worked the
+	// attempt was built with. Empty if it couldn't be determined, like when
+	// River was built as the main module.
+	ClientVersion string `json:"client_version,omitempty"`
+
+	// Hostname is the hostname of the machine on which the attempt was worked.
+	Hostname string `json:"hostname"`
+
+	// PID is the process ID of the process that worked the attempt.
+	PID int `json:"pid"`
+
+	// QueueWaitDuration is the amount of time that the job spent waiting to be
+	// worked after it was scheduled to run.
+	QueueWaitDuration time.Duration `json:"queue_wait_duration"`
+}
+
 // AttemptError is an error from a single job attempt that failed due to an
 // error or a panic.
 type AttemptError struct {
@@ -268,6 +430,7 @@ type JobInsertParams struct {
 	Priority     int
 	Queue        string
 	ScheduledAt  *time.Time
+	ScheduledIn  time.Duration
 	State        JobState
 	Tags         []string
 	UniqueKey    []byte
@@ -323,6 +486,21 @@ type HookInsertBegin interface {
 	InsertBegin(ctx context.Context, params *JobInsertParams) error
 }
 
+// HookInsertMetadata is an interface to a hook that contributes metadata to
+// jobs being inserted, like trace IDs, tenant IDs, or a deploy version.
+type HookInsertMetadata interface {
+	Hook
+
+	// InsertMetadata is invoked for every job being inserted, before any
+	// HookInsertBegin hooks. Keys in the returned map are merged into the
+	// job's metadata, except for keys that the metadata already contains,
+	// which take precedence so that metadata given at insertion time isn't
+	// overwritten. Values must be marshalable to JSON.
+	//
+	// Returning an error aborts the insert.
+	InsertMetadata(ctx context.Context, params *JobInsertParams) (map[string]any, error)
+}
+
 // HookPeriodicJobsStart is an interface to a hook that runs when the periodic
 // job enqueuer starts on a newly elected leader.
 type HookPeriodicJobsStart interface {
@@ -503,6 +681,24 @@ type PeriodicJobHandle int
 // Queue is a configuration for a queue that is currently (or recently was) in
 // use by a client.
 type Queue struct {
+	// CountAvailable is the number of jobs in the queue that are available to
+	// be worked. Only populated by Client.QueueGet and Client.QueueList.
+	CountAvailable int
+
+	// CountClientsWithRunningJobs is the number of distinct clients with at
+	// least one job from the queue currently running, as determined by the
+	// last client to attempt each running job. Combined with CountAvailable,
+	// it answers whether a queue with work waiting is actually being
+	// processed. It is not a count of all clients working the queue: clients
+	// which are configured to work the queue but have no jobs running at the
+	// moment (like idle clients of an empty queue) aren't counted. Only
+	// populated by Client.QueueGet and Client.QueueList.
+	CountClientsWithRunningJobs int
+
+	// CountRunning is the number of jobs in the queue that are currently
+	// running. Only populated by Client.QueueGet and Client.QueueList.
+	CountRunning int
+
 	// CreatedAt is the time at which the queue first began being worked by a
 	// client. Unused queues are deleted after a retention period, so this only
 	// reflects the most recent time the queue was created if there was a long
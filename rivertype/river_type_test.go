@@ -6,6 +6,7 @@ import (
 	"go/token"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -50,6 +51,174 @@ func TestJobRow_Output(t *testing.T) {
 	})
 }
 
+func TestJobRow_AttemptInfos(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AttemptInfos", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{"river:attempt_infos": [
+				{"at": "2025-01-02T03:04:05Z", "attempt": 1, "client_id": "client1", "client_version": "v0.40.0", "hostname": "host1", "pid": 123, "queue_wait_duration": 1000000000},
+				{"at": "2025-01-02T03:05:05Z", "attempt": 2, "client_id": "client2", "hostname": "host2", "pid": 456, "queue_wait_duration": 0}
+			]}`),
+		}
+		require.Equal(t, []rivertype.AttemptInfo{
+			{At: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Attempt: 1, ClientID: "client1", ClientVersion: "v0.40.0", Hostname: "host1", PID: 123, QueueWaitDuration: time.Second},
+			{At: time.Date(2025, 1, 2, 3, 5, 5, 0, time.UTC), Attempt: 2, ClientID: "client2", Hostname: "host2", PID: 456},
+		}, jobRow.AttemptInfos())
+	})
+
+	t.Run("NoAttemptInfos", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{}`),
+		}
+		require.Nil(t, jobRow.AttemptInfos())
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`not-json`),
+		}
+		require.Nil(t, jobRow.AttemptInfos())
+	})
+}
+
+func TestJobRow_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{"river:checkpoint": {"last_id": 123}}`),
+		}
+		require.JSONEq(t, `{"last_id": 123}`, string(jobRow.Checkpoint()))
+	})
+
+	t.Run("NoCheckpoint", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{}`),
+		}
+		require.Nil(t, jobRow.Checkpoint())
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`not-json`),
+		}
+		require.Nil(t, jobRow.Checkpoint())
+	})
+}
+
+func TestJobRow_MetadataGet(t *testing.T) {
+	t.Parallel()
+
+	jobRow := &rivertype.JobRow{
+		Metadata: []byte(`{"tenant_id": 123, "tracing": {"trace_id": "abc"}}`),
+	}
+
+	t.Run("TopLevelKey", func(t *testing.T) {
+		t.Parallel()
+
+		value, ok := jobRow.MetadataGet("tenant_id")
+		require.True(t, ok)
+		require.JSONEq(t, `123`, string(value))
+	})
+
+	t.Run("NestedKey", func(t *testing.T) {
+		t.Parallel()
+
+		value, ok := jobRow.MetadataGet("tracing", "trace_id")
+		require.True(t, ok)
+		require.JSONEq(t, `"abc"`, string(value))
+	})
+
+	t.Run("NoKeys", func(t *testing.T) {
+		t.Parallel()
+
+		value, ok := jobRow.MetadataGet()
+		require.True(t, ok)
+		require.JSONEq(t, string(jobRow.Metadata), string(value))
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := jobRow.MetadataGet("missing")
+		require.False(t, ok)
+
+		_, ok = jobRow.MetadataGet("tenant_id", "not_an_object")
+		require.False(t, ok)
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := (&rivertype.JobRow{Metadata: []byte(`not-json`)}).MetadataGet("tenant_id")
+		require.False(t, ok)
+	})
+}
+
+func TestMetadataAs(t *testing.T) {
+	t.Parallel()
+
+	jobRow := &rivertype.JobRow{
+		Metadata: []byte(`{"tenant_id": 123, "tracing": {"trace_id": "abc"}}`),
+	}
+
+	t.Run("Scalar", func(t *testing.T) {
+		t.Parallel()
+
+		tenantID, err := rivertype.MetadataAs[int64](jobRow, "tenant_id")
+		require.NoError(t, err)
+		require.Equal(t, int64(123), tenantID)
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		t.Parallel()
+
+		type tracing struct {
+			TraceID string `json:"trace_id"`
+		}
+
+		type appMetadata struct {
+			TenantID int64   `json:"tenant_id"`
+			Tracing  tracing `json:"tracing"`
+		}
+
+		metadata, err := rivertype.MetadataAs[appMetadata](jobRow)
+		require.NoError(t, err)
+		require.Equal(t, appMetadata{TenantID: 123, Tracing: tracing{TraceID: "abc"}}, metadata)
+
+		tracingMetadata, err := rivertype.MetadataAs[tracing](jobRow, "tracing")
+		require.NoError(t, err)
+		require.Equal(t, tracing{TraceID: "abc"}, tracingMetadata)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rivertype.MetadataAs[string](jobRow, "missing")
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rivertype.MetadataAs[string](jobRow, "tenant_id")
+		require.ErrorContains(t, err, `error unmarshaling metadata path ["tenant_id"]`)
+	})
+}
+
 func TestJobStates(t *testing.T) {
 	t.Parallel()
 
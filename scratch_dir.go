@@ -0,0 +1,264 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type scratchDirContextKey struct{}
+
+// ScratchDir extracts the path of a job's scratch directory from context from
+// within the Work body of a worker. ScratchDirMiddleware must be installed on
+// either the worker or client for this function to be usable.
+//
+// This variant panics if no scratch directory was available in context.
+func ScratchDir(ctx context.Context) string {
+	dir, ok := ScratchDirSafely(ctx)
+	if !ok {
+		panic("no scratch directory in context; do you have river.ScratchDirMiddleware configured?")
+	}
+	return dir
+}
+
+// ScratchDirSafely extracts the path of a job's scratch directory from context
+// from within the Work body of a worker. ScratchDirMiddleware must be installed
+// on either the worker or client for this function to be usable.
+//
+// This variant returns a boolean that's true if a scratch directory was
+// available in context and false otherwise.
+func ScratchDirSafely(ctx context.Context) (string, bool) {
+	dir, ok := ctx.Value(scratchDirContextKey{}).(string)
+	return dir, ok
+}
+
+// ScratchDirMiddleware gives each job attempt an empty temporary directory for
+// scratch space, which is useful for jobs that shell out to tools that need to
+// work on disk. The directory's path is available in the work function's
+// context with ScratchDir:
+//
+//	func (w *TranscodeWorker) Work(ctx context.Context, job *river.Job[TranscodeArgs]) error {
+//		outputPath := filepath.Join(river.ScratchDir(ctx), "output.mp4")
+//		...
+//	}
+//
+// The directory and everything in it is removed after the attempt finishes,
+// whether it succeeded or not.
+//
+// A process that crashes mid-job doesn't get the chance to remove its scratch
+// directories. To handle that, the first time the middleware works a job, it
+// checks every scratch directory under its base directory against the
+// database and removes those belonging to jobs that are no longer running at
+// the attempt that created them (like a job recovered by the job rescuer).
+// Directories left by previous attempts of a job are also removed when the job
+// is next worked on the same host.
+type ScratchDirMiddleware struct {
+	baseservice.BaseService
+	MiddlewareDefaults
+
+	config    *ScratchDirMiddlewareConfig
+	sweepOnce sync.Once
+}
+
+// ScratchDirMiddlewareConfig is configuration for ScratchDirMiddleware.
+type ScratchDirMiddlewareConfig struct {
+	// BaseDir is the directory under which per-job scratch directories are
+	// created. It's created if it doesn't already exist.
+	//
+	// BaseDir should be dedicated to River scratch directories because entries
+	// in it that look like orphaned scratch directories are removed. It may be
+	// shared by multiple clients and processes on the same host, as long as
+	// they use the same database.
+	//
+	// Defaults to a `river-scratch` directory in os.TempDir.
+	BaseDir string
+}
+
+// NewScratchDirMiddleware initializes a new ScratchDirMiddleware with the given
+// configuration, which may be nil to use defaults.
+func NewScratchDirMiddleware(config *ScratchDirMiddlewareConfig) *ScratchDirMiddleware {
+	if config == nil {
+		config = &ScratchDirMiddlewareConfig{}
+	}
+
+	return &ScratchDirMiddleware{
+		config: &ScratchDirMiddlewareConfig{
+			BaseDir: cmp.Or(config.BaseDir, filepath.Join(os.TempDir(), "river-scratch")),
+		},
+	}
+}
+
+func (m *ScratchDirMiddleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	if err := os.MkdirAll(m.config.BaseDir, 0o700); err != nil {
+		return fmt.Errorf("error creating scratch base directory: %w", err)
+	}
+
+	m.sweepOnce.Do(func() {
+		if err := m.removeOrphans(ctx); err != nil {
+			m.logWarn(ctx, "Error removing orphaned scratch directories", slog.String("err", err.Error()))
+		}
+	})
+
+	if err := m.removePreviousAttempts(job); err != nil {
+		m.logWarn(ctx, "Error removing scratch directories of previous attempts", slog.Int64("job_id", job.ID), slog.String("err", err.Error()))
+	}
+
+	// An existing directory for this attempt is possible if the job was
+	// released on stop (which doesn't increment its attempt) by a process that
+	// then crashed before cleaning up. Start with an empty one regardless.
+	dir := filepath.Join(m.config.BaseDir, scratchDirName(job.ID, job.Attempt))
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("error removing existing scratch directory: %w", err)
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return fmt.Errorf("error creating scratch directory: %w", err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			m.logWarn(ctx, "Error removing scratch directory", slog.Int64("job_id", job.ID), slog.String("err", err.Error()))
+		}
+	}()
+
+	return doInner(context.WithValue(ctx, scratchDirContextKey{}, dir))
+}
+
+func (m *ScratchDirMiddleware) logWarn(ctx context.Context, msg string, attrs ...slog.Attr) {
+	// Logger is only set if the middleware was installed on a client.
+	if m.Logger != nil {
+		m.Logger.LogAttrs(ctx, slog.LevelWarn, "ScratchDirMiddleware: "+msg, attrs...)
+	}
+}
+
+// removeOrphans removes scratch directories belonging to jobs that are no
+// longer running at the attempt that created them. Jobs are looked up through
+// the client in context, so orphans aren't removed outside of a client.
+func (m *ScratchDirMiddleware) removeOrphans(ctx context.Context) error {
+	jobGetter, ok := ctx.Value(rivercommon.ContextKeyClient{}).(scratchDirJobGetter)
+	if !ok {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.config.BaseDir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		attemptsByName = make(map[string]int)
+		idsByName      = make(map[string]int64)
+		ids            = make([]int64, 0, len(entries))
+	)
+	for _, entry := range entries {
+		id, attempt, ok := parseScratchDirName(entry.Name())
+		if !ok || !entry.IsDir() {
+			continue
+		}
+
+		attemptsByName[entry.Name()] = attempt
+		idsByName[entry.Name()] = id
+		ids = append(ids, id)
+	}
+
+	if len(ids) < 1 {
+		return nil
+	}
+
+	jobs, err := jobGetter.scratchDirJobGetByIDMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	runningAttemptByID := make(map[int64]int, len(jobs))
+	for _, job := range jobs {
+		if job.State == rivertype.JobStateRunning {
+			runningAttemptByID[job.ID] = job.Attempt
+		}
+	}
+
+	var errs []error
+	for name, id := range idsByName {
+		if attempt, ok := runningAttemptByID[id]; ok && attempt == attemptsByName[name] {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(m.config.BaseDir, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// removePreviousAttempts removes scratch directories left by earlier attempts
+// of the given job.
+func (m *ScratchDirMiddleware) removePreviousAttempts(job *rivertype.JobRow) error {
+	matches, err := filepath.Glob(filepath.Join(m.config.BaseDir, strconv.FormatInt(job.ID, 10)+"-*"))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, match := range matches {
+		_, attempt, ok := parseScratchDirName(filepath.Base(match))
+		if !ok || attempt >= job.Attempt {
+			continue
+		}
+
+		if err := os.RemoveAll(match); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// scratchDirJobGetter is implemented by Client so that ScratchDirMiddleware
+// can look up jobs from the client in context without knowing its transaction
+// type.
+type scratchDirJobGetter interface {
+	scratchDirJobGetByIDMany(ctx context.Context, ids []int64) ([]*rivertype.JobRow, error)
+}
+
+func (c *Client[TTx]) scratchDirJobGetByIDMany(ctx context.Context, ids []int64) ([]*rivertype.JobRow, error) {
+	return c.driver.GetExecutor().JobGetByIDMany(ctx, &riverdriver.JobGetByIDManyParams{
+		ID:     ids,
+		Schema: c.config.Schema,
+	})
+}
+
+func scratchDirName(id int64, attempt int) string {
+	return strconv.FormatInt(id, 10) + "-" + strconv.Itoa(attempt)
+}
+
+func parseScratchDirName(name string) (int64, int, bool) {
+	idStr, attemptStr, ok := strings.Cut(name, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	attempt, err := strconv.Atoi(attemptStr)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return id, attempt, true
+}
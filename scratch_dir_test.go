@@ -0,0 +1,195 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdbtest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+var _ rivertype.WorkerMiddleware = &ScratchDirMiddleware{}
+
+func TestScratchDir(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	require.PanicsWithValue(t, "no scratch directory in context; do you have river.ScratchDirMiddleware configured?", func() {
+		ScratchDir(ctx)
+	})
+
+	_, ok := ScratchDirSafely(ctx)
+	require.False(t, ok)
+
+	ctx = context.WithValue(ctx, scratchDirContextKey{}, "/tmp/dir")
+	require.Equal(t, "/tmp/dir", ScratchDir(ctx))
+}
+
+func TestScratchDirMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		baseDir string
+	}
+
+	setup := func(t *testing.T) (*ScratchDirMiddleware, *testBundle) {
+		t.Helper()
+
+		baseDir := filepath.Join(t.TempDir(), "scratch")
+
+		return NewScratchDirMiddleware(&ScratchDirMiddlewareConfig{BaseDir: baseDir}), &testBundle{
+			baseDir: baseDir,
+		}
+	}
+
+	mkdirScratch := func(t *testing.T, bundle *testBundle, name string) string {
+		t.Helper()
+
+		dir := filepath.Join(bundle.baseDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0o700))
+		return dir
+	}
+
+	t.Run("BaseDirDefault", func(t *testing.T) {
+		t.Parallel()
+
+		middleware := NewScratchDirMiddleware(nil)
+		require.Equal(t, filepath.Join(os.TempDir(), "river-scratch"), middleware.config.BaseDir)
+	})
+
+	t.Run("CreatesAndRemovesDir", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		var scratchDir string
+		err := middleware.Work(ctx, &rivertype.JobRow{ID: 123, Attempt: 1}, func(ctx context.Context) error {
+			scratchDir = ScratchDir(ctx)
+			require.Equal(t, filepath.Join(bundle.baseDir, "123-1"), scratchDir)
+
+			entries, err := os.ReadDir(scratchDir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+
+			return os.WriteFile(filepath.Join(scratchDir, "file"), []byte("data"), 0o600)
+		})
+		require.NoError(t, err)
+		require.NoDirExists(t, scratchDir)
+	})
+
+	t.Run("RemovesDirOnError", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t)
+
+		var scratchDir string
+		err := middleware.Work(ctx, &rivertype.JobRow{ID: 123, Attempt: 1}, func(ctx context.Context) error {
+			scratchDir = ScratchDir(ctx)
+			return errors.New("job error")
+		})
+		require.EqualError(t, err, "job error")
+		require.NoDirExists(t, scratchDir)
+	})
+
+	t.Run("EmptiesExistingDirForAttempt", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		existingDir := mkdirScratch(t, bundle, "123-1")
+		require.NoError(t, os.WriteFile(filepath.Join(existingDir, "file"), []byte("data"), 0o600))
+
+		err := middleware.Work(ctx, &rivertype.JobRow{ID: 123, Attempt: 1}, func(ctx context.Context) error {
+			entries, err := os.ReadDir(ScratchDir(ctx))
+			require.NoError(t, err)
+			require.Empty(t, entries)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RemovesPreviousAttempts", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		var (
+			previousAttemptDir = mkdirScratch(t, bundle, "123-1")
+			otherJobDir        = mkdirScratch(t, bundle, "1234-1")
+		)
+
+		err := middleware.Work(ctx, &rivertype.JobRow{ID: 123, Attempt: 2}, func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+
+		require.NoDirExists(t, previousAttemptDir)
+		require.DirExists(t, otherJobDir)
+	})
+
+	t.Run("RemovesOrphans", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t)
+
+		var (
+			dbPool = riversharedtest.DBPool(ctx, t)
+			driver = riverpgxv5.New(dbPool)
+			schema = riverdbtest.TestSchema(ctx, t, driver, nil)
+			client = newTestClient(t, dbPool, newTestConfig(t, schema))
+			exec   = driver.GetExecutor()
+		)
+
+		var (
+			completedJob = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), Schema: schema, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			runningJob   = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), Schema: schema, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		)
+
+		var (
+			completedJobDir       = mkdirScratch(t, bundle, scratchDirName(completedJob.ID, 1))
+			nonexistentJobDir     = mkdirScratch(t, bundle, scratchDirName(1_000_000, 1))
+			runningJobDir         = mkdirScratch(t, bundle, scratchDirName(runningJob.ID, 2))
+			runningJobPreviousDir = mkdirScratch(t, bundle, scratchDirName(runningJob.ID, 1))
+			unrelatedDir          = mkdirScratch(t, bundle, "unrelated")
+		)
+
+		err := middleware.Work(withClient(ctx, client), &rivertype.JobRow{ID: 1_000_001, Attempt: 1}, func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+
+		require.NoDirExists(t, completedJobDir)
+		require.NoDirExists(t, nonexistentJobDir)
+		require.DirExists(t, runningJobDir)
+		require.NoDirExists(t, runningJobPreviousDir)
+		require.DirExists(t, unrelatedDir)
+
+		// Orphans are only swept on first use.
+		orphanDir := mkdirScratch(t, bundle, scratchDirName(completedJob.ID, 1))
+		err = middleware.Work(withClient(ctx, client), &rivertype.JobRow{ID: 1_000_001, Attempt: 2}, func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+		require.DirExists(t, orphanDir)
+	})
+}
+
+func TestParseScratchDirName(t *testing.T) {
+	t.Parallel()
+
+	id, attempt, ok := parseScratchDirName(scratchDirName(123, 4))
+	require.True(t, ok)
+	require.Equal(t, int64(123), id)
+	require.Equal(t, 4, attempt)
+
+	for _, name := range []string{"", "123", "123-", "abc-1", "123-abc", "123-1-1"} {
+		_, _, ok := parseScratchDirName(name)
+		require.False(t, ok, "expected %q not to parse", name)
+	}
+}
@@ -0,0 +1,190 @@
+package river
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/riverqueue/river/rivershared/util/maputil"
+)
+
+const (
+	// Defaults are chosen so that both phases fit comfortably within
+	// Kubernetes' default termination grace period of 30 seconds.
+	stopOnSignalHardStopTimeoutDefault = 5 * time.Second
+	stopOnSignalSoftStopTimeoutDefault = 20 * time.Second
+)
+
+// StopOnSignalConfig is configuration for Client.StopOnSignal.
+type StopOnSignalConfig struct {
+	// HardStopTimeout is the maximum amount of time to wait for running jobs
+	// to return after their contexts have been cancelled. If it elapses,
+	// StopOnSignal gives up waiting, logs the jobs that were abandoned, and
+	// returns an error.
+	//
+	// Defaults to 5 seconds.
+	HardStopTimeout time.Duration
+
+	// Signals are the OS signals that initiate a stop.
+	//
+	// Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+
+	// SoftStopTimeout is the maximum amount of time to wait for running jobs
+	// to finish on their own after a signal is received. If it elapses, or if
+	// a second signal is received in the meantime, running jobs have their
+	// contexts cancelled and StopOnSignal proceeds to a hard stop.
+	//
+	// Defaults to 20 seconds.
+	SoftStopTimeout time.Duration
+}
+
+func (c *StopOnSignalConfig) withDefaults() *StopOnSignalConfig {
+	if c == nil {
+		c = &StopOnSignalConfig{}
+	}
+
+	signals := c.Signals
+	if len(signals) < 1 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	return &StopOnSignalConfig{
+		HardStopTimeout: cmp.Or(c.HardStopTimeout, stopOnSignalHardStopTimeoutDefault),
+		Signals:         signals,
+		SoftStopTimeout: cmp.Or(c.SoftStopTimeout, stopOnSignalSoftStopTimeoutDefault),
+	}
+}
+
+func (c *StopOnSignalConfig) validate() error {
+	if c.HardStopTimeout < 0 {
+		return errors.New("HardStopTimeout cannot be negative")
+	}
+	if c.SoftStopTimeout < 0 {
+		return errors.New("SoftStopTimeout cannot be negative")
+	}
+	return nil
+}
+
+// StopOnSignal blocks until one of the configured OS signals is received
+// (SIGINT or SIGTERM by default), then stops the client using a two phase
+// shutdown:
+//
+//  1. A soft stop, equivalent to Stop, during which no new jobs are fetched
+//     and running jobs are given up to SoftStopTimeout to finish.
+//  2. If the soft stop doesn't complete in time, or a second signal is
+//     received, a hard stop, equivalent to StopAndCancel, during which running
+//     jobs have their contexts cancelled and are given up to HardStopTimeout
+//     to return.
+//
+// Each phase is logged along with the IDs of any jobs that were still running
+// at the time, so it's possible to tell after the fact which jobs were
+// cancelled or abandoned. Cancellation of ctx is treated the same as receipt
+// of a signal. StopOnSignal returns nil immediately if the client stops for
+// some other reason.
+//
+// StopOnSignal should be called after Start:
+//
+//	if err := riverClient.Start(ctx); err != nil {
+//		return err
+//	}
+//
+//	if err := riverClient.StopOnSignal(ctx, nil); err != nil {
+//		return err
+//	}
+func (c *Client[TTx]) StopOnSignal(ctx context.Context, config *StopOnSignalConfig) error {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, config.Signals...)
+	defer signal.Stop(sigCh)
+
+	return c.stopOnSignal(ctx, config, sigCh)
+}
+
+// Internal implementation for StopOnSignal that takes a signal channel so that
+// it can be tested without sending real signals to the process.
+func (c *Client[TTx]) stopOnSignal(ctx context.Context, config *StopOnSignalConfig, sigCh <-chan os.Signal) error {
+	select {
+	case <-c.Stopped():
+		return nil
+	case <-ctx.Done():
+		c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Context done; starting soft stop",
+			slog.Duration("soft_stop_timeout", config.SoftStopTimeout))
+	case sig := <-sigCh:
+		c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Received signal; starting soft stop",
+			slog.String("signal", sig.String()),
+			slog.Duration("soft_stop_timeout", config.SoftStopTimeout))
+	}
+
+	// The context may well have been what initiated the stop, so remove its
+	// cancellation for the rest of the sequence.
+	ctx = context.WithoutCancel(ctx)
+
+	softStopCtx, softStopCancel := context.WithTimeout(ctx, config.SoftStopTimeout)
+	defer softStopCancel()
+
+	// A second signal during the soft stop escalates to a hard stop. This is
+	// the conventional behavior for a user hitting Ctrl+C twice.
+	go func() {
+		select {
+		case <-softStopCtx.Done():
+		case sig := <-sigCh:
+			c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Received second signal; escalating to hard stop",
+				slog.String("signal", sig.String()))
+			softStopCancel()
+		}
+	}()
+
+	if err := c.Stop(softStopCtx); err == nil {
+		c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Soft stop complete")
+		return nil
+	}
+
+	c.logActiveJobs(ctx, c.baseService.Name+": Soft stop incomplete; cancelling running jobs",
+		slog.Duration("hard_stop_timeout", config.HardStopTimeout))
+
+	hardStopCtx, hardStopCancel := context.WithTimeout(ctx, config.HardStopTimeout)
+	defer hardStopCancel()
+
+	if err := c.StopAndCancel(hardStopCtx); err != nil {
+		c.logActiveJobs(ctx, c.baseService.Name+": Hard stop timeout; abandoning jobs that didn't return after cancellation")
+		return fmt.Errorf("error hard stopping client: %w", err)
+	}
+
+	c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Hard stop complete")
+	return nil
+}
+
+// Logs the given message once for each queue that has jobs still running,
+// including the IDs of those jobs.
+func (c *Client[TTx]) logActiveJobs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	c.producersMu.RLock()
+	defer c.producersMu.RUnlock()
+
+	queues := maputil.Keys(c.producersByQueueName)
+	slices.Sort(queues)
+
+	for _, queue := range queues {
+		jobIDs := c.producersByQueueName[queue].ActiveJobIDs()
+		if len(jobIDs) < 1 {
+			continue
+		}
+
+		c.baseService.Logger.LogAttrs(ctx, slog.LevelWarn, msg, append([]slog.Attr{
+			slog.String("queue", queue),
+			slog.Any("job_ids", jobIDs),
+			slog.Int("num_jobs", len(jobIDs)),
+		}, attrs...)...)
+	}
+}
@@ -0,0 +1,169 @@
+package river
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+)
+
+func TestClientStopOnSignal(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	type testBundle struct {
+		jobCancelledChan chan struct{}
+		jobStartedChan   chan int64
+		sigCh            chan os.Signal
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		config := newTestConfig(t, "")
+
+		var (
+			jobCancelledChan = make(chan struct{})
+			jobStartedChan   = make(chan int64)
+		)
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			close(jobCancelledChan)
+			return ctx.Err()
+		}))
+
+		client := runNewTestClient(ctx, t, config)
+
+		return client, &testBundle{
+			jobCancelledChan: jobCancelledChan,
+			jobStartedChan:   jobStartedChan,
+			sigCh:            make(chan os.Signal, 2),
+		}
+	}
+
+	startStopOnSignal := func(ctx context.Context, t *testing.T, client *Client[pgx.Tx], bundle *testBundle, config *StopOnSignalConfig) <-chan error {
+		t.Helper()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- client.stopOnSignal(ctx, config.withDefaults(), bundle.sigCh)
+		}()
+		return errCh
+	}
+
+	t.Run("SoftStop", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		require.NoError(t, client.Start(ctx))
+
+		errCh := startStopOnSignal(ctx, t, client, bundle, nil)
+
+		bundle.sigCh <- syscall.SIGTERM
+
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+		riversharedtest.WaitOrTimeout(t, client.Stopped())
+	})
+
+	t.Run("HardStopAfterSoftStopTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		require.NoError(t, client.Start(ctx))
+
+		_, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobStartedChan)
+
+		errCh := startStopOnSignal(ctx, t, client, bundle, &StopOnSignalConfig{SoftStopTimeout: 50 * time.Millisecond})
+
+		bundle.sigCh <- syscall.SIGTERM
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobCancelledChan)
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+		riversharedtest.WaitOrTimeout(t, client.Stopped())
+	})
+
+	t.Run("SecondSignalEscalatesToHardStop", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		require.NoError(t, client.Start(ctx))
+
+		_, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobStartedChan)
+
+		errCh := startStopOnSignal(ctx, t, client, bundle, &StopOnSignalConfig{SoftStopTimeout: time.Hour})
+
+		bundle.sigCh <- syscall.SIGTERM
+
+		select {
+		case <-bundle.jobCancelledChan:
+			require.FailNow(t, "Expected job to not be cancelled before second signal")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		bundle.sigCh <- syscall.SIGINT
+
+		riversharedtest.WaitOrTimeout(t, bundle.jobCancelledChan)
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+	})
+
+	t.Run("ContextCancellationStops", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		require.NoError(t, client.Start(ctx))
+
+		stopCtx, stopCancel := context.WithCancel(ctx)
+		errCh := startStopOnSignal(stopCtx, t, client, bundle, nil)
+
+		stopCancel()
+
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+		riversharedtest.WaitOrTimeout(t, client.Stopped())
+	})
+
+	t.Run("ReturnsWhenClientStoppedElsewhere", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		require.NoError(t, client.Start(ctx))
+
+		errCh := startStopOnSignal(ctx, t, client, bundle, nil)
+
+		require.NoError(t, client.Stop(ctx))
+
+		require.NoError(t, riversharedtest.WaitOrTimeout(t, errCh))
+	})
+
+	t.Run("ValidatesConfig", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		require.EqualError(t, client.StopOnSignal(ctx, &StopOnSignalConfig{HardStopTimeout: -1}), "HardStopTimeout cannot be negative")
+		require.EqualError(t, client.StopOnSignal(ctx, &StopOnSignalConfig{SoftStopTimeout: -1}), "SoftStopTimeout cannot be negative")
+	})
+}
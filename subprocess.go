@@ -0,0 +1,267 @@
+package river
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime/debug"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// WorkerWithSubprocess is an interface that a Worker can optionally implement
+// to have jobs of its kind worked in a child process instead of the client's
+// own. It's useful for jobs that risk running out of memory or crashing in
+// native code, which would otherwise take down the whole client along with
+// every other job it's working:
+//
+//	func (w *RenderWorker) Subprocess() bool { return true }
+//
+// The child process is a new instance of the current executable, which must
+// call SubprocessMain with a Workers bundle including the worker near the top
+// of its main function. The job's args are sent to it over stdin, and its
+// result returned over stdout. Anything the job itself writes to stdout is
+// redirected to stderr, which is shared with the client.
+//
+// Middleware and hooks are run in the client around the child process rather
+// than in it, and functions that need the client from the work context (like
+// JobCompleteTx or ClientFromContext) aren't available to a job worked in a
+// child process. RecordOutput is supported. Cancellation of the job's context
+// interrupts the child process, which is killed if it hasn't exited a short
+// time later.
+//
+// A child process that exits without returning a result, like one killed for
+// running out of memory, fails its job with an error including its exit status
+// so that it's retried according to its retry policy.
+type WorkerWithSubprocess interface {
+	// Subprocess returns true if jobs of the worker's kind should be worked in
+	// a child process.
+	Subprocess() bool
+}
+
+// subprocessEnvVar is set in the environment of a child process started to
+// work a job so that SubprocessMain knows to work it.
+const subprocessEnvVar = "RIVER_SUBPROCESS_WORKER"
+
+// subprocessCancelWaitDelay is how long a child process is given to exit after
+// being interrupted because its job's context was cancelled before it's killed.
+const subprocessCancelWaitDelay = 10 * time.Second
+
+// subprocessCommand returns the command used to start a child process to work a
+// job. It's a variable so that tests can reexecute the test binary instead.
+var subprocessCommand = func(ctx context.Context) (*exec.Cmd, error) { //nolint:gochecknoglobals
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error finding executable for job subprocess: %w", err)
+	}
+
+	return exec.CommandContext(ctx, executable), nil
+}
+
+// subprocessRequest is sent to a child process over stdin.
+type subprocessRequest struct {
+	// Args are the job's args as JSON. They're sent separately from the job
+	// row because the row's args may be encoded by a codec that the child
+	// process doesn't have access to.
+	Args json.RawMessage `json:"args"`
+
+	Job *rivertype.JobRow `json:"job"`
+}
+
+// subprocessResult is returned by a child process over stdout.
+type subprocessResult struct {
+	Cancel          bool                       `json:"cancel,omitempty"`
+	Error           string                     `json:"error,omitempty"`
+	MetadataUpdates map[string]json.RawMessage `json:"metadata_updates,omitempty"`
+	Panic           string                     `json:"panic,omitempty"`
+	Snooze          *time.Duration             `json:"snooze,omitempty"`
+}
+
+// subprocessWorkUnit is implemented by work units that can work their job in a
+// child process started by SubprocessMain.
+type subprocessWorkUnit interface {
+	workInSubprocess(ctx context.Context, args []byte) error
+}
+
+// SubprocessMain works a job in a child process started by a client for a
+// worker implementing WorkerWithSubprocess, then exits. It must be called near
+// the top of the program's main function with a Workers bundle including the
+// job's worker, before any work that shouldn't be repeated in the child
+// process. When the current process isn't a child process started to work a
+// job, it returns immediately:
+//
+//	func main() {
+//		workers := river.NewWorkers()
+//		river.AddWorker(workers, &RenderWorker{})
+//
+//		river.SubprocessMain(workers)
+//
+//		// start a client and the rest of the program as usual
+//	}
+func SubprocessMain(workers *Workers) {
+	if os.Getenv(subprocessEnvVar) == "" {
+		return
+	}
+
+	os.Exit(subprocessMain(workers))
+}
+
+// subprocessMain is the body of SubprocessMain, returning an exit code so that
+// its deferred functions run before the process exits.
+func subprocessMain(workers *Workers) int {
+	// Reserve stdout for the result, sending anything else the job writes to
+	// it to stderr instead.
+	resultWriter := os.Stdout
+	os.Stdout = os.Stderr
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	res, err := subprocessWork(ctx, workers, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "river: error working job in subprocess: %s\n", err)
+		return 1
+	}
+
+	if err := json.NewEncoder(resultWriter).Encode(res); err != nil {
+		fmt.Fprintf(os.Stderr, "river: error writing job subprocess result: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// subprocessWork works the job read from the given reader in the current
+// process on behalf of SubprocessMain. Errors from the job itself are part of
+// the result, while a returned error means no result could be produced.
+//
+//nolint:nonamedreturns
+func subprocessWork(ctx context.Context, workers *Workers, requestReader io.Reader) (res *subprocessResult, err error) {
+	var request subprocessRequest
+	if err := json.NewDecoder(requestReader).Decode(&request); err != nil {
+		return nil, fmt.Errorf("error decoding job subprocess request: %w", err)
+	}
+	if request.Job == nil {
+		return nil, errors.New("job subprocess request has no job")
+	}
+
+	workerInfo, ok := workers.workersMap[request.Job.Kind]
+	if !ok {
+		return nil, &rivertype.UnknownJobKindError{Kind: request.Job.Kind}
+	}
+
+	workUnit, ok := workerInfo.workUnitFactory.MakeUnit(request.Job).(subprocessWorkUnit)
+	if !ok {
+		return nil, fmt.Errorf("worker for job of kind %q can't be worked in a subprocess", request.Job.Kind)
+	}
+
+	metadataUpdates := make(map[string]any)
+	ctx = context.WithValue(ctx, jobexecutor.ContextKeyMetadataUpdates, metadataUpdates)
+
+	res = &subprocessResult{}
+
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			fmt.Fprintf(os.Stderr, "river: job panicked in subprocess: %v\n%s", recovery, debug.Stack())
+			res = &subprocessResult{Panic: fmt.Sprintf("%v", recovery)}
+		}
+	}()
+
+	workErr := workUnit.workInSubprocess(ctx, request.Args)
+
+	var (
+		cancelErr *rivertype.JobCancelError
+		snoozeErr *rivertype.JobSnoozeError
+	)
+	switch {
+	case errors.As(workErr, &snoozeErr):
+		res.Snooze = &snoozeErr.Duration
+	case errors.As(workErr, &cancelErr):
+		res.Cancel = true
+		if unwrapped := cancelErr.Unwrap(); unwrapped != nil {
+			res.Error = unwrapped.Error()
+		}
+	case workErr != nil:
+		res.Error = workErr.Error()
+	}
+
+	if len(metadataUpdates) > 0 {
+		res.MetadataUpdates = make(map[string]json.RawMessage, len(metadataUpdates))
+		for key, value := range metadataUpdates {
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling metadata update %q: %w", key, err)
+			}
+			res.MetadataUpdates[key] = valueBytes
+		}
+	}
+
+	return res, nil
+}
+
+// workSubprocess works a job in a child process started with SubprocessMain,
+// translating its result back to what the job's Work function returned.
+func workSubprocess(ctx context.Context, jobRow *rivertype.JobRow, args JobArgs) error {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("error marshaling args for job subprocess: %w", err)
+	}
+
+	requestBytes, err := json.Marshal(&subprocessRequest{Args: argsBytes, Job: jobRow})
+	if err != nil {
+		return fmt.Errorf("error marshaling job subprocess request: %w", err)
+	}
+
+	cmd, err := subprocessCommand(ctx)
+	if err != nil {
+		return err
+	}
+
+	var resultBuf bytes.Buffer
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.Env = append(os.Environ(), subprocessEnvVar+"=true")
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = bytes.NewReader(requestBytes)
+	cmd.Stdout = &resultBuf
+	cmd.WaitDelay = subprocessCancelWaitDelay
+
+	runErr := cmd.Run()
+
+	var res subprocessResult
+	if err := json.Unmarshal(resultBuf.Bytes(), &res); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("job subprocess exited without a result: %w", runErr)
+		}
+		return fmt.Errorf("error decoding job subprocess result: %w", err)
+	}
+
+	if metadataUpdates, ok := jobexecutor.MetadataUpdatesFromWorkContext(ctx); ok {
+		for key, value := range res.MetadataUpdates {
+			metadataUpdates[key] = value
+		}
+	}
+
+	switch {
+	case res.Panic != "":
+		panic(res.Panic)
+	case res.Snooze != nil:
+		return JobSnooze(*res.Snooze)
+	case res.Cancel:
+		if res.Error == "" {
+			return JobCancel(nil)
+		}
+		return JobCancel(errors.New(res.Error))
+	case res.Error != "":
+		return errors.New(res.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package river
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivertype"
+)
+
+//nolint:gochecknoinits
+func init() {
+	// Reexecute the test binary running only TestSubprocessHelperProcess
+	// instead of the executable, which for tests is the test binary anyway.
+	subprocessCommand = func(ctx context.Context) (*exec.Cmd, error) {
+		return exec.CommandContext(ctx, os.Args[0], "-test.run=^TestSubprocessHelperProcess$"), nil //nolint:gosec
+	}
+}
+
+type subprocessArgs struct {
+	Behavior string `json:"behavior"`
+}
+
+func (subprocessArgs) Kind() string { return "subprocess" }
+
+type subprocessWorker struct {
+	WorkerDefaults[subprocessArgs]
+}
+
+func (w *subprocessWorker) Subprocess() bool { return true }
+
+func (w *subprocessWorker) Work(ctx context.Context, job *Job[subprocessArgs]) error {
+	switch job.Args.Behavior {
+	case "cancel":
+		return JobCancel(errors.New("cancelled in subprocess"))
+	case "crash":
+		os.Exit(3)
+	case "error":
+		return errors.New("error in subprocess")
+	case "panic":
+		panic("panic in subprocess")
+	case "snooze":
+		return JobSnooze(5 * time.Minute)
+	case "wait_cancel":
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	return RecordOutput(ctx, map[string]int{"pid": os.Getpid()})
+}
+
+func subprocessTestWorkers() *Workers {
+	workers := NewWorkers()
+	AddWorker(workers, &subprocessWorker{})
+	return workers
+}
+
+// TestSubprocessHelperProcess isn't a real test. It's run in a child process by
+// the other subprocess tests to work their jobs.
+func TestSubprocessHelperProcess(t *testing.T) { //nolint:paralleltest
+	if os.Getenv(subprocessEnvVar) == "" {
+		t.Skip("only run as a job subprocess")
+	}
+
+	SubprocessMain(subprocessTestWorkers())
+}
+
+func TestWorkSubprocess(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	workJob := func(ctx context.Context, t *testing.T, behavior string) (map[string]any, error) {
+		t.Helper()
+
+		jobRow := &rivertype.JobRow{
+			ID:          123,
+			EncodedArgs: []byte(`{"behavior":"` + behavior + `"}`),
+			Kind:        (subprocessArgs{}).Kind(),
+		}
+
+		workUnit := subprocessTestWorkers().workersMap[jobRow.Kind].workUnitFactory.MakeUnit(jobRow)
+		require.NoError(t, workUnit.UnmarshalJob(nil))
+
+		metadataUpdates := make(map[string]any)
+		ctx = context.WithValue(ctx, jobexecutor.ContextKeyMetadataUpdates, metadataUpdates)
+
+		return metadataUpdates, workUnit.Work(ctx)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		metadataUpdates, err := workJob(ctx, t, "")
+		require.NoError(t, err)
+
+		var output struct {
+			PID int `json:"pid"`
+		}
+		require.NoError(t, json.Unmarshal(metadataUpdates[rivertype.MetadataKeyOutput].(json.RawMessage), &output)) //nolint:forcetypeassert
+		require.NotZero(t, output.PID)
+		require.NotEqual(t, os.Getpid(), output.PID, "expected job to be worked in a different process")
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := workJob(ctx, t, "cancel")
+		require.ErrorIs(t, err, &rivertype.JobCancelError{})
+		require.EqualError(t, errors.Unwrap(err), "cancelled in subprocess")
+	})
+
+	t.Run("Crash", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := workJob(ctx, t, "crash")
+		require.ErrorContains(t, err, "job subprocess exited without a result")
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := workJob(ctx, t, "error")
+		require.EqualError(t, err, "error in subprocess")
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithValue(t, "panic in subprocess", func() {
+			_, _ = workJob(ctx, t, "panic")
+		})
+	})
+
+	t.Run("Snooze", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := workJob(ctx, t, "snooze")
+
+		var snoozeErr *rivertype.JobSnoozeError
+		require.ErrorAs(t, err, &snoozeErr)
+		require.Equal(t, 5*time.Minute, snoozeErr.Duration)
+	})
+
+	t.Run("ContextCancellationInterruptsSubprocess", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+
+		_, err := workJob(ctx, t, "wait_cancel")
+		require.EqualError(t, err, context.Canceled.Error())
+	})
+}
+
+func TestSubprocessWork(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("UnknownJobKind", func(t *testing.T) {
+		t.Parallel()
+
+		requestBytes, err := json.Marshal(&subprocessRequest{
+			Args: json.RawMessage(`{}`),
+			Job:  &rivertype.JobRow{ID: 123, Kind: "unknown"},
+		})
+		require.NoError(t, err)
+
+		_, err = subprocessWork(ctx, subprocessTestWorkers(), bytes.NewReader(requestBytes))
+		require.ErrorIs(t, err, &rivertype.UnknownJobKindError{})
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := subprocessWork(ctx, subprocessTestWorkers(), strings.NewReader("not json"))
+		require.ErrorContains(t, err, "error decoding job subprocess request")
+	})
+}
@@ -19,6 +19,10 @@ type subscriptionManager struct {
 	baseservice.BaseService
 	startstop.BaseStartStop
 
+	// rateAnomalyMonitor is fed job updates to count failures. Nil unless
+	// Config.RateAnomalyMonitor is set.
+	rateAnomalyMonitor *rateAnomalyMonitor
+
 	subscribeCh <-chan []jobcompleter.CompleterJobUpdated
 
 	statsMu        sync.Mutex // protects stats fields
@@ -133,6 +137,10 @@ func (sm *subscriptionManager) distributeJobUpdates(ctx context.Context, updates
 		}
 	}()
 
+	if sm.rateAnomalyMonitor != nil {
+		sm.rateAnomalyMonitor.RecordJobUpdates(updates)
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -195,11 +203,11 @@ func (sm *subscriptionManager) distributeJobEvent(ctx context.Context, job *rive
 	}
 }
 
-func (sm *subscriptionManager) distributeQueueEvent(event *Event) {
-	sm.distributeQueueEventWithContext(context.Background(), event)
+func (sm *subscriptionManager) distributeEvent(event *Event) {
+	sm.distributeEventWithContext(context.Background(), event)
 }
 
-func (sm *subscriptionManager) distributeQueueEventWithContext(ctx context.Context, event *Event) {
+func (sm *subscriptionManager) distributeEventWithContext(ctx context.Context, event *Event) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -0,0 +1,197 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/jobstats"
+	"github.com/riverqueue/river/internal/workunit"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/uniquestates"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// workSynchronously works jobs in the calling goroutine in place of inserting
+// them when Config.SynchronousMode is enabled, returning insert results
+// containing each job as it would've been updated after being worked.
+func (c *Client[TTx]) workSynchronously(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
+	ctx = withClient(ctx, c)
+	if c.config.WorkContext != nil {
+		ctx = c.config.WorkContext(ctx)
+	}
+
+	results := make([]*rivertype.JobInsertResult, len(insertParams))
+	for i, params := range insertParams {
+		job, err := c.workSynchronouslyOne(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = &rivertype.JobInsertResult{Job: job}
+	}
+
+	return results, nil
+}
+
+func (c *Client[TTx]) workSynchronouslyOne(ctx context.Context, params *riverdriver.JobInsertFastParams) (*rivertype.JobRow, error) {
+	workerInfo, ok := c.config.Workers.workersMap[params.Kind]
+	if !ok {
+		return nil, &rivertype.UnknownJobKindError{Kind: params.Kind}
+	}
+
+	now := c.baseService.Time.Now()
+
+	job := &rivertype.JobRow{
+		ID:           c.synchronousJobID.Add(1),
+		Attempt:      1,
+		AttemptedAt:  &now,
+		AttemptedBy:  []string{c.config.ID},
+		CreatedAt:    now,
+		EncodedArgs:  params.EncodedArgs,
+		Kind:         params.Kind,
+		MaxAttempts:  params.MaxAttempts,
+		Metadata:     params.Metadata,
+		Priority:     params.Priority,
+		Queue:        params.Queue,
+		ScheduledAt:  now,
+		State:        rivertype.JobStateRunning,
+		Tags:         params.Tags,
+		UniqueKey:    params.UniqueKey,
+		UniqueStates: uniquestates.UniqueBitmaskToStates(params.UniqueStates),
+	}
+
+	var workUnit workunit.WorkUnit = workerInfo.workUnitFactory.MakeUnit(job)
+	if middleware := c.config.Workers.middlewareByKind[workerInfo.jobArgs.Kind()]; len(middleware) > 0 {
+		workUnit = &kindMiddlewareWorkUnit{WorkUnit: workUnit, middleware: middleware}
+	}
+
+	var errorHandler jobexecutor.ErrorHandler
+	if c.config.ErrorHandler != nil {
+		errorHandler = &errorHandlerAdapter{c.config.ErrorHandler}
+	}
+
+	completer := &synchronousCompleter{}
+
+	// jobCancel will always be called by the executor to prevent leaks.
+	jobCtx, jobCancel := context.WithCancelCause(ctx)
+
+	executor := baseservice.Init(&c.baseService.Archetype, &jobexecutor.JobExecutor{
+		ArgsDecoder:              c.argsDecoder,
+		AttemptInfo:              c.attemptInfo,
+		CancelFunc:               jobCancel,
+		ClientJobTimeout:         c.config.JobTimeout,
+		ClientRetryPolicy:        c.config.RetryPolicy,
+		Completer:                completer,
+		DefaultClientRetryPolicy: &DefaultClientRetryPolicy{},
+		ErrorHandler:             errorHandler,
+		ErrorMaxBytes:            c.config.ErrorSizeLimits.errorMaxBytes(),
+		HookLookupByJob:          c.hookLookupByJob,
+		HookLookupGlobal:         c.hookLookupGlobal,
+		MiddlewareLookupGlobal:   c.middlewareLookupGlobal,
+		JobRow:                   job,
+		ProducerCallbacks: struct {
+			JobDone func(jobRow *rivertype.JobRow)
+			Stuck   func()
+			Unstuck func()
+		}{
+			JobDone: func(jobRow *rivertype.JobRow) {},
+			Stuck:   func() {},
+			Unstuck: func() {},
+		},
+		SchedulerInterval: c.config.schedulerInterval,
+		TraceMaxBytes:     c.config.ErrorSizeLimits.traceMaxBytes(),
+		WorkUnit:          workUnit,
+	})
+
+	executor.Execute(jobCtx)
+
+	update, err := completer.jobUpdated(job)
+	if err != nil {
+		return nil, err
+	}
+
+	c.subscriptionManager.distributeJobUpdates(ctx, []jobcompleter.CompleterJobUpdated{update})
+
+	return update.Job, nil
+}
+
+// synchronousCompleter is a job completer for Config.SynchronousMode that
+// records the state that a worked job would've been set to instead of setting
+// it in the database.
+type synchronousCompleter struct {
+	startstop.BaseStartStop
+
+	params *riverdriver.JobSetStateIfRunningParams
+	stats  *jobstats.JobStatistics
+}
+
+func (c *synchronousCompleter) JobSetStateIfRunning(ctx context.Context, stats *jobstats.JobStatistics, params *riverdriver.JobSetStateIfRunningParams) error {
+	c.params, c.stats = params, stats
+	return nil
+}
+
+func (c *synchronousCompleter) ResetSubscribeChan(subscribeCh jobcompleter.SubscribeChan) {}
+
+func (c *synchronousCompleter) Start(ctx context.Context) error { return nil }
+
+// jobUpdated returns a copy of the given job updated with the state it was set
+// to after being worked.
+func (c *synchronousCompleter) jobUpdated(job *rivertype.JobRow) (jobcompleter.CompleterJobUpdated, error) {
+	if c.params == nil {
+		return jobcompleter.CompleterJobUpdated{}, fmt.Errorf("job %d wasn't completed after being worked", job.ID)
+	}
+
+	updatedJob := *job
+	updatedJob.State = c.params.State
+
+	if c.params.Attempt != nil {
+		updatedJob.Attempt = *c.params.Attempt
+	}
+	if c.params.FinalizedAt != nil {
+		updatedJob.FinalizedAt = c.params.FinalizedAt
+	}
+	if c.params.ScheduledAt != nil {
+		updatedJob.ScheduledAt = *c.params.ScheduledAt
+	}
+
+	if len(c.params.ErrData) > 0 {
+		var attemptError rivertype.AttemptError
+		if err := json.Unmarshal(c.params.ErrData, &attemptError); err != nil {
+			return jobcompleter.CompleterJobUpdated{}, fmt.Errorf("error unmarshaling job error: %w", err)
+		}
+		updatedJob.Errors = append(updatedJob.Errors, attemptError)
+	}
+
+	if c.params.MetadataDoMerge && len(c.params.MetadataUpdates) > 0 {
+		metadata := make(map[string]json.RawMessage)
+		if len(job.Metadata) > 0 {
+			if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+				return jobcompleter.CompleterJobUpdated{}, fmt.Errorf("error unmarshaling job metadata: %w", err)
+			}
+		}
+
+		var metadataUpdates map[string]json.RawMessage
+		if err := json.Unmarshal(c.params.MetadataUpdates, &metadataUpdates); err != nil {
+			return jobcompleter.CompleterJobUpdated{}, fmt.Errorf("error unmarshaling job metadata updates: %w", err)
+		}
+		maps.Copy(metadata, metadataUpdates)
+
+		metadataBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return jobcompleter.CompleterJobUpdated{}, fmt.Errorf("error marshaling job metadata: %w", err)
+		}
+		updatedJob.Metadata = metadataBytes
+	}
+
+	stats := c.stats
+	if stats == nil {
+		stats = &jobstats.JobStatistics{}
+	}
+
+	return jobcompleter.CompleterJobUpdated{Job: &updatedJob, JobStats: stats, Snoozed: c.params.Snoozed}, nil
+}
@@ -0,0 +1,89 @@
+package river
+
+import (
+	"context"
+	"time"
+
+	"github.com/riverqueue/river/internal/argscodec"
+	"github.com/riverqueue/river/internal/hooklookup"
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// UnknownJobKindPolicy determines what a client does when it fetches a job
+// whose kind has no worker registered in its Workers bundle. See
+// Config.UnknownJobKindPolicy.
+type UnknownJobKindPolicy string
+
+const (
+	// UnknownJobKindPolicyRetry records an UnknownJobKindError on the job and
+	// retries it according to the client's retry policy like any other error,
+	// consuming one of its attempts. This is the default.
+	UnknownJobKindPolicyRetry UnknownJobKindPolicy = "retry"
+
+	// UnknownJobKindPolicyRelease leaves the job for other clients by
+	// rescheduling it a short time in the future without consuming an attempt
+	// or recording an error. It's useful for mixed-deployment fleets where
+	// clients working the same queue don't all have the same workers
+	// registered, like during a rolling deploy that adds a new kind.
+	//
+	// A job that no client has a worker for is released indefinitely, so take
+	// care that every kind is registered somewhere.
+	UnknownJobKindPolicyRelease UnknownJobKindPolicy = "release"
+
+	// UnknownJobKindPolicyCatchAll works the job with
+	// Config.UnknownJobKindWorkFunc, which must also be set.
+	UnknownJobKindPolicyCatchAll UnknownJobKindPolicy = "catch_all"
+
+	// UnknownJobKindPolicyQuarantine records an UnknownJobKindError on the job
+	// and discards it immediately without further retries, where it can be
+	// inspected and retried with Client.JobRetry once a worker is available.
+	UnknownJobKindPolicyQuarantine UnknownJobKindPolicy = "quarantine"
+)
+
+func (p UnknownJobKindPolicy) validate() bool {
+	switch p {
+	case "",
+		UnknownJobKindPolicyCatchAll,
+		UnknownJobKindPolicyQuarantine,
+		UnknownJobKindPolicyRelease,
+		UnknownJobKindPolicyRetry:
+		return true
+	}
+	return false
+}
+
+// executorAction returns the action a job executor should take for a job of
+// an unknown kind. Catch all is handled by giving the executor a work unit, so
+// has no corresponding action.
+func (p UnknownJobKindPolicy) executorAction() jobexecutor.UnknownJobKindAction {
+	switch p {
+	case UnknownJobKindPolicyQuarantine:
+		return jobexecutor.UnknownJobKindActionDiscard
+	case UnknownJobKindPolicyRelease:
+		return jobexecutor.UnknownJobKindActionRelease
+	case "", UnknownJobKindPolicyCatchAll, UnknownJobKindPolicyRetry:
+	}
+	return jobexecutor.UnknownJobKindActionRetry
+}
+
+// unknownJobKindWorkUnit implements workUnit for a job of an unknown kind
+// that's worked by Config.UnknownJobKindWorkFunc. Because the job's args type
+// isn't known, the function receives the raw job row.
+type unknownJobKindWorkUnit struct {
+	jobRow   *rivertype.JobRow
+	workFunc func(ctx context.Context, job *rivertype.JobRow) error
+}
+
+func (w *unknownJobKindWorkUnit) HookLookup(lookup *hooklookup.JobHookLookup) hooklookup.HookLookupInterface {
+	return hooklookup.NewHookLookup(nil)
+}
+
+func (w *unknownJobKindWorkUnit) Middleware() []rivertype.WorkerMiddleware { return nil }
+func (w *unknownJobKindWorkUnit) NextRetry() time.Time                     { return time.Time{} }
+func (w *unknownJobKindWorkUnit) Timeout() time.Duration                   { return 0 }
+func (w *unknownJobKindWorkUnit) TimeoutGracePeriod() time.Duration        { return 0 }
+func (w *unknownJobKindWorkUnit) UnmarshalJob(*argscodec.Decoder) error    { return nil }
+func (w *unknownJobKindWorkUnit) Work(ctx context.Context) error {
+	return w.workFunc(ctx, w.jobRow)
+}
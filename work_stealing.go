@@ -0,0 +1,78 @@
+package river
+
+import "sync"
+
+// workerSlotPool pools the worker slots of a client's queues for
+// Config.WorkStealing so that a queue with a backlog can borrow slots that
+// other queues aren't using. Every queue contributes its MaxWorkers to the
+// pool, but slots up to a queue's MinWorkers are never lent out, so each queue
+// can always work at least that many jobs even while others are borrowing.
+//
+// Producers acquire slots before fetching and release those they didn't fill
+// once the fetch completes, and release one more as each job finishes.
+type workerSlotPool struct {
+	mu     sync.Mutex
+	queues map[string]*workerSlotPoolQueue
+}
+
+type workerSlotPoolQueue struct {
+	inUse      int
+	maxWorkers int
+	minWorkers int
+}
+
+func newWorkerSlotPool() *workerSlotPool {
+	return &workerSlotPool{queues: make(map[string]*workerSlotPoolQueue)}
+}
+
+// acquire acquires up to want slots for the given queue, returning the number
+// acquired, which may be zero. Slots are acquired from the pool's free slots
+// less those held back for other queues' minimums.
+func (p *workerSlotPool) acquire(queue string, want int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queueState, ok := p.queues[queue]
+	if !ok {
+		return 0
+	}
+
+	var available int
+	for name, otherState := range p.queues {
+		available += otherState.maxWorkers - otherState.inUse
+		if name != queue {
+			available -= max(0, otherState.minWorkers-otherState.inUse)
+		}
+	}
+
+	acquired := max(0, min(want, available))
+	queueState.inUse += acquired
+	return acquired
+}
+
+// register adds a queue's slots to the pool.
+func (p *workerSlotPool) register(queue string, maxWorkers, minWorkers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.queues[queue] = &workerSlotPoolQueue{maxWorkers: maxWorkers, minWorkers: minWorkers}
+}
+
+// release returns slots previously acquired for the given queue to the pool.
+func (p *workerSlotPool) release(queue string, num int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if queueState, ok := p.queues[queue]; ok {
+		queueState.inUse = max(0, queueState.inUse-num)
+	}
+}
+
+// unregister removes a queue's slots from the pool. The queue's producer
+// should have stopped so that it's no longer using any.
+func (p *workerSlotPool) unregister(queue string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.queues, queue)
+}
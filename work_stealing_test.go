@@ -0,0 +1,165 @@
+package river
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/testutil"
+)
+
+func TestWorkerSlotPool(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) *workerSlotPool {
+		t.Helper()
+
+		pool := newWorkerSlotPool()
+		pool.register("busy", 2, 0)
+		pool.register("idle", 4, 1)
+		return pool
+	}
+
+	t.Run("AcquiresOwnSlots", func(t *testing.T) {
+		t.Parallel()
+
+		pool := setup(t)
+
+		require.Equal(t, 2, pool.acquire("busy", 2))
+		require.Equal(t, 2, pool.queues["busy"].inUse)
+	})
+
+	t.Run("BorrowsIdleSlotsAboveMinimum", func(t *testing.T) {
+		t.Parallel()
+
+		pool := setup(t)
+
+		// Of the idle queue's four slots, one is reserved for its minimum.
+		require.Equal(t, 2, pool.acquire("busy", 2))
+		require.Equal(t, 2, pool.acquire("busy", 2))
+		require.Equal(t, 1, pool.acquire("busy", 2))
+		require.Zero(t, pool.acquire("busy", 2))
+
+		// The idle queue can still use its minimum.
+		require.Equal(t, 1, pool.acquire("idle", 4))
+		require.Zero(t, pool.acquire("idle", 4))
+	})
+
+	t.Run("LenderUsingSlotsLimitsBorrowing", func(t *testing.T) {
+		t.Parallel()
+
+		pool := setup(t)
+
+		require.Equal(t, 3, pool.acquire("idle", 3))
+
+		require.Equal(t, 2, pool.acquire("busy", 2))
+		require.Equal(t, 1, pool.acquire("busy", 2))
+		require.Zero(t, pool.acquire("busy", 2))
+	})
+
+	t.Run("ReleaseReturnsSlots", func(t *testing.T) {
+		t.Parallel()
+
+		pool := setup(t)
+
+		require.Equal(t, 5, pool.acquire("busy", 10))
+		require.Zero(t, pool.acquire("busy", 10))
+
+		pool.release("busy", 2)
+		require.Equal(t, 3, pool.queues["busy"].inUse)
+		require.Equal(t, 2, pool.acquire("busy", 10))
+
+		// Releasing more than acquired doesn't go negative.
+		pool.release("busy", 100)
+		require.Zero(t, pool.queues["busy"].inUse)
+	})
+
+	t.Run("MinWorkersEqualToMaxWorkersDoesntLend", func(t *testing.T) {
+		t.Parallel()
+
+		pool := newWorkerSlotPool()
+		pool.register("busy", 2, 0)
+		pool.register("reserved", 4, 4)
+
+		require.Equal(t, 2, pool.acquire("busy", 10))
+		require.Zero(t, pool.acquire("busy", 10))
+
+		// But the reserved queue may borrow from the other.
+		pool.release("busy", 2)
+		require.Equal(t, 6, pool.acquire("reserved", 10))
+	})
+
+	t.Run("UnregisteredQueue", func(t *testing.T) {
+		t.Parallel()
+
+		pool := setup(t)
+
+		pool.unregister("idle")
+		require.Zero(t, pool.acquire("idle", 1))
+		require.Equal(t, 2, pool.acquire("busy", 10))
+
+		pool.release("idle", 1) // no-op
+	})
+}
+
+func Test_Client_WorkStealing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		testutil.JobArgsReflectKind[JobArgs]
+	}
+
+	t.Run("BorrowsIdleSlotsFromOtherQueue", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			jobStarted = make(chan struct{})
+			numRunning atomic.Int64
+			unblock    = make(chan struct{})
+		)
+
+		config := newTestConfig(t, "")
+		config.Queues = map[string]QueueConfig{
+			"busy": {MaxWorkers: 1},
+			"idle": {MaxWorkers: 3, MinWorkers: 1},
+		}
+		config.WorkStealing = true
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			numRunning.Add(1)
+			jobStarted <- struct{}{}
+			<-unblock
+			return nil
+		}))
+
+		client := runNewTestClient(ctx, t, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
+		for range 4 {
+			_, err := client.Insert(ctx, JobArgs{}, &InsertOpts{Queue: "busy"})
+			require.NoError(t, err)
+		}
+
+		// The busy queue works one job in its own slot and borrows two of the
+		// idle queue's, but not the one reserved by its MinWorkers.
+		for range 3 {
+			riversharedtest.WaitOrTimeout(t, jobStarted)
+		}
+
+		time.Sleep(3 * client.config.FetchPollInterval)
+		require.Equal(t, int64(3), numRunning.Load())
+
+		close(unblock)
+
+		// The remaining job is worked once a slot frees up.
+		riversharedtest.WaitOrTimeout(t, jobStarted)
+		riversharedtest.WaitOrTimeoutN(t, subscribeChan, 4)
+	})
+}
@@ -3,8 +3,14 @@ package river
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/internal/argscodec"
 	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/rivertype"
@@ -19,6 +25,20 @@ func (w *workUnitFactoryWrapper[T]) MakeUnit(jobRow *rivertype.JobRow) workunit.
 	return &wrapperWorkUnit[T]{jobRow: jobRow, worker: w.worker}
 }
 
+// unwrapWorker returns the wrapped worker so that it can be checked for
+// optional interfaces like WorkerWithQueues or WorkerWithSetup.
+func (w *workUnitFactoryWrapper[T]) unwrapWorker() any { return w.worker }
+
+// workUnitFactoryConstructorWrapper wraps a Worker constructor to implement
+// workUnitFactory, building a new worker for each job.
+type workUnitFactoryConstructorWrapper[T JobArgs] struct {
+	newWorker func(job *rivertype.JobRow) Worker[T]
+}
+
+func (w *workUnitFactoryConstructorWrapper[T]) MakeUnit(jobRow *rivertype.JobRow) workunit.WorkUnit {
+	return &wrapperWorkUnit[T]{jobRow: jobRow, worker: w.newWorker(jobRow)}
+}
+
 // wrapperWorkUnit implements workUnit for a job and Worker.
 type wrapperWorkUnit[T JobArgs] struct {
 	job    *Job[T] // not set until after UnmarshalJob is invoked
@@ -34,14 +54,95 @@ func (w *wrapperWorkUnit[T]) HookLookup(lookup *hooklookup.JobHookLookup) hooklo
 func (w *wrapperWorkUnit[T]) Middleware() []rivertype.WorkerMiddleware {
 	return w.worker.Middleware(w.jobRow)
 }
-func (w *wrapperWorkUnit[T]) NextRetry() time.Time           { return w.worker.NextRetry(w.job) }
-func (w *wrapperWorkUnit[T]) Timeout() time.Duration         { return w.worker.Timeout(w.job) }
-func (w *wrapperWorkUnit[T]) Work(ctx context.Context) error { return w.worker.Work(ctx, w.job) }
+func (w *wrapperWorkUnit[T]) NextRetry() time.Time { return w.worker.NextRetry(w.job) }
 
-func (w *wrapperWorkUnit[T]) UnmarshalJob() error {
+func (w *wrapperWorkUnit[T]) Timeout() time.Duration {
+	if timeout := w.worker.Timeout(w.job); timeout != 0 {
+		return timeout
+	}
+
+	if argsWithTimeout, ok := any(w.job.Args).(JobArgsWithTimeout); ok {
+		return argsWithTimeout.Timeout()
+	}
+
+	return 0
+}
+
+func (w *wrapperWorkUnit[T]) TimeoutGracePeriod() time.Duration {
+	if workerWithGracePeriod, ok := w.worker.(WorkerWithTimeoutGracePeriod); ok {
+		return workerWithGracePeriod.TimeoutGracePeriod()
+	}
+
+	return 0
+}
+
+func (w *wrapperWorkUnit[T]) Work(ctx context.Context) error {
+	var err error
+	if workerWithSubprocess, ok := w.worker.(WorkerWithSubprocess); ok && workerWithSubprocess.Subprocess() {
+		err = workSubprocess(ctx, w.jobRow, w.job.Args)
+	} else {
+		err = w.worker.Work(ctx, w.job)
+	}
+
+	if workerWithSnoozeLimit, ok := w.worker.(WorkerWithSnoozeLimit); ok && errors.Is(err, &rivertype.JobSnoozeError{}) {
+		if snoozeLimit := workerWithSnoozeLimit.SnoozeLimit(); snoozeLimit != nil && snoozeLimit.MaxSnoozes > 0 &&
+			gjson.GetBytes(w.jobRow.Metadata, "snoozes").Int() >= int64(snoozeLimit.MaxSnoozes) {
+			return &rivertype.JobSnoozeLimitExceededError{
+				Discard:    snoozeLimit.Action == SnoozeLimitActionDiscard,
+				MaxSnoozes: snoozeLimit.MaxSnoozes,
+			}
+		}
+	}
+
+	return err
+}
+
+// workInSubprocess works the job in a child process started by SubprocessMain,
+// taking its args as plain JSON as they were decoded by the client.
+func (w *wrapperWorkUnit[T]) workInSubprocess(ctx context.Context, args []byte) error {
+	w.job = &Job[T]{
+		JobRow: w.jobRow,
+	}
+
+	if err := json.Unmarshal(args, &w.job.Args); err != nil {
+		return fmt.Errorf("error unmarshaling job args: %w", err)
+	}
+
+	return w.worker.Work(ctx, w.job)
+}
+
+func (w *wrapperWorkUnit[T]) UnmarshalJob(argsDecoder *argscodec.Decoder) error {
 	w.job = &Job[T]{
 		JobRow: w.jobRow,
 	}
 
-	return json.Unmarshal(w.jobRow.EncodedArgs, &w.job.Args)
+	var args T
+	return argsDecoder.DecodeWithUpgrade(w.jobRow, &w.job.Args, argsUpgradeFunc(w.jobRow, args))
+}
+
+// kindMiddlewareWorkUnit wraps a work unit for a job whose kind has middleware
+// added with AddWorkerMiddleware, running that middleware ahead of any from the
+// worker itself.
+type kindMiddlewareWorkUnit struct {
+	workunit.WorkUnit
+
+	middleware []rivertype.WorkerMiddleware
+}
+
+func (w *kindMiddlewareWorkUnit) Middleware() []rivertype.WorkerMiddleware {
+	return append(slices.Clone(w.middleware), w.WorkUnit.Middleware()...)
+}
+
+// workerQueueMismatchWorkUnit wraps a work unit for a job that was fetched from
+// a queue its worker isn't bound to with WorkerWithQueues. Instead of working
+// the job, it returns an error describing the mismatch.
+type workerQueueMismatchWorkUnit struct {
+	workunit.WorkUnit
+
+	jobRow *rivertype.JobRow
+	queues []string
+}
+
+func (w *workerQueueMismatchWorkUnit) Work(ctx context.Context) error {
+	return fmt.Errorf("job of kind %q is in queue %q, but its worker is bound to queues %v", w.jobRow.Kind, w.jobRow.Queue, w.queues)
 }
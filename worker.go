@@ -2,7 +2,9 @@ package river
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/riverqueue/river/internal/workunit"
@@ -76,6 +78,121 @@ type Worker[T JobArgs] interface {
 	Work(ctx context.Context, job *Job[T]) error
 }
 
+// WorkerWithQueues is an interface that a Worker can optionally implement to
+// declare the queues that jobs of its kind are expected to be inserted to and
+// worked from. It's useful for catching routing mistakes like a job inserted
+// to the wrong queue, or a worker registered on a client that doesn't work any
+// of its queues, which otherwise tend to surface as jobs that are never worked
+// or are worked by the wrong pool of clients.
+//
+//	func (w *EmailWorker) Queues() []string { return []string{"email"} }
+//
+// When declared, the client returns an error on insert of a job to a queue
+// not in the list, and on Start if none of the queues in the list are
+// configured on the client. It can also check jobs as they're fetched with
+// Config.WorkerQueuesEnforcedOnFetch.
+type WorkerWithQueues interface {
+	// Queues returns the names of queues jobs of the worker's kind are
+	// expected to be in. An empty list means jobs may be in any queue.
+	Queues() []string
+}
+
+// SnoozeLimit is a limit on the number of times a job may be snoozed. See
+// WorkerWithSnoozeLimit.
+type SnoozeLimit struct {
+	// Action is what happens to a job that tries to snooze beyond MaxSnoozes.
+	//
+	// Defaults to SnoozeLimitActionError.
+	Action SnoozeLimitAction
+
+	// MaxSnoozes is the number of times a job may be snoozed. Snoozes are
+	// counted in the `snoozes` key of the job's metadata. Zero disables the
+	// limit.
+	MaxSnoozes int
+}
+
+// SnoozeLimitAction is the action taken for a job that tries to snooze beyond
+// its SnoozeLimit.
+type SnoozeLimitAction string
+
+const (
+	// SnoozeLimitActionDiscard discards the job with a
+	// rivertype.JobSnoozeLimitExceededError recorded to its errors.
+	SnoozeLimitActionDiscard SnoozeLimitAction = "discard"
+
+	// SnoozeLimitActionError errors the job with a
+	// rivertype.JobSnoozeLimitExceededError so that it's retried according
+	// to its retry policy, consuming an attempt. Each retry that snoozes again
+	// errors again, so the job is discarded once its attempts are exhausted.
+	SnoozeLimitActionError SnoozeLimitAction = "error"
+)
+
+// WorkerWithSnoozeLimit is an interface that a Worker can optionally implement
+// to limit the number of times jobs of its kind may be snoozed. It's useful
+// for "poll until ready" jobs that snooze while waiting on a condition, so
+// that they don't snooze forever if the condition never materializes:
+//
+//	func (w *AwaitExportWorker) SnoozeLimit() *river.SnoozeLimit {
+//		return &river.SnoozeLimit{Action: river.SnoozeLimitActionDiscard, MaxSnoozes: 100}
+//	}
+//
+// A job that snoozes after already having been snoozed MaxSnoozes times is
+// errored or discarded instead according to SnoozeLimit.Action.
+type WorkerWithSnoozeLimit interface {
+	// SnoozeLimit returns the snooze limit for jobs of the worker's kind, or
+	// nil for no limit.
+	SnoozeLimit() *SnoozeLimit
+}
+
+// WorkerWithTimeoutGracePeriod is an interface that a Worker can optionally
+// implement to add a hard timeout phase after its soft timeout (see
+// Worker.Timeout). When a job exceeds its timeout its context is cancelled as
+// usual, giving it a chance to clean up. If it still hasn't returned once the
+// grace period has elapsed on top of that, the job is failed with a
+// rivertype.JobHardTimeoutError and retried according to its retry policy:
+//
+//	func (w *ExportWorker) TimeoutGracePeriod() time.Duration { return 30 * time.Second }
+//
+// Go has no way of stopping a goroutine from the outside, so a Work function
+// that never returns keeps running in the background after the job is failed,
+// and doesn't count against its queue's MaxWorkers. A hard timeout only
+// applies to jobs with a timeout, so a timeout of -1 disables both phases.
+type WorkerWithTimeoutGracePeriod interface {
+	// TimeoutGracePeriod returns how long jobs of the worker's kind are given
+	// to return after their context is cancelled by their timeout. Zero gives
+	// them as long as they need.
+	TimeoutGracePeriod() time.Duration
+}
+
+// WorkerWithSetup is an interface that a Worker can optionally implement to
+// run initialization once each time the client starts, before any jobs are
+// worked. It's useful for resources shared by all of a worker's Work calls
+// like connection pools or warmed caches:
+//
+//	func (w *ReportWorker) WorkerSetup(ctx context.Context) error {
+//		var err error
+//		w.warehousePool, err = warehouse.Connect(ctx, w.warehouseURL)
+//		return err
+//	}
+//
+// Setups are run in order of job kind. If one returns an error, workers that
+// were already set up have their WorkerTeardown invoked and Client.Start
+// returns the error.
+type WorkerWithSetup interface {
+	// WorkerSetup is invoked once per client start.
+	WorkerSetup(ctx context.Context) error
+}
+
+// WorkerWithTeardown is an interface that a Worker can optionally implement to
+// release resources acquired in WorkerSetup. It's invoked once each time the
+// client stops, after all producers have stopped and running jobs have
+// finished, in reverse order of job kind.
+type WorkerWithTeardown interface {
+	// WorkerTeardown is invoked once per client stop. Its context isn't
+	// cancelled by a hard stop.
+	WorkerTeardown(ctx context.Context)
+}
+
 // WorkerDefaults is an empty struct that can be embedded in your worker
 // struct to make it fulfill the Worker interface with default values.
 type WorkerDefaults[T JobArgs] struct{}
@@ -135,6 +252,71 @@ func AddWorkerSafely[T JobArgs](workers *Workers, worker Worker[T]) error {
 	return workers.add(jobArgs, &workUnitFactoryWrapper[T]{worker: worker})
 }
 
+// AddWorkerFactory registers a constructor on the provided Workers bundle that
+// builds a new Worker for each job of kind T, as opposed to AddWorker, which
+// registers a single worker that's shared by every job for the life of the
+// process. It's useful for workers that hold dependencies scoped to a single
+// job, like a dedicated database connection or a per-job logger:
+//
+//	river.AddWorkerFactory(workers, func(job *rivertype.JobRow) river.Worker[ExportArgs] {
+//		return &ExportWorker{logger: logger.With("job_id", job.ID)}
+//	})
+//
+// The constructor is invoked once per job just before it's worked, and
+// should be fast and side effect free because it's invoked even for jobs that
+// end up not being worked, like a job whose args can't be decoded. Values
+// carried by the job's context, like tracing spans, are available from the
+// context passed to Work.
+//
+// Because there's no worker instance at registration time, workers registered
+// with a constructor can't implement optional interfaces checked when a
+// client is created or started, like WorkerWithQueues, WorkerWithSetup, and
+// WorkerWithTeardown. Optional interfaces checked as a job is worked, like
+// WorkerWithSnoozeLimit, are supported.
+//
+// Like AddWorker, AddWorkerFactory panics if the kind is already registered
+// or its configuration is otherwise invalid. Use AddWorkerFactorySafely to
+// avoid panics.
+func AddWorkerFactory[T JobArgs](workers *Workers, newWorker func(job *rivertype.JobRow) Worker[T]) {
+	if err := AddWorkerFactorySafely(workers, newWorker); err != nil {
+		panic(err)
+	}
+}
+
+// AddWorkerFactorySafely is the same as AddWorkerFactory except that it returns
+// an error instead of panicking.
+func AddWorkerFactorySafely[T JobArgs](workers *Workers, newWorker func(job *rivertype.JobRow) Worker[T]) error {
+	if newWorker == nil {
+		return errors.New("worker constructor must not be nil")
+	}
+
+	var jobArgs T
+	return workers.add(jobArgs, &workUnitFactoryConstructorWrapper[T]{newWorker: newWorker})
+}
+
+// AddWorkerMiddleware registers worker middleware on the provided Workers
+// bundle that's run only for jobs of the given kinds, as opposed to middleware
+// in Config.Middleware, which runs for jobs of every kind. It's useful for
+// middleware that should only wrap a few kinds without each of their workers
+// having to return it from Worker.Middleware:
+//
+//	river.AddWorkerMiddleware(workers, []string{"charge_card", "send_invoice"}, &IdempotencyMiddleware{})
+//
+// Kinds are matched against the primary kind of each registered worker, so the
+// middleware also wraps jobs inserted with one of its kind aliases. A Client
+// returns an error on creation if middleware was added for a kind without a
+// registered worker.
+//
+// Middleware for a job is entered in a deterministic order: middleware added
+// with AddWorkerMiddleware in the order it was added, then middleware from the
+// worker's own Worker.Middleware, then worker middleware from
+// Config.Middleware.
+func AddWorkerMiddleware(workers *Workers, kinds []string, middleware ...rivertype.WorkerMiddleware) {
+	for _, kind := range kinds {
+		workers.middlewareByKind[kind] = append(workers.middlewareByKind[kind], middleware...)
+	}
+}
+
 // Workers is a list of available job workers. A Worker must be registered for
 // each type of Job to be handled.
 //
@@ -142,22 +324,84 @@ func AddWorkerSafely[T JobArgs](workers *Workers, worker Worker[T]) error {
 // worker.
 type Workers struct {
 	workersMap map[string]workerInfo // job kind -> worker info
+
+	// middlewareByKind is middleware added with AddWorkerMiddleware, keyed by
+	// job kind.
+	middlewareByKind map[string][]rivertype.WorkerMiddleware
 }
 
 // workerInfo bundles information about a registered worker for later lookup
 // in a Workers bundle.
 type workerInfo struct {
 	jobArgs         JobArgs
+	queues          []string // from WorkerWithQueues; empty means any queue
+	worker          any      // underlying Worker[T] for optional interface checks
 	workUnitFactory workunit.WorkUnitFactory
 }
 
+// queueAllowed returns true if jobs for the worker are allowed to be in the
+// given queue.
+func (i workerInfo) queueAllowed(queue string) bool {
+	return len(i.queues) < 1 || slices.Contains(i.queues, queue)
+}
+
 // NewWorkers initializes a new registry of available job workers.
 //
 // Use the top-level AddWorker function combined with a Workers registry to
 // register each available worker.
 func NewWorkers() *Workers {
 	return &Workers{
-		workersMap: make(map[string]workerInfo),
+		workersMap:       make(map[string]workerInfo),
+		middlewareByKind: make(map[string][]rivertype.WorkerMiddleware),
+	}
+}
+
+// primaryKinds returns the primary kinds of registered workers in sorted order,
+// omitting kind aliases so that each worker is only included once.
+func (w Workers) primaryKinds() []string {
+	kinds := make([]string, 0, len(w.workersMap))
+	for kind, workerInfo := range w.workersMap {
+		if kind == workerInfo.jobArgs.Kind() {
+			kinds = append(kinds, kind)
+		}
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// setup invokes WorkerSetup on each registered worker implementing
+// WorkerWithSetup. If any returns an error, workers already set up are torn
+// down before the error is returned.
+func (w Workers) setup(ctx context.Context) error {
+	kinds := w.primaryKinds()
+
+	for i, kind := range kinds {
+		workerWithSetup, ok := w.workersMap[kind].worker.(WorkerWithSetup)
+		if !ok {
+			continue
+		}
+
+		if err := workerWithSetup.WorkerSetup(ctx); err != nil {
+			w.teardownKinds(context.WithoutCancel(ctx), kinds[:i])
+			return fmt.Errorf("error setting up worker for kind %q: %w", kind, err)
+		}
+	}
+
+	return nil
+}
+
+// teardown invokes WorkerTeardown on each registered worker implementing
+// WorkerWithTeardown.
+func (w Workers) teardown(ctx context.Context) {
+	w.teardownKinds(ctx, w.primaryKinds())
+}
+
+// teardownKinds invokes WorkerTeardown for the given kinds in reverse order.
+func (w Workers) teardownKinds(ctx context.Context, kinds []string) {
+	for _, kind := range slices.Backward(kinds) {
+		if workerWithTeardown, ok := w.workersMap[kind].worker.(WorkerWithTeardown); ok {
+			workerWithTeardown.WorkerTeardown(ctx)
+		}
 	}
 }
 
@@ -169,13 +413,36 @@ func (w Workers) add(jobArgs JobArgs, workUnitFactory workunit.WorkUnitFactory)
 		return nil
 	}
 
+	kind := jobArgs.Kind()
+	if err := checkRegistered(kind); err != nil {
+		return err
+	}
+
+	var worker any
+	if workerUnwrapper, ok := workUnitFactory.(interface{ unwrapWorker() any }); ok {
+		worker = workerUnwrapper.unwrapWorker()
+	}
+
+	var queues []string
+	if workerWithQueues, ok := worker.(WorkerWithQueues); ok {
+		queues = workerWithQueues.Queues()
+		for _, queue := range queues {
+			if err := validateQueueName(queue); err != nil {
+				return fmt.Errorf("invalid queue for worker of kind %q: %w", kind, err)
+			}
+		}
+	}
+
 	workerInfo := workerInfo{
 		jobArgs:         jobArgs,
+		queues:          queues,
+		worker:          worker,
 		workUnitFactory: workUnitFactory,
 	}
 
-	kind := jobArgs.Kind()
-	if err := checkRegistered(kind); err != nil {
+	// Parse any insert option defaults from struct tags up front so that a
+	// malformed tag is reported at registration rather than on first insert.
+	if _, err := insertOptsFromStructTag(jobArgs); err != nil {
 		return err
 	}
 	w.workersMap[kind] = workerInfo
@@ -199,16 +466,74 @@ type workFunc[T JobArgs] struct {
 
 	kind string
 	f    func(context.Context, *Job[T]) error
+	opts *WorkFuncOpts[T]
 }
 
 func (wf *workFunc[T]) Kind() string {
 	return wf.kind
 }
 
+func (wf *workFunc[T]) Middleware(*rivertype.JobRow) []rivertype.WorkerMiddleware {
+	return wf.opts.Middleware
+}
+
+func (wf *workFunc[T]) NextRetry(job *Job[T]) time.Time {
+	if wf.opts.NextRetryFunc == nil {
+		return time.Time{}
+	}
+	return wf.opts.NextRetryFunc(job)
+}
+
+func (wf *workFunc[T]) Queues() []string {
+	return wf.opts.Queues
+}
+
+func (wf *workFunc[T]) Timeout(*Job[T]) time.Duration {
+	return wf.opts.Timeout
+}
+
+func (wf *workFunc[T]) TimeoutGracePeriod() time.Duration {
+	return wf.opts.TimeoutGracePeriod
+}
+
 func (wf *workFunc[T]) Work(ctx context.Context, job *Job[T]) error {
 	return wf.f(ctx, job)
 }
 
+// WorkFuncOpts are options for a worker created from a function with
+// WorkFuncWithOpts or AddWorkFunc. Each option corresponds to a method that
+// would otherwise be overridden on a worker struct embedding WorkerDefaults.
+type WorkFuncOpts[T JobArgs] struct {
+	// Middleware is type-specific middleware for jobs worked by the function.
+	// See Worker.Middleware.
+	Middleware []rivertype.WorkerMiddleware
+
+	// NextRetryFunc optionally calculates when the next retry for a failed job
+	// should take place. See Worker.NextRetry.
+	//
+	// Defaults to nil, which uses the client-level retry policy.
+	NextRetryFunc func(job *Job[T]) time.Time
+
+	// Queues are the queues jobs worked by the function are expected to be
+	// in. See WorkerWithQueues.
+	//
+	// Defaults to nil, which allows jobs in any queue.
+	Queues []string
+
+	// Timeout is the maximum amount of time a job is allowed to run before its
+	// context is cancelled. See Worker.Timeout.
+	//
+	// Defaults to zero, which inherits the client-level timeout.
+	Timeout time.Duration
+
+	// TimeoutGracePeriod is how long a job is given to return after its
+	// context is cancelled by its timeout before it's failed. See
+	// WorkerWithTimeoutGracePeriod.
+	//
+	// Defaults to zero, which gives jobs as long as they need.
+	TimeoutGracePeriod time.Duration
+}
+
 // WorkFunc wraps a function to implement the Worker interface. A job args
 // struct implementing JobArgs will still be required to specify a Kind.
 //
@@ -219,5 +544,45 @@ func (wf *workFunc[T]) Work(ctx context.Context, job *Job[T]) error {
 //		return nil
 //	}))
 func WorkFunc[T JobArgs](f func(context.Context, *Job[T]) error) Worker[T] {
-	return &workFunc[T]{f: f, kind: (*new(T)).Kind()}
+	return WorkFuncWithOpts(f, nil)
+}
+
+// WorkFuncWithOpts is the same as WorkFunc, but takes options that configure
+// the worker's timeout, middleware, and retry schedule, which would otherwise
+// require a worker struct overriding WorkerDefaults methods.
+//
+//	river.AddWorker(workers, river.WorkFuncWithOpts(func(ctx context.Context, job *river.Job[WorkFuncArgs]) error {
+//		fmt.Printf("Message: %s", job.Args.Message)
+//		return nil
+//	}, &river.WorkFuncOpts[WorkFuncArgs]{
+//		Timeout: 30 * time.Second,
+//	}))
+func WorkFuncWithOpts[T JobArgs](f func(context.Context, *Job[T]) error, opts *WorkFuncOpts[T]) Worker[T] {
+	if opts == nil {
+		opts = &WorkFuncOpts[T]{}
+	}
+
+	return &workFunc[T]{f: f, kind: (*new(T)).Kind(), opts: opts}
+}
+
+// AddWorkFunc registers a function as the worker for jobs with args of type T
+// on the provided Workers bundle. It's shorthand for AddWorker combined with
+// WorkFuncWithOpts, and is convenient for simple kinds that don't warrant a
+// worker struct:
+//
+//	river.AddWorkFunc(workers, func(ctx context.Context, job *river.Job[SortArgs]) error {
+//		sort.Strings(job.Args.Strings)
+//		return nil
+//	}, nil)
+//
+// Like AddWorker, AddWorkFunc panics if the kind is already registered or its
+// configuration is otherwise invalid. Use AddWorkFuncSafely to avoid panics.
+func AddWorkFunc[T JobArgs](workers *Workers, f func(context.Context, *Job[T]) error, opts *WorkFuncOpts[T]) {
+	AddWorker(workers, WorkFuncWithOpts(f, opts))
+}
+
+// AddWorkFuncSafely is the same as AddWorkFunc except that it returns an error
+// instead of panicking.
+func AddWorkFuncSafely[T JobArgs](workers *Workers, f func(context.Context, *Job[T]) error, opts *WorkFuncOpts[T]) error {
+	return AddWorkerSafely(workers, WorkFuncWithOpts(f, opts))
 }
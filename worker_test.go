@@ -2,7 +2,9 @@ package river
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
@@ -11,6 +13,7 @@ import (
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivershared/riversharedtest"
 	"github.com/riverqueue/river/rivershared/util/testutil"
+	"github.com/riverqueue/river/rivertype"
 )
 
 func TestWork(t *testing.T) {
@@ -85,6 +88,64 @@ func (a withKindAliasesCollisionArgs) Kind() string {
 	return (withKindAliasesArgs{}).KindAliases()[0]
 }
 
+func TestAddWorkerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	workers := NewWorkers()
+
+	middleware1 := &overridableJobMiddleware{}
+	middleware2 := &overridableJobMiddleware{}
+
+	AddWorkerMiddleware(workers, []string{"kind1", "kind2"}, middleware1)
+	AddWorkerMiddleware(workers, []string{"kind1"}, middleware2)
+
+	require.Len(t, workers.middlewareByKind["kind1"], 2)
+	require.Same(t, middleware1, workers.middlewareByKind["kind1"][0])
+	require.Same(t, middleware2, workers.middlewareByKind["kind1"][1])
+
+	require.Len(t, workers.middlewareByKind["kind2"], 1)
+	require.Same(t, middleware1, workers.middlewareByKind["kind2"][0])
+}
+
+func TestAddWorkerFactory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewWorkerPerJob", func(t *testing.T) {
+		t.Parallel()
+
+		var jobIDs []int64
+		workers := NewWorkers()
+		AddWorkerFactory(workers, func(job *rivertype.JobRow) Worker[noOpArgs] {
+			jobIDs = append(jobIDs, job.ID)
+			return WorkFunc(func(ctx context.Context, job *Job[noOpArgs]) error { return nil })
+		})
+
+		factory := workers.workersMap[(noOpArgs{}).Kind()].workUnitFactory
+		workUnit1 := factory.MakeUnit(&rivertype.JobRow{ID: 1})
+		workUnit2 := factory.MakeUnit(&rivertype.JobRow{ID: 2})
+
+		require.Equal(t, []int64{1, 2}, jobIDs)
+		require.NotSame(t, workUnit1.(*wrapperWorkUnit[noOpArgs]).worker, workUnit2.(*wrapperWorkUnit[noOpArgs]).worker) //nolint:forcetypeassert
+	})
+
+	t.Run("AlreadyRegistered", func(t *testing.T) {
+		t.Parallel()
+
+		workers := NewWorkers()
+		AddWorker(workers, &noOpWorker{})
+
+		err := AddWorkerFactorySafely(workers, func(job *rivertype.JobRow) Worker[noOpArgs] { return &noOpWorker{} })
+		require.EqualError(t, err, `worker for kind "noOp" is already registered`)
+	})
+
+	t.Run("NilConstructor", func(t *testing.T) {
+		t.Parallel()
+
+		err := AddWorkerFactorySafely[noOpArgs](NewWorkers(), nil)
+		require.EqualError(t, err, "worker constructor must not be nil")
+	})
+}
+
 func TestWorkers_add(t *testing.T) {
 	t.Parallel()
 
@@ -99,6 +160,164 @@ func TestWorkers_add(t *testing.T) {
 
 	err = workers.add(noOpArgs{}, &workUnitFactoryWrapper[noOpArgs]{worker: &noOpWorker{}})
 	require.EqualError(t, err, `worker for kind "noOp" is already registered`)
+
+	// Invalid insert options in a struct tag are rejected at registration.
+	err = workers.add(structTagInvalidInsertOptsArgs{}, &workUnitFactoryWrapper[structTagInvalidInsertOptsArgs]{
+		worker: WorkFunc(func(ctx context.Context, job *Job[structTagInvalidInsertOptsArgs]) error { return nil }),
+	})
+	require.ErrorContains(t, err, "priority must be an integer between 1 and 4")
+
+	// Queues declared by the worker are stored for later checks.
+	err = workers.add(withQueuesArgs{}, &workUnitFactoryWrapper[withQueuesArgs]{worker: &withQueuesWorker{}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"with_queues"}, workers.workersMap[(withQueuesArgs{}).Kind()].queues)
+
+	// Invalid queues declared by the worker are rejected at registration.
+	err = workers.add(WorkFuncArgs{}, &workUnitFactoryWrapper[WorkFuncArgs]{
+		worker: WorkFuncWithOpts(func(ctx context.Context, job *Job[WorkFuncArgs]) error { return nil }, &WorkFuncOpts[WorkFuncArgs]{
+			Queues: []string{"invalid*queue"},
+		}),
+	})
+	require.ErrorContains(t, err, `invalid queue for worker of kind "work_func"`)
+}
+
+type withQueuesArgs struct{}
+
+func (a withQueuesArgs) Kind() string { return "with_queues" }
+
+type withQueuesWorker struct {
+	WorkerDefaults[withQueuesArgs]
+}
+
+func (w *withQueuesWorker) Queues() []string { return []string{"with_queues"} }
+
+func (w *withQueuesWorker) Work(ctx context.Context, job *Job[withQueuesArgs]) error {
+	return nil
+}
+
+func TestWorkerInfo_queueAllowed(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, (workerInfo{}).queueAllowed(QueueDefault))
+	require.True(t, (workerInfo{queues: []string{"a", "b"}}).queueAllowed("b"))
+	require.False(t, (workerInfo{queues: []string{"a", "b"}}).queueAllowed(QueueDefault))
+}
+
+type lifecycleArgs struct{ kind string }
+
+func (a lifecycleArgs) Kind() string { return a.kind }
+
+type lifecycleWorker struct {
+	WorkerDefaults[lifecycleArgs]
+
+	calls    *[]string
+	kind     string
+	setupErr error
+}
+
+func (w *lifecycleWorker) WorkerSetup(ctx context.Context) error {
+	*w.calls = append(*w.calls, "setup_"+w.kind)
+	return w.setupErr
+}
+
+func (w *lifecycleWorker) WorkerTeardown(ctx context.Context) {
+	*w.calls = append(*w.calls, "teardown_"+w.kind)
+}
+
+func (w *lifecycleWorker) Work(ctx context.Context, job *Job[lifecycleArgs]) error {
+	return nil
+}
+
+func TestWorkers_setupAndTeardown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		calls []string
+	}
+
+	setup := func(t *testing.T, setupErrKind string) (*Workers, *testBundle) {
+		t.Helper()
+
+		var (
+			bundle  = &testBundle{}
+			workers = NewWorkers()
+		)
+
+		for _, kind := range []string{"b", "a", "c"} {
+			worker := &lifecycleWorker{calls: &bundle.calls, kind: kind}
+			if kind == setupErrKind {
+				worker.setupErr = errors.New("setup error")
+			}
+			AddWorkerArgs(workers, lifecycleArgs{kind: kind}, Worker[lifecycleArgs](worker))
+		}
+
+		// A worker without lifecycle hooks is skipped.
+		AddWorker(workers, &noOpWorker{})
+
+		return workers, bundle
+	}
+
+	t.Run("SetupAndTeardownInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		workers, bundle := setup(t, "")
+
+		require.NoError(t, workers.setup(ctx))
+		require.Equal(t, []string{"setup_a", "setup_b", "setup_c"}, bundle.calls)
+
+		workers.teardown(ctx)
+		require.Equal(t, []string{"setup_a", "setup_b", "setup_c", "teardown_c", "teardown_b", "teardown_a"}, bundle.calls)
+	})
+
+	t.Run("SetupErrorTearsDownPrevious", func(t *testing.T) {
+		t.Parallel()
+
+		workers, bundle := setup(t, "b")
+
+		require.EqualError(t, workers.setup(ctx), `error setting up worker for kind "b": setup error`)
+		require.Equal(t, []string{"setup_a", "setup_b", "teardown_a"}, bundle.calls)
+	})
+
+	t.Run("KindAliasesInvokedOnce", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls   []string
+			workers = NewWorkers()
+		)
+
+		AddWorkerArgs(workers, lifecycleWithKindAliasesArgs{}, Worker[lifecycleWithKindAliasesArgs](&lifecycleWithKindAliasesWorker{calls: &calls}))
+
+		require.NoError(t, workers.setup(ctx))
+		workers.teardown(ctx)
+		require.Equal(t, []string{"setup", "teardown"}, calls)
+	})
+}
+
+type lifecycleWithKindAliasesArgs struct{}
+
+func (a lifecycleWithKindAliasesArgs) Kind() string          { return "lifecycle_with_kind_aliases" }
+func (a lifecycleWithKindAliasesArgs) KindAliases() []string { return []string{"lifecycle_alias"} }
+
+type lifecycleWithKindAliasesWorker struct {
+	WorkerDefaults[lifecycleWithKindAliasesArgs]
+
+	calls *[]string
+}
+
+func (w *lifecycleWithKindAliasesWorker) WorkerSetup(ctx context.Context) error {
+	*w.calls = append(*w.calls, "setup")
+	return nil
+}
+
+func (w *lifecycleWithKindAliasesWorker) WorkerTeardown(ctx context.Context) {
+	*w.calls = append(*w.calls, "teardown")
+}
+
+func (w *lifecycleWithKindAliasesWorker) Work(ctx context.Context, job *Job[lifecycleWithKindAliasesArgs]) error {
+	return nil
 }
 
 type WorkFuncArgs struct{}
@@ -191,4 +410,84 @@ func TestWorkFunc(t *testing.T) {
 
 		riversharedtest.WaitOrTimeout(t, workChan)
 	})
+
+	t.Run("WithOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		var (
+			middlewareChan = make(chan struct{}, 1)
+			workChan       = make(chan time.Time, 1)
+		)
+		AddWorkFunc(client.config.Workers, func(ctx context.Context, job *Job[WorkFuncArgs]) error {
+			deadline, _ := ctx.Deadline()
+			workChan <- deadline
+			return nil
+		}, &WorkFuncOpts[WorkFuncArgs]{
+			Middleware: []rivertype.WorkerMiddleware{
+				WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+					middlewareChan <- struct{}{}
+					return doInner(ctx)
+				}),
+			},
+			Timeout: time.Hour,
+		})
+
+		_, err := client.Insert(ctx, &WorkFuncArgs{}, nil)
+		require.NoError(t, err)
+
+		riversharedtest.WaitOrTimeout(t, middlewareChan)
+		deadline := riversharedtest.WaitOrTimeout(t, workChan)
+		require.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+	})
+}
+
+func TestWorkFuncWithOpts(t *testing.T) {
+	t.Parallel()
+
+	workFunc := func(ctx context.Context, job *Job[WorkFuncArgs]) error { return nil }
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		worker := WorkFuncWithOpts(workFunc, nil)
+		require.Nil(t, worker.Middleware(&rivertype.JobRow{}))
+		require.Zero(t, worker.NextRetry(&Job[WorkFuncArgs]{}))
+		require.Zero(t, worker.Timeout(&Job[WorkFuncArgs]{}))
+		require.Zero(t, worker.(WorkerWithTimeoutGracePeriod).TimeoutGracePeriod()) //nolint:forcetypeassert
+	})
+
+	t.Run("AllOpts", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			middleware = WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				return doInner(ctx)
+			})
+			nextRetry = time.Now().Add(time.Minute)
+		)
+
+		worker := WorkFuncWithOpts(workFunc, &WorkFuncOpts[WorkFuncArgs]{
+			Middleware:         []rivertype.WorkerMiddleware{middleware},
+			NextRetryFunc:      func(job *Job[WorkFuncArgs]) time.Time { return nextRetry },
+			Timeout:            5 * time.Second,
+			TimeoutGracePeriod: 10 * time.Second,
+		})
+		require.Len(t, worker.Middleware(&rivertype.JobRow{}), 1)
+		require.Equal(t, nextRetry, worker.NextRetry(&Job[WorkFuncArgs]{}))
+		require.Equal(t, 5*time.Second, worker.Timeout(&Job[WorkFuncArgs]{}))
+		require.Equal(t, 10*time.Second, worker.(WorkerWithTimeoutGracePeriod).TimeoutGracePeriod()) //nolint:forcetypeassert
+	})
+}
+
+func TestAddWorkFuncSafely(t *testing.T) {
+	t.Parallel()
+
+	workers := NewWorkers()
+
+	workFunc := func(ctx context.Context, job *Job[WorkFuncArgs]) error { return nil }
+
+	require.NoError(t, AddWorkFuncSafely(workers, workFunc, nil))
+	require.EqualError(t, AddWorkFuncSafely(workers, workFunc, nil), `worker for kind "work_func" is already registered`)
 }